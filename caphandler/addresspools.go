@@ -32,7 +32,7 @@ import (
 
 // GetAddressPoolCollection fetches the addresspool which are linked to that fabric
 func GetAddressPoolCollection(ctx iris.Context) {
-	uri := ctx.Request().RequestURI
+	uri := ctx.Path()
 	fabricID := ctx.Params().Get("id")
 	// get all switches which are store under that fabric
 
@@ -64,7 +64,7 @@ func GetAddressPoolCollection(ctx iris.Context) {
 
 // GetAddressPoolInfo fetches the addresspool info for given addresspool id
 func GetAddressPoolInfo(ctx iris.Context) {
-	uri := ctx.Request().RequestURI
+	uri := ctx.Path()
 	fabricID := ctx.Params().Get("id")
 
 	if _, err := capmodel.GetFabric(fabricID); err != nil {
@@ -86,7 +86,7 @@ func GetAddressPoolInfo(ctx iris.Context) {
 
 // CreateAddressPool stores the given addresspool against given fabric
 func CreateAddressPool(ctx iris.Context) {
-	uri := ctx.Request().RequestURI
+	uri := ctx.Path()
 	fabricID := ctx.Params().Get("id")
 	if _, err := capmodel.GetFabric(fabricID); err != nil {
 		errMsg := fmt.Sprintf("failed to fetch fabric data for uri %s: %s", uri, err.Error())
@@ -100,7 +100,7 @@ func CreateAddressPool(ctx iris.Context) {
 		log.Error(errorMessage)
 		resp := updateErrorResponse(response.MalformedJSON, errorMessage, nil)
 		ctx.StatusCode(http.StatusBadRequest)
-		ctx.JSON(resp)
+		writeErrorResponse(ctx, resp)
 		return
 	}
 	// Todo :Add required validation for the request params
@@ -109,7 +109,7 @@ func CreateAddressPool(ctx iris.Context) {
 		log.Error(err.Error())
 		resp := updateErrorResponse(response.PropertyMissing, err.Error(), []interface{}{missingAttribute})
 		ctx.StatusCode(http.StatusBadRequest)
-		ctx.JSON(resp)
+		writeErrorResponse(ctx, resp)
 		return
 	}
 	var nativeVLANflag bool
@@ -119,7 +119,7 @@ func CreateAddressPool(ctx iris.Context) {
 			log.Errorf(errorMessage)
 			resp := updateErrorResponse(response.PropertyValueFormatError, errorMessage, []interface{}{addresspoolData.Ethernet.IPv4.GatewayIPAddress, "GatewayIPAddress"})
 			ctx.StatusCode(http.StatusBadRequest)
-			ctx.JSON(resp)
+			writeErrorResponse(ctx, resp)
 			return
 
 		}
@@ -133,7 +133,7 @@ func CreateAddressPool(ctx iris.Context) {
 			log.Error(errorMessage)
 			resp := updateErrorResponse(response.PropertyUnknown, errorMessage, []interface{}{"VLANIdentifierAddressRange"})
 			ctx.StatusCode(http.StatusBadRequest)
-			ctx.JSON(resp)
+			writeErrorResponse(ctx, resp)
 			return
 		}
 		addressPools, err := capmodel.GetAllAddressPools(fabricID)
@@ -151,7 +151,7 @@ func CreateAddressPool(ctx iris.Context) {
 				log.Error(errorMessage)
 				resp := updateErrorResponse(response.ResourceAlreadyExists, errorMessage, []interface{}{"AddressPool", "GatewayIPAddress", addresspoolData.Ethernet.IPv4.GatewayIPAddress})
 				ctx.StatusCode(http.StatusConflict)
-				ctx.JSON(resp)
+				writeErrorResponse(ctx, resp)
 				return
 			}
 		}
@@ -162,7 +162,7 @@ func CreateAddressPool(ctx iris.Context) {
 		log.Error(errorMessage)
 		resp := updateErrorResponse(response.PropertyUnknown, errorMessage, []interface{}{"VLANIdentifierAddressRange"})
 		ctx.StatusCode(http.StatusBadRequest)
-		ctx.JSON(resp)
+		writeErrorResponse(ctx, resp)
 		return
 	}
 	// validate the  VLANIdentifierAddressRange lower value
@@ -175,7 +175,7 @@ func CreateAddressPool(ctx iris.Context) {
 	}
 	if statusCode != http.StatusOK {
 		ctx.StatusCode(statusCode)
-		ctx.JSON(resp)
+		writeErrorResponse(ctx, resp)
 		return
 	}
 
@@ -211,7 +211,7 @@ func validateAddressPoolRequest(request model.AddressPool) (string, error) {
 
 // DeleteAddressPoolInfo stores the given addresspool against given fabric
 func DeleteAddressPoolInfo(ctx iris.Context) {
-	uri := ctx.Request().RequestURI
+	uri := ctx.Path()
 	fabricID := ctx.Params().Get("id")
 
 	if _, err := capmodel.GetFabric(fabricID); err != nil {
@@ -231,7 +231,7 @@ func DeleteAddressPoolInfo(ctx iris.Context) {
 		log.Error(errMsg)
 		resp := updateErrorResponse(response.ResourceCannotBeDeleted, errMsg, []interface{}{uri, "AddressPool"})
 		ctx.StatusCode(http.StatusNotAcceptable)
-		ctx.JSON(resp)
+		writeErrorResponse(ctx, resp)
 		return
 	}
 	// Todo:Add the validation  to verify the links
@@ -0,0 +1,154 @@
+//(C) Copyright [2020] Hewlett Packard Enterprise Development LP
+//
+//Licensed under the Apache License, Version 2.0 (the "License"); you may
+//not use this file except in compliance with the License. You may obtain
+//a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+//Unless required by applicable law or agreed to in writing, software
+//distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+//WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+//License for the specific language governing permissions and limitations
+// under the License.
+
+//Package caphandler ...
+package caphandler
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"regexp"
+	"sync"
+	"time"
+
+	"github.com/ODIM-Project/ODIM/lib-utilities/common"
+	"github.com/ODIM-Project/PluginCiscoACI/capmodel"
+	"github.com/ODIM-Project/PluginCiscoACI/capresponse"
+	"github.com/ODIM-Project/PluginCiscoACI/caputilities"
+	"github.com/ODIM-Project/PluginCiscoACI/config"
+	"github.com/ODIM-Project/PluginCiscoACI/constants"
+	iris "github.com/kataras/iris/v12"
+	uuid "github.com/satori/go.uuid"
+	log "github.com/sirupsen/logrus"
+)
+
+var (
+	apicSubscriptions     *caputilities.APICSubscriptionManager
+	apicSubscriptionsLock sync.Mutex
+	// apicPortDNPattern matches the dn of the physical interface MOs, like topology/pod-1/node-101/sys/phys-[eth1/1]/phys
+	apicPortDNPattern = regexp.MustCompile(`^topology/pod-([^/]+)/node-([^/]+)/sys/phys-\[([^\]]+)\]`)
+)
+
+// SubscribeAPICEvents subscribes the plugin to the changes of the configured classes over the APIC
+// websocket, the link state changes of the ports are published as events
+func SubscribeAPICEvents(ctx iris.Context) {
+	manager := getAPICSubscriptions()
+	statusCode := http.StatusOK
+	if manager.Start() {
+		statusCode = http.StatusCreated
+	}
+	ctx.StatusCode(statusCode)
+	ctx.JSON(capresponse.APICSubscriptionResponse{Subscribed: true, Classes: manager.Classes()})
+}
+
+// UnsubscribeAPICEvents closes the subscriptions to the APIC websocket events
+func UnsubscribeAPICEvents(ctx iris.Context) {
+	manager := getAPICSubscriptions()
+	manager.Stop()
+	ctx.StatusCode(http.StatusOK)
+	ctx.JSON(capresponse.APICSubscriptionResponse{Subscribed: false, Classes: manager.Classes()})
+}
+
+func getAPICSubscriptions() *caputilities.APICSubscriptionManager {
+	apicSubscriptionsLock.Lock()
+	defer apicSubscriptionsLock.Unlock()
+	if apicSubscriptions == nil {
+		refresh := time.Duration(config.Data.APICConf.SubscriptionRefreshInSeconds) * time.Second
+		apicSubscriptions = caputilities.NewAPICSubscriptionManager(config.Data.APICConf.SubscriptionClasses, refresh, publishAPICNotification)
+	}
+	return apicSubscriptions
+}
+
+// publishAPICNotification publishes the link state change notified by APIC as an event
+func publishAPICNotification(notification caputilities.APICNotification) {
+	message, err := newLinkStateEvent(notification)
+	if err != nil {
+		log.Error("while processing APIC notification of class " + notification.Class + ", got: " + err.Error())
+		return
+	}
+	if message == nil {
+		return
+	}
+	portEventStreams.broadcast(message)
+	data, _ := json.Marshal(message)
+	writeEventToJobQueue(common.Events{
+		IP:      config.Data.LoadBalancerConf.Host,
+		Request: data,
+	})
+}
+
+// newLinkStateEvent builds the event of the port link state change notified by APIC,
+// nil is returned for notifications which are not link state changes of a port
+func newLinkStateEvent(notification caputilities.APICNotification) (*common.MessageData, error) {
+	operState, _ := notification.Attributes["operSt"].(string)
+	dn, _ := notification.Attributes["dn"].(string)
+	if operState == "" || !apicPortDNPattern.MatchString(dn) {
+		return nil, nil
+	}
+	portOID, err := findPortOID(dn)
+	if err != nil {
+		return nil, err
+	}
+	event := common.Event{
+		EventID:           uuid.NewV4().String(),
+		EventType:         "StatusChange",
+		EventTimestamp:    time.Now().Format(time.RFC3339),
+		Severity:          "OK",
+		MessageID:         constants.ResourceStatusChangedOKMessageID,
+		Message:           fmt.Sprintf("The link state of the port changed to %s", operState),
+		OriginOfCondition: &common.Link{Oid: portOID},
+	}
+	if operState != "up" {
+		event.Severity = "Warning"
+		event.MessageID = constants.ResourceStatusChangedWarningMessageID
+	}
+	return &common.MessageData{
+		Name:      "Port link state changed event",
+		Context:   "/redfish/v1/$metadata#Event.Event",
+		OdataType: constants.EventODataType,
+		Events:    []common.Event{event},
+	}, nil
+}
+
+// findPortOID finds the port stored for the physical interface with the given APIC dn
+func findPortOID(dn string) (string, error) {
+	match := apicPortDNPattern.FindStringSubmatch(dn)
+	if match == nil {
+		return "", fmt.Errorf("%s is not the dn of a physical interface", dn)
+	}
+	podID, nodeID, portID := match[1], match[2], match[3]
+	allFabric, err := capmodel.GetAllFabric("")
+	if err != nil {
+		return "", err
+	}
+	for fabricID, fabric := range allFabric {
+		if fabric.PodID != podID {
+			continue
+		}
+		for _, switchID := range fabric.SwitchData {
+			if capmodel.SwitchNodeID(switchID) != nodeID {
+				continue
+			}
+			ports, err := capmodel.GetSwitchPort(switchID)
+			if err != nil {
+				return "", err
+			}
+			if id := findSwitchPort(ports, portID); id != "" {
+				return fmt.Sprintf("/ODIM/v1/Fabrics/%s/Switches/%s/Ports/%s", fabricID, switchID, id), nil
+			}
+		}
+	}
+	return "", fmt.Errorf("no port found for %s", dn)
+}
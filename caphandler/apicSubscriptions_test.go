@@ -0,0 +1,83 @@
+//(C) Copyright [2020] Hewlett Packard Enterprise Development LP
+//
+//Licensed under the Apache License, Version 2.0 (the "License"); you may
+//not use this file except in compliance with the License. You may obtain
+//a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+//Unless required by applicable law or agreed to in writing, software
+//distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+//WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+//License for the specific language governing permissions and limitations
+// under the License.
+
+package caphandler
+
+import (
+	"testing"
+
+	"github.com/ODIM-Project/PluginCiscoACI/capdata"
+	"github.com/ODIM-Project/PluginCiscoACI/capmodel"
+	"github.com/ODIM-Project/PluginCiscoACI/caputilities"
+	"github.com/ODIM-Project/PluginCiscoACI/constants"
+)
+
+func TestNewLinkStateEvent(t *testing.T) {
+	mockPortApp(t)
+	capmodel.SaveFabric(testFabricID, &capdata.Fabric{PodID: "1", SwitchData: []string{testSwitchID}})
+	tests := []struct {
+		name          string
+		attributes    map[string]interface{}
+		wantEvent     bool
+		wantMessageID string
+		wantErr       bool
+	}{
+		{
+			name:          "link down",
+			attributes:    map[string]interface{}{"dn": "topology/pod-1/node-101/sys/phys-[eth1/1]/phys", "operSt": "down"},
+			wantEvent:     true,
+			wantMessageID: constants.ResourceStatusChangedWarningMessageID,
+		},
+		{
+			name:          "link up",
+			attributes:    map[string]interface{}{"dn": "topology/pod-1/node-101/sys/phys-[eth1/1]/phys", "operSt": "up"},
+			wantEvent:     true,
+			wantMessageID: constants.ResourceStatusChangedOKMessageID,
+		},
+		{
+			name:       "not a link state change",
+			attributes: map[string]interface{}{"dn": "topology/pod-1/node-101/sys/phys-[eth1/1]/phys", "descr": "uplink"},
+		},
+		{
+			name:       "not a physical interface",
+			attributes: map[string]interface{}{"dn": "topology/pod-1/node-101/sys", "operSt": "up"},
+		},
+		{
+			name:       "unknown port",
+			attributes: map[string]interface{}{"dn": "topology/pod-1/node-101/sys/phys-[eth1/9]/phys", "operSt": "up"},
+			wantErr:    true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			message, err := newLinkStateEvent(caputilities.APICNotification{Class: "ethpmPhysIf", Attributes: tt.attributes})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("newLinkStateEvent() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if (message != nil) != tt.wantEvent {
+				t.Fatalf("newLinkStateEvent() = %v, want event %v", message, tt.wantEvent)
+			}
+			if message == nil {
+				return
+			}
+			event := message.Events[0]
+			if event.MessageID != tt.wantMessageID {
+				t.Errorf("newLinkStateEvent() MessageID = %s, want %s", event.MessageID, tt.wantMessageID)
+			}
+			if event.OriginOfCondition.Oid != testPortURI {
+				t.Errorf("newLinkStateEvent() OriginOfCondition = %s, want %s", event.OriginOfCondition.Oid, testPortURI)
+			}
+		})
+	}
+}
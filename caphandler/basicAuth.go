@@ -16,6 +16,7 @@
 package caphandler
 
 import (
+	"errors"
 	"io/ioutil"
 	"net/http"
 	"time"
@@ -25,23 +26,33 @@ import (
 	"github.com/ODIM-Project/PluginCiscoACI/capresponse"
 	"github.com/ODIM-Project/PluginCiscoACI/caputilities"
 	pluginConfig "github.com/ODIM-Project/PluginCiscoACI/config"
+	"github.com/ODIM-Project/PluginCiscoACI/db"
 	iris "github.com/kataras/iris/v12"
 	log "github.com/sirupsen/logrus"
 )
 
-//TokenValidation validates sent token with the list of plugin generated tokens
-func TokenValidation(token string) bool {
-	var flag bool
-	flag = false
-	for _, v := range tokenDetails {
-		if token == v.Token {
-			flag = true
-			if time.Since(v.LastUsed).Minutes() > pluginConfig.Data.SessionTimeoutInMinutes {
-				return flag
-			}
+//TokenValidation validates sent token with the sessions created by the plugin and returns the
+//user name of its session, the expired session of the token is removed. The timeout of the
+//session is restarted unless RefreshSessionOnActivity is disabled.
+func TokenValidation(token string) (string, bool) {
+	session, err := capmodel.GetSessionByToken(token)
+	if err != nil {
+		if !errors.Is(err, db.ErrorKeyNotFound) {
+			log.Error("while trying to validate the session token, got: " + err.Error())
+		}
+		return "", false
+	}
+	if sessionExpired(session) {
+		expireSession(session)
+		return "", false
+	}
+	if *pluginConfig.Data.RefreshSessionOnActivity {
+		session.LastUsed = time.Now().UTC()
+		if err := capmodel.UpdateSession(session, sessionTimeout()); err != nil {
+			log.Error("while trying to refresh the session " + session.ID + ", got: " + err.Error())
 		}
 	}
-	return flag
+	return session.UserName, true
 }
 
 //Validate does Basic authentication with device and returns UUID of device in response
@@ -56,7 +67,7 @@ func Validate(ctx iris.Context) {
 	token := ctx.GetHeader("X-Auth-Token")
 	//Validating the token
 	if token != "" {
-		flag := TokenValidation(token)
+		_, flag := TokenValidation(token)
 		if !flag {
 			log.Error("Invalid/Expired X-Auth-Token")
 			ctx.StatusCode(http.StatusUnauthorized)
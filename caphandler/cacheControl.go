@@ -0,0 +1,49 @@
+//(C) Copyright [2020] Hewlett Packard Enterprise Development LP
+//
+//Licensed under the Apache License, Version 2.0 (the "License"); you may
+//not use this file except in compliance with the License. You may obtain
+//a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+//Unless required by applicable law or agreed to in writing, software
+//distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+//WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+//License for the specific language governing permissions and limitations
+// under the License.
+
+//Package caphandler ...
+package caphandler
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/ODIM-Project/PluginCiscoACI/config"
+	iris "github.com/kataras/iris/v12"
+)
+
+// setReadCacheControl allows the clients and the caches to reuse the read response for maxAge
+func setReadCacheControl(ctx iris.Context, maxAge time.Duration) {
+	ctx.Header("Cache-Control", "max-age="+strconv.Itoa(int(maxAge/time.Second)))
+}
+
+// setWriteCacheControl forbids the clients and the caches to store the response of a write
+func setWriteCacheControl(ctx iris.Context) {
+	ctx.Header("Cache-Control", "no-store")
+}
+
+// readCacheMaxAge returns the configured max-age of the responses read from the stored resources
+func readCacheMaxAge() time.Duration {
+	return time.Duration(config.Data.ServerConf.CacheMaxAgeInSeconds) * time.Second
+}
+
+// portCacheMaxAge returns the max-age of the port response, the port enriched with the
+// attributes read from APIC isn't reused beyond the time the health read from APIC is
+func portCacheMaxAge(enriched bool) time.Duration {
+	maxAge := readCacheMaxAge()
+	if enriched && maxAge > switchPortsHealthTTL {
+		return switchPortsHealthTTL
+	}
+	return maxAge
+}
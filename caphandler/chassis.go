@@ -29,7 +29,7 @@ import (
 
 // GetChassisCollection collects all the chassis details which are managed by plugin
 func GetChassisCollection(ctx iris.Context) {
-	uri := ctx.Request().RequestURI
+	uri := ctx.Path()
 	var members []*model.Link
 	chassisData, err := capmodel.GetAllSwitchChassis("")
 	if err != nil {
@@ -57,7 +57,7 @@ func GetChassisCollection(ctx iris.Context) {
 
 // GetChassis collects retrives the specific  chassis details which is managed by plugin
 func GetChassis(ctx iris.Context) {
-	uri := ctx.Request().RequestURI
+	uri := ctx.Path()
 	chassisID := ctx.Params().Get("id")
 	data, err := capmodel.GetSwitchChassis(chassisID)
 	if err != nil {
@@ -83,6 +83,6 @@ func ChassisMethodNotAllowed(ctx iris.Context) {
 			},
 		},
 	}
-	ctx.JSON(errArgs.CreateGenericErrorResponse())
+	writeErrorResponse(ctx, errArgs.CreateGenericErrorResponse())
 	return
 }
@@ -0,0 +1,94 @@
+//(C) Copyright [2020] Hewlett Packard Enterprise Development LP
+//
+//Licensed under the Apache License, Version 2.0 (the "License"); you may
+//not use this file except in compliance with the License. You may obtain
+//a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+//Unless required by applicable law or agreed to in writing, software
+//distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+//WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+//License for the specific language governing permissions and limitations
+// under the License.
+
+//Package caphandler ...
+package caphandler
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/ODIM-Project/ODIM/lib-dmtf/model"
+	"github.com/ODIM-Project/ODIM/lib-utilities/response"
+	"github.com/ODIM-Project/PluginCiscoACI/capresponse"
+	iris "github.com/kataras/iris/v12"
+	log "github.com/sirupsen/logrus"
+)
+
+// streamedMembers is the start of the members array in the collection marshalled without members,
+// the members are streamed after it
+var streamedMembers = []byte(`"Members":[`)
+
+// byteCounter counts the bytes written to it
+type byteCounter int
+
+func (c *byteCounter) Write(p []byte) (int, error) {
+	*c += byteCounter(len(p))
+	return len(p), nil
+}
+
+// streamCollection writes the collection as JSON, the count members returned by member are
+// encoded one by one as the response is written, so that the members are not held in memory.
+// HEAD is answered with the Content-Length of the body encoded the same way, without the body.
+func streamCollection(ctx iris.Context, collection capresponse.CollectionResponse, count int, member func(int) *model.Link) {
+	collection.Members = []*model.Link{}
+	data, err := json.Marshal(collection)
+	index := bytes.Index(data, streamedMembers)
+	if err == nil && index < 0 {
+		err = fmt.Errorf("no members in %s", data)
+	}
+	if err != nil {
+		errMsg := fmt.Sprintf("failed to encode collection %s: %s", collection.ODataID, err.Error())
+		log.Error(errMsg)
+		ctx.StatusCode(http.StatusInternalServerError)
+		writeErrorResponse(ctx, updateErrorResponse(response.InternalError, errMsg, nil))
+		return
+	}
+	ctx.ContentType("application/json")
+	index += len(streamedMembers)
+	if ctx.Method() == http.MethodHead {
+		var length byteCounter
+		writeCollectionMembers(&length, data[:index], data[index:], count, member)
+		ctx.Header("Content-Length", strconv.Itoa(int(length)))
+		ctx.StatusCode(http.StatusOK)
+		return
+	}
+	ctx.StatusCode(http.StatusOK)
+	if err := writeCollectionMembers(ctx.ResponseWriter(), data[:index], data[index:], count, member); err != nil {
+		log.Error("while streaming collection " + collection.ODataID + ", got: " + err.Error())
+	}
+}
+
+// writeCollectionMembers writes the collection encoded without members, split in head and tail
+// where the members are, with the count members returned by member in between
+func writeCollectionMembers(writer io.Writer, head, tail []byte, count int, member func(int) *model.Link) error {
+	if _, err := writer.Write(head); err != nil {
+		return err
+	}
+	encoder := json.NewEncoder(writer)
+	for i := 0; i < count; i++ {
+		if i > 0 {
+			writer.Write([]byte(","))
+		}
+		if err := encoder.Encode(member(i)); err != nil {
+			return err
+		}
+	}
+	_, err := writer.Write(tail)
+	return err
+}
@@ -18,8 +18,14 @@ package caphandler
 import (
 	"encoding/base64"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/ODIM-Project/ODIM/lib-dmtf/model"
+	"github.com/ODIM-Project/ODIM/lib-utilities/response"
 	"github.com/ODIM-Project/PluginCiscoACI/capmodel"
+	"github.com/ODIM-Project/PluginCiscoACI/capresponse"
 	pluginConfig "github.com/ODIM-Project/PluginCiscoACI/config"
+	"github.com/ODIM-Project/PluginCiscoACI/db"
 	iris "github.com/kataras/iris/v12"
 	"github.com/satori/go.uuid"
 	log "github.com/sirupsen/logrus"
@@ -29,16 +35,10 @@ import (
 	"time"
 )
 
-//TokenMap is used to define the plugin generated tokens
-type TokenMap struct {
-	Token    string
-	LastUsed time.Time
-}
-
-var tokenDetails []TokenMap
-var tokenSpec TokenMap
+const sessionsURI = "/ODIM/v1/SessionService/Sessions"
 
-//CreateSession is used to create session for odimra to interact with plugin
+//CreateSession is used to create session for odimra to interact with plugin, the session token
+//is returned in the X-Auth-Token header and expires after SessionTimeoutInMinutes
 func CreateSession(ctx iris.Context) {
 	var userCreds capmodel.Users
 	rawBodyAsBytes, err := ioutil.ReadAll(ctx.Request().Body)
@@ -47,13 +47,15 @@ func CreateSession(ctx iris.Context) {
 		log.Error(errorMessage)
 		ctx.StatusCode(http.StatusBadRequest)
 		ctx.WriteString(errorMessage)
+		return
 	}
 	err = json.Unmarshal(rawBodyAsBytes, &userCreds)
 	if err != nil {
 		errorMessage := "while trying to unmarshal user details, PluginCiscoACI got: " + err.Error()
 		log.Error(errorMessage)
-		ctx.StatusCode(http.StatusInternalServerError)
+		ctx.StatusCode(http.StatusBadRequest)
 		ctx.WriteString(errorMessage)
+		return
 	}
 	//Validate the credentials
 	userName := userCreds.Username
@@ -68,14 +70,123 @@ func CreateSession(ctx iris.Context) {
 	}
 	//Create token
 	token := createToken()
-	currentTime := time.Now()
-	tokenSpec.Token = token
-	tokenSpec.LastUsed = currentTime
-	tokenDetails = append(tokenDetails, tokenSpec)
+	currentTime := time.Now().UTC()
+	session := &capmodel.Session{
+		ID:          uuid.NewV4().String(),
+		UserName:    userName,
+		TokenHash:   capmodel.SessionTokenHash(token),
+		CreatedTime: currentTime,
+		LastUsed:    currentTime,
+	}
+	if err := capmodel.SaveSession(session, sessionTimeout()); err != nil {
+		createDbErrResp(ctx, err, "while trying to save the session, got: "+err.Error(), nil)
+		return
+	}
 	ctx.StatusCode(http.StatusCreated)
 	ctx.Header("X-Auth-Token", token)
+	ctx.Header("Location", sessionURI(session.ID))
+	ctx.JSON(sessionResponse(session))
+}
+
+//GetSessionCollection returns the sessions which have not expired, the expired sessions are removed by the DB
+func GetSessionCollection(ctx iris.Context) {
+	sessionIDs, err := capmodel.GetAllSessionIDs()
+	if err != nil {
+		createDbErrResp(ctx, err, "while trying to collect the sessions, got: "+err.Error(), nil)
+		return
+	}
+	members := []*model.Link{}
+	for _, sessionID := range sessionIDs {
+		members = append(members, &model.Link{Oid: sessionURI(sessionID)})
+	}
+	ctx.StatusCode(http.StatusOK)
+	ctx.JSON(model.Collection{
+		ODataContext: "/ODIM/v1/$metadata#SessionCollection.SessionCollection",
+		ODataID:      sessionsURI,
+		ODataType:    "#SessionCollection.SessionCollection",
+		Description:  "Sessions view",
+		Name:         "Sessions",
+		Members:      members,
+		MembersCount: len(members),
+	})
+}
+
+//GetSession returns the session, 404 is returned when it doesn't exist or has expired
+func GetSession(ctx iris.Context) {
+	sessionID := ctx.Params().Get("id")
+	session, ok := getActiveSession(ctx, sessionID)
+	if !ok {
+		return
+	}
+	ctx.StatusCode(http.StatusOK)
+	ctx.JSON(sessionResponse(session))
+}
+
+//DeleteSession logs the session out, its token is no longer accepted
+func DeleteSession(ctx iris.Context) {
+	sessionID := ctx.Params().Get("id")
+	session, ok := getActiveSession(ctx, sessionID)
+	if !ok {
+		return
+	}
+	if err := capmodel.DeleteSession(session); err != nil {
+		createDbErrResp(ctx, err, "while trying to delete the session, got: "+err.Error(), nil)
+		return
+	}
+	log.Info("session " + sessionID + " of " + session.UserName + " logged out")
+	ctx.StatusCode(http.StatusNoContent)
 }
 
+// getActiveSession reads the session and writes the error response when it doesn't exist or has expired
+func getActiveSession(ctx iris.Context, sessionID string) (*capmodel.Session, bool) {
+	session, err := capmodel.GetSession(sessionID)
+	if err == nil && sessionExpired(session) {
+		expireSession(session)
+		err = fmt.Errorf("%w: session %s has expired", db.ErrorKeyNotFound, sessionID)
+	}
+	if err != nil {
+		createDbErrResp(ctx, err, "while trying to get the session, got: "+err.Error(), []interface{}{"Session", sessionID})
+		return nil, false
+	}
+	return session, true
+}
+
+// sessionExpired reports whether SessionTimeoutInMinutes has elapsed since the session was last used,
+// the last use is not updated when RefreshSessionOnActivity is disabled
+func sessionExpired(session *capmodel.Session) bool {
+	return time.Since(session.LastUsed).Minutes() > pluginConfig.Data.SessionTimeoutInMinutes
+}
+
+// expireSession removes the expired session, a failure is only logged as the session
+// is anyway rejected on every use
+func expireSession(session *capmodel.Session) {
+	if err := capmodel.DeleteSession(session); err != nil && !errors.Is(err, db.ErrorKeyNotFound) {
+		log.Error("while trying to remove the expired session " + session.ID + ", got: " + err.Error())
+		return
+	}
+	log.Info("session " + session.ID + " of " + session.UserName + " has expired")
+}
+
+// sessionTimeout returns the time after which the DB removes the sessions not refreshed
+func sessionTimeout() time.Duration {
+	return time.Duration(pluginConfig.Data.SessionTimeoutInMinutes * float64(time.Minute))
+}
+
+func sessionURI(sessionID string) string {
+	return sessionsURI + "/" + sessionID
+}
+
+func sessionResponse(session *capmodel.Session) capresponse.Session {
+	return capresponse.Session{
+		OdataContext: "/ODIM/v1/$metadata#Session.Session",
+		OdataID:      sessionURI(session.ID),
+		OdataType:    "#Session.v1_3_0.Session",
+		ID:           session.ID,
+		Name:         "User Session",
+		UserName:     session.UserName,
+		CreatedTime:  session.CreatedTime.Format(time.RFC3339),
+	}
+}
 func validate(userName, password string) bool {
 	//var err error
 	username := pluginConfig.Data.PluginConf.UserName
@@ -91,7 +202,6 @@ func validate(userName, password string) bool {
 		log.Error("username/password does not match")
 		return false
 	}
-
 	return true
 }
 
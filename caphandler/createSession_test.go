@@ -0,0 +1,158 @@
+//(C) Copyright [2020] Hewlett Packard Enterprise Development LP
+//
+//Licensed under the Apache License, Version 2.0 (the "License"); you may
+//not use this file except in compliance with the License. You may obtain
+//a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+//Unless required by applicable law or agreed to in writing, software
+//distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+//WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+//License for the specific language governing permissions and limitations
+// under the License.
+
+package caphandler
+
+import (
+	"encoding/base64"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/ODIM-Project/PluginCiscoACI/capmiddleware"
+	"github.com/ODIM-Project/PluginCiscoACI/capmodel"
+	"github.com/ODIM-Project/PluginCiscoACI/config"
+	"github.com/ODIM-Project/PluginCiscoACI/db"
+	iris "github.com/kataras/iris/v12"
+	"github.com/kataras/iris/v12/httptest"
+	"golang.org/x/crypto/sha3"
+)
+
+func mockSessionApp(t *testing.T) *httptest.Expect {
+	config.SetUpMockConfig(t)
+	db.Connector = db.NewMockMemoryConnector()
+	hash := sha3.New512()
+	hash.Write([]byte("Session@123"))
+	config.Data.PluginConf.Password = base64.URLEncoding.EncodeToString(hash.Sum(nil))
+	capmiddleware.TokenValidator = TokenValidation
+	t.Cleanup(func() { capmiddleware.TokenValidator = nil })
+	mockApp := iris.New()
+	pluginRoutes := mockApp.Party("/ODIM/v1")
+	pluginRoutes.Post("/SessionService/Sessions", CreateSession)
+	pluginRoutes.Get("/SessionService/Sessions", capmiddleware.BasicAuth, GetSessionCollection)
+	pluginRoutes.Get("/SessionService/Sessions/{id}", capmiddleware.BasicAuth, GetSession)
+	pluginRoutes.Delete("/SessionService/Sessions/{id}", capmiddleware.BasicAuth, DeleteSession)
+	return httptest.New(t, mockApp)
+}
+
+// createTestSession creates a session and returns its token and uri
+func createTestSession(e *httptest.Expect) (string, string) {
+	resp := e.POST(sessionsURI).WithJSON(capmodel.Users{Username: "admin", Password: "Session@123"}).
+		Expect().Status(http.StatusCreated)
+	return resp.Header("X-Auth-Token").NotEmpty().Raw(), resp.Header("Location").NotEmpty().Raw()
+}
+
+// ageTestSession moves the last use of the session of the token back by age
+func ageTestSession(t *testing.T, token string, age time.Duration) {
+	session, err := capmodel.GetSessionByToken(token)
+	if err != nil {
+		t.Fatalf("GetSessionByToken() error = %v", err)
+	}
+	session.LastUsed = session.LastUsed.Add(-age)
+	if err := capmodel.UpdateSession(session, 30*time.Minute); err != nil {
+		t.Fatalf("UpdateSession() error = %v", err)
+	}
+}
+
+func TestCreateSession(t *testing.T) {
+	e := mockSessionApp(t)
+	resp := e.POST(sessionsURI).WithJSON(capmodel.Users{Username: "admin", Password: "Session@123"}).
+		Expect().Status(http.StatusCreated)
+	token := resp.Header("X-Auth-Token").NotEmpty().Raw()
+	body := resp.JSON().Object()
+	body.Value("UserName").Equal("admin")
+	body.Value("@odata.id").Equal(resp.Header("Location").Raw())
+
+	session, err := capmodel.GetSessionByToken(token)
+	if err != nil {
+		t.Fatalf("GetSessionByToken() error = %v", err)
+	}
+	if session.TokenHash == token {
+		t.Error("session token is stored in clear")
+	}
+
+	e.POST(sessionsURI).WithJSON(capmodel.Users{Username: "admin", Password: "Wrong@123"}).
+		Expect().Status(http.StatusUnauthorized).Header("X-Auth-Token").Empty()
+}
+
+func TestSessionUseBeforeExpiry(t *testing.T) {
+	e := mockSessionApp(t)
+	token, uri := createTestSession(e)
+	ageTestSession(t, token, 29*time.Minute)
+
+	e.GET(uri).WithHeader("X-Auth-Token", token).Expect().Status(http.StatusOK).
+		JSON().Object().Value("@odata.id").Equal(uri)
+	e.GET(sessionsURI).WithHeader("X-Auth-Token", token).Expect().Status(http.StatusOK).
+		JSON().Object().Value("Members@odata.count").Equal(1)
+}
+
+func TestSessionUseAfterExpiry(t *testing.T) {
+	e := mockSessionApp(t)
+	token, uri := createTestSession(e)
+	ageTestSession(t, token, 31*time.Minute)
+
+	e.GET(uri).WithHeader("X-Auth-Token", token).Expect().Status(http.StatusUnauthorized)
+	if _, err := capmodel.GetSessionByToken(token); !errors.Is(err, db.ErrorKeyNotFound) {
+		t.Errorf("expired session is still stored, GetSessionByToken() error = %v", err)
+	}
+}
+
+func TestSessionRefreshOnActivity(t *testing.T) {
+	e := mockSessionApp(t)
+	token, uri := createTestSession(e)
+	ageTestSession(t, token, 20*time.Minute)
+
+	// the sessions in use are refreshed by default
+	e.GET(uri).WithHeader("X-Auth-Token", token).Expect().Status(http.StatusOK)
+	ageTestSession(t, token, 20*time.Minute)
+	e.GET(uri).WithHeader("X-Auth-Token", token).Expect().Status(http.StatusOK)
+
+	// without refresh the session expires 30 minutes after its creation
+	refresh := false
+	config.Data.RefreshSessionOnActivity = &refresh
+	token, uri = createTestSession(e)
+	ageTestSession(t, token, 20*time.Minute)
+	e.GET(uri).WithHeader("X-Auth-Token", token).Expect().Status(http.StatusOK)
+	ageTestSession(t, token, 11*time.Minute)
+	e.GET(uri).WithHeader("X-Auth-Token", token).Expect().Status(http.StatusUnauthorized)
+}
+
+func TestDeleteSession(t *testing.T) {
+	e := mockSessionApp(t)
+	token, uri := createTestSession(e)
+	otherToken, otherURI := createTestSession(e)
+
+	e.DELETE(uri).WithHeader("X-Auth-Token", token).Expect().Status(http.StatusNoContent)
+	e.GET(otherURI).WithHeader("X-Auth-Token", token).Expect().Status(http.StatusUnauthorized)
+	e.GET(uri).WithHeader("X-Auth-Token", otherToken).Expect().Status(http.StatusNotFound)
+	e.DELETE(uri).WithHeader("X-Auth-Token", otherToken).Expect().Status(http.StatusNotFound)
+	e.GET(otherURI).WithHeader("X-Auth-Token", otherToken).Expect().Status(http.StatusOK)
+}
+
+func TestSessionRemovedByDB(t *testing.T) {
+	e := mockSessionApp(t)
+	token, _ := createTestSession(e)
+	config.Data.SessionTimeoutInMinutes = 0.001
+	otherToken, _ := createTestSession(e)
+	time.Sleep(100 * time.Millisecond)
+	config.Data.SessionTimeoutInMinutes = 30
+
+	// the sessions are removed by the DB on their timeout, without scanning them on the requests
+	e.GET(sessionsURI).WithHeader("X-Auth-Token", token).Expect().Status(http.StatusOK).
+		JSON().Object().Value("Members@odata.count").Equal(1)
+	if _, err := capmodel.GetSessionByToken(otherToken); !errors.Is(err, db.ErrorKeyNotFound) {
+		t.Errorf("GetSessionByToken() of the timed out session error = %v, want %v", err, db.ErrorKeyNotFound)
+	}
+}
@@ -0,0 +1,380 @@
+//(C) Copyright [2020] Hewlett Packard Enterprise Development LP
+//
+//Licensed under the Apache License, Version 2.0 (the "License"); you may
+//not use this file except in compliance with the License. You may obtain
+//a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+//Unless required by applicable law or agreed to in writing, software
+//distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+//WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+//License for the specific language governing permissions and limitations
+// under the License.
+
+//Package caphandler ...
+package caphandler
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"path"
+	"strconv"
+	"strings"
+	"sync"
+
+	dmtfmodel "github.com/ODIM-Project/ODIM/lib-dmtf/model"
+	"github.com/ODIM-Project/ODIM/lib-utilities/response"
+	"github.com/ODIM-Project/PluginCiscoACI/capdata"
+	"github.com/ODIM-Project/PluginCiscoACI/capmodel"
+	"github.com/ODIM-Project/PluginCiscoACI/capresponse"
+	"github.com/ODIM-Project/PluginCiscoACI/caputilities"
+	"github.com/ODIM-Project/PluginCiscoACI/config"
+	"github.com/ODIM-Project/PluginCiscoACI/db"
+	"github.com/ciscoecosystem/aci-go-client/models"
+	iris "github.com/kataras/iris/v12"
+	uuid "github.com/satori/go.uuid"
+	log "github.com/sirupsen/logrus"
+)
+
+var (
+	// discoveryLock serializes the discovery of the fabrics with the rebuild of their stored data
+	discoveryLock sync.Mutex
+	// discoverFabrics is the discovery used by the rebuild, replaced in the tests
+	discoverFabrics = discoverACIData
+)
+
+// DiscoverACIData reads required fabric,switch and port data from aci and stores it in the data store
+func DiscoverACIData() error {
+	discoveryLock.Lock()
+	defer discoveryLock.Unlock()
+	// fabrics cached before the discovery are read again from the DB
+	capmodel.InvalidateFabricCache()
+	_, err := discoverACIData("")
+	return err
+}
+
+// repairPortsAction is the path of the fabric action removing the ports listed more than once for a switch
+const repairPortsAction = "Actions/Oem/CiscoACIFabric.RepairPorts"
+
+// RebuildFabric removes the fabric, its switches, their chassis and ports from the DB and discovers
+// them again from APIC. The zones, address pools and endpoints of the fabric are left as they are.
+// The number of objects removed and recreated is returned.
+func RebuildFabric(ctx iris.Context) {
+	uri := ctx.Path()
+	fabricID := ctx.Params().Get("id")
+	discoveryLock.Lock()
+	defer discoveryLock.Unlock()
+	if _, err := capmodel.GetFabric(fabricID); err != nil {
+		errMsg := fmt.Sprintf("failed to fetch fabric data for uri %s: %s", uri, err.Error())
+		createDbErrResp(ctx, err, errMsg, []interface{}{"Fabric", fabricID})
+		return
+	}
+	removed, err := flushFabric(fabricID)
+	if err != nil {
+		errMsg := fmt.Sprintf("failed to remove the data of fabric %s after removing %d objects: %s", fabricID, removed, err.Error())
+		createDbErrResp(ctx, err, errMsg, []interface{}{"Fabric", fabricID})
+		return
+	}
+	recreated, err := discoverFabrics(fabricID)
+	if err != nil {
+		errMsg := fmt.Sprintf("failed to discover fabric %s from APIC after removing %d objects: %s", fabricID, removed, err.Error())
+		log.Error(errMsg)
+		resp := updateErrorResponse(response.GeneralError, errMsg, nil)
+		ctx.StatusCode(http.StatusInternalServerError)
+		writeErrorResponse(ctx, resp)
+		return
+	}
+	log.Info(fmt.Sprintf("rebuilt fabric %s, removed %d and recreated %d objects", fabricID, removed, recreated))
+	ctx.StatusCode(http.StatusOK)
+	ctx.JSON(capresponse.FabricRebuildResponse{
+		FabricID:  fabricID,
+		Removed:   removed,
+		Recreated: recreated,
+	})
+}
+
+// RepairFabricPorts removes the ports listed more than once from the switch-port data of the switches
+// of the fabric, the number of duplicates removed is returned
+func RepairFabricPorts(ctx iris.Context) {
+	uri := ctx.Path()
+	fabricID := ctx.Params().Get("id")
+	fabricData, err := capmodel.GetFabric(fabricID)
+	if err != nil {
+		errMsg := fmt.Sprintf("failed to fetch fabric data for uri %s: %s", uri, err.Error())
+		createDbErrResp(ctx, err, errMsg, []interface{}{"Fabric", fabricID})
+		return
+	}
+	removed := 0
+	for _, switchID := range fabricData.SwitchData {
+		duplicates, err := capmodel.RemoveDuplicateSwitchPorts(switchID)
+		if err != nil {
+			errMsg := fmt.Sprintf("failed to repair the ports of switch %s after removing %d duplicates: %s", switchID, removed, err.Error())
+			createDbErrResp(ctx, err, errMsg, []interface{}{"Switch", switchID})
+			return
+		}
+		if duplicates > 0 {
+			log.Warn(fmt.Sprintf("removed %d ports listed more than once for switch %s", duplicates, switchID))
+		}
+		removed += duplicates
+	}
+	ctx.StatusCode(http.StatusOK)
+	ctx.JSON(capresponse.FabricRepairPortsResponse{
+		FabricID:          fabricID,
+		DuplicatesRemoved: removed,
+	})
+}
+
+// flushFabric removes the fabric with its switches, their chassis and ports from the DB,
+// the number of objects removed is returned
+func flushFabric(fabricID string) (int, error) {
+	fabricData, err := capmodel.GetFabric(fabricID)
+	if err != nil {
+		return 0, err
+	}
+	removed := 0
+	for _, switchID := range fabricData.SwitchData {
+		ports, err := capmodel.DeleteSwitchPorts(fabricID, switchID)
+		removed += ports
+		if err != nil && !errors.Is(err, db.ErrorKeyNotFound) {
+			return removed, err
+		}
+		switchData, err := capmodel.GetSwitch(switchID)
+		if err != nil {
+			if errors.Is(err, db.ErrorKeyNotFound) {
+				continue
+			}
+			return removed, err
+		}
+		if switchData.Links != nil && switchData.Links.Chassis != nil {
+			if err := capmodel.DeleteSwitchChassis(path.Base(switchData.Links.Chassis.Oid)); err != nil {
+				return removed, err
+			}
+			removed++
+		}
+		if err := capmodel.DeleteSwitch(switchID); err != nil {
+			return removed, err
+		}
+		removed++
+	}
+	if err := capmodel.DeleteFabric(fabricID); err != nil {
+		return removed, err
+	}
+	return removed + 1, nil
+}
+
+// discoverACIData stores the fabrics, switches, chassis and ports discovered from APIC which are
+// not already stored. Only the given fabric is discovered when fabricID is not empty.
+// The number of objects stored is returned.
+func discoverACIData(fabricID string) (int, error) {
+	aciNodesData, err := caputilities.GetFabricNodeData()
+	if err != nil {
+		return 0, fmt.Errorf("while reading the fabric nodes from APIC, got: %v", err)
+	}
+	created := 0
+	for _, aciNodeData := range aciNodesData {
+		nodeFabricID := config.Data.RootServiceUUID + ":" + aciNodeData.FabricId
+		if fabricID != "" && nodeFabricID != fabricID {
+			continue
+		}
+		switchID, err := capmodel.CanonicalSwitchID(uuid.NewV4().String() + ":" + aciNodeData.NodeId)
+		if err != nil {
+			return created, fmt.Errorf("while reading node %s of fabric %s from APIC, got: %w", aciNodeData.NodeId, nodeFabricID, err)
+		}
+		fabricExists := true
+		fabricData, err := capmodel.GetFabric(nodeFabricID)
+		if err != nil {
+			if !errors.Is(err, db.ErrorKeyNotFound) {
+				return created, fmt.Errorf("fetching %s fabric failed with %w", nodeFabricID, err)
+			}
+			fabricExists = false
+			data := &capdata.Fabric{
+				SwitchData: []string{
+					switchID,
+				},
+				PodID: aciNodeData.PodId,
+			}
+			if err := capmodel.SaveFabric(nodeFabricID, data); err != nil {
+				return created, fmt.Errorf("storing %s fabric failed with %w", nodeFabricID, err)
+			}
+			created++
+		}
+		if checkSwitchIDExists(fabricData.SwitchData, capmodel.SwitchNodeID(switchID)) {
+			continue
+		}
+		if fabricExists {
+			fabricData.SwitchData = append(fabricData.SwitchData, switchID)
+			fabricData.PodID = aciNodeData.PodId
+			if err := capmodel.UpdateFabric(nodeFabricID, &fabricData); err != nil {
+				return created, fmt.Errorf("updating %s fabric failed with %w", nodeFabricID, err)
+			}
+		}
+		switchData, chassisData, err := getSwitchData(nodeFabricID, aciNodeData, switchID)
+		if err != nil {
+			return created, err
+		}
+		if err := capmodel.SaveSwitchChassis(chassisData.ID, chassisData); err != nil {
+			return created, fmt.Errorf("storing %s chassis failed with %w", chassisData.ID, err)
+		}
+		if err := capmodel.SaveSwitch(switchID, switchData); err != nil {
+			return created, fmt.Errorf("storing %s switch failed with %w", switchID, err)
+		}
+		created += 2
+		// adding logic to collect the ports data
+		portData, err := caputilities.GetPortData(aciNodeData.PodId, aciNodeData.NodeId)
+		if err != nil {
+			return created, fmt.Errorf("while reading the ports of node %s from APIC, got: %v", aciNodeData.NodeId, err)
+		}
+		ports, err := parsePortData(portData, switchID, nodeFabricID, aciNodeData.PodId, aciNodeData.NodeId)
+		created += ports
+		if err != nil {
+			return created, err
+		}
+	}
+	return created, nil
+}
+
+// parsePortData parses the portData and adds the ports to the switch in the DB, the number of ports stored is returned
+func parsePortData(portResponseData *capmodel.PortCollectionResponse, switchID, fabricID, podID, nodeID string) (int, error) {
+	var stored int
+	var existenceCheck capmodel.PortExistenceCheck
+	if config.Data.APICConf.VerifyPortExistence {
+		existenceCheck = caputilities.PortExistenceCheck(podID, nodeID)
+	}
+	for _, imdata := range portResponseData.IMData {
+		portAttributes := imdata.PhysicalInterface.Attributes
+		apicPortID, err := caputilities.AttributeString(portAttributes, "id")
+		if err != nil {
+			log.Error("skipping port of switch " + switchID + ": " + err.Error())
+			continue
+		}
+		id := strings.Replace(apicPortID, "/", "-", -1)
+		portID := uuid.NewV4().String() + ":" + id
+		portInfo := dmtfmodel.Port{
+			ODataContext:          "/ODIM/v1/$metadata#Port.Port",
+			ODataType:             portODataType,
+			ODataID:               fmt.Sprintf("/ODIM/v1/Fabrics/%s/Switches/%s/Ports/%s", fabricID, switchID, portID),
+			ID:                    portID,
+			Name:                  "Port-" + apicPortID,
+			PortID:                apicPortID,
+			PortProtocol:          "Ethernet",
+			PortType:              "BidirectionalPort",
+			LinkNetworkTechnology: "Ethernet",
+		}
+		mtuValue, _ := portAttributes["mtu"].(string)
+		mtu, err := strconv.Atoi(mtuValue)
+		if err != nil {
+			log.Error("Unable to get mtu for the port" + portID)
+		}
+		portInfo.MaxFrameSize = mtu
+		saved, err := capmodel.AddPortToSwitchIfExists(switchID, &portInfo, existenceCheck)
+		if err != nil {
+			return stored, fmt.Errorf("storing %s port failed with %w", portInfo.ODataID, err)
+		}
+		if !saved {
+			log.Warn("port " + portInfo.PortID + " is no longer present in APIC, skipped storing it")
+			continue
+		}
+		stored++
+		if adminState := config.Data.APICConf.DefaultPortAdminState; adminState != "" {
+			if _, err := capmodel.RecordPortAdminState(portInfo.ODataID, adminState); err != nil {
+				return stored, fmt.Errorf("recording the admin state of %s port failed with %w", portInfo.ODataID, err)
+			}
+		}
+	}
+	return stored, nil
+}
+
+func getSwitchData(fabricID string, fabricNodeData *models.FabricNodeMember, switchID string) (*dmtfmodel.Switch, *dmtfmodel.Chassis, error) {
+	var switchData = dmtfmodel.Switch{
+		ODataContext: "/ODIM/v1/$metadata#Switch.Switch",
+		ODataType:    switchODataType,
+		ODataID:      "/ODIM/v1/Fabrics/" + fabricID + "/Switches/" + switchID,
+		ID:           switchID,
+		Name:         fabricNodeData.Name,
+		SwitchType:   "Ethernet",
+		UUID:         capmodel.SwitchUUID(switchID),
+		SerialNumber: fabricNodeData.Serial,
+	}
+	podID, err := strconv.Atoi(fabricNodeData.PodId)
+	if err != nil {
+		return nil, nil, fmt.Errorf("Converstion of PODID %s failed", fabricNodeData.PodId)
+	}
+	nodeID, err := strconv.Atoi(fabricNodeData.NodeId)
+	if err != nil {
+		return nil, nil, fmt.Errorf("Converstion of NodeID %s failed", fabricNodeData.NodeId)
+	}
+	log.Info("Getting the switchData for NodeID" + fabricNodeData.NodeId)
+	switchRespData, err := caputilities.GetSwitchInfo(podID, nodeID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("Unable to get the Switch info: %v", err)
+	}
+	switchData.FirmwareVersion = switchRespData.SystemAttributes.Version
+	switchChassisData, healthChassisData, err := caputilities.GetSwitchChassisInfo(fabricNodeData.PodId, fabricNodeData.NodeId)
+	if err != nil {
+		return nil, nil, fmt.Errorf("Unable to get the Switch Chassis info for node %s: %v", fabricNodeData.NodeId, err)
+	}
+	if len(switchChassisData.IMData) == 0 || len(healthChassisData.IMData) == 0 {
+		return nil, nil, fmt.Errorf("%w: no chassis of node %s in response", caputilities.ErrAPICResponseMalformed, fabricNodeData.NodeId)
+	}
+	chassisAttributes := map[string]string{}
+	for _, name := range []string{"vendor", "model", "id", "ser", "operSt"} {
+		value, err := caputilities.AttributeString(switchChassisData.IMData[0].SwitchChassisData.Attributes, name)
+		if err != nil {
+			return nil, nil, fmt.Errorf("Unable to get the Switch Chassis info for node %s: %v", fabricNodeData.NodeId, err)
+		}
+		chassisAttributes[name] = value
+	}
+	switchData.Manufacturer = chassisAttributes["vendor"]
+	switchData.Model = chassisAttributes["model"]
+	chassisID := chassisAttributes["id"]
+	chassisUUID := uuid.NewV4().String()
+
+	//take health value
+	healthValue, err := caputilities.HealthScore(healthChassisData.IMData[0].HealthData.Attributes)
+	if err != nil {
+		return nil, nil, fmt.Errorf("Unable to get the health of chassis of node %s: %v", fabricNodeData.NodeId, err)
+	}
+	chassisHealth := healthOfScore(healthValue)
+	var chassisData = dmtfmodel.Chassis{
+		Ocontext:     "/ODIM/v1/$metadata#Chassis.Chassis",
+		Otype:        "#Chassis.v1_4_0.Chassis",
+		Oid:          "/ODIM/v1/Chassis/" + chassisUUID + ":" + chassisID,
+		ID:           chassisUUID + ":" + chassisID,
+		Name:         fabricNodeData.Name + "_chassis",
+		ChassisType:  "RackMount",
+		UUID:         chassisUUID,
+		SerialNumber: chassisAttributes["ser"],
+		Manufacturer: chassisAttributes["vendor"],
+		Model:        chassisAttributes["model"],
+		PowerState:   chassisAttributes["operSt"],
+		Status: &dmtfmodel.Status{
+			State:  "Enabled",
+			Health: chassisHealth,
+		},
+		Links: &dmtfmodel.Links{
+			Switches: []*dmtfmodel.Link{
+				&dmtfmodel.Link{
+					Oid: switchData.ODataID,
+				},
+			},
+		},
+	}
+	switchData.Links = &dmtfmodel.SwitchLinks{
+		Chassis: &dmtfmodel.Link{
+			Oid: chassisData.Oid,
+		},
+	}
+
+	return &switchData, &chassisData, nil
+}
+
+func checkSwitchIDExists(switchIDs []string, nodeID string) (exists bool) {
+	for _, switchid := range switchIDs {
+		if capmodel.SwitchNodeID(switchid) == nodeID {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,197 @@
+//(C) Copyright [2020] Hewlett Packard Enterprise Development LP
+//
+//Licensed under the Apache License, Version 2.0 (the "License"); you may
+//not use this file except in compliance with the License. You may obtain
+//a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+//Unless required by applicable law or agreed to in writing, software
+//distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+//WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+//License for the specific language governing permissions and limitations
+// under the License.
+
+package caphandler
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/ODIM-Project/ODIM/lib-dmtf/model"
+	"github.com/ODIM-Project/PluginCiscoACI/capdata"
+	"github.com/ODIM-Project/PluginCiscoACI/capmodel"
+	"github.com/ODIM-Project/PluginCiscoACI/caputilities"
+	"github.com/ODIM-Project/PluginCiscoACI/config"
+	"github.com/ODIM-Project/PluginCiscoACI/db"
+
+	iris "github.com/kataras/iris/v12"
+	"github.com/kataras/iris/v12/httptest"
+)
+
+const (
+	testRebuildURI = "/ODIM/v1/Fabrics/fabricID/Actions/Oem/CiscoACIFabric.Rebuild"
+	testChassisID  = "chassisUUID:1"
+	testSwitchURI  = "/ODIM/v1/Fabrics/fabricID/Switches/switchUUID:101"
+	testChassisURI = "/ODIM/v1/Chassis/" + testChassisID
+)
+
+func mockRebuildApp(t *testing.T) *httptest.Expect {
+	mockPortApp(t)
+	capmodel.SaveFabric(testFabricID, &capdata.Fabric{PodID: "1", SwitchData: []string{testSwitchID}})
+	capmodel.SaveSwitch(testSwitchID, &model.Switch{
+		ODataID: testSwitchURI,
+		ID:      testSwitchID,
+		Links:   &model.SwitchLinks{Chassis: &model.Link{Oid: testChassisURI}},
+	})
+	capmodel.SaveSwitchChassis(testChassisID, &model.Chassis{Oid: testChassisURI, ID: testChassisID})
+	capmodel.SavePort(testPortURI, &model.Port{ODataID: testPortURI, ID: testPortID, PortID: "eth1/1"})
+	mockApp := iris.New()
+	mockApp.Post("/ODIM/v1/Fabrics/{id}/Actions/Oem/CiscoACIFabric.Rebuild", RebuildFabric)
+	return httptest.New(t, mockApp)
+}
+
+func TestRebuildFabric(t *testing.T) {
+	e := mockRebuildApp(t)
+	discoverFabrics = func(fabricID string) (int, error) {
+		if discoveryLock.TryLock() {
+			discoveryLock.Unlock()
+			t.Error("discovery must run with the discovery lock held")
+		}
+		if fabricID != testFabricID {
+			t.Errorf("discovered fabric %s, want %s", fabricID, testFabricID)
+		}
+		for _, key := range []struct{ table, id string }{
+			{db.TableFabric, testFabricID},
+			{db.TableSwitch, testSwitchID},
+			{db.TableSwitchChassis, testChassisID},
+			{db.TablePort, testPortURI},
+			{db.TableSwitchPorts, testSwitchID},
+		} {
+			if _, err := db.Connector.Get(key.table, key.id); !errors.Is(err, db.ErrorKeyNotFound) {
+				t.Errorf("%s %s is not removed before the discovery, got: %v", key.table, key.id, err)
+			}
+		}
+		return 1, capmodel.SaveFabric(fabricID, &capdata.Fabric{PodID: "1"})
+	}
+	defer func() { discoverFabrics = discoverACIData }()
+
+	resp := e.POST(testRebuildURI).Expect().Status(http.StatusOK).JSON().Object()
+	resp.Value("FabricId").Equal(testFabricID)
+	resp.Value("Removed").Number().Equal(4)
+	resp.Value("Recreated").Number().Equal(1)
+	if count, _ := capmodel.CountPorts(testSwitchID); count != 0 {
+		t.Errorf("CountPorts() = %d after rebuild, want 0", count)
+	}
+}
+
+func TestRepairFabricPorts(t *testing.T) {
+	mockRebuildApp(t)
+	mockApp := iris.New()
+	mockApp.Post("/ODIM/v1/Fabrics/{id}/Actions/Oem/CiscoACIFabric.RepairPorts", RepairFabricPorts)
+	e := httptest.New(t, mockApp)
+	capmodel.SaveSwitchPort(testSwitchID, []string{testPortID, "p2", testPortID, "p2"})
+
+	resp := e.POST("/ODIM/v1/Fabrics/fabricID/Actions/Oem/CiscoACIFabric.RepairPorts").Expect().Status(http.StatusOK).JSON().Object()
+	resp.Value("FabricId").Equal(testFabricID)
+	resp.Value("DuplicatesRemoved").Number().Equal(2)
+	if ports, err := capmodel.GetSwitchPort(testSwitchID); err != nil || len(ports) != 2 {
+		t.Errorf("GetSwitchPort() after repair = %v, %v, want the 2 ports once", ports, err)
+	}
+	// the repaired ports are left as they are
+	e.POST("/ODIM/v1/Fabrics/fabricID/Actions/Oem/CiscoACIFabric.RepairPorts").Expect().Status(http.StatusOK).
+		JSON().Object().Value("DuplicatesRemoved").Number().Equal(0)
+	e.POST("/ODIM/v1/Fabrics/unknown/Actions/Oem/CiscoACIFabric.RepairPorts").Expect().Status(http.StatusNotFound)
+}
+
+func TestRebuildFabricNotFound(t *testing.T) {
+	e := mockRebuildApp(t)
+	discoverFabrics = func(fabricID string) (int, error) {
+		t.Error("discovery must not run for a fabric which doesn't exist")
+		return 0, nil
+	}
+	defer func() { discoverFabrics = discoverACIData }()
+
+	e.POST("/ODIM/v1/Fabrics/unknown/Actions/Oem/CiscoACIFabric.Rebuild").Expect().Status(http.StatusNotFound)
+	if _, err := capmodel.GetFabric(testFabricID); err != nil {
+		t.Errorf("fabric %s is removed by the rebuild of another fabric: %v", testFabricID, err)
+	}
+}
+
+func TestDiscoverSwitch(t *testing.T) {
+	mockRebuildApp(t)
+	const otherSwitchID = "switchUUID:102"
+	const otherPortID = "portUUID:eth1-2"
+	otherPortURI := "/ODIM/v1/Fabrics/fabricID/Switches/" + otherSwitchID + "/Ports/" + otherPortID
+	stalePortURI := "/ODIM/v1/Fabrics/fabricID/Switches/switchUUID:101/Ports/portUUID:eth1-2"
+	capmodel.SaveFabric(testFabricID, &capdata.Fabric{PodID: "1", SwitchData: []string{testSwitchID, otherSwitchID}})
+	capmodel.SaveSwitch(otherSwitchID, &model.Switch{ID: otherSwitchID})
+	capmodel.AddPortToSwitch(testSwitchID, &model.Port{ODataID: stalePortURI, ID: "portUUID:eth1-2", PortID: "eth1/2"})
+	capmodel.AddPortToSwitch(otherSwitchID, &model.Port{ODataID: otherPortURI, ID: otherPortID, PortID: "eth1/2"})
+	getSwitchPortData = func(podID, ACISwitchID string) (*capmodel.PortCollectionResponse, error) {
+		if ACISwitchID != "101" {
+			t.Errorf("ports of node %s read, want only the ports of node 101", ACISwitchID)
+		}
+		return &capmodel.PortCollectionResponse{IMData: []capmodel.PortCollectionIMData{
+			{PhysicalInterface: capmodel.PhysicalInterface{Attributes: map[string]interface{}{"id": "eth1/1", "mtu": "9000"}}},
+			{PhysicalInterface: capmodel.PhysicalInterface{Attributes: map[string]interface{}{"id": "eth1/3", "mtu": "9000"}}},
+		}}, nil
+	}
+	defer func() { getSwitchPortData = caputilities.GetPortData }()
+	mockApp := iris.New()
+	mockApp.Post("/ODIM/v1/Fabrics/{id}/Switches/{switchID}/Actions/Oem/CiscoACISwitch.Discover", DiscoverSwitch)
+	e := httptest.New(t, mockApp)
+	discoverURI := testSwitchURI + "/Actions/Oem/CiscoACISwitch.Discover"
+
+	resp := e.POST(discoverURI).Expect().Status(http.StatusOK).JSON().Object()
+	resp.Value("SwitchId").Equal(testSwitchID)
+	resp.Value("Added").Number().Equal(1)
+	resp.Value("Removed").Number().Equal(1)
+	ports, err := capmodel.GetSwitchPort(testSwitchID)
+	if err != nil || len(ports) != 2 || findSwitchPort(ports, "eth1/1") != testPortID || findSwitchPort(ports, "eth1/3") == "" {
+		t.Errorf("GetSwitchPort() after discovery = %v, %v, want %s and a new eth1/3 port", ports, err, testPortID)
+	}
+	if _, err := capmodel.GetPort(stalePortURI); !errors.Is(err, db.ErrorKeyNotFound) {
+		t.Errorf("stale port %s is not removed, got: %v", stalePortURI, err)
+	}
+	// the ports of the other switches are left as they are
+	if ports, err := capmodel.GetSwitchPort(otherSwitchID); err != nil || len(ports) != 1 || ports[0] != otherPortID {
+		t.Errorf("GetSwitchPort(%s) after discovery = %v, %v, want only %s", otherSwitchID, ports, err, otherPortID)
+	}
+	if _, err := capmodel.GetPort(otherPortURI); err != nil {
+		t.Errorf("port %s of the other switch is removed: %v", otherPortURI, err)
+	}
+
+	// discovering the switch again changes nothing
+	resp = e.POST(discoverURI).Expect().Status(http.StatusOK).JSON().Object()
+	resp.Value("Added").Number().Equal(0)
+	resp.Value("Removed").Number().Equal(0)
+	e.POST("/ODIM/v1/Fabrics/fabricID/Switches/switchUUID:103/Actions/Oem/CiscoACISwitch.Discover").Expect().Status(http.StatusNotFound)
+}
+
+func TestDiscoverSwitchDefaultPortAdminState(t *testing.T) {
+	mockRebuildApp(t)
+	config.Data.APICConf.DefaultPortAdminState = config.PortAdminStateDisabled
+	defer func() { config.Data.APICConf.DefaultPortAdminState = "" }()
+	getSwitchPortData = func(podID, ACISwitchID string) (*capmodel.PortCollectionResponse, error) {
+		return &capmodel.PortCollectionResponse{IMData: []capmodel.PortCollectionIMData{
+			{PhysicalInterface: capmodel.PhysicalInterface{Attributes: map[string]interface{}{"id": "eth1/1"}}},
+			{PhysicalInterface: capmodel.PhysicalInterface{Attributes: map[string]interface{}{"id": "eth1/3"}}},
+		}}, nil
+	}
+	defer func() { getSwitchPortData = caputilities.GetPortData }()
+
+	if added, _, err := discoverSwitchPorts(testFabricID, "1", testSwitchID); err != nil || added != 1 {
+		t.Fatalf("discoverSwitchPorts() = %d, %v, want the eth1/3 port added", added, err)
+	}
+	ports, _ := capmodel.GetSwitchPort(testSwitchID)
+	newPortURI := testSwitchURI + "/Ports/" + findSwitchPort(ports, "eth1/3")
+	if settings, err := capmodel.GetPortSettings(newPortURI); err != nil || settings.AdminState != config.PortAdminStateDisabled {
+		t.Errorf("AdminState of the new port = %q, %v, want %s", settings.AdminState, err, config.PortAdminStateDisabled)
+	}
+	// the ports already stored are left as they are
+	if settings, err := capmodel.GetPortSettings(testPortURI); err != nil || settings.AdminState != "" {
+		t.Errorf("AdminState of the existing port = %q, %v, want none", settings.AdminState, err)
+	}
+}
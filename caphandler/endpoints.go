@@ -24,6 +24,7 @@ import (
 	"github.com/ODIM-Project/ODIM/lib-utilities/common"
 	"github.com/ODIM-Project/ODIM/lib-utilities/response"
 	"github.com/ODIM-Project/PluginCiscoACI/capdata"
+	"github.com/ODIM-Project/PluginCiscoACI/capmiddleware"
 	"github.com/ODIM-Project/PluginCiscoACI/capmodel"
 	"github.com/ODIM-Project/PluginCiscoACI/caputilities"
 
@@ -35,7 +36,7 @@ import (
 
 //GetEndpointCollection : Fetches details of the given resource from the device
 func GetEndpointCollection(ctx iris.Context) {
-	uri := ctx.Request().RequestURI
+	uri := ctx.Path()
 	fabricID := ctx.Params().Get("id")
 
 	endpointData, err := capmodel.GetAllEndpoints(fabricID)
@@ -68,7 +69,7 @@ func GetEndpointCollection(ctx iris.Context) {
 //CreateEndpoint : created endpoints under given fabric
 func CreateEndpoint(ctx iris.Context) {
 	// Add logic to check if given ports exits
-	uri := ctx.Request().RequestURI
+	uri := ctx.Path()
 	fabricID := ctx.Params().Get("id")
 	fabricData, err := capmodel.GetFabric(fabricID)
 	if err != nil {
@@ -83,21 +84,21 @@ func CreateEndpoint(ctx iris.Context) {
 		log.Error(errorMessage)
 		resp := updateErrorResponse(response.MalformedJSON, errorMessage, nil)
 		ctx.StatusCode(http.StatusBadRequest)
-		ctx.JSON(resp)
+		writeErrorResponse(ctx, resp)
 		return
 	}
 	if len(endpoint.Redundancy) < 1 {
 		errMsg := fmt.Sprintf("Endpoint cannot be created, Redudancy in the request is missing: " + err.Error())
 		resp := updateErrorResponse(response.PropertyMissing, errMsg, []interface{}{"Redundancy"})
 		ctx.StatusCode(http.StatusBadRequest)
-		ctx.JSON(resp)
+		writeErrorResponse(ctx, resp)
 		return
 	}
 	if len(endpoint.Redundancy[0].RedundancySet) == 0 {
 		errMsg := fmt.Sprintf("Endpoint cannot be created, RedudancySet in the request is missing: " + err.Error())
 		resp := updateErrorResponse(response.PropertyMissing, errMsg, []interface{}{"RedudancySet"})
 		ctx.StatusCode(http.StatusBadRequest)
-		ctx.JSON(resp)
+		writeErrorResponse(ctx, resp)
 		return
 	}
 	// get all existing endpoints under fabric check for the name
@@ -112,7 +113,7 @@ func CreateEndpoint(ctx iris.Context) {
 			errMsg := "Endpoint name is already assigned to other endpoint:" + endpointData.Endpoint.Name
 			resp := updateErrorResponse(response.ResourceAlreadyExists, errMsg, []interface{}{"Endpoint", endpointData.Endpoint.Name, endpoint.Name})
 			ctx.StatusCode(http.StatusConflict)
-			ctx.JSON(resp)
+			writeErrorResponse(ctx, resp)
 			return
 		}
 	}
@@ -126,7 +127,7 @@ func CreateEndpoint(ctx iris.Context) {
 			errMsg := "Duplicate port passed in the request"
 			resp := updateErrorResponse(response.PropertyValueConflict, errMsg, []interface{}{endpoint.Redundancy[0].RedundancySet[i].Oid, endpoint.Redundancy[0].RedundancySet[i].Oid})
 			ctx.StatusCode(http.StatusBadRequest)
-			ctx.JSON(resp)
+			writeErrorResponse(ctx, resp)
 			return
 
 		}
@@ -139,13 +140,12 @@ func CreateEndpoint(ctx iris.Context) {
 		statusCode, resp := checkEndpointPortMapping(endpoint.Redundancy[0].RedundancySet[i].Oid)
 		if statusCode != http.StatusOK {
 			ctx.StatusCode(statusCode)
-			ctx.JSON(resp)
+			writeErrorResponse(ctx, resp)
 			return
 		}
 		portURIData := strings.Split(portURI, "/")
 		switchID := portURIData[6]
-		switchIDData := strings.Split(switchID, ":")
-		switchURI = switchURI + "-" + switchIDData[1]
+		switchURI = switchURI + "-" + capmodel.SwitchNodeID(switchID)
 		portIDData := strings.Split(portURIData[8], ":")
 		tmpPortPattern := strings.Replace(portIDData[1], "eth", "", -1)
 		tmpPortPattern = strings.Replace(tmpPortPattern, "-", "-ports-", -1)
@@ -157,11 +157,11 @@ func CreateEndpoint(ctx iris.Context) {
 	resp, statusCode, aciPolicyGroupData := createPolicyGroup(switchURI, portPattern)
 	if statusCode != http.StatusCreated {
 		ctx.StatusCode(statusCode)
-		ctx.JSON(resp)
+		writeErrorResponse(ctx, resp)
 		return
 	}
 
-	log.Info("Dn of Policy group:" + policyGroupDN)
+	capmiddleware.RequestLogger(ctx).Info("Dn of Policy group:" + policyGroupDN)
 	aciPolicyGroupData.PolicyGroupDN = fmt.Sprintf("topology/pod-%s/protpaths%s/pathep-[%s]", fabricData.PodID, switchURI, aciPolicyGroupData.PcVPCPolicyGroupName)
 	if err = saveEndpointData(uri, fabricID, aciPolicyGroupData, &endpoint); err != nil {
 		errMsg := fmt.Sprintf("failed to store endpoint data for uri %s: %s", uri, err.Error())
@@ -177,7 +177,7 @@ func CreateEndpoint(ctx iris.Context) {
 
 //GetEndpointInfo : gets endpoints under given fabric
 func GetEndpointInfo(ctx iris.Context) {
-	uri := ctx.Request().RequestURI
+	uri := ctx.Path()
 	fabricID := ctx.Params().Get("id")
 
 	endpointData, err := capmodel.GetEndpoints(fabricID, uri)
@@ -192,7 +192,7 @@ func GetEndpointInfo(ctx iris.Context) {
 
 //DeleteEndpointInfo : deletes  endpoints under given fabric
 func DeleteEndpointInfo(ctx iris.Context) {
-	uri := ctx.Request().RequestURI
+	uri := ctx.Path()
 	fabricID := ctx.Params().Get("id")
 
 	endpointData, err := capmodel.GetEndpoints(fabricID, uri)
@@ -207,13 +207,13 @@ func DeleteEndpointInfo(ctx iris.Context) {
 		log.Error(errMsg)
 		resp := updateErrorResponse(response.ResourceCannotBeDeleted, errMsg, []interface{}{uri, "Endpoint"})
 		ctx.StatusCode(http.StatusNotAcceptable)
-		ctx.JSON(resp)
+		writeErrorResponse(ctx, resp)
 		return
 	}
 	// Todo:Add the validation  to verify the links
 	resp, statusCode := deletePolicyGroup(endpointData.ACIPolicyGroupData)
 	if statusCode != http.StatusOK {
-		ctx.JSON(resp)
+		writeErrorResponse(ctx, resp)
 		ctx.StatusCode(statusCode)
 		return
 	}
@@ -18,7 +18,6 @@ package caphandler
 import (
 	"fmt"
 	"net/http"
-	"strconv"
 
 	"github.com/ODIM-Project/ODIM/lib-dmtf/model"
 	"github.com/ODIM-Project/PluginCiscoACI/capmodel"
@@ -28,14 +27,40 @@ import (
 	log "github.com/sirupsen/logrus"
 )
 
-//GetFabricResource : Fetches details of the given resource from the device
-func GetFabricResource(ctx iris.Context) {
-	ctx.StatusCode(http.StatusNotImplemented)
+// APIC call reading the health of the fabric, replaced in unit tests
+var getFabricHealth = caputilities.GetFabricHealth
+
+// GetFabricCollection lists all the fabrics stored, an empty collection is returned when there is none
+func GetFabricCollection(ctx iris.Context) {
+	uri := ctx.Path()
+	fabricIDs, err := capmodel.ListFabrics()
+	if err != nil {
+		errMsg := fmt.Sprintf("failed to fetch fabric data for uri %s: %s", uri, err.Error())
+		createDbErrResp(ctx, err, errMsg, []interface{}{"Fabric", uri})
+		return
+	}
+	var members = []*model.Link{}
+	for _, fabricID := range fabricIDs {
+		members = append(members, &model.Link{
+			Oid: "/ODIM/v1/Fabrics/" + fabricID,
+		})
+	}
+	fabricCollectionResponse := model.Collection{
+		ODataContext: "/ODIM/v1/$metadata#FabricCollection.FabricCollection",
+		ODataID:      uri,
+		ODataType:    "#FabricCollection.FabricCollection",
+		Description:  "Fabrics view",
+		Name:         "Fabrics",
+		Members:      members,
+		MembersCount: len(members),
+	}
+	ctx.StatusCode(http.StatusOK)
+	ctx.JSON(fabricCollectionResponse)
 }
 
 // GetFabricData fetches the fabric information
 func GetFabricData(ctx iris.Context) {
-	uri := ctx.Request().RequestURI
+	uri := ctx.Path()
 	fabricID := ctx.Params().Get("id")
 	fabricData, err := capmodel.GetFabric(fabricID)
 	if err != nil {
@@ -73,23 +98,16 @@ func GetFabricData(ctx iris.Context) {
 }
 
 func getFabricHealthData(podID string) string {
-	fabricHealthResposne, err := caputilities.GetFabricHealth(podID)
+	fabricHealthResposne, err := getFabricHealth(podID)
 	if err != nil {
 		log.Info("Unable to get fabric health" + err.Error())
 		return ""
 	}
 	log.Info(fabricHealthResposne)
-	data := fabricHealthResposne.IMData[0].FabricHealthData.Attributes
-	currentHealthValue := data["cur"].(string)
-	healthValue, err := strconv.Atoi(currentHealthValue)
+	healthValue, err := caputilities.HealthScore(fabricHealthResposne.IMData[0].FabricHealthData.Attributes)
 	if err != nil {
-		log.Error("Unable to convert current Health value:" + currentHealthValue + " go the error" + err.Error())
+		log.Error("Unable to get current Health value: " + err.Error())
 		return ""
 	}
-	if healthValue > 90 {
-		return "OK"
-	} else if healthValue <= 90 && healthValue < 30 {
-		return "Warning"
-	}
-	return "Critical"
+	return healthOfScore(healthValue)
 }
@@ -0,0 +1,96 @@
+//(C) Copyright [2020] Hewlett Packard Enterprise Development LP
+//
+//Licensed under the Apache License, Version 2.0 (the "License"); you may
+//not use this file except in compliance with the License. You may obtain
+//a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+//Unless required by applicable law or agreed to in writing, software
+//distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+//WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+//License for the specific language governing permissions and limitations
+// under the License.
+
+package caphandler
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/ODIM-Project/PluginCiscoACI/capdata"
+	"github.com/ODIM-Project/PluginCiscoACI/capmodel"
+	"github.com/ODIM-Project/PluginCiscoACI/caputilities"
+	"github.com/ODIM-Project/PluginCiscoACI/config"
+	"github.com/ODIM-Project/PluginCiscoACI/db"
+
+	iris "github.com/kataras/iris/v12"
+	"github.com/kataras/iris/v12/httptest"
+)
+
+func TestGetFabricCollection(t *testing.T) {
+	config.SetUpMockConfig(t)
+	db.Connector = db.NewMockMemoryConnector()
+	capmodel.InvalidateFabricCache()
+	defer capmodel.InvalidateFabricCache()
+	mockApp := iris.New()
+	mockApp.Get("/ODIM/v1/Fabrics", GetFabricCollection)
+	e := httptest.New(t, mockApp)
+
+	// no fabric discovered yet
+	collection := e.GET("/ODIM/v1/Fabrics").Expect().Status(http.StatusOK).JSON().Object()
+	collection.Value("Members").Array().Empty()
+	collection.Value("Members@odata.count").Equal(0)
+
+	capmodel.SaveFabric("fabricA", &capdata.Fabric{PodID: "1"})
+	capmodel.SaveFabric("fabricB", &capdata.Fabric{PodID: "2"})
+	collection = e.GET("/ODIM/v1/Fabrics").Expect().Status(http.StatusOK).JSON().Object()
+	collection.Value("Members").Array().Equal([]map[string]string{
+		{"@odata.id": "/ODIM/v1/Fabrics/fabricA"},
+		{"@odata.id": "/ODIM/v1/Fabrics/fabricB"},
+	})
+	collection.Value("Members@odata.count").Equal(2)
+
+	capmodel.DeleteFabric("fabricA")
+	collection = e.GET("/ODIM/v1/Fabrics").Expect().Status(http.StatusOK).JSON().Object()
+	collection.Value("Members").Array().Equal([]map[string]string{{"@odata.id": "/ODIM/v1/Fabrics/fabricB"}})
+}
+
+func TestFabricAndSwitchHealthBands(t *testing.T) {
+	var score string
+	getFabricHealth = func(podID string) (*capmodel.FabricHealth, error) {
+		return &capmodel.FabricHealth{IMData: []capmodel.FabricHealthIMData{{
+			FabricHealthData: capmodel.FabricHealthData{Attributes: map[string]interface{}{"cur": score}},
+		}}}, nil
+	}
+	getSwitchHealth = func(podID, ACISwitchID string) (*capmodel.Health, error) {
+		return &capmodel.Health{IMData: []capmodel.HealthIMData{{
+			HealthData: capmodel.HealthData{Attributes: map[string]interface{}{"cur": score}},
+		}}}, nil
+	}
+	defer func() {
+		getFabricHealth = caputilities.GetFabricHealth
+		getSwitchHealth = caputilities.GetSwitchHealth
+	}()
+	// the scores of 30 to 90 are a Warning, the ones below 30 Critical
+	tests := []struct {
+		score string
+		want  string
+	}{
+		{"100", "OK"},
+		{"91", "OK"},
+		{"90", "Warning"},
+		{"30", "Warning"},
+		{"29", "Critical"},
+		{"0", "Critical"},
+	}
+	for _, tt := range tests {
+		score = tt.score
+		if got := getFabricHealthData("1"); got != tt.want {
+			t.Errorf("getFabricHealthData() of score %s = %s, want %s", tt.score, got, tt.want)
+		}
+		if got := getSwitchHealthData("1", "switchUUID:101"); got != tt.want {
+			t.Errorf("getSwitchHealthData() of score %s = %s, want %s", tt.score, got, tt.want)
+		}
+	}
+}
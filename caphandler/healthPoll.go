@@ -0,0 +1,167 @@
+//(C) Copyright [2020] Hewlett Packard Enterprise Development LP
+//
+//Licensed under the Apache License, Version 2.0 (the "License"); you may
+//not use this file except in compliance with the License. You may obtain
+//a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+//Unless required by applicable law or agreed to in writing, software
+//distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+//WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+//License for the specific language governing permissions and limitations
+// under the License.
+
+//Package caphandler ...
+package caphandler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/ODIM-Project/PluginCiscoACI/capmodel"
+	"github.com/ODIM-Project/PluginCiscoACI/caputilities"
+	"github.com/ODIM-Project/PluginCiscoACI/config"
+	"github.com/ODIM-Project/PluginCiscoACI/db"
+	log "github.com/sirupsen/logrus"
+)
+
+// ErrHealthPollRunning is returned when the health of the fabric is already being polled
+var ErrHealthPollRunning = errors.New("the health of the fabric is already being polled")
+
+// HealthPollSummary is the result of polling the health of the ports of a fabric
+type HealthPollSummary struct {
+	FabricID string
+	// Switches is the number of switches of the fabric and FailedSwitches the ones whose health couldn't be read
+	Switches       int
+	FailedSwitches int
+	// Ports is the number of ports whose health was read and stored, by health in PortsByHealth
+	Ports         int
+	PortsByHealth map[string]int
+}
+
+var (
+	healthPollsLock sync.Mutex
+	// healthPolls are the fabrics whose health is being polled
+	healthPolls = map[string]bool{}
+)
+
+// PollFabricHealth reads the health of all the ports of the fabric from APIC and stores it in the state of
+// each port. The health of the ports of a switch is read in a single APIC query, the switches are polled by
+// up to concurrency workers, APIC HealthPollConcurrency when not positive, and the queries are subject to
+// the APIC rate limit of the plugin. The poll stops with the error of ctx when it is cancelled, and fails
+// with ErrHealthPollRunning while another poll of the fabric is running.
+func PollFabricHealth(ctx context.Context, fabricID string, concurrency int) (HealthPollSummary, error) {
+	summary := HealthPollSummary{FabricID: fabricID, PortsByHealth: map[string]int{}}
+	healthPollsLock.Lock()
+	if healthPolls[fabricID] {
+		healthPollsLock.Unlock()
+		return summary, ErrHealthPollRunning
+	}
+	healthPolls[fabricID] = true
+	healthPollsLock.Unlock()
+	defer func() {
+		healthPollsLock.Lock()
+		delete(healthPolls, fabricID)
+		healthPollsLock.Unlock()
+	}()
+
+	fabric, err := capmodel.GetFabric(fabricID)
+	if err != nil {
+		return summary, fmt.Errorf("while trying to collect fabric %s, got: %w", fabricID, err)
+	}
+	summary.Switches = len(fabric.SwitchData)
+	if concurrency <= 0 {
+		concurrency = config.Data.APICConf.HealthPollConcurrency
+	}
+	switches := make(chan string)
+	var lock sync.Mutex
+	var workers sync.WaitGroup
+	for i := 0; i < concurrency; i++ {
+		workers.Add(1)
+		go func() {
+			defer workers.Done()
+			for switchID := range switches {
+				// the switches handed out as the poll is cancelled are skipped
+				if ctx.Err() != nil {
+					continue
+				}
+				portsByHealth, err := pollSwitchHealth(fabricID, fabric.PodID, switchID)
+				lock.Lock()
+				if err != nil {
+					log.Error("while polling the health of the ports of switch " + switchID + ", got: " + err.Error())
+					summary.FailedSwitches++
+				}
+				for health, count := range portsByHealth {
+					summary.PortsByHealth[health] += count
+					summary.Ports += count
+				}
+				lock.Unlock()
+			}
+		}()
+	}
+feed:
+	for _, switchID := range fabric.SwitchData {
+		select {
+		case switches <- switchID:
+		case <-ctx.Done():
+			break feed
+		}
+	}
+	close(switches)
+	workers.Wait()
+	return summary, ctx.Err()
+}
+
+// pollSwitchHealth reads the health of the ports of the switch from APIC and stores it in the state of each
+// stored port, the number of ports stored by health is returned
+func pollSwitchHealth(fabricID, podID, switchID string) (map[string]int, error) {
+	health, err := getSwitchPortsHealth("", podID, capmodel.SwitchNodeID(switchID))
+	if err != nil {
+		return nil, err
+	}
+	ports, err := capmodel.GetSwitchPort(switchID)
+	if err != nil {
+		return nil, err
+	}
+	portsByHealth := map[string]int{}
+	for apicPortID, healthData := range health {
+		portID := findSwitchPort(ports, apicPortID)
+		if portID == "" {
+			continue
+		}
+		portOID := fmt.Sprintf("/ODIM/v1/Fabrics/%s/Switches/%s/Ports/%s", fabricID, switchID, portID)
+		portHealth := unknownPortHealth()
+		if score, err := caputilities.HealthScore(healthData.Attributes); err == nil {
+			portHealth = healthOfScore(score)
+		}
+		if portHealth == "" {
+			continue
+		}
+		if err := storePortHealth(portOID, portHealth); err != nil {
+			return portsByHealth, err
+		}
+		portsByHealth[portHealth]++
+	}
+	return portsByHealth, nil
+}
+
+// storePortHealth stores the health of the port in its state, the link state last read for the
+// port is kept. The health going Critical is held for the flap grace window like in GetPortInfo.
+func storePortHealth(portOID, health string) error {
+	previous, err := capmodel.GetPortState(portOID)
+	found := err == nil
+	if err != nil && !errors.Is(err, db.ErrorKeyNotFound) {
+		return err
+	}
+	observed := previous
+	if previous.Pending != nil {
+		observed = *previous.Pending
+	}
+	observed.Pending, observed.PendingSince = nil, nil
+	observed.Health = health
+	_, err = storePortState(portOID, previous, found, observed)
+	return err
+}
@@ -0,0 +1,156 @@
+//(C) Copyright [2020] Hewlett Packard Enterprise Development LP
+//
+//Licensed under the Apache License, Version 2.0 (the "License"); you may
+//not use this file except in compliance with the License. You may obtain
+//a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+//Unless required by applicable law or agreed to in writing, software
+//distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+//WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+//License for the specific language governing permissions and limitations
+// under the License.
+
+package caphandler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ODIM-Project/PluginCiscoACI/capdata"
+	"github.com/ODIM-Project/PluginCiscoACI/capmodel"
+	"github.com/ODIM-Project/PluginCiscoACI/caputilities"
+	"github.com/ODIM-Project/PluginCiscoACI/config"
+	"github.com/ODIM-Project/PluginCiscoACI/db"
+)
+
+// mockHealthPollFabric stores the fabric with the given number of switches of two ports each
+func mockHealthPollFabric(t *testing.T, switches int) []string {
+	config.SetUpMockConfig(t)
+	db.Connector = db.NewMockMemoryConnector()
+	capmodel.InvalidateFabricCache()
+	var switchIDs []string
+	for i := 0; i < switches; i++ {
+		switchID := fmt.Sprintf("switchUUID:%d", 101+i)
+		switchIDs = append(switchIDs, switchID)
+		capmodel.SaveSwitchPort(switchID, []string{"portUUID:eth1-1", "portUUID:eth1-2"})
+	}
+	capmodel.SaveFabric(testFabricID, &capdata.Fabric{PodID: "1", SwitchData: switchIDs})
+	return switchIDs
+}
+
+func switchPortsHealthData(scores ...string) map[string]capmodel.HealthData {
+	health := map[string]capmodel.HealthData{}
+	for i, score := range scores {
+		health[fmt.Sprintf("eth1/%d", i+1)] = capmodel.HealthData{Attributes: map[string]interface{}{"cur": score}}
+	}
+	return health
+}
+
+func TestPollFabricHealth(t *testing.T) {
+	mockHealthPollFabric(t, 6)
+	var lock sync.Mutex
+	var running, maxRunning int
+	queried := map[string]int{}
+	getSwitchPortsHealth = func(traceParent, podID, ACISwitchID string) (map[string]capmodel.HealthData, error) {
+		lock.Lock()
+		queried[ACISwitchID]++
+		running++
+		if running > maxRunning {
+			maxRunning = running
+		}
+		lock.Unlock()
+		time.Sleep(20 * time.Millisecond)
+		lock.Lock()
+		running--
+		lock.Unlock()
+		if ACISwitchID == "106" {
+			return nil, errors.New("APIC unreachable")
+		}
+		return switchPortsHealthData("100", "50"), nil
+	}
+	defer func() { getSwitchPortsHealth = caputilities.GetSwitchPortsHealth }()
+
+	summary, err := PollFabricHealth(context.Background(), testFabricID, 2)
+	if err != nil {
+		t.Fatalf("PollFabricHealth() error = %v", err)
+	}
+	// the health of the ports of each switch is read in a single query
+	if len(queried) != 6 {
+		t.Errorf("queried switches = %v, want the 6 switches", queried)
+	}
+	for nodeID, count := range queried {
+		if count != 1 {
+			t.Errorf("switch %s queried %d times, want once", nodeID, count)
+		}
+	}
+	if maxRunning > 2 {
+		t.Errorf("%d switches queried at once, want at most 2", maxRunning)
+	}
+	if summary.Switches != 6 || summary.FailedSwitches != 1 || summary.Ports != 10 ||
+		summary.PortsByHealth["OK"] != 5 || summary.PortsByHealth["Warning"] != 5 {
+		t.Errorf("PollFabricHealth() = %+v, want 10 ports of 5 switches polled, half of them OK", summary)
+	}
+
+	// the health is stored in the state of the ports
+	state, err := capmodel.GetPortState("/ODIM/v1/Fabrics/fabricID/Switches/switchUUID:101/Ports/portUUID:eth1-2")
+	if err != nil || state.Health != "Warning" {
+		t.Errorf("stored port state = %+v, %v, want Warning health", state, err)
+	}
+	warning, _ := capmodel.GetPortsByHealth("/ODIM/v1/Fabrics/fabricID", []string{"Warning"})
+	if len(warning) != 5 {
+		t.Errorf("ports of Warning health = %v, want 5", warning)
+	}
+}
+
+func TestPollFabricHealthExclusive(t *testing.T) {
+	mockHealthPollFabric(t, 1)
+	started, release := make(chan struct{}), make(chan struct{})
+	getSwitchPortsHealth = func(traceParent, podID, ACISwitchID string) (map[string]capmodel.HealthData, error) {
+		close(started)
+		<-release
+		return switchPortsHealthData("100"), nil
+	}
+	defer func() { getSwitchPortsHealth = caputilities.GetSwitchPortsHealth }()
+
+	done := make(chan error)
+	go func() {
+		_, err := PollFabricHealth(context.Background(), testFabricID, 1)
+		done <- err
+	}()
+	<-started
+	if _, err := PollFabricHealth(context.Background(), testFabricID, 1); !errors.Is(err, ErrHealthPollRunning) {
+		t.Errorf("PollFabricHealth() while polling the fabric error = %v, want ErrHealthPollRunning", err)
+	}
+	close(release)
+	if err := <-done; err != nil {
+		t.Errorf("PollFabricHealth() error = %v", err)
+	}
+}
+
+func TestPollFabricHealthCancelled(t *testing.T) {
+	mockHealthPollFabric(t, 4)
+	ctx, cancel := context.WithCancel(context.Background())
+	var lock sync.Mutex
+	queried := 0
+	getSwitchPortsHealth = func(traceParent, podID, ACISwitchID string) (map[string]capmodel.HealthData, error) {
+		lock.Lock()
+		defer lock.Unlock()
+		queried++
+		cancel()
+		return switchPortsHealthData("100"), nil
+	}
+	defer func() { getSwitchPortsHealth = caputilities.GetSwitchPortsHealth }()
+
+	if _, err := PollFabricHealth(ctx, testFabricID, 1); !errors.Is(err, context.Canceled) {
+		t.Errorf("PollFabricHealth() cancelled error = %v, want context.Canceled", err)
+	}
+	if queried != 1 {
+		t.Errorf("%d switches queried, want the poll stopped once cancelled", queried)
+	}
+}
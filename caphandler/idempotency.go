@@ -0,0 +1,133 @@
+//(C) Copyright [2020] Hewlett Packard Enterprise Development LP
+//
+//Licensed under the Apache License, Version 2.0 (the "License"); you may
+//not use this file except in compliance with the License. You may obtain
+//a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+//Unless required by applicable law or agreed to in writing, software
+//distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+//WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+//License for the specific language governing permissions and limitations
+// under the License.
+
+//Package caphandler ...
+package caphandler
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/ODIM-Project/ODIM/lib-utilities/response"
+	"github.com/ODIM-Project/PluginCiscoACI/capmodel"
+	"github.com/ODIM-Project/PluginCiscoACI/config"
+	"github.com/ODIM-Project/PluginCiscoACI/db"
+	iris "github.com/kataras/iris/v12"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	// idempotencyKeyHeader is the header the clients set for the retries of a request to be applied once
+	idempotencyKeyHeader = "Idempotency-Key"
+	// idempotentReplayedHeader is set on the responses replayed for an idempotency key
+	idempotentReplayedHeader = "Idempotent-Replayed"
+)
+
+// idempotentRequest is a request made with an idempotency key
+type idempotentRequest struct {
+	key   string
+	hash  string
+	saved bool
+}
+
+// replayIdempotentRequest reserves the idempotency key of the request, or answers the request with the result
+// stored for the key, true is returned when the request is answered. A key used for a different request is
+// answered with 422 and a key reserved by the same request still being applied with 409. The request to be
+// applied is returned, nil when it has no idempotency key, and must be completed with releaseIdempotentRequest.
+func replayIdempotentRequest(ctx iris.Context, body []byte, resource resourceRef) (*idempotentRequest, bool) {
+	key := ctx.GetHeader(idempotencyKeyHeader)
+	if key == "" {
+		return nil, false
+	}
+	hash := sha256.Sum256([]byte(ctx.Method() + " " + ctx.Path() + "\n" + string(body)))
+	request := &idempotentRequest{key: key, hash: hex.EncodeToString(hash[:])}
+	var result *capmodel.IdempotentResult
+	for result == nil {
+		err := capmodel.ReserveIdempotencyKey(key, request.hash, idempotencyKeyTTL())
+		if err == nil {
+			return request, false
+		}
+		if errors.Is(err, db.ErrorKeyAlreadyExist) {
+			// the key expired or was released since it couldn't be reserved when it isn't found
+			result, err = capmodel.GetIdempotentResult(key)
+			if errors.Is(err, db.ErrorKeyNotFound) {
+				continue
+			}
+		}
+		if err != nil {
+			errMsg := fmt.Sprintf("failed to reserve idempotency key %s: %s", key, err.Error())
+			createResourceDbErrResp(ctx, err, errMsg, nil, resource)
+			return nil, true
+		}
+	}
+	if result.RequestHash != request.hash {
+		errMsg := fmt.Sprintf("idempotency key %s was used for a different request", key)
+		log.Error(errMsg)
+		ctx.StatusCode(http.StatusUnprocessableEntity)
+		writeErrorResponse(ctx, withResource(updateErrorResponse(response.GeneralError, errMsg, nil), resource))
+		return nil, true
+	}
+	if result.Pending {
+		errMsg := fmt.Sprintf("the request with idempotency key %s is still being applied", key)
+		log.Error(errMsg)
+		ctx.Header("Retry-After", "1")
+		ctx.StatusCode(http.StatusConflict)
+		writeErrorResponse(ctx, withResource(updateErrorResponse(response.GeneralError, errMsg, nil), resource))
+		return nil, true
+	}
+	ctx.Header(idempotentReplayedHeader, "true")
+	ctx.ContentType("application/json")
+	ctx.StatusCode(result.StatusCode)
+	ctx.Write(result.Body)
+	return nil, true
+}
+
+// saveIdempotentResult stores the result of the request under its idempotency key, for the retries of the request
+// to be answered with it. The result isn't stored for a request without idempotency key.
+func saveIdempotentResult(request *idempotentRequest, statusCode int, resp interface{}) {
+	if request == nil {
+		return
+	}
+	body, err := json.Marshal(resp)
+	if err != nil {
+		log.Error(fmt.Sprintf("failed to encode the result of idempotency key %s: %s", request.key, err.Error()))
+		return
+	}
+	result := &capmodel.IdempotentResult{RequestHash: request.hash, StatusCode: statusCode, Body: body}
+	if err = capmodel.SaveIdempotentResult(request.key, result, idempotencyKeyTTL()); err != nil {
+		log.Error(fmt.Sprintf("failed to store the result of idempotency key %s: %s", request.key, err.Error()))
+		return
+	}
+	request.saved = true
+}
+
+// releaseIdempotentRequest releases the idempotency key reserved by the request when no result was stored
+// for it, like for a request which failed, so that the request can be retried with the key
+func releaseIdempotentRequest(request *idempotentRequest) {
+	if request == nil || request.saved {
+		return
+	}
+	if err := capmodel.ReleaseIdempotencyKey(request.key); err != nil {
+		log.Error(fmt.Sprintf("failed to release idempotency key %s: %s", request.key, err.Error()))
+	}
+}
+
+func idempotencyKeyTTL() time.Duration {
+	return time.Duration(config.Data.ServerConf.IdempotencyKeyTTLInSeconds) * time.Second
+}
@@ -29,7 +29,7 @@ import (
 
 //GetManagersCollection Fetches details of the manager collection
 func GetManagersCollection(ctx iris.Context) {
-	uri := ctx.Request().RequestURI
+	uri := ctx.Path()
 	var members = []*model.Link{
 		&model.Link{
 			Oid: "/ODIM/v1/Managers/" + pluginConfig.Data.RootServiceUUID,
@@ -53,7 +53,7 @@ func GetManagersCollection(ctx iris.Context) {
 
 //GetManagersInfo Fetches details of the given manager info
 func GetManagersInfo(ctx iris.Context) {
-	uri := ctx.Request().RequestURI
+	uri := ctx.Path()
 	// Get all switch data uri
 	managedSwitches := []model.Link{}
 	allFabric, err := capmodel.GetAllFabric("")
@@ -73,7 +73,9 @@ func GetManagersInfo(ctx iris.Context) {
 		ODataContext:    "/ODIM/v1/$metadata#Manager.Manager",
 		ODataID:         uri,
 		ODataType:       "#Manager.v1_10_0.Manager",
-		Name:            pluginConfig.Data.PluginConf.ID,
+		Name:            pluginConfig.Data.PluginConf.Name,
+		Manufacturer:    pluginConfig.Data.PluginConf.Vendor,
+		Model:           pluginConfig.Data.PluginConf.Model,
 		ManagerType:     "Service",
 		ID:              pluginConfig.Data.RootServiceUUID,
 		UUID:            pluginConfig.Data.RootServiceUUID,
@@ -54,3 +54,19 @@ func TestGetManager(t *testing.T) {
 	//Unit Test for success scenario
 	e.GET("/ODIM/v1/Managers").WithJSON(deviceDetails).Expect().Status(http.StatusOK)
 }
+
+func TestGetManagerVendorModel(t *testing.T) {
+	config.SetUpMockConfig(t)
+	db.Connector = db.NewMockMemoryConnector()
+	config.Data.PluginConf.Vendor = "Example Corp"
+	config.Data.PluginConf.Model = "ACI Fabric Plugin"
+	config.Data.PluginConf.Name = "ACI plugin of site A"
+	mockApp := iris.New()
+	mockApp.Get("/ODIM/v1/Managers/{id}", GetManagersInfo)
+	e := httptest.New(t, mockApp)
+
+	manager := e.GET("/ODIM/v1/Managers/" + config.Data.RootServiceUUID).Expect().Status(http.StatusOK).JSON().Object()
+	manager.Value("Manufacturer").Equal("Example Corp")
+	manager.Value("Model").Equal("ACI Fabric Plugin")
+	manager.Value("Name").Equal("ACI plugin of site A")
+}
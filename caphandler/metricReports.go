@@ -0,0 +1,279 @@
+//(C) Copyright [2020] Hewlett Packard Enterprise Development LP
+//
+//Licensed under the Apache License, Version 2.0 (the "License"); you may
+//not use this file except in compliance with the License. You may obtain
+//a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+//Unless required by applicable law or agreed to in writing, software
+//distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+//WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+//License for the specific language governing permissions and limitations
+// under the License.
+
+//Package caphandler ...
+package caphandler
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/ODIM-Project/ODIM/lib-dmtf/model"
+	"github.com/ODIM-Project/ODIM/lib-utilities/common"
+	"github.com/ODIM-Project/ODIM/lib-utilities/response"
+	"github.com/ODIM-Project/PluginCiscoACI/capdata"
+	"github.com/ODIM-Project/PluginCiscoACI/capmodel"
+	"github.com/ODIM-Project/PluginCiscoACI/capresponse"
+	"github.com/ODIM-Project/PluginCiscoACI/captrace"
+	"github.com/ODIM-Project/PluginCiscoACI/caputilities"
+	"github.com/ODIM-Project/PluginCiscoACI/config"
+	iris "github.com/kataras/iris/v12"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	telemetryServiceURI             = "/ODIM/v1/TelemetryService"
+	portCountersReportID            = "PortCounters"
+	metricReportDefinitionODataType = "#MetricReportDefinition.v1_4_2.MetricReportDefinition"
+	metricDefinitionODataType       = "#MetricDefinition.v1_3_1.MetricDefinition"
+	metricReportODataType           = "#MetricReport.v1_4_2.MetricReport"
+	// portCountersDuration is the interval APIC collects the counters of the ports over, as an ISO 8601 duration
+	portCountersDuration = "PT5M"
+)
+
+// portCounterMetric is a metric of the PortCounters report, read from the APIC counters of the ports
+type portCounterMetric struct {
+	id          string
+	description string
+	units       string
+	value       func(capmodel.PortCounters) uint64
+}
+
+// portCounterMetrics are the metrics of the PortCounters report, in the order of the metric values of a port
+var portCounterMetrics = []portCounterMetric{
+	{"RXBytes", "Bytes received by the port over the last 5 minutes", "By", func(c capmodel.PortCounters) uint64 { return c.RXBytes }},
+	{"TXBytes", "Bytes transmitted by the port over the last 5 minutes", "By", func(c capmodel.PortCounters) uint64 { return c.TXBytes }},
+	{"RXErrors", "Packets received by the port and dropped for errors over the last 5 minutes", "{packet}", func(c capmodel.PortCounters) uint64 { return c.RXErrors }},
+	{"TXErrors", "Packets dropped for errors by the port before transmitting them over the last 5 minutes", "{packet}", func(c capmodel.PortCounters) uint64 { return c.TXErrors }},
+}
+
+// APIC call used for collecting the counters of the ports, replaced in unit tests
+var getPortCounters = caputilities.GetPortCounters
+
+// GetMetricReportDefinition returns the definition of the PortCounters metric report, the report is
+// generated on request and also published on the message bus when PortMetricReportIntervalInSeconds is set
+func GetMetricReportDefinition(ctx iris.Context) {
+	id := ctx.Params().Get("id")
+	if id != portCountersReportID {
+		metricReportNotFound(ctx, "MetricReportDefinition", id)
+		return
+	}
+	uri := telemetryServiceURI + "/MetricReportDefinitions/" + portCountersReportID
+	definition := capresponse.MetricReportDefinition{
+		ODataID:   uri,
+		ODataType: metricReportDefinitionODataType,
+		ID:        portCountersReportID,
+		Name:      "Port Counters Metric Report Definition",
+		Description: "Traffic and errors of the ports of the switches over the last 5 minutes, read from APIC. " +
+			"The metric properties are in the PortMetrics of the ports.",
+		MetricReportDefinitionType: "OnRequest",
+		ReportActions:              []string{"LogToMetricReportsCollection"},
+		ReportUpdates:              "Overwrite",
+		Metrics:                    make([]capresponse.MetricReportMetric, 0, len(portCounterMetrics)),
+		MetricReport:               &model.Link{Oid: telemetryServiceURI + "/MetricReports/" + portCountersReportID},
+		Links:                      &capresponse.MetricReportDefLinks{},
+	}
+	if interval := config.Data.APICConf.PortMetricReportIntervalInSeconds; interval > 0 {
+		definition.MetricReportDefinitionType = "Periodic"
+		definition.ReportActions = append(definition.ReportActions, "RedfishEvent")
+		definition.Schedule = &capresponse.MetricReportSchedule{RecurrenceInterval: fmt.Sprintf("PT%dS", interval)}
+	}
+	for _, metric := range portCounterMetrics {
+		definition.Metrics = append(definition.Metrics, capresponse.MetricReportMetric{
+			MetricID:           metric.id,
+			MetricProperties:   []string{portMetricProperty("/ODIM/v1/Fabrics/{FabricID}/Switches/{SwitchID}/Ports/{PortID}", metric.id)},
+			CollectionDuration: portCountersDuration,
+		})
+		definition.Links.MetricDefinitions = append(definition.Links.MetricDefinitions, model.Link{Oid: metricDefinitionURI(metric.id)})
+	}
+	ctx.StatusCode(http.StatusOK)
+	ctx.JSON(definition)
+}
+
+// GetMetricDefinition returns the definition of a metric of the PortCounters report, its values are integers
+func GetMetricDefinition(ctx iris.Context) {
+	id := ctx.Params().Get("id")
+	for _, metric := range portCounterMetrics {
+		if metric.id != id {
+			continue
+		}
+		ctx.StatusCode(http.StatusOK)
+		ctx.JSON(capresponse.MetricDefinition{
+			ODataID:            metricDefinitionURI(id),
+			ODataType:          metricDefinitionODataType,
+			ID:                 id,
+			Name:               id + " Metric Definition",
+			Description:        metric.description,
+			MetricType:         "Numeric",
+			MetricDataType:     "Integer",
+			Units:              metric.units,
+			IsLinear:           true,
+			CollectionDuration: portCountersDuration,
+		})
+		return
+	}
+	metricReportNotFound(ctx, "MetricDefinition", id)
+}
+
+// GetMetricReport generates the PortCounters metric report from the counters of the ports read from
+// APIC, in a single query of each fabric
+func GetMetricReport(ctx iris.Context) {
+	id := ctx.Params().Get("id")
+	if id != portCountersReportID {
+		metricReportNotFound(ctx, "MetricReport", id)
+		return
+	}
+	span := captrace.StartHandlerSpan(ctx, "GetMetricReport")
+	defer span.End()
+	fabrics, err := capmodel.GetAllFabric("")
+	if err != nil {
+		errMsg := "failed to fetch the fabrics for the metric report: " + err.Error()
+		createResourceDbErrResp(ctx, err, errMsg, nil, resourceRef{})
+		return
+	}
+	report, err := newPortCountersReport(span, fabrics)
+	if err != nil {
+		errMsg := "failed to fetch the port counters for the metric report: " + err.Error()
+		statusCode, resp := createAPICErrResp(nil, err, errMsg, nil)
+		writeAPICErrResp(ctx, err, statusCode, resp)
+		return
+	}
+	ctx.StatusCode(http.StatusOK)
+	ctx.JSON(report)
+}
+
+// newPortCountersReport builds the PortCounters metric report of the stored ports of the fabrics,
+// the metric values are ordered by fabric, switch and port. The switches whose ports can't be read
+// from the DB are left out of the report.
+func newPortCountersReport(span *captrace.Span, fabrics map[string]capdata.Fabric) (capresponse.MetricReport, error) {
+	now := time.Now().Format(time.RFC3339)
+	report := capresponse.MetricReport{
+		ODataID:                telemetryServiceURI + "/MetricReports/" + portCountersReportID,
+		ODataType:              metricReportODataType,
+		ID:                     portCountersReportID,
+		Name:                   "Port Counters Metric Report",
+		Timestamp:              now,
+		MetricReportDefinition: &model.Link{Oid: telemetryServiceURI + "/MetricReportDefinitions/" + portCountersReportID},
+		// the metric values are always present so that clients can iterate them without checking for null
+		MetricValues: []capresponse.MetricValue{},
+	}
+	fabricIDs := make([]string, 0, len(fabrics))
+	for fabricID := range fabrics {
+		fabricIDs = append(fabricIDs, fabricID)
+	}
+	sort.Strings(fabricIDs)
+	for _, fabricID := range fabricIDs {
+		fabric := fabrics[fabricID]
+		apicSpan := startAPICSpan(span, "caputilities.GetPortCounters")
+		counters, err := getPortCounters(apicSpan.TraceParent(), fabric.PodID)
+		apicSpan.RecordError(err)
+		apicSpan.End()
+		if err != nil {
+			return report, err
+		}
+		for _, switchID := range fabric.SwitchData {
+			switchCounters := counters[capmodel.SwitchNodeID(switchID)]
+			if len(switchCounters) == 0 {
+				continue
+			}
+			ports, err := capmodel.GetSwitchPort(switchID)
+			if err != nil {
+				log.Error("while collecting the ports of switch " + switchID + " for the metric report, got: " + err.Error())
+				continue
+			}
+			apicPortIDs := make([]string, 0, len(switchCounters))
+			for apicPortID := range switchCounters {
+				apicPortIDs = append(apicPortIDs, apicPortID)
+			}
+			sort.Strings(apicPortIDs)
+			for _, apicPortID := range apicPortIDs {
+				portID := findSwitchPort(ports, apicPortID)
+				if portID == "" {
+					continue
+				}
+				portURI := fmt.Sprintf("/ODIM/v1/Fabrics/%s/Switches/%s/Ports/%s", fabricID, switchID, portID)
+				portCounters := switchCounters[apicPortID]
+				timestamp := portCounters.IntervalEnd
+				if timestamp == "" {
+					timestamp = now
+				}
+				for _, metric := range portCounterMetrics {
+					report.MetricValues = append(report.MetricValues, capresponse.MetricValue{
+						MetricID:       metric.id,
+						MetricValue:    strconv.FormatUint(metric.value(portCounters), 10),
+						Timestamp:      timestamp,
+						MetricProperty: portMetricProperty(portURI, metric.id),
+					})
+				}
+			}
+		}
+	}
+	report.MetricValuesCount = len(report.MetricValues)
+	return report, nil
+}
+
+// StartPortMetricReports publishes the PortCounters metric report on the message bus at every
+// interval, jittered so that the plugin instances don't poll APIC at once
+func StartPortMetricReports(interval time.Duration) {
+	go func() {
+		for {
+			time.Sleep(caputilities.JitteredInterval(interval))
+			publishPortCountersReport()
+		}
+	}()
+}
+
+// publishPortCountersReport publishes the PortCounters metric report as a MetricReport event
+func publishPortCountersReport() {
+	fabrics, err := capmodel.GetAllFabric("")
+	if err != nil {
+		log.Error("while collecting the fabrics for the metric report, got: " + err.Error())
+		return
+	}
+	report, err := newPortCountersReport(nil, fabrics)
+	if err != nil {
+		log.Error("while collecting the port counters for the metric report, got: " + err.Error())
+		return
+	}
+	data, err := json.Marshal(report)
+	if err != nil {
+		log.Error("while marshalling the metric report, got: " + err.Error())
+		return
+	}
+	writeEventToJobQueue(common.Events{
+		IP:        config.Data.LoadBalancerConf.Host,
+		Request:   data,
+		EventType: "MetricReport",
+	})
+}
+
+// portMetricProperty returns the property of the PortMetrics of the port holding the metric
+func portMetricProperty(portURI, metricID string) string {
+	return portURI + "/Metrics#/" + metricID
+}
+
+func metricDefinitionURI(metricID string) string {
+	return telemetryServiceURI + "/MetricDefinitions/" + metricID
+}
+
+func metricReportNotFound(ctx iris.Context, resource, id string) {
+	errMsg := fmt.Sprintf("%s %s not found, the plugin provides the %s metric report", resource, id, portCountersReportID)
+	log.Error(errMsg)
+	ctx.StatusCode(http.StatusNotFound)
+	writeErrorResponse(ctx, updateErrorResponse(response.ResourceNotFound, errMsg, []interface{}{resource, id}))
+}
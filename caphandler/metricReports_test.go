@@ -0,0 +1,154 @@
+//(C) Copyright [2020] Hewlett Packard Enterprise Development LP
+//
+//Licensed under the Apache License, Version 2.0 (the "License"); you may
+//not use this file except in compliance with the License. You may obtain
+//a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+//Unless required by applicable law or agreed to in writing, software
+//distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+//WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+//License for the specific language governing permissions and limitations
+// under the License.
+
+package caphandler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/ODIM-Project/ODIM/lib-utilities/common"
+	"github.com/ODIM-Project/PluginCiscoACI/capdata"
+	"github.com/ODIM-Project/PluginCiscoACI/capmessagebus"
+	"github.com/ODIM-Project/PluginCiscoACI/capmodel"
+	"github.com/ODIM-Project/PluginCiscoACI/capresponse"
+	"github.com/ODIM-Project/PluginCiscoACI/caputilities"
+	"github.com/ODIM-Project/PluginCiscoACI/config"
+	iris "github.com/kataras/iris/v12"
+	"github.com/kataras/iris/v12/httptest"
+)
+
+const (
+	testMetricReportURI           = "/ODIM/v1/TelemetryService/MetricReports/PortCounters"
+	testMetricReportDefinitionURI = "/ODIM/v1/TelemetryService/MetricReportDefinitions/PortCounters"
+)
+
+func mockMetricReportApp(t *testing.T) *httptest.Expect {
+	mockPortApp(t)
+	capmodel.SaveFabric(testFabricID, &capdata.Fabric{PodID: "1", SwitchData: []string{testSwitchID}})
+	getPortCounters = func(traceParent, podID string) (map[string]map[string]capmodel.PortCounters, error) {
+		if podID != "1" {
+			t.Errorf("GetPortCounters(%s), want the counters of pod 1", podID)
+		}
+		return map[string]map[string]capmodel.PortCounters{
+			"101": {
+				"eth1/1": {IntervalEnd: "2026-10-16T10:05:00.000+00:00", RXBytes: 1023, TXBytes: 500, RXErrors: 7, TXErrors: 2},
+				// the port isn't stored
+				"eth1/9": {RXBytes: 1},
+			},
+		}, nil
+	}
+	t.Cleanup(func() { getPortCounters = caputilities.GetPortCounters })
+	app := iris.New()
+	app.Get("/ODIM/v1/TelemetryService/MetricReportDefinitions/{id}", GetMetricReportDefinition)
+	app.Get("/ODIM/v1/TelemetryService/MetricDefinitions/{id}", GetMetricDefinition)
+	app.Get("/ODIM/v1/TelemetryService/MetricReports/{id}", GetMetricReport)
+	return httptest.New(t, app)
+}
+
+func TestGetMetricReport(t *testing.T) {
+	e := mockMetricReportApp(t)
+
+	report := e.GET(testMetricReportURI).Expect().Status(http.StatusOK).JSON().Object()
+	report.Value("@odata.type").Equal(metricReportODataType)
+	report.Path("$.MetricReportDefinition['@odata.id']").Equal(testMetricReportDefinitionURI)
+	report.Value("MetricValues@odata.count").Number().Equal(len(portCounterMetrics))
+	want := map[string]uint64{"RXBytes": 1023, "TXBytes": 500, "RXErrors": 7, "TXErrors": 2}
+	for i, value := range report.Value("MetricValues").Array().Iter() {
+		metricValue := value.Object()
+		metricID := metricValue.Value("MetricId").String().Raw()
+		if metricID != portCounterMetrics[i].id {
+			t.Errorf("MetricId of value %d = %s, want %s", i, metricID, portCounterMetrics[i].id)
+		}
+		metricValue.Value("MetricProperty").Equal(testPortURI + "/Metrics#/" + metricID)
+		metricValue.Value("Timestamp").Equal("2026-10-16T10:05:00.000+00:00")
+		// the values are strings holding the integers of the Integer MetricDataType
+		raw := metricValue.Value("MetricValue").String().Raw()
+		if got, err := strconv.ParseUint(raw, 10, 64); err != nil || got != want[metricID] {
+			t.Errorf("MetricValue of %s = %q, want the integer %d", metricID, raw, want[metricID])
+		}
+	}
+
+	e.GET("/ODIM/v1/TelemetryService/MetricReports/Unknown").Expect().Status(http.StatusNotFound)
+
+	getPortCounters = func(traceParent, podID string) (map[string]map[string]capmodel.PortCounters, error) {
+		return nil, caputilities.ErrAPICRateLimited
+	}
+	e.GET(testMetricReportURI).Expect().Status(http.StatusTooManyRequests)
+}
+
+func TestGetMetricReportDefinition(t *testing.T) {
+	e := mockMetricReportApp(t)
+
+	definition := e.GET(testMetricReportDefinitionURI).Expect().Status(http.StatusOK).JSON().Object()
+	definition.Value("MetricReportDefinitionType").Equal("OnRequest")
+	definition.NotContainsKey("Schedule")
+	definition.Path("$.MetricReport['@odata.id']").Equal(testMetricReportURI)
+	metrics := definition.Value("Metrics").Array()
+	metrics.Length().Equal(len(portCounterMetrics))
+	for i, metric := range portCounterMetrics {
+		metrics.Element(i).Object().ValueEqual("MetricId", metric.id).ValueEqual("CollectionDuration", "PT5M")
+		definitionURI := definition.Path("$.Links.MetricDefinitions").Array().Element(i).Object().Value("@odata.id").String().Raw()
+		e.GET(definitionURI).Expect().Status(http.StatusOK).JSON().Object().
+			ValueEqual("Id", metric.id).ValueEqual("MetricDataType", "Integer").ValueEqual("Units", metric.units)
+	}
+	e.GET("/ODIM/v1/TelemetryService/MetricDefinitions/Unknown").Expect().Status(http.StatusNotFound)
+	e.GET("/ODIM/v1/TelemetryService/MetricReportDefinitions/Unknown").Expect().Status(http.StatusNotFound)
+
+	config.Data.APICConf.PortMetricReportIntervalInSeconds = 300
+	definition = e.GET(testMetricReportDefinitionURI).Expect().Status(http.StatusOK).JSON().Object()
+	definition.Value("MetricReportDefinitionType").Equal("Periodic")
+	definition.Path("$.Schedule.RecurrenceInterval").Equal("PT300S")
+	definition.Value("ReportActions").Array().Contains("RedfishEvent")
+}
+
+func TestPublishPortCountersReport(t *testing.T) {
+	mockMetricReportApp(t)
+	EventQueue = capmessagebus.NewEventBuffer(config.DefaultEventBufferCapacity, config.EventOverflowDropOldest, time.Second)
+	published := make(chan common.Events, 1)
+	go EventQueue.Run(func(event interface{}) bool {
+		published <- event.(common.Events)
+		return true
+	})
+	defer EventQueue.Close()
+
+	publishPortCountersReport()
+	select {
+	case event := <-published:
+		if event.EventType != "MetricReport" {
+			t.Errorf("EventType of the published report = %s, want MetricReport", event.EventType)
+		}
+		var report capresponse.MetricReport
+		if err := json.Unmarshal(event.Request, &report); err != nil || len(report.MetricValues) != len(portCounterMetrics) {
+			t.Errorf("published report %s, %v, want the %d metric values of the port", event.Request, err, len(portCounterMetrics))
+		}
+	case <-time.After(time.Second):
+		t.Fatal("no metric report published")
+	}
+
+	// no report is published when the counters can't be read
+	getPortCounters = func(traceParent, podID string) (map[string]map[string]capmodel.PortCounters, error) {
+		return nil, errors.New("APIC unreachable")
+	}
+	publishPortCountersReport()
+	select {
+	case event := <-published:
+		t.Errorf("published %s, want no report", event.Request)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
@@ -0,0 +1,302 @@
+//(C) Copyright [2020] Hewlett Packard Enterprise Development LP
+//
+//Licensed under the Apache License, Version 2.0 (the "License"); you may
+//not use this file except in compliance with the License. You may obtain
+//a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+//Unless required by applicable law or agreed to in writing, software
+//distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+//WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+//License for the specific language governing permissions and limitations
+// under the License.
+
+//Package caphandler ...
+package caphandler
+
+import (
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/ODIM-Project/ODIM/lib-dmtf/model"
+	"github.com/ODIM-Project/ODIM/lib-utilities/response"
+	"github.com/ODIM-Project/PluginCiscoACI/capresponse"
+	"github.com/ODIM-Project/PluginCiscoACI/config"
+	iris "github.com/kataras/iris/v12"
+)
+
+const (
+	openAPIVersion = "3.0.3"
+	openAPISchemas = "#/components/schemas/"
+	portsPath      = "/ODIM/v1/Fabrics/{id}/Switches/{switchID}/Ports"
+	portPath       = portsPath + "/{portID}"
+)
+
+var (
+	jsonMarshalerType = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+	timeType          = reflect.TypeOf(time.Time{})
+)
+
+// GetOpenAPI returns the OpenAPI document of the port operations served by the plugin
+func GetOpenAPI(ctx iris.Context) {
+	ctx.StatusCode(http.StatusOK)
+	ctx.JSON(PortOpenAPI())
+}
+
+// PortOpenAPI builds the OpenAPI document of the operations on the ports and the port collection of a
+// switch. Only the operations, query parameters and headers the handlers implement are described,
+// the schemas are derived from the types the handlers write, which embed the DMTF models.
+func PortOpenAPI() capresponse.OpenAPI {
+	schemas := openAPISchemaBuilder{schemas: make(map[string]*capresponse.OpenAPISchema), names: make(map[reflect.Type]string)}
+	portSchema := schemas.schemaOf(reflect.TypeOf(capresponse.Port{}))
+	patchSchema := schemas.schemaOf(reflect.TypeOf(model.Port{}))
+	collectionSchema := schemas.schemaOf(reflect.TypeOf(capresponse.CollectionResponse{}))
+	errorSchema := schemas.schemaOf(reflect.TypeOf(updateErrorResponse(response.GeneralError, "", nil)))
+
+	pathParameters := []capresponse.OpenAPIParameter{
+		pathParameter("id", "Id of the fabric"),
+		pathParameter("switchID", "Id of the switch, the UUID of the fabric and the APIC node id separated by a colon"),
+	}
+	portParameters := append(append([]capresponse.OpenAPIParameter{}, pathParameters...), pathParameter("portID", "Id of the port"))
+	acceptParameter := capresponse.OpenAPIParameter{
+		Name:        "Accept",
+		In:          "header",
+		Description: "Media type of the response, JSON is returned when absent",
+		Schema:      &capresponse.OpenAPISchema{Type: "string", Enum: []string{mediaTypeJSON, mediaTypeXML}},
+	}
+	minimum := float64(0)
+	pageParameters := []capresponse.OpenAPIParameter{
+		{Name: "$top", In: "query", Description: "Number of members of the page, clamped to the configured MaxPageSize", Schema: &capresponse.OpenAPISchema{Type: "integer", Minimum: &minimum}},
+		{Name: "$skip", In: "query", Description: "Number of members skipped before the page", Schema: &capresponse.OpenAPISchema{Type: "integer", Minimum: &minimum}},
+		{Name: "$count", In: "query", Description: "When true only the number of members is returned, as plain text", Schema: &capresponse.OpenAPISchema{Type: "boolean"}},
+	}
+	collectionHeaders := map[string]capresponse.OpenAPIHeader{
+		"X-Total-Count": {Description: "Number of ports of the switch", Schema: &capresponse.OpenAPISchema{Type: "integer"}},
+		"ETag":          {Description: "Weak entity tag of the members of the page", Schema: &capresponse.OpenAPISchema{Type: "string"}},
+	}
+	etagHeader := map[string]capresponse.OpenAPIHeader{
+		"ETag": {Description: "Weak entity tag of the stored port", Schema: &capresponse.OpenAPISchema{Type: "string"}},
+	}
+	errorResponse := func(description string) capresponse.OpenAPIResponse {
+		return capresponse.OpenAPIResponse{Description: description, Content: jsonContent(errorSchema)}
+	}
+	readErrors := map[string]capresponse.OpenAPIResponse{
+		"400": errorResponse("Invalid query parameter or switch id"),
+		"404": errorResponse("Fabric, switch or port not found"),
+		"406": errorResponse("None of the accepted media types is supported"),
+		"503": errorResponse("Database or APIC unavailable"),
+	}
+	withErrors := func(responses map[string]capresponse.OpenAPIResponse, errors map[string]capresponse.OpenAPIResponse) map[string]capresponse.OpenAPIResponse {
+		for status, resp := range errors {
+			responses[status] = resp
+		}
+		return responses
+	}
+
+	return capresponse.OpenAPI{
+		OpenAPI: openAPIVersion,
+		Info: capresponse.OpenAPIInfo{
+			Title:       "Cisco ACI plugin ports",
+			Description: "Operations on the ports of the switches of the fabrics managed by the plugin",
+			Version:     config.Data.FirmwareVersion,
+		},
+		Paths: map[string]capresponse.OpenAPIPathItem{
+			portsPath: {
+				"get": {
+					OperationID: "GetPortCollection",
+					Summary:     "Get the port collection of the switch",
+					Parameters:  append(append(append([]capresponse.OpenAPIParameter{}, pathParameters...), pageParameters...), acceptParameter),
+					Responses: withErrors(map[string]capresponse.OpenAPIResponse{
+						"200": {
+							Description: "Port collection, or the number of ports when $count is true",
+							Headers:     collectionHeaders,
+							Content: map[string]capresponse.OpenAPIMediaType{
+								mediaTypeJSON: {Schema: collectionSchema},
+								"text/plain":  {Schema: &capresponse.OpenAPISchema{Type: "integer"}},
+							},
+						},
+					}, readErrors),
+				},
+				"head": {
+					OperationID: "HeadPortCollection",
+					Summary:     "Get the headers of the port collection of the switch",
+					Parameters:  append(append(append([]capresponse.OpenAPIParameter{}, pathParameters...), pageParameters...), acceptParameter),
+					Responses: withErrors(map[string]capresponse.OpenAPIResponse{
+						"200": {Description: "Headers of the port collection, including the number of ports", Headers: collectionHeaders},
+					}, readErrors),
+				},
+			},
+			portPath: {
+				"get": {
+					OperationID: "GetPort",
+					Summary:     "Get the port with its link state and health read from APIC",
+					Parameters:  append(append([]capresponse.OpenAPIParameter{}, portParameters...), acceptParameter),
+					Responses: withErrors(map[string]capresponse.OpenAPIResponse{
+						"200": {Description: "Port", Headers: etagHeader, Content: jsonContent(portSchema)},
+						"403": errorResponse("Access to APIC forbidden"),
+						"429": errorResponse("APIC throttled the request"),
+					}, readErrors),
+				},
+				"head": {
+					OperationID: "HeadPort",
+					Summary:     "Get the headers of the stored port",
+					Parameters:  append(append([]capresponse.OpenAPIParameter{}, portParameters...), acceptParameter),
+					Responses: withErrors(map[string]capresponse.OpenAPIResponse{
+						"200": {Description: "Headers of the stored port", Headers: etagHeader},
+					}, readErrors),
+				},
+				"patch": {
+					OperationID: "PatchPort",
+					Summary:     "Update the port, the body is applied as a JSON merge patch",
+					Parameters: append(append([]capresponse.OpenAPIParameter{}, portParameters...),
+						capresponse.OpenAPIParameter{
+							Name:        "Prefer",
+							In:          "header",
+							Description: "return=minimal answers with 204 instead of the updated port",
+							Schema:      &capresponse.OpenAPISchema{Type: "string"},
+						},
+						capresponse.OpenAPIParameter{
+							Name:        idempotencyKeyHeader,
+							In:          "header",
+							Description: "Key of the request, a retried request with the same key and body gets the recorded result",
+							Schema:      &capresponse.OpenAPISchema{Type: "string"},
+						},
+					),
+					RequestBody: &capresponse.OpenAPIRequestBody{Required: true, Content: jsonContent(patchSchema)},
+					Responses: map[string]capresponse.OpenAPIResponse{
+						"200": {Description: "Updated port", Content: jsonContent(patchSchema)},
+						"204": {Description: "Port updated, returned when the client prefers the minimal return"},
+						"400": errorResponse("Empty or malformed body, or property not writable"),
+						"404": errorResponse("Port or connected ethernet interface not found"),
+						"422": errorResponse("Idempotency key reused with another body"),
+						"503": errorResponse("Database or ODIM unavailable"),
+					},
+				},
+			},
+		},
+		Components: capresponse.OpenAPIComponents{
+			Schemas: schemas.schemas,
+			SecuritySchemes: map[string]capresponse.OpenAPISecurityScheme{
+				"basicAuth": {Type: "http", Scheme: "basic"},
+				"authToken": {Type: "apiKey", Name: "X-Auth-Token", In: "header"},
+			},
+		},
+		Security: []map[string][]string{{"basicAuth": {}}, {"authToken": {}}},
+	}
+}
+
+func pathParameter(name, description string) capresponse.OpenAPIParameter {
+	return capresponse.OpenAPIParameter{Name: name, In: "path", Description: description, Required: true, Schema: &capresponse.OpenAPISchema{Type: "string"}}
+}
+
+func jsonContent(schema *capresponse.OpenAPISchema) map[string]capresponse.OpenAPIMediaType {
+	return map[string]capresponse.OpenAPIMediaType{mediaTypeJSON: {Schema: schema}}
+}
+
+// openAPISchemaBuilder derives the schemas of the Go types as they are encoded by encoding/json,
+// the named structs are added to the component schemas and referred to
+type openAPISchemaBuilder struct {
+	schemas map[string]*capresponse.OpenAPISchema
+	names   map[reflect.Type]string
+}
+
+func (b *openAPISchemaBuilder) schemaOf(t reflect.Type) *capresponse.OpenAPISchema {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t == timeType {
+		return &capresponse.OpenAPISchema{Type: "string", Format: "date-time"}
+	}
+	// the encoding of the types with their own marshaller can't be derived
+	if t.Implements(jsonMarshalerType) || reflect.PtrTo(t).Implements(jsonMarshalerType) {
+		return &capresponse.OpenAPISchema{}
+	}
+	switch t.Kind() {
+	case reflect.Bool:
+		return &capresponse.OpenAPISchema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return &capresponse.OpenAPISchema{Type: "integer"}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		minimum := float64(0)
+		return &capresponse.OpenAPISchema{Type: "integer", Minimum: &minimum}
+	case reflect.Float32, reflect.Float64:
+		return &capresponse.OpenAPISchema{Type: "number"}
+	case reflect.String:
+		return &capresponse.OpenAPISchema{Type: "string"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return &capresponse.OpenAPISchema{Type: "string", Format: "byte"}
+		}
+		return &capresponse.OpenAPISchema{Type: "array", Items: b.schemaOf(t.Elem())}
+	case reflect.Map:
+		return &capresponse.OpenAPISchema{Type: "object", AdditionalProperties: b.schemaOf(t.Elem())}
+	case reflect.Struct:
+		if t.Name() == "" {
+			return b.structSchema(t)
+		}
+		name, ok := b.names[t]
+		if !ok {
+			name = b.schemaName(t)
+			b.names[t] = name
+			// the name is registered before the fields, so that the recursive types refer to it
+			b.schemas[name] = &capresponse.OpenAPISchema{}
+			*b.schemas[name] = *b.structSchema(t)
+		}
+		return &capresponse.OpenAPISchema{Ref: openAPISchemas + name}
+	}
+	// interface values can be of any type
+	return &capresponse.OpenAPISchema{}
+}
+
+// schemaName returns the component name of the struct, qualified by its package when
+// a struct of another package has the same name
+func (b *openAPISchemaBuilder) schemaName(t reflect.Type) string {
+	name := t.Name()
+	if _, taken := b.schemas[name]; !taken {
+		return name
+	}
+	pkg := t.PkgPath()
+	return pkg[strings.LastIndex(pkg, "/")+1:] + "." + name
+}
+
+// structSchema returns the object schema of the struct fields, the fields of the embedded structs are
+// promoted unless a field of the struct has the same name, as encoding/json does
+func (b *openAPISchemaBuilder) structSchema(t reflect.Type) *capresponse.OpenAPISchema {
+	schema := &capresponse.OpenAPISchema{Type: "object", Properties: make(map[string]*capresponse.OpenAPISchema)}
+	var embedded []reflect.Type
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag := field.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name := strings.Split(tag, ",")[0]
+		fieldType := field.Type
+		for fieldType.Kind() == reflect.Ptr {
+			fieldType = fieldType.Elem()
+		}
+		if field.Anonymous && name == "" && fieldType.Kind() == reflect.Struct {
+			embedded = append(embedded, fieldType)
+			continue
+		}
+		if field.PkgPath != "" {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		schema.Properties[name] = b.schemaOf(field.Type)
+	}
+	for _, embeddedType := range embedded {
+		for name, property := range b.structSchema(embeddedType).Properties {
+			if _, ok := schema.Properties[name]; !ok {
+				schema.Properties[name] = property
+			}
+		}
+	}
+	return schema
+}
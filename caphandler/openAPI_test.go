@@ -0,0 +1,133 @@
+//(C) Copyright [2020] Hewlett Packard Enterprise Development LP
+//
+//Licensed under the Apache License, Version 2.0 (the "License"); you may
+//not use this file except in compliance with the License. You may obtain
+//a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+//Unless required by applicable law or agreed to in writing, software
+//distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+//WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+//License for the specific language governing permissions and limitations
+// under the License.
+
+package caphandler
+
+import (
+	"encoding/json"
+	"net/http"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/ODIM-Project/PluginCiscoACI/config"
+
+	iris "github.com/kataras/iris/v12"
+	"github.com/kataras/iris/v12/httptest"
+)
+
+func TestPortOpenAPI(t *testing.T) {
+	config.SetUpMockConfig(t)
+	data, err := json.Marshal(PortOpenAPI())
+	if err != nil {
+		t.Fatalf("error while marshalling the OpenAPI document: %v", err)
+	}
+	var doc map[string]interface{}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		t.Fatalf("error while unmarshalling the OpenAPI document: %v", err)
+	}
+	if doc["openapi"] != "3.0.3" {
+		t.Errorf("openapi = %v, want 3.0.3", doc["openapi"])
+	}
+	info, _ := doc["info"].(map[string]interface{})
+	if info["title"] == "" || info["version"] == "" {
+		t.Errorf("info %v without title or version", info)
+	}
+	schemas := doc["components"].(map[string]interface{})["schemas"].(map[string]interface{})
+
+	pathParamPattern := regexp.MustCompile(`{([^}]+)}`)
+	operationIDs := make(map[string]bool)
+	paths := doc["paths"].(map[string]interface{})
+	for _, path := range []string{portsPath, portPath} {
+		if _, ok := paths[path]; !ok {
+			t.Errorf("path %s is not described", path)
+		}
+	}
+	for path, item := range paths {
+		for method, op := range item.(map[string]interface{}) {
+			operation := op.(map[string]interface{})
+			id, _ := operation["operationId"].(string)
+			if id == "" || operationIDs[id] {
+				t.Errorf("%s %s: operationId %q is empty or not unique", method, path, id)
+			}
+			operationIDs[id] = true
+			if responses, _ := operation["responses"].(map[string]interface{}); len(responses) == 0 {
+				t.Errorf("%s %s has no responses", method, path)
+			}
+			declared := make(map[string]bool)
+			parameters, _ := operation["parameters"].([]interface{})
+			for _, p := range parameters {
+				parameter := p.(map[string]interface{})
+				if parameter["schema"] == nil {
+					t.Errorf("%s %s: parameter %v has no schema", method, path, parameter["name"])
+				}
+				if parameter["in"] == "path" {
+					if parameter["required"] != true {
+						t.Errorf("%s %s: path parameter %v is not required", method, path, parameter["name"])
+					}
+					declared[parameter["name"].(string)] = true
+				}
+			}
+			for _, match := range pathParamPattern.FindAllStringSubmatch(path, -1) {
+				if !declared[match[1]] {
+					t.Errorf("%s %s: path parameter %s is not declared", method, path, match[1])
+				}
+			}
+		}
+	}
+	if !operationIDs["PatchPort"] || !operationIDs["GetPortCollection"] {
+		t.Errorf("operations %v miss the port PATCH or the collection GET", operationIDs)
+	}
+
+	refs := 0
+	var checkRefs func(v interface{})
+	checkRefs = func(v interface{}) {
+		switch value := v.(type) {
+		case map[string]interface{}:
+			if ref, ok := value["$ref"].(string); ok {
+				refs++
+				if _, found := schemas[strings.TrimPrefix(ref, "#/components/schemas/")]; !strings.HasPrefix(ref, "#/components/schemas/") || !found {
+					t.Errorf("$ref %s doesn't resolve to a component schema", ref)
+				}
+			}
+			for _, child := range value {
+				checkRefs(child)
+			}
+		case []interface{}:
+			for _, child := range value {
+				checkRefs(child)
+			}
+		}
+	}
+	checkRefs(doc)
+	if refs == 0 {
+		t.Error("no schema of the document refers to a component schema")
+	}
+
+	port := schemas["Port"].(map[string]interface{})["properties"].(map[string]interface{})
+	for _, property := range []string{"Id", "@odata.id", "Oem", "Actions", "@Redfish.Settings"} {
+		if _, ok := port[property]; !ok {
+			t.Errorf("Port schema has no %s property", property)
+		}
+	}
+}
+
+func TestGetOpenAPI(t *testing.T) {
+	config.SetUpMockConfig(t)
+	mockApp := iris.New()
+	mockApp.Get("/ODIM/v1/openapi.json", GetOpenAPI)
+	e := httptest.New(t, mockApp)
+	e.GET("/ODIM/v1/openapi.json").Expect().Status(http.StatusOK).
+		JSON().Object().ValueEqual("openapi", "3.0.3").Value("paths").Object().ContainsKey(portPath)
+}
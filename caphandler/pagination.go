@@ -0,0 +1,135 @@
+//(C) Copyright [2020] Hewlett Packard Enterprise Development LP
+//
+//Licensed under the Apache License, Version 2.0 (the "License"); you may
+//not use this file except in compliance with the License. You may obtain
+//a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+//Unless required by applicable law or agreed to in writing, software
+//distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+//WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+//License for the specific language governing permissions and limitations
+// under the License.
+
+//Package caphandler ...
+package caphandler
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/ODIM-Project/ODIM/lib-utilities/response"
+	"github.com/ODIM-Project/PluginCiscoACI/config"
+	iris "github.com/kataras/iris/v12"
+	log "github.com/sirupsen/logrus"
+)
+
+// collectionPage holds the $top and $skip query options of a collection request
+type collectionPage struct {
+	top       int
+	skip      int
+	paginated bool
+}
+
+// getCollectionPage reads $top and $skip query options of the request,
+// 400 is written to the response and false returned when they are not valid.
+// $top above the configured MaxPageSize is clamped to it, the pagination links
+// of the response carry the clamped $top.
+func getCollectionPage(ctx iris.Context) (collectionPage, bool) {
+	var page collectionPage
+	var err error
+	query := ctx.Request().URL.Query()
+	if top := query.Get("$top"); top != "" {
+		if page.top, err = strconv.Atoi(top); err != nil || page.top < 0 {
+			writeQueryErrResp(ctx, "$top", top)
+			return page, false
+		}
+		if config.Data.ServerConf != nil && page.top > config.Data.ServerConf.MaxPageSize {
+			page.top = config.Data.ServerConf.MaxPageSize
+		}
+		page.paginated = true
+	}
+	if skip := query.Get("$skip"); skip != "" {
+		if page.skip, err = strconv.Atoi(skip); err != nil || page.skip < 0 {
+			writeQueryErrResp(ctx, "$skip", skip)
+			return page, false
+		}
+		page.paginated = true
+	}
+	if page.paginated && query.Get("$top") == "" {
+		page.top = -1
+	}
+	return page, true
+}
+
+// bounds returns the start and end index of the page in a collection of the given size
+func (page collectionPage) bounds(total int) (int, int) {
+	if !page.paginated {
+		return 0, total
+	}
+	start := page.skip
+	if start > total {
+		start = total
+	}
+	end := total
+	if page.top >= 0 && start+page.top < total {
+		end = start + page.top
+	}
+	return start, end
+}
+
+// setPaginationLinks sets the RFC 5988 Link header with first, prev, next and last
+// relations of the page and returns the link to the next page, if there is one
+func setPaginationLinks(ctx iris.Context, page collectionPage, total int) string {
+	if !page.paginated || page.top <= 0 {
+		return ""
+	}
+	lastSkip := 0
+	if total > 0 {
+		lastSkip = ((total - 1) / page.top) * page.top
+	}
+	links := []string{formatLink(ctx, page.top, 0, "first")}
+	if page.skip > 0 {
+		prevSkip := page.skip - page.top
+		if prevSkip < 0 {
+			prevSkip = 0
+		}
+		links = append(links, formatLink(ctx, page.top, prevSkip, "prev"))
+	}
+	var nextLink string
+	if page.skip+page.top < total {
+		nextLink = pageURI(ctx, page.top, page.skip+page.top)
+		links = append(links, formatLink(ctx, page.top, page.skip+page.top, "next"))
+	}
+	links = append(links, formatLink(ctx, page.top, lastSkip, "last"))
+	ctx.Header("Link", strings.Join(links, ", "))
+	return nextLink
+}
+
+func formatLink(ctx iris.Context, top, skip int, rel string) string {
+	return fmt.Sprintf("<%s>; rel=\"%s\"", pageURI(ctx, top, skip), rel)
+}
+
+// pageURI builds the request URI for the page, preserving the other query parameters of the request
+func pageURI(ctx iris.Context, top, skip int) string {
+	query := url.Values{}
+	for key, values := range ctx.Request().URL.Query() {
+		query[key] = values
+	}
+	query.Set("$top", strconv.Itoa(top))
+	query.Set("$skip", strconv.Itoa(skip))
+	// keeping the $ of the query options readable
+	return ctx.Path() + "?" + strings.Replace(query.Encode(), "%24", "$", -1)
+}
+
+func writeQueryErrResp(ctx iris.Context, option, value string) {
+	errMsg := fmt.Sprintf("invalid value %s for query option %s, a non-negative integer is expected", value, option)
+	log.Error(errMsg)
+	resp := updateErrorResponse(response.GeneralError, errMsg, nil)
+	ctx.StatusCode(http.StatusBadRequest)
+	writeErrorResponse(ctx, resp)
+}
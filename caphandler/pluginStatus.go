@@ -40,7 +40,7 @@ func GetPluginStatus(ctx iris.Context) {
 	token := ctx.GetHeader("X-Auth-Token")
 	//Validating the token
 	if token != "" {
-		flag := TokenValidation(token)
+		_, flag := TokenValidation(token)
 		if !flag {
 			log.Error("Invalid/Expired X-Auth-Token")
 			ctx.StatusCode(http.StatusUnauthorized)
@@ -54,10 +54,7 @@ func GetPluginStatus(ctx iris.Context) {
 		Name:    "Common Redfish Plugin Status",
 		Version: pluginConfig.Data.FirmwareVersion,
 	}
-	resp.Status = caputilities.Status
-	elapsedTime := time.Since(caputilities.PluginStartTime)
-	resp.Status.Uptime = elapsedTime.String()
-	resp.Status.TimeStamp = time.Now().Format(time.RFC3339)
+	resp.Status = getCurrentStatus()
 	resp.EventMessageBus = capresponse.EventMessageBus{
 		EmbType: pluginConfig.Data.MessageBusConf.EmbType,
 	}
@@ -79,6 +76,49 @@ func GetPluginStatus(ctx iris.Context) {
 
 }
 
+// GetPluginReadiness reports whether the plugin is ready to serve requests
+// along with the plugin start time and uptime. The health of the DB, checked with
+// a round-trip, is reported apart from the health of APIC.
+func GetPluginReadiness(ctx iris.Context) {
+	resp := capresponse.ReadinessResponse{
+		Status: getCurrentStatus(),
+		DB:     capresponse.DependencyHealth{Healthy: true},
+		APIC:   capresponse.DependencyHealth{Healthy: caputilities.Status.Available == "yes"},
+	}
+	if !resp.APIC.Healthy {
+		resp.APIC.Error = "the fabric data is not yet discovered from APIC"
+	}
+	if err := capmodel.Ping(ctx.Request().Context()); err != nil {
+		log.Error("readiness check of the DB failed: " + err.Error())
+		resp.DB = capresponse.DependencyHealth{Error: err.Error()}
+	}
+	resp.Ready = resp.DB.Healthy && resp.APIC.Healthy
+	if !resp.Ready {
+		ctx.StatusCode(http.StatusServiceUnavailable)
+		ctx.JSON(resp)
+		return
+	}
+	ctx.StatusCode(http.StatusOK)
+	ctx.JSON(resp)
+}
+
+// getCurrentStatus returns the plugin status with the start time and the uptime
+// computed, uptime is reported both as a duration string and in seconds, and with
+// the maintenance mode
+func getCurrentStatus() capresponse.Status {
+	status := caputilities.Status
+	elapsedTime := time.Since(caputilities.PluginStartTime).Truncate(time.Second)
+	status.StartTime = caputilities.PluginStartTime.Format(time.RFC3339)
+	status.Uptime = elapsedTime.String()
+	status.UptimeSeconds = int64(elapsedTime.Seconds())
+	status.TimeStamp = time.Now().Format(time.RFC3339)
+	if serverConf := config.CurrentServerConf(); serverConf != nil && serverConf.MaintenanceMode {
+		status.MaintenanceMode = true
+		status.MaintenanceReason = serverConf.MaintenanceReason
+	}
+	return status
+}
+
 // GetPluginStartup ...
 func GetPluginStartup(ctx iris.Context) {
 	// TODO: implementation pending
@@ -0,0 +1,94 @@
+//(C) Copyright [2020] Hewlett Packard Enterprise Development LP
+//
+//Licensed under the Apache License, Version 2.0 (the "License"); you may
+//not use this file except in compliance with the License. You may obtain
+//a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+//Unless required by applicable law or agreed to in writing, software
+//distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+//WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+//License for the specific language governing permissions and limitations
+// under the License.
+
+package caphandler
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/ODIM-Project/PluginCiscoACI/capmodel"
+	"github.com/ODIM-Project/PluginCiscoACI/caputilities"
+	"github.com/ODIM-Project/PluginCiscoACI/config"
+	"github.com/ODIM-Project/PluginCiscoACI/db"
+
+	iris "github.com/kataras/iris/v12"
+	"github.com/kataras/iris/v12/httptest"
+)
+
+func TestGetPluginReadiness(t *testing.T) {
+	config.SetUpMockConfig(t)
+	db.Connector = db.NewMockMemoryConnector()
+	mockApp := iris.New()
+	redfishRoutes := mockApp.Party("/ODIM/v1")
+	redfishRoutes.Get("/Readiness", GetPluginReadiness)
+	e := httptest.New(t, mockApp)
+
+	startTime := time.Now().Add(-90 * time.Second)
+	caputilities.PluginStartTime = startTime
+	caputilities.Status.Available = "yes"
+	resp := e.GET("/ODIM/v1/Readiness").Expect().Status(http.StatusOK).JSON().Object()
+	resp.Value("Ready").Boolean().True()
+	resp.Value("DB").Object().Value("Healthy").Boolean().True()
+	resp.Value("APIC").Object().Value("Healthy").Boolean().True()
+	status := resp.Value("Status").Object()
+	status.Value("StartTime").String().Equal(startTime.Format(time.RFC3339))
+	status.Value("UptimeSeconds").Number().Ge(90)
+	status.Value("Uptime").String().NotEmpty()
+
+	// the DB is reported unhealthy apart from APIC
+	db.Connector = unreachableDBConnector{db.NewMockMemoryConnector()}
+	resp = e.GET("/ODIM/v1/Readiness").Expect().Status(http.StatusServiceUnavailable).JSON().Object()
+	resp.Value("Ready").Boolean().False()
+	resp.Value("DB").Object().Value("Healthy").Boolean().False()
+	resp.Value("DB").Object().Value("Error").String().NotEmpty()
+	resp.Value("APIC").Object().Value("Healthy").Boolean().True()
+
+	db.Connector = db.NewMockMemoryConnector()
+	caputilities.Status.Available = ""
+	resp = e.GET("/ODIM/v1/Readiness").Expect().Status(http.StatusServiceUnavailable).JSON().Object()
+	resp.Value("Ready").Boolean().False()
+	resp.Value("DB").Object().Value("Healthy").Boolean().True()
+	resp.Value("APIC").Object().Value("Healthy").Boolean().False()
+}
+
+// unreachableDBConnector fails the pings as a DB which can't be reached
+type unreachableDBConnector struct {
+	db.MockMemoryConnector
+}
+
+func (d unreachableDBConnector) Ping(ctx context.Context) error {
+	return fmt.Errorf("%w: connection refused", db.ErrorServiceUnavailable)
+}
+
+func TestGetPluginStatusMaintenanceMode(t *testing.T) {
+	config.SetUpMockConfig(t)
+	mockApp := iris.New()
+	mockApp.Get("/ODIM/v1/Status", GetPluginStatus)
+	e := httptest.New(t, mockApp)
+	capmodel.PluginIntialStatus = true
+
+	status := e.GET("/ODIM/v1/Status").Expect().Status(http.StatusOK).JSON().Object().Value("Status").Object()
+	status.Value("MaintenanceMode").Boolean().False()
+	status.NotContainsKey("MaintenanceReason")
+
+	config.Data.ServerConf.MaintenanceMode = true
+	config.Data.ServerConf.MaintenanceReason = "leaf replacement"
+	status = e.GET("/ODIM/v1/Status").Expect().Status(http.StatusOK).JSON().Object().Value("Status").Object()
+	status.Value("MaintenanceMode").Boolean().True()
+	status.Value("MaintenanceReason").Equal("leaf replacement")
+}
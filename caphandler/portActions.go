@@ -0,0 +1,123 @@
+//(C) Copyright [2020] Hewlett Packard Enterprise Development LP
+//
+//Licensed under the Apache License, Version 2.0 (the "License"); you may
+//not use this file except in compliance with the License. You may obtain
+//a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+//Unless required by applicable law or agreed to in writing, software
+//distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+//WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+//License for the specific language governing permissions and limitations
+// under the License.
+
+//Package caphandler ...
+package caphandler
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+
+	"github.com/ODIM-Project/ODIM/lib-utilities/response"
+	"github.com/ODIM-Project/PluginCiscoACI/capmiddleware"
+	"github.com/ODIM-Project/PluginCiscoACI/capmodel"
+	"github.com/ODIM-Project/PluginCiscoACI/capresponse"
+	"github.com/ODIM-Project/PluginCiscoACI/captrace"
+	"github.com/ODIM-Project/PluginCiscoACI/caputilities"
+	"github.com/ODIM-Project/PluginCiscoACI/config"
+	iris "github.com/kataras/iris/v12"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	portResetAction = "Port.Reset"
+	// defaultPortResetType is the reset done when the request doesn't give the ResetType
+	defaultPortResetType = "ForceRestart"
+)
+
+// portResetTypes are the reset types of the Port.Reset action, mapped to the out of service
+// states the port is set in APIC in turn
+var portResetTypes = map[string][]bool{
+	"On":           {false},
+	"ForceOff":     {true},
+	"ForceRestart": {true, false},
+}
+
+// setPortOutOfService is the APIC call resetting the ports, replaced in unit tests
+var setPortOutOfService = caputilities.SetPortOutOfService
+
+// portActions returns the actions enabled on the port, nil when none is enabled
+func portActions(portURI string) *capresponse.PortActions {
+	if !config.Data.APICConf.PortResetEnabled {
+		return nil
+	}
+	return &capresponse.PortActions{
+		Reset: &capresponse.ResetAction{
+			Target:          portURI + "/Actions/" + portResetAction,
+			AllowableValues: []string{"On", "ForceOff", "ForceRestart"},
+		},
+	}
+}
+
+// ResetPort resets the port with the Port.Reset action, by taking the port out of service in APIC
+// and back. The action is supported only when PortResetEnabled is configured.
+func ResetPort(ctx iris.Context) {
+	portURI := requestedPortURI(ctx)
+	span := captrace.StartHandlerSpan(ctx, "ResetPort")
+	defer span.End()
+	setWriteCacheControl(ctx)
+	if !config.Data.APICConf.PortResetEnabled {
+		errMsg := "the Port.Reset action is not enabled on the ports"
+		log.Error(errMsg)
+		ctx.StatusCode(http.StatusBadRequest)
+		writeErrorResponse(ctx, withResource(updateErrorResponse(response.ActionNotSupported, errMsg, []interface{}{portResetAction}), resourceRef{portODataType, portURI}))
+		return
+	}
+	var request struct {
+		ResetType string `json:"ResetType"`
+	}
+	body, err := ioutil.ReadAll(ctx.Request().Body)
+	if err == nil && len(body) > 0 {
+		err = json.Unmarshal(body, &request)
+	}
+	if err != nil {
+		errMsg := "error while trying to get JSON body from the request: " + err.Error()
+		log.Error(errMsg)
+		ctx.StatusCode(http.StatusBadRequest)
+		writeErrorResponse(ctx, updateErrorResponse(response.MalformedJSON, errMsg, nil))
+		return
+	}
+	if request.ResetType == "" {
+		request.ResetType = defaultPortResetType
+	}
+	outOfServiceStates, ok := portResetTypes[request.ResetType]
+	if !ok {
+		errMsg := fmt.Sprintf("invalid ResetType %s for %s", request.ResetType, portResetAction)
+		log.Error(errMsg)
+		ctx.StatusCode(http.StatusBadRequest)
+		writeErrorResponse(ctx, updateErrorResponse(response.PropertyValueNotInList, errMsg, []interface{}{request.ResetType, "ResetType"}))
+		return
+	}
+	podID, portData, ok := lookupRequestedPort(ctx, portURI)
+	if !ok {
+		return
+	}
+	nodeID := capmodel.SwitchNodeID(ctx.Params().Get("switchID"))
+	for _, outOfService := range outOfServiceStates {
+		apicSpan := startAPICSpan(span, "caputilities.SetPortOutOfService")
+		err := setPortOutOfService(apicSpan.TraceParent(), podID, nodeID, portData.PortID, outOfService)
+		apicSpan.RecordError(err)
+		apicSpan.End()
+		if err != nil {
+			errMsg := fmt.Sprintf("failed to reset port %s with ResetType %s: %s", portURI, request.ResetType, err.Error())
+			statusCode, resp := createAPICErrResp(nil, err, errMsg, nil)
+			writeAPICErrResp(ctx, err, statusCode, withResource(resp, resourceRef{portODataType, portURI}))
+			return
+		}
+	}
+	capmiddleware.RequestLogger(ctx).Info(fmt.Sprintf("port %s reset with ResetType %s", portURI, request.ResetType))
+	ctx.StatusCode(http.StatusNoContent)
+}
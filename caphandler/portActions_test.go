@@ -0,0 +1,74 @@
+//(C) Copyright [2020] Hewlett Packard Enterprise Development LP
+//
+//Licensed under the Apache License, Version 2.0 (the "License"); you may
+//not use this file except in compliance with the License. You may obtain
+//a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+//Unless required by applicable law or agreed to in writing, software
+//distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+//WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+//License for the specific language governing permissions and limitations
+// under the License.
+
+package caphandler
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/ODIM-Project/ODIM/lib-dmtf/model"
+	"github.com/ODIM-Project/PluginCiscoACI/capdata"
+	"github.com/ODIM-Project/PluginCiscoACI/capmodel"
+	"github.com/ODIM-Project/PluginCiscoACI/caputilities"
+	"github.com/ODIM-Project/PluginCiscoACI/config"
+)
+
+const testPortResetURI = testPortURI + "/Actions/Port.Reset"
+
+func TestGetPortInfoActions(t *testing.T) {
+	e := mockPortApp(t)
+	config.Data.APICConf.DisableLiveEnrichment = true
+	capmodel.SaveFabric(testFabricID, &capdata.Fabric{PodID: "1", SwitchData: []string{testSwitchID}})
+	capmodel.SavePort(testPortURI, &model.Port{ODataID: testPortURI, ID: testPortID, PortID: "eth1/1"})
+	defer func() { config.Data.APICConf.PortResetEnabled = false }()
+
+	// no action is enabled by default
+	e.GET(testPortURI).Expect().Status(http.StatusOK).JSON().Object().NotContainsKey("Actions")
+
+	config.Data.APICConf.PortResetEnabled = true
+	reset := e.GET(testPortURI).Expect().Status(http.StatusOK).JSON().Path("$.Actions['#Port.Reset']").Object()
+	reset.Value("target").Equal(testPortResetURI)
+	reset.Value("ResetType@Redfish.AllowableValues").Array().ContainsOnly("On", "ForceOff", "ForceRestart")
+}
+
+func TestResetPort(t *testing.T) {
+	e := mockPortApp(t)
+	capmodel.SaveFabric(testFabricID, &capdata.Fabric{PodID: "1", SwitchData: []string{testSwitchID}})
+	capmodel.SavePort(testPortURI, &model.Port{ODataID: testPortURI, ID: testPortID, PortID: "eth1/1"})
+	var states []string
+	setPortOutOfService = func(traceParent, podID, ACISwitchID, portID string, outOfService bool) error {
+		states = append(states, fmt.Sprintf("%s/%s/%s/%t", podID, ACISwitchID, portID, outOfService))
+		return nil
+	}
+	defer func() {
+		setPortOutOfService = caputilities.SetPortOutOfService
+		config.Data.APICConf.PortResetEnabled = false
+	}()
+
+	// the action is not enabled
+	e.POST(testPortResetURI).WithJSON(map[string]string{"ResetType": "ForceRestart"}).Expect().Status(http.StatusBadRequest)
+
+	config.Data.APICConf.PortResetEnabled = true
+	e.POST(testPortResetURI).WithJSON(map[string]string{"ResetType": "ForceOff"}).Expect().Status(http.StatusNoContent)
+	e.POST(testPortResetURI).WithJSON(map[string]string{"ResetType": "On"}).Expect().Status(http.StatusNoContent)
+	// ForceRestart is the default reset
+	e.POST(testPortResetURI).Expect().Status(http.StatusNoContent)
+	e.POST(testPortResetURI).WithJSON(map[string]string{"ResetType": "GracefulRestart"}).Expect().Status(http.StatusBadRequest)
+	want := []string{"1/101/eth1/1/true", "1/101/eth1/1/false", "1/101/eth1/1/true", "1/101/eth1/1/false"}
+	if fmt.Sprint(states) != fmt.Sprint(want) {
+		t.Errorf("port out of service states set in APIC = %v, want %v", states, want)
+	}
+}
@@ -0,0 +1,218 @@
+//(C) Copyright [2020] Hewlett Packard Enterprise Development LP
+//
+//Licensed under the Apache License, Version 2.0 (the "License"); you may
+//not use this file except in compliance with the License. You may obtain
+//a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+//Unless required by applicable law or agreed to in writing, software
+//distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+//WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+//License for the specific language governing permissions and limitations
+// under the License.
+
+package caphandler
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ODIM-Project/ODIM/lib-utilities/common"
+	"github.com/ODIM-Project/ODIM/lib-utilities/response"
+	"github.com/ODIM-Project/PluginCiscoACI/capmodel"
+	"github.com/ODIM-Project/PluginCiscoACI/config"
+	"github.com/ODIM-Project/PluginCiscoACI/constants"
+	iris "github.com/kataras/iris/v12"
+	uuid "github.com/satori/go.uuid"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	// portEventStreamBuffer is the number of events held for a client, the events sent to a client
+	// not reading them fast enough are dropped for this client once its buffer is full
+	portEventStreamBuffer = 32
+	// portEventStreamKeepAlive is how often a comment is sent on an idle stream, so the
+	// disconnected clients are detected and the proxies don't close the idle stream
+	portEventStreamKeepAlive = 30 * time.Second
+)
+
+// errTooManyPortEventStreams is returned when MaxPortEventStreams clients are already connected
+var errTooManyPortEventStreams = errors.New("too many clients connected to the port events")
+
+// portEventStreams holds the clients connected to the stream of the port events
+var portEventStreams = &portEventHub{clients: map[*portEventClient]bool{}}
+
+// portEventHub dispatches the port events to the connected clients
+type portEventHub struct {
+	lock    sync.Mutex
+	clients map[*portEventClient]bool
+}
+
+// portEventClient is a client connected to the stream of the port events, which are
+// filtered by fabric and switch when those are set
+type portEventClient struct {
+	fabricID, switchID string
+	events             chan []byte
+}
+
+// StreamPortEvents streams the state changes of the ports to the client as server-sent events,
+// the events are the link state changes notified by APIC and the changes of the state reported
+// for the ports, whether read on a port GET or by the health poll. The fabric and switch query
+// parameters restrict the events to the ports of the fabric and of the switch.
+func StreamPortEvents(ctx iris.Context) {
+	flusher, ok := ctx.ResponseWriter().Naive().(http.Flusher)
+	if !ok {
+		errMsg := "streaming of the port events is not supported by the response writer"
+		log.Error(errMsg)
+		ctx.StatusCode(http.StatusInternalServerError)
+		writeErrorResponse(ctx, updateErrorResponse(response.InternalError, errMsg, nil))
+		return
+	}
+	client, err := portEventStreams.subscribe(ctx.URLParam("fabric"), ctx.URLParam("switch"))
+	if err != nil {
+		errMsg := fmt.Sprintf("%s, at most %d streams are served", err.Error(), config.Data.ServerConf.MaxPortEventStreams)
+		log.Warn(errMsg)
+		ctx.StatusCode(http.StatusServiceUnavailable)
+		writeErrorResponse(ctx, updateErrorResponse(response.GeneralError, errMsg, nil))
+		return
+	}
+	defer portEventStreams.unsubscribe(client)
+
+	header := ctx.ResponseWriter().Header()
+	header.Set("Content-Type", "text/event-stream")
+	header.Set("Cache-Control", "no-cache")
+	ctx.StatusCode(http.StatusOK)
+	writer := ctx.ResponseWriter()
+	writer.Write([]byte(": connected\n\n"))
+	flusher.Flush()
+
+	keepAlive := time.NewTicker(portEventStreamKeepAlive)
+	defer keepAlive.Stop()
+	for {
+		var err error
+		select {
+		case <-ctx.Request().Context().Done():
+			return
+		case event := <-client.events:
+			_, err = writer.Write(event)
+		case <-keepAlive.C:
+			_, err = writer.Write([]byte(": keep-alive\n\n"))
+		}
+		if err != nil {
+			log.Info("port events client disconnected: " + err.Error())
+			return
+		}
+		flusher.Flush()
+	}
+}
+
+// subscribe connects a client to the port events of the fabric and switch
+func (h *portEventHub) subscribe(fabricID, switchID string) (*portEventClient, error) {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+	if len(h.clients) >= config.Data.ServerConf.MaxPortEventStreams {
+		return nil, errTooManyPortEventStreams
+	}
+	client := &portEventClient{
+		fabricID: fabricID,
+		switchID: switchID,
+		events:   make(chan []byte, portEventStreamBuffer),
+	}
+	h.clients[client] = true
+	return client, nil
+}
+
+// unsubscribe disconnects the client from the port events
+func (h *portEventHub) unsubscribe(client *portEventClient) {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+	delete(h.clients, client)
+}
+
+// count returns the number of connected clients
+func (h *portEventHub) count() int {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+	return len(h.clients)
+}
+
+// broadcast sends the events of the message to the clients of the ports they originate from,
+// each event is sent as a server-sent event with the message restricted to this event
+func (h *portEventHub) broadcast(message *common.MessageData) {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+	if len(h.clients) == 0 {
+		return
+	}
+	for _, event := range message.Events {
+		if event.OriginOfCondition == nil {
+			continue
+		}
+		eventMessage := *message
+		eventMessage.Events = []common.Event{event}
+		data, err := json.Marshal(eventMessage)
+		if err != nil {
+			log.Error("while marshalling port event, got: " + err.Error())
+			continue
+		}
+		frame := []byte(fmt.Sprintf("id: %s\nevent: %s\ndata: %s\n\n", event.EventID, event.EventType, data))
+		for client := range h.clients {
+			if !client.matches(event.OriginOfCondition.Oid) {
+				continue
+			}
+			select {
+			case client.events <- frame:
+			default:
+				log.Warn("port events client is not reading the events, dropped event " + event.EventID)
+			}
+		}
+	}
+}
+
+// matches reports whether the port with the given OID is of the fabric and switch of the client
+func (c *portEventClient) matches(portOID string) bool {
+	// the port OIDs are like /ODIM/v1/Fabrics/{fabricID}/Switches/{switchID}/Ports/{portID}
+	segments := strings.Split(strings.TrimPrefix(portOID, "/ODIM/v1/Fabrics/"), "/")
+	if len(segments) != 5 || segments[1] != "Switches" || segments[3] != "Ports" {
+		return false
+	}
+	return (c.fabricID == "" || c.fabricID == segments[0]) && (c.switchID == "" || c.switchID == segments[2])
+}
+
+// newPortStateEvent builds the event of the change of the state reported for the port,
+// its severity follows the health and the link state of the port
+func newPortStateEvent(portOID string, state capmodel.PortState) *common.MessageData {
+	health := state.Health
+	if health == "" {
+		health = "Unknown"
+	}
+	event := common.Event{
+		EventID:           uuid.NewV4().String(),
+		EventType:         "StatusChange",
+		EventTimestamp:    time.Now().Format(time.RFC3339),
+		Severity:          "OK",
+		MessageID:         constants.ResourceStatusChangedOKMessageID,
+		Message:           fmt.Sprintf("The state of the port changed to link %s and health %s", state.LinkState, health),
+		OriginOfCondition: &common.Link{Oid: portOID},
+	}
+	switch {
+	case state.Health == "Critical":
+		event.Severity = "Critical"
+		event.MessageID = constants.ResourceStatusChangedCriticalMessageID
+	case state.Health == "Warning" || state.LinkState != "Enabled":
+		event.Severity = "Warning"
+		event.MessageID = constants.ResourceStatusChangedWarningMessageID
+	}
+	return &common.MessageData{
+		Name:      "Port state changed event",
+		Context:   "/redfish/v1/$metadata#Event.Event",
+		OdataType: constants.EventODataType,
+		Events:    []common.Event{event},
+	}
+}
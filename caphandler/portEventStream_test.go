@@ -0,0 +1,179 @@
+//(C) Copyright [2020] Hewlett Packard Enterprise Development LP
+//
+//Licensed under the Apache License, Version 2.0 (the "License"); you may
+//not use this file except in compliance with the License. You may obtain
+//a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+//Unless required by applicable law or agreed to in writing, software
+//distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+//WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+//License for the specific language governing permissions and limitations
+// under the License.
+
+package caphandler
+
+import (
+	"bufio"
+	"context"
+	"net/http"
+	nethttptest "net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ODIM-Project/PluginCiscoACI/capdata"
+	"github.com/ODIM-Project/PluginCiscoACI/capmessagebus"
+	"github.com/ODIM-Project/PluginCiscoACI/capmodel"
+	"github.com/ODIM-Project/PluginCiscoACI/caputilities"
+	"github.com/ODIM-Project/PluginCiscoACI/config"
+	iris "github.com/kataras/iris/v12"
+)
+
+const testPortEventsURI = "/ODIM/v1/PortEvents"
+
+func mockPortEventServer(t *testing.T) *nethttptest.Server {
+	mockPortApp(t)
+	capmodel.SaveFabric(testFabricID, &capdata.Fabric{PodID: "1", SwitchData: []string{testSwitchID}})
+	EventQueue = capmessagebus.NewEventBuffer(config.DefaultEventBufferCapacity, config.EventOverflowDropOldest, time.Second)
+	app := iris.New()
+	app.Get(testPortEventsURI, StreamPortEvents)
+	if err := app.Build(); err != nil {
+		t.Fatal(err)
+	}
+	server := nethttptest.NewServer(app)
+	t.Cleanup(server.Close)
+	return server
+}
+
+// connectPortEvents opens the stream of the port events, it returns once the client is connected
+func connectPortEvents(ctx context.Context, t *testing.T, url string) *bufio.Reader {
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("failed to connect to the port events: %v", err)
+	}
+	if resp.StatusCode != http.StatusOK || resp.Header.Get("Content-Type") != "text/event-stream" {
+		t.Fatalf("port events response %d of %s, want 200 of text/event-stream", resp.StatusCode, resp.Header.Get("Content-Type"))
+	}
+	reader := bufio.NewReader(resp.Body)
+	if line, err := reader.ReadString('\n'); err != nil || line != ": connected\n" {
+		t.Fatalf("first line of the port events = %q, %v, want the connected comment", line, err)
+	}
+	return reader
+}
+
+func waitPortEventStreams(t *testing.T, want int) {
+	deadline := time.Now().Add(time.Second)
+	for portEventStreams.count() != want {
+		if time.Now().After(deadline) {
+			t.Fatalf("%d clients connected to the port events, want %d", portEventStreams.count(), want)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestStreamPortEvents(t *testing.T) {
+	server := mockPortEventServer(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	all := connectPortEvents(ctx, t, server.URL+testPortEventsURI)
+	otherSwitch := connectPortEvents(ctx, t, server.URL+testPortEventsURI+"?fabric="+testFabricID+"&switch=switchUUID:102")
+	waitPortEventStreams(t, 2)
+
+	publishAPICNotification(caputilities.APICNotification{
+		Class:      "ethpmPhysIf",
+		Attributes: map[string]interface{}{"dn": "topology/pod-1/node-101/sys/phys-[eth1/1]/phys", "operSt": "down"},
+	})
+	var frame []string
+	for len(frame) == 0 || frame[len(frame)-1] != "\n" {
+		line, err := all.ReadString('\n')
+		if err != nil {
+			t.Fatalf("failed to read the port event: %v", err)
+		}
+		frame = append(frame, line)
+	}
+	if len(frame) != 4 || !strings.HasPrefix(frame[0], "id: ") || frame[1] != "event: StatusChange\n" ||
+		!strings.Contains(frame[2], testPortURI) {
+		t.Errorf("port event = %q, want the link state change of %s", frame, testPortURI)
+	}
+
+	// the event of the port of another switch is filtered out
+	otherSwitchEvent := make(chan string, 1)
+	go func() {
+		line, _ := otherSwitch.ReadString('\n')
+		otherSwitchEvent <- line
+	}()
+	select {
+	case line := <-otherSwitchEvent:
+		t.Errorf("port events of switchUUID:102 got %q, want no event", line)
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	// the clients are disconnected once they close the stream
+	cancel()
+	waitPortEventStreams(t, 0)
+}
+
+func TestStreamPortEventsBounded(t *testing.T) {
+	server := mockPortEventServer(t)
+	config.Data.ServerConf.MaxPortEventStreams = 1
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	connectPortEvents(ctx, t, server.URL+testPortEventsURI)
+
+	resp, err := http.Get(server.URL + testPortEventsURI)
+	if err != nil {
+		t.Fatalf("failed to connect to the port events: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusServiceUnavailable {
+		t.Errorf("port events beyond MaxPortEventStreams status = %d, want 503", resp.StatusCode)
+	}
+	cancel()
+	waitPortEventStreams(t, 0)
+}
+
+func TestStreamPortStateChanges(t *testing.T) {
+	server := mockPortEventServer(t)
+	config.Data.APICConf.PortFlapGraceInSeconds = 0
+	capmodel.UpdatePortState(testPortURI, capmodel.PortState{LinkState: "Enabled", LinkStatus: "LinkUp", Health: "OK"})
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	events := connectPortEvents(ctx, t, server.URL+testPortEventsURI)
+	waitPortEventStreams(t, 1)
+
+	// the health read by the poll is streamed when it changes the state reported for the port
+	if err := storePortHealth(testPortURI, "Critical"); err != nil {
+		t.Fatalf("failed to store the health of the port: %v", err)
+	}
+	var frame []string
+	for len(frame) == 0 || frame[len(frame)-1] != "\n" {
+		line, err := events.ReadString('\n')
+		if err != nil {
+			t.Fatalf("failed to read the port event: %v", err)
+		}
+		frame = append(frame, line)
+	}
+	if len(frame) != 4 || !strings.Contains(frame[2], testPortURI) || !strings.Contains(frame[2], "ResourceStatusChangedCritical") {
+		t.Errorf("port event = %q, want the Critical state change of %s", frame, testPortURI)
+	}
+
+	// the same state stored again isn't streamed
+	if err := storePortHealth(testPortURI, "Critical"); err != nil {
+		t.Fatalf("failed to store the health of the port: %v", err)
+	}
+	unchanged := make(chan string, 1)
+	go func() {
+		line, _ := events.ReadString('\n')
+		unchanged <- line
+	}()
+	select {
+	case line := <-unchanged:
+		t.Errorf("port events got %q for an unchanged state, want no event", line)
+	case <-time.After(100 * time.Millisecond):
+	}
+	cancel()
+	waitPortEventStreams(t, 0)
+}
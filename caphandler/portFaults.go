@@ -0,0 +1,149 @@
+//(C) Copyright [2020] Hewlett Packard Enterprise Development LP
+//
+//Licensed under the Apache License, Version 2.0 (the "License"); you may
+//not use this file except in compliance with the License. You may obtain
+//a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+//Unless required by applicable law or agreed to in writing, software
+//distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+//WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+//License for the specific language governing permissions and limitations
+// under the License.
+
+//Package caphandler ...
+package caphandler
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/ODIM-Project/ODIM/lib-dmtf/model"
+	"github.com/ODIM-Project/ODIM/lib-utilities/response"
+	"github.com/ODIM-Project/PluginCiscoACI/capmodel"
+	"github.com/ODIM-Project/PluginCiscoACI/capresponse"
+	"github.com/ODIM-Project/PluginCiscoACI/captrace"
+	"github.com/ODIM-Project/PluginCiscoACI/caputilities"
+	iris "github.com/kataras/iris/v12"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	// defaultPortFaultSeverity is the minimum severity of the port faults when not requested
+	defaultPortFaultSeverity = "major"
+	portFaultsODataType      = "#CiscoACIPortFaults.v1_0_0.PortFaults"
+)
+
+// APIC call used for collecting the faults of the ports, replaced in unit tests
+var getPortFaults = caputilities.GetPortFaults
+
+// GetPortFaults returns the ports of the fabric, or of the switch, with an APIC fault of the severity
+// query parameter or more severe, major when not given, along with the worst fault of each port. The
+// faults are read in a single APIC query of the pod or of the switch, not per port.
+func GetPortFaults(ctx iris.Context) {
+	fabricID := ctx.Params().Get("id")
+	switchID := ctx.Params().Get("switchID")
+	span := captrace.StartHandlerSpan(ctx, "GetPortFaults")
+	defer span.End()
+	severity := defaultPortFaultSeverity
+	if ctx.URLParamExists("severity") {
+		severity = ctx.URLParam("severity")
+	}
+	minRank := caputilities.FaultSeverityRank(severity)
+	if minRank < 0 {
+		errMsg := fmt.Sprintf("invalid value %s for query parameter severity, it should be one of %s",
+			severity, strings.Join(caputilities.FaultSeverities, ", "))
+		log.Error(errMsg)
+		resp := updateErrorResponse(response.GeneralError, errMsg, nil)
+		ctx.StatusCode(http.StatusBadRequest)
+		writeErrorResponse(ctx, resp)
+		return
+	}
+	fabricData, err := capmodel.GetFabric(fabricID)
+	if err != nil {
+		errMsg := fmt.Sprintf("failed to fetch port faults for uri %s: %s", ctx.Path(), err.Error())
+		createResourceDbErrResp(ctx, err, errMsg, []interface{}{"Fabric", fabricID}, resourceRef{fabricODataType, "/ODIM/v1/Fabrics/" + fabricID})
+		return
+	}
+	switches := fabricData.SwitchData
+	var nodeID string
+	if switchID != "" {
+		if !checkSwitchExists(ctx, switchID) {
+			return
+		}
+		switches, nodeID = []string{switchID}, capmodel.SwitchNodeID(switchID)
+	}
+	apicSpan := startAPICSpan(span, "caputilities.GetPortFaults")
+	faults, err := getPortFaults(apicSpan.TraceParent(), fabricData.PodID, nodeID)
+	apicSpan.RecordError(err)
+	apicSpan.End()
+	if err != nil {
+		errMsg := fmt.Sprintf("failed to fetch port faults for uri %s: %s", ctx.Path(), err.Error())
+		statusCode, resp := createAPICErrResp(nil, err, errMsg, nil)
+		writeAPICErrResp(ctx, err, statusCode, resp)
+		return
+	}
+
+	portFaults := capresponse.PortFaults{
+		ODataID:     ctx.Path(),
+		ODataType:   portFaultsODataType,
+		ID:          "PortFaults",
+		Name:        "Port Faults",
+		MinSeverity: severity,
+		// the members are always present so that clients can iterate them without checking for null
+		Members: []capresponse.PortFaultMember{},
+	}
+	for _, id := range switches {
+		switchFaults := faults[capmodel.SwitchNodeID(id)]
+		if len(switchFaults) == 0 {
+			continue
+		}
+		ports, err := capmodel.GetSwitchPort(id)
+		if err != nil {
+			errMsg := fmt.Sprintf("failed to fetch ports of switch %s: %s", id, err.Error())
+			createResourceDbErrResp(ctx, err, errMsg, []interface{}{"Switch", id}, resourceRef{switchODataType, fmt.Sprintf("/ODIM/v1/Fabrics/%s/Switches/%s", fabricID, id)})
+			return
+		}
+		for apicPortID, fault := range switchFaults {
+			if caputilities.FaultSeverityRank(fault.Severity) < minRank {
+				continue
+			}
+			portID := findSwitchPort(ports, apicPortID)
+			if portID == "" {
+				continue
+			}
+			portFaults.Members = append(portFaults.Members, capresponse.PortFaultMember{
+				Port:        &model.Link{Oid: fmt.Sprintf("/ODIM/v1/Fabrics/%s/Switches/%s/Ports/%s", fabricID, id, portID)},
+				Code:        fault.Code,
+				Severity:    fault.Severity,
+				Description: fault.Description,
+				Created:     fault.Created,
+			})
+		}
+	}
+	sort.Slice(portFaults.Members, func(i, j int) bool {
+		a, b := portFaults.Members[i], portFaults.Members[j]
+		if rankA, rankB := caputilities.FaultSeverityRank(a.Severity), caputilities.FaultSeverityRank(b.Severity); rankA != rankB {
+			return rankA > rankB
+		}
+		return a.Port.Oid < b.Port.Oid
+	})
+	portFaults.MembersCount = len(portFaults.Members)
+	ctx.StatusCode(http.StatusOK)
+	ctx.JSON(portFaults)
+}
+
+// findSwitchPort returns the id of the stored port of the switch for the APIC port id, like eth1/1,
+// empty when the port isn't stored
+func findSwitchPort(ports []string, apicPortID string) string {
+	suffix := ":" + strings.Replace(apicPortID, "/", "-", -1)
+	for _, id := range ports {
+		if strings.HasSuffix(id, suffix) {
+			return id
+		}
+	}
+	return ""
+}
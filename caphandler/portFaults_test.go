@@ -0,0 +1,87 @@
+//(C) Copyright [2020] Hewlett Packard Enterprise Development LP
+//
+//Licensed under the Apache License, Version 2.0 (the "License"); you may
+//not use this file except in compliance with the License. You may obtain
+//a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+//Unless required by applicable law or agreed to in writing, software
+//distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+//WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+//License for the specific language governing permissions and limitations
+// under the License.
+
+package caphandler
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/ODIM-Project/ODIM/lib-dmtf/model"
+	"github.com/ODIM-Project/PluginCiscoACI/capdata"
+	"github.com/ODIM-Project/PluginCiscoACI/capmodel"
+	"github.com/ODIM-Project/PluginCiscoACI/caputilities"
+)
+
+func TestGetPortFaults(t *testing.T) {
+	e := mockPortApp(t)
+	otherSwitchID := "switchUUID:102"
+	capmodel.SaveFabric(testFabricID, &capdata.Fabric{PodID: "1", SwitchData: []string{testSwitchID, otherSwitchID}})
+	capmodel.SaveSwitch(otherSwitchID, &model.Switch{ID: otherSwitchID})
+	capmodel.SaveSwitchPort(otherSwitchID, []string{"portUUID:eth1-2", "portUUID:eth1-49-1"})
+	var requested []string
+	getPortFaults = func(traceParent, podID, ACISwitchID string) (map[string]map[string]capmodel.PortFault, error) {
+		requested = append(requested, podID+"/"+ACISwitchID)
+		faults := map[string]map[string]capmodel.PortFault{
+			"101": {
+				"eth1/1": {Code: "F1678", Severity: "major"},
+				// the port isn't stored
+				"eth1/9": {Code: "F0532", Severity: "critical"},
+			},
+			"102": {
+				"eth1/2":    {Code: "F0532", Severity: "warning"},
+				"eth1/49/1": {Code: "F0532", Severity: "critical"},
+			},
+		}
+		if ACISwitchID != "" {
+			return map[string]map[string]capmodel.PortFault{ACISwitchID: faults[ACISwitchID]}, nil
+		}
+		return faults, nil
+	}
+	defer func() {
+		getPortFaults = caputilities.GetPortFaults
+	}()
+	fabricFaultsURI := "/ODIM/v1/Fabrics/fabricID/Oem/CiscoACI/PortFaults"
+	otherPortURI := "/ODIM/v1/Fabrics/fabricID/Switches/switchUUID:102/Ports/portUUID:eth1-49-1"
+
+	// major and critical faults by default, the most severe first
+	faults := e.GET(fabricFaultsURI).Expect().Status(http.StatusOK).JSON().Object()
+	faults.Value("MinSeverity").Equal("major")
+	faults.Value("Members@odata.count").Number().Equal(2)
+	faults.Path("$.Members[0]").Object().ValueEqual("Severity", "critical").ValueEqual("Code", "F0532").
+		Path("$.Port['@odata.id']").Equal(otherPortURI)
+	faults.Path("$.Members[1]").Object().ValueEqual("Severity", "major").
+		Path("$.Port['@odata.id']").Equal(testPortURI)
+
+	faults = e.GET(fabricFaultsURI).WithQuery("severity", "warning").Expect().Status(http.StatusOK).JSON().Object()
+	faults.Value("Members@odata.count").Number().Equal(3)
+	faults.Path("$.Members[2].Severity").Equal("warning")
+
+	faults = e.GET(fabricFaultsURI).WithQuery("severity", "critical").Expect().Status(http.StatusOK).JSON().Object()
+	faults.Value("Members@odata.count").Number().Equal(1)
+
+	// the faults of a switch are read in the query of the switch only
+	faults = e.GET("/ODIM/v1/Fabrics/fabricID/Switches/switchUUID:101/Oem/CiscoACI/PortFaults").Expect().Status(http.StatusOK).JSON().Object()
+	faults.Value("Members@odata.count").Number().Equal(1)
+	faults.Path("$.Members[0].Port['@odata.id']").Equal(testPortURI)
+
+	for _, severity := range []string{"", "fatal", "Major"} {
+		e.GET(fabricFaultsURI).WithQuery("severity", severity).Expect().Status(http.StatusBadRequest)
+	}
+	e.GET("/ODIM/v1/Fabrics/fabricID/Switches/switchUUID:103/Oem/CiscoACI/PortFaults").Expect().Status(http.StatusNotFound)
+	if want := []string{"1/", "1/", "1/", "1/101"}; fmt.Sprint(requested) != fmt.Sprint(want) {
+		t.Errorf("port faults requested from APIC = %v, want %v", requested, want)
+	}
+}
@@ -0,0 +1,81 @@
+//(C) Copyright [2020] Hewlett Packard Enterprise Development LP
+//
+//Licensed under the Apache License, Version 2.0 (the "License"); you may
+//not use this file except in compliance with the License. You may obtain
+//a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+//Unless required by applicable law or agreed to in writing, software
+//distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+//WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+//License for the specific language governing permissions and limitations
+// under the License.
+
+//Package caphandler ...
+package caphandler
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/ODIM-Project/PluginCiscoACI/capmodel"
+	"github.com/ODIM-Project/PluginCiscoACI/caputilities"
+)
+
+// switchPortsHealthTTL is how long the health of the ports read at once for a switch is used, so that
+// the ports of a switch fetched one after the other by a client are served from a single APIC query
+const switchPortsHealthTTL = 10 * time.Second
+
+// APIC call used for collecting the health of all the ports of a switch, replaced in unit tests
+var getSwitchPortsHealth = caputilities.GetSwitchPortsHealth
+
+// switchPortsHealth is the health of the ports of a switch read at once
+type switchPortsHealth struct {
+	lock    sync.Mutex
+	fetched time.Time
+	health  map[string]capmodel.HealthData
+}
+
+var (
+	switchPortsHealthLock  sync.Mutex
+	switchPortsHealthCache = map[string]*switchPortsHealth{}
+)
+
+// getPortHealthFromSwitch returns the health of the port out of the health of all the ports of the switch,
+// which is read again from APIC once it is older than switchPortsHealthTTL. The concurrent requests on the
+// ports of a switch wait for the single APIC query. ErrAPICResponseMalformed is returned for a port without
+// health score in APIC, like with GetPortHealth. The APIC query carries the trace context of traceParent.
+func getPortHealthFromSwitch(traceParent, podID, ACISwitchID, portID string) (*capmodel.Health, error) {
+	switchPortsHealthLock.Lock()
+	key := podID + "/" + ACISwitchID
+	entry, ok := switchPortsHealthCache[key]
+	if !ok {
+		entry = &switchPortsHealth{}
+		switchPortsHealthCache[key] = entry
+	}
+	switchPortsHealthLock.Unlock()
+
+	entry.lock.Lock()
+	defer entry.lock.Unlock()
+	if entry.health == nil || time.Since(entry.fetched) >= switchPortsHealthTTL {
+		health, err := getSwitchPortsHealth(traceParent, podID, ACISwitchID)
+		if err != nil {
+			return nil, err
+		}
+		entry.health, entry.fetched = health, time.Now()
+	}
+	healthData, ok := entry.health[portID]
+	if !ok {
+		return nil, fmt.Errorf("%w: no health of port %s in the health of switch %s", caputilities.ErrAPICResponseMalformed, portID, ACISwitchID)
+	}
+	return &capmodel.Health{IMData: []capmodel.HealthIMData{{HealthData: healthData}}}, nil
+}
+
+// resetSwitchPortsHealth drops the health of the ports read for the switches
+func resetSwitchPortsHealth() {
+	switchPortsHealthLock.Lock()
+	defer switchPortsHealthLock.Unlock()
+	switchPortsHealthCache = map[string]*switchPortsHealth{}
+}
@@ -0,0 +1,363 @@
+//(C) Copyright [2020] Hewlett Packard Enterprise Development LP
+//
+//Licensed under the Apache License, Version 2.0 (the "License"); you may
+//not use this file except in compliance with the License. You may obtain
+//a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+//Unless required by applicable law or agreed to in writing, software
+//distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+//WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+//License for the specific language governing permissions and limitations
+// under the License.
+
+//Package caphandler ...
+package caphandler
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ODIM-Project/ODIM/lib-dmtf/model"
+	"github.com/ODIM-Project/ODIM/lib-utilities/response"
+	"github.com/ODIM-Project/PluginCiscoACI/capmodel"
+	"github.com/ODIM-Project/PluginCiscoACI/capresponse"
+	"github.com/ODIM-Project/PluginCiscoACI/captrace"
+	"github.com/ODIM-Project/PluginCiscoACI/caputilities"
+	iris "github.com/kataras/iris/v12"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	settingsODataType           = "#Settings.v1_3_5.Settings"
+	preferredApplyTimeODataType = "#Settings.v1_3_5.PreferredApplyTime"
+	// the port settings are applied by APIC as soon as they are requested
+	portSettingsApplyTime = "Immediate"
+	// maxPortDescriptionLength is the longest description APIC accepts for an interface
+	maxPortDescriptionLength = 128
+)
+
+// portSetting maps a property of the port Settings resource to the l1PhysIf attribute applying it in APIC
+type portSetting struct {
+	attribute string
+	// apicValue converts the requested value of the property to the value of the attribute
+	apicValue func(value interface{}) (string, error)
+}
+
+// portSettings are the properties of the port which are changed through its Settings resource
+var portSettings = map[string]portSetting{
+	"Description":      {"descr", portDescriptionSetting},
+	"CurrentSpeedGbps": {"speed", portSpeedSetting},
+}
+
+// portSpeedSettings are the admin speeds of APIC for the speeds in Gbps
+var portSpeedSettings = map[float64]string{
+	0.1: "100M", 1: "1G", 10: "10G", 25: "25G", 40: "40G", 50: "50G", 100: "100G", 200: "200G", 400: "400G",
+}
+
+// portOIDPattern matches the OID of a port, like /ODIM/v1/Fabrics/<fabricID>/Switches/<switchID>/Ports/<portID>
+var portOIDPattern = regexp.MustCompile(`^/ODIM/v1/Fabrics/([^/]+)/Switches/([^/]+)/Ports/([^/]+)$`)
+
+// APIC calls used for applying the port settings and for reading the applied ones, replaced in unit tests
+var (
+	applyPortSettings    = caputilities.ApplyPortSettings
+	getPhysicalInterface = caputilities.GetPhysicalInterface
+)
+
+// GetPortSettings fetches the Settings resource of the port, which has the values requested and not yet
+// applied by APIC. The pending values are checked against APIC first, the applied ones are promoted to the port.
+func GetPortSettings(ctx iris.Context) {
+	portURI := requestedPortURI(ctx)
+	span := captrace.StartHandlerSpan(ctx, "GetPortSettings")
+	defer span.End()
+	podID, portData, ok := lookupRequestedPort(ctx, portURI)
+	if !ok {
+		return
+	}
+	settings, err := capmodel.GetPortSettings(portURI)
+	if err != nil {
+		errMsg := fmt.Sprintf("failed to fetch port settings for uri %s: %s", portURI, err.Error())
+		createResourceDbErrResp(ctx, err, errMsg, []interface{}{"Ports", portURI}, resourceRef{portODataType, portURI})
+		return
+	}
+	if len(settings.Pending) > 0 {
+		if reconciled, err := reconcilePortSettings(podID, portURI, portData, settings); err != nil {
+			log.Error("while checking the applied settings of port " + portURI + ", got: " + err.Error())
+		} else {
+			settings = reconciled
+		}
+	}
+	ctx.StatusCode(http.StatusOK)
+	ctx.JSON(newPortSettingsResponse(ctx.Path(), settings))
+}
+
+// PatchPortSettings requests APIC to apply the settings of the port, the requested values are kept
+// pending in the Settings resource until APIC reports them as applied
+func PatchPortSettings(ctx iris.Context) {
+	portURI := requestedPortURI(ctx)
+	span := captrace.StartHandlerSpan(ctx, "PatchPortSettings")
+	defer span.End()
+	setWriteCacheControl(ctx)
+	body, err := ioutil.ReadAll(ctx.Request().Body)
+	if err != nil {
+		errorMessage := "error while trying to read the request body: " + err.Error()
+		log.Error(errorMessage)
+		ctx.StatusCode(http.StatusBadRequest)
+		writeErrorResponse(ctx, updateErrorResponse(response.MalformedJSON, errorMessage, nil))
+		return
+	}
+	properties, attributes, statusCode, resp := decodePortSettings(body)
+	if statusCode != http.StatusOK {
+		ctx.StatusCode(statusCode)
+		writeErrorResponse(ctx, withResource(resp, resourceRef{portODataType, ctx.Path()}))
+		return
+	}
+	podID, portData, ok := lookupRequestedPort(ctx, portURI)
+	if !ok {
+		return
+	}
+	apicSpan := startAPICSpan(span, "caputilities.ApplyPortSettings")
+	err = applyPortSettings(apicSpan.TraceParent(), podID, capmodel.SwitchNodeID(ctx.Params().Get("switchID")), portData.PortID, attributes)
+	apicSpan.RecordError(err)
+	apicSpan.End()
+	if err != nil {
+		errMsg := fmt.Sprintf("failed to apply port settings for uri %s: %s", portURI, err.Error())
+		statusCode, resp := createAPICErrResp(nil, err, errMsg, nil)
+		writeAPICErrResp(ctx, err, statusCode, withResource(resp, resourceRef{portODataType, portURI}))
+		return
+	}
+	settings, err := capmodel.RequestPortSettings(portURI, properties, time.Now())
+	if err != nil {
+		errMsg := fmt.Sprintf("failed to store port settings for uri %s: %s", portURI, err.Error())
+		createResourceDbErrResp(ctx, err, errMsg, []interface{}{"Ports", portURI}, resourceRef{portODataType, portURI})
+		return
+	}
+	ctx.StatusCode(http.StatusOK)
+	ctx.JSON(newPortSettingsResponse(ctx.Path(), settings))
+}
+
+// requestedPortURI returns the OID of the port of the subresource or the action requested
+func requestedPortURI(ctx iris.Context) string {
+	return fmt.Sprintf("/ODIM/v1/Fabrics/%s/Switches/%s/Ports/%s", ctx.Params().Get("id"), ctx.Params().Get("switchID"), ctx.Params().Get("portID"))
+}
+
+// lookupRequestedPort collects the pod of the fabric and the port of the subresource or the action requested,
+// the error response is written and false is returned when any of them is not found
+func lookupRequestedPort(ctx iris.Context, portURI string) (string, *model.Port, bool) {
+	fabricID := ctx.Params().Get("id")
+	fabricData, err := capmodel.GetFabric(fabricID)
+	if err != nil {
+		errMsg := fmt.Sprintf("failed to fetch fabric data for uri %s: %s", ctx.Path(), err.Error())
+		createResourceDbErrResp(ctx, err, errMsg, []interface{}{"Fabric", fabricID}, resourceRef{fabricODataType, "/ODIM/v1/Fabrics/" + fabricID})
+		return "", nil, false
+	}
+	if !checkSwitchExists(ctx, ctx.Params().Get("switchID")) {
+		return "", nil, false
+	}
+	portData := getPortData(ctx, portURI)
+	if portData == nil {
+		return "", nil, false
+	}
+	return fabricData.PodID, portData, true
+}
+
+// decodePortSettings validates the requested settings, the requested values of the properties and the
+// l1PhysIf attributes applying them are returned. The status code and the response of the error are
+// returned when the request is not valid.
+func decodePortSettings(body []byte) (map[string]interface{}, map[string]string, int, interface{}) {
+	var request map[string]json.RawMessage
+	if err := json.Unmarshal(body, &request); err != nil {
+		errorMessage := "error while trying to get JSON body from the request: " + err.Error()
+		log.Error(errorMessage)
+		return nil, nil, http.StatusBadRequest, updateErrorResponse(response.MalformedJSON, errorMessage, nil)
+	}
+	// the properties are validated in the order of their names, so the same error is reported for the same request
+	names := make([]string, 0, len(request))
+	for property := range request {
+		names = append(names, property)
+	}
+	sort.Strings(names)
+	properties := map[string]interface{}{}
+	attributes := map[string]string{}
+	for _, property := range names {
+		rawValue := request[property]
+		if strings.HasPrefix(property, "@odata.") {
+			continue
+		}
+		if property == "@Redfish.SettingsApplyTime" {
+			var applyTime capresponse.PreferredApplyTime
+			if err := json.Unmarshal(rawValue, &applyTime); err != nil || applyTime.ApplyTime != portSettingsApplyTime {
+				errorMessage := fmt.Sprintf("invalid @Redfish.SettingsApplyTime %s, the port settings are applied %s", rawValue, portSettingsApplyTime)
+				log.Error(errorMessage)
+				return nil, nil, http.StatusBadRequest, updateErrorResponse(response.PropertyValueNotInList, errorMessage, []interface{}{string(rawValue), property})
+			}
+			continue
+		}
+		setting, ok := portSettings[property]
+		if !ok {
+			errorMessage := fmt.Sprintf("property %s can't be changed through the port settings", property)
+			log.Error(errorMessage)
+			return nil, nil, http.StatusBadRequest, updateErrorResponse(response.PropertyUnknown, errorMessage, []interface{}{property})
+		}
+		var value interface{}
+		json.Unmarshal(rawValue, &value)
+		attribute, err := setting.apicValue(value)
+		if err != nil {
+			errorMessage := fmt.Sprintf("invalid value %s for property %s: %s", rawValue, property, err.Error())
+			log.Error(errorMessage)
+			return nil, nil, http.StatusBadRequest, updateErrorResponse(response.PropertyValueNotInList, errorMessage, []interface{}{string(rawValue), property})
+		}
+		properties[property] = value
+		attributes[setting.attribute] = attribute
+	}
+	if len(properties) == 0 {
+		errorMessage := "no port settings in the request"
+		log.Error(errorMessage)
+		return nil, nil, http.StatusBadRequest, updateErrorResponse(response.PropertyMissing, errorMessage, []interface{}{"Description"})
+	}
+	return properties, attributes, http.StatusOK, nil
+}
+
+func portDescriptionSetting(value interface{}) (string, error) {
+	description, ok := value.(string)
+	if !ok {
+		return "", fmt.Errorf("a string is expected")
+	}
+	if len(description) > maxPortDescriptionLength {
+		return "", fmt.Errorf("it should not be longer than %d characters", maxPortDescriptionLength)
+	}
+	return description, nil
+}
+
+func portSpeedSetting(value interface{}) (string, error) {
+	speedGbps, ok := value.(float64)
+	if !ok {
+		return "", fmt.Errorf("a number is expected")
+	}
+	speed, ok := portSpeedSettings[speedGbps]
+	if !ok {
+		return "", fmt.Errorf("the speed is not supported by APIC")
+	}
+	return speed, nil
+}
+
+// reconcilePortSettings promotes the pending settings of the port which APIC reports as applied on the port
+func reconcilePortSettings(podID, portURI string, portData *model.Port, settings capmodel.PortSettings) (capmodel.PortSettings, error) {
+	match := portOIDPattern.FindStringSubmatch(portURI)
+	if match == nil {
+		return settings, fmt.Errorf("%s is not the OID of a port", portURI)
+	}
+	attributes, err := getPhysicalInterface(podID, capmodel.SwitchNodeID(match[2]), portData.PortID)
+	if err != nil {
+		return settings, err
+	}
+	var applied []string
+	for property, value := range settings.Pending {
+		setting, ok := portSettings[property]
+		if !ok {
+			continue
+		}
+		requested, err := setting.apicValue(value)
+		if err != nil {
+			continue
+		}
+		if current, _ := attributes[setting.attribute].(string); current == requested {
+			applied = append(applied, property)
+		}
+	}
+	if len(applied) == 0 {
+		return settings, nil
+	}
+	sort.Strings(applied)
+	log.Info(fmt.Sprintf("settings %v of port %s are applied by APIC", applied, portURI))
+	return capmodel.PromotePortSettings(portURI, applied, time.Now())
+}
+
+// ReconcilePortSettings promotes the pending settings of all the ports which APIC reports as applied
+func ReconcilePortSettings() {
+	portURIs, err := capmodel.GetPortsWithSettings()
+	if err != nil {
+		log.Error("while collecting the ports with settings, got: " + err.Error())
+		return
+	}
+	for _, portURI := range portURIs {
+		settings, err := capmodel.GetPortSettings(portURI)
+		if err != nil || len(settings.Pending) == 0 {
+			continue
+		}
+		match := portOIDPattern.FindStringSubmatch(portURI)
+		if match == nil {
+			continue
+		}
+		fabricData, err := capmodel.GetFabric(match[1])
+		if err != nil {
+			log.Error("while reconciling the settings of port " + portURI + ", got: " + err.Error())
+			continue
+		}
+		portData, err := capmodel.GetPort(portURI)
+		if err != nil {
+			log.Error("while reconciling the settings of port " + portURI + ", got: " + err.Error())
+			continue
+		}
+		if _, err := reconcilePortSettings(fabricData.PodID, portURI, portData, settings); err != nil {
+			log.Error("while reconciling the settings of port " + portURI + ", got: " + err.Error())
+		}
+	}
+}
+
+// StartPortSettingsReconciler reconciles the pending settings of the ports with APIC at every
+// interval, jittered so that the plugin instances don't poll APIC at once
+func StartPortSettingsReconciler(interval time.Duration) {
+	go func() {
+		for {
+			time.Sleep(caputilities.JitteredInterval(interval))
+			ReconcilePortSettings()
+		}
+	}()
+}
+
+// portSettingsAnnotation returns the @Redfish.Settings annotation of the port, the settings
+// are reported without the time they were applied when it can't be read
+func portSettingsAnnotation(portURI string) *capresponse.Settings {
+	annotation := &capresponse.Settings{
+		ODataType:      settingsODataType,
+		SettingsObject: model.Link{Oid: portURI + "/Settings"},
+	}
+	settings, err := capmodel.GetPortSettings(portURI)
+	if err != nil {
+		log.Error("while collecting the settings of port " + portURI + ", got: " + err.Error())
+		return annotation
+	}
+	if settings.AppliedAt != nil {
+		annotation.Time = settings.AppliedAt.Format(time.RFC3339)
+	}
+	return annotation
+}
+
+// newPortSettingsResponse builds the Settings resource of the port with the pending settings
+func newPortSettingsResponse(uri string, settings capmodel.PortSettings) capresponse.PortSettings {
+	resp := capresponse.PortSettings{
+		ODataID:   uri,
+		ODataType: portODataType,
+		ID:        "Settings",
+		Name:      "Port Settings",
+		SettingsApplyTime: capresponse.PreferredApplyTime{
+			ODataType: preferredApplyTimeODataType,
+			ApplyTime: portSettingsApplyTime,
+		},
+	}
+	if description, ok := settings.Pending["Description"].(string); ok {
+		resp.Description = &description
+	}
+	if speed, ok := settings.Pending["CurrentSpeedGbps"].(float64); ok {
+		resp.CurrentSpeedGbps = &speed
+	}
+	return resp
+}
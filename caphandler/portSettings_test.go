@@ -0,0 +1,104 @@
+//(C) Copyright [2020] Hewlett Packard Enterprise Development LP
+//
+//Licensed under the Apache License, Version 2.0 (the "License"); you may
+//not use this file except in compliance with the License. You may obtain
+//a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+//Unless required by applicable law or agreed to in writing, software
+//distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+//WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+//License for the specific language governing permissions and limitations
+// under the License.
+
+package caphandler
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/ODIM-Project/ODIM/lib-dmtf/model"
+	"github.com/ODIM-Project/PluginCiscoACI/capdata"
+	"github.com/ODIM-Project/PluginCiscoACI/capmodel"
+	"github.com/ODIM-Project/PluginCiscoACI/caputilities"
+	"github.com/ODIM-Project/PluginCiscoACI/config"
+)
+
+const testPortSettingsURI = testPortURI + "/Settings"
+
+func TestPatchPortSettings(t *testing.T) {
+	e := mockPortApp(t)
+	config.Data.APICConf.DisableLiveEnrichment = true
+	capmodel.SaveFabric(testFabricID, &capdata.Fabric{PodID: "1", SwitchData: []string{testSwitchID}})
+	capmodel.SavePort(testPortURI, &model.Port{ODataID: testPortURI, ID: testPortID, PortID: "eth1/1"})
+	var applied []string
+	applyPortSettings = func(traceParent, podID, ACISwitchID, portID string, settings map[string]string) error {
+		applied = append(applied, fmt.Sprintf("%s/%s/%s/%s/%s", podID, ACISwitchID, portID, settings["descr"], settings["speed"]))
+		return nil
+	}
+	physicalInterface := map[string]interface{}{"id": "eth1/1", "descr": "", "speed": "inherit"}
+	getPhysicalInterface = func(podID, ACISwitchID, portID string) (map[string]interface{}, error) {
+		return physicalInterface, nil
+	}
+	defer func() {
+		applyPortSettings = caputilities.ApplyPortSettings
+		getPhysicalInterface = caputilities.GetPhysicalInterface
+	}()
+
+	settings := e.PATCH(testPortSettingsURI).WithJSON(map[string]interface{}{
+		"@Redfish.SettingsApplyTime": map[string]string{"ApplyTime": "Immediate"},
+		"Description":                "uplink",
+		"CurrentSpeedGbps":           25,
+	}).Expect().Status(http.StatusOK).JSON().Object()
+	settings.Value("Description").Equal("uplink")
+	settings.Value("CurrentSpeedGbps").Number().Equal(25)
+	if want := []string{"1/101/eth1/1/uplink/25G"}; fmt.Sprint(applied) != fmt.Sprint(want) {
+		t.Errorf("settings applied in APIC = %v, want %v", applied, want)
+	}
+
+	port := e.GET(testPortURI).Expect().Status(http.StatusOK).JSON().Object()
+	port.Path("$['@Redfish.Settings'].SettingsObject['@odata.id']").Equal(testPortSettingsURI)
+	port.Path("$['@Redfish.Settings']").Object().NotContainsKey("Time")
+
+	// APIC applied the description only, the speed stays pending
+	physicalInterface["descr"] = "uplink"
+	settings = e.GET(testPortSettingsURI).Expect().Status(http.StatusOK).JSON().Object()
+	settings.NotContainsKey("Description")
+	settings.Value("CurrentSpeedGbps").Number().Equal(25)
+	port = e.GET(testPortURI).Expect().Status(http.StatusOK).JSON().Object()
+	port.Value("Description").Equal("uplink")
+	port.Path("$['@Redfish.Settings']").Object().NotContainsKey("Time")
+
+	physicalInterface["speed"] = "25G"
+	ReconcilePortSettings()
+	e.GET(testPortSettingsURI).Expect().Status(http.StatusOK).JSON().Object().NotContainsKey("CurrentSpeedGbps")
+	port = e.GET(testPortURI).Expect().Status(http.StatusOK).JSON().Object()
+	port.Value("CurrentSpeedGbps").Number().Equal(25)
+	port.Path("$['@Redfish.Settings'].Time").String().NotEmpty()
+}
+
+func TestPatchPortSettingsInvalid(t *testing.T) {
+	e := mockPortApp(t)
+	capmodel.SaveFabric(testFabricID, &capdata.Fabric{PodID: "1", SwitchData: []string{testSwitchID}})
+	capmodel.SavePort(testPortURI, &model.Port{ODataID: testPortURI, ID: testPortID, PortID: "eth1/1"})
+	applyPortSettings = func(traceParent, podID, ACISwitchID, portID string, settings map[string]string) error {
+		t.Error("ApplyPortSettings must not be called for invalid settings")
+		return nil
+	}
+	defer func() {
+		applyPortSettings = caputilities.ApplyPortSettings
+	}()
+
+	for _, request := range []map[string]interface{}{
+		{"CurrentSpeedGbps": 30},
+		{"Description": 10},
+		{"LinkState": "Disabled"},
+		{"@Redfish.SettingsApplyTime": map[string]string{"ApplyTime": "OnReset"}, "Description": "uplink"},
+		{},
+	} {
+		e.PATCH(testPortSettingsURI).WithJSON(request).Expect().Status(http.StatusBadRequest)
+	}
+	e.PATCH(testPortSettingsURI).WithBytes([]byte("{")).Expect().Status(http.StatusBadRequest)
+}
@@ -0,0 +1,117 @@
+//(C) Copyright [2020] Hewlett Packard Enterprise Development LP
+//
+//Licensed under the Apache License, Version 2.0 (the "License"); you may
+//not use this file except in compliance with the License. You may obtain
+//a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+//Unless required by applicable law or agreed to in writing, software
+//distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+//WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+//License for the specific language governing permissions and limitations
+// under the License.
+
+//Package caphandler ...
+package caphandler
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/ODIM-Project/ODIM/lib-utilities/response"
+	"github.com/ODIM-Project/PluginCiscoACI/capmodel"
+	"github.com/ODIM-Project/PluginCiscoACI/capresponse"
+	"github.com/ODIM-Project/PluginCiscoACI/captrace"
+	"github.com/ODIM-Project/PluginCiscoACI/caputilities"
+	"github.com/ODIM-Project/PluginCiscoACI/config"
+	iris "github.com/kataras/iris/v12"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	// defaultPortStatsGranularity is the granularity of the statistics history when not requested
+	defaultPortStatsGranularity    = "5min"
+	portStatisticsHistoryODataType = "#CiscoACIPortStatisticsHistory.v1_0_0.PortStatisticsHistory"
+)
+
+// APIC call used for collecting the statistics history of a port, replaced in unit tests
+var getPortStatsHistory = caputilities.GetPortStatsHistory
+
+// GetPortStatisticsHistory fetches the traffic history of the port from APIC. The granularity query
+// parameter selects the APIC rollup interval, 5min when not given, and only the most recent
+// PortStatsHistoryMaxSamples samples are returned.
+func GetPortStatisticsHistory(ctx iris.Context) {
+	fabricID := ctx.Params().Get("id")
+	switchID := ctx.Params().Get("switchID")
+	portURI := fmt.Sprintf("/ODIM/v1/Fabrics/%s/Switches/%s/Ports/%s", fabricID, switchID, ctx.Params().Get("portID"))
+	span := captrace.StartHandlerSpan(ctx, "GetPortStatisticsHistory")
+	defer span.End()
+	span.SetAttribute("switchID", switchID)
+	span.SetAttribute("portID", ctx.Params().Get("portID"))
+	granularity := defaultPortStatsGranularity
+	if ctx.URLParamExists("granularity") {
+		granularity = ctx.URLParam("granularity")
+	}
+	if !caputilities.IsPortStatsGranularity(granularity) {
+		errMsg := fmt.Sprintf("invalid value %s for query parameter granularity, it should be one of %s",
+			granularity, strings.Join(caputilities.PortStatsGranularities, ", "))
+		log.Error(errMsg)
+		resp := updateErrorResponse(response.GeneralError, errMsg, nil)
+		ctx.StatusCode(http.StatusBadRequest)
+		writeErrorResponse(ctx, resp)
+		return
+	}
+	fabricData, err := capmodel.GetFabric(fabricID)
+	if err != nil {
+		errMsg := fmt.Sprintf("failed to fetch port statistics history for uri %s: %s", portURI, err.Error())
+		createResourceDbErrResp(ctx, err, errMsg, []interface{}{"Fabric", fabricID}, resourceRef{fabricODataType, "/ODIM/v1/Fabrics/" + fabricID})
+		return
+	}
+	if !checkSwitchExists(ctx, switchID) {
+		return
+	}
+	portData := getPortData(ctx, portURI)
+	if portData == nil {
+		return
+	}
+	apicSpan := startAPICSpan(span, "caputilities.GetPortStatsHistory")
+	samples, err := getPortStatsHistory(apicSpan.TraceParent(), fabricData.PodID, capmodel.SwitchNodeID(switchID), portData.PortID, granularity)
+	apicSpan.RecordError(err)
+	apicSpan.End()
+	if err != nil {
+		errMsg := fmt.Sprintf("failed to fetch port statistics history for uri %s: %s", portURI, err.Error())
+		statusCode, resp := createAPICErrResp(nil, err, errMsg, nil)
+		writeAPICErrResp(ctx, err, statusCode, withResource(resp, resourceRef{portODataType, portURI}))
+		return
+	}
+	ctx.StatusCode(http.StatusOK)
+	ctx.JSON(newPortStatisticsHistory(ctx.Path(), granularity, samples))
+}
+
+// newPortStatisticsHistory builds the statistics history response of the most recent samples
+func newPortStatisticsHistory(uri, granularity string, samples []capmodel.PortStatsSample) capresponse.PortStatisticsHistory {
+	if maxSamples := config.Data.APICConf.PortStatsHistoryMaxSamples; maxSamples > 0 && len(samples) > maxSamples {
+		samples = samples[len(samples)-maxSamples:]
+	}
+	history := capresponse.PortStatisticsHistory{
+		ODataID:     uri,
+		ODataType:   portStatisticsHistoryODataType,
+		ID:          "StatisticsHistory",
+		Name:        "Port Statistics History",
+		Granularity: granularity,
+		// the samples are always present so that clients can iterate them without checking for null
+		Samples:      make([]capresponse.PortStatisticsSample, 0, len(samples)),
+		SamplesCount: len(samples),
+	}
+	for _, sample := range samples {
+		history.Samples = append(history.Samples, capresponse.PortStatisticsSample{
+			IntervalStart: sample.IntervalStart,
+			IntervalEnd:   sample.IntervalEnd,
+			RXBytes:       sample.RXBytes,
+			TXBytes:       sample.TXBytes,
+		})
+	}
+	return history
+}
@@ -0,0 +1,83 @@
+//(C) Copyright [2020] Hewlett Packard Enterprise Development LP
+//
+//Licensed under the Apache License, Version 2.0 (the "License"); you may
+//not use this file except in compliance with the License. You may obtain
+//a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+//Unless required by applicable law or agreed to in writing, software
+//distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+//WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+//License for the specific language governing permissions and limitations
+// under the License.
+
+package caphandler
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/ODIM-Project/ODIM/lib-dmtf/model"
+	"github.com/ODIM-Project/PluginCiscoACI/capdata"
+	"github.com/ODIM-Project/PluginCiscoACI/capmodel"
+	"github.com/ODIM-Project/PluginCiscoACI/caputilities"
+	"github.com/ODIM-Project/PluginCiscoACI/config"
+)
+
+const testPortStatsURI = testPortURI + "/Oem/CiscoACI/StatisticsHistory"
+
+func TestGetPortStatisticsHistory(t *testing.T) {
+	e := mockPortApp(t)
+	capmodel.SaveFabric(testFabricID, &capdata.Fabric{PodID: "1", SwitchData: []string{testSwitchID}})
+	capmodel.SavePort(testPortURI, &model.Port{ODataID: testPortURI, ID: testPortID, PortID: "eth1/1"})
+	var requested []string
+	getPortStatsHistory = func(traceParent, podID, ACISwitchID, portID, granularity string) ([]capmodel.PortStatsSample, error) {
+		requested = append(requested, fmt.Sprintf("%s/%s/%s/%s", podID, ACISwitchID, portID, granularity))
+		samples := make([]capmodel.PortStatsSample, 5)
+		for i := range samples {
+			samples[i] = capmodel.PortStatsSample{IntervalStart: fmt.Sprintf("T%d", i), RXBytes: uint64(i), TXBytes: uint64(2 * i)}
+		}
+		return samples, nil
+	}
+	defer func() {
+		getPortStatsHistory = caputilities.GetPortStatsHistory
+	}()
+
+	history := e.GET(testPortStatsURI).Expect().Status(http.StatusOK).JSON().Object()
+	history.Value("Granularity").Equal("5min")
+	history.Value("Samples@odata.count").Number().Equal(5)
+	history.Path("$.Samples[4]").Object().ValueEqual("RXBytes", 4).ValueEqual("TXBytes", 8)
+
+	// only the most recent samples are returned
+	config.Data.APICConf.PortStatsHistoryMaxSamples = 2
+	history = e.GET(testPortStatsURI).WithQuery("granularity", "1h").Expect().Status(http.StatusOK).JSON().Object()
+	history.Value("Granularity").Equal("1h")
+	history.Value("Samples@odata.count").Number().Equal(2)
+	history.Path("$.Samples[0].IntervalStart").Equal("T3")
+	config.Data.APICConf.PortStatsHistoryMaxSamples = config.DefaultPortStatsHistoryMaxSamples
+
+	for _, granularity := range []string{"1m", "", "5MIN"} {
+		e.GET(testPortStatsURI).WithQuery("granularity", granularity).Expect().Status(http.StatusBadRequest)
+	}
+	if want := []string{"1/101/eth1/1/5min", "1/101/eth1/1/1h"}; fmt.Sprint(requested) != fmt.Sprint(want) {
+		t.Errorf("statistics history requested from APIC = %v, want %v", requested, want)
+	}
+}
+
+func TestGetPortStatisticsHistoryAPICError(t *testing.T) {
+	e := mockPortApp(t)
+	capmodel.SaveFabric(testFabricID, &capdata.Fabric{PodID: "1", SwitchData: []string{testSwitchID}})
+	getPortStatsHistory = func(traceParent, podID, ACISwitchID, portID, granularity string) ([]capmodel.PortStatsSample, error) {
+		return nil, caputilities.ErrAPICResponseMalformed
+	}
+	defer func() {
+		getPortStatsHistory = caputilities.GetPortStatsHistory
+	}()
+
+	// the port is not stored
+	e.GET(testPortStatsURI).Expect().Status(http.StatusNotFound)
+	capmodel.SavePort(testPortURI, &model.Port{ODataID: testPortURI, ID: testPortID, PortID: "eth1/1"})
+	e.GET(testPortStatsURI).Expect().Status(http.StatusBadRequest)
+}
@@ -16,15 +16,26 @@
 package caphandler
 
 import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/xml"
 	"errors"
 	"fmt"
+	"io/ioutil"
 	"net/http"
+	"regexp"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/ODIM-Project/ODIM/lib-dmtf/model"
 	"github.com/ODIM-Project/ODIM/lib-utilities/response"
+	"github.com/ODIM-Project/PluginCiscoACI/capmiddleware"
 	"github.com/ODIM-Project/PluginCiscoACI/capmodel"
+	"github.com/ODIM-Project/PluginCiscoACI/capresponse"
+	"github.com/ODIM-Project/PluginCiscoACI/captrace"
 	"github.com/ODIM-Project/PluginCiscoACI/caputilities"
 	"github.com/ODIM-Project/PluginCiscoACI/config"
 	"github.com/ODIM-Project/PluginCiscoACI/db"
@@ -32,188 +43,832 @@ import (
 	log "github.com/sirupsen/logrus"
 )
 
+const (
+	mediaTypeJSON = "application/json"
+	mediaTypeXML  = "application/xml"
+	// return preferences of the Prefer header (RFC 7240) of the write requests
+	returnMinimal        = "minimal"
+	returnRepresentation = "representation"
+	// types of the resources reported in the errors of the port handlers
+	portODataType   = "#Port.v1_3_0.Port"
+	switchODataType = "#Switch.v1_4_0.Switch"
+	fabricODataType = "#Fabric.v1_2_0.Fabric"
+)
+
+// resourceRef identifies the resource involved in an error
+type resourceRef struct {
+	odataType string
+	odataID   string
+}
+
+// APIC calls used for collecting the live port attributes, replaced in unit tests
+var (
+	getPortInfo        = caputilities.GetPortInfo
+	getPortHealth      = caputilities.GetPortHealth
+	getPortTransceiver = caputilities.GetPortTransceiver
+	getPortNeighbors   = caputilities.GetPortNeighbors
+)
+
+// ODIM call used for validating the ethernet interface connected to a port, replaced in unit tests
+var checkEthernetInODIM = checkValidityOfEthernet
+
 // GetPortCollection fetches the ports  which are linked to that switch
 func GetPortCollection(ctx iris.Context) {
-	uri := ctx.Request().RequestURI
+	uri := ctx.Path()
 	switchID := ctx.Params().Get("switchID")
+	mediaType, ok := negotiateMediaType(ctx)
+	if !ok {
+		return
+	}
+	page, ok := getCollectionPage(ctx)
+	if !ok {
+		return
+	}
+	if !checkSwitchExists(ctx, switchID) {
+		return
+	}
+	if ctx.URLParam("$count") == "true" {
+		getPortCount(ctx, switchID)
+		return
+	}
 
 	// get all port which are store under that switch
 	portData, err := capmodel.GetSwitchPort(switchID)
 	if err != nil {
 		errMsg := fmt.Sprintf("failed to fetch port data for uri %s: %s", uri, err.Error())
-		createDbErrResp(ctx, err, errMsg, []interface{}{"Port", uri})
+		createResourceDbErrResp(ctx, err, errMsg, []interface{}{"Port", uri}, switchRef(ctx))
 		return
 	}
+	if unique := capmodel.UniqueSwitchPorts(portData); len(unique) != len(portData) {
+		capmiddleware.RequestLogger(ctx).Warn(fmt.Sprintf("switch %s lists %d ports more than once, they are removed with the %s action",
+			switchID, len(portData)-len(unique), repairPortsAction))
+		portData = unique
+	}
 
-	var members = []*model.Link{}
-	for i := 0; i < len(portData); i++ {
-		members = append(members, &model.Link{
-			Oid: uri + "/" + portData[i],
-		})
+	setReadCacheControl(ctx, readCacheMaxAge())
+	start, end := page.bounds(len(portData))
+	// the tag is computed from the members listed, so that it is the same for GET and HEAD
+	ctx.Header("ETag", collectionETag(portData[start:end], len(portData)))
+	ctx.Header("X-Total-Count", strconv.Itoa(len(portData)))
+	portCollectionResponse := capresponse.CollectionResponse{
+		Collection: model.Collection{
+			ODataContext: "/ODIM/v1/$metadata#PortCollection.PortCollection",
+			ODataID:      uri,
+			ODataType:    "#PortCollection.PortCollection",
+			Description:  "PortCollection view",
+			Name:         "Ports",
+			MembersCount: len(portData),
+		},
+	}
+	portCollectionResponse.NextLink = setPaginationLinks(ctx, page, len(portData))
+	portLink := func(i int) *model.Link {
+		return &model.Link{
+			Oid: uri + "/" + portData[start+i],
+		}
+	}
+	if mediaType == mediaTypeXML {
+		for i := 0; i < end-start; i++ {
+			portCollectionResponse.Members = append(portCollectionResponse.Members, portLink(i))
+		}
+		if ctx.Method() == http.MethodHead {
+			headXML(ctx, capresponse.NewCollectionXML(portCollectionResponse.Collection))
+			return
+		}
+		ctx.StatusCode(http.StatusOK)
+		writeXML(ctx, capresponse.NewCollectionXML(portCollectionResponse.Collection))
+		return
 	}
+	// the members are streamed, as a switch can have too many ports to build the collection in memory
+	streamCollection(ctx, portCollectionResponse, end-start, portLink)
+}
 
-	portCollectionResponse := model.Collection{
-		ODataContext: "/ODIM/v1/$metadata#PortCollection.PortCollection",
-		ODataID:      uri,
-		ODataType:    "#PortCollection.PortCollection",
-		Description:  "PortCollection view",
-		Name:         "Ports",
-		Members:      members,
-		MembersCount: len(members),
+// collectionETag returns the weak entity tag of the page of the collection from the OIDs of its
+// members and the number of members of the collection, without encoding the page
+func collectionETag(members []string, total int) string {
+	hash := sha256.New()
+	hash.Write([]byte(strconv.Itoa(total)))
+	for _, member := range members {
+		hash.Write([]byte("\n" + member))
 	}
+	sum := hash.Sum(nil)
+	return `W/"` + hex.EncodeToString(sum[:16]) + `"`
+}
+
+// getPortCount writes only the number of ports of the switch, the count is sent
+// in X-Total-Count header and as the plain text body, HEAD gets the headers only
+func getPortCount(ctx iris.Context, switchID string) {
+	count, err := capmodel.CountPorts(switchID)
+	if err != nil {
+		errMsg := fmt.Sprintf("failed to count ports of switch %s: %s", switchID, err.Error())
+		createResourceDbErrResp(ctx, err, errMsg, []interface{}{"Switch", switchID}, switchRef(ctx))
+		return
+	}
+	body := strconv.Itoa(count)
+	ctx.Header("X-Total-Count", body)
+	setReadCacheControl(ctx, readCacheMaxAge())
+	ctx.ContentType("text/plain")
+	ctx.Header("Content-Length", strconv.Itoa(len(body)))
 	ctx.StatusCode(http.StatusOK)
-	ctx.JSON(portCollectionResponse)
+	if ctx.Method() == http.MethodHead {
+		return
+	}
+	ctx.WriteString(body)
 }
 
-// GetPortInfo fetches the port info for given port id
+// GetPortInfo fetches the port info for given port id. HEAD is answered from the stored port
+// only, the link state, health and transceiver of the port are not read from APIC for it.
 func GetPortInfo(ctx iris.Context) {
-	uri := ctx.Request().RequestURI
+	uri := ctx.Path()
 	switchID := ctx.Params().Get("switchID")
 	fabricID := ctx.Params().Get("id")
+	span := captrace.StartHandlerSpan(ctx, "GetPortInfo")
+	defer span.End()
+	span.SetAttribute("switchID", switchID)
+	span.SetAttribute("portID", ctx.Params().Get("portID"))
+	mediaType, ok := negotiateMediaType(ctx)
+	if !ok {
+		return
+	}
+	dbSpan := span.StartChild("capmodel.GetFabric")
 	fabricData, err := capmodel.GetFabric(fabricID)
+	dbSpan.RecordError(err)
+	dbSpan.End()
 	if err != nil {
 		errMsg := fmt.Sprintf("failed to fetch port data for uri %s: %s", uri, err.Error())
-		createDbErrResp(ctx, err, errMsg, []interface{}{"Fabric", fabricID})
+		createResourceDbErrResp(ctx, err, errMsg, []interface{}{"Fabric", fabricID}, resourceRef{fabricODataType, "/ODIM/v1/Fabrics/" + fabricID})
 		return
 	}
-	portData := getPortData(ctx, uri)
+	if !checkSwitchExists(ctx, switchID) {
+		return
+	}
+	portData, stored := getStoredPortData(ctx, uri)
 	if portData == nil {
 		return
 	}
-	getPortAddtionalAttributes(fabricData.PodID, switchID, portData)
+	// the tag is computed from the stored port, so that it is the same for GET and HEAD
+	ctx.Header("ETag", portETag(stored))
+	if ctx.Method() == http.MethodHead {
+		setReadCacheControl(ctx, portCacheMaxAge(false))
+		headPort(ctx, mediaType, fabricData.PodID, switchID, portData)
+		return
+	}
+	conditions, err := getPortAddtionalAttributes(span, fabricData.PodID, switchID, portData)
+	if err != nil {
+		errMsg := fmt.Sprintf("failed to fetch port data for uri %s: %s", uri, err.Error())
+		statusCode, resp := createAPICErrResp(nil, err, errMsg, nil)
+		writeAPICErrResp(ctx, err, statusCode, withResource(resp, resourceRef{portODataType, uri}))
+		return
+	}
+	setReadCacheControl(ctx, portCacheMaxAge(!config.Data.APICConf.DisableLiveEnrichment))
+	transceiver := portTransceiver(span, fabricData.PodID, switchID, portData.PortID)
+	setPortMedium(portData, transceiver)
+	oem := portOem(fabricData.PodID, switchID, portData.PortID, ctx.Path(), transceiver)
+	oem.CiscoACI.Neighbors = portNeighbors(span, fabricData.PodID, switchID, portData.PortID)
+	oem.CiscoACI.Conditions = conditions
+	port := capresponse.Port{
+		Port:     displayPort(portData),
+		Settings: portSettingsAnnotation(ctx.Path()),
+		Actions:  portActions(ctx.Path()),
+		Oem:      oem,
+	}
 	ctx.StatusCode(http.StatusOK)
-	ctx.JSON(portData)
+	if mediaType == mediaTypeXML {
+		writeXML(ctx, capresponse.NewPortXML(port))
+		return
+	}
+	ctx.JSON(port)
+}
 
+// displayPort returns the copy of the port reported to the clients, with the PortId in the configured
+// format, the port data keeps the APIC port id for the calls made to APIC
+func displayPort(portData *model.Port) *model.Port {
+	port := *portData
+	port.PortID = caputilities.DisplayPortID(portData.PortID)
+	return &port
+}
+
+// portETag returns the weak entity tag of the port from its bytes as stored in the DB, the tag
+// is weak as the link state and the health of the port read from APIC are not part of it
+func portETag(stored []byte) string {
+	sum := sha256.Sum256(stored)
+	return `W/"` + hex.EncodeToString(sum[:16]) + `"`
+}
+
+// headPort answers HEAD on the port with the headers of the stored port representation, its
+// Content-Length is the one of the representation without the properties read from APIC
+func headPort(ctx iris.Context, mediaType, podID, switchID string, portData *model.Port) {
+	port := capresponse.Port{
+		Port:     displayPort(portData),
+		Settings: portSettingsAnnotation(ctx.Path()),
+		Actions:  portActions(ctx.Path()),
+		Oem:      portOem(podID, switchID, portData.PortID, ctx.Path(), nil),
+	}
+	var body []byte
+	var err error
+	if mediaType == mediaTypeXML {
+		body, err = xml.Marshal(capresponse.NewPortXML(port))
+		body = append([]byte(xml.Header), body...)
+	} else {
+		body, err = json.Marshal(port)
+	}
+	if err != nil {
+		errMsg := "failed to marshal the port: " + err.Error()
+		log.Error(errMsg)
+		ctx.StatusCode(http.StatusInternalServerError)
+		return
+	}
+	ctx.ContentType(mediaType)
+	ctx.Header("Content-Length", strconv.Itoa(len(body)))
+	ctx.StatusCode(http.StatusOK)
 }
 
-// PatchPort Update the given port with provied information
+// PatchPort Update the given port with provied information, the request body is applied on the
+// stored port as a JSON merge patch (RFC 7386): null removes a property, an absent one is kept.
+// The updated port is returned unless the client prefers the minimal return, answered with 204.
 func PatchPort(ctx iris.Context) {
-	uri := ctx.Request().RequestURI
-	var port model.Port
-	err := ctx.ReadJSON(&port)
+	uri := ctx.Path()
+	span := captrace.StartHandlerSpan(ctx, "PatchPort")
+	defer span.End()
+	setWriteCacheControl(ctx)
+	span.SetAttribute("switchID", ctx.Params().Get("switchID"))
+	span.SetAttribute("portID", ctx.Params().Get("portID"))
+	body, err := ioutil.ReadAll(ctx.Request().Body)
+	if err != nil {
+		errorMessage := "error while trying to read the request body: " + err.Error()
+		log.Error(errorMessage)
+		resp := withResource(updateErrorResponse(response.MalformedJSON, errorMessage, nil), resourceRef{portODataType, uri})
+		ctx.StatusCode(http.StatusBadRequest)
+		writeErrorResponse(ctx, resp)
+		return
+	}
+	// an empty body is a request without properties to update rather than malformed JSON
+	if len(bytes.TrimSpace(body)) == 0 {
+		errorMessage := "at least one writable property is required to update the port, the writable properties are " +
+			strings.Join(writablePortProperties(), ", ")
+		log.Error(errorMessage)
+		resp := withResource(updateErrorResponse(response.GeneralError, errorMessage, nil), resourceRef{portODataType, uri})
+		ctx.StatusCode(http.StatusBadRequest)
+		writeErrorResponse(ctx, resp)
+		return
+	}
+	idempotent, answered := replayIdempotentRequest(ctx, body, resourceRef{portODataType, uri})
+	if answered {
+		return
+	}
+	defer releaseIdempotentRequest(idempotent)
+	port, properties, err := decodePortPatch(body)
 	if err != nil {
 		errorMessage := "error while trying to get JSON body from the  request: " + err.Error()
 		log.Error(errorMessage)
-		resp := updateErrorResponse(response.MalformedJSON, errorMessage, nil)
+		resp := withResource(updateErrorResponse(response.MalformedJSON, errorMessage, nil), resourceRef{portODataType, uri})
 		ctx.StatusCode(http.StatusBadRequest)
-		ctx.JSON(resp)
+		writeErrorResponse(ctx, resp)
 		return
 	}
-	portData := getPortData(ctx, uri)
-	if portData == nil {
+	if getPortData(ctx, uri) == nil {
 		return
 	}
-	checkFlag := false
-
 	if port.Links != nil {
-		if port.Links.ConnectedPorts != nil {
-			if len(port.Links.ConnectedPorts) > 0 {
-				//Assuming we have only one connected port
-				ethernetURI := port.Links.ConnectedPorts[0].Oid
-				//Check on ODIM if ethernet is valid
-				reqURL := config.Data.ODIMConf.URL + ethernetURI
-				odimUsername := config.Data.ODIMConf.UserName
-				odimPassword := config.Data.ODIMConf.Password
-				for key, value := range config.Data.URLTranslation.SouthBoundURL {
-					reqURL = strings.Replace(reqURL, key, value, -1)
-				}
-				enigma, err := caputilities.NewEnigma(string(config.Data.KeyCertConf.RSAPrivateKeyPath))
-				if err != nil {
-					errMsg := fmt.Sprintf("Error while trying to read private key path %s ", err.Error())
-					log.Error(errMsg)
-					resp := updateErrorResponse(response.InternalError, errMsg, nil)
-					ctx.StatusCode(http.StatusServiceUnavailable)
-					ctx.JSON(resp)
-					return
-				}
-				//decrypting odim password
-				odimPwd := string(enigma.Decrypt(odimPassword))
-				checkFlag, err = caputilities.CheckValidityOfEthernet(reqURL, odimUsername, odimPwd)
-				if err != nil {
-					errMsg := fmt.Sprintf("Error while trying to contact ODIM")
-					log.Error(errMsg)
-					resp := updateErrorResponse(response.InternalError, errMsg, nil)
-					ctx.StatusCode(http.StatusServiceUnavailable)
-					ctx.JSON(resp)
-					return
-				}
-				if !checkFlag {
-					errMsg := fmt.Sprintf("Ethernet data for uri %s not found", reqURL)
-					log.Error(errMsg)
-					resp := updateErrorResponse(response.ResourceNotFound, errMsg, []interface{}{"Ethernet", reqURL})
-					ctx.StatusCode(http.StatusNotFound)
-					ctx.JSON(resp)
-					return
-				}
-				portData.Links = &model.PortLinks{}
-				portData.Links.ConnectedPorts = []model.Link{}
-				portData.Links.ConnectedPorts = append(portData.Links.ConnectedPorts, model.Link{Oid: ethernetURI})
-			} else {
-				portData.Links.ConnectedPorts = nil
+		for _, connectedPort := range port.Links.ConnectedPorts {
+			//Check on ODIM if ethernet is valid
+			reqURL := config.Data.ODIMConf.URL + caputilities.TranslateSouthBoundPath(connectedPort.Oid)
+			odimSpan := span.StartChild("caputilities.CheckValidityOfEthernet")
+			checkFlag, err := checkEthernetInODIM(odimSpan.TraceParent(), reqURL)
+			odimSpan.RecordError(err)
+			odimSpan.End()
+			if err != nil {
+				errMsg := fmt.Sprintf("Error while trying to contact ODIM: %s", err.Error())
+				log.Error(errMsg)
+				resp := withResource(updateErrorResponse(response.InternalError, errMsg, nil), resourceRef{portODataType, uri})
+				ctx.StatusCode(http.StatusServiceUnavailable)
+				writeErrorResponse(ctx, resp)
+				return
+			}
+			if !checkFlag {
+				errMsg := fmt.Sprintf("Ethernet data for uri %s not found", reqURL)
+				log.Error(errMsg)
+				resp := updateErrorResponse(response.ResourceNotFound, errMsg, []interface{}{"Ethernet", reqURL})
+				ctx.StatusCode(http.StatusNotFound)
+				writeErrorResponse(ctx, resp)
+				return
 			}
-		} else {
-			portData.Links.ConnectedPorts = nil
 		}
 	}
-	if err := capmodel.UpdatePort(uri, portData); err != nil {
+	dbSpan := span.StartChild("capmodel.MergePatchPort")
+	portData, err := capmodel.MergePatchPort(uri, portMergePatch(properties), isWritablePortProperty)
+	dbSpan.RecordError(err)
+	dbSpan.End()
+	var notWritable *capmodel.FieldNotWritableError
+	if errors.As(err, &notWritable) {
+		log.Error(err.Error())
+		resp := withResource(updateErrorResponse(response.PropertyNotWritable, err.Error(), []interface{}{notWritable.Field}), resourceRef{portODataType, uri})
+		ctx.StatusCode(http.StatusBadRequest)
+		writeErrorResponse(ctx, resp)
+		return
+	}
+	if err != nil {
 		errMsg := fmt.Sprintf("failed to update port data for uri %s: %s", uri, err.Error())
-		createDbErrResp(ctx, err, errMsg, []interface{}{"Ports", uri})
+		createResourceDbErrResp(ctx, err, errMsg, []interface{}{"Ports", uri}, resourceRef{portODataType, uri})
+		return
+	}
+	saveIdempotentResult(idempotent, http.StatusOK, displayPort(portData))
+	if preferredReturn(ctx) == returnMinimal {
+		ctx.StatusCode(http.StatusNoContent)
 		return
 	}
 	ctx.StatusCode(http.StatusOK)
-	ctx.JSON(portData)
+	ctx.JSON(displayPort(portData))
+}
+
+// decodePortPatch decodes the body of the port PATCH request into the port and its top level properties.
+// The connected ports are normalized to links, as the clients send them as {"@odata.id": uri},
+// as {"Oid": uri} following the shape of the link model, or as the plain uri.
+func decodePortPatch(body []byte) (model.Port, map[string]json.RawMessage, error) {
+	var port model.Port
+	var properties map[string]json.RawMessage
+	if err := json.Unmarshal(body, &properties); err != nil {
+		return port, nil, err
+	}
+	var links map[string]json.RawMessage
+	if err := json.Unmarshal(properties["Links"], &links); properties["Links"] != nil && err != nil {
+		return port, nil, fmt.Errorf("invalid Links: %v", err)
+	}
+	if connectedPorts, ok := links["ConnectedPorts"]; ok {
+		var rawLinks []json.RawMessage
+		if err := json.Unmarshal(connectedPorts, &rawLinks); err != nil {
+			return port, nil, fmt.Errorf("invalid Links.ConnectedPorts: %v", err)
+		}
+		var normalized []model.Link
+		for _, rawLink := range rawLinks {
+			oid, err := parseLinkOid(rawLink)
+			if err != nil {
+				return port, nil, err
+			}
+			normalized = append(normalized, model.Link{Oid: oid})
+		}
+		if rawLinks != nil && normalized == nil {
+			normalized = []model.Link{}
+		}
+		links["ConnectedPorts"], _ = json.Marshal(normalized)
+		properties["Links"], _ = json.Marshal(links)
+	}
+	normalizedBody, _ := json.Marshal(properties)
+	if err := json.Unmarshal(normalizedBody, &port); err != nil {
+		return port, nil, err
+	}
+	return port, properties, nil
+}
+
+// parseLinkOid returns the uri of the link given as the plain uri or as an object with @odata.id or Oid
+func parseLinkOid(rawLink json.RawMessage) (string, error) {
+	var oid string
+	if err := json.Unmarshal(rawLink, &oid); err == nil && oid != "" {
+		return oid, nil
+	}
+	var link map[string]interface{}
+	if err := json.Unmarshal(rawLink, &link); err == nil {
+		for _, key := range []string{"@odata.id", "Oid"} {
+			if oid, ok := link[key].(string); ok && oid != "" {
+				return oid, nil
+			}
+		}
+	}
+	return "", fmt.Errorf("invalid link %s in Links.ConnectedPorts, @odata.id is missing", string(rawLink))
 }
 
-func getPortAddtionalAttributes(fabricID, switchID string, p *model.Port) {
-	switchIDData := strings.Split(switchID, ":")
-	PortInfoResponse, err := caputilities.GetPortInfo(fabricID, switchIDData[1], p.PortID)
+// checkValidityOfEthernet checks the ethernet interface of the URL is present in ODIM,
+// the request carries the trace context of traceParent
+func checkValidityOfEthernet(traceParent, reqURL string) (bool, error) {
+	enigma, err := caputilities.NewEnigma(string(config.Data.KeyCertConf.RSAPrivateKeyPath))
 	if err != nil {
-		log.Error("Unable to get addtional port info " + err.Error())
+		return false, fmt.Errorf("while trying to read private key path, got: %v", err)
+	}
+	//decrypting odim password
+	odimPwd := string(enigma.Decrypt(config.Data.ODIMConf.Password))
+	return caputilities.CheckValidityOfEthernet(traceParent, reqURL, config.Data.ODIMConf.UserName, odimPwd)
+}
+
+// DeletePortConnectedPorts clears the connected ports of the port, deleting the connection
+// of a port without connected ports succeeds without any change
+func DeletePortConnectedPorts(ctx iris.Context) {
+	uri := fmt.Sprintf("/ODIM/v1/Fabrics/%s/Switches/%s/Ports/%s", ctx.Params().Get("id"), ctx.Params().Get("switchID"), ctx.Params().Get("portID"))
+	span := captrace.StartHandlerSpan(ctx, "DeletePortConnectedPorts")
+	defer span.End()
+	setWriteCacheControl(ctx)
+	span.SetAttribute("switchID", ctx.Params().Get("switchID"))
+	span.SetAttribute("portID", ctx.Params().Get("portID"))
+	portData := getPortData(ctx, uri)
+	if portData == nil {
 		return
 	}
+	if portData.Links == nil || len(portData.Links.ConnectedPorts) == 0 {
+		ctx.StatusCode(http.StatusOK)
+		ctx.JSON(displayPort(portData))
+		return
+	}
+	portData.Links.ConnectedPorts = nil
+	dbSpan := span.StartChild("capmodel.UpdatePortFields")
+	err := capmodel.UpdatePortFields(uri, map[string]interface{}{"Links": portData.Links})
+	dbSpan.RecordError(err)
+	dbSpan.End()
+	if err != nil {
+		errMsg := fmt.Sprintf("failed to clear connected ports of port %s: %s", uri, err.Error())
+		createResourceDbErrResp(ctx, err, errMsg, []interface{}{"Ports", uri}, resourceRef{portODataType, uri})
+		return
+	}
+	ctx.StatusCode(http.StatusOK)
+	ctx.JSON(displayPort(portData))
+}
+
+// portMergePatch returns the merge patch of the PATCH request properties, annotations like @odata.etag are ignored
+func portMergePatch(properties map[string]json.RawMessage) map[string]interface{} {
+	patch := map[string]interface{}{}
+	for property, value := range properties {
+		if strings.HasPrefix(property, "@") {
+			continue
+		}
+		var field interface{}
+		json.Unmarshal(value, &field)
+		patch[property] = field
+	}
+	return patch
+}
+
+// isWritablePortProperty checks if the port property can be modified with PATCH
+func isWritablePortProperty(property string) bool {
+	for _, writable := range writablePortProperties() {
+		if writable == property {
+			return true
+		}
+	}
+	return false
+}
+
+func writablePortProperties() []string {
+	if len(config.Data.WritablePortProperties) == 0 {
+		return config.DefaultWritablePortProperties
+	}
+	return config.Data.WritablePortProperties
+}
+
+// getPortAddtionalAttributes adds the link state and health read from APIC to the port. The port is
+// served without them when APIC can't be read, except when APIC requests are throttled, as the
+// client is then asked to retry later. When only the health can't be read, the health of the
+// UnavailableHealthPolicy is reported and the returned conditions note it.
+func getPortAddtionalAttributes(span *captrace.Span, fabricID, switchID string, p *model.Port) ([]capresponse.PortCondition, error) {
+	if config.Data.APICConf.DisableLiveEnrichment {
+		return nil, nil
+	}
+	nodeID := capmodel.SwitchNodeID(switchID)
+	apicSpan := startAPICSpan(span, "caputilities.GetPortInfo")
+	PortInfoResponse, err := getPortInfo(apicSpan.TraceParent(), fabricID, nodeID, p.PortID)
+	apicSpan.RecordError(err)
+	apicSpan.End()
+	if err != nil {
+		if isAPICThrottled(err) {
+			return nil, err
+		}
+		log.Error("Unable to get addtional port info " + err.Error())
+		return nil, nil
+	}
 	portInfoData := PortInfoResponse.IMData[0].PhysicalInterface.Attributes
-	operationState := portInfoData["operSt"].(string)
-	if operationState == "up" {
-		p.LinkState = "Enabled"
-		p.LinkStatus = "LinkUp"
-		p.InterfaceEnabled = true
+	operationState, err := caputilities.AttributeString(portInfoData, "operSt")
+	if err != nil {
+		log.Error("Unable to get addtional port info " + err.Error())
+		return nil, nil
+	}
+	operStateQualifier, _ := portInfoData["operStQual"].(string)
+	operState := portOperState(operationState, operStateQualifier)
+	setPortOperState(p, operState)
+	operSpeed, _ := portInfoData["operSpeed"].(string)
+	p.CurrentSpeedGbps = parseSpeedGbps(operSpeed)
+	usage, _ := portInfoData["usage"].(string)
+	setPortType(p, usage)
+	apicSpan = startAPICSpan(span, "caputilities.GetSwitchPortsHealth")
+	portsHealthResposne, err := getPortHealthFromSwitch(apicSpan.TraceParent(), fabricID, nodeID, p.PortID)
+	apicSpan.RecordError(err)
+	apicSpan.End()
+	if err != nil && !errors.Is(err, caputilities.ErrAPICResponseMalformed) && !isAPICThrottled(err) {
+		log.Warn("Unable to get Health of the ports of switch, reading the port health: " + err.Error())
+		apicSpan = startAPICSpan(span, "caputilities.GetPortHealth")
+		portsHealthResposne, err = getPortHealth(apicSpan.TraceParent(), fabricID, nodeID, p.PortID)
+		apicSpan.RecordError(err)
+		apicSpan.End()
+	}
+	// the ports out of the tenant the queries are scoped to have no health, like the ports without health score
+	if err != nil && !errors.Is(err, caputilities.ErrAPICResponseMalformed) && !errors.Is(err, caputilities.ErrOutOfTenant) {
+		if isAPICThrottled(err) {
+			return nil, err
+		}
+		log.Error("Unable to get Health of port " + err.Error())
+		return unavailablePortHealth(p, operState), nil
+	}
+	var healthValue int
+	if err == nil {
+		healthValue, err = caputilities.HealthScore(portsHealthResposne.IMData[0].HealthData.Attributes)
+	}
+	var health string
+	if err != nil {
+		// APIC reports no health score for some ports, like the admin-down ones
+		log.Warn("Unable to get Health of port " + err.Error())
+		health = unknownPortHealth()
 	} else {
-		p.LinkState = "Disabled"
+		health = healthOfScore(healthValue)
+	}
+
+	operState.Health = health
+	state := reportPortState(p.ODataID, operState)
+	setPortOperState(p, state)
+	if state.Health != "" {
+		p.Status = &model.Status{
+			State:  state.State,
+			Health: state.Health,
+		}
+		if state.State == "" {
+			p.Status.State = state.LinkState
+		}
+	}
+	return nil, nil
+}
+
+// healthOfScore returns the health of the port, switch, chassis or fabric for its APIC health score
+func healthOfScore(healthValue int) string {
+	switch {
+	case healthValue > 90:
+		return "OK"
+	case healthValue >= 30:
+		return "Warning"
+	default:
+		return "Critical"
+	}
+}
+
+// unavailablePortHealth reports the health of the UnavailableHealthPolicy for the port whose health
+// couldn't be read from APIC, with the condition noting it. The health isn't stored as the state of
+// the port, so the health index only holds the health read from APIC.
+func unavailablePortHealth(p *model.Port, operState capmodel.PortState) []capresponse.PortCondition {
+	health := config.Data.APICConf.UnavailableHealthPolicy
+	if health == "" || health == config.UnknownHealthIgnore {
+		return nil
+	}
+	p.Status = &model.Status{
+		State:  operState.State,
+		Health: health,
+	}
+	if operState.State == "" {
+		p.Status.State = operState.LinkState
+	}
+	return []capresponse.PortCondition{{
+		Message:   "The health of the port could not be read from APIC, the reported health is not the current one",
+		Severity:  health,
+		Timestamp: time.Now().UTC().Format(time.RFC3339),
+	}}
+}
+
+// portOperState returns the Redfish state of the port for its APIC operSt. The reason of a port being
+// down, like err-disabled, is given by APIC in operStQual, which is used when it is mapped.
+func portOperState(operState, qualifier string) capmodel.PortState {
+	mapping, ok := lookupPortOperState(qualifier)
+	if operState != "down" || !ok {
+		if mapping, ok = lookupPortOperState(operState); !ok {
+			mapping = config.DefaultPortOperStates["down"]
+		}
+	}
+	return capmodel.PortState{LinkState: mapping.LinkState, LinkStatus: mapping.LinkStatus, State: mapping.State}
+}
+
+// lookupPortOperState returns the Redfish state configured for the APIC operSt or operStQual value,
+// the configured mapping takes precedence over the default one
+func lookupPortOperState(value string) (config.PortOperState, bool) {
+	if mapping, ok := config.Data.APICConf.PortOperStates[value]; ok {
+		return mapping, true
+	}
+	mapping, ok := config.DefaultPortOperStates[value]
+	return mapping, ok
+}
+
+// setPortOperState sets the link state and link status of the port. The link status is derived from
+// the link state for the states stored without it, which are reported while a flapping port is held.
+func setPortOperState(p *model.Port, state capmodel.PortState) {
+	p.LinkState = state.LinkState
+	p.LinkStatus = state.LinkStatus
+	p.InterfaceEnabled = state.LinkState == "Enabled"
+	if p.LinkStatus == "" {
 		p.LinkStatus = "LinkDown"
-		p.InterfaceEnabled = false
+		if state.LinkState == "Enabled" {
+			p.LinkStatus = "LinkUp"
+		}
+	}
+}
+
+// reportPortState stores the state of the port observed in APIC and returns the state to report,
+// which is the last stable state while a degraded state is within the flap grace window
+func reportPortState(portOID string, observed capmodel.PortState) capmodel.PortState {
+	previous, err := capmodel.GetPortState(portOID)
+	found := err == nil
+	if err != nil && !errors.Is(err, db.ErrorKeyNotFound) {
+		log.Error("Unable to read the state of port " + portOID + ": " + err.Error())
 	}
-	curSpeedData := strings.Split(portInfoData["operSpeed"].(string), "G")
-	data, err := strconv.ParseFloat(curSpeedData[0], 64)
+	state, err := storePortState(portOID, previous, found, observed)
 	if err != nil {
-		log.Error("Unable to get current speed  of port " + err.Error())
+		log.Error("Unable to store the state of port " + portOID + ": " + err.Error())
+	}
+	return state
+}
+
+// storePortState debounces the observed state of the port and stores it, the clients of the port
+// event streams are notified when the state reported for the port changes
+func storePortState(portOID string, previous capmodel.PortState, found bool, observed capmodel.PortState) (capmodel.PortState, error) {
+	grace := time.Duration(config.Data.APICConf.PortFlapGraceInSeconds) * time.Second
+	state := debouncePortState(previous, found, observed, time.Now(), grace)
+	if err := capmodel.UpdatePortState(portOID, state); err != nil {
+		return state, err
+	}
+	if found && !samePortState(previous, state) {
+		portEventStreams.broadcast(newPortStateEvent(portOID, state))
 	}
-	p.CurrentSpeedGbps = data
-	portsHealthResposne, err := caputilities.GetPortHealth(fabricID, switchIDData[1], p.PortID)
+	return state, nil
+}
+
+// debouncePortState returns the state of the port to store when the observed state follows the
+// previous one. A link going down or health going Critical is kept pending, with the previous
+// state reported, until it persists for the grace window. Recoveries are reported immediately.
+func debouncePortState(previous capmodel.PortState, found bool, observed capmodel.PortState, now time.Time, grace time.Duration) capmodel.PortState {
+	degraded := (observed.LinkState == "Disabled" && previous.LinkState != "Disabled") ||
+		(observed.LinkStatus != "LinkUp" && previous.LinkStatus == "LinkUp") ||
+		(observed.Health == "Critical" && previous.Health != "Critical")
+	if grace <= 0 || !found || !degraded {
+		return observed
+	}
+	pending := previous
+	if previous.PendingSince == nil || previous.Pending == nil || !samePortState(*previous.Pending, observed) {
+		pending.Pending = &observed
+		pending.PendingSince = &now
+		return pending
+	}
+	if now.Sub(*previous.PendingSince) >= grace {
+		return observed
+	}
+	return pending
+}
+
+// samePortState checks if the ports are reported with the same state
+func samePortState(a, b capmodel.PortState) bool {
+	return a.LinkState == b.LinkState && a.LinkStatus == b.LinkStatus && a.State == b.State && a.Health == b.Health
+}
+
+// unknownPortHealth returns the health reported for a port whose health score is missing or
+// not numeric in APIC as per the UnknownHealthPolicy, empty when the status is not reported
+func unknownPortHealth() string {
+	switch config.Data.APICConf.UnknownHealthPolicy {
+	case config.UnknownHealthOK:
+		return "OK"
+	case config.UnknownHealthWarning:
+		return "Warning"
+	}
+	return ""
+}
+
+// portOem returns the OEM properties of the port, derived from the port without querying APIC
+func portOem(podID, switchID, portID, portURI string, transceiver *capresponse.PortTransceiver) *capresponse.PortOem {
+	return &capresponse.PortOem{
+		CiscoACI: capresponse.PortOemCiscoACI{
+			DistinguishedName: caputilities.PortDN(podID, capmodel.SwitchNodeID(switchID), portID),
+			StatisticsHistory: &model.Link{Oid: portURI + "/Oem/CiscoACI/StatisticsHistory"},
+			Transceiver:       transceiver,
+		},
+	}
+}
+
+// portTransceiver reads the transceiver plugged in the port from APIC, nil is returned when the
+// transceiver slot is empty. The transceiver is only an enrichment of the port, it is omitted
+// when it can't be read.
+func portTransceiver(span *captrace.Span, podID, switchID, portID string) *capresponse.PortTransceiver {
+	if config.Data.APICConf.DisableLiveEnrichment {
+		return nil
+	}
+	apicSpan := startAPICSpan(span, "caputilities.GetPortTransceiver")
+	transceiver, err := getPortTransceiver(apicSpan.TraceParent(), podID, capmodel.SwitchNodeID(switchID), portID)
+	apicSpan.RecordError(err)
+	apicSpan.End()
 	if err != nil {
-		log.Error("Unable to get Health of port " + err.Error())
-		return
+		log.Warn("Unable to get transceiver of port " + portID + ": " + err.Error())
+		return nil
+	}
+	if transceiver == nil {
+		return nil
+	}
+	resp := &capresponse.PortTransceiver{
+		Vendor:       transceiver.Vendor,
+		PartNumber:   transceiver.PartNumber,
+		SerialNumber: transceiver.SerialNumber,
+		Type:         transceiver.Type,
 	}
+	if transceiver.WavelengthNanometers > 0 {
+		wavelength := transceiver.WavelengthNanometers
+		resp.WavelengthNanometers = &wavelength
+	}
+	return resp
+}
 
-	Healthdata := portsHealthResposne.IMData[0].HealthData.Attributes
-	currentHealthValue := Healthdata["cur"].(string)
-	healthValue, err := strconv.Atoi(currentHealthValue)
+// portNeighbors reads the devices connected to the port discovered by LLDP and CDP from APIC, nil is
+// returned when none is discovered. The neighbors are only an enrichment of the port, they are omitted
+// when they can't be read.
+func portNeighbors(span *captrace.Span, podID, switchID, portID string) []capresponse.PortNeighbor {
+	if config.Data.APICConf.DisableLiveEnrichment {
+		return nil
+	}
+	apicSpan := startAPICSpan(span, "caputilities.GetPortNeighbors")
+	neighbors, err := getPortNeighbors(apicSpan.TraceParent(), podID, capmodel.SwitchNodeID(switchID), portID)
+	apicSpan.RecordError(err)
+	apicSpan.End()
 	if err != nil {
-		log.Error("Unable to convert current Health value:" + currentHealthValue + " go the error" + err.Error())
-		return
+		log.Warn("Unable to get neighbors of port " + portID + ": " + err.Error())
+		return nil
 	}
-	var portStatus = model.Status{
-		State: p.LinkState,
+	var resp []capresponse.PortNeighbor
+	for _, neighbor := range neighbors {
+		resp = append(resp, capresponse.PortNeighbor{
+			Protocol:   neighbor.Protocol,
+			ChassisID:  neighbor.ChassisID,
+			PortID:     neighbor.PortID,
+			SystemName: neighbor.SystemName,
+		})
 	}
-	if healthValue > 90 {
-		portStatus.Health = "OK"
-	} else if healthValue <= 90 && healthValue < 30 {
-		portStatus.Health = "Warning"
-	} else {
-		portStatus.Health = "Critical"
+	return resp
+}
+
+// setPortType classifies the port by its usage in the fabric reported by APIC, a list like epg or
+// fabric,fabric-ext. The port type stored by the discovery is kept when the usage is unknown.
+func setPortType(p *model.Port, usage string) {
+	usages := map[string]bool{}
+	for _, u := range strings.Split(usage, ",") {
+		usages[strings.TrimSpace(u)] = true
 	}
+	switch {
+	case usages["fabric"] || usages["fabric-ext"]:
+		// uplinks between the leaf and the spine switches
+		p.PortType = "InterswitchPort"
+	case usages["epg"] || usages["infra"] || usages["controller"]:
+		p.PortType = "BidirectionalPort"
+	case usages["discovery"]:
+		// ports without any policy deployed
+		p.PortType = "UnconfiguredPort"
+	}
+}
+
+// copperTransceiverPattern matches the types of the copper transceivers and cables, like
+// 10Gbase-CU3M, QSFP-100G-CU1M or 1000base-T, the other types are optical
+var copperTransceiverPattern = regexp.MustCompile(`(?i)(-cu|base-t$|dac)`)
 
-	p.Status = &portStatus
-	return
+// setPortMedium sets the medium of the port from the transceiver plugged in it, the medium is
+// left unset when the slot is empty or the transceiver type is unknown
+func setPortMedium(p *model.Port, transceiver *capresponse.PortTransceiver) {
+	switch {
+	case transceiver == nil || transceiver.Type == "":
+	case copperTransceiverPattern.MatchString(transceiver.Type):
+		p.PortMedium = "Electrical"
+	default:
+		p.PortMedium = "Optical"
+	}
+}
+
+// isAPICThrottled reports whether the APIC request failed for the rate limit of the plugin or of APIC
+func isAPICThrottled(err error) bool {
+	return errors.Is(err, caputilities.ErrAPICRateLimited) || errors.Is(err, caputilities.ErrAPICThrottled)
+}
+
+// startAPICSpan starts the span of an APIC call made as part of the given span
+func startAPICSpan(span *captrace.Span, name string) *captrace.Span {
+	apicSpan := span.StartChild(name)
+	apicSpan.SetAttribute("apic.host", config.Data.APICConf.APICHost)
+	return apicSpan
+}
+
+// speedUnitsInGbps holds the multiplier for converting the APIC speed units to Gbps
+var speedUnitsInGbps = map[string]float64{
+	"M": 0.001,
+	"G": 1,
+	"T": 1000,
+}
+
+// parseSpeedGbps converts the APIC operSpeed value like "100M", "10G" or "1T" to Gbps.
+// Values which are not numeric, like "unknown" or "inherit", are reported as 0.
+func parseSpeedGbps(operSpeed string) float64 {
+	speed := strings.ToUpper(strings.TrimSpace(operSpeed))
+	multiplier := 1.0
+	if len(speed) > 0 {
+		if unitMultiplier, ok := speedUnitsInGbps[speed[len(speed)-1:]]; ok {
+			multiplier = unitMultiplier
+			speed = speed[:len(speed)-1]
+		}
+	}
+	value, err := strconv.ParseFloat(speed, 64)
+	if err != nil || value < 0 {
+		log.Info("port speed " + operSpeed + " is not a numeric value, reporting speed as 0")
+		return 0
+	}
+	return value * multiplier
 }
 
 func updateErrorResponse(statusMsg, errMsg string, msgArgs []interface{}) interface{} {
@@ -231,7 +886,77 @@ func updateErrorResponse(statusMsg, errMsg string, msgArgs []interface{}) interf
 	return args.CreateGenericErrorResponse()
 }
 
+// withResource adds the @odata.type and @odata.id of the resource involved in the error to
+// the Oem of the extended info messages of the error response, so that the clients can handle
+// the errors based on the type of the resource. The response is unchanged when no resource is given.
+func withResource(resp interface{}, resource resourceRef) interface{} {
+	if resource.odataID == "" {
+		return resp
+	}
+	data, err := json.Marshal(resp)
+	if err != nil {
+		return resp
+	}
+	var errResp map[string]interface{}
+	if err := json.Unmarshal(data, &errResp); err != nil {
+		return resp
+	}
+	errClass, _ := errResp["error"].(map[string]interface{})
+	messages, _ := errClass["@Message.ExtendedInfo"].([]interface{})
+	for _, message := range messages {
+		if message, ok := message.(map[string]interface{}); ok {
+			message["Oem"] = map[string]interface{}{
+				"CiscoACI": map[string]string{
+					"@odata.type": resource.odataType,
+					"@odata.id":   resource.odataID,
+				},
+			}
+		}
+	}
+	return errResp
+}
+
+// checkSwitchExists checks if the switch is stored before its ports are read, the
+// request is answered with 404 when it is not, false is returned when answered
+func checkSwitchExists(ctx iris.Context, switchID string) bool {
+	if !checkSwitchID(ctx, switchID) {
+		return false
+	}
+	exists, err := capmodel.SwitchExists(switchID)
+	if err == nil && !exists {
+		err = fmt.Errorf("%w: switch %s not found", db.ErrorKeyNotFound, switchID)
+	}
+	if err != nil {
+		errMsg := fmt.Sprintf("failed to fetch switch data for uri %s: %s", ctx.Path(), err.Error())
+		createResourceDbErrResp(ctx, err, errMsg, []interface{}{"Switch", switchID}, switchRef(ctx))
+		return false
+	}
+	return true
+}
+
+// checkSwitchID checks the switch id of the request is of the canonical format, the request
+// is answered with 400 when it is not, false is returned when answered
+func checkSwitchID(ctx iris.Context, switchID string) bool {
+	if err := capmodel.ValidateSwitchID(switchID); err != nil {
+		errMsg := fmt.Sprintf("invalid switch id in uri %s: %s", ctx.Path(), err.Error())
+		resp := updateErrorResponse(response.GeneralError, errMsg, nil)
+		ctx.StatusCode(http.StatusBadRequest)
+		writeErrorResponse(ctx, withResource(resp, resourceRef{switchODataType, fmt.Sprintf("/ODIM/v1/Fabrics/%s/Switches/%s", ctx.Params().Get("id"), switchID)}))
+		return false
+	}
+	return true
+}
+
+func switchRef(ctx iris.Context) resourceRef {
+	return resourceRef{switchODataType, fmt.Sprintf("/ODIM/v1/Fabrics/%s/Switches/%s", ctx.Params().Get("id"), ctx.Params().Get("switchID"))}
+}
+
 func createDbErrResp(ctx iris.Context, err error, errMsg string, msgArgs []interface{}) (int, interface{}) {
+	return createResourceDbErrResp(ctx, err, errMsg, msgArgs, resourceRef{})
+}
+
+// createResourceDbErrResp is createDbErrResp reporting the resource involved in the error
+func createResourceDbErrResp(ctx iris.Context, err error, errMsg string, msgArgs []interface{}, resource resourceRef) (int, interface{}) {
 	var resp interface{}
 	var statusCode int
 	switch {
@@ -248,21 +973,196 @@ func createDbErrResp(ctx iris.Context, err error, errMsg string, msgArgs []inter
 		resp = updateErrorResponse(response.InternalError, errMsg, nil)
 		statusCode = http.StatusInternalServerError
 	}
+	resp = withResource(resp, resource)
 	log.Error(errMsg)
 	if ctx != nil {
 		ctx.StatusCode(statusCode)
-		ctx.JSON(resp)
+		writeErrorResponse(ctx, resp)
 	}
 	return statusCode, resp
 }
 
+// createAPICErrResp builds the error response of a failed APIC request, the status code is
+// chosen by the error APIC returned
+func createAPICErrResp(ctx iris.Context, err error, errMsg string, msgArgs []interface{}) (int, interface{}) {
+	var resp interface{}
+	var statusCode int
+	var apicErr *caputilities.APICError
+	switch {
+	case isAPICThrottled(err):
+		if errors.Is(err, caputilities.ErrAPICRateLimited) {
+			errMsg = fmt.Sprintf("%s; the plugin is limiting the rate of the APIC requests", errMsg)
+		} else {
+			errMsg = fmt.Sprintf("%s; APIC is throttling the requests of the plugin", errMsg)
+		}
+		resp = updateErrorResponse(response.GeneralError, errMsg, msgArgs)
+		statusCode = http.StatusTooManyRequests
+	case errors.Is(err, caputilities.ErrAPICWritePrivilege):
+		errMsg = fmt.Sprintf("%s; grant write privilege on the interface policy to APIC user %s", errMsg, config.Data.APICConf.UserName)
+		resp = updateErrorResponse(response.InsufficientPrivilege, errMsg, nil)
+		statusCode = http.StatusForbidden
+	case errors.As(err, &apicErr) && apicErr.Unauthorized():
+		errMsg = fmt.Sprintf("%s; APIC rejected the credentials of APIC user %s", errMsg, config.Data.APICConf.UserName)
+		resp = updateErrorResponse(response.CouldNotEstablishConnection, errMsg, nil)
+		statusCode = http.StatusServiceUnavailable
+	case errors.As(err, &apicErr) && apicErr.NotFound():
+		resp = updateErrorResponse(response.GeneralError, errMsg, msgArgs)
+		statusCode = http.StatusNotFound
+	default:
+		resp = updateErrorResponse(response.GeneralError, errMsg, msgArgs)
+		statusCode = http.StatusBadRequest
+	}
+	log.Error(errMsg)
+	if ctx != nil {
+		writeAPICErrResp(ctx, err, statusCode, resp)
+	}
+	return statusCode, resp
+}
+
+// writeAPICErrResp writes the error response built for the APIC error, the Retry-After
+// header is set when the APIC requests are throttled
+func writeAPICErrResp(ctx iris.Context, err error, statusCode int, resp interface{}) {
+	var throttleErr *caputilities.APICThrottleError
+	if errors.As(err, &throttleErr) {
+		ctx.Header("Retry-After", caputilities.RetryAfterSeconds(throttleErr.RetryAfter))
+	}
+	ctx.StatusCode(statusCode)
+	writeErrorResponse(ctx, resp)
+}
+
 func getPortData(ctx iris.Context, portOID string) *model.Port {
-	log.Info("Port uri" + portOID)
+	capmiddleware.RequestLogger(ctx).Info("Port uri" + portOID)
+	dbSpan := captrace.SpanFromContext(ctx).StartChild("capmodel.GetPort")
 	portData, err := capmodel.GetPort(portOID)
+	dbSpan.RecordError(err)
+	dbSpan.End()
 	if err != nil {
 		errMsg := fmt.Sprintf("failed to fetch port data for uri %s: %s", portOID, err.Error())
-		createDbErrResp(ctx, err, errMsg, []interface{}{"Ports", portOID})
+		createResourceDbErrResp(ctx, err, errMsg, []interface{}{"Ports", portOID}, resourceRef{portODataType, portOID})
 		return nil
 	}
 	return portData
 }
+
+// getStoredPortData collects the port data along with its bytes as stored in the DB, when the
+// port can't be collected the error is written to the response and nil is returned
+func getStoredPortData(ctx iris.Context, portOID string) (*model.Port, []byte) {
+	capmiddleware.RequestLogger(ctx).Info("Port uri" + portOID)
+	dbSpan := captrace.SpanFromContext(ctx).StartChild("capmodel.GetPortRaw")
+	stored, err := capmodel.GetPortRaw(portOID)
+	dbSpan.RecordError(err)
+	dbSpan.End()
+	var portData model.Port
+	if err == nil {
+		if err = json.Unmarshal(stored, &portData); err != nil {
+			err = fmt.Errorf("while trying to unmarshal port data, got: %v", err)
+		}
+	}
+	if err != nil {
+		errMsg := fmt.Sprintf("failed to fetch port data for uri %s: %s", portOID, err.Error())
+		createResourceDbErrResp(ctx, err, errMsg, []interface{}{"Ports", portOID}, resourceRef{portODataType, portOID})
+		return nil, nil
+	}
+	return &portData, stored
+}
+
+// negotiateMediaType selects the response media type from the Accept header of the request,
+// JSON is used when the header is absent. The supported media type of the highest quality value
+// is selected, the first one listed among the ones of the same quality, and the media ranges of
+// quality 0 are not acceptable. When none of the accepted media types are supported 406 is written
+// to the response and false is returned.
+func negotiateMediaType(ctx iris.Context) (string, bool) {
+	accept := ctx.GetHeader("Accept")
+	if strings.TrimSpace(accept) == "" {
+		return mediaTypeJSON, true
+	}
+	selected, selectedQuality := "", 0.0
+	for _, mediaRange := range strings.Split(accept, ",") {
+		params := strings.Split(mediaRange, ";")
+		var mediaType string
+		switch strings.ToLower(strings.TrimSpace(params[0])) {
+		case mediaTypeJSON, "application/*", "*/*":
+			mediaType = mediaTypeJSON
+		case mediaTypeXML, "text/xml":
+			mediaType = mediaTypeXML
+		default:
+			continue
+		}
+		if quality := mediaRangeQuality(params[1:]); quality > selectedQuality {
+			selected, selectedQuality = mediaType, quality
+		}
+	}
+	if selected != "" {
+		return selected, true
+	}
+	errMsg := fmt.Sprintf("none of the media types %s accepted by the client is supported", accept)
+	log.Error(errMsg)
+	resp := updateErrorResponse(response.GeneralError, errMsg, nil)
+	ctx.StatusCode(http.StatusNotAcceptable)
+	writeErrorResponse(ctx, resp)
+	return "", false
+}
+
+// mediaRangeQuality returns the quality value of the q parameter of a media range of the Accept
+// header, 1 when it is absent. An invalid quality value is taken as 0, the media range is not acceptable.
+func mediaRangeQuality(params []string) float64 {
+	for _, param := range params {
+		name, value, _ := strings.Cut(param, "=")
+		if !strings.EqualFold(strings.TrimSpace(name), "q") {
+			continue
+		}
+		quality, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+		if err != nil || quality < 0 || quality > 1 {
+			return 0
+		}
+		return quality
+	}
+	return 1
+}
+
+// preferredReturn returns the return preference, minimal or representation, of the Prefer header of the
+// request and echoes it in the Preference-Applied header. "" is returned when the client has no return
+// preference, the representation is then returned as when it is preferred.
+func preferredReturn(ctx iris.Context) string {
+	for _, header := range ctx.Request().Header.Values("Prefer") {
+		for _, preference := range strings.Split(header, ",") {
+			// the parameters of the preference, after ;, are ignored
+			name, value, _ := strings.Cut(strings.Split(preference, ";")[0], "=")
+			if !strings.EqualFold(strings.TrimSpace(name), "return") {
+				continue
+			}
+			value = strings.ToLower(strings.Trim(strings.TrimSpace(value), `"`))
+			if value == returnMinimal || value == returnRepresentation {
+				ctx.Header("Preference-Applied", "return="+value)
+				return value
+			}
+		}
+	}
+	return ""
+}
+
+func writeXML(ctx iris.Context, data interface{}) {
+	body, err := xml.Marshal(data)
+	if err != nil {
+		errMsg := "failed to marshal the response to XML: " + err.Error()
+		log.Error(errMsg)
+		ctx.StatusCode(http.StatusInternalServerError)
+		writeErrorResponse(ctx, updateErrorResponse(response.InternalError, errMsg, nil))
+		return
+	}
+	ctx.ContentType(mediaTypeXML)
+	ctx.Write(append([]byte(xml.Header), body...))
+}
+
+// headXML answers HEAD with the headers of the XML encoded data written by writeXML for GET
+func headXML(ctx iris.Context, data interface{}) {
+	body, err := xml.Marshal(data)
+	if err != nil {
+		log.Error("failed to marshal the response to XML: " + err.Error())
+		ctx.StatusCode(http.StatusInternalServerError)
+		return
+	}
+	ctx.ContentType(mediaTypeXML)
+	ctx.Header("Content-Length", strconv.Itoa(len(xml.Header)+len(body)))
+	ctx.StatusCode(http.StatusOK)
+}
@@ -0,0 +1,116 @@
+//(C) Copyright [2020] Hewlett Packard Enterprise Development LP
+//
+//Licensed under the Apache License, Version 2.0 (the "License"); you may
+//not use this file except in compliance with the License. You may obtain
+//a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+//Unless required by applicable law or agreed to in writing, software
+//distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+//WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+//License for the specific language governing permissions and limitations
+// under the License.
+
+//Package caphandler ...
+package caphandler
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/ODIM-Project/ODIM/lib-dmtf/model"
+	"github.com/ODIM-Project/ODIM/lib-utilities/response"
+	"github.com/ODIM-Project/PluginCiscoACI/capmodel"
+	"github.com/ODIM-Project/PluginCiscoACI/capresponse"
+	iris "github.com/kataras/iris/v12"
+	log "github.com/sirupsen/logrus"
+)
+
+const portsByHealthODataType = "#CiscoACIPortsByHealth.v1_0_0.PortsByHealth"
+
+var (
+	// portHealthStates are the health states the ports are indexed by
+	portHealthStates = []string{"OK", "Warning", "Critical"}
+	// defaultPortsByHealthStates are the health states of the ports returned when not requested
+	defaultPortsByHealthStates = []string{"Warning", "Critical"}
+)
+
+// GetPortsByHealth returns the ports of the fabric, or of the switch, whose last known health is one of
+// the comma separated states of the health query parameter, Warning and Critical when not given. The
+// health is the one stored on the port GET and by the health poll, it isn't read from APIC, and the ports
+// whose health was never read are not returned.
+func GetPortsByHealth(ctx iris.Context) {
+	fabricID := ctx.Params().Get("id")
+	switchID := ctx.Params().Get("switchID")
+	states := defaultPortsByHealthStates
+	if ctx.URLParamExists("health") {
+		states = strings.Split(ctx.URLParam("health"), ",")
+	}
+	requested := map[string]bool{}
+	for _, state := range states {
+		requested[state] = true
+		if !isPortHealthState(state) {
+			errMsg := fmt.Sprintf("invalid value %s for query parameter health, it should be one of %s",
+				state, strings.Join(portHealthStates, ", "))
+			log.Error(errMsg)
+			resp := updateErrorResponse(response.GeneralError, errMsg, nil)
+			ctx.StatusCode(http.StatusBadRequest)
+			writeErrorResponse(ctx, resp)
+			return
+		}
+	}
+	if _, err := capmodel.GetFabric(fabricID); err != nil {
+		errMsg := fmt.Sprintf("failed to fetch ports by health for uri %s: %s", ctx.Path(), err.Error())
+		createResourceDbErrResp(ctx, err, errMsg, []interface{}{"Fabric", fabricID}, resourceRef{fabricODataType, "/ODIM/v1/Fabrics/" + fabricID})
+		return
+	}
+	scope := "/ODIM/v1/Fabrics/" + fabricID
+	if switchID != "" {
+		if !checkSwitchExists(ctx, switchID) {
+			return
+		}
+		scope += "/Switches/" + switchID
+	}
+
+	portsByHealth := capresponse.PortsByHealth{
+		ODataID:   ctx.Path(),
+		ODataType: portsByHealthODataType,
+		ID:        "PortsByHealth",
+		Name:      "Ports By Health",
+		Health:    states,
+		// the members are always present so that clients can iterate them without checking for null
+		Members: []capresponse.PortHealthMember{},
+	}
+	for _, state := range portHealthStates {
+		if !requested[state] {
+			continue
+		}
+		portOIDs, err := capmodel.GetPortsByHealth(scope, []string{state})
+		if err != nil {
+			errMsg := fmt.Sprintf("failed to fetch ports by health for uri %s: %s", ctx.Path(), err.Error())
+			createDbErrResp(ctx, err, errMsg, nil)
+			return
+		}
+		for _, portOID := range portOIDs {
+			portsByHealth.Members = append(portsByHealth.Members, capresponse.PortHealthMember{
+				Port:   &model.Link{Oid: portOID},
+				Health: state,
+			})
+		}
+	}
+	portsByHealth.MembersCount = len(portsByHealth.Members)
+	ctx.StatusCode(http.StatusOK)
+	ctx.JSON(portsByHealth)
+}
+
+// isPortHealthState tells whether the ports are indexed by the health state
+func isPortHealthState(state string) bool {
+	for _, health := range portHealthStates {
+		if health == state {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,54 @@
+//(C) Copyright [2020] Hewlett Packard Enterprise Development LP
+//
+//Licensed under the Apache License, Version 2.0 (the "License"); you may
+//not use this file except in compliance with the License. You may obtain
+//a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+//Unless required by applicable law or agreed to in writing, software
+//distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+//WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+//License for the specific language governing permissions and limitations
+// under the License.
+
+package caphandler
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/ODIM-Project/ODIM/lib-dmtf/model"
+	"github.com/ODIM-Project/PluginCiscoACI/capdata"
+	"github.com/ODIM-Project/PluginCiscoACI/capmodel"
+)
+
+func TestGetPortsByHealth(t *testing.T) {
+	e := mockPortApp(t)
+	otherSwitchID := "switchUUID:102"
+	otherPortURI := "/ODIM/v1/Fabrics/fabricID/Switches/switchUUID:102/Ports/portUUID:eth1-2"
+	capmodel.SaveFabric(testFabricID, &capdata.Fabric{PodID: "1", SwitchData: []string{testSwitchID, otherSwitchID}})
+	capmodel.SaveSwitch(otherSwitchID, &model.Switch{ID: otherSwitchID})
+	capmodel.UpdatePortState(testPortURI, capmodel.PortState{LinkState: "Enabled", Health: "Critical"})
+	capmodel.UpdatePortState(otherPortURI, capmodel.PortState{LinkState: "Enabled", Health: "Warning"})
+	capmodel.UpdatePortState(testPortsURI+"/portUUID:eth1-3", capmodel.PortState{LinkState: "Enabled", Health: "OK"})
+	fabricPortsURI := "/ODIM/v1/Fabrics/fabricID/Oem/CiscoACI/PortsByHealth"
+
+	// the Warning and Critical ports by default, ordered by health
+	ports := e.GET(fabricPortsURI).Expect().Status(http.StatusOK).JSON().Object()
+	ports.Value("Members@odata.count").Number().Equal(2)
+	ports.Path("$.Members[0]").Object().ValueEqual("Health", "Warning").Path("$.Port['@odata.id']").Equal(otherPortURI)
+	ports.Path("$.Members[1]").Object().ValueEqual("Health", "Critical").Path("$.Port['@odata.id']").Equal(testPortURI)
+
+	ports = e.GET(fabricPortsURI).WithQuery("health", "OK").Expect().Status(http.StatusOK).JSON().Object()
+	ports.Value("Members@odata.count").Number().Equal(1)
+
+	// the ports of the switch only
+	ports = e.GET(testSwitchURI + "/Oem/CiscoACI/PortsByHealth").Expect().Status(http.StatusOK).JSON().Object()
+	ports.Value("Members@odata.count").Number().Equal(1)
+	ports.Path("$.Members[0].Port['@odata.id']").Equal(testPortURI)
+
+	e.GET(fabricPortsURI).WithQuery("health", "Unknown").Expect().Status(http.StatusBadRequest)
+	e.GET("/ODIM/v1/Fabrics/otherFabric/Oem/CiscoACI/PortsByHealth").Expect().Status(http.StatusNotFound)
+	e.GET("/ODIM/v1/Fabrics/fabricID/Switches/switchUUID:109/Oem/CiscoACI/PortsByHealth").Expect().Status(http.StatusNotFound)
+}
@@ -0,0 +1,1486 @@
+//(C) Copyright [2020] Hewlett Packard Enterprise Development LP
+//
+//Licensed under the Apache License, Version 2.0 (the "License"); you may
+//not use this file except in compliance with the License. You may obtain
+//a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+//Unless required by applicable law or agreed to in writing, software
+//distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+//WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+//License for the specific language governing permissions and limitations
+// under the License.
+
+package caphandler
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	nethttptest "net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/ODIM-Project/ODIM/lib-dmtf/model"
+	"github.com/ODIM-Project/PluginCiscoACI/capdata"
+	"github.com/ODIM-Project/PluginCiscoACI/capmiddleware"
+	"github.com/ODIM-Project/PluginCiscoACI/capmodel"
+	"github.com/ODIM-Project/PluginCiscoACI/capresponse"
+	"github.com/ODIM-Project/PluginCiscoACI/caputilities"
+	"github.com/ODIM-Project/PluginCiscoACI/config"
+	"github.com/ODIM-Project/PluginCiscoACI/db"
+
+	iris "github.com/kataras/iris/v12"
+	"github.com/kataras/iris/v12/httptest"
+	"golang.org/x/crypto/sha3"
+)
+
+const (
+	testFabricID   = "fabricID"
+	testSwitchID   = "switchUUID:101"
+	testPortsURI   = "/ODIM/v1/Fabrics/fabricID/Switches/switchUUID:101/Ports"
+	testPortID     = "portUUID:eth1-1"
+	testPortURI    = testPortsURI + "/" + testPortID
+	testEthernetID = "/ODIM/v1/Systems/sysUUID.1/EthernetInterfaces/1"
+)
+
+func mockPortApp(t *testing.T) *httptest.Expect {
+	config.SetUpMockConfig(t)
+	db.Connector = db.NewMockMemoryConnector()
+	capmodel.InvalidatePortCache()
+	capmodel.InvalidateFabricCache()
+	resetSwitchPortsHealth()
+	// the port health is read per port unless a test provides the health of the switch ports
+	getSwitchPortsHealth = func(traceParent, podID, ACISwitchID string) (map[string]capmodel.HealthData, error) {
+		return nil, errors.New("health of the switch ports not available")
+	}
+	// the transceiver slot is empty unless a test plugs a transceiver
+	getPortTransceiver = func(traceParent, podID, ACISwitchID, portID string) (*capmodel.PortTransceiver, error) {
+		return nil, nil
+	}
+	// no neighbor is discovered unless a test connects one
+	getPortNeighbors = func(traceParent, podID, ACISwitchID, portID string) ([]capmodel.PortNeighbor, error) {
+		return nil, nil
+	}
+	capmodel.SaveSwitch(testSwitchID, &model.Switch{ID: testSwitchID})
+	capmodel.SaveSwitchPort(testSwitchID, []string{testPortID})
+	mockApp := iris.New()
+	mockApp.WrapRouter(capmiddleware.TrailingSlash)
+	fabricRoutes := mockApp.Party("/ODIM/v1/Fabrics")
+	fabricRoutes.Get("/{id}/Oem/CiscoACI/PortFaults", GetPortFaults)
+	fabricRoutes.Get("/{id}/Switches/{switchID}/Oem/CiscoACI/PortFaults", GetPortFaults)
+	fabricRoutes.Get("/{id}/Oem/CiscoACI/PortsByHealth", GetPortsByHealth)
+	fabricRoutes.Get("/{id}/Switches/{switchID}/Oem/CiscoACI/PortsByHealth", GetPortsByHealth)
+	fabricRoutes.Get("/{id}/Switches/{switchID}/Ports", GetPortCollection)
+	fabricRoutes.Head("/{id}/Switches/{switchID}/Ports", GetPortCollection)
+	fabricRoutes.Get("/{id}/Switches/{switchID}/Ports/{portID}", GetPortInfo)
+	fabricRoutes.Head("/{id}/Switches/{switchID}/Ports/{portID}", GetPortInfo)
+	fabricRoutes.Patch("/{id}/Switches/{switchID}/Ports/{portID}", PatchPort)
+	fabricRoutes.Get("/{id}/Switches/{switchID}/Ports/{portID}/Oem/CiscoACI/StatisticsHistory", GetPortStatisticsHistory)
+	fabricRoutes.Get("/{id}/Switches/{switchID}/Ports/{portID}/Settings", GetPortSettings)
+	fabricRoutes.Patch("/{id}/Switches/{switchID}/Ports/{portID}/Settings", PatchPortSettings)
+	fabricRoutes.Post("/{id}/Switches/{switchID}/Ports/{portID}/Actions/Port.Reset", ResetPort)
+	fabricRoutes.Delete("/{id}/Switches/{switchID}/Ports/{portID}/Links/ConnectedPorts", DeletePortConnectedPorts)
+	return httptest.New(t, mockApp)
+}
+
+func TestGetPortCollectionMediaType(t *testing.T) {
+	e := mockPortApp(t)
+
+	// JSON is the default
+	e.GET(testPortsURI).Expect().Status(http.StatusOK).
+		JSON().Object().Value("Members@odata.count").Number().Equal(1)
+	e.GET(testPortsURI).WithHeader("Accept", "application/json").Expect().Status(http.StatusOK).
+		ContentType("application/json")
+
+	// XML on request
+	resp := e.GET(testPortsURI).WithHeader("Accept", "application/xml").Expect().Status(http.StatusOK)
+	resp.ContentType("application/xml")
+	resp.Body().Contains("<MembersCount>1</MembersCount>").Contains(testPortURI)
+
+	// the media type of the highest quality is selected
+	e.GET(testPortsURI).WithHeader("Accept", "application/xml;q=0.1, application/json").Expect().Status(http.StatusOK).
+		ContentType("application/json")
+	e.GET(testPortsURI).WithHeader("Accept", "application/json;q=0.5, application/xml").Expect().Status(http.StatusOK).
+		ContentType("application/xml")
+
+	// unsupported media type
+	e.GET(testPortsURI).WithHeader("Accept", "text/csv").Expect().Status(http.StatusNotAcceptable)
+	e.GET(testPortURI).WithHeader("Accept", "text/csv").Expect().Status(http.StatusNotAcceptable)
+	e.GET(testPortsURI).WithHeader("Accept", "application/xml;q=0, text/csv").Expect().Status(http.StatusNotAcceptable)
+}
+
+func TestGetPortCollectionCount(t *testing.T) {
+	e := mockPortApp(t)
+	e.GET(testPortsURI).WithQuery("$count", "true").Expect().Status(http.StatusOK).
+		Body().Equal("1")
+	e.HEAD(testPortsURI).Expect().Status(http.StatusOK).
+		Header("X-Total-Count").Equal("1")
+	e.GET("/ODIM/v1/Fabrics/fabricID/Switches/unknown:102/Ports").WithQuery("$count", "true").
+		Expect().Status(http.StatusNotFound)
+}
+
+func TestGetPortCollectionEmptySwitch(t *testing.T) {
+	e := mockPortApp(t)
+	capmodel.SaveSwitch("switchUUID:102", &model.Switch{ID: "switchUUID:102"})
+	emptyPortsURI := "/ODIM/v1/Fabrics/fabricID/Switches/switchUUID:102/Ports"
+
+	collection := e.GET(emptyPortsURI).Expect().Status(http.StatusOK).JSON().Object()
+	collection.Value("Members@odata.count").Number().Equal(0)
+	collection.Value("Members").Array().Empty()
+	e.GET(emptyPortsURI).WithQuery("$count", "true").Expect().Status(http.StatusOK).Body().Equal("0")
+	e.GET("/ODIM/v1/Fabrics/fabricID/Switches/switchUUID:103/Ports").Expect().Status(http.StatusNotFound)
+}
+
+func TestGetPortCollectionInvalidSwitchID(t *testing.T) {
+	e := mockPortApp(t)
+	for _, switchID := range []string{"switchUUID", "switchUUID:leaf101", "switchUUID:0101"} {
+		resource := e.GET("/ODIM/v1/Fabrics/fabricID/Switches/" + switchID + "/Ports").Expect().Status(http.StatusBadRequest).
+			JSON().Path("$.error['@Message.ExtendedInfo'][0].Oem.CiscoACI").Object()
+		resource.Value("@odata.type").Equal(switchODataType)
+		resource.Value("@odata.id").Equal("/ODIM/v1/Fabrics/fabricID/Switches/" + switchID)
+	}
+}
+
+func TestGetPortInfoLiveEnrichmentDisabled(t *testing.T) {
+	e := mockPortApp(t)
+	config.Data.APICConf.DisableLiveEnrichment = true
+	capmodel.SaveFabric(testFabricID, &capdata.Fabric{PodID: "1", SwitchData: []string{testSwitchID}})
+	capmodel.SavePort(testPortURI, &model.Port{ODataID: testPortURI, ID: testPortID, PortID: "eth1/1"})
+	getPortInfo = func(traceParent, podID, ACISwitchID, portID string) (*capmodel.PortInfoResponse, error) {
+		t.Error("GetPortInfo must not be called when live enrichment is disabled")
+		return nil, errors.New("unexpected call")
+	}
+	getPortHealth = func(traceParent, podID, ACISwitchID, portID string) (*capmodel.Health, error) {
+		t.Error("GetPortHealth must not be called when live enrichment is disabled")
+		return nil, errors.New("unexpected call")
+	}
+	defer func() {
+		getPortInfo = caputilities.GetPortInfo
+		getPortHealth = caputilities.GetPortHealth
+	}()
+
+	e.GET(testPortURI).Expect().Status(http.StatusOK).JSON().Object().Value("Id").Equal(testPortID)
+}
+
+func TestGetPortInfoQueryString(t *testing.T) {
+	e := mockPortApp(t)
+	config.Data.APICConf.DisableLiveEnrichment = true
+	capmodel.SaveFabric(testFabricID, &capdata.Fabric{PodID: "1", SwitchData: []string{testSwitchID}})
+	capmodel.SavePort(testPortURI, &model.Port{ODataID: testPortURI, ID: testPortID, PortID: "eth1/1"})
+
+	// the query string isn't part of the key of the port, with or without a trailing slash
+	e.GET(testPortURI).WithQuery("x", "y").Expect().Status(http.StatusOK).JSON().Object().Value("Id").Equal(testPortID)
+	e.GET(testPortURI+"/").WithQuery("x", "y").Expect().Status(http.StatusOK).JSON().Object().Value("Id").Equal(testPortID)
+}
+
+func TestGetPortCollectionHead(t *testing.T) {
+	e := mockPortApp(t)
+	capmodel.SaveSwitchPort(testSwitchID, []string{testPortID, "portUUID:eth1-2"})
+	for _, mediaType := range []string{"application/json", "application/xml"} {
+		get := e.GET(testPortsURI).WithHeader("Accept", mediaType).Expect().Status(http.StatusOK)
+		head := e.HEAD(testPortsURI).WithHeader("Accept", mediaType).Expect().Status(http.StatusOK)
+		head.Body().Empty()
+		head.ContentType(mediaType)
+		head.Header("Content-Length").Equal(strconv.Itoa(len(get.Body().Raw())))
+		head.Header("ETag").Equal(get.Header("ETag").NotEmpty().Raw())
+		head.Header("X-Total-Count").Equal("2")
+	}
+
+	// the entity tag changes with the members of the page
+	etag := e.HEAD(testPortsURI).Expect().Status(http.StatusOK).Header("ETag").Raw()
+	e.HEAD(testPortsURI).WithQuery("$top", "1").Expect().Status(http.StatusOK).Header("ETag").NotEqual(etag)
+	capmodel.SaveSwitchPort(testSwitchID, []string{testPortID})
+	e.HEAD(testPortsURI).Expect().Status(http.StatusOK).Header("ETag").NotEqual(etag)
+
+	// the count has the headers of its GET
+	count := e.GET(testPortsURI).WithQuery("$count", "true").Expect().Status(http.StatusOK)
+	e.HEAD(testPortsURI).WithQuery("$count", "true").Expect().Status(http.StatusOK).
+		Header("Content-Length").Equal(strconv.Itoa(len(count.Body().Raw())))
+}
+
+func TestGetPortInfoHead(t *testing.T) {
+	e := mockPortApp(t)
+	capmodel.SaveFabric(testFabricID, &capdata.Fabric{PodID: "1", SwitchData: []string{testSwitchID}})
+	capmodel.SavePort(testPortURI, &model.Port{ODataID: testPortURI, ID: testPortID, PortID: "eth1/1"})
+	getPortInfo = func(traceParent, podID, ACISwitchID, portID string) (*capmodel.PortInfoResponse, error) {
+		t.Error("GetPortInfo must not be called for HEAD")
+		return nil, errors.New("unexpected call")
+	}
+	getPortTransceiver = func(traceParent, podID, ACISwitchID, portID string) (*capmodel.PortTransceiver, error) {
+		t.Error("GetPortTransceiver must not be called for HEAD")
+		return nil, errors.New("unexpected call")
+	}
+	defer func() {
+		getPortInfo = caputilities.GetPortInfo
+		getPortTransceiver = caputilities.GetPortTransceiver
+	}()
+
+	resp := e.HEAD(testPortURI).Expect().Status(http.StatusOK)
+	resp.Body().Empty()
+	resp.ContentType("application/json")
+	resp.Header("Content-Length").NotEmpty()
+	resp.Header("Content-Length").NotEqual("0")
+	etag := resp.Header("ETag").Raw()
+	if !strings.HasPrefix(etag, `W/"`) {
+		t.Errorf("ETag = %q, want a weak entity tag", etag)
+	}
+	e.HEAD(testPortURI).WithHeader("Accept", "application/xml").Expect().Status(http.StatusOK).
+		ContentType("application/xml")
+	e.HEAD(testPortsURI + "/portUUID:eth1-2").Expect().Status(http.StatusNotFound)
+
+	// the entity tag changes with the stored port only
+	config.Data.APICConf.DisableLiveEnrichment = true
+	e.GET(testPortURI).Expect().Status(http.StatusOK).Header("ETag").Equal(etag)
+	capmodel.SavePort(testPortURI, &model.Port{ODataID: testPortURI, ID: testPortID, PortID: "eth1/1", Description: "uplink"})
+	e.HEAD(testPortURI).Expect().Status(http.StatusOK).Header("ETag").NotEqual(etag)
+	// the entity tag hashes the bytes stored
+	stored, err := capmodel.GetPortRaw(testPortURI)
+	if err != nil {
+		t.Fatalf("GetPortRaw() error = %v", err)
+	}
+	e.GET(testPortURI).Expect().Status(http.StatusOK).Header("ETag").Equal(portETag(stored))
+}
+
+func TestGetPortInfoAPICDistinguishedName(t *testing.T) {
+	e := mockPortApp(t)
+	config.Data.APICConf.DisableLiveEnrichment = true
+	capmodel.SaveFabric(testFabricID, &capdata.Fabric{PodID: "1", SwitchData: []string{testSwitchID}})
+	capmodel.SavePort(testPortURI, &model.Port{ODataID: testPortURI, ID: testPortID, PortID: "eth1/1"})
+
+	port := e.GET(testPortURI).Expect().Status(http.StatusOK).JSON().Object()
+	port.Value("Id").Equal(testPortID)
+	port.Path("$.Oem.CiscoACI.DistinguishedName").Equal("topology/pod-1/node-101/sys/phys-[eth1/1]")
+	port.Path("$.Oem.CiscoACI.StatisticsHistory['@odata.id']").Equal(testPortURI + "/Oem/CiscoACI/StatisticsHistory")
+}
+
+func TestParseSpeedGbps(t *testing.T) {
+	tests := []struct {
+		operSpeed string
+		want      float64
+	}{
+		{"10G", 10},
+		{"100G", 100},
+		{"25g", 25},
+		{"100M", 0.1},
+		{"1T", 1000},
+		{"40", 40},
+		{"unknown", 0},
+		{"inherit", 0},
+		{"", 0},
+		{"G", 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.operSpeed, func(t *testing.T) {
+			if got := parseSpeedGbps(tt.operSpeed); got != tt.want {
+				t.Errorf("parseSpeedGbps(%q) = %v, want %v", tt.operSpeed, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGetPortCollectionPagination(t *testing.T) {
+	e := mockPortApp(t)
+	capmodel.AddSwitchPort(testSwitchID, "p2")
+	capmodel.AddSwitchPort(testSwitchID, "p3")
+	capmodel.AddSwitchPort(testSwitchID, "p4")
+	capmodel.AddSwitchPort(testSwitchID, "p5")
+
+	resp := e.GET(testPortsURI).WithQuery("$top", 2).WithQuery("$skip", 2).WithQuery("Name", "eth1").
+		Expect().Status(http.StatusOK)
+	pageURI := func(skip string) string {
+		return "<" + testPortsURI + "?$skip=" + skip + "&$top=2&Name=eth1>"
+	}
+	resp.Header("Link").Equal(pageURI("0") + `; rel="first", ` + pageURI("0") + `; rel="prev", ` +
+		pageURI("4") + `; rel="next", ` + pageURI("4") + `; rel="last"`)
+	body := resp.JSON().Object()
+	body.Value("Members@odata.count").Number().Equal(5)
+	body.Value("Members").Array().Length().Equal(2)
+	body.Value("Members").Array().Element(0).Object().Value("@odata.id").Equal(testPortsURI + "/p3")
+	body.Value("Members@odata.nextLink").Equal(testPortsURI + "?$skip=4&$top=2&Name=eth1")
+
+	e.GET(testPortsURI).WithQuery("$top", "-1").Expect().Status(http.StatusBadRequest)
+}
+
+func TestGetPortCollectionDuplicatePorts(t *testing.T) {
+	e := mockPortApp(t)
+	// a port listed twice, like after an interrupted discovery
+	capmodel.SaveSwitchPort(testSwitchID, []string{testPortID, "p2", testPortID})
+
+	members := e.GET(testPortsURI).Expect().Status(http.StatusOK).JSON().Object().Value("Members").Array()
+	members.Length().Equal(2)
+	members.Element(0).Object().Value("@odata.id").Equal(testPortURI)
+	members.Element(1).Object().Value("@odata.id").Equal(testPortsURI + "/p2")
+}
+
+func TestGetPortCollectionMaxPageSize(t *testing.T) {
+	e := mockPortApp(t)
+	capmodel.AddSwitchPort(testSwitchID, "p2")
+	capmodel.AddSwitchPort(testSwitchID, "p3")
+	config.Data.ServerConf.MaxPageSize = 2
+
+	// $top above the maximum is clamped and the links carry the clamped $top
+	resp := e.GET(testPortsURI).WithQuery("$top", 1000000).Expect().Status(http.StatusOK)
+	resp.Header("Link").Contains("<" + testPortsURI + "?$skip=2&$top=2>; rel=\"next\"")
+	body := resp.JSON().Object()
+	body.Value("Members@odata.count").Number().Equal(3)
+	body.Value("Members").Array().Length().Equal(2)
+	body.Value("Members@odata.nextLink").Equal(testPortsURI + "?$skip=2&$top=2")
+
+	// $top of 0 returns an empty page with the count
+	body = e.GET(testPortsURI).WithQuery("$top", 0).Expect().Status(http.StatusOK).JSON().Object()
+	body.Value("Members@odata.count").Number().Equal(3)
+	body.Value("Members").Array().Empty()
+	body.NotContainsKey("Members@odata.nextLink")
+}
+
+func TestPatchPortWritableProperties(t *testing.T) {
+	e := mockPortApp(t)
+	capmodel.SavePort(testPortURI, &model.Port{ODataID: testPortURI, ID: testPortID, PortID: "eth1/1", Description: "old"})
+	defer func() { config.Data.WritablePortProperties = nil }()
+
+	// only links are writable by default
+	e.PATCH(testPortURI).WithJSON(map[string]interface{}{"Links": map[string]interface{}{"ConnectedPorts": []interface{}{}}}).
+		Expect().Status(http.StatusOK)
+	e.PATCH(testPortURI).WithJSON(map[string]interface{}{"Description": "new"}).
+		Expect().Status(http.StatusBadRequest).Body().Contains("PropertyNotWritable")
+
+	config.Data.WritablePortProperties = []string{"Links", "Description"}
+	e.PATCH(testPortURI).WithJSON(map[string]interface{}{"Description": "new"}).
+		Expect().Status(http.StatusOK).JSON().Object().Value("Description").Equal("new")
+	e.PATCH(testPortURI).WithJSON(map[string]interface{}{"Name": "port"}).
+		Expect().Status(http.StatusBadRequest).Body().Contains("PropertyNotWritable")
+	port, err := capmodel.GetPort(testPortURI)
+	if err != nil {
+		t.Fatalf("GetPort() error = %v", err)
+	}
+	if port.Description != "new" || port.Name != "" {
+		t.Errorf("stored port Description = %s, Name = %s, want only Description updated", port.Description, port.Name)
+	}
+}
+
+func TestPortTrailingSlash(t *testing.T) {
+	e := mockPortApp(t)
+	config.Data.APICConf.DisableLiveEnrichment = true
+	config.Data.WritablePortProperties = []string{"Links", "Description"}
+	defer func() { config.Data.WritablePortProperties = nil }()
+	capmodel.SavePort(testPortURI, &model.Port{ODataID: testPortURI, ID: testPortID, PortID: "eth1/1"})
+
+	get := e.GET(testPortURI).Expect().Status(http.StatusOK).Body().Raw()
+	for _, uri := range []string{testPortURI + "/", testPortURI + "//"} {
+		e.GET(uri).Expect().Status(http.StatusOK).Body().Equal(get)
+	}
+	patch := e.PATCH(testPortURI).WithJSON(map[string]interface{}{"Description": "uplink"}).
+		Expect().Status(http.StatusOK).Body().Raw()
+	e.PATCH(testPortURI + "/").WithJSON(map[string]interface{}{"Description": "uplink"}).
+		Expect().Status(http.StatusOK).Body().Equal(patch)
+	e.GET(testPortsURI + "/").Expect().Status(http.StatusOK).JSON().Object().Value("@odata.id").Equal(testPortsURI)
+}
+
+func TestPortCacheControl(t *testing.T) {
+	e := mockPortApp(t)
+	config.Data.ServerConf.CacheMaxAgeInSeconds = 60
+	config.Data.WritablePortProperties = []string{"Links", "Description"}
+	defer func() { config.Data.WritablePortProperties = nil }()
+	capmodel.SaveFabric(testFabricID, &capdata.Fabric{PodID: "1", SwitchData: []string{testSwitchID}})
+	capmodel.SavePort(testPortURI, &model.Port{ODataID: testPortURI, ID: testPortID, PortID: "eth1/1"})
+	getPortInfo = func(traceParent, podID, ACISwitchID, portID string) (*capmodel.PortInfoResponse, error) {
+		return &capmodel.PortInfoResponse{IMData: []capmodel.PortInfoIMData{{
+			PhysicalInterface: capmodel.PhysicalInterface{Attributes: map[string]interface{}{"operSt": "up"}},
+		}}}, nil
+	}
+	getPortHealth = func(traceParent, podID, ACISwitchID, portID string) (*capmodel.Health, error) {
+		return &capmodel.Health{IMData: []capmodel.HealthIMData{{
+			HealthData: capmodel.HealthData{Attributes: map[string]interface{}{"cur": "100", "maxSev": "cleared"}},
+		}}}, nil
+	}
+	defer func() {
+		getPortInfo = caputilities.GetPortInfo
+		getPortHealth = caputilities.GetPortHealth
+	}()
+
+	// the stored resources are reused for the configured max-age
+	e.GET(testPortsURI).Expect().Status(http.StatusOK).Header("Cache-Control").Equal("max-age=60")
+	e.HEAD(testPortsURI).Expect().Status(http.StatusOK).Header("Cache-Control").Equal("max-age=60")
+	e.HEAD(testPortURI).Expect().Status(http.StatusOK).Header("Cache-Control").Equal("max-age=60")
+	// the port enriched from APIC isn't reused beyond the health read from APIC
+	e.GET(testPortURI).Expect().Status(http.StatusOK).Header("Cache-Control").Equal("max-age=10")
+	config.Data.ServerConf.CacheMaxAgeInSeconds = 5
+	e.GET(testPortURI).Expect().Status(http.StatusOK).Header("Cache-Control").Equal("max-age=5")
+	config.Data.APICConf.DisableLiveEnrichment = true
+	config.Data.ServerConf.CacheMaxAgeInSeconds = 60
+	e.GET(testPortURI).Expect().Status(http.StatusOK).Header("Cache-Control").Equal("max-age=60")
+
+	// the writes are not stored
+	e.PATCH(testPortURI).WithJSON(map[string]interface{}{"Description": "uplink"}).
+		Expect().Status(http.StatusOK).Header("Cache-Control").Equal("no-store")
+	e.PATCH(testPortURI).WithJSON(map[string]interface{}{"Name": "port"}).
+		Expect().Status(http.StatusBadRequest).Header("Cache-Control").Equal("no-store")
+}
+
+func TestPatchPortEmptyBody(t *testing.T) {
+	e := mockPortApp(t)
+	capmodel.SavePort(testPortURI, &model.Port{ODataID: testPortURI, ID: testPortID, PortID: "eth1/1"})
+
+	for _, body := range []string{"", " \n\t "} {
+		resp := e.PATCH(testPortURI).WithHeader("Content-Type", "application/json").WithBytes([]byte(body)).
+			Expect().Status(http.StatusBadRequest).Body()
+		resp.Contains("at least one writable property is required")
+		resp.NotContains("MalformedJSON")
+	}
+	resp := e.PATCH(testPortURI).WithHeader("Content-Type", "application/json").WithBytes([]byte(`{"Links":`)).
+		Expect().Status(http.StatusBadRequest).Body()
+	resp.Contains("MalformedJSON")
+	resp.NotContains("at least one writable property is required")
+}
+
+func TestPatchPortInvalidatesPortCache(t *testing.T) {
+	e := mockPortApp(t)
+	config.Data.APICConf.DisableLiveEnrichment = true
+	config.Data.WritablePortProperties = []string{"Links", "Description"}
+	defer func() { config.Data.WritablePortProperties = nil }()
+	capmodel.SaveFabric(testFabricID, &capdata.Fabric{PodID: "1", SwitchData: []string{testSwitchID}})
+	capmodel.SavePort(testPortURI, &model.Port{ODataID: testPortURI, ID: testPortID, PortID: "eth1/1", Description: "old"})
+
+	// the port is cached by the first GET
+	e.GET(testPortURI).Expect().Status(http.StatusOK).JSON().Object().Value("Description").Equal("old")
+	e.PATCH(testPortURI).WithJSON(map[string]interface{}{"Description": "new"}).Expect().Status(http.StatusOK)
+	e.GET(testPortURI).Expect().Status(http.StatusOK).JSON().Object().Value("Description").Equal("new")
+}
+
+func TestPatchPortPreferReturn(t *testing.T) {
+	e := mockPortApp(t)
+	capmodel.SavePort(testPortURI, &model.Port{ODataID: testPortURI, ID: testPortID, PortID: "eth1/1", Description: "old"})
+	config.Data.WritablePortProperties = []string{"Links", "Description"}
+	defer func() { config.Data.WritablePortProperties = nil }()
+
+	resp := e.PATCH(testPortURI).WithHeader("Prefer", "return=minimal").WithJSON(map[string]interface{}{"Description": "minimal"}).
+		Expect().Status(http.StatusNoContent)
+	resp.Header("Preference-Applied").Equal("return=minimal")
+	resp.Body().Empty()
+	port, err := capmodel.GetPort(testPortURI)
+	if err != nil || port.Description != "minimal" {
+		t.Errorf("stored port Description = %v, %v, want the port updated with minimal return", port, err)
+	}
+
+	resp = e.PATCH(testPortURI).WithHeader("Prefer", "respond-async, return=representation").WithJSON(map[string]interface{}{"Description": "representation"}).
+		Expect().Status(http.StatusOK)
+	resp.Header("Preference-Applied").Equal("return=representation")
+	resp.JSON().Object().Value("Description").Equal("representation")
+
+	// the representation is returned by default, without preference applied
+	resp = e.PATCH(testPortURI).WithJSON(map[string]interface{}{"Description": "default"}).Expect().Status(http.StatusOK)
+	resp.Header("Preference-Applied").Empty()
+	resp.JSON().Object().Value("Description").Equal("default")
+	resp = e.PATCH(testPortURI).WithHeader("Prefer", "return=headers-only").WithJSON(map[string]interface{}{"Description": "unknown"}).
+		Expect().Status(http.StatusOK)
+	resp.Header("Preference-Applied").Empty()
+	resp.JSON().Object().Value("Description").Equal("unknown")
+}
+
+func TestPatchPortAudit(t *testing.T) {
+	mockPortApp(t)
+	capmodel.SavePort(testPortURI, &model.Port{ODataID: testPortURI, ID: testPortID, PortID: "eth1/1", Description: "old"})
+	config.Data.WritablePortProperties = []string{"Links", "Description"}
+	auditFile := filepath.Join(t.TempDir(), "audit.log")
+	config.Data.AuditConf = &config.AuditConf{Sink: config.AuditSinkFile, FilePath: auditFile}
+	hash := sha3.New512()
+	hash.Write([]byte("Audit@123"))
+	config.Data.PluginConf.Password = base64.URLEncoding.EncodeToString(hash.Sum(nil))
+	capmiddleware.TokenValidator = TokenValidation
+	defer func() {
+		config.Data.WritablePortProperties = nil
+		config.Data.AuditConf = nil
+		capmiddleware.TokenValidator = nil
+	}()
+	now := time.Now().UTC()
+	session := &capmodel.Session{
+		ID:          "sessionID",
+		UserName:    "operator",
+		TokenHash:   capmodel.SessionTokenHash("audit-token"),
+		CreatedTime: now,
+		LastUsed:    now,
+	}
+	if err := capmodel.SaveSession(session, time.Hour); err != nil {
+		t.Fatalf("failed to save the session: %v", err)
+	}
+	mockApp := iris.New()
+	fabricRoutes := mockApp.Party("/ODIM/v1/Fabrics", capmiddleware.Audit, capmiddleware.BasicAuth)
+	fabricRoutes.Get("/{id}/Switches/{switchID}/Ports/{portID}", GetPortInfo)
+	fabricRoutes.Patch("/{id}/Switches/{switchID}/Ports/{portID}", PatchPort)
+	e := httptest.New(t, mockApp)
+
+	e.PATCH(testPortURI).WithBasicAuth("admin", "Audit@123").WithJSON(map[string]interface{}{"Description": "new"}).
+		Expect().Status(http.StatusOK)
+	e.PATCH(testPortURI).WithBasicAuth("admin", "Audit@123").WithJSON(map[string]interface{}{"Name": "port"}).
+		Expect().Status(http.StatusBadRequest)
+	// the requests of a session are audited with the user name of the session
+	e.PATCH(testPortURI).WithHeader("X-Auth-Token", "audit-token").WithJSON(map[string]interface{}{"Description": "session"}).
+		Expect().Status(http.StatusOK)
+	// reads are not audited
+	e.GET(testPortURI).WithBasicAuth("admin", "Audit@123").Expect()
+
+	file, err := os.Open(auditFile)
+	if err != nil {
+		t.Fatalf("failed to open audit file: %v", err)
+	}
+	defer file.Close()
+	var records []capmiddleware.AuditRecord
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var record capmiddleware.AuditRecord
+		if err := json.Unmarshal(scanner.Bytes(), &record); err != nil {
+			t.Fatalf("audit record %s is not JSON: %v", scanner.Text(), err)
+		}
+		if _, err := time.Parse(time.RFC3339Nano, record.Timestamp); err != nil {
+			t.Errorf("audit record timestamp %s is not RFC3339: %v", record.Timestamp, err)
+		}
+		record.Timestamp = ""
+		records = append(records, record)
+	}
+	want := []capmiddleware.AuditRecord{
+		{Principal: "admin", Method: http.MethodPatch, Resource: testPortURI, StatusCode: http.StatusOK, Outcome: "Success"},
+		{Principal: "admin", Method: http.MethodPatch, Resource: testPortURI, StatusCode: http.StatusBadRequest, Outcome: "Failure"},
+		{Principal: "operator", Method: http.MethodPatch, Resource: testPortURI, StatusCode: http.StatusOK, Outcome: "Success"},
+	}
+	if fmt.Sprint(records) != fmt.Sprint(want) {
+		t.Errorf("audit records = %+v, want %+v", records, want)
+	}
+}
+
+func TestPatchPortIdempotencyKey(t *testing.T) {
+	e := mockPortApp(t)
+	capmodel.SavePort(testPortURI, &model.Port{ODataID: testPortURI, ID: testPortID, PortID: "eth1/1", Description: "old"})
+	config.Data.WritablePortProperties = []string{"Links", "Description"}
+	defer func() { config.Data.WritablePortProperties = nil }()
+
+	patch := map[string]interface{}{"Description": "new"}
+	e.PATCH(testPortURI).WithHeader(idempotencyKeyHeader, "key-1").WithJSON(patch).
+		Expect().Status(http.StatusOK).JSON().Object().Value("Description").Equal("new")
+	e.PATCH(testPortURI).WithJSON(map[string]interface{}{"Description": "changed"}).Expect().Status(http.StatusOK)
+
+	// the retry is answered with the stored result without applying the request again
+	resp := e.PATCH(testPortURI).WithHeader(idempotencyKeyHeader, "key-1").WithJSON(patch).Expect().Status(http.StatusOK)
+	resp.Header(idempotentReplayedHeader).Equal("true")
+	resp.JSON().Object().Value("Description").Equal("new")
+	if port, _ := capmodel.GetPort(testPortURI); port.Description != "changed" {
+		t.Errorf("stored port Description = %s, want the retry not applied", port.Description)
+	}
+
+	// the key can't be reused for a different request
+	e.PATCH(testPortURI).WithHeader(idempotencyKeyHeader, "key-1").WithJSON(map[string]interface{}{"Description": "other"}).
+		Expect().Status(http.StatusUnprocessableEntity)
+	if port, _ := capmodel.GetPort(testPortURI); port.Description != "changed" {
+		t.Errorf("stored port Description = %s, want the request with reused key not applied", port.Description)
+	}
+
+	// the key reserved by the same request still being applied is answered with 409
+	body, _ := json.Marshal(patch)
+	hash := sha256.Sum256([]byte(http.MethodPatch + " " + testPortURI + "\n" + string(body)))
+	capmodel.ReserveIdempotencyKey("key-2", hex.EncodeToString(hash[:]), time.Minute)
+	e.PATCH(testPortURI).WithHeader(idempotencyKeyHeader, "key-2").WithJSON(patch).
+		Expect().Status(http.StatusConflict).Header("Retry-After").Equal("1")
+
+	// the key of a failed request is released for the request to be retried
+	notWritable := map[string]interface{}{"PortId": "eth1/2"}
+	e.PATCH(testPortURI).WithHeader(idempotencyKeyHeader, "key-3").WithJSON(notWritable).Expect().Status(http.StatusBadRequest)
+	e.PATCH(testPortURI).WithHeader(idempotencyKeyHeader, "key-3").WithJSON(notWritable).Expect().Status(http.StatusBadRequest)
+}
+
+func TestDeletePortConnectedPorts(t *testing.T) {
+	e := mockPortApp(t)
+	capmodel.SavePort(testPortURI, &model.Port{
+		ODataID: testPortURI,
+		ID:      testPortID,
+		Links:   &model.PortLinks{ConnectedPorts: []model.Link{{Oid: testEthernetID}}},
+	})
+
+	// clearing a connected port
+	e.DELETE(testPortURI + "/Links/ConnectedPorts").Expect().Status(http.StatusOK).
+		JSON().Object().Value("Id").Equal(testPortID)
+	port, err := capmodel.GetPort(testPortURI)
+	if err != nil {
+		t.Fatalf("GetPort() error = %v", err)
+	}
+	if port.Links != nil && len(port.Links.ConnectedPorts) != 0 {
+		t.Errorf("connected ports = %v, want none", port.Links.ConnectedPorts)
+	}
+
+	// clearing again is a no-op
+	e.DELETE(testPortURI + "/Links/ConnectedPorts").Expect().Status(http.StatusOK)
+
+	e.DELETE(testPortsURI + "/portUUID:eth1-2/Links/ConnectedPorts").Expect().Status(http.StatusNotFound)
+}
+
+// countingConnector counts the DB reads
+type countingConnector struct {
+	db.MockMemoryConnector
+	gets *int64
+}
+
+func (c countingConnector) Get(table, resourceID string) (string, error) {
+	atomic.AddInt64(c.gets, 1)
+	return c.MockMemoryConnector.Get(table, resourceID)
+}
+
+func mockCountingPortApp(t testing.TB) (*iris.Application, *int64) {
+	config.SetUpMockConfig(t)
+	config.Data.APICConf.DisableLiveEnrichment = true
+	var gets int64
+	db.Connector = countingConnector{MockMemoryConnector: db.NewMockMemoryConnector(), gets: &gets}
+	capmodel.InvalidatePortCache()
+	capmodel.InvalidateFabricCache()
+	capmodel.SaveFabric(testFabricID, &capdata.Fabric{PodID: "1", SwitchData: []string{testSwitchID}})
+	capmodel.SaveSwitch(testSwitchID, &model.Switch{ID: testSwitchID})
+	capmodel.SavePort(testPortURI, &model.Port{ODataID: testPortURI, ID: testPortID, PortID: "eth1/1"})
+	mockApp := iris.New()
+	mockApp.Get("/ODIM/v1/Fabrics/{id}/Switches/{switchID}/Ports/{portID}", GetPortInfo)
+	if err := mockApp.Build(); err != nil {
+		t.Fatal(err)
+	}
+	return mockApp, &gets
+}
+
+func getPortInfoStatus(app *iris.Application) int {
+	recorder := nethttptest.NewRecorder()
+	app.ServeHTTP(recorder, nethttptest.NewRequest(http.MethodGet, testPortURI, nil))
+	return recorder.Code
+}
+
+func TestGetPortInfoFabricCache(t *testing.T) {
+	app, gets := mockCountingPortApp(t)
+	if status := getPortInfoStatus(app); status != http.StatusOK || *gets != 2 {
+		t.Errorf("status %d with %d DB reads with the fabric cache cold, want 200 with 2", status, *gets)
+	}
+	// the port is read from the DB again
+	capmodel.InvalidatePortCache()
+	atomic.StoreInt64(gets, 0)
+	if status := getPortInfoStatus(app); status != http.StatusOK || *gets != 1 {
+		t.Errorf("status %d with %d DB reads with the fabric cache warm, want 200 with 1", status, *gets)
+	}
+	atomic.StoreInt64(gets, 0)
+	if status := getPortInfoStatus(app); status != http.StatusOK || *gets != 0 {
+		t.Errorf("status %d with %d DB reads with the fabric and port caches warm, want 200 with 0", status, *gets)
+	}
+}
+
+func BenchmarkGetPortInfo(b *testing.B) {
+	for _, warm := range []bool{false, true} {
+		name := "FabricCacheCold"
+		if warm {
+			name = "FabricCacheWarm"
+		}
+		b.Run(name, func(b *testing.B) {
+			app, gets := mockCountingPortApp(b)
+			atomic.StoreInt64(gets, 0)
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				if !warm {
+					capmodel.InvalidateFabricCache()
+					capmodel.InvalidatePortCache()
+				}
+				getPortInfoStatus(app)
+			}
+			b.ReportMetric(float64(atomic.LoadInt64(gets))/float64(b.N), "dbreads/op")
+		})
+	}
+}
+
+func TestPortErrorResource(t *testing.T) {
+	e := mockPortApp(t)
+	capmodel.SaveFabric(testFabricID, &capdata.Fabric{PodID: "1", SwitchData: []string{testSwitchID}})
+
+	// not found port, fabric and switch
+	resource := e.GET(testPortsURI + "/portUUID:eth1-2").Expect().Status(http.StatusNotFound).
+		JSON().Path("$.error['@Message.ExtendedInfo'][0].Oem.CiscoACI").Object()
+	resource.Value("@odata.type").Equal(portODataType)
+	resource.Value("@odata.id").Equal(testPortsURI + "/portUUID:eth1-2")
+	e.GET("/ODIM/v1/Fabrics/unknown/Switches/" + testSwitchID + "/Ports/" + testPortID).Expect().Status(http.StatusNotFound).
+		JSON().Path("$.error['@Message.ExtendedInfo'][0].Oem.CiscoACI['@odata.type']").Equal(fabricODataType)
+	e.GET("/ODIM/v1/Fabrics/fabricID/Switches/unknown:102/Ports").WithQuery("$count", "true").Expect().Status(http.StatusNotFound).
+		JSON().Path("$.error['@Message.ExtendedInfo'][0].Oem.CiscoACI['@odata.id']").Equal("/ODIM/v1/Fabrics/fabricID/Switches/unknown:102")
+
+	// conflict and internal error
+	port := resourceRef{portODataType, testPortURI}
+	for _, err := range []error{db.ErrorKeyAlreadyExist, errors.New("unexpected")} {
+		_, resp := createResourceDbErrResp(nil, err, err.Error(), []interface{}{"Ports", testPortURI}, port)
+		messages := resp.(map[string]interface{})["error"].(map[string]interface{})["@Message.ExtendedInfo"].([]interface{})
+		oem := messages[0].(map[string]interface{})["Oem"].(map[string]interface{})["CiscoACI"].(map[string]string)
+		if oem["@odata.type"] != portODataType || oem["@odata.id"] != testPortURI {
+			t.Errorf("error response for %v reports resource %v, want the port", err, oem)
+		}
+	}
+}
+
+func TestPortsOfMissingSwitch(t *testing.T) {
+	e := mockPortApp(t)
+	capmodel.SaveFabric(testFabricID, &capdata.Fabric{PodID: "1", SwitchData: []string{testSwitchID}})
+	missingSwitchURI := "/ODIM/v1/Fabrics/" + testFabricID + "/Switches/switchUUID:102"
+	capmodel.SaveSwitchPort("switchUUID:102", []string{testPortID})
+	capmodel.SavePort(missingSwitchURI+"/Ports/"+testPortID, &model.Port{ID: testPortID, PortID: "eth1/1"})
+
+	for _, uri := range []string{missingSwitchURI + "/Ports", missingSwitchURI + "/Ports/" + testPortID} {
+		e.GET(uri).Expect().Status(http.StatusNotFound).
+			JSON().Path("$.error['@Message.ExtendedInfo'][0].Oem.CiscoACI['@odata.id']").Equal(missingSwitchURI)
+	}
+	e.GET(testPortsURI).Expect().Status(http.StatusOK)
+}
+
+func TestPatchPortConnectedPortShapes(t *testing.T) {
+	e := mockPortApp(t)
+	checkEthernetInODIM = func(traceParent, reqURL string) (bool, error) {
+		return true, nil
+	}
+	defer func() { checkEthernetInODIM = checkValidityOfEthernet }()
+
+	for _, link := range []interface{}{
+		map[string]string{"@odata.id": testEthernetID},
+		map[string]string{"Oid": testEthernetID},
+		testEthernetID,
+	} {
+		capmodel.SavePort(testPortURI, &model.Port{ODataID: testPortURI, ID: testPortID})
+		e.PATCH(testPortURI).WithJSON(map[string]interface{}{"Links": map[string]interface{}{"ConnectedPorts": []interface{}{link}}}).
+			Expect().Status(http.StatusOK)
+		port, err := capmodel.GetPort(testPortURI)
+		if err != nil {
+			t.Fatalf("GetPort() error = %v", err)
+		}
+		if port.Links == nil || len(port.Links.ConnectedPorts) != 1 || port.Links.ConnectedPorts[0].Oid != testEthernetID {
+			t.Errorf("PATCH with connected port %v stored links %+v, want %s", link, port.Links, testEthernetID)
+		}
+	}
+
+	e.PATCH(testPortURI).WithJSON(map[string]interface{}{"Links": map[string]interface{}{"ConnectedPorts": []interface{}{map[string]string{"href": testEthernetID}}}}).
+		Expect().Status(http.StatusBadRequest)
+}
+
+func TestPatchPortMergePatch(t *testing.T) {
+	e := mockPortApp(t)
+	checkEthernetInODIM = func(traceParent, reqURL string) (bool, error) {
+		return true, nil
+	}
+	defer func() { checkEthernetInODIM = checkValidityOfEthernet }()
+	otherEthernetID := "/ODIM/v1/Systems/sysUUID.1/EthernetInterfaces/2"
+	capmodel.SavePort(testPortURI, &model.Port{
+		ODataID: testPortURI,
+		ID:      testPortID,
+		Links:   &model.PortLinks{ConnectedPorts: []model.Link{{Oid: testEthernetID}}},
+	})
+
+	tests := []struct {
+		name  string
+		patch map[string]interface{}
+		want  []string
+	}{
+		{"absent keeps", map[string]interface{}{"Links": map[string]interface{}{}}, []string{testEthernetID}},
+		{"value replaces", map[string]interface{}{"Links": map[string]interface{}{"ConnectedPorts": []interface{}{otherEthernetID}}}, []string{otherEthernetID}},
+		{"null clears", map[string]interface{}{"Links": map[string]interface{}{"ConnectedPorts": nil}}, nil},
+	}
+	for _, tt := range tests {
+		e.PATCH(testPortURI).WithJSON(tt.patch).Expect().Status(http.StatusOK)
+		port, err := capmodel.GetPort(testPortURI)
+		if err != nil {
+			t.Fatalf("GetPort() error = %v", err)
+		}
+		var got []string
+		if port.Links != nil {
+			for _, link := range port.Links.ConnectedPorts {
+				got = append(got, link.Oid)
+			}
+		}
+		if fmt.Sprint(got) != fmt.Sprint(tt.want) {
+			t.Errorf("%s: stored connected ports %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestGetPortInfoAPICThrottled(t *testing.T) {
+	tests := []struct {
+		name       string
+		err        error
+		retryAfter string
+		message    string
+	}{
+		{
+			name:       "plugin rate limit",
+			err:        &caputilities.APICThrottleError{Err: caputilities.ErrAPICRateLimited, Endpoint: "phys.json", RetryAfter: 1500 * time.Millisecond},
+			retryAfter: "2",
+			message:    "the plugin is limiting the rate of the APIC requests",
+		},
+		{
+			name:       "APIC throttling",
+			err:        &caputilities.APICThrottleError{Err: caputilities.ErrAPICThrottled, Endpoint: "phys.json", RetryAfter: 30 * time.Second},
+			retryAfter: "30",
+			message:    "APIC is throttling the requests of the plugin",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := mockPortApp(t)
+			capmodel.SaveFabric(testFabricID, &capdata.Fabric{PodID: "1", SwitchData: []string{testSwitchID}})
+			capmodel.SavePort(testPortURI, &model.Port{ODataID: testPortURI, ID: testPortID, PortID: "eth1/1"})
+			getPortInfo = func(traceParent, podID, ACISwitchID, portID string) (*capmodel.PortInfoResponse, error) {
+				return nil, tt.err
+			}
+			defer func() { getPortInfo = caputilities.GetPortInfo }()
+
+			resp := e.GET(testPortURI).Expect().Status(http.StatusTooManyRequests)
+			resp.Header("Retry-After").Equal(tt.retryAfter)
+			resp.Body().Contains(tt.message).Contains(testPortURI)
+		})
+	}
+}
+
+func TestCreateAPICErrResp(t *testing.T) {
+	config.SetUpMockConfig(t)
+	tests := []struct {
+		err  error
+		want int
+	}{
+		{&caputilities.APICError{StatusCode: http.StatusForbidden, Code: "403", Text: "Token was invalid (Error: Token timeout)"}, http.StatusServiceUnavailable},
+		{&caputilities.APICError{Method: http.MethodPost, StatusCode: http.StatusBadRequest, Code: "122", Text: "user odim does not have domain access to config Mo"}, http.StatusForbidden},
+		{&caputilities.APICError{Method: http.MethodGet, StatusCode: http.StatusBadRequest, Code: "122", Text: "user odim does not have domain access to config Mo"}, http.StatusBadRequest},
+		{&caputilities.APICError{StatusCode: http.StatusBadRequest, Code: "102", Text: "configured object ((Dn0)) not found"}, http.StatusNotFound},
+		{fmt.Errorf("while trying to get port: %w", &caputilities.APICError{Code: "102"}), http.StatusNotFound},
+		{&caputilities.APICError{StatusCode: http.StatusBadRequest, Code: "400", Text: "Request failed, unresolved class"}, http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		if statusCode, _ := createAPICErrResp(nil, tt.err, tt.err.Error(), nil); statusCode != tt.want {
+			t.Errorf("createAPICErrResp(%v) status = %d, want %d", tt.err, statusCode, tt.want)
+		}
+	}
+}
+
+func TestGetPortInfoUnknownHealthPolicy(t *testing.T) {
+	tests := []struct {
+		policy     string
+		wantHealth string
+	}{
+		{config.UnknownHealthOK, "OK"},
+		{config.UnknownHealthWarning, "Warning"},
+		{config.UnknownHealthIgnore, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.policy, func(t *testing.T) {
+			e := mockPortApp(t)
+			config.Data.APICConf.UnknownHealthPolicy = tt.policy
+			capmodel.SaveFabric(testFabricID, &capdata.Fabric{PodID: "1", SwitchData: []string{testSwitchID}})
+			capmodel.SavePort(testPortURI, &model.Port{ODataID: testPortURI, ID: testPortID, PortID: "eth1/1"})
+			getPortInfo = func(traceParent, podID, ACISwitchID, portID string) (*capmodel.PortInfoResponse, error) {
+				return &capmodel.PortInfoResponse{IMData: []capmodel.PortInfoIMData{{
+					PhysicalInterface: capmodel.PhysicalInterface{Attributes: map[string]interface{}{"operSt": "down"}},
+				}}}, nil
+			}
+			// admin-down port without current health score
+			getPortHealth = func(traceParent, podID, ACISwitchID, portID string) (*capmodel.Health, error) {
+				return &capmodel.Health{IMData: []capmodel.HealthIMData{{
+					HealthData: capmodel.HealthData{Attributes: map[string]interface{}{"maxSev": "cleared"}},
+				}}}, nil
+			}
+			defer func() {
+				getPortInfo = caputilities.GetPortInfo
+				getPortHealth = caputilities.GetPortHealth
+			}()
+
+			port := e.GET(testPortURI).Expect().Status(http.StatusOK).JSON().Object()
+			port.Value("LinkStatus").Equal("LinkDown")
+			if tt.wantHealth == "" {
+				port.NotContainsKey("Status")
+				return
+			}
+			port.Path("$.Status.Health").Equal(tt.wantHealth)
+			port.Path("$.Status.State").Equal("Disabled")
+		})
+	}
+}
+
+func TestGetPortInfoUnavailableHealth(t *testing.T) {
+	tests := []struct {
+		policy     string
+		wantHealth string
+	}{
+		{config.UnknownHealthWarning, "Warning"},
+		{config.UnknownHealthOK, "OK"},
+		{config.UnknownHealthIgnore, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.policy, func(t *testing.T) {
+			e := mockPortApp(t)
+			config.Data.APICConf.UnavailableHealthPolicy = tt.policy
+			capmodel.SaveFabric(testFabricID, &capdata.Fabric{PodID: "1", SwitchData: []string{testSwitchID}})
+			capmodel.SavePort(testPortURI, &model.Port{ODataID: testPortURI, ID: testPortID, PortID: "eth1/1"})
+			getPortInfo = func(traceParent, podID, ACISwitchID, portID string) (*capmodel.PortInfoResponse, error) {
+				return &capmodel.PortInfoResponse{IMData: []capmodel.PortInfoIMData{{
+					PhysicalInterface: capmodel.PhysicalInterface{Attributes: map[string]interface{}{"operSt": "up"}},
+				}}}, nil
+			}
+			// transient APIC failure
+			getPortHealth = func(traceParent, podID, ACISwitchID, portID string) (*capmodel.Health, error) {
+				return nil, errors.New("connection reset by peer")
+			}
+			defer func() {
+				getPortInfo = caputilities.GetPortInfo
+				getPortHealth = caputilities.GetPortHealth
+			}()
+
+			port := e.GET(testPortURI).Expect().Status(http.StatusOK).JSON().Object()
+			port.Value("LinkStatus").Equal("LinkUp")
+			if tt.wantHealth == "" {
+				port.NotContainsKey("Status")
+				port.Path("$.Oem.CiscoACI").Object().NotContainsKey("Conditions")
+				return
+			}
+			port.Path("$.Status.Health").Equal(tt.wantHealth)
+			port.Path("$.Status.State").Equal("Enabled")
+			condition := port.Path("$.Oem.CiscoACI.Conditions").Array().Element(0).Object()
+			condition.Value("Severity").Equal(tt.wantHealth)
+			condition.Value("Message").String().Contains("could not be read from APIC")
+			// the fallback health is not stored as the state of the port
+			if _, err := capmodel.GetPortState(testPortURI); !errors.Is(err, db.ErrorKeyNotFound) {
+				t.Errorf("GetPortState() error = %v, want ErrorKeyNotFound", err)
+			}
+		})
+	}
+}
+
+func TestGetPortCollectionStreamed(t *testing.T) {
+	e := mockPortApp(t)
+	const portCount = 20000
+	ports := make([]string, portCount)
+	for i := range ports {
+		ports[i] = fmt.Sprintf("portUUID%d:eth1-%d", i, i)
+	}
+	db.Connector = db.NewMockMemoryConnector()
+	capmodel.InvalidatePortCache()
+	capmodel.SaveSwitchPort(testSwitchID, ports)
+
+	resp := e.GET(testPortsURI).Expect().Status(http.StatusOK).ContentType("application/json")
+	if body := resp.Body().Raw(); !json.Valid([]byte(body)) {
+		t.Fatalf("streamed port collection is not valid JSON: %.200s", body)
+	}
+	collection := resp.JSON().Object()
+	collection.Value("Members@odata.count").Number().Equal(portCount)
+	members := collection.Value("Members").Array()
+	members.Length().Equal(portCount)
+	members.Element(portCount - 1).Object().Value("@odata.id").Equal(testPortsURI + "/" + ports[portCount-1])
+
+	// a page of the collection is streamed the same way
+	page := e.GET(testPortsURI).WithQuery("$top", 2).WithQuery("$skip", 1).Expect().Status(http.StatusOK).JSON().Object()
+	page.Value("Members").Array().Length().Equal(2)
+	page.Value("Members@odata.nextLink").String().Contains("$skip=3")
+}
+
+func TestPortOperState(t *testing.T) {
+	config.SetUpMockConfig(t)
+	tests := []struct {
+		operState  string
+		qualifier  string
+		linkState  string
+		linkStatus string
+		state      string
+	}{
+		{"up", "none", "Enabled", "LinkUp", "Enabled"},
+		{"link-up", "", "Enabled", "Starting", "Starting"},
+		{"down", "", "Disabled", "LinkDown", "Disabled"},
+		{"down", "link-failure", "Disabled", "LinkDown", "Disabled"},
+		{"down", "admin-down", "Disabled", "LinkDown", "Disabled"},
+		{"down", "err-disabled", "Enabled", "LinkDown", "UnavailableOffline"},
+		{"admin-down", "", "Disabled", "LinkDown", "Disabled"},
+		{"err-disabled", "", "Enabled", "LinkDown", "UnavailableOffline"},
+		{"unknown", "", "Disabled", "NoLink", "Disabled"},
+		{"testing", "", "Disabled", "LinkDown", "Disabled"},
+	}
+	for _, tt := range tests {
+		got := portOperState(tt.operState, tt.qualifier)
+		if got.LinkState != tt.linkState || got.LinkStatus != tt.linkStatus || got.State != tt.state {
+			t.Errorf("portOperState(%s, %s) = %+v, want %s, %s, %s", tt.operState, tt.qualifier, got, tt.linkState, tt.linkStatus, tt.state)
+		}
+	}
+
+	config.Data.APICConf.PortOperStates = map[string]config.PortOperState{
+		"link-up": {LinkState: "Enabled", LinkStatus: "Training", State: "InTest"},
+	}
+	defer func() { config.Data.APICConf.PortOperStates = nil }()
+	if got := portOperState("link-up", ""); got.LinkStatus != "Training" || got.State != "InTest" {
+		t.Errorf("portOperState(link-up) = %+v, want the configured state", got)
+	}
+}
+
+func TestGetPortInfoErrDisabled(t *testing.T) {
+	e := mockPortApp(t)
+	capmodel.SaveFabric(testFabricID, &capdata.Fabric{PodID: "1", SwitchData: []string{testSwitchID}})
+	capmodel.SavePort(testPortURI, &model.Port{ODataID: testPortURI, ID: testPortID, PortID: "eth1/1"})
+	getPortInfo = func(traceParent, podID, ACISwitchID, portID string) (*capmodel.PortInfoResponse, error) {
+		return &capmodel.PortInfoResponse{IMData: []capmodel.PortInfoIMData{{
+			PhysicalInterface: capmodel.PhysicalInterface{Attributes: map[string]interface{}{"operSt": "down", "operStQual": "err-disabled"}},
+		}}}, nil
+	}
+	getPortHealth = func(traceParent, podID, ACISwitchID, portID string) (*capmodel.Health, error) {
+		return &capmodel.Health{IMData: []capmodel.HealthIMData{{
+			HealthData: capmodel.HealthData{Attributes: map[string]interface{}{"cur": "0"}},
+		}}}, nil
+	}
+	defer func() {
+		getPortInfo = caputilities.GetPortInfo
+		getPortHealth = caputilities.GetPortHealth
+	}()
+
+	port := e.GET(testPortURI).Expect().Status(http.StatusOK).JSON().Object()
+	port.Value("LinkState").Equal("Enabled")
+	port.Value("LinkStatus").Equal("LinkDown")
+	port.Path("$.Status.State").Equal("UnavailableOffline")
+}
+
+func TestGetPortInfoTransceiver(t *testing.T) {
+	e := mockPortApp(t)
+	capmodel.SaveFabric(testFabricID, &capdata.Fabric{PodID: "1", SwitchData: []string{testSwitchID}})
+	capmodel.SavePort(testPortURI, &model.Port{ODataID: testPortURI, ID: testPortID, PortID: "eth1/1"})
+	getPortInfo = func(traceParent, podID, ACISwitchID, portID string) (*capmodel.PortInfoResponse, error) {
+		return &capmodel.PortInfoResponse{IMData: []capmodel.PortInfoIMData{{
+			PhysicalInterface: capmodel.PhysicalInterface{Attributes: map[string]interface{}{"operSt": "up"}},
+		}}}, nil
+	}
+	getPortHealth = func(traceParent, podID, ACISwitchID, portID string) (*capmodel.Health, error) {
+		return &capmodel.Health{IMData: []capmodel.HealthIMData{{
+			HealthData: capmodel.HealthData{Attributes: map[string]interface{}{"cur": "100"}},
+		}}}, nil
+	}
+	defer func() {
+		getPortInfo = caputilities.GetPortInfo
+		getPortHealth = caputilities.GetPortHealth
+	}()
+
+	// the transceiver slot is empty
+	e.GET(testPortURI).Expect().Status(http.StatusOK).JSON().Path("$.Oem.CiscoACI").Object().NotContainsKey("Transceiver")
+
+	getPortTransceiver = func(traceParent, podID, ACISwitchID, portID string) (*capmodel.PortTransceiver, error) {
+		if podID != "1" || ACISwitchID != "101" || portID != "eth1/1" {
+			t.Errorf("GetPortTransceiver(%s, %s, %s), want the transceiver of eth1/1 of node 101 of pod 1", podID, ACISwitchID, portID)
+		}
+		return &capmodel.PortTransceiver{Vendor: "CISCO-FINISAR", PartNumber: "FTLX8574D3BCL-C2", SerialNumber: "FNS17251ABC", Type: "10Gbase-SR", WavelengthNanometers: 850}, nil
+	}
+	transceiver := e.GET(testPortURI).Expect().Status(http.StatusOK).JSON().Path("$.Oem.CiscoACI.Transceiver").Object()
+	transceiver.Value("Vendor").Equal("CISCO-FINISAR")
+	transceiver.Value("PartNumber").Equal("FTLX8574D3BCL-C2")
+	transceiver.Value("SerialNumber").Equal("FNS17251ABC")
+	transceiver.Value("Type").Equal("10Gbase-SR")
+	transceiver.Value("WavelengthNanometers").Equal(850)
+
+	// the transceiver is omitted when it can't be read
+	getPortTransceiver = func(traceParent, podID, ACISwitchID, portID string) (*capmodel.PortTransceiver, error) {
+		return nil, caputilities.ErrAPICResponseMalformed
+	}
+	e.GET(testPortURI).Expect().Status(http.StatusOK).JSON().Path("$.Oem.CiscoACI").Object().NotContainsKey("Transceiver")
+}
+
+func TestGetPortInfoStableJSON(t *testing.T) {
+	e := mockPortApp(t)
+	capmodel.SaveFabric(testFabricID, &capdata.Fabric{PodID: "1", SwitchData: []string{testSwitchID}})
+	capmodel.SavePort(testPortURI, &model.Port{ODataID: testPortURI, ID: testPortID, PortID: "eth1/1"})
+	getPortInfo = func(traceParent, podID, ACISwitchID, portID string) (*capmodel.PortInfoResponse, error) {
+		return &capmodel.PortInfoResponse{IMData: []capmodel.PortInfoIMData{{
+			PhysicalInterface: capmodel.PhysicalInterface{Attributes: map[string]interface{}{"operSt": "up", "operSpeed": "10G"}},
+		}}}, nil
+	}
+	getPortHealth = func(traceParent, podID, ACISwitchID, portID string) (*capmodel.Health, error) {
+		return &capmodel.Health{IMData: []capmodel.HealthIMData{{
+			HealthData: capmodel.HealthData{Attributes: map[string]interface{}{"cur": "100"}},
+		}}}, nil
+	}
+	getPortTransceiver = func(traceParent, podID, ACISwitchID, portID string) (*capmodel.PortTransceiver, error) {
+		return &capmodel.PortTransceiver{Vendor: "CISCO-FINISAR", PartNumber: "FTLX8574D3BCL-C2", SerialNumber: "FNS17251ABC", Type: "10Gbase-SR"}, nil
+	}
+	defer func() {
+		getPortInfo = caputilities.GetPortInfo
+		getPortHealth = caputilities.GetPortHealth
+	}()
+
+	// the ETag hashes the encoded port and clients diff the responses, the same port must be encoded byte for byte the same
+	first := e.GET(testPortURI).Expect().Status(http.StatusOK).Body().Raw()
+	for i := 0; i < 10; i++ {
+		if body := e.GET(testPortURI).Expect().Status(http.StatusOK).Body().Raw(); body != first {
+			t.Fatalf("GET %s = %s, want the same body as %s", testPortURI, body, first)
+		}
+	}
+}
+
+func TestGetPortInfoSwitchPortsHealth(t *testing.T) {
+	e := mockPortApp(t)
+	capmodel.SaveFabric(testFabricID, &capdata.Fabric{PodID: "1", SwitchData: []string{testSwitchID}})
+	scores := map[string]string{"portUUID:eth1-1": "100", "portUUID:eth1-2": "50", "portUUID:eth1-3": "10"}
+	portIDs := map[string]string{"portUUID:eth1-1": "eth1/1", "portUUID:eth1-2": "eth1/2", "portUUID:eth1-3": "eth1/3"}
+	health := map[string]capmodel.HealthData{}
+	for id, portID := range portIDs {
+		capmodel.SavePort(testPortsURI+"/"+id, &model.Port{ODataID: testPortsURI + "/" + id, ID: id, PortID: portID})
+		health[portID] = capmodel.HealthData{Attributes: map[string]interface{}{"cur": scores[id]}}
+	}
+	var bulkCalls int64
+	getSwitchPortsHealth = func(traceParent, podID, ACISwitchID string) (map[string]capmodel.HealthData, error) {
+		atomic.AddInt64(&bulkCalls, 1)
+		return health, nil
+	}
+	getPortInfo = func(traceParent, podID, ACISwitchID, portID string) (*capmodel.PortInfoResponse, error) {
+		return &capmodel.PortInfoResponse{IMData: []capmodel.PortInfoIMData{{
+			PhysicalInterface: capmodel.PhysicalInterface{Attributes: map[string]interface{}{"operSt": "up"}},
+		}}}, nil
+	}
+	getPortHealth = func(traceParent, podID, ACISwitchID, portID string) (*capmodel.Health, error) {
+		t.Errorf("GetPortHealth of %s must not be called when the health of the switch ports is read", portID)
+		return nil, errors.New("unexpected call")
+	}
+	defer func() {
+		getPortInfo = caputilities.GetPortInfo
+		getPortHealth = caputilities.GetPortHealth
+	}()
+
+	want := map[string]string{"portUUID:eth1-1": "OK", "portUUID:eth1-2": "Warning", "portUUID:eth1-3": "Critical"}
+	for id, wantHealth := range want {
+		e.GET(testPortsURI + "/" + id).Expect().Status(http.StatusOK).JSON().Object().Path("$.Status.Health").Equal(wantHealth)
+	}
+	if bulkCalls != 1 {
+		t.Errorf("health of the switch ports read %d times, want once", bulkCalls)
+	}
+}
+
+func TestHealthOfScore(t *testing.T) {
+	tests := []struct {
+		score int
+		want  string
+	}{
+		{100, "OK"},
+		{91, "OK"},
+		{90, "Warning"},
+		{30, "Warning"},
+		{29, "Critical"},
+		{0, "Critical"},
+	}
+	for _, tt := range tests {
+		if got := healthOfScore(tt.score); got != tt.want {
+			t.Errorf("healthOfScore(%d) = %s, want %s", tt.score, got, tt.want)
+		}
+	}
+}
+
+func TestDebouncePortState(t *testing.T) {
+	up := capmodel.PortState{LinkState: "Enabled", Health: "OK"}
+	down := capmodel.PortState{LinkState: "Disabled", Health: "Critical"}
+	start := time.Now()
+	grace := 30 * time.Second
+
+	tests := []struct {
+		name     string
+		grace    time.Duration
+		observed []capmodel.PortState
+		offsets  []time.Duration
+		reported []string
+	}{
+		{
+			name:     "flap within the window",
+			grace:    grace,
+			observed: []capmodel.PortState{up, down, down, up},
+			offsets:  []time.Duration{0, 0, 10 * time.Second, 20 * time.Second},
+			reported: []string{"Enabled", "Enabled", "Enabled", "Enabled"},
+		},
+		{
+			name:     "down beyond the window",
+			grace:    grace,
+			observed: []capmodel.PortState{up, down, down, down},
+			offsets:  []time.Duration{0, 0, 10 * time.Second, 30 * time.Second},
+			reported: []string{"Enabled", "Enabled", "Enabled", "Disabled"},
+		},
+		{
+			name:     "no window",
+			observed: []capmodel.PortState{up, down, up},
+			offsets:  []time.Duration{0, 0, 0},
+			reported: []string{"Enabled", "Disabled", "Enabled"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var state capmodel.PortState
+			found := false
+			for i, observed := range tt.observed {
+				state = debouncePortState(state, found, observed, start.Add(tt.offsets[i]), tt.grace)
+				found = true
+				if state.LinkState != tt.reported[i] {
+					t.Errorf("observation %d: reported link state %s, want %s", i, state.LinkState, tt.reported[i])
+				}
+			}
+		})
+	}
+}
+
+func TestGetPortInfoFlapGrace(t *testing.T) {
+	e := mockPortApp(t)
+	config.Data.APICConf.PortFlapGraceInSeconds = 60
+	capmodel.SaveFabric(testFabricID, &capdata.Fabric{PodID: "1", SwitchData: []string{testSwitchID}})
+	capmodel.SavePort(testPortURI, &model.Port{ODataID: testPortURI, ID: testPortID, PortID: "eth1/1"})
+	operState, healthScore := "up", "100"
+	getPortInfo = func(traceParent, podID, ACISwitchID, portID string) (*capmodel.PortInfoResponse, error) {
+		return &capmodel.PortInfoResponse{IMData: []capmodel.PortInfoIMData{{
+			PhysicalInterface: capmodel.PhysicalInterface{Attributes: map[string]interface{}{"operSt": operState}},
+		}}}, nil
+	}
+	getPortHealth = func(traceParent, podID, ACISwitchID, portID string) (*capmodel.Health, error) {
+		return &capmodel.Health{IMData: []capmodel.HealthIMData{{
+			HealthData: capmodel.HealthData{Attributes: map[string]interface{}{"cur": healthScore}},
+		}}}, nil
+	}
+	defer func() {
+		getPortInfo = caputilities.GetPortInfo
+		getPortHealth = caputilities.GetPortHealth
+	}()
+
+	e.GET(testPortURI).Expect().Status(http.StatusOK).JSON().Object().Path("$.Status.Health").Equal("OK")
+	// the port going down is not reported within the grace window
+	operState, healthScore = "down", "50"
+	port := e.GET(testPortURI).Expect().Status(http.StatusOK).JSON().Object()
+	port.Value("LinkStatus").Equal("LinkUp")
+	port.Path("$.Status.Health").Equal("OK")
+	if critical, _ := capmodel.GetPortsByHealth(testPortsURI, []string{"Critical"}); len(critical) != 0 {
+		t.Errorf("GetPortsByHealth() = %v within the grace window, want none", critical)
+	}
+	state, _ := capmodel.GetPortState(testPortURI)
+	if state.Pending == nil || state.Pending.LinkState != "Disabled" || state.PendingSince == nil {
+		t.Errorf("stored state %+v, want the pending down state", state)
+	}
+}
+
+func TestSetPortType(t *testing.T) {
+	tests := []struct {
+		usage string
+		want  string
+	}{
+		{"epg", "BidirectionalPort"},
+		{"infra,epg", "BidirectionalPort"},
+		{"fabric,fabric-ext", "InterswitchPort"},
+		{"fabric-ext", "InterswitchPort"},
+		{"discovery", "UnconfiguredPort"},
+		// the port type stored by the discovery is kept
+		{"", "BidirectionalPort"},
+		{"blacklist", "BidirectionalPort"},
+	}
+	for _, tt := range tests {
+		p := &model.Port{PortType: "BidirectionalPort"}
+		setPortType(p, tt.usage)
+		if string(p.PortType) != tt.want {
+			t.Errorf("setPortType() with usage %q = %s, want %s", tt.usage, p.PortType, tt.want)
+		}
+	}
+}
+
+func TestSetPortMedium(t *testing.T) {
+	tests := []struct {
+		transceiver *capresponse.PortTransceiver
+		want        string
+	}{
+		{&capresponse.PortTransceiver{Type: "10Gbase-SR"}, "Optical"},
+		{&capresponse.PortTransceiver{Type: "QSFP-100G-SR4"}, "Optical"},
+		{&capresponse.PortTransceiver{Type: "QSFP-100G-AOC2M"}, "Optical"},
+		{&capresponse.PortTransceiver{Type: "10Gbase-CU3M"}, "Electrical"},
+		{&capresponse.PortTransceiver{Type: "QSFP-H40G-CU1M"}, "Electrical"},
+		{&capresponse.PortTransceiver{Type: "1000base-T"}, "Electrical"},
+		{&capresponse.PortTransceiver{}, ""},
+		{nil, ""},
+	}
+	for _, tt := range tests {
+		p := &model.Port{}
+		setPortMedium(p, tt.transceiver)
+		if string(p.PortMedium) != tt.want {
+			t.Errorf("setPortMedium() with transceiver %+v = %s, want %s", tt.transceiver, p.PortMedium, tt.want)
+		}
+	}
+}
+
+func TestGetPortInfoPortTypeMedium(t *testing.T) {
+	e := mockPortApp(t)
+	capmodel.SaveFabric(testFabricID, &capdata.Fabric{PodID: "1", SwitchData: []string{testSwitchID}})
+	capmodel.SavePort(testPortURI, &model.Port{ODataID: testPortURI, ID: testPortID, PortID: "eth1/49", PortType: "BidirectionalPort"})
+	getPortInfo = func(traceParent, podID, ACISwitchID, portID string) (*capmodel.PortInfoResponse, error) {
+		return &capmodel.PortInfoResponse{IMData: []capmodel.PortInfoIMData{{
+			PhysicalInterface: capmodel.PhysicalInterface{Attributes: map[string]interface{}{"operSt": "up", "usage": "fabric,fabric-ext"}},
+		}}}, nil
+	}
+	getPortTransceiver = func(traceParent, podID, ACISwitchID, portID string) (*capmodel.PortTransceiver, error) {
+		return &capmodel.PortTransceiver{Type: "QSFP-100G-SR4"}, nil
+	}
+	getPortHealth = func(traceParent, podID, ACISwitchID, portID string) (*capmodel.Health, error) {
+		return &capmodel.Health{IMData: []capmodel.HealthIMData{{
+			HealthData: capmodel.HealthData{Attributes: map[string]interface{}{"cur": "100"}},
+		}}}, nil
+	}
+	defer func() {
+		getPortInfo = caputilities.GetPortInfo
+		getPortHealth = caputilities.GetPortHealth
+	}()
+
+	port := e.GET(testPortURI).Expect().Status(http.StatusOK).JSON().Object()
+	port.Value("PortType").Equal("InterswitchPort")
+	port.Value("PortMedium").Equal("Optical")
+
+	// the medium is left unset when the transceiver slot is empty
+	getPortTransceiver = func(traceParent, podID, ACISwitchID, portID string) (*capmodel.PortTransceiver, error) {
+		return nil, nil
+	}
+	e.GET(testPortURI).Expect().Status(http.StatusOK).JSON().Object().NotContainsKey("PortMedium")
+}
+
+func TestGetPortInfoNeighbors(t *testing.T) {
+	e := mockPortApp(t)
+	capmodel.SaveFabric(testFabricID, &capdata.Fabric{PodID: "1", SwitchData: []string{testSwitchID}})
+	capmodel.SavePort(testPortURI, &model.Port{ODataID: testPortURI, ID: testPortID, PortID: "eth1/1"})
+	getPortInfo = func(traceParent, podID, ACISwitchID, portID string) (*capmodel.PortInfoResponse, error) {
+		return &capmodel.PortInfoResponse{IMData: []capmodel.PortInfoIMData{{
+			PhysicalInterface: capmodel.PhysicalInterface{Attributes: map[string]interface{}{"operSt": "up"}},
+		}}}, nil
+	}
+	getPortHealth = func(traceParent, podID, ACISwitchID, portID string) (*capmodel.Health, error) {
+		return &capmodel.Health{IMData: []capmodel.HealthIMData{{
+			HealthData: capmodel.HealthData{Attributes: map[string]interface{}{"cur": "100"}},
+		}}}, nil
+	}
+	defer func() {
+		getPortInfo = caputilities.GetPortInfo
+		getPortHealth = caputilities.GetPortHealth
+	}()
+
+	// no neighbor discovered
+	e.GET(testPortURI).Expect().Status(http.StatusOK).JSON().Object().
+		Value("Oem").Object().Value("CiscoACI").Object().NotContainsKey("Neighbors")
+
+	getPortNeighbors = func(traceParent, podID, ACISwitchID, portID string) ([]capmodel.PortNeighbor, error) {
+		if podID != "1" || ACISwitchID != "101" || portID != "eth1/1" {
+			t.Errorf("getPortNeighbors(%s, %s, %s), want the port eth1/1 of node 101 in pod 1", podID, ACISwitchID, portID)
+		}
+		return []capmodel.PortNeighbor{{Protocol: "LLDP", ChassisID: "00:50:56:aa:bb:cc", PortID: "ens192", SystemName: "server-1"}}, nil
+	}
+	neighbors := e.GET(testPortURI).Expect().Status(http.StatusOK).JSON().Object().
+		Value("Oem").Object().Value("CiscoACI").Object().Value("Neighbors").Array()
+	neighbors.Length().Equal(1)
+	neighbors.First().Object().Equal(map[string]string{
+		"Protocol": "LLDP", "ChassisId": "00:50:56:aa:bb:cc", "PortId": "ens192", "SystemName": "server-1",
+	})
+
+	// the neighbors are omitted when they can't be read
+	getPortNeighbors = func(traceParent, podID, ACISwitchID, portID string) ([]capmodel.PortNeighbor, error) {
+		return nil, errors.New("APIC unreachable")
+	}
+	e.GET(testPortURI).Expect().Status(http.StatusOK).JSON().Object().
+		Value("Oem").Object().Value("CiscoACI").Object().NotContainsKey("Neighbors")
+}
+
+func TestGetPortInfoPortIDFormat(t *testing.T) {
+	e := mockPortApp(t)
+	config.Data.APICConf.PortIDFormat = "Ethernet{id}"
+	capmodel.SaveFabric(testFabricID, &capdata.Fabric{PodID: "1", SwitchData: []string{testSwitchID}})
+	capmodel.SavePort(testPortURI, &model.Port{ODataID: testPortURI, ID: testPortID, PortID: "eth1/1"})
+	getPortInfo = func(traceParent, podID, ACISwitchID, portID string) (*capmodel.PortInfoResponse, error) {
+		if portID != "eth1/1" {
+			t.Errorf("getPortInfo() of port %s, want the APIC port id eth1/1", portID)
+		}
+		return &capmodel.PortInfoResponse{IMData: []capmodel.PortInfoIMData{{
+			PhysicalInterface: capmodel.PhysicalInterface{Attributes: map[string]interface{}{"operSt": "up"}},
+		}}}, nil
+	}
+	getPortHealth = func(traceParent, podID, ACISwitchID, portID string) (*capmodel.Health, error) {
+		return &capmodel.Health{IMData: []capmodel.HealthIMData{{
+			HealthData: capmodel.HealthData{Attributes: map[string]interface{}{"cur": "100"}},
+		}}}, nil
+	}
+	defer func() {
+		getPortInfo = caputilities.GetPortInfo
+		getPortHealth = caputilities.GetPortHealth
+	}()
+
+	e.GET(testPortURI).Expect().Status(http.StatusOK).JSON().Object().Value("PortId").Equal("Ethernet1/1")
+	e.GET(testPortURI).WithHeader("Accept", mediaTypeXML).Expect().Status(http.StatusOK).Body().Contains("<PortId>Ethernet1/1</PortId>")
+
+	// the XML representation has the settings annotation, the actions and the OEM properties of the JSON one
+	config.Data.APICConf.PortResetEnabled = true
+	defer func() { config.Data.APICConf.PortResetEnabled = false }()
+	e.GET(testPortURI).WithHeader("Accept", mediaTypeXML).Expect().Status(http.StatusOK).Body().
+		Contains("<RedfishSettings><SettingsObject><OdataId>" + testPortURI + "/Settings</OdataId></SettingsObject>").
+		Contains("<Actions><PortReset><Target>" + testPortURI + "/Actions/Port.Reset</Target>").
+		Contains("<Oem><CiscoACI><DistinguishedName>topology/pod-1/").
+		Contains("<StatisticsHistory><OdataId>" + testPortURI + "/Oem/CiscoACI/StatisticsHistory</OdataId></StatisticsHistory>")
+
+	// the APIC port id is kept in the DB
+	port, err := capmodel.GetPort(testPortURI)
+	if err != nil {
+		t.Fatalf("GetPort() error = %v", err)
+	}
+	if port.PortID != "eth1/1" {
+		t.Errorf("stored PortId = %s, want eth1/1", port.PortID)
+	}
+}
+
+func TestGetPortInfoProblemJSON(t *testing.T) {
+	e := mockPortApp(t)
+	capmodel.SaveFabric(testFabricID, &capdata.Fabric{PodID: "1", SwitchData: []string{testSwitchID}})
+	missingPortURI := testPortsURI + "/portUUID:eth1-9"
+
+	// the Redfish error is kept unless the client prefers the problem details
+	e.GET(missingPortURI).WithHeader("Accept", "application/json, application/problem+json;q=0.5").
+		Expect().Status(http.StatusNotFound).ContentType("application/json").
+		JSON().Object().ContainsKey("error")
+
+	problem := e.GET(missingPortURI).WithHeader("Accept", "application/json;q=0.5, application/problem+json").
+		Expect().Status(http.StatusNotFound).ContentType(mediaTypeProblemJSON).JSON().Object()
+	problem.NotContainsKey("error")
+	problem.ValueEqual("type", "about:blank")
+	problem.ValueEqual("title", "Not Found")
+	problem.ValueEqual("status", http.StatusNotFound)
+	problem.ValueEqual("instance", missingPortURI)
+	problem.Value("code").String().Contains("GeneralError")
+	problem.Value("messageId").String().Contains("ResourceNotFound")
+	problem.Value("detail").String().Contains("failed to fetch port data for uri " + missingPortURI)
+	problem.ValueEqual("messageArgs", []interface{}{"Ports", missingPortURI})
+}
+
+func TestGetPortInfoTraceParent(t *testing.T) {
+	e := mockPortApp(t)
+	config.Data.OTelConf = &config.OTelConf{Enabled: true, SamplingRatio: 1, Exporter: "log"}
+	defer func() { config.Data.OTelConf = nil }()
+	capmodel.SaveFabric(testFabricID, &capdata.Fabric{PodID: "1", SwitchData: []string{testSwitchID}})
+	capmodel.SavePort(testPortURI, &model.Port{ODataID: testPortURI, ID: testPortID, PortID: "eth1/1"})
+	var sent []string
+	getPortInfo = func(traceParent, podID, ACISwitchID, portID string) (*capmodel.PortInfoResponse, error) {
+		sent = append(sent, traceParent)
+		return &capmodel.PortInfoResponse{IMData: []capmodel.PortInfoIMData{{
+			PhysicalInterface: capmodel.PhysicalInterface{Attributes: map[string]interface{}{"operSt": "up"}},
+		}}}, nil
+	}
+	getPortHealth = func(traceParent, podID, ACISwitchID, portID string) (*capmodel.Health, error) {
+		sent = append(sent, traceParent)
+		return &capmodel.Health{IMData: []capmodel.HealthIMData{{
+			HealthData: capmodel.HealthData{Attributes: map[string]interface{}{"cur": "100"}},
+		}}}, nil
+	}
+	defer func() {
+		getPortInfo = caputilities.GetPortInfo
+		getPortHealth = caputilities.GetPortHealth
+	}()
+
+	// the APIC calls are made in the trace of the request, each as a child span
+	incoming := "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+	e.GET(testPortURI).WithHeader("traceparent", incoming).Expect().Status(http.StatusOK)
+	if len(sent) == 0 {
+		t.Fatal("no APIC call made for the port")
+	}
+	for _, traceParent := range sent {
+		if !strings.HasPrefix(traceParent, "00-4bf92f3577b34da6a3ce929d0e0e4736-") || traceParent == incoming {
+			t.Errorf("APIC call made with traceparent %q, want a child span of %s", traceParent, incoming)
+		}
+	}
+}
@@ -0,0 +1,104 @@
+//(C) Copyright [2020] Hewlett Packard Enterprise Development LP
+//
+//Licensed under the Apache License, Version 2.0 (the "License"); you may
+//not use this file except in compliance with the License. You may obtain
+//a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+//Unless required by applicable law or agreed to in writing, software
+//distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+//WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+//License for the specific language governing permissions and limitations
+// under the License.
+
+//Package caphandler ...
+package caphandler
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/ODIM-Project/PluginCiscoACI/capresponse"
+	iris "github.com/kataras/iris/v12"
+	log "github.com/sirupsen/logrus"
+)
+
+// mediaTypeProblemJSON is the media type of the RFC 7807 problem details
+const mediaTypeProblemJSON = "application/problem+json"
+
+// writeErrorResponse writes the Redfish error response, as the problem details when the client
+// prefers application/problem+json over application/json in its Accept header
+func writeErrorResponse(ctx iris.Context, resp interface{}) {
+	if !acceptsProblemJSON(ctx.GetHeader("Accept")) {
+		ctx.JSON(resp)
+		return
+	}
+	body, err := json.Marshal(newProblemDetails(ctx.GetStatusCode(), ctx.Path(), resp))
+	if err != nil {
+		log.Error("failed to marshal the problem details, writing the Redfish error: " + err.Error())
+		ctx.JSON(resp)
+		return
+	}
+	ctx.ContentType(mediaTypeProblemJSON)
+	ctx.Write(body)
+}
+
+// acceptsProblemJSON checks if application/problem+json is accepted with a quality
+// not lower than the one of application/json
+func acceptsProblemJSON(accept string) bool {
+	problemQuality, jsonQuality := 0.0, 0.0
+	for _, mediaRange := range strings.Split(accept, ",") {
+		params := strings.Split(mediaRange, ";")
+		quality := mediaRangeQuality(params[1:])
+		switch strings.ToLower(strings.TrimSpace(params[0])) {
+		case mediaTypeProblemJSON:
+			problemQuality = quality
+		case mediaTypeJSON:
+			jsonQuality = quality
+		}
+	}
+	return problemQuality > 0 && problemQuality >= jsonQuality
+}
+
+// newProblemDetails maps the Redfish error response to the problem details, the code of the error
+// and the first message of its extended info give the detail, the message id and the message args
+func newProblemDetails(statusCode int, instance string, resp interface{}) capresponse.ProblemDetails {
+	problem := capresponse.ProblemDetails{
+		Type:     "about:blank",
+		Title:    http.StatusText(statusCode),
+		Status:   statusCode,
+		Instance: instance,
+	}
+	var errResp struct {
+		Error struct {
+			Code         string `json:"code"`
+			Message      string `json:"message"`
+			ExtendedInfo []struct {
+				MessageID   string        `json:"MessageId"`
+				Message     string        `json:"Message"`
+				MessageArgs []interface{} `json:"MessageArgs"`
+			} `json:"@Message.ExtendedInfo"`
+		} `json:"error"`
+	}
+	data, err := json.Marshal(resp)
+	if err == nil {
+		err = json.Unmarshal(data, &errResp)
+	}
+	if err != nil {
+		log.Error("failed to read the Redfish error for the problem details: " + err.Error())
+		return problem
+	}
+	problem.Code = errResp.Error.Code
+	problem.Detail = errResp.Error.Message
+	if len(errResp.Error.ExtendedInfo) > 0 {
+		message := errResp.Error.ExtendedInfo[0]
+		problem.MessageID = message.MessageID
+		problem.MessageArgs = message.MessageArgs
+		if message.Message != "" {
+			problem.Detail = message.Message
+		}
+	}
+	return problem
+}
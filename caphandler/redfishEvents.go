@@ -18,6 +18,7 @@ package caphandler
 import (
 	"encoding/json"
 	"github.com/ODIM-Project/ODIM/lib-utilities/common"
+	"github.com/ODIM-Project/PluginCiscoACI/capmessagebus"
 	pluginConfig "github.com/ODIM-Project/PluginCiscoACI/config"
 	iris "github.com/kataras/iris/v12"
 	log "github.com/sirupsen/logrus"
@@ -25,12 +26,8 @@ import (
 	"strings"
 )
 
-var (
-	// In Channel
-	In chan<- interface{}
-	// Out Channel
-	Out <-chan interface{}
-)
+// EventQueue buffers the events to be published on the message bus
+var EventQueue *capmessagebus.EventBuffer
 
 // RedfishEvents receives the subscribed events from the south bound system
 // Then it will send the received data and ip to publish method
@@ -63,11 +60,7 @@ func RedfishEvents(ctx iris.Context) {
 
 }
 
-// writeEventToJobQueue will write events to worker pool
+// writeEventToJobQueue will write events to the buffer of the publisher
 func writeEventToJobQueue(event common.Events) {
-	var events []interface{}
-	//events := make([]interface{}, 0)
-	events = append(events, event)
-	done := make(chan bool)
-	go common.RunWriteWorkers(In, events, 1, done)
+	EventQueue.Enqueue(event)
 }
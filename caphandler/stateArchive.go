@@ -0,0 +1,96 @@
+//(C) Copyright [2020] Hewlett Packard Enterprise Development LP
+//
+//Licensed under the Apache License, Version 2.0 (the "License"); you may
+//not use this file except in compliance with the License. You may obtain
+//a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+//Unless required by applicable law or agreed to in writing, software
+//distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+//WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+//License for the specific language governing permissions and limitations
+// under the License.
+
+package caphandler
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+
+	"github.com/ODIM-Project/ODIM/lib-utilities/response"
+	"github.com/ODIM-Project/PluginCiscoACI/capmodel"
+	"github.com/ODIM-Project/PluginCiscoACI/capresponse"
+	iris "github.com/kataras/iris/v12"
+	log "github.com/sirupsen/logrus"
+)
+
+// ExportStateArchive returns the fabrics stored by the plugin, with their switches and ports,
+// as a single versioned archive to be imported back by ImportStateArchive for disaster recovery
+func ExportStateArchive(ctx iris.Context) {
+	discoveryLock.Lock()
+	defer discoveryLock.Unlock()
+	archive, err := capmodel.ExportArchive()
+	if err != nil {
+		errMsg := "failed to export the state of the plugin: " + err.Error()
+		createDbErrResp(ctx, err, errMsg, nil)
+		return
+	}
+	ctx.StatusCode(http.StatusOK)
+	ctx.JSON(archive)
+}
+
+// ImportStateArchive stores the fabrics of the archive exported by ExportStateArchive, each fabric
+// is imported in a single DB transaction. The fabrics whose documents are already stored are refused
+// with a conflict, unless the force query parameter is true: their stored switches and ports are then
+// replaced by the ones of the archive.
+func ImportStateArchive(ctx iris.Context) {
+	force := ctx.URLParam("force") == "true"
+	if ctx.URLParamExists("force") && !force && ctx.URLParam("force") != "false" {
+		errMsg := fmt.Sprintf("invalid value %s for query parameter force, it should be true or false", ctx.URLParam("force"))
+		log.Error(errMsg)
+		ctx.StatusCode(http.StatusBadRequest)
+		writeErrorResponse(ctx, updateErrorResponse(response.GeneralError, errMsg, nil))
+		return
+	}
+	body, err := ioutil.ReadAll(ctx.Request().Body)
+	if err != nil {
+		errorMessage := "error while trying to read the request body: " + err.Error()
+		log.Error(errorMessage)
+		ctx.StatusCode(http.StatusBadRequest)
+		writeErrorResponse(ctx, updateErrorResponse(response.MalformedJSON, errorMessage, nil))
+		return
+	}
+	var archive capmodel.Archive
+	if err := json.Unmarshal(body, &archive); err != nil {
+		errorMessage := "error while trying to decode the state archive: " + err.Error()
+		log.Error(errorMessage)
+		ctx.StatusCode(http.StatusBadRequest)
+		writeErrorResponse(ctx, updateErrorResponse(response.MalformedJSON, errorMessage, nil))
+		return
+	}
+	if err := capmodel.ValidateArchive(&archive); err != nil {
+		errMsg := "invalid state archive: " + err.Error()
+		log.Error(errMsg)
+		ctx.StatusCode(http.StatusBadRequest)
+		writeErrorResponse(ctx, updateErrorResponse(response.GeneralError, errMsg, nil))
+		return
+	}
+	discoveryLock.Lock()
+	defer discoveryLock.Unlock()
+	imported := []string{}
+	for _, fabricArchive := range archive.Fabrics {
+		if err := capmodel.ImportFabric(fabricArchive, force); err != nil {
+			errMsg := fmt.Sprintf("failed to import fabric %s after importing fabrics %v: %s", fabricArchive.ID, imported, err.Error())
+			createResourceDbErrResp(ctx, err, errMsg, []interface{}{"Fabric", fabricArchive.ID},
+				resourceRef{fabricODataType, "/ODIM/v1/Fabrics/" + fabricArchive.ID})
+			return
+		}
+		imported = append(imported, fabricArchive.ID)
+		log.Info(fmt.Sprintf("imported fabric %s from the state archive, forced: %v", fabricArchive.ID, force))
+	}
+	ctx.StatusCode(http.StatusOK)
+	ctx.JSON(capresponse.StateArchiveImportResponse{Imported: imported})
+}
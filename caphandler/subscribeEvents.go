@@ -20,6 +20,7 @@ import (
 	"errors"
 	"fmt"
 	"github.com/ODIM-Project/ODIM/lib-utilities/common"
+	"github.com/ODIM-Project/PluginCiscoACI/capmiddleware"
 	"github.com/ODIM-Project/PluginCiscoACI/capmodel"
 	"github.com/ODIM-Project/PluginCiscoACI/caputilities"
 	evtConfig "github.com/ODIM-Project/PluginCiscoACI/config"
@@ -219,7 +220,7 @@ func getDeviceDetails(ctx iris.Context) (*caputilities.RedfishDevice, *capmodel.
 	token := ctx.GetHeader("X-Auth-Token")
 	//Validating the token
 	if token != "" {
-		flag := TokenValidation(token)
+		_, flag := TokenValidation(token)
 		if !flag {
 			log.Error("Invalid/Expired X-Auth-Token")
 			ctx.StatusCode(http.StatusUnauthorized)
@@ -316,7 +317,7 @@ func validateResponse(ctx iris.Context, device *caputilities.RedfishDevice, resp
 	}
 	common.SetResponseHeader(ctx, header)
 	ctx.StatusCode(resp.StatusCode)
-	log.Info("Redfish plugin response body: " + string(body))
+	capmiddleware.RequestLogger(ctx).Info("Redfish plugin response body: " + string(body))
 	ctx.WriteString(string(body))
 	return nil
 }
@@ -0,0 +1,136 @@
+//(C) Copyright [2020] Hewlett Packard Enterprise Development LP
+//
+//Licensed under the Apache License, Version 2.0 (the "License"); you may
+//not use this file except in compliance with the License. You may obtain
+//a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+//Unless required by applicable law or agreed to in writing, software
+//distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+//WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+//License for the specific language governing permissions and limitations
+// under the License.
+
+//Package caphandler ...
+package caphandler
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/ODIM-Project/ODIM/lib-utilities/response"
+	"github.com/ODIM-Project/PluginCiscoACI/capmodel"
+	"github.com/ODIM-Project/PluginCiscoACI/capresponse"
+	"github.com/ODIM-Project/PluginCiscoACI/caputilities"
+	"github.com/ODIM-Project/PluginCiscoACI/db"
+	iris "github.com/kataras/iris/v12"
+	log "github.com/sirupsen/logrus"
+)
+
+// APIC call used for collecting the ports of a switch, replaced in unit tests
+var getSwitchPortData = caputilities.GetPortData
+
+var (
+	switchDiscoveryLocksLock sync.Mutex
+	// switchDiscoveryLocks serialize the discoveries of the ports of each switch
+	switchDiscoveryLocks = map[string]*sync.Mutex{}
+)
+
+// DiscoverSwitch discovers the ports of the switch from APIC, the ports not stored are added and the
+// stored ports no longer in APIC are removed. The other switches of the fabric are left as they are.
+// The number of ports added and removed is returned.
+func DiscoverSwitch(ctx iris.Context) {
+	uri := ctx.Path()
+	fabricID := ctx.Params().Get("id")
+	switchID := ctx.Params().Get("switchID")
+	fabricData, err := capmodel.GetFabric(fabricID)
+	if err != nil {
+		errMsg := fmt.Sprintf("failed to fetch fabric data for uri %s: %s", uri, err.Error())
+		createDbErrResp(ctx, err, errMsg, []interface{}{"Fabric", fabricID})
+		return
+	}
+	if !checkSwitchExists(ctx, switchID) {
+		return
+	}
+	if !checkSwitchIDExists(fabricData.SwitchData, capmodel.SwitchNodeID(switchID)) {
+		errMsg := fmt.Sprintf("switch %s is not part of fabric %s", switchID, fabricID)
+		createResourceDbErrResp(ctx, fmt.Errorf("%w: %s", db.ErrorKeyNotFound, errMsg), errMsg, []interface{}{"Switch", switchID}, switchRef(ctx))
+		return
+	}
+	added, removed, err := discoverSwitchPorts(fabricID, fabricData.PodID, switchID)
+	if err != nil {
+		errMsg := fmt.Sprintf("failed to discover the ports of switch %s after adding %d and removing %d ports: %s", switchID, added, removed, err.Error())
+		if isAPICThrottled(err) {
+			createAPICErrResp(ctx, err, errMsg, nil)
+			return
+		}
+		log.Error(errMsg)
+		resp := withResource(updateErrorResponse(response.GeneralError, errMsg, nil), switchRef(ctx))
+		ctx.StatusCode(http.StatusInternalServerError)
+		writeErrorResponse(ctx, resp)
+		return
+	}
+	log.Info(fmt.Sprintf("discovered the ports of switch %s, added %d and removed %d ports", switchID, added, removed))
+	ctx.StatusCode(http.StatusOK)
+	ctx.JSON(capresponse.SwitchDiscoveryResponse{
+		SwitchID: switchID,
+		Added:    added,
+		Removed:  removed,
+	})
+}
+
+// discoverSwitchPorts reconciles the stored ports of the switch with the ports read from APIC, the
+// number of ports added and removed is returned. The ports kept are left as they are, with their
+// settings and links. Discoveries of the same switch are serialized.
+func discoverSwitchPorts(fabricID, podID, switchID string) (int, int, error) {
+	lock := switchDiscoveryLock(switchID)
+	lock.Lock()
+	defer lock.Unlock()
+	nodeID := capmodel.SwitchNodeID(switchID)
+	portData, err := getSwitchPortData(podID, nodeID)
+	if err != nil {
+		return 0, 0, fmt.Errorf("while reading the ports of node %s from APIC, got: %w", nodeID, err)
+	}
+	stored, err := capmodel.GetSwitchPort(switchID)
+	if err != nil {
+		return 0, 0, err
+	}
+	// the stored ports are identified by the APIC port id with - for /, after the : of their id
+	inAPIC := map[string]bool{}
+	newPorts := &capmodel.PortCollectionResponse{}
+	for _, imdata := range portData.IMData {
+		apicPortID, _ := imdata.PhysicalInterface.Attributes["id"].(string)
+		inAPIC[strings.Replace(apicPortID, "/", "-", -1)] = true
+		if apicPortID == "" || findSwitchPort(stored, apicPortID) == "" {
+			newPorts.IMData = append(newPorts.IMData, imdata)
+		}
+	}
+	removed := 0
+	for _, portID := range stored {
+		if inAPIC[portID[strings.LastIndex(portID, ":")+1:]] {
+			continue
+		}
+		portOID := fmt.Sprintf("/ODIM/v1/Fabrics/%s/Switches/%s/Ports/%s", fabricID, switchID, portID)
+		if err := capmodel.DeletePort(switchID, portOID); err != nil {
+			return 0, removed, err
+		}
+		removed++
+	}
+	added, err := parsePortData(newPorts, switchID, fabricID, podID, nodeID)
+	return added, removed, err
+}
+
+// switchDiscoveryLock returns the lock serializing the discoveries of the ports of the switch
+func switchDiscoveryLock(switchID string) *sync.Mutex {
+	switchDiscoveryLocksLock.Lock()
+	defer switchDiscoveryLocksLock.Unlock()
+	lock, ok := switchDiscoveryLocks[switchID]
+	if !ok {
+		lock = &sync.Mutex{}
+		switchDiscoveryLocks[switchID] = lock
+	}
+	return lock
+}
@@ -18,8 +18,6 @@ package caphandler
 import (
 	"fmt"
 	"net/http"
-	"strconv"
-	"strings"
 
 	"github.com/ODIM-Project/ODIM/lib-dmtf/model"
 	"github.com/ODIM-Project/PluginCiscoACI/capmodel"
@@ -29,9 +27,12 @@ import (
 	log "github.com/sirupsen/logrus"
 )
 
+// APIC call reading the health of the switch, replaced in unit tests
+var getSwitchHealth = caputilities.GetSwitchHealth
+
 // GetSwitchCollection fetches the switches which are linked to that fabric
 func GetSwitchCollection(ctx iris.Context) {
-	uri := ctx.Request().RequestURI
+	uri := ctx.Path()
 	fabricID := ctx.Params().Get("id")
 
 	// get all switches which are store under that fabric
@@ -64,9 +65,12 @@ func GetSwitchCollection(ctx iris.Context) {
 
 // GetSwitchInfo fetches the switch info for given swith id
 func GetSwitchInfo(ctx iris.Context) {
-	uri := ctx.Request().RequestURI
+	uri := ctx.Path()
 	switchID := ctx.Params().Get("rid")
 	fabricID := ctx.Params().Get("id")
+	if !checkSwitchID(ctx, switchID) {
+		return
+	}
 	fabricData, err := capmodel.GetFabric(fabricID)
 	if err != nil {
 		errMsg := fmt.Sprintf("failed to fetch switch data for uri %s: %s", uri, err.Error())
@@ -95,23 +99,15 @@ func GetSwitchInfo(ctx iris.Context) {
 }
 
 func getSwitchHealthData(podID, switchID string) string {
-	switchIDData := strings.Split(switchID, ":")
-	switchHealthResposne, err := caputilities.GetSwitchHealth(podID, switchIDData[1])
+	switchHealthResposne, err := getSwitchHealth(podID, capmodel.SwitchNodeID(switchID))
 	if err != nil {
 		log.Error("Unable to get Health of switch " + err.Error())
 		return ""
 	}
-	data := switchHealthResposne.IMData[0].HealthData.Attributes
-	currentHealthValue := data["cur"].(string)
-	healthValue, err := strconv.Atoi(currentHealthValue)
+	healthValue, err := caputilities.HealthScore(switchHealthResposne.IMData[0].HealthData.Attributes)
 	if err != nil {
-		log.Error("Unable to convert current Health value:" + currentHealthValue + " go the error" + err.Error())
+		log.Error("Unable to get current Health value: " + err.Error())
 		return ""
 	}
-	if healthValue > 90 {
-		return "OK"
-	} else if healthValue <= 90 && healthValue < 30 {
-		return "Warning"
-	}
-	return "Critical"
+	return healthOfScore(healthValue)
 }
@@ -0,0 +1,89 @@
+//(C) Copyright [2020] Hewlett Packard Enterprise Development LP
+//
+//Licensed under the Apache License, Version 2.0 (the "License"); you may
+//not use this file except in compliance with the License. You may obtain
+//a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+//Unless required by applicable law or agreed to in writing, software
+//distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+//WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+//License for the specific language governing permissions and limitations
+// under the License.
+
+//Package caphandler ...
+package caphandler
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+
+	"github.com/ODIM-Project/PluginCiscoACI/capmodel"
+	"github.com/ODIM-Project/PluginCiscoACI/capresponse"
+	iris "github.com/kataras/iris/v12"
+)
+
+// ExportFabricTopology returns the switches, ports and the connected ethernet interfaces of the fabric
+// as a graph. The graph is built only from the data stored in the DB, without querying APIC.
+func ExportFabricTopology(ctx iris.Context) {
+	uri := ctx.Path()
+	fabricID := ctx.Params().Get("id")
+	fabricData, err := capmodel.GetFabric(fabricID)
+	if err != nil {
+		errMsg := fmt.Sprintf("failed to fetch fabric data for uri %s: %s", uri, err.Error())
+		createDbErrResp(ctx, err, errMsg, []interface{}{"Fabric", fabricID})
+		return
+	}
+	graph := capresponse.TopologyGraph{
+		FabricID: fabricID,
+		Nodes:    []capresponse.TopologyNode{},
+		Edges:    []capresponse.TopologyEdge{},
+	}
+	ethernetInterfaces := map[string]bool{}
+	for _, switchID := range fabricData.SwitchData {
+		switchOID := fmt.Sprintf("/ODIM/v1/Fabrics/%s/Switches/%s", fabricID, switchID)
+		switchNode := capresponse.TopologyNode{ID: switchOID, Type: capresponse.TopologyNodeSwitch}
+		if switchData, err := capmodel.GetSwitch(switchID); err == nil {
+			switchNode.Name = switchData.Name
+		}
+		graph.Nodes = append(graph.Nodes, switchNode)
+		ports, err := capmodel.GetSwitchPort(switchID)
+		if err != nil {
+			errMsg := fmt.Sprintf("failed to fetch port data of switch %s: %s", switchID, err.Error())
+			createDbErrResp(ctx, err, errMsg, []interface{}{"Switch", switchOID})
+			return
+		}
+		for _, portID := range ports {
+			portOID := switchOID + "/Ports/" + portID
+			port, err := capmodel.GetPort(portOID)
+			if err != nil {
+				errMsg := fmt.Sprintf("failed to fetch port data for uri %s: %s", portOID, err.Error())
+				createDbErrResp(ctx, err, errMsg, []interface{}{"Port", portOID})
+				return
+			}
+			graph.Nodes = append(graph.Nodes, capresponse.TopologyNode{ID: portOID, Type: capresponse.TopologyNodePort, Name: port.Name})
+			graph.Edges = append(graph.Edges, capresponse.TopologyEdge{Source: switchOID, Target: portOID, Type: capresponse.TopologyEdgeContains})
+			if port.Links == nil {
+				continue
+			}
+			for _, connectedPort := range port.Links.ConnectedPorts {
+				ethernetInterfaces[connectedPort.Oid] = true
+				graph.Edges = append(graph.Edges, capresponse.TopologyEdge{Source: portOID, Target: connectedPort.Oid, Type: capresponse.TopologyEdgeConnectedTo})
+			}
+		}
+	}
+	for ethernetOID := range ethernetInterfaces {
+		graph.Nodes = append(graph.Nodes, capresponse.TopologyNode{ID: ethernetOID, Type: capresponse.TopologyNodeEthernetInterface})
+	}
+	sort.Slice(graph.Nodes, func(i, j int) bool { return graph.Nodes[i].ID < graph.Nodes[j].ID })
+	sort.Slice(graph.Edges, func(i, j int) bool {
+		if graph.Edges[i].Source != graph.Edges[j].Source {
+			return graph.Edges[i].Source < graph.Edges[j].Source
+		}
+		return graph.Edges[i].Target < graph.Edges[j].Target
+	})
+	ctx.StatusCode(http.StatusOK)
+	ctx.JSON(graph)
+}
@@ -0,0 +1,63 @@
+//(C) Copyright [2020] Hewlett Packard Enterprise Development LP
+//
+//Licensed under the Apache License, Version 2.0 (the "License"); you may
+//not use this file except in compliance with the License. You may obtain
+//a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+//Unless required by applicable law or agreed to in writing, software
+//distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+//WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+//License for the specific language governing permissions and limitations
+// under the License.
+
+package caphandler
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/ODIM-Project/ODIM/lib-dmtf/model"
+	"github.com/ODIM-Project/PluginCiscoACI/capdata"
+	"github.com/ODIM-Project/PluginCiscoACI/capmodel"
+	"github.com/ODIM-Project/PluginCiscoACI/config"
+	"github.com/ODIM-Project/PluginCiscoACI/db"
+
+	iris "github.com/kataras/iris/v12"
+	"github.com/kataras/iris/v12/httptest"
+)
+
+func TestExportFabricTopology(t *testing.T) {
+	config.SetUpMockConfig(t)
+	db.Connector = db.NewMockMemoryConnector()
+	capmodel.InvalidatePortCache()
+	capmodel.InvalidateFabricCache()
+	capmodel.SaveFabric(testFabricID, &capdata.Fabric{PodID: "1", SwitchData: []string{testSwitchID}})
+	capmodel.SaveSwitchPort(testSwitchID, []string{testPortID})
+	capmodel.SavePort(testPortURI, &model.Port{
+		ODataID: testPortURI,
+		ID:      testPortID,
+		Name:    "Port-eth1/1",
+		Links:   &model.PortLinks{ConnectedPorts: []model.Link{{Oid: testEthernetID}}},
+	})
+	mockApp := iris.New()
+	mockApp.Post("/ODIM/v1/Fabrics/{id}/Actions/Oem/CiscoACIFabric.ExportTopology", ExportFabricTopology)
+	e := httptest.New(t, mockApp)
+
+	switchURI := "/ODIM/v1/Fabrics/fabricID/Switches/switchUUID:101"
+	graph := e.POST("/ODIM/v1/Fabrics/fabricID/Actions/Oem/CiscoACIFabric.ExportTopology").
+		Expect().Status(http.StatusOK).JSON().Object()
+	graph.Value("FabricId").Equal(testFabricID)
+	nodes := graph.Value("Nodes").Array()
+	nodes.Length().Equal(3)
+	nodes.Element(0).Object().ValueEqual("Id", switchURI).ValueEqual("Type", "Switch")
+	nodes.Element(1).Object().ValueEqual("Id", testPortURI).ValueEqual("Type", "Port").ValueEqual("Name", "Port-eth1/1")
+	nodes.Element(2).Object().ValueEqual("Id", testEthernetID).ValueEqual("Type", "EthernetInterface")
+	edges := graph.Value("Edges").Array()
+	edges.Length().Equal(2)
+	edges.Element(0).Object().ValueEqual("Source", switchURI).ValueEqual("Target", testPortURI).ValueEqual("Type", "Contains")
+	edges.Element(1).Object().ValueEqual("Source", testPortURI).ValueEqual("Target", testEthernetID).ValueEqual("Type", "ConnectedTo")
+
+	e.POST("/ODIM/v1/Fabrics/unknown/Actions/Oem/CiscoACIFabric.ExportTopology").Expect().Status(http.StatusNotFound)
+}
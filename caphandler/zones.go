@@ -49,7 +49,7 @@ func CreateVRF(name string, tenant string, description string, fvCtxattr aciMode
 
 // GetZones returns the collection of zones present under a fabric
 func GetZones(ctx iris.Context) {
-	uri := ctx.Request().RequestURI
+	uri := ctx.Path()
 	fabricID := ctx.Params().Get("id")
 	if _, err := capmodel.GetFabric(fabricID); err != nil {
 		errMsg := fmt.Sprintf("failed to fetch fabric data for uri %s: %s", uri, err.Error())
@@ -85,7 +85,7 @@ func GetZones(ctx iris.Context) {
 
 // GetZone returns a specific zone present under a fabric
 func GetZone(ctx iris.Context) {
-	uri := ctx.Request().RequestURI
+	uri := ctx.Path()
 	fabricID := ctx.Params().Get("id")
 	if _, err := capmodel.GetFabric(fabricID); err != nil {
 		errMsg := fmt.Sprintf("failed to fetch fabric data for uri %s: %s", uri, err.Error())
@@ -105,7 +105,7 @@ func GetZone(ctx iris.Context) {
 
 // CreateZone default function called for creation of any type of zone
 func CreateZone(ctx iris.Context) {
-	uri := ctx.Request().RequestURI
+	uri := ctx.Path()
 	fabricID := ctx.Params().Get("id")
 	if _, err := capmodel.GetFabric(fabricID); err != nil {
 		errMsg := fmt.Sprintf("failed to fetch fabric data for uri %s: %s", uri, err.Error())
@@ -120,7 +120,7 @@ func CreateZone(ctx iris.Context) {
 		log.Error(errorMessage)
 		resp := updateErrorResponse(response.MalformedJSON, errorMessage, nil)
 		ctx.StatusCode(http.StatusBadRequest)
-		ctx.JSON(resp)
+		writeErrorResponse(ctx, resp)
 		return
 	}
 	switch zone.ZoneType {
@@ -128,7 +128,7 @@ func CreateZone(ctx iris.Context) {
 		resp, statusCode := CreateDefaultZone(zone)
 		if statusCode != http.StatusCreated {
 			ctx.StatusCode(statusCode)
-			ctx.JSON(resp)
+			writeErrorResponse(ctx, resp)
 			return
 		}
 		conflictFlag := false
@@ -171,7 +171,7 @@ func CreateZone(ctx iris.Context) {
 		defaultZoneLink, resp, statusCode, domainData := CreateZoneOfZones(uri, fabricID, zone)
 		if statusCode != http.StatusCreated {
 			ctx.StatusCode(statusCode)
-			ctx.JSON(resp)
+			writeErrorResponse(ctx, resp)
 			return
 		}
 		conflictFlag := false
@@ -229,7 +229,7 @@ func CreateZone(ctx iris.Context) {
 		zoneofZoneOID, resp, statusCode := createZoneOfEndpoints(uri, fabricID, zone)
 		if statusCode != http.StatusCreated {
 			ctx.StatusCode(statusCode)
-			ctx.JSON(resp)
+			writeErrorResponse(ctx, resp)
 			return
 		}
 		zoneID := uuid.NewV4().String()
@@ -311,7 +311,7 @@ func CreateDefaultZone(zone model.Zone) (interface{}, int) {
 
 // DeleteZone deletes the zone from the resource
 func DeleteZone(ctx iris.Context) {
-	uri := ctx.Request().RequestURI
+	uri := ctx.Path()
 	fabricID := ctx.Params().Get("id")
 	if _, err := capmodel.GetFabric(fabricID); err != nil {
 		errMsg := fmt.Sprintf("failed to fetch fabric data for uri %s: %s", uri, err.Error())
@@ -333,7 +333,7 @@ func DeleteZone(ctx iris.Context) {
 			log.Error(errMsg)
 			resp := updateErrorResponse(response.ResourceCannotBeDeleted, errMsg, []interface{}{"Zone", uri})
 			ctx.StatusCode(http.StatusNotAcceptable)
-			ctx.JSON(resp)
+			writeErrorResponse(ctx, resp)
 			return
 		}
 	}
@@ -343,7 +343,7 @@ func DeleteZone(ctx iris.Context) {
 			if err.Error() == "Error deleting Application Profile" {
 				resp := updateErrorResponse(response.GeneralError, err.Error(), nil)
 				ctx.StatusCode(http.StatusBadRequest)
-				ctx.JSON(resp)
+				writeErrorResponse(ctx, resp)
 				return
 			}
 		}
@@ -352,7 +352,7 @@ func DeleteZone(ctx iris.Context) {
 			log.Error(errMsg)
 			resp := updateErrorResponse(response.ResourceNotFound, errMsg, []interface{}{"Zone", uri})
 			ctx.StatusCode(http.StatusNotFound)
-			ctx.JSON(resp)
+			writeErrorResponse(ctx, resp)
 			return
 		}
 		if err = capmodel.DeleteZone(fabricID, uri); err != nil {
@@ -369,7 +369,7 @@ func DeleteZone(ctx iris.Context) {
 			errMsg := "Error while deleting Zone: " + err.Error()
 			resp := updateErrorResponse(response.GeneralError, errMsg, nil)
 			ctx.StatusCode(http.StatusBadRequest)
-			ctx.JSON(resp)
+			writeErrorResponse(ctx, resp)
 			return
 		}
 		if err = capmodel.DeleteZone(fabricID, uri); err != nil {
@@ -382,7 +382,7 @@ func DeleteZone(ctx iris.Context) {
 	if zoneData.ZoneType == "ZoneOfEndpoints" {
 		resp, statusCode := deleteZoneOfEndpoints(fabricID, &zoneData)
 		ctx.StatusCode(statusCode)
-		ctx.JSON(resp)
+		writeErrorResponse(ctx, resp)
 	}
 }
 
@@ -962,15 +962,15 @@ func createStaticPort(epgName, tenantName, applicationProfileName string, aciPol
 	_, err := aciClient.CreateStaticPath(aciPolicyGroupData.PolicyGroupDN, epgName, applicationProfileName, tenantName, "", staticPathAttributes)
 	if err != nil {
 		errMsg := "Error while creating  Zone of Zones: " + err.Error()
-		resp := updateErrorResponse(response.GeneralError, errMsg, nil)
-		return resp, http.StatusBadRequest
+		statusCode, resp := createAPICErrResp(nil, err, errMsg, nil)
+		return resp, statusCode
 	}
 	// Attach the domain entity profile to given policy group
 	err = aciClient.CreateRelationinfraRsAttEntPFromPCVPCInterfacePolicyGroup(aciPolicyGroupData.PCVPCPolicyGroupDN, domainData.DomainEntityProfileDn)
 	if err != nil {
 		errMsg := "Error while creating  Zone of Zones: " + err.Error()
-		resp := updateErrorResponse(response.GeneralError, errMsg, nil)
-		return resp, http.StatusBadRequest
+		statusCode, resp := createAPICErrResp(nil, err, errMsg, nil)
+		return resp, statusCode
 	}
 	return nil, http.StatusCreated
 }
@@ -1042,7 +1042,7 @@ func getZoneTODomainDNData(zoneID string) (capdata.ACIDomainData, error) {
 
 // UpdateZoneData provides patch operation on Zone
 func UpdateZoneData(ctx iris.Context) {
-	uri := ctx.Request().RequestURI
+	uri := ctx.Path()
 	fabricID := ctx.Params().Get("id")
 	if _, err := capmodel.GetFabric(fabricID); err != nil {
 		errMsg := fmt.Sprintf("failed to fetch fabric data for uri %s: %s", uri, err.Error())
@@ -1061,7 +1061,7 @@ func UpdateZoneData(ctx iris.Context) {
 	if zoneData.ZoneType != "ZoneOfEndpoints" {
 		ctx.StatusCode(http.StatusMethodNotAllowed)
 		resp := updateErrorResponse(response.ActionNotSupported, "", []interface{}{ctx.Request().Method})
-		ctx.JSON(resp)
+		writeErrorResponse(ctx, resp)
 		return
 	}
 	var zoneRequest model.Zone
@@ -1070,7 +1070,7 @@ func UpdateZoneData(ctx iris.Context) {
 		log.Error(errorMessage)
 		resp := updateErrorResponse(response.MalformedJSON, errorMessage, nil)
 		ctx.StatusCode(http.StatusBadRequest)
-		ctx.JSON(resp)
+		writeErrorResponse(ctx, resp)
 		return
 	}
 
@@ -1079,14 +1079,14 @@ func UpdateZoneData(ctx iris.Context) {
 		log.Error(errMsg)
 		resp := updateErrorResponse(response.PropertyMissing, errMsg, []interface{}{"Links"})
 		ctx.StatusCode(http.StatusBadRequest)
-		ctx.JSON(resp)
+		writeErrorResponse(ctx, resp)
 		return
 	}
 	// get the AddressPoolData for the zone
 	addresspoolData, statusCode, resp := getAddressPoolData(fabricID, zoneData.Links.AddressPools[0].Oid)
 	if statusCode != http.StatusOK {
 		ctx.StatusCode(statusCode)
-		ctx.JSON(resp)
+		writeErrorResponse(ctx, resp)
 		return
 	}
 	// get the domaindata for the ZoneOfZone
@@ -1096,7 +1096,7 @@ func UpdateZoneData(ctx iris.Context) {
 		log.Error(errMsg)
 		resp = updateErrorResponse(response.ResourceNotFound, errMsg, []interface{}{zoneData.Links.ContainedByZones[0].Oid, "Domain"})
 		ctx.StatusCode(http.StatusNotFound)
-		ctx.JSON(resp)
+		writeErrorResponse(ctx, resp)
 		return
 	}
 	// check all given endpoints
@@ -1108,7 +1108,7 @@ func UpdateZoneData(ctx iris.Context) {
 		data, statusCode, resp := getEndpointData(fabricID, zoneData.Links.Endpoints[i].Oid)
 		if statusCode != http.StatusOK {
 			ctx.StatusCode(statusCode)
-			ctx.JSON(resp)
+			writeErrorResponse(ctx, resp)
 			return
 		}
 		endPointData[zoneData.Links.Endpoints[i].Oid] = &data
@@ -1118,7 +1118,7 @@ func UpdateZoneData(ctx iris.Context) {
 		data, statusCode, resp := getEndpointData(fabricID, zoneRequest.Links.Endpoints[i].Oid)
 		if statusCode != http.StatusOK {
 			ctx.StatusCode(statusCode)
-			ctx.JSON(resp)
+			writeErrorResponse(ctx, resp)
 			return
 		}
 		endpointRequestData[zoneRequest.Links.Endpoints[i].Oid] = &data
@@ -1160,7 +1160,7 @@ func UpdateZoneData(ctx iris.Context) {
 			resp, statusCode = createStaticPort(zoneData.Name+"-EPG", defaultZoneData.Name, zoneofZoneData.Name, data.ACIPolicyGroupData, vlan, &domainData, aciClient, untagVLANflag)
 			if statusCode != http.StatusCreated {
 				ctx.StatusCode(statusCode)
-				ctx.JSON(resp)
+				writeErrorResponse(ctx, resp)
 				return
 			}
 		}
@@ -1171,7 +1171,7 @@ func UpdateZoneData(ctx iris.Context) {
 		resp, statusCode = deleteStaticPort(data.ACIPolicyGroupData.PolicyGroupDN, zoneData.Name+"-EPG", defaultZoneData.Name, zoneofZoneData.Name)
 		if statusCode != http.StatusOK {
 			ctx.StatusCode(statusCode)
-			ctx.JSON(resp)
+			writeErrorResponse(ctx, resp)
 			return
 		}
 		endpointPresentFlag := checkEndpointExits(endpointOID, zonesData, defaultZoneData, zoneData.ODataID)
@@ -1181,7 +1181,7 @@ func UpdateZoneData(ctx iris.Context) {
 
 			if statusCode != http.StatusOK {
 				ctx.StatusCode(statusCode)
-				ctx.JSON(resp)
+				writeErrorResponse(ctx, resp)
 				return
 			}
 		}
@@ -1202,8 +1202,8 @@ func deleteStaticPort(policyGroupDN, epgName, tenantName, applicationProfileName
 	err := aciClient.DeleteStaticPath(policyGroupDN, epgName, applicationProfileName, tenantName)
 	if err != nil {
 		errMsg := "Error while creating  Zone of Zones: " + err.Error()
-		resp := updateErrorResponse(response.GeneralError, errMsg, nil)
-		return resp, http.StatusBadRequest
+		statusCode, resp := createAPICErrResp(nil, err, errMsg, nil)
+		return resp, statusCode
 	}
 	return nil, http.StatusOK
 }
@@ -1213,8 +1213,8 @@ func deleteRelationDomainEntityGroupInterfacePolicyGroup(policyGroupDN string) (
 	err := aciClient.DeleteRelationinfraRsAttEntPFromPCVPCInterfacePolicyGroup(policyGroupDN)
 	if err != nil {
 		errMsg := "Error while creating  Zone of Zones: " + err.Error()
-		resp := updateErrorResponse(response.GeneralError, errMsg, nil)
-		return resp, http.StatusBadRequest
+		statusCode, resp := createAPICErrResp(nil, err, errMsg, nil)
+		return resp, statusCode
 	}
 	return nil, http.StatusOK
 }
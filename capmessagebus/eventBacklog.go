@@ -0,0 +1,135 @@
+//(C) Copyright [2020] Hewlett Packard Enterprise Development LP
+//
+//Licensed under the Apache License, Version 2.0 (the "License"); you may
+//not use this file except in compliance with the License. You may obtain
+//a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+//Unless required by applicable law or agreed to in writing, software
+//distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+//WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+//License for the specific language governing permissions and limitations
+// under the License.
+
+package capmessagebus
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/ODIM-Project/ODIM/lib-utilities/common"
+	"github.com/ODIM-Project/PluginCiscoACI/capmodel"
+	log "github.com/sirupsen/logrus"
+)
+
+// EventBacklog keeps the events in the DB while the message bus is unavailable, and publishes them
+// in order once it recovers. The backlog is bounded, the oldest events are dropped when it is full.
+type EventBacklog struct {
+	capacity int
+	retry    time.Duration
+	publish  func(common.Events) error
+	// lock serializes publishing the new events with flushing the backlog, to keep the events in order
+	lock sync.Mutex
+}
+
+// NewEventBacklog returns the backlog of the given capacity, the events are published with publish
+// and retry is the interval of the retries of publishing the backlog
+func NewEventBacklog(capacity int, retry time.Duration, publish func(common.Events) error) *EventBacklog {
+	return &EventBacklog{
+		capacity: capacity,
+		retry:    retry,
+		publish:  publish,
+	}
+}
+
+// Publish publishes the event, the event is added to the backlog instead when the message bus is
+// unavailable or events are already waiting in the backlog. false is returned when the event is lost.
+func (b *EventBacklog) Publish(data interface{}) bool {
+	event, ok := data.(common.Events)
+	if !ok {
+		log.Error("Invalid data on publishing events")
+		return false
+	}
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	size, err := capmodel.GetEventBacklogSize()
+	if err != nil {
+		log.Error("while reading the event backlog, got: " + err.Error())
+	}
+	if size == 0 {
+		err := b.publish(event)
+		if err == nil {
+			return true
+		}
+		if !errors.Is(err, ErrMessageBusUnavailable) {
+			log.Error(err.Error())
+			return false
+		}
+		log.Warn("while publishing event, got: " + err.Error() + ", keeping the events in the backlog until the message bus recovers")
+	}
+	return b.add(event)
+}
+
+func (b *EventBacklog) add(event common.Events) bool {
+	data, err := json.Marshal(event)
+	if err != nil {
+		log.Error("while marshalling event, got: " + err.Error())
+		return false
+	}
+	dropped, err := capmodel.AddEventToBacklog(data, b.capacity)
+	if err != nil {
+		log.Error(err.Error())
+		return false
+	}
+	if dropped > 0 {
+		log.Warn(fmt.Sprintf("the event backlog is full, dropped the %d oldest events", dropped))
+	}
+	if size, err := capmodel.GetEventBacklogSize(); err == nil {
+		log.Info(fmt.Sprintf("%d events in the backlog waiting for the message bus", size))
+	}
+	return true
+}
+
+// Flush publishes the events of the backlog in order, it stops at the first event which can't be
+// published as the message bus is unavailable. Events which fail to publish otherwise are dropped.
+func (b *EventBacklog) Flush() error {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	eventIDs, err := capmodel.ListBackloggedEvents()
+	if err != nil || len(eventIDs) == 0 {
+		return err
+	}
+	for i, eventID := range eventIDs {
+		data, err := capmodel.GetBackloggedEvent(eventID)
+		if err != nil {
+			return err
+		}
+		var event common.Events
+		if err := json.Unmarshal(data, &event); err != nil {
+			log.Error("while unmarshalling event " + eventID + " of the backlog, dropping it, got: " + err.Error())
+		} else if err := b.publish(event); errors.Is(err, ErrMessageBusUnavailable) {
+			return fmt.Errorf("%d events left in the backlog: %w", len(eventIDs)-i, err)
+		} else if err != nil {
+			log.Error("while publishing event " + eventID + " of the backlog, dropping it, got: " + err.Error())
+		}
+		if err := capmodel.RemoveEventFromBacklog(eventID); err != nil {
+			return err
+		}
+	}
+	log.Info(fmt.Sprintf("message bus recovered, published the backlog of %d events", len(eventIDs)))
+	return nil
+}
+
+// Run retries flushing the backlog every retry interval
+func (b *EventBacklog) Run() {
+	for {
+		time.Sleep(b.retry)
+		if err := b.Flush(); err != nil {
+			log.Warn("while publishing the event backlog, got: " + err.Error())
+		}
+	}
+}
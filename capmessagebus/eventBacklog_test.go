@@ -0,0 +1,95 @@
+//(C) Copyright [2020] Hewlett Packard Enterprise Development LP
+//
+//Licensed under the Apache License, Version 2.0 (the "License"); you may
+//not use this file except in compliance with the License. You may obtain
+//a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+//Unless required by applicable law or agreed to in writing, software
+//distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+//WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+//License for the specific language governing permissions and limitations
+// under the License.
+
+package capmessagebus
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/ODIM-Project/ODIM/lib-utilities/common"
+	"github.com/ODIM-Project/PluginCiscoACI/capmodel"
+	"github.com/ODIM-Project/PluginCiscoACI/db"
+)
+
+// fakeMessageBus records the events published, publishing fails while it is down
+type fakeMessageBus struct {
+	down      bool
+	published []string
+}
+
+func (m *fakeMessageBus) publish(event common.Events) error {
+	if m.down {
+		return ErrMessageBusUnavailable
+	}
+	m.published = append(m.published, string(event.Request))
+	return nil
+}
+
+func TestEventBacklogOutage(t *testing.T) {
+	db.Connector = db.NewMockMemoryConnector()
+	bus := &fakeMessageBus{down: true}
+	backlog := NewEventBacklog(10, time.Second, bus.publish)
+	for _, request := range []string{"first", "second"} {
+		if !backlog.Publish(common.Events{Request: []byte(request)}) {
+			t.Errorf("Publish(%s) = false, want the event kept in the backlog", request)
+		}
+	}
+	if size, _ := capmodel.GetEventBacklogSize(); size != 2 || len(bus.published) != 0 {
+		t.Fatalf("backlog size = %d with %v published, want the 2 events in the backlog", size, bus.published)
+	}
+	if err := backlog.Flush(); !errors.Is(err, ErrMessageBusUnavailable) {
+		t.Errorf("Flush() while message bus down error = %v, want ErrMessageBusUnavailable", err)
+	}
+
+	// new events are queued behind the backlog, even once the message bus recovers
+	bus.down = false
+	backlog.Publish(common.Events{Request: []byte("third")})
+	if len(bus.published) != 0 {
+		t.Fatalf("published %v before flushing the backlog, want none", bus.published)
+	}
+	if err := backlog.Flush(); err != nil {
+		t.Fatalf("Flush() error = %v", err)
+	}
+	if want := []string{"first", "second", "third"}; !reflect.DeepEqual(bus.published, want) {
+		t.Errorf("published events = %v, want %v", bus.published, want)
+	}
+	if size, _ := capmodel.GetEventBacklogSize(); size != 0 {
+		t.Errorf("backlog size after flush = %d, want 0", size)
+	}
+
+	// events are published directly with the backlog empty
+	backlog.Publish(common.Events{Request: []byte("fourth")})
+	if want := []string{"first", "second", "third", "fourth"}; !reflect.DeepEqual(bus.published, want) {
+		t.Errorf("published events = %v, want %v", bus.published, want)
+	}
+}
+
+func TestEventBacklogCapacity(t *testing.T) {
+	db.Connector = db.NewMockMemoryConnector()
+	bus := &fakeMessageBus{down: true}
+	backlog := NewEventBacklog(2, time.Second, bus.publish)
+	for _, request := range []string{"first", "second", "third"} {
+		backlog.Publish(common.Events{Request: []byte(request)})
+	}
+	bus.down = false
+	if err := backlog.Flush(); err != nil {
+		t.Fatalf("Flush() error = %v", err)
+	}
+	if want := []string{"second", "third"}; !reflect.DeepEqual(bus.published, want) {
+		t.Errorf("published events = %v, want %v", bus.published, want)
+	}
+}
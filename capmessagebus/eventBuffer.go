@@ -0,0 +1,101 @@
+//(C) Copyright [2020] Hewlett Packard Enterprise Development LP
+//
+//Licensed under the Apache License, Version 2.0 (the "License"); you may
+//not use this file except in compliance with the License. You may obtain
+//a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+//Unless required by applicable law or agreed to in writing, software
+//distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+//WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+//License for the specific language governing permissions and limitations
+// under the License.
+
+package capmessagebus
+
+import (
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"github.com/ODIM-Project/PluginCiscoACI/config"
+	log "github.com/sirupsen/logrus"
+)
+
+// EventBuffer is the bounded buffer between the producers of the events and the publisher, so a
+// slow message bus doesn't stall the producers. The events produced while the buffer is full are
+// dropped according to the overflow policy, EventOverflowDropOldest or EventOverflowBlock.
+type EventBuffer struct {
+	events  chan interface{}
+	policy  string
+	timeout time.Duration
+	dropped uint64
+}
+
+// NewEventBuffer returns the buffer of the given capacity, timeout is how long Enqueue waits for room
+// in the buffer with EventOverflowBlock policy
+func NewEventBuffer(capacity int, policy string, timeout time.Duration) *EventBuffer {
+	return &EventBuffer{
+		events:  make(chan interface{}, capacity),
+		policy:  policy,
+		timeout: timeout,
+	}
+}
+
+// Enqueue buffers the event for publishing. When the buffer is full, the oldest buffered event is
+// dropped with EventOverflowDropOldest policy, while the event is dropped with EventOverflowBlock
+// policy if no room is made in the buffer within the timeout. false is returned when the event is dropped.
+func (b *EventBuffer) Enqueue(event interface{}) bool {
+	select {
+	case b.events <- event:
+		return true
+	default:
+	}
+	if b.policy == config.EventOverflowBlock {
+		timer := time.NewTimer(b.timeout)
+		defer timer.Stop()
+		select {
+		case b.events <- event:
+			return true
+		case <-timer.C:
+			b.drop(fmt.Sprintf("no room made in the event buffer within %s", b.timeout))
+			return false
+		}
+	}
+	for {
+		select {
+		case b.events <- event:
+			return true
+		default:
+		}
+		// the publisher can take the oldest event before it is dropped, then there is room for the event
+		select {
+		case <-b.events:
+			b.drop("the event buffer is full, dropped the oldest event")
+		default:
+		}
+	}
+}
+
+func (b *EventBuffer) drop(reason string) {
+	dropped := atomic.AddUint64(&b.dropped, 1)
+	log.Warn(fmt.Sprintf("%s, %d events dropped so far", reason, dropped))
+}
+
+// Dropped returns the number of events dropped since the buffer was created
+func (b *EventBuffer) Dropped() uint64 {
+	return atomic.LoadUint64(&b.dropped)
+}
+
+// Run publishes the buffered events with publish, one at a time, until Close is called
+func (b *EventBuffer) Run(publish func(interface{}) bool) {
+	for event := range b.events {
+		publish(event)
+	}
+}
+
+// Close stops Run once the buffered events are published, Enqueue must not be called afterwards
+func (b *EventBuffer) Close() {
+	close(b.events)
+}
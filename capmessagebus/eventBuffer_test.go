@@ -0,0 +1,86 @@
+//(C) Copyright [2020] Hewlett Packard Enterprise Development LP
+//
+//Licensed under the Apache License, Version 2.0 (the "License"); you may
+//not use this file except in compliance with the License. You may obtain
+//a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+//Unless required by applicable law or agreed to in writing, software
+//distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+//WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+//License for the specific language governing permissions and limitations
+// under the License.
+
+package capmessagebus
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/ODIM-Project/PluginCiscoACI/config"
+)
+
+func TestEventBufferDropOldest(t *testing.T) {
+	buffer := NewEventBuffer(2, config.EventOverflowDropOldest, time.Second)
+	for _, event := range []string{"first", "second", "third", "fourth"} {
+		if !buffer.Enqueue(event) {
+			t.Errorf("Enqueue(%s) = false, want the event buffered", event)
+		}
+	}
+	if dropped := buffer.Dropped(); dropped != 2 {
+		t.Errorf("Dropped() = %d, want 2", dropped)
+	}
+	buffer.Close()
+	var published []interface{}
+	buffer.Run(func(event interface{}) bool {
+		published = append(published, event)
+		return true
+	})
+	if want := []interface{}{"third", "fourth"}; !reflect.DeepEqual(published, want) {
+		t.Errorf("published events = %v, want %v", published, want)
+	}
+}
+
+func TestEventBufferBlock(t *testing.T) {
+	buffer := NewEventBuffer(1, config.EventOverflowBlock, 50*time.Millisecond)
+	if !buffer.Enqueue("first") {
+		t.Fatal("Enqueue(first) = false, want the event buffered")
+	}
+	started := time.Now()
+	if buffer.Enqueue("second") {
+		t.Fatal("Enqueue(second) in full buffer = true, want the event dropped")
+	}
+	if elapsed := time.Since(started); elapsed < 50*time.Millisecond {
+		t.Errorf("Enqueue(second) returned after %s, want it blocked for the timeout", elapsed)
+	}
+	if dropped := buffer.Dropped(); dropped != 1 {
+		t.Errorf("Dropped() = %d, want 1", dropped)
+	}
+
+	// the event is buffered once the publisher makes room within the timeout
+	buffer.timeout = time.Minute
+	published := make(chan interface{}, 2)
+	go buffer.Run(func(event interface{}) bool {
+		published <- event
+		return true
+	})
+	if !buffer.Enqueue("third") {
+		t.Error("Enqueue(third) = false, want the event buffered once published the first")
+	}
+	for _, want := range []string{"first", "third"} {
+		select {
+		case event := <-published:
+			if event != want {
+				t.Errorf("published event = %v, want %s", event, want)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("event %s not published", want)
+		}
+	}
+	buffer.Close()
+	if dropped := buffer.Dropped(); dropped != 1 {
+		t.Errorf("Dropped() = %d, want 1", dropped)
+	}
+}
@@ -17,24 +17,36 @@ package capmessagebus
 
 import (
 	"encoding/json"
+	"errors"
+	"fmt"
 	dc "github.com/ODIM-Project/ODIM/lib-messagebus/datacommunicator"
 	"github.com/ODIM-Project/ODIM/lib-utilities/common"
 	"github.com/ODIM-Project/PluginCiscoACI/config"
 	log "github.com/sirupsen/logrus"
 )
 
+// ErrMessageBusUnavailable is wrapped in the errors of publishing when the message bus can't be reached
+var ErrMessageBusUnavailable = errors.New("message bus unavailable")
+
 // Publish ...
 func Publish(data interface{}) bool {
 	if data == nil {
 		log.Error("Invalid data on publishing events")
 		return false
 	}
-	event := data.(common.Events)
+	if err := PublishEvent(data.(common.Events)); err != nil {
+		log.Error(err.Error())
+		return false
+	}
+	return true
+}
 
+// PublishEvent publishes the event on the message bus, the error wraps ErrMessageBusUnavailable
+// when the message bus can't be reached
+func PublishEvent(event common.Events) error {
 	K, err := dc.Communicator(dc.KAFKA, config.Data.MessageBusConf.MessageQueueConfigFilePath)
 	if err != nil {
-		log.Error("Unable communicate with kafka, got: " + err.Error())
-		return false
+		return fmt.Errorf("%w: unable communicate with kafka, got: %v", ErrMessageBusUnavailable, err)
 	}
 	defer K.Close()
 	// Since we are deleting the first event from the eventlist,
@@ -42,16 +54,27 @@ func Publish(data interface{}) bool {
 	var message common.MessageData
 	err = json.Unmarshal(event.Request, &message)
 	if err != nil {
-		log.Error("Failed to unmarshal the event: " + err.Error())
-		return false
+		return fmt.Errorf("failed to unmarshal the event: %v", err)
 	}
 	topic := config.Data.MessageBusConf.EmbQueue[0]
 	if err := K.Distribute(topic, event); err != nil {
-		log.Error("Unable Publish events to kafka, got: " + err.Error())
-		return false
+		return fmt.Errorf("%w: unable Publish events to kafka, got: %v", ErrMessageBusUnavailable, err)
 	}
 	for _, eventMessage := range message.Events {
 		log.Info("Event " + eventMessage.EventType + " Published\n")
 	}
-	return true
+	return nil
+}
+
+// PublishToTopic publishes the data to the given topic of the message bus
+func PublishToTopic(topic string, data interface{}) error {
+	K, err := dc.Communicator(dc.KAFKA, config.Data.MessageBusConf.MessageQueueConfigFilePath)
+	if err != nil {
+		return fmt.Errorf("unable communicate with kafka, got: %v", err)
+	}
+	defer K.Close()
+	if err := K.Distribute(topic, data); err != nil {
+		return fmt.Errorf("unable to publish to kafka topic %s, got: %v", topic, err)
+	}
+	return nil
 }
@@ -0,0 +1,128 @@
+//(C) Copyright [2020] Hewlett Packard Enterprise Development LP
+//
+//Licensed under the Apache License, Version 2.0 (the "License"); you may
+//not use this file except in compliance with the License. You may obtain
+//a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+//Unless required by applicable law or agreed to in writing, software
+//distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+//WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+//License for the specific language governing permissions and limitations
+// under the License.
+
+//Package capmiddleware ...
+package capmiddleware
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/ODIM-Project/PluginCiscoACI/capmessagebus"
+	"github.com/ODIM-Project/PluginCiscoACI/config"
+	iris "github.com/kataras/iris/v12"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	// principalContextKey is the key of the authenticated principal in the request context
+	principalContextKey = "capmiddleware.principal"
+	// anonymousPrincipal is audited for the requests without an authenticated principal
+	anonymousPrincipal  = "anonymous"
+	auditOutcomeSuccess = "Success"
+	auditOutcomeFailure = "Failure"
+)
+
+// AuditRecord is the record of a write operation done through the plugin
+type AuditRecord struct {
+	Timestamp  string `json:"Timestamp"`
+	Principal  string `json:"Principal"`
+	Method     string `json:"Method"`
+	Resource   string `json:"Resource"`
+	StatusCode int    `json:"StatusCode"`
+	Outcome    string `json:"Outcome"`
+}
+
+// auditFileLock serializes the appends to the audit file, so that the records aren't interleaved
+var auditFileLock sync.Mutex
+
+//Audit records the write operations, the POST, PUT, PATCH and DELETE requests, to the sink
+//configured in AuditConf once they are handled. The records are written regardless of the
+//log level, and the requests are not audited when AuditConf is not set.
+func Audit(ctx iris.Context) {
+	switch ctx.Method() {
+	case http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete:
+	default:
+		ctx.Next()
+		return
+	}
+	auditConf := config.Data.AuditConf
+	if auditConf == nil {
+		ctx.Next()
+		return
+	}
+	ctx.Next()
+	record := AuditRecord{
+		Timestamp:  time.Now().UTC().Format(time.RFC3339Nano),
+		Principal:  Principal(ctx),
+		Method:     ctx.Method(),
+		Resource:   ctx.Path(),
+		StatusCode: ctx.GetStatusCode(),
+		Outcome:    auditOutcomeSuccess,
+	}
+	if record.StatusCode >= http.StatusBadRequest {
+		record.Outcome = auditOutcomeFailure
+	}
+	writeAuditRecord(auditConf, record)
+}
+
+// SetPrincipal stores the authenticated principal of the request
+func SetPrincipal(ctx iris.Context, principal string) {
+	ctx.Values().Set(principalContextKey, principal)
+}
+
+// Principal returns the authenticated principal of the request, anonymous when there isn't one
+func Principal(ctx iris.Context) string {
+	if principal := ctx.Values().GetString(principalContextKey); principal != "" {
+		return principal
+	}
+	return anonymousPrincipal
+}
+
+func writeAuditRecord(auditConf *config.AuditConf, record AuditRecord) {
+	data, err := json.Marshal(record)
+	if err != nil {
+		log.Error("while marshalling audit record, got: " + err.Error())
+		return
+	}
+	switch auditConf.Sink {
+	case config.AuditSinkMessageBus:
+		go func() {
+			if err := capmessagebus.PublishToTopic(auditConf.Topic, record); err != nil {
+				log.Error(fmt.Sprintf("while publishing audit record %s, got: %v", data, err))
+			}
+		}()
+	default:
+		if err := appendAuditFile(auditConf.FilePath, data); err != nil {
+			log.Error(fmt.Sprintf("while writing audit record %s, got: %v", data, err))
+		}
+	}
+}
+
+// appendAuditFile appends the record to the audit file as a line, the file is only ever appended to
+func appendAuditFile(path string, data []byte) error {
+	auditFileLock.Lock()
+	defer auditFileLock.Unlock()
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	_, err = file.Write(append(data, '\n'))
+	return err
+}
@@ -25,53 +25,83 @@ import (
 	"strings"
 )
 
-//BasicAuth is used to validate REST API calls with plugin with basic autherization
+const authRealm = "PluginCiscoACI"
+
+//TokenValidator validates the X-Auth-Token of the sessions created by the plugin and returns the
+//user name of the session, it is set by main as the sessions are kept by caphandler. Tokens are
+//rejected while it is not set.
+var TokenValidator func(token string) (string, bool)
+
+//BasicAuth is used to validate REST API calls with plugin with basic autherization or with
+//the X-Auth-Token of a plugin session. Requests without credentials or with an unknown or expired
+//X-Auth-Token are rejected with 401 and the WWW-Authenticate challenge, so that the clients
+//authenticate again, as ODIM does by recreating its plugin session. Requests with invalid basic
+//credentials are rejected with 403.
 func BasicAuth(ctx iris.Context) {
 	basicAuth := ctx.GetHeader("Authorization")
-	if basicAuth != "" {
-		var username, password string
-		yes := strings.Contains(basicAuth, "Basic")
-		if yes {
-			spl := strings.Split(basicAuth, " ")
-			data, err := base64.StdEncoding.DecodeString(spl[1])
-			if err != nil {
-				log.Error(err.Error())
-				ctx.StatusCode(http.StatusInternalServerError)
-				ctx.WriteString(err.Error())
-				return
-			}
-			userCred := strings.SplitN(string(data), ":", 2)
-			if len(userCred) < 2 {
-				log.Error("unauthorized")
-				ctx.StatusCode(http.StatusUnauthorized)
-				ctx.WriteString("error: not a valid basic auth")
-				return
-			}
-			username = userCred[0]
-			password = userCred[1]
-		} else {
-			log.Error("unauthorized")
-			ctx.StatusCode(http.StatusUnauthorized)
-			ctx.WriteString("error: not a valid basic auth")
-			return
-		}
-		userName := config.Data.PluginConf.UserName
-		passwd := config.Data.PluginConf.Password
-		if username != userName {
-			ctx.StatusCode(http.StatusUnauthorized)
-			ctx.WriteString("Invalid Username/Password")
+	token := ctx.GetHeader("X-Auth-Token")
+	if basicAuth == "" && token == "" {
+		unauthorized(ctx, "no credentials", "authentication required")
+		return
+	}
+	if basicAuth == "" {
+		if TokenValidator == nil {
+			unauthorized(ctx, "invalid/expired X-Auth-Token", "invalid/expired X-Auth-Token")
 			return
 		}
-		hash := sha3.New512()
-		hash.Write([]byte(password))
-		hashSum := hash.Sum(nil)
-		hashedPassword := base64.URLEncoding.EncodeToString(hashSum)
-		if passwd != hashedPassword {
-			ctx.StatusCode(http.StatusUnauthorized)
-			ctx.WriteString("Invalid Username/Password")
+		userName, valid := TokenValidator(token)
+		if !valid {
+			unauthorized(ctx, "invalid/expired X-Auth-Token", "invalid/expired X-Auth-Token")
 			return
 		}
+		SetPrincipal(ctx, userName)
+		ctx.Next()
+		return
+	}
+	var username, password string
+	spl := strings.SplitN(basicAuth, " ", 2)
+	if len(spl) < 2 || spl[0] != "Basic" {
+		forbidden(ctx, "not a valid basic auth", "not a valid basic auth")
+		return
 	}
+	data, err := base64.StdEncoding.DecodeString(spl[1])
+	if err != nil {
+		forbidden(ctx, err.Error(), "not a valid basic auth")
+		return
+	}
+	userCred := strings.SplitN(string(data), ":", 2)
+	if len(userCred) < 2 {
+		forbidden(ctx, "not a valid basic auth", "not a valid basic auth")
+		return
+	}
+	username = userCred[0]
+	password = userCred[1]
+	userName := config.Data.PluginConf.UserName
+	passwd := config.Data.PluginConf.Password
+	hash := sha3.New512()
+	hash.Write([]byte(password))
+	hashSum := hash.Sum(nil)
+	hashedPassword := base64.URLEncoding.EncodeToString(hashSum)
+	if username != userName || passwd != hashedPassword {
+		forbidden(ctx, "invalid username/password", "Invalid Username/Password")
+		return
+	}
+	SetPrincipal(ctx, username)
 	ctx.Next()
+}
+
+//unauthorized rejects the request with 401 and the WWW-Authenticate challenge, reason is logged
+//and message is answered
+func unauthorized(ctx iris.Context, reason, message string) {
+	log.Error("unauthorized: " + reason + " in request " + ctx.Method() + " " + ctx.Path())
+	ctx.Header("WWW-Authenticate", `Basic realm="`+authRealm+`", charset="UTF-8"`)
+	ctx.StatusCode(http.StatusUnauthorized)
+	ctx.WriteString("error: " + message)
+}
 
+//forbidden rejects the request with 403, reason is logged and message is answered
+func forbidden(ctx iris.Context, reason, message string) {
+	log.Error("forbidden: " + reason + " in request " + ctx.Method() + " " + ctx.Path())
+	ctx.StatusCode(http.StatusForbidden)
+	ctx.WriteString("error: " + message)
 }
@@ -0,0 +1,71 @@
+//(C) Copyright [2020] Hewlett Packard Enterprise Development LP
+//
+//Licensed under the Apache License, Version 2.0 (the "License"); you may
+//not use this file except in compliance with the License. You may obtain
+//a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+//Unless required by applicable law or agreed to in writing, software
+//distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+//WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+//License for the specific language governing permissions and limitations
+// under the License.
+
+package capmiddleware
+
+import (
+	"encoding/base64"
+	"net/http"
+	"testing"
+
+	"github.com/ODIM-Project/PluginCiscoACI/config"
+	iris "github.com/kataras/iris/v12"
+	"github.com/kataras/iris/v12/httptest"
+	"golang.org/x/crypto/sha3"
+)
+
+func mockAuthApp(t *testing.T) *httptest.Expect {
+	config.SetUpMockConfig(t)
+	hash := sha3.New512()
+	hash.Write([]byte("Plugin@123"))
+	config.Data.PluginConf.Password = base64.URLEncoding.EncodeToString(hash.Sum(nil))
+	TokenValidator = func(token string) (string, bool) { return "operator", token == "valid-token" }
+	t.Cleanup(func() { TokenValidator = nil })
+	mockApp := iris.New()
+	fabricRoutes := mockApp.Party("/ODIM/v1/Fabrics", BasicAuth)
+	fabricRoutes.Get("/{id}", func(ctx iris.Context) {
+		ctx.StatusCode(http.StatusOK)
+		ctx.WriteString(Principal(ctx))
+	})
+	return httptest.New(t, mockApp)
+}
+
+func TestBasicAuthMissingCredentials(t *testing.T) {
+	e := mockAuthApp(t)
+	resp := e.GET("/ODIM/v1/Fabrics/fabricID").Expect().Status(http.StatusUnauthorized)
+	resp.Header("WWW-Authenticate").Equal(`Basic realm="PluginCiscoACI", charset="UTF-8"`)
+}
+
+func TestBasicAuthInvalidCredentials(t *testing.T) {
+	e := mockAuthApp(t)
+	e.GET("/ODIM/v1/Fabrics/fabricID").WithBasicAuth("admin", "Wrong@123").
+		Expect().Status(http.StatusForbidden).Header("WWW-Authenticate").Empty()
+	e.GET("/ODIM/v1/Fabrics/fabricID").WithBasicAuth("operator", "Plugin@123").
+		Expect().Status(http.StatusForbidden)
+	e.GET("/ODIM/v1/Fabrics/fabricID").WithHeader("Authorization", "Bearer abc").
+		Expect().Status(http.StatusForbidden)
+	e.GET("/ODIM/v1/Fabrics/fabricID").WithHeader("Authorization", "Basic !!!").
+		Expect().Status(http.StatusForbidden)
+	// ODIM recreates its session when an expired token is challenged
+	e.GET("/ODIM/v1/Fabrics/fabricID").WithHeader("X-Auth-Token", "expired-token").
+		Expect().Status(http.StatusUnauthorized).Header("WWW-Authenticate").NotEmpty()
+}
+
+func TestBasicAuthValidCredentials(t *testing.T) {
+	e := mockAuthApp(t)
+	e.GET("/ODIM/v1/Fabrics/fabricID").WithBasicAuth("admin", "Plugin@123").
+		Expect().Status(http.StatusOK).Body().Equal("admin")
+	e.GET("/ODIM/v1/Fabrics/fabricID").WithHeader("X-Auth-Token", "valid-token").
+		Expect().Status(http.StatusOK).Body().Equal("operator")
+}
@@ -0,0 +1,182 @@
+//(C) Copyright [2020] Hewlett Packard Enterprise Development LP
+//
+//Licensed under the Apache License, Version 2.0 (the "License"); you may
+//not use this file except in compliance with the License. You may obtain
+//a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+//Unless required by applicable law or agreed to in writing, software
+//distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+//WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+//License for the specific language governing permissions and limitations
+// under the License.
+
+//Package capmiddleware ...
+package capmiddleware
+
+import (
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/ODIM-Project/PluginCiscoACI/config"
+	"github.com/andybalholm/brotli"
+)
+
+//Compress compresses the responses with the configured algorithm the client prefers in its Accept-Encoding.
+//The responses smaller than CompressionMinSizeInBytes, the HEAD responses and the ones already encoded are
+//sent uncompressed. The configuration is read on every request so that a change of the configuration file
+//is applied without restart.
+func Compress(w http.ResponseWriter, r *http.Request, router http.HandlerFunc) {
+	serverConf := config.Data.ServerConf
+	if serverConf == nil || r.Method == http.MethodHead {
+		router(w, r)
+		return
+	}
+	w.Header().Add("Vary", "Accept-Encoding")
+	encoding := negotiateEncoding(r.Header.Get("Accept-Encoding"), serverConf.CompressionAlgorithms)
+	if encoding == "" {
+		router(w, r)
+		return
+	}
+	cw := &compressWriter{ResponseWriter: w, encoding: encoding, minSize: serverConf.CompressionMinSizeInBytes, statusCode: http.StatusOK}
+	defer cw.close()
+	router(cw, r)
+}
+
+// negotiateEncoding returns the enabled algorithm with the highest quality in the Accept-Encoding header,
+// the first enabled one among those of equal quality. Empty is returned when none of them is accepted.
+func negotiateEncoding(acceptEncoding string, enabled []string) string {
+	qualities := map[string]float64{}
+	for _, accepted := range strings.Split(acceptEncoding, ",") {
+		params := strings.Split(accepted, ";")
+		coding := strings.ToLower(strings.TrimSpace(params[0]))
+		if coding == "" {
+			continue
+		}
+		quality := 1.0
+		for _, param := range params[1:] {
+			if q := strings.TrimSpace(param); strings.HasPrefix(q, "q=") {
+				if value, err := strconv.ParseFloat(q[2:], 64); err == nil {
+					quality = value
+				}
+			}
+		}
+		qualities[coding] = quality
+	}
+	encoding, best := "", 0.0
+	for _, algorithm := range enabled {
+		quality, ok := qualities[algorithm]
+		if !ok {
+			quality = qualities["*"]
+		}
+		if quality > best {
+			encoding, best = algorithm, quality
+		}
+	}
+	return encoding
+}
+
+// compressor is the writer of a compression algorithm
+type compressor interface {
+	io.WriteCloser
+	Flush() error
+}
+
+func newCompressor(encoding string, w io.Writer) compressor {
+	switch encoding {
+	case config.CompressionBrotli:
+		return brotli.NewWriter(w)
+	case config.CompressionDeflate:
+		// flate only fails on an invalid level
+		writer, _ := flate.NewWriter(w, flate.DefaultCompression)
+		return writer
+	}
+	return gzip.NewWriter(w)
+}
+
+// compressWriter buffers the start of the response until it is known whether it reaches minSize,
+// the response is then sent compressed when it does and uncompressed otherwise
+type compressWriter struct {
+	http.ResponseWriter
+	encoding   string
+	minSize    int
+	statusCode int
+	buffer     bytes.Buffer
+	started    bool
+	compressor compressor
+}
+
+// WriteHeader holds the status code until the response is started
+func (w *compressWriter) WriteHeader(statusCode int) {
+	if !w.started {
+		w.statusCode = statusCode
+	}
+}
+
+func (w *compressWriter) Write(p []byte) (int, error) {
+	if !w.started {
+		w.buffer.Write(p)
+		if w.buffer.Len() < w.minSize {
+			return len(p), nil
+		}
+		return len(p), w.start(true)
+	}
+	if w.compressor != nil {
+		return w.compressor.Write(p)
+	}
+	return w.ResponseWriter.Write(p)
+}
+
+// Flush sends the response written so far, a response flushed before reaching minSize, like an
+// event stream, is sent uncompressed
+func (w *compressWriter) Flush() {
+	if !w.started {
+		w.start(false)
+	}
+	if w.compressor != nil {
+		w.compressor.Flush()
+	}
+	if flusher, ok := w.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+// start writes the status code and the buffered response, compressed when compress is set and the
+// response can be compressed
+func (w *compressWriter) start(compress bool) error {
+	w.started = true
+	header := w.ResponseWriter.Header()
+	if compress && w.statusCode != http.StatusNoContent && w.statusCode != http.StatusNotModified && header.Get("Content-Encoding") == "" {
+		header.Set("Content-Encoding", w.encoding)
+		header.Del("Content-Length")
+		w.compressor = newCompressor(w.encoding, w.ResponseWriter)
+	}
+	w.ResponseWriter.WriteHeader(w.statusCode)
+	if w.buffer.Len() == 0 {
+		return nil
+	}
+	var err error
+	if w.compressor != nil {
+		_, err = w.compressor.Write(w.buffer.Bytes())
+	} else {
+		_, err = w.ResponseWriter.Write(w.buffer.Bytes())
+	}
+	w.buffer.Reset()
+	return err
+}
+
+// close sends the response smaller than minSize uncompressed and completes the compressed response
+func (w *compressWriter) close() {
+	if !w.started {
+		w.start(false)
+	}
+	if w.compressor != nil {
+		w.compressor.Close()
+	}
+}
@@ -0,0 +1,124 @@
+//(C) Copyright [2020] Hewlett Packard Enterprise Development LP
+//
+//Licensed under the Apache License, Version 2.0 (the "License"); you may
+//not use this file except in compliance with the License. You may obtain
+//a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+//Unless required by applicable law or agreed to in writing, software
+//distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+//WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+//License for the specific language governing permissions and limitations
+// under the License.
+
+package capmiddleware
+
+import (
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"io"
+	"io/ioutil"
+	"net/http"
+	nethttptest "net/http/httptest"
+	"testing"
+
+	"github.com/ODIM-Project/PluginCiscoACI/config"
+	"github.com/andybalholm/brotli"
+)
+
+func TestNegotiateEncoding(t *testing.T) {
+	tests := []struct {
+		acceptEncoding string
+		enabled        []string
+		want           string
+	}{
+		{"gzip, deflate, br", []string{"gzip", "deflate"}, "gzip"},
+		{"gzip, deflate, br", []string{"br", "gzip"}, "br"},
+		{"gzip;q=0.5, br", []string{"gzip", "br"}, "br"},
+		{"gzip;q=0.5, deflate;q=0.8", []string{"gzip", "deflate"}, "deflate"},
+		{"br", []string{"gzip", "deflate"}, ""},
+		{"gzip;q=0, *", []string{"gzip", "deflate"}, "deflate"},
+		{"*;q=0", []string{"gzip"}, ""},
+		{"identity", []string{"gzip"}, ""},
+		{"", []string{"gzip"}, ""},
+		{"GZIP", []string{"gzip"}, "gzip"},
+	}
+	for _, tt := range tests {
+		if got := negotiateEncoding(tt.acceptEncoding, tt.enabled); got != tt.want {
+			t.Errorf("negotiateEncoding(%q, %v) = %q, want %q", tt.acceptEncoding, tt.enabled, got, tt.want)
+		}
+	}
+}
+
+// compressedResponse returns the response of body served to a client accepting acceptEncoding
+func compressedResponse(body []byte, acceptEncoding string) *nethttptest.ResponseRecorder {
+	recorder := nethttptest.NewRecorder()
+	request := nethttptest.NewRequest(http.MethodGet, "/ODIM/v1/Chassis", nil)
+	request.Header.Set("Accept-Encoding", acceptEncoding)
+	Compress(recorder, request, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		// the body is written in parts smaller than the threshold
+		for i := 0; i < len(body); i += 100 {
+			end := i + 100
+			if end > len(body) {
+				end = len(body)
+			}
+			w.Write(body[i:end])
+		}
+	})
+	return recorder
+}
+
+func TestCompress(t *testing.T) {
+	config.SetUpMockConfig(t)
+	config.Data.ServerConf.CompressionAlgorithms = []string{config.CompressionGzip, config.CompressionDeflate, config.CompressionBrotli}
+	body := bytes.Repeat([]byte(`{"@odata.id":"/ODIM/v1/Chassis/chassisUUID:1"},`), 100)
+
+	decoders := map[string]func(io.Reader) io.Reader{
+		"gzip": func(r io.Reader) io.Reader {
+			reader, err := gzip.NewReader(r)
+			if err != nil {
+				t.Fatalf("gzip.NewReader() error = %v", err)
+			}
+			return reader
+		},
+		"deflate": func(r io.Reader) io.Reader { return flate.NewReader(r) },
+		"br":      func(r io.Reader) io.Reader { return brotli.NewReader(r) },
+	}
+	for encoding, decoder := range decoders {
+		recorder := compressedResponse(body, encoding+", identity;q=0.5")
+		if got := recorder.Header().Get("Content-Encoding"); got != encoding {
+			t.Errorf("Content-Encoding = %q, want %q", got, encoding)
+			continue
+		}
+		if recorder.Body.Len() >= len(body) {
+			t.Errorf("%s response of %d bytes, want less than %d", encoding, recorder.Body.Len(), len(body))
+		}
+		decoded, err := ioutil.ReadAll(decoder(recorder.Body))
+		if err != nil || !bytes.Equal(decoded, body) {
+			t.Errorf("%s response decoded as %q, %v, want the body", encoding, decoded, err)
+		}
+	}
+
+	// the algorithms not enabled are not used
+	config.Data.ServerConf.CompressionAlgorithms = []string{config.CompressionGzip}
+	if got := compressedResponse(body, "br").Header().Get("Content-Encoding"); got != "" {
+		t.Errorf("Content-Encoding with br not enabled = %q, want none", got)
+	}
+
+	// the responses below the threshold are sent uncompressed
+	small := body[:config.Data.ServerConf.CompressionMinSizeInBytes-1]
+	recorder := compressedResponse(small, "gzip")
+	if got := recorder.Header().Get("Content-Encoding"); got != "" || !bytes.Equal(recorder.Body.Bytes(), small) {
+		t.Errorf("response below threshold = %q with Content-Encoding %q, want it uncompressed", recorder.Body.Bytes(), got)
+	}
+	if recorder.Code != http.StatusOK || recorder.Header().Get("Vary") != "Accept-Encoding" {
+		t.Errorf("response below threshold = %d with Vary %q, want 200 varying on Accept-Encoding", recorder.Code, recorder.Header().Get("Vary"))
+	}
+	config.Data.ServerConf.CompressionMinSizeInBytes = len(body) + 1
+	if got := compressedResponse(body, "gzip").Header().Get("Content-Encoding"); got != "" {
+		t.Errorf("Content-Encoding below raised threshold = %q, want none", got)
+	}
+}
@@ -0,0 +1,112 @@
+//(C) Copyright [2020] Hewlett Packard Enterprise Development LP
+//
+//Licensed under the Apache License, Version 2.0 (the "License"); you may
+//not use this file except in compliance with the License. You may obtain
+//a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+//Unless required by applicable law or agreed to in writing, software
+//distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+//WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+//License for the specific language governing permissions and limitations
+// under the License.
+
+//Package capmiddleware ...
+package capmiddleware
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ODIM-Project/PluginCiscoACI/config"
+	iris "github.com/kataras/iris/v12"
+	log "github.com/sirupsen/logrus"
+)
+
+// concurrencyRetryAfter is the time the rejected requests are asked to wait before retrying
+const concurrencyRetryAfter = time.Second
+
+var requestLimiter = newConcurrencyLimiter()
+
+// concurrencyExemptPaths are the long lived streams which would hold a slot for as long as their
+// clients are connected, they are bound by MaxPortEventStreams instead
+var concurrencyExemptPaths = map[string]bool{
+	"/ODIM/v1/PortEvents": true,
+}
+
+//LimitConcurrency bounds the number of requests handled at once to the configured MaxConcurrentRequests.
+//A request beyond the limit waits up to RequestQueueTimeoutInMilliseconds for another one to complete
+//and is answered with 503 and Retry-After when it doesn't. The limit is read from the configuration last
+//loaded on every request so that a change of the configuration file is applied without restart. The event
+//streams are not counted.
+func LimitConcurrency(ctx iris.Context) {
+	serverConf := config.CurrentServerConf()
+	if serverConf == nil || serverConf.MaxConcurrentRequests <= 0 || concurrencyExemptPaths[strings.TrimSuffix(ctx.Path(), "/")] {
+		ctx.Next()
+		return
+	}
+	wait := time.Duration(serverConf.RequestQueueTimeoutInMilliseconds) * time.Millisecond
+	if !requestLimiter.acquire(serverConf.MaxConcurrentRequests, wait) {
+		log.Warn("rejecting request " + ctx.Method() + " " + ctx.Path() + ", " +
+			strconv.Itoa(serverConf.MaxConcurrentRequests) + " requests are already in flight")
+		ctx.Header("Retry-After", strconv.Itoa(int(concurrencyRetryAfter.Seconds())))
+		ctx.StatusCode(http.StatusServiceUnavailable)
+		ctx.WriteString("error: the plugin is handling too many requests, retry later")
+		return
+	}
+	defer requestLimiter.release()
+	ctx.Next()
+}
+
+// concurrencyLimiter counts the requests in flight, unlike a buffered channel the
+// limit is given on every acquire so that it can be changed at any time
+type concurrencyLimiter struct {
+	lock     sync.Mutex
+	inFlight int
+	// released is closed and replaced whenever a request completes, waking up the waiting requests
+	released chan struct{}
+}
+
+func newConcurrencyLimiter() *concurrencyLimiter {
+	return &concurrencyLimiter{released: make(chan struct{})}
+}
+
+// acquire reserves a slot for a request when less than limit are in flight, waiting up to wait
+// for a request to complete otherwise. false is returned when no slot could be reserved.
+func (l *concurrencyLimiter) acquire(limit int, wait time.Duration) bool {
+	deadline := time.Now().Add(wait)
+	for {
+		l.lock.Lock()
+		if l.inFlight < limit {
+			l.inFlight++
+			l.lock.Unlock()
+			return true
+		}
+		released := l.released
+		l.lock.Unlock()
+		remaining := time.Until(deadline)
+		if remaining <= 0 {
+			return false
+		}
+		timer := time.NewTimer(remaining)
+		select {
+		case <-released:
+			timer.Stop()
+		case <-timer.C:
+			return false
+		}
+	}
+}
+
+// release frees the slot of a completed request
+func (l *concurrencyLimiter) release() {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	l.inFlight--
+	close(l.released)
+	l.released = make(chan struct{})
+}
@@ -0,0 +1,96 @@
+//(C) Copyright [2020] Hewlett Packard Enterprise Development LP
+//
+//Licensed under the Apache License, Version 2.0 (the "License"); you may
+//not use this file except in compliance with the License. You may obtain
+//a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+//Unless required by applicable law or agreed to in writing, software
+//distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+//WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+//License for the specific language governing permissions and limitations
+// under the License.
+
+package capmiddleware
+
+import (
+	"net/http"
+	"sync"
+	"testing"
+
+	"github.com/ODIM-Project/PluginCiscoACI/config"
+	iris "github.com/kataras/iris/v12"
+	"github.com/kataras/iris/v12/httptest"
+)
+
+// mockConcurrencyApp serves requests which signal entered and block until unblock is closed
+func mockConcurrencyApp(entered chan<- struct{}, unblock <-chan struct{}) *iris.Application {
+	mockApp := iris.New()
+	mockApp.UseRouter(LimitConcurrency)
+	mockApp.Get("/ODIM/v1/Fabrics", func(ctx iris.Context) {
+		entered <- struct{}{}
+		<-unblock
+		ctx.StatusCode(http.StatusOK)
+	})
+	mockApp.Get("/ODIM/v1/PortEvents", func(ctx iris.Context) {
+		ctx.StatusCode(http.StatusOK)
+	})
+	return mockApp
+}
+
+func TestLimitConcurrency(t *testing.T) {
+	config.SetUpMockConfig(t)
+	config.Data.ServerConf.MaxConcurrentRequests = 2
+	defer func() { config.Data.ServerConf.MaxConcurrentRequests = 0 }()
+	entered := make(chan struct{}, 3)
+	unblock := make(chan struct{})
+	e := httptest.New(t, mockConcurrencyApp(entered, unblock))
+
+	var inFlight sync.WaitGroup
+	for i := 0; i < 2; i++ {
+		inFlight.Add(1)
+		go func() {
+			defer inFlight.Done()
+			e.GET("/ODIM/v1/Fabrics").Expect().Status(http.StatusOK)
+		}()
+		<-entered
+	}
+	// the third concurrent request is rejected without reaching the handler
+	e.GET("/ODIM/v1/Fabrics").Expect().Status(http.StatusServiceUnavailable).Header("Retry-After").Equal("1")
+	// the event streams are not bound by the limit
+	e.GET("/ODIM/v1/PortEvents").Expect().Status(http.StatusOK)
+
+	// with a queue timeout the third request waits for one of the requests in flight
+	config.Data.ServerConf.RequestQueueTimeoutInMilliseconds = 5000
+	defer func() { config.Data.ServerConf.RequestQueueTimeoutInMilliseconds = 0 }()
+	inFlight.Add(1)
+	go func() {
+		defer inFlight.Done()
+		e.GET("/ODIM/v1/Fabrics").Expect().Status(http.StatusOK)
+	}()
+	select {
+	case <-entered:
+		t.Fatal("queued request was handled while the limit was reached")
+	default:
+	}
+	close(unblock)
+	<-entered
+	inFlight.Wait()
+}
+
+func TestLimitConcurrencyChangedLimit(t *testing.T) {
+	limiter := newConcurrencyLimiter()
+	if !limiter.acquire(1, 0) || limiter.acquire(1, 0) {
+		t.Fatal("acquire() doesn't bound the requests to the limit of 1")
+	}
+	// the limit raised by a configuration reload applies to the next request
+	if !limiter.acquire(2, 0) {
+		t.Error("acquire() with the raised limit of 2 was rejected")
+	}
+	limiter.release()
+	limiter.release()
+	if limiter.inFlight != 0 {
+		t.Errorf("requests in flight after release() = %d, want 0", limiter.inFlight)
+	}
+}
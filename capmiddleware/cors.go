@@ -0,0 +1,71 @@
+//(C) Copyright [2020] Hewlett Packard Enterprise Development LP
+//
+//Licensed under the Apache License, Version 2.0 (the "License"); you may
+//not use this file except in compliance with the License. You may obtain
+//a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+//Unless required by applicable law or agreed to in writing, software
+//distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+//WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+//License for the specific language governing permissions and limitations
+// under the License.
+
+//Package capmiddleware ...
+package capmiddleware
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/ODIM-Project/PluginCiscoACI/config"
+	iris "github.com/kataras/iris/v12"
+	log "github.com/sirupsen/logrus"
+)
+
+//CORS applies the configured CORS policy on the request, when CORSConf
+//is not configured the request is passed on without adding any CORS headers
+func CORS(ctx iris.Context) {
+	corsConf := config.Data.CORSConf
+	origin := ctx.GetHeader("Origin")
+	if corsConf == nil || origin == "" {
+		ctx.Next()
+		return
+	}
+	preflight := ctx.Method() == http.MethodOptions && ctx.GetHeader("Access-Control-Request-Method") != ""
+	allowedOrigin, allowed := getAllowedOrigin(corsConf.AllowedOrigins, origin)
+	if !allowed {
+		if preflight {
+			log.Error("CORS preflight request from disallowed origin " + origin)
+			ctx.StatusCode(http.StatusForbidden)
+			return
+		}
+		ctx.Next()
+		return
+	}
+	ctx.Header("Access-Control-Allow-Origin", allowedOrigin)
+	ctx.Header("Vary", "Origin")
+	if corsConf.AllowCredentials {
+		ctx.Header("Access-Control-Allow-Credentials", "true")
+	}
+	if preflight {
+		ctx.Header("Access-Control-Allow-Methods", strings.Join(corsConf.AllowedMethods, ", "))
+		ctx.Header("Access-Control-Allow-Headers", strings.Join(corsConf.AllowedHeaders, ", "))
+		ctx.StatusCode(http.StatusNoContent)
+		return
+	}
+	ctx.Next()
+}
+
+func getAllowedOrigin(allowedOrigins []string, origin string) (string, bool) {
+	for _, allowedOrigin := range allowedOrigins {
+		if allowedOrigin == "*" {
+			return "*", true
+		}
+		if strings.EqualFold(allowedOrigin, origin) {
+			return origin, true
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,68 @@
+//(C) Copyright [2020] Hewlett Packard Enterprise Development LP
+//
+//Licensed under the Apache License, Version 2.0 (the "License"); you may
+//not use this file except in compliance with the License. You may obtain
+//a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+//Unless required by applicable law or agreed to in writing, software
+//distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+//WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+//License for the specific language governing permissions and limitations
+// under the License.
+
+package capmiddleware
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/ODIM-Project/PluginCiscoACI/config"
+	iris "github.com/kataras/iris/v12"
+	"github.com/kataras/iris/v12/httptest"
+)
+
+func mockCORSApp() *iris.Application {
+	mockApp := iris.New()
+	mockApp.UseRouter(CORS)
+	mockApp.Get("/ODIM/v1/Fabrics/{id}/Switches/{switchID}/Ports/{portID}", func(ctx iris.Context) {
+		ctx.StatusCode(http.StatusOK)
+	})
+	return mockApp
+}
+
+func TestCORS(t *testing.T) {
+	config.SetUpMockConfig(t)
+	config.Data.CORSConf = &config.CORSConf{
+		AllowedOrigins: []string{"https://troubleshoot.example.com"},
+		AllowedMethods: []string{"GET", "PATCH"},
+		AllowedHeaders: []string{"Authorization", "Content-Type"},
+	}
+	defer func() { config.Data.CORSConf = nil }()
+	e := httptest.New(t, mockCORSApp())
+	portURI := "/ODIM/v1/Fabrics/f1/Switches/s1/Ports/p1"
+
+	// preflight from an allowed origin
+	resp := e.OPTIONS(portURI).WithHeader("Origin", "https://troubleshoot.example.com").
+		WithHeader("Access-Control-Request-Method", "GET").Expect().Status(http.StatusNoContent)
+	resp.Header("Access-Control-Allow-Origin").Equal("https://troubleshoot.example.com")
+	resp.Header("Access-Control-Allow-Methods").Equal("GET, PATCH")
+	resp.Header("Access-Control-Allow-Headers").Equal("Authorization, Content-Type")
+
+	// preflight from a disallowed origin
+	e.OPTIONS(portURI).WithHeader("Origin", "https://other.example.com").
+		WithHeader("Access-Control-Request-Method", "GET").Expect().Status(http.StatusForbidden)
+
+	// actual request from an allowed origin
+	e.GET(portURI).WithHeader("Origin", "https://troubleshoot.example.com").Expect().
+		Status(http.StatusOK).Header("Access-Control-Allow-Origin").Equal("https://troubleshoot.example.com")
+}
+
+func TestCORSNotConfigured(t *testing.T) {
+	config.SetUpMockConfig(t)
+	config.Data.CORSConf = nil
+	e := httptest.New(t, mockCORSApp())
+	e.GET("/ODIM/v1/Fabrics/f1/Switches/s1/Ports/p1").WithHeader("Origin", "https://troubleshoot.example.com").
+		Expect().Status(http.StatusOK).Header("Access-Control-Allow-Origin").Empty()
+}
@@ -0,0 +1,77 @@
+//(C) Copyright [2020] Hewlett Packard Enterprise Development LP
+//
+//Licensed under the Apache License, Version 2.0 (the "License"); you may
+//not use this file except in compliance with the License. You may obtain
+//a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+//Unless required by applicable law or agreed to in writing, software
+//distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+//WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+//License for the specific language governing permissions and limitations
+// under the License.
+
+//Package capmiddleware ...
+package capmiddleware
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/ODIM-Project/ODIM/lib-utilities/response"
+	"github.com/ODIM-Project/PluginCiscoACI/config"
+	iris "github.com/kataras/iris/v12"
+	log "github.com/sirupsen/logrus"
+)
+
+// maintenanceReadOnlyActions are the actions which only read the fabric, they are
+// served in maintenance mode like the reads
+var maintenanceReadOnlyActions = []string{
+	"/Actions/Oem/CiscoACIFabric.ExportTopology",
+}
+
+//ReadOnlyInMaintenance rejects the write requests with 503 and the Redfish error response while the
+//plugin is configured in maintenance mode, the reads and the read-only actions are served as usual.
+//The mode is read from the configuration on every request so that it is entered and left by a change
+//of the configuration file.
+func ReadOnlyInMaintenance(ctx iris.Context) {
+	serverConf := config.CurrentServerConf()
+	if serverConf == nil || !serverConf.MaintenanceMode || isReadMethod(ctx.Method()) || isReadOnlyAction(ctx.Method(), ctx.Path()) {
+		ctx.Next()
+		return
+	}
+	errMsg := "the plugin is in maintenance mode, write operations are rejected"
+	if serverConf.MaintenanceReason != "" {
+		errMsg += ": " + serverConf.MaintenanceReason
+	}
+	log.Warn("rejecting request " + ctx.Method() + " " + ctx.Path() + " in maintenance mode")
+	args := response.Args{
+		Code: response.GeneralError,
+		ErrorArgs: []response.ErrArgs{
+			{
+				StatusMessage: response.GeneralError,
+				ErrorMessage:  errMsg,
+			},
+		},
+	}
+	ctx.StatusCode(http.StatusServiceUnavailable)
+	ctx.JSON(args.CreateGenericErrorResponse())
+}
+
+func isReadMethod(method string) bool {
+	return method == http.MethodGet || method == http.MethodHead || method == http.MethodOptions
+}
+
+func isReadOnlyAction(method, path string) bool {
+	if method != http.MethodPost {
+		return false
+	}
+	path = strings.TrimSuffix(path, "/")
+	for _, action := range maintenanceReadOnlyActions {
+		if strings.HasSuffix(path, action) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,64 @@
+//(C) Copyright [2020] Hewlett Packard Enterprise Development LP
+//
+//Licensed under the Apache License, Version 2.0 (the "License"); you may
+//not use this file except in compliance with the License. You may obtain
+//a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+//Unless required by applicable law or agreed to in writing, software
+//distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+//WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+//License for the specific language governing permissions and limitations
+// under the License.
+
+package capmiddleware
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/ODIM-Project/PluginCiscoACI/config"
+	iris "github.com/kataras/iris/v12"
+	"github.com/kataras/iris/v12/httptest"
+)
+
+func TestReadOnlyInMaintenance(t *testing.T) {
+	config.SetUpMockConfig(t)
+	handled := 0
+	handler := func(ctx iris.Context) {
+		handled++
+		ctx.StatusCode(http.StatusOK)
+	}
+	mockApp := iris.New()
+	fabricRoutes := mockApp.Party("/ODIM/v1/Fabrics", ReadOnlyInMaintenance)
+	fabricRoutes.Get("/{id}", handler)
+	fabricRoutes.Head("/{id}", handler)
+	fabricRoutes.Patch("/{id}", handler)
+	fabricRoutes.Post("/{id}/Zones", handler)
+	fabricRoutes.Post("/{id}/Actions/Oem/CiscoACIFabric.ExportTopology", handler)
+	fabricRoutes.Post("/{id}/Actions/Oem/CiscoACIFabric.Rebuild", handler)
+	fabricRoutes.Delete("/{id}/Zones/{rid}", handler)
+	e := httptest.New(t, mockApp)
+
+	e.PATCH("/ODIM/v1/Fabrics/fabricID").Expect().Status(http.StatusOK)
+
+	config.Data.ServerConf.MaintenanceMode = true
+	config.Data.ServerConf.MaintenanceReason = "leaf replacement"
+	handled = 0
+	e.GET("/ODIM/v1/Fabrics/fabricID").Expect().Status(http.StatusOK)
+	e.HEAD("/ODIM/v1/Fabrics/fabricID").Expect().Status(http.StatusOK)
+	e.POST("/ODIM/v1/Fabrics/fabricID/Actions/Oem/CiscoACIFabric.ExportTopology").Expect().Status(http.StatusOK)
+	e.PATCH("/ODIM/v1/Fabrics/fabricID").Expect().Status(http.StatusServiceUnavailable).
+		JSON().Path("$.error['@Message.ExtendedInfo'][0].Message").String().Contains("maintenance mode").Contains("leaf replacement")
+	e.POST("/ODIM/v1/Fabrics/fabricID/Zones").Expect().Status(http.StatusServiceUnavailable)
+	e.POST("/ODIM/v1/Fabrics/fabricID/Actions/Oem/CiscoACIFabric.Rebuild").Expect().Status(http.StatusServiceUnavailable)
+	e.DELETE("/ODIM/v1/Fabrics/fabricID/Zones/zoneID").Expect().Status(http.StatusServiceUnavailable)
+	if handled != 3 {
+		t.Errorf("%d requests reached the handlers in maintenance mode, want only the 2 reads and the export", handled)
+	}
+
+	// leaving the maintenance mode by a configuration reload allows the writes again
+	config.Data.ServerConf.MaintenanceMode = false
+	e.PATCH("/ODIM/v1/Fabrics/fabricID").Expect().Status(http.StatusOK)
+}
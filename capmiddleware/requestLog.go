@@ -0,0 +1,72 @@
+//(C) Copyright [2020] Hewlett Packard Enterprise Development LP
+//
+//Licensed under the Apache License, Version 2.0 (the "License"); you may
+//not use this file except in compliance with the License. You may obtain
+//a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+//Unless required by applicable law or agreed to in writing, software
+//distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+//WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+//License for the specific language governing permissions and limitations
+// under the License.
+
+package capmiddleware
+
+import (
+	"sync/atomic"
+
+	"github.com/ODIM-Project/PluginCiscoACI/config"
+	iris "github.com/kataras/iris/v12"
+	log "github.com/sirupsen/logrus"
+)
+
+// requestLoggerKey is the key of the context value holding the logger of the request
+const requestLoggerKey = "requestLogger"
+
+// loggedRequests counts the requests, for selecting the requests whose info logs are written
+var loggedRequests uint64
+
+//SampleLogs sets the logger of the request, RequestLogger, so that only one request in the configured
+//LogSampleRate has its info logs written. The warnings and errors of all the requests are written.
+func SampleLogs(ctx iris.Context) {
+	rate := uint64(1)
+	if serverConf := config.Data.ServerConf; serverConf != nil && serverConf.LogSampleRate > 1 {
+		rate = uint64(serverConf.LogSampleRate)
+	}
+	logger := log.StandardLogger()
+	if (atomic.AddUint64(&loggedRequests, 1)-1)%rate != 0 {
+		logger = warningLogger(logger)
+	}
+	ctx.Values().Set(requestLoggerKey, logger.WithFields(log.Fields{
+		"method": ctx.Method(),
+		"path":   ctx.Path(),
+	}))
+	ctx.Next()
+}
+
+//RequestLogger returns the logger of the request set by SampleLogs, the standard logger
+//is returned for the requests not going through SampleLogs
+func RequestLogger(ctx iris.Context) *log.Entry {
+	if entry, ok := ctx.Values().Get(requestLoggerKey).(*log.Entry); ok {
+		return entry
+	}
+	return log.NewEntry(log.StandardLogger())
+}
+
+// warningLogger returns the logger writing like logger but only from the warning level
+func warningLogger(logger *log.Logger) *log.Logger {
+	level := logger.GetLevel()
+	if level > log.WarnLevel {
+		level = log.WarnLevel
+	}
+	return &log.Logger{
+		Out:          logger.Out,
+		Hooks:        logger.Hooks,
+		Formatter:    logger.Formatter,
+		ReportCaller: logger.ReportCaller,
+		Level:        level,
+		ExitFunc:     logger.ExitFunc,
+	}
+}
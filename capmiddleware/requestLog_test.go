@@ -0,0 +1,69 @@
+//(C) Copyright [2020] Hewlett Packard Enterprise Development LP
+//
+//Licensed under the Apache License, Version 2.0 (the "License"); you may
+//not use this file except in compliance with the License. You may obtain
+//a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+//Unless required by applicable law or agreed to in writing, software
+//distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+//WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+//License for the specific language governing permissions and limitations
+// under the License.
+
+package capmiddleware
+
+import (
+	"bytes"
+	"net/http"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/ODIM-Project/PluginCiscoACI/config"
+	iris "github.com/kataras/iris/v12"
+	"github.com/kataras/iris/v12/httptest"
+	log "github.com/sirupsen/logrus"
+)
+
+func TestSampleLogs(t *testing.T) {
+	config.SetUpMockConfig(t)
+	config.Data.ServerConf.LogSampleRate = 4
+	defer func() { config.Data.ServerConf.LogSampleRate = 1 }()
+	var logs bytes.Buffer
+	log.SetOutput(&logs)
+	defer log.SetOutput(os.Stderr)
+	loggedRequests = 0
+
+	mockApp := iris.New()
+	mockApp.UseRouter(SampleLogs)
+	mockApp.Get("/ODIM/v1/Fabrics", func(ctx iris.Context) {
+		RequestLogger(ctx).Info("request handled")
+		RequestLogger(ctx).Error("request failed")
+		ctx.StatusCode(http.StatusOK)
+	})
+	e := httptest.New(t, mockApp)
+	for i := 0; i < 8; i++ {
+		e.GET("/ODIM/v1/Fabrics").Expect().Status(http.StatusOK)
+	}
+	if infos := strings.Count(logs.String(), "request handled"); infos != 2 {
+		t.Errorf("%d info logs written for 8 requests sampled 1 in 4, want 2", infos)
+	}
+	if errs := strings.Count(logs.String(), "request failed"); errs != 8 {
+		t.Errorf("%d error logs written for 8 requests, want 8", errs)
+	}
+	if !strings.Contains(logs.String(), "path=/ODIM/v1/Fabrics") {
+		t.Errorf("logs %q don't have the path of the request", logs.String())
+	}
+
+	// all the requests are logged by default
+	logs.Reset()
+	config.Data.ServerConf.LogSampleRate = 1
+	for i := 0; i < 3; i++ {
+		e.GET("/ODIM/v1/Fabrics").Expect().Status(http.StatusOK)
+	}
+	if infos := strings.Count(logs.String(), "request handled"); infos != 3 {
+		t.Errorf("%d info logs written for 3 requests, want 3", infos)
+	}
+}
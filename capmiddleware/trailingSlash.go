@@ -0,0 +1,48 @@
+//(C) Copyright [2020] Hewlett Packard Enterprise Development LP
+//
+//Licensed under the Apache License, Version 2.0 (the "License"); you may
+//not use this file except in compliance with the License. You may obtain
+//a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+//Unless required by applicable law or agreed to in writing, software
+//distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+//WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+//License for the specific language governing permissions and limitations
+// under the License.
+
+//Package capmiddleware ...
+package capmiddleware
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/ODIM-Project/PluginCiscoACI/config"
+)
+
+//TrailingSlash matches the request URIs with trailing slashes, like /Ports/1/, to the routes of the URIs
+//without them, as ODIM and the clients send the URIs of the resources in both forms. With the Redirect
+//TrailingSlashPolicy the request is answered with a 308 redirect to the URI without the slashes, which
+//keeps the method and the body of the request, otherwise it is routed as that URI. The policy is read
+//on every request so that a change of the configuration file is applied without restart.
+func TrailingSlash(w http.ResponseWriter, r *http.Request, router http.HandlerFunc) {
+	path := strings.TrimRight(r.URL.Path, "/")
+	if path == r.URL.Path || path == "" {
+		router(w, r)
+		return
+	}
+	if serverConf := config.Data.ServerConf; serverConf != nil && serverConf.TrailingSlashPolicy == config.TrailingSlashRedirect {
+		location := path
+		if r.URL.RawQuery != "" {
+			location += "?" + r.URL.RawQuery
+		}
+		http.Redirect(w, r, location, http.StatusPermanentRedirect)
+		return
+	}
+	r.URL.Path = path
+	r.URL.RawPath = strings.TrimRight(r.URL.RawPath, "/")
+	r.RequestURI = r.URL.RequestURI()
+	router(w, r)
+}
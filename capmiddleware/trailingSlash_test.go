@@ -0,0 +1,57 @@
+//(C) Copyright [2020] Hewlett Packard Enterprise Development LP
+//
+//Licensed under the Apache License, Version 2.0 (the "License"); you may
+//not use this file except in compliance with the License. You may obtain
+//a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+//Unless required by applicable law or agreed to in writing, software
+//distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+//WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+//License for the specific language governing permissions and limitations
+// under the License.
+
+package capmiddleware
+
+import (
+	"net/http"
+	nethttptest "net/http/httptest"
+	"testing"
+
+	"github.com/ODIM-Project/PluginCiscoACI/config"
+)
+
+func TestTrailingSlash(t *testing.T) {
+	config.SetUpMockConfig(t)
+	portURI := "/ODIM/v1/Fabrics/fabricID/Switches/switchUUID:101/Ports/portUUID:eth1-1"
+	var routed *http.Request
+	router := func(w http.ResponseWriter, r *http.Request) { routed = r }
+
+	for _, uri := range []string{portURI, portURI + "/", portURI + "//"} {
+		routed = nil
+		TrailingSlash(nethttptest.NewRecorder(), nethttptest.NewRequest(http.MethodPatch, uri+"?$select=Id", nil), router)
+		if routed == nil || routed.URL.Path != portURI || routed.RequestURI != portURI+"?$select=Id" {
+			t.Errorf("PATCH %s routed as %+v, want %s with the query", uri, routed, portURI)
+		}
+	}
+	TrailingSlash(nethttptest.NewRecorder(), nethttptest.NewRequest(http.MethodGet, "/", nil), router)
+	if routed.URL.Path != "/" {
+		t.Errorf("GET / routed as %s, want /", routed.URL.Path)
+	}
+
+	config.Data.ServerConf.TrailingSlashPolicy = config.TrailingSlashRedirect
+	routed = nil
+	recorder := nethttptest.NewRecorder()
+	TrailingSlash(recorder, nethttptest.NewRequest(http.MethodPatch, portURI+"/?$select=Id", nil), router)
+	if routed != nil {
+		t.Errorf("PATCH %s/ with Redirect policy was routed, want it redirected", portURI)
+	}
+	if recorder.Code != http.StatusPermanentRedirect || recorder.Header().Get("Location") != portURI+"?$select=Id" {
+		t.Errorf("PATCH %s/ with Redirect policy = %d to %s, want 308 to %s", portURI, recorder.Code, recorder.Header().Get("Location"), portURI)
+	}
+	TrailingSlash(nethttptest.NewRecorder(), nethttptest.NewRequest(http.MethodPatch, portURI, nil), router)
+	if routed == nil || routed.URL.Path != portURI {
+		t.Errorf("PATCH %s with Redirect policy routed as %+v, want it routed unchanged", portURI, routed)
+	}
+}
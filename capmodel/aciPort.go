@@ -16,7 +16,10 @@ package capmodel
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
+	"path"
+	"runtime"
 
 	dmtf "github.com/ODIM-Project/ODIM/lib-dmtf/model"
 	"github.com/ODIM-Project/PluginCiscoACI/db"
@@ -49,43 +52,533 @@ type PortInfoIMData struct {
 	PhysicalInterface PhysicalInterface `json:"ethpmPhysIf"`
 }
 
-// GetPort collects the port data from the DB
+// PortStatsHistoryResponse holds the counter history managed objects of a port, keyed by
+// their class like eqptIngrBytesHist5min
+type PortStatsHistoryResponse struct {
+	TotalCount string                                  `json:"totalCount"`
+	IMData     []map[string]PortStatsHistoryManagedObj `json:"imdata"`
+}
+
+// PortStatsHistoryManagedObj ...
+type PortStatsHistoryManagedObj struct {
+	Attributes map[string]interface{} `json:"attributes"`
+}
+
+// PortTransceiverResponse holds the ethpmFcot managed object of the transceiver slot of a port
+type PortTransceiverResponse struct {
+	TotalCount string                  `json:"totalCount"`
+	IMData     []PortTransceiverIMData `json:"imdata"`
+}
+
+// PortTransceiverIMData ...
+type PortTransceiverIMData struct {
+	Transceiver PhysicalInterface `json:"ethpmFcot"`
+}
+
+// PortTransceiver holds the inventory of the transceiver plugged in a port, WavelengthNanometers
+// is 0 when APIC doesn't report the wavelength of the transceiver
+type PortTransceiver struct {
+	Vendor               string
+	PartNumber           string
+	SerialNumber         string
+	Type                 string
+	WavelengthNanometers float64
+}
+
+// PortNeighborsResponse holds the lldpAdjEp or cdpAdjEp managed objects of the neighbors of a port
+type PortNeighborsResponse struct {
+	TotalCount string                `json:"totalCount"`
+	IMData     []PortNeighborsIMData `json:"imdata"`
+}
+
+// PortNeighborsIMData ...
+type PortNeighborsIMData struct {
+	LLDPAdjacency *PhysicalInterface `json:"lldpAdjEp,omitempty"`
+	CDPAdjacency  *PhysicalInterface `json:"cdpAdjEp,omitempty"`
+}
+
+// PortNeighbor holds a device connected to a port as discovered by LLDP or CDP, ChassisID is the
+// device id for CDP which has no chassis id
+type PortNeighbor struct {
+	Protocol   string
+	ChassisID  string
+	PortID     string
+	SystemName string
+}
+
+// PortFaultsResponse holds the faultInst managed objects raised on the ports
+type PortFaultsResponse struct {
+	TotalCount string             `json:"totalCount"`
+	IMData     []PortFaultsIMData `json:"imdata"`
+}
+
+// PortFaultsIMData ...
+type PortFaultsIMData struct {
+	Fault PortStatsHistoryManagedObj `json:"faultInst"`
+}
+
+// PortFault holds a fault raised by APIC on a port, Created is in the format reported by APIC
+type PortFault struct {
+	Code        string
+	Severity    string
+	Description string
+	Created     string
+}
+
+// PortStatsSample holds the traffic of a port over one interval of its statistics history
+type PortStatsSample struct {
+	IntervalStart string
+	IntervalEnd   string
+	RXBytes       uint64
+	TXBytes       uint64
+}
+
+// PortCounters holds the traffic of a port over the last 5 minute interval of APIC, the errors are
+// the packets dropped for errors and IntervalEnd is in the format reported by APIC
+type PortCounters struct {
+	IntervalEnd string
+	RXBytes     uint64
+	TXBytes     uint64
+	RXErrors    uint64
+	TXErrors    uint64
+}
+
+// GetPort collects the port data from the cache, or from the DB when not cached
 func GetPort(portID string) (*dmtf.Port, error) {
 	var port dmtf.Port
-	data, err := db.Connector.Get(db.TablePort, portID)
+	data, err := getPortRaw(portID)
 	if err != nil {
-		return nil, fmt.Errorf("while trying to collect port data, got: %w", err)
+		return nil, err
 	}
-	if err = json.Unmarshal([]byte(data), &port); err != nil {
+	if err := json.Unmarshal([]byte(data), &port); err != nil {
 		return nil, fmt.Errorf("while trying to unmarshal port data, got: %v", err)
 	}
 	return &port, nil
 }
 
-// GetSwitchPort collects the switch-port data from the DB
+// GetPortRaw collects the port data as stored in the DB, from the cache or from the DB when not
+// cached. The stored bytes are the source of truth for the entity tag of the port, as the port
+// decoded and encoded again isn't guaranteed to match them, the handlers which only pass the port
+// through use them as well. The handlers modifying the port use GetPort.
+func GetPortRaw(portID string) ([]byte, error) {
+	data, err := getPortRaw(portID)
+	if err != nil {
+		return nil, err
+	}
+	return []byte(data), nil
+}
+
+func getPortRaw(portID string) (string, error) {
+	if data, ok := getCachedPort(portID); ok {
+		return data, nil
+	}
+	generation := portCacheGeneration()
+	data, err := dbGet(db.TablePort, portID)
+	if err != nil {
+		return "", fmt.Errorf("while trying to collect port data, got: %w", err)
+	}
+	cachePort(portID, data, generation)
+	return data, nil
+}
+
+// GetSwitchPort collects the switch-port data from the DB. An empty list is returned for a switch
+// stored without ports, ErrorKeyNotFound is returned only when the switch itself is not stored.
 func GetSwitchPort(switchID string) ([]string, error) {
-	var port []string
-	data, err := db.Connector.Get(db.TableSwitchPorts, switchID)
+	port := []string{}
+	data, err := dbGet(db.TableSwitchPorts, switchID)
+	if errors.Is(err, db.ErrorKeyNotFound) {
+		exists, existsErr := SwitchExists(switchID)
+		if existsErr != nil {
+			return nil, existsErr
+		}
+		if exists {
+			return port, nil
+		}
+	}
 	if err != nil {
 		return nil, fmt.Errorf("while trying to collect port data, got: %w", err)
 	}
 	if err = json.Unmarshal([]byte(data), &port); err != nil {
 		return nil, fmt.Errorf("while trying to unmarshal port data, got: %v", err)
 	}
+	if port == nil {
+		port = []string{}
+	}
 	return port, nil
 }
 
+// UniqueSwitchPorts returns the switch-port data without the ports listed more than once, like
+// after an interrupted discovery, in the order they are first listed
+func UniqueSwitchPorts(ports []string) []string {
+	seen := make(map[string]bool, len(ports))
+	unique := make([]string, 0, len(ports))
+	for _, portID := range ports {
+		if !seen[portID] {
+			seen[portID] = true
+			unique = append(unique, portID)
+		}
+	}
+	return unique
+}
+
+// RemoveDuplicateSwitchPorts removes the ports listed more than once from the switch-port data
+// stored in the DB, the number of duplicates removed is returned. The data is written back only
+// if it was not modified in between.
+func RemoveDuplicateSwitchPorts(switchID string) (int, error) {
+	for i := 0; i < maxPortUpdateRetries; i++ {
+		data, err := dbGet(db.TableSwitchPorts, switchID)
+		if errors.Is(err, db.ErrorKeyNotFound) {
+			return 0, nil
+		}
+		if err != nil {
+			return 0, fmt.Errorf("while trying to collect switch-port data, got: %w", err)
+		}
+		var ports []string
+		if err := json.Unmarshal([]byte(data), &ports); err != nil {
+			return 0, fmt.Errorf("while trying to unmarshal switch-port data, got: %v", err)
+		}
+		unique := UniqueSwitchPorts(ports)
+		if len(unique) == len(ports) {
+			return 0, nil
+		}
+		updatedData, err := json.Marshal(unique)
+		if err != nil {
+			return 0, fmt.Errorf("while trying to marshal switch-port data, got: %v", err)
+		}
+		swapped, err := db.Connector.CompareAndSwap(db.TableSwitchPorts, switchID, data, string(updatedData))
+		if err != nil {
+			return 0, fmt.Errorf("while trying to update switch-port data, got: %w", err)
+		}
+		if swapped {
+			return len(ports) - len(unique), nil
+		}
+		runtime.Gosched()
+	}
+	return 0, fmt.Errorf("while trying to update switch-port data, got: switch %s is being modified concurrently", switchID)
+}
+
 // SavePort stores the port data in the DB
 func SavePort(portID string, data *dmtf.Port) error {
+	defer portWritten(portID)
 	return SaveToDB(db.TablePort, portID, *data)
 }
 
+// PortExistenceCheck reports whether the port with the given APIC port id is still present in APIC
+type PortExistenceCheck func(portID string) (bool, error)
+
+// SavePortIfExists stores the port data in the DB after verifying with check that the port is
+// still present in APIC, the write is skipped and false is returned when the port has vanished.
+// When check is nil the port is stored without verification.
+func SavePortIfExists(portOID string, data *dmtf.Port, check PortExistenceCheck) (bool, error) {
+	if exists, err := verifyPortExists(data, check); err != nil || !exists {
+		return false, err
+	}
+	if err := SavePort(portOID, data); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+func verifyPortExists(data *dmtf.Port, check PortExistenceCheck) (bool, error) {
+	if check == nil {
+		return true, nil
+	}
+	exists, err := check(data.PortID)
+	if err != nil {
+		return false, fmt.Errorf("while trying to verify presence of port %s, got: %v", data.PortID, err)
+	}
+	return exists, nil
+}
+
+// AddPortToSwitch stores the port data under its OID and adds the port to the switch-port data
+// in a single transaction, so that a port is never stored without being in the ports of its
+// switch, nor the other way around
+func AddPortToSwitch(switchID string, data *dmtf.Port) error {
+	ports, err := GetSwitchPort(switchID)
+	if err != nil {
+		return err
+	}
+	var writes []db.Write
+	if writes, err = updateWrite(writes, db.TablePort, data.ODataID, *data); err != nil {
+		return err
+	}
+	if !containsString(ports, data.ID) {
+		if writes, err = updateWrite(writes, db.TableSwitchPorts, switchID, append(ports, data.ID)); err != nil {
+			return err
+		}
+	}
+	writes = append(writes, db.Write{KeySet: fmt.Sprintf("%s:%s", db.TableSwitchPortSet, switchID), Member: data.ID})
+	defer portWritten(data.ODataID)
+	if err := db.Connector.Transaction(writes); err != nil {
+		return fmt.Errorf("while trying to add port %s to switch %s, got: %w", data.ODataID, switchID, err)
+	}
+	return nil
+}
+
+// AddPortToSwitchIfExists adds the port to the switch like AddPortToSwitch after verifying with check
+// that the port is still present in APIC, like SavePortIfExists
+func AddPortToSwitchIfExists(switchID string, data *dmtf.Port, check PortExistenceCheck) (bool, error) {
+	if exists, err := verifyPortExists(data, check); err != nil || !exists {
+		return false, err
+	}
+	if err := AddPortToSwitch(switchID, data); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
 // SaveSwitchPort stores the switch-port data in the DB
 func SaveSwitchPort(switchID string, data []string) error {
-	return SaveToDB(db.TableSwitchPorts, switchID, data)
+	if err := SaveToDB(db.TableSwitchPorts, switchID, data); err != nil {
+		return err
+	}
+	keySet := fmt.Sprintf("%s:%s", db.TableSwitchPortSet, switchID)
+	for _, portID := range data {
+		if err := db.Connector.UpdateKeySet(keySet, portID); err != nil {
+			return fmt.Errorf("while trying to update switch-port key set members, got: %v", err)
+		}
+	}
+	return nil
+}
+
+// AddSwitchPort adds the port to the switch-port data stored in the DB
+func AddSwitchPort(switchID, portID string) error {
+	ports, err := GetSwitchPort(switchID)
+	if err != nil {
+		return err
+	}
+	if containsString(ports, portID) {
+		return nil
+	}
+	if err := UpdateDbData(db.TableSwitchPorts, switchID, append(ports, portID)); err != nil {
+		return fmt.Errorf("while trying to update switch-port data, got: %w", err)
+	}
+	keySet := fmt.Sprintf("%s:%s", db.TableSwitchPortSet, switchID)
+	if err := db.Connector.UpdateKeySet(keySet, portID); err != nil {
+		return fmt.Errorf("while trying to update switch-port key set members, got: %v", err)
+	}
+	return nil
+}
+
+// DeletePort removes the port data, its settings and state and the port from the switch-port data
+// stored in the DB in a single transaction, so that none of them is left behind on a failure
+func DeletePort(switchID, portOID string) error {
+	ports, err := GetSwitchPort(switchID)
+	if err != nil {
+		return err
+	}
+	state, err := GetPortState(portOID)
+	if err != nil && !errors.Is(err, db.ErrorKeyNotFound) {
+		return err
+	}
+	portData, err := getOptionalDocument(db.TablePort, portOID)
+	if err != nil {
+		return err
+	}
+	portID := path.Base(portOID)
+	var remainingPorts = []string{}
+	for _, id := range ports {
+		if id != portID {
+			remainingPorts = append(remainingPorts, id)
+		}
+	}
+	var writes []db.Write
+	if writes, err = updateWrite(writes, db.TableSwitchPorts, switchID, remainingPorts); err != nil {
+		return err
+	}
+	writes = append(writes,
+		db.Write{KeySet: fmt.Sprintf("%s:%s", db.TableSwitchPortSet, switchID), Member: portID, Delete: true},
+		db.Write{Table: db.TablePort, ResourceID: portOID, Delete: true},
+		db.Write{Table: db.TablePortSettings, ResourceID: portOID, Delete: true},
+		db.Write{Table: db.TablePortState, ResourceID: portOID, Delete: true},
+	)
+	if state.Health != "" {
+		writes = append(writes, db.Write{KeySet: portHealthSet(state.Health), Member: portOID, Delete: true})
+	}
+	for _, uri := range connectedEthernetsOf(portData) {
+		writes = append(writes, db.Write{KeySet: portLinkSet(uri), Member: portOID, Delete: true})
+	}
+	defer portWritten(portOID)
+	if err := db.Connector.Transaction(writes); err != nil {
+		return fmt.Errorf("while trying to remove port %s, got: %w", portOID, err)
+	}
+	return nil
+}
+
+func containsString(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
+
+// DeleteSwitchPorts removes all the ports of the switch along with the switch-port data
+// stored in the DB, the number of ports removed is returned
+func DeleteSwitchPorts(fabricID, switchID string) (int, error) {
+	ports, err := GetSwitchPort(switchID)
+	if err != nil {
+		return 0, err
+	}
+	keySet := fmt.Sprintf("%s:%s", db.TableSwitchPortSet, switchID)
+	for i, portID := range ports {
+		portOID := fmt.Sprintf("/ODIM/v1/Fabrics/%s/Switches/%s/Ports/%s", fabricID, switchID, portID)
+		err := db.Connector.Delete(db.TablePort, portOID)
+		portWritten(portOID)
+		if err != nil {
+			return i, fmt.Errorf("while trying to remove port data, got: %w", err)
+		}
+		if err := DeletePortState(portOID); err != nil {
+			return i + 1, err
+		}
+		if err := DeletePortSettings(portOID); err != nil {
+			return i + 1, err
+		}
+		if err := db.Connector.DeleteKeySetMembers(keySet, portID); err != nil {
+			return i + 1, fmt.Errorf("while trying to remove member from switch-port key set, got: %v", err)
+		}
+	}
+	if err := db.Connector.Delete(db.TableSwitchPorts, switchID); err != nil {
+		return len(ports), fmt.Errorf("while trying to remove switch-port data, got: %w", err)
+	}
+	return len(ports), nil
+}
+
+// CountPorts returns the number of ports stored for the switch, without reading the switch-port data
+func CountPorts(switchID string) (int, error) {
+	keySet := fmt.Sprintf("%s:%s", db.TableSwitchPortSet, switchID)
+	count, err := dbGetKeySetCount(keySet)
+	if err != nil {
+		return 0, fmt.Errorf("while trying to count ports, got: %w", err)
+	}
+	if count > 0 {
+		return count, nil
+	}
+	// key set is empty either for a switch without ports, for a switch which is
+	// not present or for the data stored before the key set was maintained
+	ports, err := GetSwitchPort(switchID)
+	if err != nil {
+		return 0, err
+	}
+	return len(ports), nil
 }
 
 // UpdatePort updates the port data stored in the DB
 func UpdatePort(portID string, data *dmtf.Port) error {
+	defer portWritten(portID)
 	return UpdateDbData(db.TablePort, portID, *data)
 }
+
+// maxPortUpdateRetries is the number of times a partial port update is retried
+// when the port data is modified concurrently
+const maxPortUpdateRetries = 10
+
+// UpdatePortFields merges the given top level fields into the port data stored in the DB.
+// The stored data is read, merged and written back only if it was not modified in between,
+// so the concurrent updates of disjoint fields do not overwrite each other.
+func UpdatePortFields(portID string, fields map[string]interface{}) error {
+	_, err := updatePortDocument(portID, func(port map[string]interface{}) error {
+		for key, value := range fields {
+			port[key] = value
+		}
+		return nil
+	})
+	return err
+}
+
+// FieldNotWritableError is returned when a patch modifies a field of the port which isn't writable
+type FieldNotWritableError struct {
+	Field string
+}
+
+func (e *FieldNotWritableError) Error() string {
+	return fmt.Sprintf("property %s of port is not writable", e.Field)
+}
+
+// MergePatchPort applies the JSON merge patch (RFC 7386) on the port data stored in the DB: a null
+// value removes the field, an object is merged into the stored one and any other value replaces the
+// stored one. The patch is rejected with FieldNotWritableError when it modifies a top level field
+// which isn't writable. The port is updated like UpdatePortFields and the merged port is returned.
+func MergePatchPort(portID string, patch map[string]interface{}, writable func(field string) bool) (*dmtf.Port, error) {
+	data, err := updatePortDocument(portID, func(port map[string]interface{}) error {
+		for key, value := range patch {
+			stored, _ := json.Marshal(port[key])
+			merged := mergePatch(port[key], value)
+			if updated, _ := json.Marshal(merged); string(updated) != string(stored) && !writable(key) {
+				return &FieldNotWritableError{Field: key}
+			}
+			if merged == nil {
+				delete(port, key)
+				continue
+			}
+			port[key] = merged
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	var port dmtf.Port
+	if err = json.Unmarshal(data, &port); err != nil {
+		return nil, fmt.Errorf("while trying to unmarshal port data, got: %v", err)
+	}
+	return &port, nil
+}
+
+// mergePatch returns the target value with the merge patch applied, nil when the patch removes it
+func mergePatch(target, patch interface{}) interface{} {
+	patchObject, ok := patch.(map[string]interface{})
+	if !ok {
+		return patch
+	}
+	targetObject, ok := target.(map[string]interface{})
+	if !ok {
+		targetObject = map[string]interface{}{}
+	}
+	for key, value := range patchObject {
+		if merged := mergePatch(targetObject[key], value); merged != nil {
+			targetObject[key] = merged
+		} else {
+			delete(targetObject, key)
+		}
+	}
+	return targetObject
+}
+
+// updatePortDocument applies update on the port data stored in the DB and writes it back only if it
+// was not modified in between, the update is retried on the data read again otherwise. The link index
+// of the ethernet interfaces the port is connected to is updated in the same transaction. The updated
+// port data is returned.
+func updatePortDocument(portID string, update func(port map[string]interface{}) error) ([]byte, error) {
+	for i := 0; i < maxPortUpdateRetries; i++ {
+		data, err := dbGet(db.TablePort, portID)
+		if err != nil {
+			return nil, fmt.Errorf("while trying to collect port data, got: %w", err)
+		}
+		var port map[string]interface{}
+		if err = json.Unmarshal([]byte(data), &port); err != nil {
+			return nil, fmt.Errorf("while trying to unmarshal port data, got: %v", err)
+		}
+		previousLinks := connectedEthernets(port)
+		if err = update(port); err != nil {
+			return nil, err
+		}
+		updatedData, err := json.Marshal(port)
+		if err != nil {
+			return nil, fmt.Errorf("while trying to marshal port data, got: %v", err)
+		}
+		writes := append([]db.Write{{Table: db.TablePort, ResourceID: portID, Data: string(updatedData), Compare: true, Previous: data}},
+			portLinkWrites(portID, previousLinks, connectedEthernetsOf(updatedData))...)
+		err = db.Connector.Transaction(writes)
+		if err == nil {
+			portWritten(portID)
+			return updatedData, nil
+		}
+		if !errors.Is(err, db.ErrorEntryModified) {
+			return nil, fmt.Errorf("while trying to update port data, got: %w", err)
+		}
+		runtime.Gosched()
+	}
+	return nil, fmt.Errorf("while trying to update port data, got: port %s is being modified concurrently", portID)
+}
@@ -15,15 +15,21 @@
 package capmodel
 
 import (
+	"encoding/json"
+	"errors"
 	"reflect"
+	"strconv"
+	"sync"
 	"testing"
 
 	dmtf "github.com/ODIM-Project/ODIM/lib-dmtf/model"
+	"github.com/ODIM-Project/PluginCiscoACI/config"
 	"github.com/ODIM-Project/PluginCiscoACI/db"
 )
 
 func TestGetPort(t *testing.T) {
 	db.Connector = db.MockConnector{}
+	InvalidatePortCache()
 	type args struct {
 		portID string
 	}
@@ -65,3 +71,437 @@ func TestGetPort(t *testing.T) {
 		})
 	}
 }
+
+func TestGetPortKeyPrefix(t *testing.T) {
+	config.SetUpMockConfig(t)
+	config.Data.DBConf.KeyPrefix = "prod:aci:"
+	defer func() { config.Data.DBConf.KeyPrefix = "" }()
+	connector := db.NewMockMemoryConnector()
+	db.Connector = connector
+	portOID := "/ODIM/v1/Fabrics/fabricID/Switches/switchUUID:101/Ports/portUUID:eth1-1"
+	if err := SavePort(portOID, &dmtf.Port{ID: "portUUID:eth1-1", PortID: "eth1-1"}); err != nil {
+		t.Fatalf("SavePort() error = %v", err)
+	}
+	if err := SaveSwitchPort("switchUUID:101", []string{portOID}); err != nil {
+		t.Fatalf("SaveSwitchPort() error = %v", err)
+	}
+	want := []string{
+		"prod:aci:" + db.TablePort + ":" + portOID,
+		"prod:aci:" + db.TableSwitchPortSet + ":switchUUID:101",
+		"prod:aci:" + db.TableSwitchPorts + ":switchUUID:101",
+	}
+	if got := connector.Keys(); !reflect.DeepEqual(got, want) {
+		t.Errorf("stored keys = %v, want %v", got, want)
+	}
+	port, err := GetPort(portOID)
+	if err != nil {
+		t.Fatalf("GetPort() error = %v", err)
+	}
+	if port.ID != "portUUID:eth1-1" || port.PortID != "eth1-1" {
+		t.Errorf("GetPort() = %+v", port)
+	}
+	if count, err := CountPorts("switchUUID:101"); err != nil || count != 1 {
+		t.Errorf("CountPorts() = %d, %v, want 1", count, err)
+	}
+	if keys, err := db.Connector.GetAllMatchingKeys(db.TablePort, ""); err != nil || !reflect.DeepEqual(keys, []string{portOID}) {
+		t.Errorf("GetAllMatchingKeys() = %v, %v, want the unprefixed port OID", keys, err)
+	}
+
+	// the keys of another prefix are not read
+	config.Data.DBConf.KeyPrefix = "test:aci:"
+	InvalidatePortCache()
+	if _, err := GetPort(portOID); !errors.Is(err, db.ErrorKeyNotFound) {
+		t.Errorf("GetPort() under another prefix error = %v, want ErrorKeyNotFound", err)
+	}
+}
+
+func TestGetPortRaw(t *testing.T) {
+	db.Connector = db.NewMockMemoryConnector()
+	InvalidatePortCache()
+	portOID := "/ODIM/v1/Fabrics/fabricID/Switches/switchUUID:101/Ports/portUUID:eth1-1"
+	port := &dmtf.Port{ID: "portUUID:eth1-1", PortID: "eth1-1", Name: "eth1/1"}
+	if err := SavePort(portOID, port); err != nil {
+		t.Fatalf("SavePort() error = %v", err)
+	}
+	want, _ := json.Marshal(*port)
+	// read from the DB and then from the cache
+	for i := 0; i < 2; i++ {
+		if got, err := GetPortRaw(portOID); err != nil || string(got) != string(want) {
+			t.Errorf("GetPortRaw() = %s, %v, want %s", got, err, want)
+		}
+	}
+
+	// the bytes stored are returned as is, even when encoding the port again differs
+	stored := `{ "PortId": "eth1-1",  "Id": "portUUID:eth1-1" }`
+	if err := db.Connector.Update(db.TablePort, portOID, stored); err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+	InvalidatePort(portOID)
+	for i := 0; i < 2; i++ {
+		if got, err := GetPortRaw(portOID); err != nil || string(got) != stored {
+			t.Errorf("GetPortRaw() = %s, %v, want %s", got, err, stored)
+		}
+	}
+
+	if _, err := GetPortRaw("invalidID"); !errors.Is(err, db.ErrorKeyNotFound) {
+		t.Errorf("GetPortRaw() of absent port error = %v, want ErrorKeyNotFound", err)
+	}
+}
+
+func TestGetSwitchPortEmpty(t *testing.T) {
+	db.Connector = db.NewMockMemoryConnector()
+	if err := SaveSwitch("switchUUID:101", &dmtf.Switch{ID: "switchUUID:101"}); err != nil {
+		t.Fatalf("SaveSwitch() error = %v", err)
+	}
+	// switch stored without ports
+	ports, err := GetSwitchPort("switchUUID:101")
+	if err != nil || ports == nil || len(ports) != 0 {
+		t.Errorf("GetSwitchPort() of switch without ports = %v, %v, want empty list", ports, err)
+	}
+	if err := SaveSwitchPort("switchUUID:101", nil); err != nil {
+		t.Fatalf("SaveSwitchPort() error = %v", err)
+	}
+	if ports, err = GetSwitchPort("switchUUID:101"); err != nil || ports == nil || len(ports) != 0 {
+		t.Errorf("GetSwitchPort() of switch with empty ports = %v, %v, want empty list", ports, err)
+	}
+	if count, err := CountPorts("switchUUID:101"); err != nil || count != 0 {
+		t.Errorf("CountPorts() of switch without ports = %d, %v, want 0", count, err)
+	}
+	// switch not stored
+	if _, err := GetSwitchPort("switchUUID:102"); !errors.Is(err, db.ErrorKeyNotFound) {
+		t.Errorf("GetSwitchPort() of absent switch error = %v, want ErrorKeyNotFound", err)
+	}
+}
+
+func TestCountPorts(t *testing.T) {
+	db.Connector = db.NewMockMemoryConnector()
+	switchID := "switchUUID:101"
+	portsURI := "/ODIM/v1/Fabrics/fabricID/Switches/" + switchID + "/Ports/"
+
+	if _, err := CountPorts(switchID); !errors.Is(err, db.ErrorKeyNotFound) {
+		t.Errorf("CountPorts() error = %v, want ErrorKeyNotFound for a missing switch", err)
+	}
+	if err := SaveSwitchPort(switchID, []string{"p1", "p2"}); err != nil {
+		t.Fatalf("SaveSwitchPort() error = %v", err)
+	}
+	for _, portID := range []string{"p1", "p2", "p3"} {
+		SavePort(portsURI+portID, &dmtf.Port{ID: portID})
+	}
+	steps := []struct {
+		name string
+		op   func() error
+		want int
+	}{
+		{"after save", func() error { return nil }, 2},
+		{"add new port", func() error { return AddSwitchPort(switchID, "p3") }, 3},
+		{"add existing port", func() error { return AddSwitchPort(switchID, "p3") }, 3},
+		{"delete port", func() error { return DeletePort(switchID, portsURI+"p1") }, 2},
+		{"delete all ports", func() error {
+			if err := DeletePort(switchID, portsURI+"p2"); err != nil {
+				return err
+			}
+			return DeletePort(switchID, portsURI+"p3")
+		}, 0},
+		{"add port again", func() error { return AddSwitchPort(switchID, "p1") }, 1},
+	}
+	for _, step := range steps {
+		if err := step.op(); err != nil {
+			t.Fatalf("%s: error = %v", step.name, err)
+		}
+		got, err := CountPorts(switchID)
+		if err != nil {
+			t.Fatalf("%s: CountPorts() error = %v", step.name, err)
+		}
+		ports, _ := GetSwitchPort(switchID)
+		if got != step.want || len(ports) != step.want {
+			t.Errorf("%s: CountPorts() = %d, stored ports = %d, want %d", step.name, got, len(ports), step.want)
+		}
+	}
+}
+
+func TestSavePortIfExists(t *testing.T) {
+	present := func(portID string) (bool, error) { return true, nil }
+	vanished := func(portID string) (bool, error) { return false, nil }
+	unreachable := func(portID string) (bool, error) { return false, errors.New("APIC unreachable") }
+	tests := []struct {
+		name      string
+		check     PortExistenceCheck
+		wantSaved bool
+		wantErr   bool
+	}{
+		{name: "port present in APIC", check: present, wantSaved: true},
+		{name: "port vanished from APIC", check: vanished, wantSaved: false},
+		{name: "APIC check failed", check: unreachable, wantSaved: false, wantErr: true},
+		{name: "no check", check: nil, wantSaved: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db.Connector = db.NewMockMemoryConnector()
+			InvalidatePortCache()
+			portOID := "/ODIM/v1/Fabrics/fabricID/Switches/switchUUID:101/Ports/portUUID:eth1-1"
+			saved, err := SavePortIfExists(portOID, &dmtf.Port{ID: "portUUID:eth1-1", PortID: "eth1/1"}, tt.check)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("SavePortIfExists() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if saved != tt.wantSaved {
+				t.Errorf("SavePortIfExists() = %v, want %v", saved, tt.wantSaved)
+			}
+			if _, err := GetPort(portOID); (err == nil) != tt.wantSaved {
+				t.Errorf("GetPort() error = %v, port stored should be %v", err, tt.wantSaved)
+			}
+		})
+	}
+}
+
+// transactionConnector fails the writes made outside of a transaction, and the transactions as well
+// when failTransaction is set, like the DB going away in the middle of an operation
+type transactionConnector struct {
+	db.MockMemoryConnector
+	failTransaction *bool
+}
+
+var errWriteFailed = errors.New("connection reset")
+
+func (d transactionConnector) Create(table, resourceID, data string) error { return errWriteFailed }
+func (d transactionConnector) Update(table, resourceID, data string) error { return errWriteFailed }
+func (d transactionConnector) Delete(table, resourceID string) error       { return errWriteFailed }
+func (d transactionConnector) UpdateKeySet(key, member string) error       { return errWriteFailed }
+func (d transactionConnector) DeleteKeySetMembers(key, member string) error {
+	return errWriteFailed
+}
+
+func (d transactionConnector) Transaction(writes []db.Write) error {
+	if *d.failTransaction {
+		return errWriteFailed
+	}
+	return d.MockMemoryConnector.Transaction(writes)
+}
+
+func TestAddPortToSwitchAtomic(t *testing.T) {
+	switchID := "switchUUID:101"
+	portOID := "/ODIM/v1/Fabrics/fabricID/Switches/switchUUID:101/Ports/portUUID:eth1-1"
+	port := &dmtf.Port{ODataID: portOID, ID: "portUUID:eth1-1", PortID: "eth1/1"}
+	memory := db.NewMockMemoryConnector()
+	db.Connector = memory
+	InvalidatePortCache()
+	if err := SaveSwitch(switchID, &dmtf.Switch{ID: switchID}); err != nil {
+		t.Fatalf("SaveSwitch() error = %v", err)
+	}
+	failTransaction := true
+	faulty := transactionConnector{MockMemoryConnector: memory, failTransaction: &failTransaction}
+	db.Connector = faulty
+	// stored is whether the port document and the port in the switch-port data are stored, both or neither
+	stored := func() (bool, bool) {
+		db.Connector = memory
+		defer func() { db.Connector = faulty }()
+		_, err := GetPort(portOID)
+		ports, _ := GetSwitchPort(switchID)
+		count, _ := CountPorts(switchID)
+		return err == nil, reflect.DeepEqual(ports, []string{port.ID}) && count == 1
+	}
+
+	if err := AddPortToSwitch(switchID, port); err == nil {
+		t.Error("AddPortToSwitch() with the transaction failed, want error")
+	}
+	if document, member := stored(); document || member {
+		t.Errorf("failed AddPortToSwitch() stored the port document %v and the switch member %v, want neither", document, member)
+	}
+	failTransaction = false
+	if err := AddPortToSwitch(switchID, port); err != nil {
+		t.Fatalf("AddPortToSwitch() error = %v", err)
+	}
+	if document, member := stored(); !document || !member {
+		t.Errorf("AddPortToSwitch() stored the port document %v and the switch member %v, want both", document, member)
+	}
+	// adding the port again doesn't duplicate it
+	if err := AddPortToSwitch(switchID, port); err != nil {
+		t.Fatalf("AddPortToSwitch() error = %v", err)
+	}
+	if document, member := stored(); !document || !member {
+		t.Errorf("AddPortToSwitch() again stored the port document %v and the switch member %v, want both once", document, member)
+	}
+
+	failTransaction = true
+	if err := DeletePort(switchID, portOID); err == nil {
+		t.Error("DeletePort() with the transaction failed, want error")
+	}
+	if document, member := stored(); !document || !member {
+		t.Errorf("failed DeletePort() left the port document %v and the switch member %v, want both", document, member)
+	}
+	failTransaction = false
+	if err := DeletePort(switchID, portOID); err != nil {
+		t.Fatalf("DeletePort() error = %v", err)
+	}
+	if document, member := stored(); document || member {
+		t.Errorf("DeletePort() left the port document %v and the switch member %v, want neither", document, member)
+	}
+}
+
+func TestRemoveDuplicateSwitchPorts(t *testing.T) {
+	db.Connector = db.NewMockMemoryConnector()
+	switchID := "switchUUID:101"
+	if err := SaveSwitchPort(switchID, []string{"p1", "p2", "p1", "p3", "p2"}); err != nil {
+		t.Fatalf("SaveSwitchPort() error = %v", err)
+	}
+	if removed, err := RemoveDuplicateSwitchPorts(switchID); err != nil || removed != 2 {
+		t.Errorf("RemoveDuplicateSwitchPorts() = %d, %v, want 2", removed, err)
+	}
+	if ports, err := GetSwitchPort(switchID); err != nil || !reflect.DeepEqual(ports, []string{"p1", "p2", "p3"}) {
+		t.Errorf("GetSwitchPort() after RemoveDuplicateSwitchPorts() = %v, %v, want [p1 p2 p3]", ports, err)
+	}
+	if removed, err := RemoveDuplicateSwitchPorts(switchID); err != nil || removed != 0 {
+		t.Errorf("RemoveDuplicateSwitchPorts() without duplicates = %d, %v, want 0", removed, err)
+	}
+	if removed, err := RemoveDuplicateSwitchPorts("switchUUID:102"); err != nil || removed != 0 {
+		t.Errorf("RemoveDuplicateSwitchPorts() of switch without ports = %d, %v, want 0", removed, err)
+	}
+}
+
+func TestUpdatePortFields(t *testing.T) {
+	db.Connector = db.NewMockMemoryConnector()
+	portOID := "/ODIM/v1/Fabrics/fabricID/Switches/switchUUID:101/Ports/portUUID:eth1-1"
+	if err := SavePort(portOID, &dmtf.Port{ID: "portUUID:eth1-1", PortID: "eth1/1"}); err != nil {
+		t.Fatalf("SavePort() error = %v", err)
+	}
+
+	// concurrent updates of disjoint fields must both be retained
+	const updates = 50
+	var wg sync.WaitGroup
+	start := make(chan struct{})
+	for _, field := range []string{"Name", "Description"} {
+		wg.Add(1)
+		go func(field string) {
+			defer wg.Done()
+			<-start
+			for i := 1; i <= updates; i++ {
+				if err := UpdatePortFields(portOID, map[string]interface{}{field: field + strconv.Itoa(i)}); err != nil {
+					t.Errorf("UpdatePortFields() of %s error = %v", field, err)
+					return
+				}
+			}
+		}(field)
+	}
+	close(start)
+	wg.Wait()
+
+	port, err := GetPort(portOID)
+	if err != nil {
+		t.Fatalf("GetPort() error = %v", err)
+	}
+	if port.Name != "Name50" || port.Description != "Description50" {
+		t.Errorf("GetPort() Name = %s, Description = %s, want Name50 and Description50", port.Name, port.Description)
+	}
+	if port.PortID != "eth1/1" {
+		t.Errorf("GetPort() PortID = %s, fields not updated must be retained", port.PortID)
+	}
+
+	if err := UpdatePortFields("unknownPort", map[string]interface{}{"Name": "name"}); !errors.Is(err, db.ErrorKeyNotFound) {
+		t.Errorf("UpdatePortFields() of unknown port error = %v, want %v", err, db.ErrorKeyNotFound)
+	}
+}
+
+func TestMergePatch(t *testing.T) {
+	// examples of RFC 7386 appendix A
+	tests := []struct {
+		target string
+		patch  string
+		want   string
+	}{
+		{`{"a":"b"}`, `{"a":"c"}`, `{"a":"c"}`},
+		{`{"a":"b"}`, `{"b":"c"}`, `{"a":"b","b":"c"}`},
+		{`{"a":"b"}`, `{"a":null}`, `{}`},
+		{`{"a":"b","b":"c"}`, `{"a":null}`, `{"b":"c"}`},
+		{`{"a":["b"]}`, `{"a":"c"}`, `{"a":"c"}`},
+		{`{"a":"c"}`, `{"a":["b"]}`, `{"a":["b"]}`},
+		{`{"a":{"b":"c"}}`, `{"a":{"b":"d","c":null}}`, `{"a":{"b":"d"}}`},
+		{`{"a":[{"b":"c"}]}`, `{"a":[1]}`, `{"a":[1]}`},
+		{`{"e":null}`, `{"a":1}`, `{"a":1,"e":null}`},
+		{`[1,2]`, `{"a":"b","c":null}`, `{"a":"b"}`},
+		{`{}`, `{"a":{"bb":{"ccc":null}}}`, `{"a":{"bb":{}}}`},
+	}
+	for _, tt := range tests {
+		var target, patch interface{}
+		json.Unmarshal([]byte(tt.target), &target)
+		json.Unmarshal([]byte(tt.patch), &patch)
+		got, _ := json.Marshal(mergePatch(target, patch))
+		if string(got) != tt.want {
+			t.Errorf("mergePatch(%s, %s) = %s, want %s", tt.target, tt.patch, got, tt.want)
+		}
+	}
+}
+
+func TestMergePatchPort(t *testing.T) {
+	db.Connector = db.NewMockMemoryConnector()
+	portOID := "/ODIM/v1/Fabrics/fabricID/Switches/switchUUID:101/Ports/portUUID:eth1-1"
+	SavePort(portOID, &dmtf.Port{ID: "portUUID:eth1-1", PortID: "eth1/1", Description: "port"})
+	writable := func(field string) bool { return field == "Description" }
+
+	port, err := MergePatchPort(portOID, map[string]interface{}{"Description": nil}, writable)
+	if err != nil || port.Description != "" || port.PortID != "eth1/1" {
+		t.Errorf("MergePatchPort() = %+v, %v, want Description removed", port, err)
+	}
+	_, err = MergePatchPort(portOID, map[string]interface{}{"Description": "new", "PortID": "eth1/2"}, writable)
+	var notWritable *FieldNotWritableError
+	if !errors.As(err, &notWritable) || notWritable.Field != "PortID" {
+		t.Errorf("MergePatchPort() of PortID error = %v, want FieldNotWritableError", err)
+	}
+	// unchanged fields are not checked
+	if _, err = MergePatchPort(portOID, map[string]interface{}{"PortID": "eth1/1"}, writable); err != nil {
+		t.Errorf("MergePatchPort() of unchanged PortID error = %v", err)
+	}
+	if stored, _ := GetPort(portOID); stored.Description != "" || stored.PortID != "eth1/1" {
+		t.Errorf("GetPort() = %+v, want the rejected patch not applied", stored)
+	}
+}
+
+func TestGetPortCache(t *testing.T) {
+	var gets int64
+	db.Connector = countingConnector{MockMemoryConnector: db.NewMockMemoryConnector(), gets: &gets}
+	InvalidatePortCache()
+	var written []string
+	OnPortWrite(func(portOID string) { written = append(written, portOID) })
+	defer func() { portWriteHooks = portWriteHooks[:len(portWriteHooks)-1] }()
+	const switchID = "switchUUID:101"
+	portOID := "/ODIM/v1/Fabrics/fabricID/Switches/" + switchID + "/Ports/portUUID:eth1-1"
+	SaveSwitchPort(switchID, []string{"portUUID:eth1-1"})
+	SavePort(portOID, &dmtf.Port{ID: "portUUID:eth1-1", Description: "saved"})
+
+	for i := 0; i < 2; i++ {
+		port, err := GetPort(portOID)
+		if err != nil || port.Description != "saved" {
+			t.Fatalf("GetPort() = %+v, %v, want the saved port", port, err)
+		}
+		// the callers can modify the port without modifying the cached one
+		port.Description = "modified"
+	}
+	if gets != 1 {
+		t.Errorf("%d DB reads for 2 GetPort(), want the port read once", gets)
+	}
+
+	UpdatePort(portOID, &dmtf.Port{ID: "portUUID:eth1-1", Description: "updated"})
+	if port, err := GetPort(portOID); err != nil || port.Description != "updated" {
+		t.Errorf("GetPort() after UpdatePort() = %+v, %v, want the updated port", port, err)
+	}
+	MergePatchPort(portOID, map[string]interface{}{"Description": "patched"}, func(string) bool { return true })
+	if port, err := GetPort(portOID); err != nil || port.Description != "patched" {
+		t.Errorf("GetPort() after MergePatchPort() = %+v, %v, want the patched port", port, err)
+	}
+	if err := DeletePort(switchID, portOID); err != nil {
+		t.Fatalf("DeletePort() error = %v", err)
+	}
+	if _, err := GetPort(portOID); !errors.Is(err, db.ErrorKeyNotFound) {
+		t.Errorf("GetPort() after DeletePort() error = %v, want ErrorKeyNotFound", err)
+	}
+	if want := []string{portOID, portOID, portOID, portOID}; !reflect.DeepEqual(written, want) {
+		t.Errorf("port write hooks invoked for %v, want %v", written, want)
+	}
+
+	// a port read before an invalidation is not cached after it
+	SavePort(portOID, &dmtf.Port{ID: "portUUID:eth1-1"})
+	generation := portCacheGeneration()
+	InvalidatePort(portOID)
+	cachePort(portOID, `{"Id":"stale"}`, generation)
+	if port, err := GetPort(portOID); err != nil || port.ID != "portUUID:eth1-1" {
+		t.Errorf("GetPort() = %+v, %v, want the port read after the invalidation", port, err)
+	}
+}
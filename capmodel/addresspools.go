@@ -26,7 +26,7 @@ import (
 func GetAddressPool(fabricID, oid string) (model.AddressPool, error) {
 	var addressPool model.AddressPool
 	key := fmt.Sprintf("%s:%s", fabricID, oid)
-	data, err := db.Connector.Get(db.TableAddressPool, key)
+	data, err := dbGet(db.TableAddressPool, key)
 	if err != nil {
 		return addressPool, err
 	}
@@ -40,7 +40,7 @@ func GetAddressPool(fabricID, oid string) (model.AddressPool, error) {
 func GetAllAddressPools(fabricID string) (map[string]model.AddressPool, error) {
 	allAddressPoolData := make(map[string]model.AddressPool)
 	keySet := fmt.Sprintf("%s:%s", db.TableAddressPool, fabricID)
-	addressPoolOids, err := db.Connector.GetKeySetMembers(keySet)
+	addressPoolOids, err := dbGetKeySetMembers(keySet)
 	if err != nil {
 		return nil, fmt.Errorf("while trying to collect all addressPool data, got: %v", err)
 	}
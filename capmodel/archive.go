@@ -0,0 +1,418 @@
+//(C) Copyright [2020] Hewlett Packard Enterprise Development LP
+//
+//Licensed under the Apache License, Version 2.0 (the "License"); you may
+//not use this file except in compliance with the License. You may obtain
+//a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+//Unless required by applicable law or agreed to in writing, software
+//distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+//WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+//License for the specific language governing permissions and limitations
+// under the License.
+
+package capmodel
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path"
+	"sort"
+	"time"
+
+	"github.com/ODIM-Project/PluginCiscoACI/capdata"
+	"github.com/ODIM-Project/PluginCiscoACI/db"
+)
+
+// ArchiveVersion is the version of the archive format written by ExportArchive,
+// archives of any other version are refused by ImportArchive
+const ArchiveVersion = 1
+
+var (
+	// ErrArchiveVersion is returned when the archive is not of ArchiveVersion
+	ErrArchiveVersion = errors.New("unsupported archive version")
+	// ErrArchiveMalformed is returned when the archive doesn't describe consistent fabrics
+	ErrArchiveMalformed = errors.New("malformed archive")
+)
+
+// Archive is the state of the fabrics stored by the plugin, exported for disaster recovery.
+// The documents are kept as stored in the DB, while the DB keys and the key sets indexing
+// the ports are not archived: they are derived from the ids of the fabrics, switches and
+// ports when the archive is imported.
+type Archive struct {
+	Version    int             `json:"Version"`
+	ExportedAt time.Time       `json:"ExportedAt"`
+	Fabrics    []FabricArchive `json:"Fabrics"`
+}
+
+// FabricArchive is the fabric document with the switches listed in it
+type FabricArchive struct {
+	ID       string          `json:"Id"`
+	Fabric   json.RawMessage `json:"Fabric"`
+	Switches []SwitchArchive `json:"Switches"`
+}
+
+// SwitchArchive is the switch document with its chassis and its ports, the chassis is
+// absent when the switch has none stored
+type SwitchArchive struct {
+	ID      string          `json:"Id"`
+	Switch  json.RawMessage `json:"Switch"`
+	Chassis *ChassisArchive `json:"Chassis,omitempty"`
+	Ports   []PortArchive   `json:"Ports"`
+}
+
+// ChassisArchive is the chassis document of a switch
+type ChassisArchive struct {
+	ID      string          `json:"Id"`
+	Chassis json.RawMessage `json:"Chassis"`
+}
+
+// PortArchive is the port document with the last known state and the settings of the port,
+// those are absent when they were never stored for the port
+type PortArchive struct {
+	ID       string          `json:"Id"`
+	Port     json.RawMessage `json:"Port"`
+	State    json.RawMessage `json:"State,omitempty"`
+	Settings json.RawMessage `json:"Settings,omitempty"`
+}
+
+// ExportArchive collects the documents of all the fabrics, with their switches and ports, from the DB.
+// The fabrics, switches and ports are ordered by id, so the same state is always exported alike.
+func ExportArchive() (*Archive, error) {
+	fabricIDs, err := dbGetAllMatchingKeys(db.TableFabric, "")
+	if err != nil {
+		return nil, fmt.Errorf("while trying to collect all fabric data, got: %w", err)
+	}
+	sort.Strings(fabricIDs)
+	archive := Archive{Version: ArchiveVersion, ExportedAt: time.Now().UTC(), Fabrics: []FabricArchive{}}
+	for _, fabricID := range fabricIDs {
+		fabricArchive, err := exportFabric(fabricID)
+		if err != nil {
+			return nil, err
+		}
+		archive.Fabrics = append(archive.Fabrics, fabricArchive)
+	}
+	return &archive, nil
+}
+
+func exportFabric(fabricID string) (FabricArchive, error) {
+	fabricArchive := FabricArchive{ID: fabricID, Switches: []SwitchArchive{}}
+	data, err := dbGet(db.TableFabric, fabricID)
+	if err != nil {
+		return fabricArchive, fmt.Errorf("while trying to collect fabric data of %s, got: %w", fabricID, err)
+	}
+	fabricArchive.Fabric = json.RawMessage(data)
+	var fabric capdata.Fabric
+	if err := json.Unmarshal([]byte(data), &fabric); err != nil {
+		return fabricArchive, fmt.Errorf("while trying to unmarshal fabric data of %s, got: %v", fabricID, err)
+	}
+	switchIDs := append([]string{}, fabric.SwitchData...)
+	sort.Strings(switchIDs)
+	for _, switchID := range switchIDs {
+		switchArchive, err := exportSwitch(fabricID, switchID)
+		if err != nil {
+			return fabricArchive, err
+		}
+		fabricArchive.Switches = append(fabricArchive.Switches, switchArchive)
+	}
+	return fabricArchive, nil
+}
+
+func exportSwitch(fabricID, switchID string) (SwitchArchive, error) {
+	switchArchive := SwitchArchive{ID: switchID, Ports: []PortArchive{}}
+	data, err := dbGet(db.TableSwitch, switchID)
+	if err != nil {
+		return switchArchive, fmt.Errorf("while trying to collect switch data of %s, got: %w", switchID, err)
+	}
+	switchArchive.Switch = json.RawMessage(data)
+	var switchData map[string]interface{}
+	if err := json.Unmarshal([]byte(data), &switchData); err != nil {
+		return switchArchive, fmt.Errorf("while trying to unmarshal switch data of %s, got: %v", switchID, err)
+	}
+	if chassisOID := switchChassisOID(switchData); chassisOID != "" {
+		chassisID := path.Base(chassisOID)
+		chassis, err := getOptionalDocument(db.TableSwitchChassis, chassisID)
+		if err != nil {
+			return switchArchive, err
+		}
+		if chassis != nil {
+			switchArchive.Chassis = &ChassisArchive{ID: chassisID, Chassis: chassis}
+		}
+	}
+	ports, err := GetSwitchPort(switchID)
+	if err != nil && !errors.Is(err, db.ErrorKeyNotFound) {
+		return switchArchive, err
+	}
+	ports = append([]string{}, ports...)
+	sort.Strings(ports)
+	for _, portID := range ports {
+		portOID := switchPortOID(fabricID, switchID, portID)
+		portArchive := PortArchive{ID: portID}
+		data, err := dbGet(db.TablePort, portOID)
+		if err != nil {
+			return switchArchive, fmt.Errorf("while trying to collect port data of %s, got: %w", portOID, err)
+		}
+		portArchive.Port = json.RawMessage(data)
+		if portArchive.State, err = getOptionalDocument(db.TablePortState, portOID); err != nil {
+			return switchArchive, err
+		}
+		if portArchive.Settings, err = getOptionalDocument(db.TablePortSettings, portOID); err != nil {
+			return switchArchive, err
+		}
+		switchArchive.Ports = append(switchArchive.Ports, portArchive)
+	}
+	return switchArchive, nil
+}
+
+// getOptionalDocument collects the entry from the DB, nil is returned when it is not present
+func getOptionalDocument(table, resourceID string) (json.RawMessage, error) {
+	data, err := dbGet(table, resourceID)
+	if errors.Is(err, db.ErrorKeyNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("while trying to collect %s data of %s, got: %w", table, resourceID, err)
+	}
+	return json.RawMessage(data), nil
+}
+
+// ValidateArchive checks the archive is of ArchiveVersion and that its fabrics are consistent:
+// the documents are JSON objects and the switches are the ones listed in their fabric
+func ValidateArchive(archive *Archive) error {
+	if archive.Version != ArchiveVersion {
+		return fmt.Errorf("%w: %d, only version %d is supported", ErrArchiveVersion, archive.Version, ArchiveVersion)
+	}
+	fabricIDs := map[string]bool{}
+	for _, fabricArchive := range archive.Fabrics {
+		if fabricArchive.ID == "" || fabricIDs[fabricArchive.ID] {
+			return fmt.Errorf("%w: fabric id %q is empty or repeated", ErrArchiveMalformed, fabricArchive.ID)
+		}
+		fabricIDs[fabricArchive.ID] = true
+		var fabric capdata.Fabric
+		if err := json.Unmarshal(fabricArchive.Fabric, &fabric); err != nil {
+			return fmt.Errorf("%w: fabric %s: %v", ErrArchiveMalformed, fabricArchive.ID, err)
+		}
+		listed := map[string]bool{}
+		for _, switchID := range fabric.SwitchData {
+			listed[switchID] = true
+		}
+		for _, switchArchive := range fabricArchive.Switches {
+			if err := ValidateSwitchID(switchArchive.ID); err != nil {
+				return fmt.Errorf("%w: fabric %s: %v", ErrArchiveMalformed, fabricArchive.ID, err)
+			}
+			if !listed[switchArchive.ID] {
+				return fmt.Errorf("%w: switch %q is not listed in fabric %s", ErrArchiveMalformed, switchArchive.ID, fabricArchive.ID)
+			}
+			delete(listed, switchArchive.ID)
+			if err := validateArchiveDocuments(switchArchive); err != nil {
+				return fmt.Errorf("%w: switch %s of fabric %s: %v", ErrArchiveMalformed, switchArchive.ID, fabricArchive.ID, err)
+			}
+		}
+		if len(listed) != 0 {
+			return fmt.Errorf("%w: fabric %s lists switches missing from the archive", ErrArchiveMalformed, fabricArchive.ID)
+		}
+	}
+	return nil
+}
+
+func validateArchiveDocuments(switchArchive SwitchArchive) error {
+	var document map[string]interface{}
+	if err := json.Unmarshal(switchArchive.Switch, &document); err != nil || document == nil {
+		return fmt.Errorf("switch document is not a JSON object")
+	}
+	if chassis := switchArchive.Chassis; chassis != nil {
+		if err := json.Unmarshal(chassis.Chassis, &document); err != nil || document == nil || chassis.ID == "" {
+			return fmt.Errorf("chassis document is not a JSON object or has no id")
+		}
+	}
+	portIDs := map[string]bool{}
+	for _, portArchive := range switchArchive.Ports {
+		if portArchive.ID == "" || portIDs[portArchive.ID] {
+			return fmt.Errorf("port id %q is empty or repeated", portArchive.ID)
+		}
+		portIDs[portArchive.ID] = true
+		if err := json.Unmarshal(portArchive.Port, &document); err != nil || document == nil {
+			return fmt.Errorf("port document of %s is not a JSON object", portArchive.ID)
+		}
+		if portArchive.State != nil {
+			var state PortState
+			if err := json.Unmarshal(portArchive.State, &state); err != nil {
+				return fmt.Errorf("port state of %s: %v", portArchive.ID, err)
+			}
+		}
+		if portArchive.Settings != nil {
+			var settings PortSettings
+			if err := json.Unmarshal(portArchive.Settings, &settings); err != nil {
+				return fmt.Errorf("port settings of %s: %v", portArchive.ID, err)
+			}
+		}
+	}
+	return nil
+}
+
+// ImportFabric stores the fabric of the archive with its switches and ports in a single DB
+// transaction, the archive must have been validated with ValidateArchive. ErrorKeyAlreadyExist
+// is returned when one of the documents is already present, unless force is set: the switches
+// and ports currently stored for the fabric are then replaced by the ones of the archive.
+// The key sets indexing the ports are rebuilt afterwards, as they are only used for counting
+// and filtering ports.
+func ImportFabric(fabricArchive FabricArchive, force bool) error {
+	var writes []db.Write
+	var stale []archivedPort
+	if force {
+		var err error
+		if writes, stale, err = fabricDeleteWrites(fabricArchive.ID); err != nil {
+			return err
+		}
+	}
+	create := !force
+	add := func(table, resourceID string, data json.RawMessage) {
+		writes = append(writes, db.Write{Table: table, ResourceID: resourceID, Data: string(data), Create: create})
+	}
+	var imported []archivedPort
+	add(db.TableFabric, fabricArchive.ID, fabricArchive.Fabric)
+	writes = append(writes, db.Write{KeySet: db.TableFabricSet, Member: fabricArchive.ID})
+	for _, switchArchive := range fabricArchive.Switches {
+		add(db.TableSwitch, switchArchive.ID, switchArchive.Switch)
+		if switchArchive.Chassis != nil {
+			add(db.TableSwitchChassis, switchArchive.Chassis.ID, switchArchive.Chassis.Chassis)
+		}
+		ports := []string{}
+		for _, portArchive := range switchArchive.Ports {
+			portOID := switchPortOID(fabricArchive.ID, switchArchive.ID, portArchive.ID)
+			add(db.TablePort, portOID, portArchive.Port)
+			port := archivedPort{switchID: switchArchive.ID, portID: portArchive.ID, portOID: portOID,
+				links: connectedEthernetsOf(portArchive.Port)}
+			if portArchive.State != nil {
+				add(db.TablePortState, portOID, portArchive.State)
+				var state PortState
+				json.Unmarshal(portArchive.State, &state)
+				port.health = state.Health
+			}
+			if portArchive.Settings != nil {
+				add(db.TablePortSettings, portOID, portArchive.Settings)
+			}
+			ports = append(ports, portArchive.ID)
+			imported = append(imported, port)
+		}
+		data, err := json.Marshal(ports)
+		if err != nil {
+			return fmt.Errorf("while marshalling data, got: %v", err)
+		}
+		add(db.TableSwitchPorts, switchArchive.ID, data)
+	}
+	if err := db.Connector.Transaction(writes); err != nil {
+		return fmt.Errorf("while trying to import fabric %s, got: %w", fabricArchive.ID, err)
+	}
+	InvalidateFabric(fabricArchive.ID)
+	for _, port := range append(append([]archivedPort{}, stale...), imported...) {
+		portWritten(port.portOID)
+	}
+	return rebuildPortKeySets(stale, imported)
+}
+
+// archivedPort is a port with the keys indexing it
+type archivedPort struct {
+	switchID, portID, portOID, health string
+	links                             []string
+}
+
+// fabricDeleteWrites returns the writes removing the switches and ports currently stored for
+// the fabric, with the ports to be removed from the key sets
+func fabricDeleteWrites(fabricID string) ([]db.Write, []archivedPort, error) {
+	var writes []db.Write
+	var stale []archivedPort
+	data, err := dbGet(db.TableFabric, fabricID)
+	if errors.Is(err, db.ErrorKeyNotFound) {
+		return nil, nil, nil
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("while trying to collect fabric data of %s, got: %w", fabricID, err)
+	}
+	var fabric capdata.Fabric
+	if err := json.Unmarshal([]byte(data), &fabric); err != nil {
+		return nil, nil, fmt.Errorf("while trying to unmarshal fabric data of %s, got: %v", fabricID, err)
+	}
+	remove := func(table, resourceID string) {
+		writes = append(writes, db.Write{Table: table, ResourceID: resourceID, Delete: true})
+	}
+	for _, switchID := range fabric.SwitchData {
+		switchData, err := getOptionalDocument(db.TableSwitch, switchID)
+		if err != nil {
+			return nil, nil, err
+		}
+		if switchData != nil {
+			var document map[string]interface{}
+			json.Unmarshal(switchData, &document)
+			if chassisOID := switchChassisOID(document); chassisOID != "" {
+				remove(db.TableSwitchChassis, path.Base(chassisOID))
+			}
+			remove(db.TableSwitch, switchID)
+		}
+		ports, err := GetSwitchPort(switchID)
+		if err != nil && !errors.Is(err, db.ErrorKeyNotFound) {
+			return nil, nil, err
+		}
+		for _, portID := range ports {
+			portOID := switchPortOID(fabricID, switchID, portID)
+			state, err := GetPortState(portOID)
+			if err != nil && !errors.Is(err, db.ErrorKeyNotFound) {
+				return nil, nil, err
+			}
+			portData, err := getOptionalDocument(db.TablePort, portOID)
+			if err != nil {
+				return nil, nil, err
+			}
+			remove(db.TablePort, portOID)
+			remove(db.TablePortState, portOID)
+			remove(db.TablePortSettings, portOID)
+			stale = append(stale, archivedPort{switchID: switchID, portID: portID, portOID: portOID, health: state.Health,
+				links: connectedEthernetsOf(portData)})
+		}
+		remove(db.TableSwitchPorts, switchID)
+	}
+	return writes, stale, nil
+}
+
+// rebuildPortKeySets removes the replaced ports from the switch-port, port health and port link
+// key sets and adds the imported ones
+func rebuildPortKeySets(stale, imported []archivedPort) error {
+	for _, port := range stale {
+		keySet := fmt.Sprintf("%s:%s", db.TableSwitchPortSet, port.switchID)
+		if err := db.Connector.DeleteKeySetMembers(keySet, port.portID); err != nil {
+			return fmt.Errorf("while trying to remove member from switch-port key set, got: %v", err)
+		}
+		if err := updatePortLinks(port.portOID, port.links, nil); err != nil {
+			return err
+		}
+		if port.health == "" {
+			continue
+		}
+		if err := db.Connector.DeleteKeySetMembers(portHealthSet(port.health), port.portOID); err != nil {
+			return fmt.Errorf("while trying to remove member from port health key set, got: %v", err)
+		}
+	}
+	for _, port := range imported {
+		keySet := fmt.Sprintf("%s:%s", db.TableSwitchPortSet, port.switchID)
+		if err := db.Connector.UpdateKeySet(keySet, port.portID); err != nil {
+			return fmt.Errorf("while trying to update switch-port key set members, got: %v", err)
+		}
+		if err := updatePortLinks(port.portOID, nil, port.links); err != nil {
+			return err
+		}
+		if port.health == "" {
+			continue
+		}
+		if err := db.Connector.UpdateKeySet(portHealthSet(port.health), port.portOID); err != nil {
+			return fmt.Errorf("while trying to update port health key set members, got: %v", err)
+		}
+	}
+	return nil
+}
+
+// switchPortOID returns the OID of the port of the switch of the fabric
+func switchPortOID(fabricID, switchID, portID string) string {
+	return fmt.Sprintf("/ODIM/v1/Fabrics/%s/Switches/%s/Ports/%s", fabricID, switchID, portID)
+}
@@ -0,0 +1,158 @@
+//(C) Copyright [2020] Hewlett Packard Enterprise Development LP
+//
+//Licensed under the Apache License, Version 2.0 (the "License"); you may
+//not use this file except in compliance with the License. You may obtain
+//a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+//Unless required by applicable law or agreed to in writing, software
+//distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+//WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+//License for the specific language governing permissions and limitations
+// under the License.
+
+package capmodel
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/ODIM-Project/ODIM/lib-dmtf/model"
+	"github.com/ODIM-Project/PluginCiscoACI/capdata"
+	"github.com/ODIM-Project/PluginCiscoACI/db"
+)
+
+const archiveSwitchOID = "/ODIM/v1/Fabrics/fabricID/Switches/switchUUID:101"
+
+func saveArchiveState(t *testing.T) {
+	SaveFabric("fabricID", &capdata.Fabric{PodID: "1", SwitchData: []string{"switchUUID:101"}})
+	SaveSwitch("switchUUID:101", &model.Switch{ODataID: archiveSwitchOID, ID: "switchUUID:101",
+		Links: &model.SwitchLinks{Chassis: &model.Link{Oid: "/ODIM/v1/Chassis/chassisUUID:1"}}})
+	SaveSwitchChassis("chassisUUID:1", &model.Chassis{Oid: "/ODIM/v1/Chassis/chassisUUID:1", ID: "chassisUUID:1"})
+	ports := []string{"portUUID:eth1-1", "portUUID:eth1-2"}
+	SaveSwitchPort("switchUUID:101", ports)
+	for _, portID := range ports {
+		SavePort(archiveSwitchOID+"/Ports/"+portID, &model.Port{ODataID: archiveSwitchOID + "/Ports/" + portID, ID: portID})
+	}
+	if err := UpdatePortState(archiveSwitchOID+"/Ports/portUUID:eth1-1", PortState{LinkState: "Enabled", Health: "Warning"}); err != nil {
+		t.Fatalf("UpdatePortState() error = %v", err)
+	}
+	if _, err := RequestPortSettings(archiveSwitchOID+"/Ports/portUUID:eth1-2", map[string]interface{}{"Description": "uplink"}, time.Date(2026, 10, 16, 10, 0, 0, 0, time.UTC)); err != nil {
+		t.Fatalf("RequestPortSettings() error = %v", err)
+	}
+}
+
+func exportArchiveJSON(t *testing.T) []byte {
+	archive, err := ExportArchive()
+	if err != nil {
+		t.Fatalf("ExportArchive() error = %v", err)
+	}
+	if err := ValidateArchive(archive); err != nil {
+		t.Fatalf("ValidateArchive() of exported archive error = %v", err)
+	}
+	data, err := json.Marshal(archive.Fabrics)
+	if err != nil {
+		t.Fatalf("marshalling archive, got: %v", err)
+	}
+	return data
+}
+
+func TestArchiveRoundTrip(t *testing.T) {
+	connector := db.NewMockMemoryConnector()
+	db.Connector = connector
+	InvalidateFabricCache()
+	saveArchiveState(t)
+	wantKeys := connector.Keys()
+	archive, err := ExportArchive()
+	if err != nil {
+		t.Fatalf("ExportArchive() error = %v", err)
+	}
+	data, err := json.Marshal(archive)
+	if err != nil {
+		t.Fatalf("marshalling archive, got: %v", err)
+	}
+	exported := exportArchiveJSON(t)
+
+	// import into an empty DB
+	connector = db.NewMockMemoryConnector()
+	db.Connector = connector
+	InvalidateFabricCache()
+	var imported Archive
+	if err := json.Unmarshal(data, &imported); err != nil {
+		t.Fatalf("unmarshalling archive, got: %v", err)
+	}
+	if err := ValidateArchive(&imported); err != nil {
+		t.Fatalf("ValidateArchive() error = %v", err)
+	}
+	for _, fabricArchive := range imported.Fabrics {
+		if err := ImportFabric(fabricArchive, false); err != nil {
+			t.Fatalf("ImportFabric() error = %v", err)
+		}
+	}
+	if got := exportArchiveJSON(t); string(got) != string(exported) {
+		t.Errorf("re-exported archive = %s, want %s", got, exported)
+	}
+	if got := connector.Keys(); !reflect.DeepEqual(got, wantKeys) {
+		t.Errorf("imported keys = %v, want %v", got, wantKeys)
+	}
+	if count, err := CountPorts("switchUUID:101"); err != nil || count != 2 {
+		t.Errorf("CountPorts() = %d, %v, want 2", count, err)
+	}
+	warning, err := GetPortsByHealth("", []string{"Warning"})
+	if err != nil || !reflect.DeepEqual(warning, []string{archiveSwitchOID + "/Ports/portUUID:eth1-1"}) {
+		t.Errorf("GetPortsByHealth(Warning) = %v, %v, want the imported port", warning, err)
+	}
+
+	// the fabric is present
+	if err := ImportFabric(imported.Fabrics[0], false); !errors.Is(err, db.ErrorKeyAlreadyExist) {
+		t.Fatalf("ImportFabric() of present fabric error = %v, want ErrorKeyAlreadyExist", err)
+	}
+
+	// the present fabric is replaced when forced, the ports missing from the archive are removed
+	AddSwitchPort("switchUUID:101", "portUUID:eth1-3")
+	SavePort(archiveSwitchOID+"/Ports/portUUID:eth1-3", &model.Port{ID: "portUUID:eth1-3"})
+	UpdatePortState(archiveSwitchOID+"/Ports/portUUID:eth1-3", PortState{LinkState: "Disabled", Health: "Critical"})
+	if err := ImportFabric(imported.Fabrics[0], true); err != nil {
+		t.Fatalf("forced ImportFabric() error = %v", err)
+	}
+	if got := exportArchiveJSON(t); string(got) != string(exported) {
+		t.Errorf("archive re-exported after forced import = %s, want %s", got, exported)
+	}
+	if got := connector.Keys(); !reflect.DeepEqual(got, wantKeys) {
+		t.Errorf("keys after forced import = %v, want %v", got, wantKeys)
+	}
+	if critical, err := GetPortsByHealth("", []string{"Critical"}); err != nil || len(critical) != 0 {
+		t.Errorf("GetPortsByHealth(Critical) = %v, %v, want the replaced port removed", critical, err)
+	}
+}
+
+func TestValidateArchive(t *testing.T) {
+	fabric := json.RawMessage(`{"PodID":"1","SwitchData":["switchUUID:101"]}`)
+	switchArchive := SwitchArchive{ID: "switchUUID:101", Switch: json.RawMessage(`{"Id":"switchUUID:101"}`),
+		Ports: []PortArchive{{ID: "portUUID:eth1-1", Port: json.RawMessage(`{"Id":"portUUID:eth1-1"}`)}}}
+	tests := []struct {
+		name    string
+		archive Archive
+		wantErr error
+	}{
+		{"valid", Archive{Version: ArchiveVersion, Fabrics: []FabricArchive{{ID: "fabricID", Fabric: fabric, Switches: []SwitchArchive{switchArchive}}}}, nil},
+		{"other version", Archive{Version: ArchiveVersion + 1}, ErrArchiveVersion},
+		{"switch not listed", Archive{Version: ArchiveVersion, Fabrics: []FabricArchive{{ID: "fabricID", Fabric: json.RawMessage(`{"PodID":"1"}`), Switches: []SwitchArchive{switchArchive}}}}, ErrArchiveMalformed},
+		{"listed switch missing", Archive{Version: ArchiveVersion, Fabrics: []FabricArchive{{ID: "fabricID", Fabric: fabric}}}, ErrArchiveMalformed},
+		{"repeated fabric", Archive{Version: ArchiveVersion, Fabrics: []FabricArchive{{ID: "fabricID", Fabric: json.RawMessage(`{}`)}, {ID: "fabricID", Fabric: json.RawMessage(`{}`)}}}, ErrArchiveMalformed},
+		{"port not an object", Archive{Version: ArchiveVersion, Fabrics: []FabricArchive{{ID: "fabricID", Fabric: fabric, Switches: []SwitchArchive{{ID: "switchUUID:101", Switch: switchArchive.Switch,
+			Ports: []PortArchive{{ID: "portUUID:eth1-1", Port: json.RawMessage(`"port"`)}}}}}}}, ErrArchiveMalformed},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateArchive(&tt.archive)
+			if (tt.wantErr == nil && err != nil) || (tt.wantErr != nil && !errors.Is(err, tt.wantErr)) {
+				t.Errorf("ValidateArchive() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -16,7 +16,9 @@ package capmodel
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
+	"time"
 
 	"github.com/ODIM-Project/PluginCiscoACI/db"
 )
@@ -24,6 +26,10 @@ import (
 // PluginIntialStatus hold value to check if it's intial status request to plugin
 var PluginIntialStatus = false
 
+// readRetryDelay is the time waited before a read which failed to reach the DB is retried, it gives
+// the DB client the time to reconnect to the new master during a sentinel failover
+var readRetryDelay = 500 * time.Millisecond
+
 // SaveToDB is for adding data to the DB
 func SaveToDB(table, resourceID string, data interface{}) error {
 	dataByte, err := json.Marshal(data)
@@ -41,3 +47,58 @@ func UpdateDbData(table, resourceID string, data interface{}) error {
 	}
 	return db.Connector.Update(table, resourceID, string(dataByte))
 }
+
+// retryRead does the DB read and retries it once after readRetryDelay when the DB can't be reached
+func retryRead(read func() error) error {
+	err := read()
+	if errors.Is(err, db.ErrorServiceUnavailable) {
+		time.Sleep(readRetryDelay)
+		err = read()
+	}
+	return err
+}
+
+// dbGet reads the entry of the table, the read is retried once when the DB can't be reached
+func dbGet(table, resourceID string) (data string, err error) {
+	err = retryRead(func() error {
+		data, err = db.Connector.Get(table, resourceID)
+		return err
+	})
+	return data, err
+}
+
+// dbExists checks the entry of the table is present, the read is retried once when the DB can't be reached
+func dbExists(table, resourceID string) (exists bool, err error) {
+	err = retryRead(func() error {
+		exists, err = db.Connector.Exists(table, resourceID)
+		return err
+	})
+	return exists, err
+}
+
+// dbGetAllMatchingKeys reads the keys of the table matching the pattern, the read is retried once when the DB can't be reached
+func dbGetAllMatchingKeys(table, pattern string) (keys []string, err error) {
+	err = retryRead(func() error {
+		keys, err = db.Connector.GetAllMatchingKeys(table, pattern)
+		return err
+	})
+	return keys, err
+}
+
+// dbGetKeySetMembers reads the members of the key set, the read is retried once when the DB can't be reached
+func dbGetKeySetMembers(key string) (members []string, err error) {
+	err = retryRead(func() error {
+		members, err = db.Connector.GetKeySetMembers(key)
+		return err
+	})
+	return members, err
+}
+
+// dbGetKeySetCount reads the number of members of the key set, the read is retried once when the DB can't be reached
+func dbGetKeySetCount(key string) (count int, err error) {
+	err = retryRead(func() error {
+		count, err = db.Connector.GetKeySetCount(key)
+		return err
+	})
+	return count, err
+}
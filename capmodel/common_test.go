@@ -15,11 +15,31 @@
 package capmodel
 
 import (
+	"errors"
+	"fmt"
 	"testing"
+	"time"
 
+	dmtf "github.com/ODIM-Project/ODIM/lib-dmtf/model"
 	"github.com/ODIM-Project/PluginCiscoACI/db"
 )
 
+// failoverConnector fails the given number of reads as the DB does while its master is failed over
+type failoverConnector struct {
+	db.MockMemoryConnector
+	failures *int
+	reads    *int
+}
+
+func (d failoverConnector) Get(table, resourceID string) (string, error) {
+	*d.reads++
+	if *d.failures > 0 {
+		*d.failures--
+		return "", fmt.Errorf("%w: connection refused", db.ErrorServiceUnavailable)
+	}
+	return d.MockMemoryConnector.Get(table, resourceID)
+}
+
 func TestSaveToDB(t *testing.T) {
 	db.Connector = db.MockConnector{}
 	type args struct {
@@ -59,3 +79,33 @@ func TestSaveToDB(t *testing.T) {
 		})
 	}
 }
+
+func TestReadRetriedOnFailover(t *testing.T) {
+	defer func(delay time.Duration) { readRetryDelay = delay }(readRetryDelay)
+	readRetryDelay = 0
+	failures, reads := 1, 0
+	db.Connector = failoverConnector{MockMemoryConnector: db.NewMockMemoryConnector(), failures: &failures, reads: &reads}
+	InvalidatePortCache()
+	if err := SavePort("portOID", &dmtf.Port{ID: "portUUID:eth1-1"}); err != nil {
+		t.Fatalf("SavePort() error = %v", err)
+	}
+	port, err := GetPort("portOID")
+	if err != nil {
+		t.Fatalf("GetPort() error = %v", err)
+	}
+	if port.ID != "portUUID:eth1-1" || reads != 2 {
+		t.Errorf("GetPort() = %+v after %d reads, want the port after 2 reads", port, reads)
+	}
+
+	// the read is retried only once
+	InvalidatePortCache()
+	failures, reads = 2, 0
+	if _, err := GetPort("portOID"); !errors.Is(err, db.ErrorServiceUnavailable) || reads != 2 {
+		t.Errorf("GetPort() error = %v after %d reads, want ErrorServiceUnavailable after 2 reads", err, reads)
+	}
+	// the other errors are not retried
+	failures, reads = 0, 0
+	if _, err := GetPort("unknown"); !errors.Is(err, db.ErrorKeyNotFound) || reads != 1 {
+		t.Errorf("GetPort() error = %v after %d reads, want ErrorKeyNotFound after 1 read", err, reads)
+	}
+}
@@ -0,0 +1,67 @@
+//(C) Copyright [2020] Hewlett Packard Enterprise Development LP
+//
+//Licensed under the Apache License, Version 2.0 (the "License"); you may
+//not use this file except in compliance with the License. You may obtain
+//a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+//Unless required by applicable law or agreed to in writing, software
+//distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+//WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+//License for the specific language governing permissions and limitations
+// under the License.
+
+package capmodel
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/ODIM-Project/PluginCiscoACI/db"
+)
+
+// dbPingTimeout bounds the round-trip of Ping, so that an unresponsive DB doesn't hold the caller
+const dbPingTimeout = 2 * time.Second
+
+// DBStat holds the basic health of the DB, the outcome of the last Ping and the statistics of the
+// DB connection pool. LastPing is zero until the DB is pinged.
+type DBStat struct {
+	LastPing        time.Time
+	LastPingLatency time.Duration
+	LastPingError   string
+	db.PoolStats
+}
+
+var (
+	dbStatLock sync.Mutex
+	dbStat     DBStat
+)
+
+// Ping checks the DB is reachable with a round-trip, through the sentinel when Redis HA is enabled.
+// The round-trip is bounded by a short timeout, an error is returned when the DB doesn't answer in time.
+func Ping(ctx context.Context) error {
+	ctx, cancel := context.WithTimeout(ctx, dbPingTimeout)
+	defer cancel()
+	started := time.Now()
+	err := db.Connector.Ping(ctx)
+	dbStatLock.Lock()
+	defer dbStatLock.Unlock()
+	dbStat.LastPing = started
+	dbStat.LastPingLatency = time.Since(started)
+	dbStat.LastPingError = ""
+	if err != nil {
+		dbStat.LastPingError = err.Error()
+	}
+	return err
+}
+
+// Stat returns the basic health of the DB without a round-trip to the DB
+func Stat() DBStat {
+	dbStatLock.Lock()
+	stat := dbStat
+	dbStatLock.Unlock()
+	stat.PoolStats = db.Connector.Stats()
+	return stat
+}
@@ -0,0 +1,54 @@
+//(C) Copyright [2020] Hewlett Packard Enterprise Development LP
+//
+//Licensed under the Apache License, Version 2.0 (the "License"); you may
+//not use this file except in compliance with the License. You may obtain
+//a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+//Unless required by applicable law or agreed to in writing, software
+//distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+//WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+//License for the specific language governing permissions and limitations
+// under the License.
+
+package capmodel
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/ODIM-Project/PluginCiscoACI/db"
+)
+
+// unreachableConnector doesn't answer the pings until ctx is done, as a DB which can't be reached
+type unreachableConnector struct {
+	db.MockMemoryConnector
+}
+
+func (d unreachableConnector) Ping(ctx context.Context) error {
+	<-ctx.Done()
+	return fmt.Errorf("%w: %v", db.ErrorServiceUnavailable, ctx.Err())
+}
+
+func TestPing(t *testing.T) {
+	db.Connector = db.NewMockMemoryConnector()
+	if err := Ping(context.Background()); err != nil {
+		t.Errorf("Ping() of healthy DB = %v, want nil", err)
+	}
+	if stat := Stat(); stat.LastPing.IsZero() || stat.LastPingError != "" {
+		t.Errorf("Stat() after healthy ping = %+v, want the ping recorded without error", stat)
+	}
+
+	db.Connector = unreachableConnector{db.NewMockMemoryConnector()}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := Ping(ctx); !errors.Is(err, db.ErrorServiceUnavailable) {
+		t.Errorf("Ping() of unreachable DB = %v, want %v", err, db.ErrorServiceUnavailable)
+	}
+	if stat := Stat(); stat.LastPingError == "" {
+		t.Errorf("Stat() after failed ping = %+v, want the error recorded", stat)
+	}
+}
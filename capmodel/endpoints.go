@@ -26,7 +26,7 @@ import (
 func GetEndpoints(fabricID, oid string) (capdata.EndpointData, error) {
 	var endpoint capdata.EndpointData
 	key := fmt.Sprintf("%s:%s", fabricID, oid)
-	data, err := db.Connector.Get(db.TableEndPoint, key)
+	data, err := dbGet(db.TableEndPoint, key)
 	if err != nil {
 		return endpoint, err
 	}
@@ -40,7 +40,7 @@ func GetEndpoints(fabricID, oid string) (capdata.EndpointData, error) {
 func GetAllEndpoints(fabricID string) (map[string]capdata.EndpointData, error) {
 	allEndpointData := make(map[string]capdata.EndpointData)
 	keySet := fmt.Sprintf("%s:%s", db.TableEndPoint, fabricID)
-	endpointOids, err := db.Connector.GetKeySetMembers(keySet)
+	endpointOids, err := dbGetKeySetMembers(keySet)
 	if err != nil {
 		return nil, fmt.Errorf("while trying to collect all endpoint data, got: %v", err)
 	}
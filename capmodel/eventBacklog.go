@@ -0,0 +1,92 @@
+//(C) Copyright [2020] Hewlett Packard Enterprise Development LP
+//
+//Licensed under the Apache License, Version 2.0 (the "License"); you may
+//not use this file except in compliance with the License. You may obtain
+//a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+//Unless required by applicable law or agreed to in writing, software
+//distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+//WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+//License for the specific language governing permissions and limitations
+// under the License.
+
+package capmodel
+
+import (
+	"fmt"
+	"sort"
+	"sync/atomic"
+	"time"
+
+	"github.com/ODIM-Project/PluginCiscoACI/db"
+)
+
+// eventBacklogSequence tells apart the ids of the events added to the backlog at the same time
+var eventBacklogSequence uint64
+
+// AddEventToBacklog stores the event in the backlog of the events not yet published, the oldest events
+// are dropped to keep the backlog within capacity. The number of events dropped is returned.
+func AddEventToBacklog(data []byte, capacity int) (int, error) {
+	// the ids sort in the order the events are added
+	eventID := fmt.Sprintf("%020d%06d", time.Now().UnixNano(), atomic.AddUint64(&eventBacklogSequence, 1)%1000000)
+	writes := []db.Write{
+		{Table: db.TableEventBacklog, ResourceID: eventID, Data: string(data), Create: true},
+		{KeySet: db.TableEventBacklogSet, Member: eventID},
+	}
+	if err := db.Connector.Transaction(writes); err != nil {
+		return 0, fmt.Errorf("while trying to add event to the backlog, got: %w", err)
+	}
+	eventIDs, err := ListBackloggedEvents()
+	if err != nil {
+		return 0, err
+	}
+	dropped := 0
+	for ; len(eventIDs)-dropped > capacity; dropped++ {
+		if err := RemoveEventFromBacklog(eventIDs[dropped]); err != nil {
+			return dropped, err
+		}
+	}
+	return dropped, nil
+}
+
+// ListBackloggedEvents returns the ids of the events in the backlog, oldest first
+func ListBackloggedEvents() ([]string, error) {
+	eventIDs, err := dbGetKeySetMembers(db.TableEventBacklogSet)
+	if err != nil {
+		return nil, fmt.Errorf("while trying to list the events in the backlog, got: %w", err)
+	}
+	sort.Strings(eventIDs)
+	return eventIDs, nil
+}
+
+// GetBackloggedEvent reads the event with the given id from the backlog
+func GetBackloggedEvent(eventID string) ([]byte, error) {
+	data, err := dbGet(db.TableEventBacklog, eventID)
+	if err != nil {
+		return nil, fmt.Errorf("while trying to get event %s from the backlog, got: %w", eventID, err)
+	}
+	return []byte(data), nil
+}
+
+// RemoveEventFromBacklog removes the event with the given id from the backlog
+func RemoveEventFromBacklog(eventID string) error {
+	writes := []db.Write{
+		{Table: db.TableEventBacklog, ResourceID: eventID, Delete: true},
+		{KeySet: db.TableEventBacklogSet, Member: eventID, Delete: true},
+	}
+	if err := db.Connector.Transaction(writes); err != nil {
+		return fmt.Errorf("while trying to remove event %s from the backlog, got: %w", eventID, err)
+	}
+	return nil
+}
+
+// GetEventBacklogSize returns the number of events in the backlog
+func GetEventBacklogSize() (int, error) {
+	size, err := dbGetKeySetCount(db.TableEventBacklogSet)
+	if err != nil {
+		return 0, fmt.Errorf("while trying to count the events in the backlog, got: %w", err)
+	}
+	return size, nil
+}
@@ -17,6 +17,7 @@ package capmodel
 import (
 	"encoding/json"
 	"fmt"
+	"sort"
 
 	"github.com/ODIM-Project/PluginCiscoACI/capdata"
 	"github.com/ODIM-Project/PluginCiscoACI/db"
@@ -24,21 +25,25 @@ import (
 
 // GetFabric collects the fabric data from the DB
 func GetFabric(fabricID string) (capdata.Fabric, error) {
+	if fabric, ok := getCachedFabric(fabricID); ok {
+		return fabric, nil
+	}
 	var fabric capdata.Fabric
-	data, err := db.Connector.Get(db.TableFabric, fabricID)
+	data, err := dbGet(db.TableFabric, fabricID)
 	if err != nil {
 		return fabric, err
 	}
 	if err = json.Unmarshal([]byte(data), &fabric); err != nil {
 		return fabric, fmt.Errorf("while trying to unmarshal fabric data, got: %v", err)
 	}
+	cacheFabric(fabricID, fabric)
 	return fabric, nil
 }
 
 // GetAllFabric collects the fabric data from the DB
 func GetAllFabric(pattern string) (map[string]capdata.Fabric, error) {
 	allFabricData := make(map[string]capdata.Fabric)
-	fabricIDs, err := db.Connector.GetAllMatchingKeys(db.TableFabric, pattern)
+	fabricIDs, err := dbGetAllMatchingKeys(db.TableFabric, pattern)
 	if err != nil {
 		return nil, fmt.Errorf("while trying to collect all fabric data, got: %w", err)
 	}
@@ -52,12 +57,63 @@ func GetAllFabric(pattern string) (map[string]capdata.Fabric, error) {
 	return allFabricData, nil
 }
 
-// SaveFabric stores the fabric data in the DB
+// ListFabrics returns the ids of all the fabrics stored, sorted
+func ListFabrics() ([]string, error) {
+	fabricIDs, err := dbGetKeySetMembers(db.TableFabricSet)
+	if err != nil {
+		return nil, fmt.Errorf("while trying to list the fabrics, got: %w", err)
+	}
+	sort.Strings(fabricIDs)
+	return fabricIDs, nil
+}
+
+// IndexFabrics adds the fabrics stored before the fabric set was maintained to the set
+func IndexFabrics() error {
+	fabricIDs, err := dbGetAllMatchingKeys(db.TableFabric, "")
+	if err != nil {
+		return fmt.Errorf("while trying to collect all fabric ids, got: %w", err)
+	}
+	for _, fabricID := range fabricIDs {
+		if err := db.Connector.UpdateKeySet(db.TableFabricSet, fabricID); err != nil {
+			return fmt.Errorf("while trying to update fabric key set members, got: %v", err)
+		}
+	}
+	return nil
+}
+
+// SaveFabric stores the fabric data in the DB and adds the fabric to the fabric set in a single transaction
 func SaveFabric(fabricID string, data *capdata.Fabric) error {
-	return SaveToDB(db.TableFabric, fabricID, *data)
+	defer InvalidateFabric(fabricID)
+	dataByte, err := json.Marshal(*data)
+	if err != nil {
+		return fmt.Errorf("while marshalling data, got: %v", err)
+	}
+	writes := []db.Write{
+		{Table: db.TableFabric, ResourceID: fabricID, Data: string(dataByte), Create: true},
+		{KeySet: db.TableFabricSet, Member: fabricID},
+	}
+	if err := db.Connector.Transaction(writes); err != nil {
+		return fmt.Errorf("while trying to save fabric %s, got: %w", fabricID, err)
+	}
+	return nil
 }
 
 // UpdateFabric updates the fabric data stored in the DB
 func UpdateFabric(fabricID string, data *capdata.Fabric) error {
+	defer InvalidateFabric(fabricID)
 	return UpdateDbData(db.TableFabric, fabricID, *data)
 }
+
+// DeleteFabric deletes the fabric data stored in the DB and removes the fabric from the fabric set
+// in a single transaction
+func DeleteFabric(fabricID string) error {
+	defer InvalidateFabric(fabricID)
+	writes := []db.Write{
+		{Table: db.TableFabric, ResourceID: fabricID, Delete: true},
+		{KeySet: db.TableFabricSet, Member: fabricID, Delete: true},
+	}
+	if err := db.Connector.Transaction(writes); err != nil {
+		return fmt.Errorf("while trying to remove fabric data, got: %w", err)
+	}
+	return nil
+}
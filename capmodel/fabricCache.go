@@ -0,0 +1,90 @@
+//(C) Copyright [2020] Hewlett Packard Enterprise Development LP
+//
+//Licensed under the Apache License, Version 2.0 (the "License"); you may
+//not use this file except in compliance with the License. You may obtain
+//a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+//Unless required by applicable law or agreed to in writing, software
+//distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+//WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+//License for the specific language governing permissions and limitations
+// under the License.
+
+package capmodel
+
+import (
+	"sync"
+	"time"
+
+	"github.com/ODIM-Project/PluginCiscoACI/capdata"
+)
+
+const (
+	// maxCachedFabrics bounds the number of fabrics cached, the oldest is evicted when full
+	maxCachedFabrics = 64
+	// fabricCacheTTL limits the staleness of the fabrics cached when the DB is updated by another plugin instance
+	fabricCacheTTL = 5 * time.Minute
+)
+
+type cachedFabric struct {
+	fabric   capdata.Fabric
+	cachedAt time.Time
+}
+
+// fabricCache holds the fabrics read from the DB, they are topology data
+// which change only when the fabrics are discovered again
+var fabricCache = struct {
+	lock    sync.RWMutex
+	fabrics map[string]cachedFabric
+}{fabrics: make(map[string]cachedFabric)}
+
+func getCachedFabric(fabricID string) (capdata.Fabric, bool) {
+	fabricCache.lock.RLock()
+	defer fabricCache.lock.RUnlock()
+	cached, ok := fabricCache.fabrics[fabricID]
+	if !ok || time.Since(cached.cachedAt) > fabricCacheTTL {
+		return capdata.Fabric{}, false
+	}
+	return copyFabric(cached.fabric), true
+}
+
+func cacheFabric(fabricID string, fabric capdata.Fabric) {
+	fabricCache.lock.Lock()
+	defer fabricCache.lock.Unlock()
+	if _, ok := fabricCache.fabrics[fabricID]; !ok && len(fabricCache.fabrics) >= maxCachedFabrics {
+		var oldestID string
+		var oldest time.Time
+		for id, cached := range fabricCache.fabrics {
+			if oldestID == "" || cached.cachedAt.Before(oldest) {
+				oldestID, oldest = id, cached.cachedAt
+			}
+		}
+		delete(fabricCache.fabrics, oldestID)
+	}
+	fabricCache.fabrics[fabricID] = cachedFabric{fabric: copyFabric(fabric), cachedAt: time.Now()}
+}
+
+// InvalidateFabric removes the fabric from the cache, to be called when the fabric is added,
+// removed or discovered again without using SaveFabric or UpdateFabric, which invalidate it
+func InvalidateFabric(fabricID string) {
+	fabricCache.lock.Lock()
+	defer fabricCache.lock.Unlock()
+	delete(fabricCache.fabrics, fabricID)
+}
+
+// InvalidateFabricCache removes all the fabrics from the cache
+func InvalidateFabricCache() {
+	fabricCache.lock.Lock()
+	defer fabricCache.lock.Unlock()
+	fabricCache.fabrics = make(map[string]cachedFabric)
+}
+
+// copyFabric copies the switches of the fabric, so that the callers can't modify the cached fabric
+func copyFabric(fabric capdata.Fabric) capdata.Fabric {
+	if fabric.SwitchData != nil {
+		fabric.SwitchData = append([]string{}, fabric.SwitchData...)
+	}
+	return fabric
+}
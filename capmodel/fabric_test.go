@@ -15,15 +15,30 @@
 package capmodel
 
 import (
+	"errors"
+	"fmt"
 	"reflect"
+	"sync/atomic"
 	"testing"
 
 	"github.com/ODIM-Project/PluginCiscoACI/capdata"
 	"github.com/ODIM-Project/PluginCiscoACI/db"
 )
 
+// countingConnector counts the DB reads
+type countingConnector struct {
+	db.MockMemoryConnector
+	gets *int64
+}
+
+func (c countingConnector) Get(table, resourceID string) (string, error) {
+	atomic.AddInt64(c.gets, 1)
+	return c.MockMemoryConnector.Get(table, resourceID)
+}
+
 func TestGetFabric(t *testing.T) {
 	db.Connector = db.MockConnector{}
+	InvalidateFabricCache()
 	type args struct {
 		fabricID string
 	}
@@ -69,6 +84,7 @@ func TestGetFabric(t *testing.T) {
 
 func TestGetAllFabric(t *testing.T) {
 	db.Connector = db.MockConnector{}
+	InvalidateFabricCache()
 	tests := []struct {
 		name    string
 		want    map[string]capdata.Fabric
@@ -98,3 +114,90 @@ func TestGetAllFabric(t *testing.T) {
 		})
 	}
 }
+
+func TestFabricCache(t *testing.T) {
+	var gets int64
+	db.Connector = countingConnector{MockMemoryConnector: db.NewMockMemoryConnector(), gets: &gets}
+	InvalidateFabricCache()
+	defer InvalidateFabricCache()
+	SaveFabric("fabricID", &capdata.Fabric{SwitchData: []string{"switchUUID:101"}, PodID: "1"})
+
+	fabric, _ := GetFabric("fabricID")
+	fabric.SwitchData[0] = "modified"
+	fabric, _ = GetFabric("fabricID")
+	if gets != 1 || fabric.SwitchData[0] != "switchUUID:101" {
+		t.Errorf("GetFabric() = %v with %d DB reads, want the cached fabric unmodified read once", fabric, gets)
+	}
+
+	// updating the fabric invalidates it
+	UpdateFabric("fabricID", &capdata.Fabric{SwitchData: []string{"switchUUID:101", "switchUUID:102"}, PodID: "1"})
+	if fabric, _ = GetFabric("fabricID"); len(fabric.SwitchData) != 2 || gets != 2 {
+		t.Errorf("GetFabric() = %v with %d DB reads, want the updated fabric", fabric, gets)
+	}
+
+	// removed fabric is read again from the DB
+	InvalidateFabric("fabricID")
+	db.Connector.Delete(db.TableFabric, "fabricID")
+	if _, err := GetFabric("fabricID"); err == nil || gets != 3 {
+		t.Errorf("GetFabric() error = %v with %d DB reads, want the removed fabric not found", err, gets)
+	}
+
+	// cache is bounded
+	for i := 0; i <= maxCachedFabrics; i++ {
+		SaveFabric(fmt.Sprintf("fabric%d", i), &capdata.Fabric{PodID: "1"})
+		GetFabric(fmt.Sprintf("fabric%d", i))
+	}
+	if len(fabricCache.fabrics) != maxCachedFabrics {
+		t.Errorf("%d fabrics cached, want %d", len(fabricCache.fabrics), maxCachedFabrics)
+	}
+}
+
+func TestListFabrics(t *testing.T) {
+	db.Connector = db.NewMockMemoryConnector()
+	InvalidateFabricCache()
+	defer InvalidateFabricCache()
+	if fabricIDs, err := ListFabrics(); err != nil || len(fabricIDs) != 0 {
+		t.Fatalf("ListFabrics() = %v, %v, want no fabric", fabricIDs, err)
+	}
+
+	SaveFabric("fabricB", &capdata.Fabric{PodID: "1"})
+	if fabricIDs, err := ListFabrics(); err != nil || !reflect.DeepEqual(fabricIDs, []string{"fabricB"}) {
+		t.Errorf("ListFabrics() = %v, %v, want [fabricB]", fabricIDs, err)
+	}
+
+	SaveFabric("fabricC", &capdata.Fabric{PodID: "2"})
+	SaveFabric("fabricA", &capdata.Fabric{PodID: "3"})
+	if fabricIDs, err := ListFabrics(); err != nil || !reflect.DeepEqual(fabricIDs, []string{"fabricA", "fabricB", "fabricC"}) {
+		t.Errorf("ListFabrics() = %v, %v, want [fabricA fabricB fabricC]", fabricIDs, err)
+	}
+
+	// the fabric set follows the fabrics removed and added again, failed saves leave it unchanged
+	DeleteFabric("fabricB")
+	if err := SaveFabric("fabricA", &capdata.Fabric{PodID: "3"}); !errors.Is(err, db.ErrorKeyAlreadyExist) {
+		t.Errorf("SaveFabric() of present fabric error = %v, want ErrorKeyAlreadyExist", err)
+	}
+	if fabricIDs, err := ListFabrics(); err != nil || !reflect.DeepEqual(fabricIDs, []string{"fabricA", "fabricC"}) {
+		t.Errorf("ListFabrics() after removing fabricB = %v, %v, want [fabricA fabricC]", fabricIDs, err)
+	}
+	SaveFabric("fabricB", &capdata.Fabric{PodID: "1"})
+	DeleteFabric("fabricA")
+	DeleteFabric("fabricC")
+	if fabricIDs, err := ListFabrics(); err != nil || !reflect.DeepEqual(fabricIDs, []string{"fabricB"}) {
+		t.Errorf("ListFabrics() after adding fabricB back = %v, %v, want [fabricB]", fabricIDs, err)
+	}
+}
+
+func TestIndexFabrics(t *testing.T) {
+	db.Connector = db.NewMockMemoryConnector()
+	InvalidateFabricCache()
+	defer InvalidateFabricCache()
+	// fabrics stored before the fabric set was maintained
+	SaveToDB(db.TableFabric, "fabricA", capdata.Fabric{PodID: "1"})
+	SaveToDB(db.TableFabric, "fabricB", capdata.Fabric{PodID: "2"})
+	if err := IndexFabrics(); err != nil {
+		t.Fatalf("IndexFabrics() error = %v", err)
+	}
+	if fabricIDs, err := ListFabrics(); err != nil || !reflect.DeepEqual(fabricIDs, []string{"fabricA", "fabricB"}) {
+		t.Errorf("ListFabrics() = %v, %v, want [fabricA fabricB]", fabricIDs, err)
+	}
+}
@@ -0,0 +1,83 @@
+//(C) Copyright [2020] Hewlett Packard Enterprise Development LP
+//
+//Licensed under the Apache License, Version 2.0 (the "License"); you may
+//not use this file except in compliance with the License. You may obtain
+//a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+//Unless required by applicable law or agreed to in writing, software
+//distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+//WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+//License for the specific language governing permissions and limitations
+// under the License.
+
+package capmodel
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/ODIM-Project/PluginCiscoACI/db"
+)
+
+// IdempotentResult is the result of a request made with an idempotency key, RequestHash
+// identifies the request so that the key can't be reused for a different request. Pending
+// is set while the request reserving the key is being applied, before its result is stored.
+type IdempotentResult struct {
+	RequestHash string          `json:"RequestHash"`
+	Pending     bool            `json:"Pending,omitempty"`
+	StatusCode  int             `json:"StatusCode,omitempty"`
+	Body        json.RawMessage `json:"Body,omitempty"`
+}
+
+// GetIdempotentResult collects the result stored for the idempotency key from the DB
+func GetIdempotentResult(key string) (*IdempotentResult, error) {
+	data, err := dbGet(db.TableIdempotencyKey, key)
+	if err != nil {
+		return nil, err
+	}
+	var result IdempotentResult
+	if err = json.Unmarshal([]byte(data), &result); err != nil {
+		return nil, fmt.Errorf("while trying to unmarshal idempotent result, got: %v", err)
+	}
+	return &result, nil
+}
+
+// ReserveIdempotencyKey atomically reserves the idempotency key for the request with the given hash, with a
+// pending result the DB removes once ttl has elapsed. db.ErrorKeyAlreadyExist is returned when the key is
+// already reserved, by a request being applied or for the result of an applied one.
+func ReserveIdempotencyKey(key, requestHash string, ttl time.Duration) error {
+	data, err := json.Marshal(&IdempotentResult{RequestHash: requestHash, Pending: true})
+	if err != nil {
+		return fmt.Errorf("while trying to marshal idempotent result, got: %v", err)
+	}
+	if err = db.Connector.CreateWithExpiry(db.TableIdempotencyKey, key, string(data), ttl); err != nil {
+		return fmt.Errorf("while trying to reserve idempotency key, got: %w", err)
+	}
+	return nil
+}
+
+// SaveIdempotentResult replaces the pending result of the reserved idempotency key with the result of the
+// request, the DB removes it once ttl has elapsed
+func SaveIdempotentResult(key string, result *IdempotentResult, ttl time.Duration) error {
+	data, err := json.Marshal(result)
+	if err != nil {
+		return fmt.Errorf("while trying to marshal idempotent result, got: %v", err)
+	}
+	write := db.Write{Table: db.TableIdempotencyKey, ResourceID: key, Data: string(data), Expiry: ttl}
+	if err = db.Connector.Transaction([]db.Write{write}); err != nil {
+		return fmt.Errorf("while trying to save idempotent result, got: %w", err)
+	}
+	return nil
+}
+
+// ReleaseIdempotencyKey removes the reservation of the idempotency key, for the request to be applied again
+func ReleaseIdempotencyKey(key string) error {
+	if err := db.Connector.Delete(db.TableIdempotencyKey, key); err != nil && !errors.Is(err, db.ErrorKeyNotFound) {
+		return fmt.Errorf("while trying to release idempotency key, got: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,129 @@
+//(C) Copyright [2020] Hewlett Packard Enterprise Development LP
+//
+//Licensed under the Apache License, Version 2.0 (the "License"); you may
+//not use this file except in compliance with the License. You may obtain
+//a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+//Unless required by applicable law or agreed to in writing, software
+//distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+//WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+//License for the specific language governing permissions and limitations
+// under the License.
+
+package capmodel
+
+import (
+	"sync"
+	"time"
+)
+
+// Port read cache
+//
+// The ports read from the DB are cached by GetPort. The writes of the ports made through capmodel
+// invoke the port write hooks registered with OnPortWrite with the OID of the port written, the cache
+// registers one removing the port, so that the next read reflects the write. The cache is coherent
+// only with the writes of this plugin instance: the writes of the other instances sharing the DB
+// are seen once the cached port expires after portCacheTTL, invalidating the ports across instances
+// needs the writes to be published to them, like over Redis pub/sub, which isn't done yet.
+
+const (
+	// maxCachedPorts bounds the number of ports cached, the oldest is evicted when full
+	maxCachedPorts = 4096
+	// portCacheTTL limits the staleness of the ports cached when the DB is updated by another plugin instance
+	portCacheTTL = 30 * time.Second
+)
+
+type cachedPort struct {
+	data     string
+	cachedAt time.Time
+}
+
+// portCache holds the port data read from the DB, the data is unmarshalled for every read so that
+// the callers can't modify the cached port. The generation is incremented for every invalidation,
+// a port read before an invalidation isn't cached after it.
+var portCache = struct {
+	lock       sync.RWMutex
+	ports      map[string]cachedPort
+	generation uint64
+}{ports: make(map[string]cachedPort)}
+
+var (
+	portWriteHooks     []func(portOID string)
+	portWriteHooksLock sync.RWMutex
+)
+
+func init() {
+	OnPortWrite(InvalidatePort)
+}
+
+// OnPortWrite registers the hook invoked with the OID of the port after the port is written,
+// updated or removed through capmodel
+func OnPortWrite(hook func(portOID string)) {
+	portWriteHooksLock.Lock()
+	defer portWriteHooksLock.Unlock()
+	portWriteHooks = append(portWriteHooks, hook)
+}
+
+// portWritten invokes the port write hooks for the port
+func portWritten(portOID string) {
+	portWriteHooksLock.RLock()
+	defer portWriteHooksLock.RUnlock()
+	for _, hook := range portWriteHooks {
+		hook(portOID)
+	}
+}
+
+func getCachedPort(portOID string) (string, bool) {
+	portCache.lock.RLock()
+	defer portCache.lock.RUnlock()
+	cached, ok := portCache.ports[portOID]
+	if !ok || time.Since(cached.cachedAt) > portCacheTTL {
+		return "", false
+	}
+	return cached.data, true
+}
+
+// portCacheGeneration returns the generation of the cache, to be taken before reading the port from the DB
+func portCacheGeneration() uint64 {
+	portCache.lock.RLock()
+	defer portCache.lock.RUnlock()
+	return portCache.generation
+}
+
+// cachePort caches the port data read from the DB at the given generation, unless the cache was invalidated since
+func cachePort(portOID, data string, generation uint64) {
+	portCache.lock.Lock()
+	defer portCache.lock.Unlock()
+	if generation != portCache.generation {
+		return
+	}
+	if _, ok := portCache.ports[portOID]; !ok && len(portCache.ports) >= maxCachedPorts {
+		var oldestOID string
+		var oldest time.Time
+		for oid, cached := range portCache.ports {
+			if oldestOID == "" || cached.cachedAt.Before(oldest) {
+				oldestOID, oldest = oid, cached.cachedAt
+			}
+		}
+		delete(portCache.ports, oldestOID)
+	}
+	portCache.ports[portOID] = cachedPort{data: data, cachedAt: time.Now()}
+}
+
+// InvalidatePort removes the port from the cache
+func InvalidatePort(portOID string) {
+	portCache.lock.Lock()
+	defer portCache.lock.Unlock()
+	portCache.generation++
+	delete(portCache.ports, portOID)
+}
+
+// InvalidatePortCache removes all the ports from the cache
+func InvalidatePortCache() {
+	portCache.lock.Lock()
+	defer portCache.lock.Unlock()
+	portCache.generation++
+	portCache.ports = make(map[string]cachedPort)
+}
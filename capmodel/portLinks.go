@@ -0,0 +1,101 @@
+//(C) Copyright [2020] Hewlett Packard Enterprise Development LP
+//
+//Licensed under the Apache License, Version 2.0 (the "License"); you may
+//not use this file except in compliance with the License. You may obtain
+//a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+//Unless required by applicable law or agreed to in writing, software
+//distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+//WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+//License for the specific language governing permissions and limitations
+// under the License.
+
+package capmodel
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"github.com/ODIM-Project/PluginCiscoACI/db"
+)
+
+// GetPortsByEthernet returns the sorted OIDs of the ports whose Links.ConnectedPorts refer to the
+// ethernet interface with the given URI, as set with PATCH on the ports
+func GetPortsByEthernet(ethernetURI string) ([]string, error) {
+	portOIDs, err := dbGetKeySetMembers(portLinkSet(ethernetURI))
+	if err != nil {
+		return nil, fmt.Errorf("while trying to collect ports connected to %s, got: %w", ethernetURI, err)
+	}
+	sort.Strings(portOIDs)
+	return portOIDs, nil
+}
+
+func portLinkSet(ethernetURI string) string {
+	return fmt.Sprintf("%s:%s", db.TablePortLinkSet, ethernetURI)
+}
+
+// connectedEthernets returns the URIs of the ethernet interfaces in Links.ConnectedPorts of the port data
+func connectedEthernets(port map[string]interface{}) []string {
+	links, _ := port["Links"].(map[string]interface{})
+	connectedPorts, _ := links["ConnectedPorts"].([]interface{})
+	var uris []string
+	for _, connectedPort := range connectedPorts {
+		link, _ := connectedPort.(map[string]interface{})
+		if uri, _ := link["@odata.id"].(string); uri != "" {
+			uris = append(uris, uri)
+		}
+	}
+	return uris
+}
+
+// connectedEthernetsOf returns the URIs of the ethernet interfaces the port stored as data is connected to
+func connectedEthernetsOf(data []byte) []string {
+	var port map[string]interface{}
+	json.Unmarshal(data, &port)
+	return connectedEthernets(port)
+}
+
+// updatePortLinks moves the port from the link index of the ethernet interfaces it was connected to,
+// to the index of the ones it is now connected to
+func updatePortLinks(portOID string, previous, current []string) error {
+	connected := map[string]bool{}
+	for _, uri := range current {
+		connected[uri] = true
+	}
+	for _, uri := range previous {
+		if connected[uri] {
+			continue
+		}
+		if err := db.Connector.DeleteKeySetMembers(portLinkSet(uri), portOID); err != nil {
+			return fmt.Errorf("while trying to remove member from port link key set, got: %v", err)
+		}
+	}
+	for _, uri := range current {
+		if err := db.Connector.UpdateKeySet(portLinkSet(uri), portOID); err != nil {
+			return fmt.Errorf("while trying to update port link key set members, got: %v", err)
+		}
+	}
+	return nil
+}
+
+// portLinkWrites returns the writes moving the port from the link index of the ethernet interfaces it
+// was connected to, to the index of the ones it is now connected to, for the transaction of the port
+func portLinkWrites(portOID string, previous, current []string) []db.Write {
+	connected := map[string]bool{}
+	for _, uri := range current {
+		connected[uri] = true
+	}
+	var writes []db.Write
+	for _, uri := range previous {
+		if !connected[uri] {
+			writes = append(writes, db.Write{KeySet: portLinkSet(uri), Member: portOID, Delete: true})
+		}
+	}
+	for _, uri := range current {
+		writes = append(writes, db.Write{KeySet: portLinkSet(uri), Member: portOID})
+	}
+	return writes
+}
@@ -0,0 +1,120 @@
+//(C) Copyright [2020] Hewlett Packard Enterprise Development LP
+//
+//Licensed under the Apache License, Version 2.0 (the "License"); you may
+//not use this file except in compliance with the License. You may obtain
+//a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+//Unless required by applicable law or agreed to in writing, software
+//distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+//WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+//License for the specific language governing permissions and limitations
+// under the License.
+
+package capmodel
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/ODIM-Project/ODIM/lib-dmtf/model"
+	"github.com/ODIM-Project/PluginCiscoACI/db"
+)
+
+// connectedPortsPatch is the merge patch setting the connected ports of a port to the ethernet interfaces
+func connectedPortsPatch(ethernetURIs ...string) map[string]interface{} {
+	links := []interface{}{}
+	for _, uri := range ethernetURIs {
+		links = append(links, map[string]interface{}{"@odata.id": uri})
+	}
+	return map[string]interface{}{"Links": map[string]interface{}{"ConnectedPorts": links}}
+}
+
+func TestGetPortsByEthernet(t *testing.T) {
+	db.Connector = db.NewMockMemoryConnector()
+	InvalidatePortCache()
+	defer InvalidatePortCache()
+	switchOID := "/ODIM/v1/Fabrics/fabricID/Switches/switchUUID:101"
+	port1 := switchOID + "/Ports/portUUID:eth1-1"
+	port2 := switchOID + "/Ports/portUUID:eth1-2"
+	eth1 := "/redfish/v1/Systems/systemUUID.1/EthernetInterfaces/1"
+	eth2 := "/redfish/v1/Systems/systemUUID.1/EthernetInterfaces/2"
+	writable := func(string) bool { return true }
+	SaveSwitchPort("switchUUID:101", []string{"portUUID:eth1-1", "portUUID:eth1-2"})
+	for _, portOID := range []string{port1, port2} {
+		SavePort(portOID, &model.Port{ODataID: portOID})
+	}
+	lookup := func(ethernetURI string, want []string) {
+		t.Helper()
+		if got, err := GetPortsByEthernet(ethernetURI); err != nil || !reflect.DeepEqual(got, want) {
+			t.Errorf("GetPortsByEthernet(%s) = %v, %v, want %v", ethernetURI, got, err, want)
+		}
+	}
+	lookup(eth1, []string{})
+
+	// links set
+	if _, err := MergePatchPort(port1, connectedPortsPatch(eth1, eth2), writable); err != nil {
+		t.Fatalf("MergePatchPort() error = %v", err)
+	}
+	if _, err := MergePatchPort(port2, connectedPortsPatch(eth1), writable); err != nil {
+		t.Fatalf("MergePatchPort() error = %v", err)
+	}
+	lookup(eth1, []string{port1, port2})
+	lookup(eth2, []string{port1})
+
+	// link replaced
+	if _, err := MergePatchPort(port1, connectedPortsPatch(eth2), writable); err != nil {
+		t.Fatalf("MergePatchPort() error = %v", err)
+	}
+	lookup(eth1, []string{port2})
+	lookup(eth2, []string{port1})
+
+	// links cleared, like on DELETE of the connected ports
+	if err := UpdatePortFields(port1, map[string]interface{}{"Links": &model.PortLinks{}}); err != nil {
+		t.Fatalf("UpdatePortFields() error = %v", err)
+	}
+	lookup(eth2, []string{})
+
+	// removed port
+	if err := DeletePort("switchUUID:101", port2); err != nil {
+		t.Fatalf("DeletePort() error = %v", err)
+	}
+	lookup(eth1, []string{})
+}
+
+func TestMergePatchPortLinksAtomic(t *testing.T) {
+	memory := db.NewMockMemoryConnector()
+	db.Connector = memory
+	InvalidatePortCache()
+	defer InvalidatePortCache()
+	portOID := "/ODIM/v1/Fabrics/fabricID/Switches/switchUUID:101/Ports/portUUID:eth1-1"
+	eth1 := "/redfish/v1/Systems/systemUUID.1/EthernetInterfaces/1"
+	writable := func(string) bool { return true }
+	SavePort(portOID, &model.Port{ODataID: portOID})
+	failTransaction := true
+	db.Connector = transactionConnector{MockMemoryConnector: memory, failTransaction: &failTransaction}
+
+	// neither the port nor its link index are written when the transaction fails
+	if _, err := MergePatchPort(portOID, connectedPortsPatch(eth1), writable); err == nil {
+		t.Fatal("MergePatchPort() with failing transaction succeeded, want error")
+	}
+	port, err := GetPort(portOID)
+	if err != nil {
+		t.Fatalf("GetPort() error = %v", err)
+	}
+	if port.Links != nil && len(port.Links.ConnectedPorts) != 0 {
+		t.Errorf("port Links = %+v, want the links not written", port.Links)
+	}
+	if got, err := GetPortsByEthernet(eth1); err != nil || len(got) != 0 {
+		t.Errorf("GetPortsByEthernet(%s) = %v, %v, want the link index not written", eth1, got, err)
+	}
+
+	failTransaction = false
+	if _, err := MergePatchPort(portOID, connectedPortsPatch(eth1), writable); err != nil {
+		t.Fatalf("MergePatchPort() error = %v", err)
+	}
+	if got, err := GetPortsByEthernet(eth1); err != nil || !reflect.DeepEqual(got, []string{portOID}) {
+		t.Errorf("GetPortsByEthernet(%s) = %v, %v, want %v", eth1, got, err, []string{portOID})
+	}
+}
@@ -0,0 +1,135 @@
+//(C) Copyright [2020] Hewlett Packard Enterprise Development LP
+//
+//Licensed under the Apache License, Version 2.0 (the "License"); you may
+//not use this file except in compliance with the License. You may obtain
+//a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+//Unless required by applicable law or agreed to in writing, software
+//distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+//WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+//License for the specific language governing permissions and limitations
+// under the License.
+
+package capmodel
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/ODIM-Project/PluginCiscoACI/db"
+)
+
+// PortSettings holds the settings of a port requested through its Settings resource. Pending are
+// the values of the Redfish properties, like Description, requested from APIC and not yet applied
+// by it, those are promoted to the port once APIC reports them as applied.
+type PortSettings struct {
+	Pending     map[string]interface{} `json:"Pending,omitempty"`
+	RequestedAt *time.Time             `json:"RequestedAt,omitempty"`
+	// AppliedAt is when all the requested settings were last found applied
+	AppliedAt *time.Time `json:"AppliedAt,omitempty"`
+	// AdminState is the desired admin state of the port, Enabled or Disabled, recorded when the port
+	// was discovered, the baseline of the drift detection
+	AdminState string `json:"AdminState,omitempty"`
+}
+
+// GetPortSettings collects the settings of the port from the DB, the settings are empty
+// when none were requested for the port
+func GetPortSettings(portOID string) (PortSettings, error) {
+	var settings PortSettings
+	data, err := dbGet(db.TablePortSettings, portOID)
+	if err != nil {
+		if errors.Is(err, db.ErrorKeyNotFound) {
+			return settings, nil
+		}
+		return settings, fmt.Errorf("while trying to collect port settings, got: %w", err)
+	}
+	if err = json.Unmarshal([]byte(data), &settings); err != nil {
+		return settings, fmt.Errorf("while trying to unmarshal port settings, got: %v", err)
+	}
+	return settings, nil
+}
+
+// RequestPortSettings adds the requested values of the properties to the pending settings of the port
+func RequestPortSettings(portOID string, properties map[string]interface{}, requestedAt time.Time) (PortSettings, error) {
+	settings, err := GetPortSettings(portOID)
+	if err != nil {
+		return settings, err
+	}
+	if settings.Pending == nil {
+		settings.Pending = map[string]interface{}{}
+	}
+	for property, value := range properties {
+		settings.Pending[property] = value
+	}
+	settings.RequestedAt = &requestedAt
+	if err := UpdateDbData(db.TablePortSettings, portOID, settings); err != nil {
+		return settings, fmt.Errorf("while trying to update port settings, got: %w", err)
+	}
+	return settings, nil
+}
+
+// PromotePortSettings moves the applied properties from the pending settings to the port, the
+// settings are marked applied at appliedAt once none of them is pending anymore
+func PromotePortSettings(portOID string, applied []string, appliedAt time.Time) (PortSettings, error) {
+	settings, err := GetPortSettings(portOID)
+	if err != nil || len(applied) == 0 {
+		return settings, err
+	}
+	_, err = updatePortDocument(portOID, func(port map[string]interface{}) error {
+		for _, property := range applied {
+			if value, ok := settings.Pending[property]; ok {
+				port[property] = value
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return settings, err
+	}
+	for _, property := range applied {
+		delete(settings.Pending, property)
+	}
+	if len(settings.Pending) == 0 {
+		settings.Pending = nil
+		settings.AppliedAt = &appliedAt
+	}
+	if err := UpdateDbData(db.TablePortSettings, portOID, settings); err != nil {
+		return settings, fmt.Errorf("while trying to update port settings, got: %w", err)
+	}
+	return settings, nil
+}
+
+// RecordPortAdminState records the desired admin state of the port, the admin state already recorded
+// for the port is kept. true is returned when the admin state is recorded.
+func RecordPortAdminState(portOID, adminState string) (bool, error) {
+	settings, err := GetPortSettings(portOID)
+	if err != nil || settings.AdminState != "" {
+		return false, err
+	}
+	settings.AdminState = adminState
+	if err := UpdateDbData(db.TablePortSettings, portOID, settings); err != nil {
+		return false, fmt.Errorf("while trying to update port settings, got: %w", err)
+	}
+	return true, nil
+}
+
+// GetPortsWithSettings returns the OIDs of the ports for which settings were requested
+func GetPortsWithSettings() ([]string, error) {
+	portOIDs, err := dbGetAllMatchingKeys(db.TablePortSettings, "")
+	if err != nil {
+		return nil, fmt.Errorf("while trying to collect ports with settings, got: %w", err)
+	}
+	return portOIDs, nil
+}
+
+// DeletePortSettings removes the settings of the port
+func DeletePortSettings(portOID string) error {
+	if err := db.Connector.Delete(db.TablePortSettings, portOID); err != nil && !errors.Is(err, db.ErrorKeyNotFound) {
+		return fmt.Errorf("while trying to remove port settings, got: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,131 @@
+//(C) Copyright [2020] Hewlett Packard Enterprise Development LP
+//
+//Licensed under the Apache License, Version 2.0 (the "License"); you may
+//not use this file except in compliance with the License. You may obtain
+//a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+//Unless required by applicable law or agreed to in writing, software
+//distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+//WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+//License for the specific language governing permissions and limitations
+// under the License.
+
+package capmodel
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ODIM-Project/PluginCiscoACI/db"
+)
+
+// PortState is the last known operational state of a port read from APIC
+type PortState struct {
+	LinkState  string `json:"LinkState"`
+	LinkStatus string `json:"LinkStatus,omitempty"`
+	State      string `json:"State,omitempty"`
+	Health     string `json:"Health"`
+	// Pending is the degraded state observed since PendingSince, which
+	// is not reported until it persists for the flap grace window
+	Pending      *PortState `json:"Pending,omitempty"`
+	PendingSince *time.Time `json:"PendingSince,omitempty"`
+}
+
+// GetPortState collects the last known operational state of the port from the DB
+func GetPortState(portOID string) (PortState, error) {
+	var state PortState
+	data, err := dbGet(db.TablePortState, portOID)
+	if err != nil {
+		return state, err
+	}
+	if err = json.Unmarshal([]byte(data), &state); err != nil {
+		return state, fmt.Errorf("while trying to unmarshal port state, got: %v", err)
+	}
+	return state, nil
+}
+
+// UpdatePortState stores the operational state of the port and moves the port to the
+// health index of its new health, which is used by GetPortsByHealth. The state and the
+// index are written in a single transaction, nothing is written when the state is unchanged.
+func UpdatePortState(portOID string, state PortState) error {
+	previous, err := GetPortState(portOID)
+	found := err == nil
+	if err != nil && !errors.Is(err, db.ErrorKeyNotFound) {
+		return err
+	}
+	if found && reflect.DeepEqual(previous, state) {
+		return nil
+	}
+	data, err := json.Marshal(state)
+	if err != nil {
+		return fmt.Errorf("while marshalling port state, got: %v", err)
+	}
+	writes := []db.Write{{Table: db.TablePortState, ResourceID: portOID, Data: string(data)}}
+	if previous.Health != "" && previous.Health != state.Health {
+		writes = append(writes, db.Write{KeySet: portHealthSet(previous.Health), Member: portOID, Delete: true})
+	}
+	if state.Health != "" {
+		writes = append(writes, db.Write{KeySet: portHealthSet(state.Health), Member: portOID})
+	}
+	if err := db.Connector.Transaction(writes); err != nil {
+		return fmt.Errorf("while trying to update port state, got: %w", err)
+	}
+	return nil
+}
+
+// DeletePortState removes the operational state of the port and the port from the health index
+func DeletePortState(portOID string) error {
+	state, err := GetPortState(portOID)
+	if err != nil {
+		if errors.Is(err, db.ErrorKeyNotFound) {
+			return nil
+		}
+		return err
+	}
+	writes := []db.Write{{Table: db.TablePortState, ResourceID: portOID, Delete: true}}
+	if state.Health != "" {
+		writes = append(writes, db.Write{KeySet: portHealthSet(state.Health), Member: portOID, Delete: true})
+	}
+	if err := db.Connector.Transaction(writes); err != nil {
+		return fmt.Errorf("while trying to remove port state, got: %w", err)
+	}
+	return nil
+}
+
+// GetPortsByHealth returns the sorted OIDs of the ports whose last known health is one of
+// the given states, like Warning or Critical. Only the ports of the fabric or switch with the
+// given OID are returned, all the ports are considered when scope is empty. The ports whose
+// health was never read from APIC are not returned.
+func GetPortsByHealth(scope string, states []string) ([]string, error) {
+	var portOIDs []string
+	scope = strings.TrimSuffix(scope, "/")
+	queried := map[string]bool{}
+	for _, state := range states {
+		if queried[state] {
+			continue
+		}
+		queried[state] = true
+		members, err := dbGetKeySetMembers(portHealthSet(state))
+		if err != nil {
+			return nil, fmt.Errorf("while trying to collect ports of health %s, got: %w", state, err)
+		}
+		for _, portOID := range members {
+			if scope == "" || strings.HasPrefix(portOID, scope+"/") {
+				portOIDs = append(portOIDs, portOID)
+			}
+		}
+	}
+	sort.Strings(portOIDs)
+	return portOIDs, nil
+}
+
+func portHealthSet(health string) string {
+	return fmt.Sprintf("%s:%s", db.TablePortHealthSet, health)
+}
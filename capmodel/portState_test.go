@@ -0,0 +1,119 @@
+//(C) Copyright [2020] Hewlett Packard Enterprise Development LP
+//
+//Licensed under the Apache License, Version 2.0 (the "License"); you may
+//not use this file except in compliance with the License. You may obtain
+//a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+//Unless required by applicable law or agreed to in writing, software
+//distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+//WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+//License for the specific language governing permissions and limitations
+// under the License.
+
+package capmodel
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/ODIM-Project/PluginCiscoACI/db"
+)
+
+func TestGetPortsByHealth(t *testing.T) {
+	db.Connector = db.NewMockMemoryConnector()
+	fabricOID := "/ODIM/v1/Fabrics/fabricID"
+	switch101 := fabricOID + "/Switches/switchUUID:101"
+	switch102 := fabricOID + "/Switches/switchUUID:102"
+	port1 := switch101 + "/Ports/portUUID:eth1-1"
+	port2 := switch101 + "/Ports/portUUID:eth1-2"
+	port3 := switch102 + "/Ports/portUUID:eth1-1"
+	for portOID, health := range map[string]string{port1: "OK", port2: "Warning", port3: "Critical"} {
+		if err := UpdatePortState(portOID, PortState{LinkState: "Enabled", Health: health}); err != nil {
+			t.Fatalf("UpdatePortState() error = %v", err)
+		}
+	}
+	// port never enriched from APIC
+	unknownPort := switch101 + "/Ports/portUUID:eth1-3"
+
+	tests := []struct {
+		name   string
+		scope  string
+		states []string
+		want   []string
+	}{
+		{"fabric", fabricOID, []string{"Warning", "Critical"}, []string{port2, port3}},
+		{"switch", switch101, []string{"Warning", "Critical"}, []string{port2}},
+		{"all ports", "", []string{"OK"}, []string{port1}},
+		{"state repeated", switch102, []string{"Critical", "Critical"}, []string{port3}},
+		{"switch with common prefix", fabricOID + "/Switches/switchUUID:10", []string{"OK", "Warning", "Critical"}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := GetPortsByHealth(tt.scope, tt.states)
+			if err != nil {
+				t.Fatalf("GetPortsByHealth() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("GetPortsByHealth() = %v, want %v", got, tt.want)
+			}
+			for _, portOID := range got {
+				if portOID == unknownPort {
+					t.Errorf("GetPortsByHealth() returned port %s without recorded state", unknownPort)
+				}
+			}
+		})
+	}
+}
+
+func TestUpdatePortStateTransitions(t *testing.T) {
+	db.Connector = db.NewMockMemoryConnector()
+	portOID := "/ODIM/v1/Fabrics/fabricID/Switches/switchUUID:101/Ports/portUUID:eth1-1"
+	for _, health := range []string{"OK", "Critical", "Warning"} {
+		if err := UpdatePortState(portOID, PortState{Health: health}); err != nil {
+			t.Fatalf("UpdatePortState(%s) error = %v", health, err)
+		}
+		for _, state := range []string{"OK", "Warning", "Critical"} {
+			got, _ := GetPortsByHealth("", []string{state})
+			if indexed := len(got) == 1; indexed != (state == health) {
+				t.Errorf("after transition to %s, port indexed under %s = %v", health, state, indexed)
+			}
+		}
+	}
+	if err := DeletePortState(portOID); err != nil {
+		t.Fatalf("DeletePortState() error = %v", err)
+	}
+	if got, _ := GetPortsByHealth("", []string{"Warning"}); len(got) != 0 {
+		t.Errorf("GetPortsByHealth() = %v after DeletePortState, want none", got)
+	}
+	if _, err := GetPortState(portOID); !errors.Is(err, db.ErrorKeyNotFound) {
+		t.Errorf("GetPortState() error = %v after DeletePortState, want ErrorKeyNotFound", err)
+	}
+}
+
+func TestUpdatePortStateAtomic(t *testing.T) {
+	portOID := "/ODIM/v1/Fabrics/fabricID/Switches/switchUUID:101/Ports/portUUID:eth1-1"
+	failTransaction := false
+	db.Connector = transactionConnector{MockMemoryConnector: db.NewMockMemoryConnector(), failTransaction: &failTransaction}
+	if err := UpdatePortState(portOID, PortState{LinkState: "Enabled", Health: "OK"}); err != nil {
+		t.Fatalf("UpdatePortState() error = %v", err)
+	}
+
+	failTransaction = true
+	// the unchanged state isn't written again
+	if err := UpdatePortState(portOID, PortState{LinkState: "Enabled", Health: "OK"}); err != nil {
+		t.Errorf("UpdatePortState() of the unchanged state error = %v, want no write", err)
+	}
+	// neither the state nor the index is changed when the transaction fails
+	if err := UpdatePortState(portOID, PortState{LinkState: "Enabled", Health: "Critical"}); err == nil {
+		t.Fatal("UpdatePortState() with the transaction failing, want error")
+	}
+	if state, _ := GetPortState(portOID); state.Health != "OK" {
+		t.Errorf("GetPortState() health = %s after the failed update, want OK", state.Health)
+	}
+	if got, _ := GetPortsByHealth("", []string{"OK"}); !reflect.DeepEqual(got, []string{portOID}) {
+		t.Errorf("GetPortsByHealth(OK) = %v after the failed update, want the port", got)
+	}
+}
@@ -0,0 +1,107 @@
+//(C) Copyright [2020] Hewlett Packard Enterprise Development LP
+//
+//Licensed under the Apache License, Version 2.0 (the "License"); you may
+//not use this file except in compliance with the License. You may obtain
+//a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+//Unless required by applicable law or agreed to in writing, software
+//distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+//WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+//License for the specific language governing permissions and limitations
+// under the License.
+
+package capmodel
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/ODIM-Project/PluginCiscoACI/db"
+)
+
+// Session is the session created by the plugin for a client. The token isn't stored,
+// the session is found by the hash of the token.
+type Session struct {
+	ID          string    `json:"Id"`
+	UserName    string    `json:"UserName"`
+	TokenHash   string    `json:"TokenHash"`
+	CreatedTime time.Time `json:"CreatedTime"`
+	LastUsed    time.Time `json:"LastUsed"`
+}
+
+// SessionTokenHash returns the hash under which the session of the token is indexed
+func SessionTokenHash(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// SaveSession stores the session and the index of its token in a single transaction, both
+// are removed by the DB once timeout has elapsed
+func SaveSession(session *Session, timeout time.Duration) error {
+	return writeSession(session, timeout, true)
+}
+
+// UpdateSession stores the changes of the session, like its last use, the session and the
+// index of its token are removed by the DB once timeout has elapsed from now
+func UpdateSession(session *Session, timeout time.Duration) error {
+	return writeSession(session, timeout, false)
+}
+
+func writeSession(session *Session, timeout time.Duration, create bool) error {
+	data, err := json.Marshal(session)
+	if err != nil {
+		return fmt.Errorf("while marshalling session, got: %v", err)
+	}
+	writes := []db.Write{
+		{Table: db.TableSession, ResourceID: session.ID, Data: string(data), Create: create, Expiry: timeout},
+		{Table: db.TableSessionToken, ResourceID: session.TokenHash, Data: session.ID, Create: create, Expiry: timeout},
+	}
+	if err = db.Connector.Transaction(writes); err != nil {
+		return fmt.Errorf("while trying to save session, got: %w", err)
+	}
+	return nil
+}
+
+// GetSession collects the session from the DB
+func GetSession(sessionID string) (*Session, error) {
+	data, err := dbGet(db.TableSession, sessionID)
+	if err != nil {
+		return nil, err
+	}
+	var session Session
+	if err = json.Unmarshal([]byte(data), &session); err != nil {
+		return nil, fmt.Errorf("while trying to unmarshal session, got: %v", err)
+	}
+	return &session, nil
+}
+
+// GetSessionByToken collects the session of the token from the DB
+func GetSessionByToken(token string) (*Session, error) {
+	sessionID, err := dbGet(db.TableSessionToken, SessionTokenHash(token))
+	if err != nil {
+		return nil, err
+	}
+	return GetSession(sessionID)
+}
+
+// GetAllSessionIDs collects the ids of the sessions from the DB
+func GetAllSessionIDs() ([]string, error) {
+	return dbGetAllMatchingKeys(db.TableSession, "*")
+}
+
+// DeleteSession removes the session and the index of its token in a single transaction
+func DeleteSession(session *Session) error {
+	writes := []db.Write{
+		{Table: db.TableSession, ResourceID: session.ID, Delete: true},
+		{Table: db.TableSessionToken, ResourceID: session.TokenHash, Delete: true},
+	}
+	if err := db.Connector.Transaction(writes); err != nil {
+		return fmt.Errorf("while trying to delete session, got: %w", err)
+	}
+	return nil
+}
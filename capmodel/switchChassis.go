@@ -17,9 +17,13 @@ package capmodel
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
+	"path"
+	"strings"
 
 	"github.com/ODIM-Project/ODIM/lib-dmtf/model"
+	"github.com/ODIM-Project/PluginCiscoACI/capdata"
 	"github.com/ODIM-Project/PluginCiscoACI/db"
 )
 
@@ -42,7 +46,7 @@ type SwitchChassisData struct {
 // GetSwitch collects the switch data from the DB
 func GetSwitch(switchID string) (model.Switch, error) {
 	var switchData model.Switch
-	data, err := db.Connector.Get(db.TableSwitch, switchID)
+	data, err := dbGet(db.TableSwitch, switchID)
 	if err != nil {
 		return switchData, err
 	}
@@ -52,15 +56,27 @@ func GetSwitch(switchID string) (model.Switch, error) {
 	return switchData, nil
 }
 
+// SwitchExists checks if the switch is stored in the DB, without reading the switch data
+func SwitchExists(switchID string) (bool, error) {
+	exists, err := dbExists(db.TableSwitch, switchID)
+	if err != nil {
+		return false, fmt.Errorf("while trying to check switch data, got: %w", err)
+	}
+	return exists, nil
+}
+
 // SaveSwitch stores the switch data in the DB
 func SaveSwitch(switchID string, data *model.Switch) error {
+	if err := ValidateSwitchID(switchID); err != nil {
+		return err
+	}
 	return SaveToDB(db.TableSwitch, switchID, *data)
 }
 
 // GetSwitchChassis collects the switch chassis data from the DB
 func GetSwitchChassis(chassisID string) (model.Chassis, error) {
 	var chassis model.Chassis
-	data, err := db.Connector.Get(db.TableSwitchChassis, chassisID)
+	data, err := dbGet(db.TableSwitchChassis, chassisID)
 	if err != nil {
 		return chassis, err
 	}
@@ -73,7 +89,7 @@ func GetSwitchChassis(chassisID string) (model.Chassis, error) {
 // GetAllSwitchChassis collects all the switch chassis data from the DB
 func GetAllSwitchChassis(pattern string) (map[string]model.Chassis, error) {
 	allChassisData := make(map[string]model.Chassis)
-	chassisIDs, err := db.Connector.GetAllMatchingKeys(db.TableSwitchChassis, pattern)
+	chassisIDs, err := dbGetAllMatchingKeys(db.TableSwitchChassis, pattern)
 	if err != nil {
 		return nil, fmt.Errorf("while trying to collect all switch chassis data, got: %w", err)
 	}
@@ -91,3 +107,228 @@ func GetAllSwitchChassis(pattern string) (map[string]model.Chassis, error) {
 func SaveSwitchChassis(chassisID string, data *model.Chassis) error {
 	return SaveToDB(db.TableSwitchChassis, chassisID, *data)
 }
+
+// DeleteSwitch deletes the switch data stored in the DB
+func DeleteSwitch(switchID string) error {
+	if err := db.Connector.Delete(db.TableSwitch, switchID); err != nil {
+		return fmt.Errorf("while trying to remove switch data, got: %w", err)
+	}
+	return nil
+}
+
+// DeleteSwitchChassis deletes the switch chassis data stored in the DB
+func DeleteSwitchChassis(chassisID string) error {
+	if err := db.Connector.Delete(db.TableSwitchChassis, chassisID); err != nil {
+		return fmt.Errorf("while trying to remove switch chassis data, got: %w", err)
+	}
+	return nil
+}
+
+// RenameSwitch re-keys the switch of the fabric from oldSwitchID to newSwitchID, as needed when the
+// switches are re-numbered during a pod migration. The switch, its chassis, the switch-port data and
+// the ports with their state and settings are stored under the new keys with the OIDs embedded in
+// them rewritten, and the switch is renamed in the fabric, all in a single DB transaction.
+// ErrorKeyAlreadyExist is returned when the switch or one of its ports is already present under the
+// new key. The key sets indexing the ports are moved afterwards, as they are only used for counting
+// and filtering ports.
+func RenameSwitch(fabricID, oldSwitchID, newSwitchID string) error {
+	if err := ValidateSwitchID(newSwitchID); err != nil {
+		return err
+	}
+	exists, err := SwitchExists(newSwitchID)
+	if err != nil {
+		return err
+	}
+	if exists {
+		return fmt.Errorf("%w: switch %s is already present", db.ErrorKeyAlreadyExist, newSwitchID)
+	}
+	fabricOID := "/ODIM/v1/Fabrics/" + fabricID
+	rekey := switchRekey{
+		oldID:  oldSwitchID,
+		newID:  newSwitchID,
+		oldOID: fabricOID + "/Switches/" + oldSwitchID,
+		newOID: fabricOID + "/Switches/" + newSwitchID,
+	}
+	var writes []db.Write
+	switchData, err := rekey.document(db.TableSwitch, oldSwitchID)
+	if err != nil {
+		return err
+	}
+	if switchData["UUID"] != nil {
+		switchData["UUID"] = SwitchUUID(newSwitchID)
+	}
+	if writes, err = moveWrites(writes, db.TableSwitch, oldSwitchID, newSwitchID, switchData); err != nil {
+		return err
+	}
+	if chassisOID := switchChassisOID(switchData); chassisOID != "" {
+		chassisID := path.Base(chassisOID)
+		chassis, err := rekey.document(db.TableSwitchChassis, chassisID)
+		if err != nil && !errors.Is(err, db.ErrorKeyNotFound) {
+			return err
+		}
+		if err == nil {
+			if writes, err = updateWrite(writes, db.TableSwitchChassis, chassisID, chassis); err != nil {
+				return err
+			}
+		}
+	}
+	ports, err := GetSwitchPort(oldSwitchID)
+	if err != nil {
+		return err
+	}
+	if writes, err = moveWrites(writes, db.TableSwitchPorts, oldSwitchID, newSwitchID, ports); err != nil {
+		return err
+	}
+	portStates := map[string]PortState{}
+	for _, portID := range ports {
+		oldPortOID, newPortOID := rekey.oldOID+"/Ports/"+portID, rekey.newOID+"/Ports/"+portID
+		port, err := rekey.document(db.TablePort, oldPortOID)
+		if err != nil {
+			return err
+		}
+		if writes, err = moveWrites(writes, db.TablePort, oldPortOID, newPortOID, port); err != nil {
+			return err
+		}
+		settings, err := GetPortSettings(oldPortOID)
+		if err != nil {
+			return err
+		}
+		if settings.Pending != nil || settings.RequestedAt != nil || settings.AdminState != "" {
+			if writes, err = moveWrites(writes, db.TablePortSettings, oldPortOID, newPortOID, settings); err != nil {
+				return err
+			}
+		}
+		state, err := GetPortState(oldPortOID)
+		if errors.Is(err, db.ErrorKeyNotFound) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		if writes, err = moveWrites(writes, db.TablePortState, oldPortOID, newPortOID, state); err != nil {
+			return err
+		}
+		portStates[portID] = state
+	}
+	var fabric capdata.Fabric
+	data, err := dbGet(db.TableFabric, fabricID)
+	if err != nil {
+		return fmt.Errorf("while trying to collect fabric data, got: %w", err)
+	}
+	if err := json.Unmarshal([]byte(data), &fabric); err != nil {
+		return fmt.Errorf("while trying to unmarshal fabric data, got: %v", err)
+	}
+	for i, switchID := range fabric.SwitchData {
+		if switchID == oldSwitchID {
+			fabric.SwitchData[i] = newSwitchID
+		}
+	}
+	if writes, err = updateWrite(writes, db.TableFabric, fabricID, fabric); err != nil {
+		return err
+	}
+	if err := db.Connector.Transaction(writes); err != nil {
+		return fmt.Errorf("while trying to rename switch %s to %s, got: %w", oldSwitchID, newSwitchID, err)
+	}
+	InvalidateFabric(fabricID)
+	for _, portID := range ports {
+		portWritten(rekey.oldOID + "/Ports/" + portID)
+		portWritten(rekey.newOID + "/Ports/" + portID)
+	}
+	return rekey.moveKeySets(ports, portStates)
+}
+
+// switchRekey rewrites the ids and OIDs of a switch and of its children
+type switchRekey struct {
+	oldID, newID   string
+	oldOID, newOID string
+}
+
+// document collects the entry from the DB with the ids and OIDs of the switch rewritten
+func (r switchRekey) document(table, resourceID string) (map[string]interface{}, error) {
+	data, err := dbGet(table, resourceID)
+	if err != nil {
+		return nil, fmt.Errorf("while trying to collect %s data of %s, got: %w", table, resourceID, err)
+	}
+	var document map[string]interface{}
+	if err := json.Unmarshal([]byte(data), &document); err != nil {
+		return nil, fmt.Errorf("while trying to unmarshal %s data of %s, got: %v", table, resourceID, err)
+	}
+	return r.rewrite(document).(map[string]interface{}), nil
+}
+
+// rewrite replaces the id of the switch and the OIDs of the switch and of its children in the value
+func (r switchRekey) rewrite(value interface{}) interface{} {
+	switch v := value.(type) {
+	case string:
+		if v == r.oldID {
+			return r.newID
+		}
+		if v == r.oldOID || strings.HasPrefix(v, r.oldOID+"/") {
+			return r.newOID + strings.TrimPrefix(v, r.oldOID)
+		}
+	case map[string]interface{}:
+		for key, item := range v {
+			v[key] = r.rewrite(item)
+		}
+	case []interface{}:
+		for i, item := range v {
+			v[i] = r.rewrite(item)
+		}
+	}
+	return value
+}
+
+// move adds the writes storing the data under the new key and removing the old key
+func moveWrites(writes []db.Write, table, oldKey, newKey string, data interface{}) ([]db.Write, error) {
+	dataByte, err := json.Marshal(data)
+	if err != nil {
+		return nil, fmt.Errorf("while marshalling data, got: %v", err)
+	}
+	return append(writes,
+		db.Write{Table: table, ResourceID: newKey, Data: string(dataByte), Create: true},
+		db.Write{Table: table, ResourceID: oldKey, Delete: true},
+	), nil
+}
+
+// update adds the write storing the data under the same key
+func updateWrite(writes []db.Write, table, key string, data interface{}) ([]db.Write, error) {
+	dataByte, err := json.Marshal(data)
+	if err != nil {
+		return nil, fmt.Errorf("while marshalling data, got: %v", err)
+	}
+	return append(writes, db.Write{Table: table, ResourceID: key, Data: string(dataByte)}), nil
+}
+
+// moveKeySets moves the ports of the switch to the switch-port key set of the new switch
+// and renames the ports in the health index
+func (r switchRekey) moveKeySets(ports []string, portStates map[string]PortState) error {
+	oldKeySet := fmt.Sprintf("%s:%s", db.TableSwitchPortSet, r.oldID)
+	newKeySet := fmt.Sprintf("%s:%s", db.TableSwitchPortSet, r.newID)
+	for _, portID := range ports {
+		if err := db.Connector.UpdateKeySet(newKeySet, portID); err != nil {
+			return fmt.Errorf("while trying to update switch-port key set members, got: %v", err)
+		}
+		if err := db.Connector.DeleteKeySetMembers(oldKeySet, portID); err != nil {
+			return fmt.Errorf("while trying to remove member from switch-port key set, got: %v", err)
+		}
+		health := portStates[portID].Health
+		if health == "" {
+			continue
+		}
+		if err := db.Connector.UpdateKeySet(portHealthSet(health), r.newOID+"/Ports/"+portID); err != nil {
+			return fmt.Errorf("while trying to update port health key set members, got: %v", err)
+		}
+		if err := db.Connector.DeleteKeySetMembers(portHealthSet(health), r.oldOID+"/Ports/"+portID); err != nil {
+			return fmt.Errorf("while trying to remove member from port health key set, got: %v", err)
+		}
+	}
+	return nil
+}
+
+// switchChassisOID returns the OID of the chassis linked to the switch document
+func switchChassisOID(switchData map[string]interface{}) string {
+	links, _ := switchData["Links"].(map[string]interface{})
+	chassis, _ := links["Chassis"].(map[string]interface{})
+	oid, _ := chassis["@odata.id"].(string)
+	return oid
+}
@@ -0,0 +1,118 @@
+//(C) Copyright [2020] Hewlett Packard Enterprise Development LP
+//
+//Licensed under the Apache License, Version 2.0 (the "License"); you may
+//not use this file except in compliance with the License. You may obtain
+//a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+//Unless required by applicable law or agreed to in writing, software
+//distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+//WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+//License for the specific language governing permissions and limitations
+// under the License.
+
+package capmodel
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/ODIM-Project/ODIM/lib-dmtf/model"
+	"github.com/ODIM-Project/PluginCiscoACI/capdata"
+	"github.com/ODIM-Project/PluginCiscoACI/db"
+)
+
+func TestSwitchExists(t *testing.T) {
+	var gets int64
+	db.Connector = countingConnector{MockMemoryConnector: db.NewMockMemoryConnector(), gets: &gets}
+	if err := SaveSwitch("switchUUID:101", &model.Switch{ID: "switchUUID:101"}); err != nil {
+		t.Fatalf("SaveSwitch() error = %v", err)
+	}
+	tests := []struct {
+		switchID string
+		want     bool
+	}{
+		{"switchUUID:101", true},
+		{"switchUUID:102", false},
+	}
+	for _, tt := range tests {
+		exists, err := SwitchExists(tt.switchID)
+		if err != nil || exists != tt.want {
+			t.Errorf("SwitchExists(%s) = %v, %v, want %v", tt.switchID, exists, err, tt.want)
+		}
+	}
+	if gets != 0 {
+		t.Errorf("SwitchExists() read the switch data %d times, want none", gets)
+	}
+}
+
+func TestRenameSwitch(t *testing.T) {
+	connector := db.NewMockMemoryConnector()
+	db.Connector = connector
+	InvalidatePortCache()
+	InvalidateFabricCache()
+	const (
+		fabricOID = "/ODIM/v1/Fabrics/fabricID"
+		oldOID    = fabricOID + "/Switches/switchUUID:101"
+		newOID    = fabricOID + "/Switches/switchUUID:201"
+	)
+	SaveFabric("fabricID", &capdata.Fabric{PodID: "1", SwitchData: []string{"switchUUID:101", "switchUUID:102"}})
+	SaveSwitch("switchUUID:101", &model.Switch{ODataID: oldOID, ID: "switchUUID:101", UUID: "switchUUID",
+		Links: &model.SwitchLinks{Chassis: &model.Link{Oid: "/ODIM/v1/Chassis/chassisUUID:1"}}})
+	SaveSwitchChassis("chassisUUID:1", &model.Chassis{Oid: "/ODIM/v1/Chassis/chassisUUID:1", ID: "chassisUUID:1",
+		Links: &model.Links{Switches: []*model.Link{{Oid: oldOID}}}})
+	SaveSwitch("switchUUID:102", &model.Switch{ID: "switchUUID:102"})
+	ports := []string{"portUUID:eth1-1", "portUUID:eth1-2"}
+	SaveSwitchPort("switchUUID:101", ports)
+	for _, portID := range ports {
+		SavePort(oldOID+"/Ports/"+portID, &model.Port{ODataID: oldOID + "/Ports/" + portID, ID: portID})
+	}
+	UpdatePortState(oldOID+"/Ports/portUUID:eth1-1", PortState{LinkState: "Enabled", Health: "Warning"})
+
+	// the target key is present
+	if err := RenameSwitch("fabricID", "switchUUID:101", "switchUUID:102"); !errors.Is(err, db.ErrorKeyAlreadyExist) {
+		t.Fatalf("RenameSwitch() to present switch error = %v, want ErrorKeyAlreadyExist", err)
+	}
+	if _, err := GetSwitch("switchUUID:101"); err != nil {
+		t.Fatalf("switch renamed to present switch is no longer stored: %v", err)
+	}
+
+	if err := RenameSwitch("fabricID", "switchUUID:101", "switchUUID:201"); err != nil {
+		t.Fatalf("RenameSwitch() error = %v", err)
+	}
+	for _, key := range connector.Keys() {
+		if strings.Contains(key, "switchUUID:101") {
+			t.Errorf("stale key %s remains after renaming the switch", key)
+		}
+	}
+	switchData, err := GetSwitch("switchUUID:201")
+	if err != nil || switchData.ODataID != newOID || switchData.ID != "switchUUID:201" {
+		t.Errorf("GetSwitch() of renamed switch = %+v, %v", switchData, err)
+	}
+	chassis, err := GetSwitchChassis("chassisUUID:1")
+	if err != nil || chassis.Links.Switches[0].Oid != newOID {
+		t.Errorf("switch link of the chassis = %+v, %v, want %s", chassis.Links, err, newOID)
+	}
+	for _, portID := range ports {
+		port, err := GetPort(newOID + "/Ports/" + portID)
+		if err != nil || port.ODataID != newOID+"/Ports/"+portID {
+			t.Errorf("GetPort() of renamed port %s = %+v, %v", portID, port, err)
+		}
+	}
+	if count, err := CountPorts("switchUUID:201"); err != nil || count != len(ports) {
+		t.Errorf("CountPorts() of renamed switch = %d, %v, want %d", count, err, len(ports))
+	}
+	if state, err := GetPortState(newOID + "/Ports/portUUID:eth1-1"); err != nil || state.Health != "Warning" {
+		t.Errorf("GetPortState() of renamed port = %+v, %v", state, err)
+	}
+	if portOIDs, _ := GetPortsByHealth(newOID, []string{"Warning"}); !reflect.DeepEqual(portOIDs, []string{newOID + "/Ports/portUUID:eth1-1"}) {
+		t.Errorf("GetPortsByHealth() of renamed switch = %v", portOIDs)
+	}
+	fabric, err := GetFabric("fabricID")
+	if err != nil || !reflect.DeepEqual(fabric.SwitchData, []string{"switchUUID:201", "switchUUID:102"}) {
+		t.Errorf("switches of the fabric = %v, %v", fabric.SwitchData, err)
+	}
+}
@@ -0,0 +1,70 @@
+//(C) Copyright [2020] Hewlett Packard Enterprise Development LP
+//
+//Licensed under the Apache License, Version 2.0 (the "License"); you may
+//not use this file except in compliance with the License. You may obtain
+//a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+//Unless required by applicable law or agreed to in writing, software
+//distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+//WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+//License for the specific language governing permissions and limitations
+// under the License.
+
+package capmodel
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// ErrInvalidSwitchID is returned for the switch ids which are not of the canonical format
+var ErrInvalidSwitchID = errors.New("invalid switch id")
+
+// switchIDPattern is the canonical format of the switch ids, <UUID>:<APIC node id> like
+// 5d8e1d2c-7c45-4e6e-9b0d-2f6c1f4b5a31:101. The UUID is generated when the switch is discovered
+// and the node id is the decimal id of the switch in APIC, without leading zeros.
+var switchIDPattern = regexp.MustCompile(`^([0-9A-Za-z-]+):([1-9][0-9]*)$`)
+
+// CanonicalSwitchID returns the switch id in the canonical format, the surrounding spaces
+// and the leading zeros of the node id are removed. ErrInvalidSwitchID is returned when the
+// switch id is not of the format <UUID>:<APIC node id>.
+func CanonicalSwitchID(switchID string) (string, error) {
+	canonical := strings.TrimSpace(switchID)
+	if separator := strings.LastIndex(canonical, ":"); separator >= 0 {
+		nodeID := strings.TrimLeft(canonical[separator+1:], "0")
+		canonical = canonical[:separator+1] + nodeID
+	}
+	if !switchIDPattern.MatchString(canonical) {
+		return "", fmt.Errorf("%w: %q, switch ids are <UUID>:<APIC node id>", ErrInvalidSwitchID, switchID)
+	}
+	return canonical, nil
+}
+
+// ValidateSwitchID returns ErrInvalidSwitchID when the switch id is not in the canonical format
+func ValidateSwitchID(switchID string) error {
+	canonical, err := CanonicalSwitchID(switchID)
+	if err != nil {
+		return err
+	}
+	if canonical != switchID {
+		return fmt.Errorf("%w: %q is not canonical, it should be %q", ErrInvalidSwitchID, switchID, canonical)
+	}
+	return nil
+}
+
+// SwitchUUID returns the UUID of the switch id, which must be valid
+func SwitchUUID(switchID string) string {
+	if separator := strings.LastIndex(switchID, ":"); separator >= 0 {
+		return switchID[:separator]
+	}
+	return ""
+}
+
+// SwitchNodeID returns the APIC node id of the switch id, which must be valid
+func SwitchNodeID(switchID string) string {
+	return switchID[strings.LastIndex(switchID, ":")+1:]
+}
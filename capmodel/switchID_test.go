@@ -0,0 +1,86 @@
+//(C) Copyright [2020] Hewlett Packard Enterprise Development LP
+//
+//Licensed under the Apache License, Version 2.0 (the "License"); you may
+//not use this file except in compliance with the License. You may obtain
+//a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+//Unless required by applicable law or agreed to in writing, software
+//distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+//WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+//License for the specific language governing permissions and limitations
+// under the License.
+
+package capmodel
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestCanonicalSwitchID(t *testing.T) {
+	tests := []struct {
+		name     string
+		switchID string
+		want     string
+		wantErr  bool
+	}{
+		{"discovered", "5d8e1d2c-7c45-4e6e-9b0d-2f6c1f4b5a31:101", "5d8e1d2c-7c45-4e6e-9b0d-2f6c1f4b5a31:101", false},
+		{"canonical", "switchUUID:101", "switchUUID:101", false},
+		{"surrounding spaces", " switchUUID:101\n", "switchUUID:101", false},
+		{"node id with leading zeros", "switchUUID:0101", "switchUUID:101", false},
+		{"empty", "", "", true},
+		{"without node id", "switchUUID", "", true},
+		{"empty node id", "switchUUID:", "", true},
+		{"empty uuid", ":101", "", true},
+		{"node id not a number", "switchUUID:leaf101", "", true},
+		{"zero node id", "switchUUID:000", "", true},
+		{"negative node id", "switchUUID:-101", "", true},
+		{"extra separator", "switch:UUID:101", "", true},
+		{"uuid with spaces", "switch UUID:101", "", true},
+		{"path separator", "switchUUID/101:101", "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := CanonicalSwitchID(tt.switchID)
+			if tt.wantErr {
+				if !errors.Is(err, ErrInvalidSwitchID) {
+					t.Fatalf("CanonicalSwitchID(%q) error = %v, want ErrInvalidSwitchID", tt.switchID, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("CanonicalSwitchID(%q) error = %v", tt.switchID, err)
+			}
+			if got != tt.want {
+				t.Errorf("CanonicalSwitchID(%q) = %q, want %q", tt.switchID, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateSwitchID(t *testing.T) {
+	if err := ValidateSwitchID("switchUUID:101"); err != nil {
+		t.Errorf("ValidateSwitchID() of canonical id error = %v", err)
+	}
+	// valid ids which are not canonical are rejected, so that a switch is stored under a single id
+	for _, switchID := range []string{"switchUUID:0101", " switchUUID:101", "switchUUID", "switchUUID:eth1"} {
+		if err := ValidateSwitchID(switchID); !errors.Is(err, ErrInvalidSwitchID) {
+			t.Errorf("ValidateSwitchID(%q) error = %v, want ErrInvalidSwitchID", switchID, err)
+		}
+	}
+	if err := SaveSwitch("switchUUID:0101", nil); !errors.Is(err, ErrInvalidSwitchID) {
+		t.Errorf("SaveSwitch() of not canonical id error = %v, want ErrInvalidSwitchID", err)
+	}
+}
+
+func TestSwitchIDParts(t *testing.T) {
+	switchID := "5d8e1d2c-7c45-4e6e-9b0d-2f6c1f4b5a31:101"
+	if got := SwitchUUID(switchID); got != "5d8e1d2c-7c45-4e6e-9b0d-2f6c1f4b5a31" {
+		t.Errorf("SwitchUUID() = %q", got)
+	}
+	if got := SwitchNodeID(switchID); got != "101" {
+		t.Errorf("SwitchNodeID() = %q", got)
+	}
+}
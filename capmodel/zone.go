@@ -27,7 +27,7 @@ import (
 func GetZone(fabricID, zoneURI string) (model.Zone, error) {
 	var zone model.Zone
 	key := fmt.Sprintf("%s:%s", fabricID, zoneURI)
-	data, err := db.Connector.Get(db.TableZone, key)
+	data, err := dbGet(db.TableZone, key)
 	if err != nil {
 		return zone, fmt.Errorf("while trying to collect zone data, got: %w", err)
 	}
@@ -40,7 +40,7 @@ func GetZone(fabricID, zoneURI string) (model.Zone, error) {
 // GetZoneDomain collects the ZoneToDomainDN data from the DB
 func GetZoneDomain(zoneURI string) (capdata.ACIDomainData, error) {
 	var domainData capdata.ACIDomainData
-	data, err := db.Connector.Get(db.TableZoneDomain, zoneURI)
+	data, err := dbGet(db.TableZoneDomain, zoneURI)
 	if err != nil {
 		return domainData, fmt.Errorf("while trying to collect zone domain data, got: %w", err)
 	}
@@ -54,7 +54,7 @@ func GetZoneDomain(zoneURI string) (capdata.ACIDomainData, error) {
 func GetAllZones(fabricID string) (map[string]model.Zone, error) {
 	allZones := make(map[string]model.Zone)
 	keySet := fmt.Sprintf("%s:%s", db.TableZone, fabricID)
-	zoneURIs, err := db.Connector.GetKeySetMembers(keySet)
+	zoneURIs, err := dbGetKeySetMembers(keySet)
 	if err != nil {
 		return nil, fmt.Errorf("while trying to collect all zone keys, got: %w", err)
 	}
@@ -21,3 +21,15 @@ type Device struct {
 	Username   string `json:"Username"`
 	DeviceUUID string `json:"device_UUID"`
 }
+
+//Session is the Redfish session created by the plugin, the token is only returned in the
+//X-Auth-Token header of the response to the session creation
+type Session struct {
+	OdataContext string `json:"@odata.context"`
+	OdataID      string `json:"@odata.id"`
+	OdataType    string `json:"@odata.type"`
+	ID           string `json:"Id"`
+	Name         string `json:"Name"`
+	UserName     string `json:"UserName"`
+	CreatedTime  string `json:"CreatedTime"`
+}
@@ -28,3 +28,17 @@ func SetErrorResponse(ctx iris.Context, statusCode int32, statusMsg, errMsg stri
 	common.SetResponseHeader(ctx, resp.Header)
 	ctx.JSON(resp.Body)
 }
+
+//ProblemDetails is the RFC 7807 problem details of an error, reported instead of the Redfish error to the
+//clients accepting application/problem+json. Code, MessageID and MessageArgs are the extension members
+//holding the code of the Redfish error and the id and arguments of its message.
+type ProblemDetails struct {
+	Type        string        `json:"type"`
+	Title       string        `json:"title"`
+	Status      int           `json:"status"`
+	Detail      string        `json:"detail,omitempty"`
+	Instance    string        `json:"instance,omitempty"`
+	Code        string        `json:"code,omitempty"`
+	MessageID   string        `json:"messageId,omitempty"`
+	MessageArgs []interface{} `json:"messageArgs,omitempty"`
+}
@@ -0,0 +1,88 @@
+//(C) Copyright [2020] Hewlett Packard Enterprise Development LP
+//
+//Licensed under the Apache License, Version 2.0 (the "License"); you may
+//not use this file except in compliance with the License. You may obtain
+//a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+//Unless required by applicable law or agreed to in writing, software
+//distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+//WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+//License for the specific language governing permissions and limitations
+// under the License.
+
+package capresponse
+
+import (
+	"github.com/ODIM-Project/ODIM/lib-dmtf/model"
+)
+
+//MetricReportDefinition describes the metrics of a metric report of the plugin and how the report is generated
+type MetricReportDefinition struct {
+	ODataID                    string                `json:"@odata.id"`
+	ODataType                  string                `json:"@odata.type"`
+	ID                         string                `json:"Id"`
+	Name                       string                `json:"Name"`
+	Description                string                `json:"Description"`
+	MetricReportDefinitionType string                `json:"MetricReportDefinitionType"`
+	ReportActions              []string              `json:"ReportActions"`
+	ReportUpdates              string                `json:"ReportUpdates"`
+	Schedule                   *MetricReportSchedule `json:"Schedule,omitempty"`
+	Metrics                    []MetricReportMetric  `json:"Metrics"`
+	MetricReport               *model.Link           `json:"MetricReport"`
+	Links                      *MetricReportDefLinks `json:"Links,omitempty"`
+}
+
+//MetricReportSchedule holds the interval of the periodic metric reports as an ISO 8601 duration
+type MetricReportSchedule struct {
+	RecurrenceInterval string `json:"RecurrenceInterval"`
+}
+
+//MetricReportMetric describes a metric of the metric report, the wildcards of the MetricProperties
+//stand for the fabrics, the switches and the ports
+type MetricReportMetric struct {
+	MetricID           string   `json:"MetricId"`
+	MetricProperties   []string `json:"MetricProperties"`
+	CollectionDuration string   `json:"CollectionDuration"`
+}
+
+//MetricReportDefLinks holds the definitions of the metrics of the metric report
+type MetricReportDefLinks struct {
+	MetricDefinitions []model.Link `json:"MetricDefinitions"`
+}
+
+//MetricDefinition describes the data type and the units of a metric
+type MetricDefinition struct {
+	ODataID            string `json:"@odata.id"`
+	ODataType          string `json:"@odata.type"`
+	ID                 string `json:"Id"`
+	Name               string `json:"Name"`
+	Description        string `json:"Description"`
+	MetricType         string `json:"MetricType"`
+	MetricDataType     string `json:"MetricDataType"`
+	Units              string `json:"Units"`
+	IsLinear           bool   `json:"IsLinear"`
+	CollectionDuration string `json:"CollectionDuration"`
+}
+
+//MetricReport holds the metric values of a metric report, generated when requested or periodically
+type MetricReport struct {
+	ODataID                string        `json:"@odata.id"`
+	ODataType              string        `json:"@odata.type"`
+	ID                     string        `json:"Id"`
+	Name                   string        `json:"Name"`
+	Timestamp              string        `json:"Timestamp"`
+	MetricReportDefinition *model.Link   `json:"MetricReportDefinition"`
+	MetricValues           []MetricValue `json:"MetricValues"`
+	MetricValuesCount      int           `json:"MetricValues@odata.count"`
+}
+
+//MetricValue holds a value of a metric of a port, the value is a string as defined by Redfish and
+//is typed by the MetricDataType of the definition of the metric
+type MetricValue struct {
+	MetricID       string `json:"MetricId"`
+	MetricValue    string `json:"MetricValue"`
+	Timestamp      string `json:"Timestamp"`
+	MetricProperty string `json:"MetricProperty"`
+}
@@ -26,9 +26,29 @@ type PluginStatusResponse struct {
 
 //Status holds information of Plugin Status
 type Status struct {
-	Available string `json:"Available"`
-	Uptime    string `json:"Uptime"`
-	TimeStamp string `json:"TimeStamp"`
+	Available     string `json:"Available"`
+	StartTime     string `json:"StartTime"`
+	Uptime        string `json:"Uptime"`
+	UptimeSeconds int64  `json:"UptimeSeconds"`
+	TimeStamp     string `json:"TimeStamp"`
+	//MaintenanceMode is set while the plugin rejects the write requests for the maintenance of the fabric
+	MaintenanceMode   bool   `json:"MaintenanceMode"`
+	MaintenanceReason string `json:"MaintenanceReason,omitempty"`
+}
+
+//ReadinessResponse holds the information of response of plugin readiness, the plugin is ready
+//when both the DB and APIC are healthy
+type ReadinessResponse struct {
+	Ready  bool             `json:"Ready"`
+	Status Status           `json:"Status"`
+	DB     DependencyHealth `json:"DB"`
+	APIC   DependencyHealth `json:"APIC"`
+}
+
+//DependencyHealth holds the health of a dependency of the plugin, with the error when it is unhealthy
+type DependencyHealth struct {
+	Healthy bool   `json:"Healthy"`
+	Error   string `json:"Error,omitempty"`
 }
 
 //EventMessageBus holds the  information of  EMB Broker type and EMBQueue information
@@ -42,3 +62,9 @@ type EmbQueue struct {
 	QueueName string `json:"EmbQueueName"`
 	QueueDesc string `json:"EmbQueueDesc"`
 }
+
+//APICSubscriptionResponse holds the information of the APIC websocket subscriptions
+type APICSubscriptionResponse struct {
+	Subscribed bool     `json:"Subscribed"`
+	Classes    []string `json:"Classes"`
+}
@@ -0,0 +1,324 @@
+//(C) Copyright [2020] Hewlett Packard Enterprise Development LP
+//
+//Licensed under the Apache License, Version 2.0 (the "License"); you may
+//not use this file except in compliance with the License. You may obtain
+//a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+//Unless required by applicable law or agreed to in writing, software
+//distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+//WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+//License for the specific language governing permissions and limitations
+// under the License.
+
+// Package capresponse ...
+package capresponse
+
+import (
+	"encoding/xml"
+
+	"github.com/ODIM-Project/ODIM/lib-dmtf/model"
+)
+
+// Port holds the port resource with the CiscoACI OEM properties of the port
+type Port struct {
+	*model.Port
+	Settings *Settings    `json:"@Redfish.Settings,omitempty"`
+	Actions  *PortActions `json:"Actions,omitempty"`
+	Oem      *PortOem     `json:"Oem,omitempty"`
+}
+
+// PortActions holds the actions enabled on the port
+type PortActions struct {
+	Reset *ResetAction `json:"#Port.Reset,omitempty"`
+}
+
+// ResetAction holds the target of a reset action with the reset types it supports
+type ResetAction struct {
+	Target          string   `json:"target"`
+	AllowableValues []string `json:"ResetType@Redfish.AllowableValues"`
+}
+
+// Settings is the @Redfish.Settings annotation of a resource whose settings are changed through
+// its SettingsObject, Time is when the requested settings were last applied
+type Settings struct {
+	ODataType      string     `json:"@odata.type"`
+	SettingsObject model.Link `json:"SettingsObject"`
+	Time           string     `json:"Time,omitempty"`
+}
+
+// PreferredApplyTime is the @Redfish.SettingsApplyTime annotation of a settings resource
+type PreferredApplyTime struct {
+	ODataType string `json:"@odata.type"`
+	ApplyTime string `json:"ApplyTime"`
+}
+
+// PortSettings holds the Settings resource of a port, with the values of the properties
+// requested and not yet applied by APIC
+type PortSettings struct {
+	ODataID           string             `json:"@odata.id"`
+	ODataType         string             `json:"@odata.type"`
+	ID                string             `json:"Id"`
+	Name              string             `json:"Name"`
+	SettingsApplyTime PreferredApplyTime `json:"@Redfish.SettingsApplyTime"`
+	Description       *string            `json:"Description,omitempty"`
+	CurrentSpeedGbps  *float64           `json:"CurrentSpeedGbps,omitempty"`
+}
+
+// PortOem holds the OEM properties of the port
+type PortOem struct {
+	CiscoACI PortOemCiscoACI `json:"CiscoACI"`
+}
+
+// PortOemCiscoACI holds the properties of the port in APIC, DistinguishedName is the
+// DN of the physical interface which can be used with the APIC API inspector or moquery
+type PortOemCiscoACI struct {
+	DistinguishedName string           `json:"DistinguishedName"`
+	StatisticsHistory *model.Link      `json:"StatisticsHistory,omitempty"`
+	Transceiver       *PortTransceiver `json:"Transceiver,omitempty"`
+	Neighbors         []PortNeighbor   `json:"Neighbors,omitempty"`
+	Conditions        []PortCondition  `json:"Conditions,omitempty"`
+}
+
+// PortCondition notes a condition affecting what is reported for the port, like its health
+// reported with the fallback value as it couldn't be read from APIC
+type PortCondition struct {
+	Message   string `json:"Message" xml:"Message"`
+	Severity  string `json:"Severity" xml:"Severity"`
+	Timestamp string `json:"Timestamp" xml:"Timestamp"`
+}
+
+// PortTransceiver holds the inventory of the transceiver plugged in the port as read from APIC
+type PortTransceiver struct {
+	Vendor               string   `json:"Vendor" xml:"Vendor"`
+	PartNumber           string   `json:"PartNumber" xml:"PartNumber"`
+	SerialNumber         string   `json:"SerialNumber" xml:"SerialNumber"`
+	Type                 string   `json:"Type" xml:"Type"`
+	WavelengthNanometers *float64 `json:"WavelengthNanometers,omitempty" xml:"WavelengthNanometers,omitempty"`
+}
+
+// PortNeighbor holds a device connected to the port as discovered by LLDP or CDP, ChassisId is
+// the device id of the CDP neighbors
+type PortNeighbor struct {
+	Protocol   string `json:"Protocol" xml:"Protocol"`
+	ChassisID  string `json:"ChassisId" xml:"ChassisId"`
+	PortID     string `json:"PortId" xml:"PortId"`
+	SystemName string `json:"SystemName,omitempty" xml:"SystemName,omitempty"`
+}
+
+// PortStatisticsHistory holds the traffic history of a port collected from APIC at the Granularity,
+// Samples are ordered from the oldest to the most recent interval
+type PortStatisticsHistory struct {
+	ODataID      string                 `json:"@odata.id"`
+	ODataType    string                 `json:"@odata.type"`
+	ID           string                 `json:"Id"`
+	Name         string                 `json:"Name"`
+	Granularity  string                 `json:"Granularity"`
+	Samples      []PortStatisticsSample `json:"Samples"`
+	SamplesCount int                    `json:"Samples@odata.count"`
+}
+
+// PortStatisticsSample holds the bytes received and transmitted by the port over one interval,
+// the interval bounds are in the format reported by APIC
+type PortStatisticsSample struct {
+	IntervalStart string `json:"IntervalStart"`
+	IntervalEnd   string `json:"IntervalEnd"`
+	RXBytes       uint64 `json:"RXBytes"`
+	TXBytes       uint64 `json:"TXBytes"`
+}
+
+// PortFaults holds the ports with an APIC fault of MinSeverity or more severe, ordered from the most severe
+type PortFaults struct {
+	ODataID      string            `json:"@odata.id"`
+	ODataType    string            `json:"@odata.type"`
+	ID           string            `json:"Id"`
+	Name         string            `json:"Name"`
+	MinSeverity  string            `json:"MinSeverity"`
+	Members      []PortFaultMember `json:"Members"`
+	MembersCount int               `json:"Members@odata.count"`
+}
+
+// PortFaultMember holds the worst APIC fault of a port, Created is in the format reported by APIC
+type PortFaultMember struct {
+	Port        *model.Link `json:"Port"`
+	Code        string      `json:"Code"`
+	Severity    string      `json:"Severity"`
+	Description string      `json:"Description"`
+	Created     string      `json:"Created"`
+}
+
+// PortsByHealth holds the ports whose last known health is one of Health, ordered by health and port
+type PortsByHealth struct {
+	ODataID      string             `json:"@odata.id"`
+	ODataType    string             `json:"@odata.type"`
+	ID           string             `json:"Id"`
+	Name         string             `json:"Name"`
+	Health       []string           `json:"Health"`
+	Members      []PortHealthMember `json:"Members"`
+	MembersCount int                `json:"Members@odata.count"`
+}
+
+// PortHealthMember holds the last known health of a port
+type PortHealthMember struct {
+	Port   *model.Link `json:"Port"`
+	Health string      `json:"Health"`
+}
+
+// PortXML holds the XML representation of the port resource
+type PortXML struct {
+	XMLName               xml.Name        `xml:"Port"`
+	ODataContext          string          `xml:"OdataContext,omitempty"`
+	ODataID               string          `xml:"OdataId"`
+	ODataType             string          `xml:"OdataType"`
+	ID                    string          `xml:"Id"`
+	Name                  string          `xml:"Name"`
+	PortID                string          `xml:"PortId,omitempty"`
+	PortProtocol          string          `xml:"PortProtocol,omitempty"`
+	PortType              string          `xml:"PortType,omitempty"`
+	LinkNetworkTechnology string          `xml:"LinkNetworkTechnology,omitempty"`
+	MaxFrameSize          int             `xml:"MaxFrameSize,omitempty"`
+	LinkState             string          `xml:"LinkState,omitempty"`
+	LinkStatus            string          `xml:"LinkStatus,omitempty"`
+	InterfaceEnabled      bool            `xml:"InterfaceEnabled"`
+	CurrentSpeedGbps      float64         `xml:"CurrentSpeedGbps"`
+	Status                *StatusXML      `xml:"Status,omitempty"`
+	Links                 *PortLinksXML   `xml:"Links,omitempty"`
+	Settings              *SettingsXML    `xml:"RedfishSettings,omitempty"`
+	Actions               *PortActionsXML `xml:"Actions,omitempty"`
+	Oem                   *PortOemXML     `xml:"Oem,omitempty"`
+}
+
+// SettingsXML holds the XML representation of the @Redfish.Settings annotation
+type SettingsXML struct {
+	SettingsObject string `xml:"SettingsObject>OdataId"`
+	Time           string `xml:"Time,omitempty"`
+}
+
+// PortActionsXML holds the XML representation of the actions enabled on the port
+type PortActionsXML struct {
+	Reset *ResetActionXML `xml:"PortReset,omitempty"`
+}
+
+// ResetActionXML holds the XML representation of a reset action
+type ResetActionXML struct {
+	Target          string   `xml:"Target"`
+	AllowableValues []string `xml:"ResetTypeAllowableValues>ResetType"`
+}
+
+// PortOemXML holds the XML representation of the OEM properties of the port
+type PortOemXML struct {
+	CiscoACI PortOemCiscoACIXML `xml:"CiscoACI"`
+}
+
+// PortOemCiscoACIXML holds the XML representation of the properties of the port in APIC
+type PortOemCiscoACIXML struct {
+	DistinguishedName string           `xml:"DistinguishedName"`
+	StatisticsHistory string           `xml:"StatisticsHistory>OdataId,omitempty"`
+	Transceiver       *PortTransceiver `xml:"Transceiver,omitempty"`
+	Neighbors         []PortNeighbor   `xml:"Neighbors>Neighbor,omitempty"`
+	Conditions        []PortCondition  `xml:"Conditions>Condition,omitempty"`
+}
+
+// StatusXML holds the XML representation of the resource status
+type StatusXML struct {
+	State  string `xml:"State,omitempty"`
+	Health string `xml:"Health,omitempty"`
+}
+
+// PortLinksXML holds the XML representation of the port links
+type PortLinksXML struct {
+	ConnectedPorts []string `xml:"ConnectedPorts>OdataId"`
+}
+
+// CollectionXML holds the XML representation of a resource collection
+type CollectionXML struct {
+	XMLName      xml.Name `xml:"Collection"`
+	ODataContext string   `xml:"OdataContext,omitempty"`
+	ODataID      string   `xml:"OdataId"`
+	ODataType    string   `xml:"OdataType"`
+	Description  string   `xml:"Description,omitempty"`
+	Name         string   `xml:"Name"`
+	Members      []string `xml:"Members>OdataId"`
+	MembersCount int      `xml:"MembersCount"`
+}
+
+// CollectionResponse holds a page of a resource collection, Members@odata.count is the total
+// number of members and Members@odata.nextLink points to the next page
+type CollectionResponse struct {
+	model.Collection
+	NextLink string `json:"Members@odata.nextLink,omitempty"`
+}
+
+// NewPortXML builds the XML representation of the given port with its settings annotation, actions and
+// OEM properties. The annotations and the action names which are not valid XML names are renamed, like
+// @Redfish.Settings to RedfishSettings and #Port.Reset to PortReset.
+func NewPortXML(resp Port) PortXML {
+	port := resp.Port
+	portXML := PortXML{
+		ODataContext:          port.ODataContext,
+		ODataID:               port.ODataID,
+		ODataType:             port.ODataType,
+		ID:                    port.ID,
+		Name:                  port.Name,
+		PortID:                port.PortID,
+		PortProtocol:          string(port.PortProtocol),
+		PortType:              string(port.PortType),
+		LinkNetworkTechnology: string(port.LinkNetworkTechnology),
+		MaxFrameSize:          port.MaxFrameSize,
+		LinkState:             string(port.LinkState),
+		LinkStatus:            string(port.LinkStatus),
+		InterfaceEnabled:      port.InterfaceEnabled,
+		CurrentSpeedGbps:      port.CurrentSpeedGbps,
+	}
+	if port.Status != nil {
+		portXML.Status = &StatusXML{
+			State:  string(port.Status.State),
+			Health: string(port.Status.Health),
+		}
+	}
+	if port.Links != nil {
+		portXML.Links = &PortLinksXML{}
+		for _, link := range port.Links.ConnectedPorts {
+			portXML.Links.ConnectedPorts = append(portXML.Links.ConnectedPorts, link.Oid)
+		}
+	}
+	if resp.Settings != nil {
+		portXML.Settings = &SettingsXML{SettingsObject: resp.Settings.SettingsObject.Oid, Time: resp.Settings.Time}
+	}
+	if resp.Actions != nil && resp.Actions.Reset != nil {
+		portXML.Actions = &PortActionsXML{Reset: &ResetActionXML{
+			Target:          resp.Actions.Reset.Target,
+			AllowableValues: resp.Actions.Reset.AllowableValues,
+		}}
+	}
+	if resp.Oem != nil {
+		oem := resp.Oem.CiscoACI
+		portXML.Oem = &PortOemXML{CiscoACI: PortOemCiscoACIXML{
+			DistinguishedName: oem.DistinguishedName,
+			Transceiver:       oem.Transceiver,
+			Neighbors:         oem.Neighbors,
+			Conditions:        oem.Conditions,
+		}}
+		if oem.StatisticsHistory != nil {
+			portXML.Oem.CiscoACI.StatisticsHistory = oem.StatisticsHistory.Oid
+		}
+	}
+	return portXML
+}
+
+// NewCollectionXML builds the XML representation of the given collection
+func NewCollectionXML(collection model.Collection) CollectionXML {
+	collectionXML := CollectionXML{
+		ODataContext: collection.ODataContext,
+		ODataID:      collection.ODataID,
+		ODataType:    collection.ODataType,
+		Description:  collection.Description,
+		Name:         collection.Name,
+		MembersCount: collection.MembersCount,
+	}
+	for _, member := range collection.Members {
+		collectionXML.Members = append(collectionXML.Members, member.Oid)
+	}
+	return collectionXML
+}
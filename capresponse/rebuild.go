@@ -0,0 +1,38 @@
+//(C) Copyright [2020] Hewlett Packard Enterprise Development LP
+//
+//Licensed under the Apache License, Version 2.0 (the "License"); you may
+//not use this file except in compliance with the License. You may obtain
+//a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+//Unless required by applicable law or agreed to in writing, software
+//distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+//WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+//License for the specific language governing permissions and limitations
+// under the License.
+
+package capresponse
+
+//FabricRebuildResponse holds the number of objects of the fabric removed from the DB and
+//recreated from APIC by the rebuild, the objects are the fabric, switches, chassis and ports
+type FabricRebuildResponse struct {
+	FabricID  string `json:"FabricId"`
+	Removed   int    `json:"Removed"`
+	Recreated int    `json:"Recreated"`
+}
+
+//FabricRepairPortsResponse holds the number of ports listed more than once for the switches
+//of the fabric which were removed from the switch-port data by the repair
+type FabricRepairPortsResponse struct {
+	FabricID          string `json:"FabricId"`
+	DuplicatesRemoved int    `json:"DuplicatesRemoved"`
+}
+
+//SwitchDiscoveryResponse holds the number of ports of the switch added to the DB and removed from it
+//by the discovery of the ports of the switch from APIC
+type SwitchDiscoveryResponse struct {
+	SwitchID string `json:"SwitchId"`
+	Added    int    `json:"Added"`
+	Removed  int    `json:"Removed"`
+}
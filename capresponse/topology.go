@@ -0,0 +1,47 @@
+//(C) Copyright [2020] Hewlett Packard Enterprise Development LP
+//
+//Licensed under the Apache License, Version 2.0 (the "License"); you may
+//not use this file except in compliance with the License. You may obtain
+//a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+//Unless required by applicable law or agreed to in writing, software
+//distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+//WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+//License for the specific language governing permissions and limitations
+// under the License.
+
+//Package capresponse ...
+package capresponse
+
+// Node types and edge types of the fabric topology graph
+const (
+	TopologyNodeSwitch            = "Switch"
+	TopologyNodePort              = "Port"
+	TopologyNodeEthernetInterface = "EthernetInterface"
+	TopologyEdgeContains          = "Contains"
+	TopologyEdgeConnectedTo       = "ConnectedTo"
+)
+
+//TopologyGraph holds the fabric topology as a graph of the switches, ports and connected
+//ethernet interfaces, nodes and edges are sorted so that the same topology gives the same graph
+type TopologyGraph struct {
+	FabricID string         `json:"FabricId"`
+	Nodes    []TopologyNode `json:"Nodes"`
+	Edges    []TopologyEdge `json:"Edges"`
+}
+
+//TopologyNode holds a resource of the topology, Id is the @odata.id of the resource
+type TopologyNode struct {
+	ID   string `json:"Id"`
+	Type string `json:"Type"`
+	Name string `json:"Name,omitempty"`
+}
+
+//TopologyEdge holds a relation between the nodes Source and Target of the topology
+type TopologyEdge struct {
+	Source string `json:"Source"`
+	Target string `json:"Target"`
+	Type   string `json:"Type"`
+}
@@ -0,0 +1,246 @@
+//(C) Copyright [2020] Hewlett Packard Enterprise Development LP
+//
+//Licensed under the Apache License, Version 2.0 (the "License"); you may
+//not use this file except in compliance with the License. You may obtain
+//a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+//Unless required by applicable law or agreed to in writing, software
+//distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+//WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+//License for the specific language governing permissions and limitations
+// under the License.
+
+package captrace
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/ODIM-Project/PluginCiscoACI/config"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	otlpStatusOk    = 1
+	otlpStatusError = 2
+	exportTimeout   = 5 * time.Second
+	// exportQueueSize is the number of finished spans waiting for the OTLP export,
+	// the spans finished while the queue is full are dropped
+	exportQueueSize = 2048
+	// exportBatchSize is the largest number of spans sent in an OTLP request
+	exportBatchSize = 512
+	// exportInterval is the longest time a finished span waits in the queue before it is sent
+	exportInterval = 5 * time.Second
+)
+
+// exporter sends the finished spans to the configured destination
+type exporter interface {
+	export(span *Span)
+}
+
+var spanExporter exporter = configuredExporter{}
+
+// configuredExporter exports the span with the exporter configured in OTelConf
+type configuredExporter struct{}
+
+func (e configuredExporter) export(span *Span) {
+	switch config.Data.OTelConf.Exporter {
+	case "otlphttp":
+		otlpExportQueue().enqueue(span)
+	default:
+		logExport(span)
+	}
+}
+
+// otlpBatcher queues the finished spans and sends them in batches from a single goroutine, so
+// that the export neither blocks the requests nor grows with their number
+type otlpBatcher struct {
+	spans     chan *Span
+	batchSize int
+	interval  time.Duration
+	send      func(spans []*Span)
+	dropped   uint64
+}
+
+var (
+	otlpQueue     *otlpBatcher
+	otlpQueueOnce sync.Once
+)
+
+// otlpExportQueue returns the queue of the OTLP export, started on the first exported span
+func otlpExportQueue() *otlpBatcher {
+	otlpQueueOnce.Do(func() {
+		otlpQueue = newOTLPBatcher(exportQueueSize, exportBatchSize, exportInterval, func(spans []*Span) {
+			otlpHTTPExport(config.Data.OTelConf.Endpoint, config.Data.OTelConf.ServiceName, spans)
+		})
+		go otlpQueue.run()
+	})
+	return otlpQueue
+}
+
+func newOTLPBatcher(queueSize, batchSize int, interval time.Duration, send func(spans []*Span)) *otlpBatcher {
+	return &otlpBatcher{
+		spans:     make(chan *Span, queueSize),
+		batchSize: batchSize,
+		interval:  interval,
+		send:      send,
+	}
+}
+
+// enqueue adds the span to the queue without waiting, the span is dropped when the queue is full
+func (b *otlpBatcher) enqueue(span *Span) {
+	select {
+	case b.spans <- span:
+	default:
+		atomic.AddUint64(&b.dropped, 1)
+	}
+}
+
+// run sends the queued spans once a batch is full, and the partial batch at each interval
+func (b *otlpBatcher) run() {
+	ticker := time.NewTicker(b.interval)
+	defer ticker.Stop()
+	batch := make([]*Span, 0, b.batchSize)
+	flush := func() {
+		if dropped := atomic.SwapUint64(&b.dropped, 0); dropped > 0 {
+			log.Warn("the OTLP export queue was full, dropped " + strconv.FormatUint(dropped, 10) + " spans")
+		}
+		if len(batch) == 0 {
+			return
+		}
+		b.send(batch)
+		batch = make([]*Span, 0, b.batchSize)
+	}
+	for {
+		select {
+		case span := <-b.spans:
+			if batch = append(batch, span); len(batch) >= b.batchSize {
+				flush()
+			}
+		case <-ticker.C:
+			flush()
+		}
+	}
+}
+
+func logExport(span *Span) {
+	fields := log.Fields{
+		"traceID":    span.TraceID,
+		"spanID":     span.SpanID,
+		"parentSpan": span.ParentSpanID,
+		"durationMs": span.EndTime.Sub(span.StartTime).Milliseconds(),
+	}
+	for key, value := range span.Attributes {
+		fields[key] = value
+	}
+	if span.Error != "" {
+		fields["error"] = span.Error
+	}
+	log.WithFields(fields).Info("span " + span.Name)
+}
+
+type otlpAttribute struct {
+	Key   string `json:"key"`
+	Value struct {
+		StringValue string `json:"stringValue"`
+	} `json:"value"`
+}
+
+type otlpSpan struct {
+	TraceID           string          `json:"traceId"`
+	SpanID            string          `json:"spanId"`
+	ParentSpanID      string          `json:"parentSpanId,omitempty"`
+	Name              string          `json:"name"`
+	Kind              int             `json:"kind"`
+	StartTimeUnixNano string          `json:"startTimeUnixNano"`
+	EndTimeUnixNano   string          `json:"endTimeUnixNano"`
+	Attributes        []otlpAttribute `json:"attributes"`
+	Status            struct {
+		Code    int    `json:"code"`
+		Message string `json:"message,omitempty"`
+	} `json:"status"`
+}
+
+// otlpRequest is the OTLP/HTTP JSON encoding of the ExportTraceServiceRequest
+type otlpRequest struct {
+	ResourceSpans []otlpResourceSpans `json:"resourceSpans"`
+}
+
+type otlpResourceSpans struct {
+	Resource struct {
+		Attributes []otlpAttribute `json:"attributes"`
+	} `json:"resource"`
+	ScopeSpans []otlpScopeSpans `json:"scopeSpans"`
+}
+
+type otlpScopeSpans struct {
+	Scope struct {
+		Name string `json:"name"`
+	} `json:"scope"`
+	Spans []otlpSpan `json:"spans"`
+}
+
+func newOTLPAttribute(key, value string) otlpAttribute {
+	attribute := otlpAttribute{Key: key}
+	attribute.Value.StringValue = value
+	return attribute
+}
+
+func newOTLPSpan(span *Span) otlpSpan {
+	s := otlpSpan{
+		TraceID:           span.TraceID,
+		SpanID:            span.SpanID,
+		ParentSpanID:      span.ParentSpanID,
+		Name:              span.Name,
+		Kind:              span.Kind,
+		StartTimeUnixNano: strconv.FormatInt(span.StartTime.UnixNano(), 10),
+		EndTimeUnixNano:   strconv.FormatInt(span.EndTime.UnixNano(), 10),
+	}
+	for key, value := range span.Attributes {
+		s.Attributes = append(s.Attributes, newOTLPAttribute(key, value))
+	}
+	s.Status.Code = otlpStatusOk
+	if span.Error != "" {
+		s.Status.Code, s.Status.Message = otlpStatusError, span.Error
+	}
+	return s
+}
+
+func newOTLPRequest(serviceName string, spans []*Span) otlpRequest {
+	scopeSpans := otlpScopeSpans{Spans: make([]otlpSpan, 0, len(spans))}
+	for _, span := range spans {
+		scopeSpans.Spans = append(scopeSpans.Spans, newOTLPSpan(span))
+	}
+	scopeSpans.Scope.Name = config.DefaultOTelServiceName
+	resourceSpans := otlpResourceSpans{ScopeSpans: []otlpScopeSpans{scopeSpans}}
+	resourceSpans.Resource.Attributes = []otlpAttribute{newOTLPAttribute("service.name", serviceName)}
+	return otlpRequest{ResourceSpans: []otlpResourceSpans{resourceSpans}}
+}
+
+// otlpClient is the HTTP client of the OTLP export, shared by the batches
+var otlpClient = &http.Client{Timeout: exportTimeout}
+
+func otlpHTTPExport(endpoint, serviceName string, spans []*Span) {
+	count := strconv.Itoa(len(spans))
+	body, err := json.Marshal(newOTLPRequest(serviceName, spans))
+	if err != nil {
+		log.Error("while marshalling " + count + " spans, got: " + err.Error())
+		return
+	}
+	resp, err := otlpClient.Post(endpoint, "application/json", bytes.NewReader(body))
+	if err != nil {
+		log.Error("while exporting " + count + " spans, got: " + err.Error())
+		return
+	}
+	resp.Body.Close()
+	if resp.StatusCode >= http.StatusMultipleChoices {
+		log.Error("while exporting " + count + " spans, got status code " + strconv.Itoa(resp.StatusCode))
+	}
+}
@@ -0,0 +1,74 @@
+//(C) Copyright [2020] Hewlett Packard Enterprise Development LP
+//
+//Licensed under the Apache License, Version 2.0 (the "License"); you may
+//not use this file except in compliance with the License. You may obtain
+//a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+//Unless required by applicable law or agreed to in writing, software
+//distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+//WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+//License for the specific language governing permissions and limitations
+// under the License.
+
+package captrace
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestOTLPBatcher(t *testing.T) {
+	batches := make(chan []*Span, 2)
+	batcher := newOTLPBatcher(2, 2, 50*time.Millisecond, func(spans []*Span) { batches <- spans })
+
+	// the span finished while the queue is full is dropped
+	for _, name := range []string{"first", "second", "third"} {
+		batcher.enqueue(&Span{Name: name})
+	}
+	if batcher.dropped != 1 {
+		t.Errorf("%d spans dropped, want 1", batcher.dropped)
+	}
+	go batcher.run()
+	if batch := <-batches; len(batch) != 2 || batch[0].Name != "first" || batch[1].Name != "second" {
+		t.Fatalf("batch of %d spans sent, want the first and the second span", len(batch))
+	}
+
+	// a partial batch is sent at the interval
+	batcher.enqueue(&Span{Name: "fourth"})
+	select {
+	case batch := <-batches:
+		if len(batch) != 1 || batch[0].Name != "fourth" {
+			t.Errorf("batch of %d spans sent, want the fourth span", len(batch))
+		}
+	case <-time.After(time.Second):
+		t.Error("partial batch not sent at the interval")
+	}
+}
+
+func TestOTLPHTTPExport(t *testing.T) {
+	var request otlpRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		json.Unmarshal(body, &request)
+	}))
+	defer server.Close()
+
+	now := time.Now()
+	otlpHTTPExport(server.URL, "plugin", []*Span{
+		{TraceID: testTraceID, SpanID: testSpanID, Name: "GetPortInfo", StartTime: now, EndTime: now},
+		{TraceID: testTraceID, SpanID: "00f067aa0ba902b8", ParentSpanID: testSpanID, Name: "caputilities.GetPortInfo", Error: "timeout"},
+	})
+	if len(request.ResourceSpans) != 1 || len(request.ResourceSpans[0].ScopeSpans) != 1 {
+		t.Fatalf("OTLP request %+v, want the spans of a single resource and scope", request)
+	}
+	spans := request.ResourceSpans[0].ScopeSpans[0].Spans
+	if len(spans) != 2 || spans[1].ParentSpanID != testSpanID || spans[1].Status.Code != otlpStatusError {
+		t.Errorf("OTLP spans %+v, want both spans in a single request", spans)
+	}
+}
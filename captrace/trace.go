@@ -0,0 +1,200 @@
+//(C) Copyright [2020] Hewlett Packard Enterprise Development LP
+//
+//Licensed under the Apache License, Version 2.0 (the "License"); you may
+//not use this file except in compliance with the License. You may obtain
+//a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+//Unless required by applicable law or agreed to in writing, software
+//distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+//WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+//License for the specific language governing permissions and limitations
+// under the License.
+
+//Package captrace records OpenTelemetry compatible spans of the plugin requests,
+//the trace context is propagated from the W3C traceparent header of the request
+package captrace
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"math/big"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ODIM-Project/PluginCiscoACI/config"
+	iris "github.com/kataras/iris/v12"
+)
+
+const (
+	// TraceParentHeader is the W3C trace context header
+	TraceParentHeader = "traceparent"
+	spanContextKey    = "captrace.span"
+	traceFlagSampled  = "01"
+	traceFlagNone     = "00"
+	traceParentFormat = "00-%s-%s-%s"
+	// span kinds as defined by OTLP
+	spanKindInternal = 1
+	spanKindServer   = 2
+)
+
+// Span is a timed operation of a request, all the methods are safe
+// to be called on a nil Span, which is used when tracing is disabled
+type Span struct {
+	TraceID      string
+	SpanID       string
+	ParentSpanID string
+	Name         string
+	Kind         int
+	StartTime    time.Time
+	EndTime      time.Time
+	Attributes   map[string]string
+	Error        string
+	sampled      bool
+	lock         sync.Mutex
+}
+
+// StartHandlerSpan starts the span of the request handled, continuing the trace of the
+// traceparent header when present. The span is stored in the request context, so that the
+// span can be retrieved using SpanFromContext. nil is returned when tracing is disabled.
+func StartHandlerSpan(ctx iris.Context, name string) *Span {
+	if !enabled() {
+		return nil
+	}
+	span := &Span{
+		Name:       name,
+		Kind:       spanKindServer,
+		SpanID:     newID(8),
+		StartTime:  time.Now(),
+		Attributes: map[string]string{"http.method": ctx.Method(), "http.target": ctx.Path()},
+	}
+	if traceID, parentSpanID, sampled, ok := parseTraceParent(ctx.GetHeader(TraceParentHeader)); ok {
+		span.TraceID, span.ParentSpanID, span.sampled = traceID, parentSpanID, sampled
+	} else {
+		span.TraceID = newID(16)
+		span.sampled = sample(config.Data.OTelConf.SamplingRatio)
+	}
+	ctx.Values().Set(spanContextKey, span)
+	return span
+}
+
+// SpanFromContext returns the span of the request, nil when there isn't one
+func SpanFromContext(ctx iris.Context) *Span {
+	if span, ok := ctx.Values().Get(spanContextKey).(*Span); ok {
+		return span
+	}
+	return nil
+}
+
+// StartChild starts a span of an operation done as part of the span
+func (s *Span) StartChild(name string) *Span {
+	if s == nil {
+		return nil
+	}
+	return &Span{
+		TraceID:      s.TraceID,
+		SpanID:       newID(8),
+		ParentSpanID: s.SpanID,
+		Name:         name,
+		Kind:         spanKindInternal,
+		StartTime:    time.Now(),
+		Attributes:   map[string]string{},
+		sampled:      s.sampled,
+	}
+}
+
+// SetAttribute adds the attribute to the span
+func (s *Span) SetAttribute(key, value string) {
+	if s == nil {
+		return
+	}
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.Attributes[key] = value
+}
+
+// RecordError marks the span as failed with the given error
+func (s *Span) RecordError(err error) {
+	if s == nil || err == nil {
+		return
+	}
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.Error = err.Error()
+}
+
+// TraceParent returns the W3C traceparent header value to propagate the span to the southbound calls
+func (s *Span) TraceParent() string {
+	if s == nil {
+		return ""
+	}
+	flags := traceFlagNone
+	if s.sampled {
+		flags = traceFlagSampled
+	}
+	return fmt.Sprintf(traceParentFormat, s.TraceID, s.SpanID, flags)
+}
+
+// End ends the span and exports it when sampled
+func (s *Span) End() {
+	if s == nil {
+		return
+	}
+	s.lock.Lock()
+	s.EndTime = time.Now()
+	s.lock.Unlock()
+	if s.sampled {
+		spanExporter.export(s)
+	}
+}
+
+func enabled() bool {
+	return config.Data.OTelConf != nil && config.Data.OTelConf.Enabled
+}
+
+// parseTraceParent extracts the trace id, parent span id and sampled flag of the traceparent header
+func parseTraceParent(traceParent string) (string, string, bool, bool) {
+	parts := strings.Split(strings.TrimSpace(traceParent), "-")
+	if len(parts) < 4 || len(parts[0]) != 2 || parts[0] == "ff" {
+		return "", "", false, false
+	}
+	traceID, spanID, flags := strings.ToLower(parts[1]), strings.ToLower(parts[2]), parts[3]
+	if !isHexID(traceID, 32) || !isHexID(spanID, 16) || len(flags) != 2 {
+		return "", "", false, false
+	}
+	flagBits, err := hex.DecodeString(flags)
+	if err != nil {
+		return "", "", false, false
+	}
+	return traceID, spanID, flagBits[0]&1 == 1, true
+}
+
+// isHexID checks the id is a lower case hex string of the given length which is not all zeros
+func isHexID(id string, length int) bool {
+	if len(id) != length || strings.Trim(id, "0") == "" {
+		return false
+	}
+	_, err := hex.DecodeString(id)
+	return err == nil
+}
+
+func newID(size int) string {
+	id := make([]byte, size)
+	rand.Read(id)
+	return hex.EncodeToString(id)
+}
+
+func sample(ratio float64) bool {
+	if ratio >= 1 {
+		return true
+	}
+	const precision = 1000000
+	n, err := rand.Int(rand.Reader, big.NewInt(precision))
+	if err != nil {
+		return false
+	}
+	return float64(n.Int64()) < ratio*precision
+}
@@ -0,0 +1,118 @@
+//(C) Copyright [2020] Hewlett Packard Enterprise Development LP
+//
+//Licensed under the Apache License, Version 2.0 (the "License"); you may
+//not use this file except in compliance with the License. You may obtain
+//a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+//Unless required by applicable law or agreed to in writing, software
+//distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+//WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+//License for the specific language governing permissions and limitations
+// under the License.
+
+package captrace
+
+import (
+	"net/http"
+	"sync"
+	"testing"
+
+	"github.com/ODIM-Project/PluginCiscoACI/config"
+	iris "github.com/kataras/iris/v12"
+	"github.com/kataras/iris/v12/httptest"
+)
+
+const (
+	testTraceID = "4bf92f3577b34da6a3ce929d0e0e4736"
+	testSpanID  = "00f067aa0ba902b7"
+)
+
+type recordingExporter struct {
+	lock  sync.Mutex
+	spans []*Span
+}
+
+func (e *recordingExporter) export(span *Span) {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+	e.spans = append(e.spans, span)
+}
+
+func TestParseTraceParent(t *testing.T) {
+	tests := []struct {
+		name        string
+		traceParent string
+		wantSampled bool
+		wantOk      bool
+	}{
+		{"sampled", "00-" + testTraceID + "-" + testSpanID + "-01", true, true},
+		{"not sampled", "00-" + testTraceID + "-" + testSpanID + "-00", false, true},
+		{"empty", "", false, false},
+		{"invalid version", "ff-" + testTraceID + "-" + testSpanID + "-01", false, false},
+		{"short trace id", "00-4bf92f35-" + testSpanID + "-01", false, false},
+		{"zero trace id", "00-00000000000000000000000000000000-" + testSpanID + "-01", false, false},
+		{"zero span id", "00-" + testTraceID + "-0000000000000000-01", false, false},
+		{"non hex flags", "00-" + testTraceID + "-" + testSpanID + "-zz", false, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			traceID, spanID, sampled, ok := parseTraceParent(tt.traceParent)
+			if ok != tt.wantOk || sampled != tt.wantSampled {
+				t.Errorf("parseTraceParent() sampled = %v, ok = %v, want %v, %v", sampled, ok, tt.wantSampled, tt.wantOk)
+			}
+			if ok && (traceID != testTraceID || spanID != testSpanID) {
+				t.Errorf("parseTraceParent() = %s, %s, want %s, %s", traceID, spanID, testTraceID, testSpanID)
+			}
+		})
+	}
+}
+
+func TestStartHandlerSpan(t *testing.T) {
+	config.SetUpMockConfig(t)
+	exporter := &recordingExporter{}
+	spanExporter = exporter
+	defer func() { spanExporter = configuredExporter{} }()
+
+	mockApp := iris.New()
+	mockApp.Get("/ports/{portID}", func(ctx iris.Context) {
+		span := StartHandlerSpan(ctx, "GetPortInfo")
+		defer span.End()
+		span.SetAttribute("portID", ctx.Params().Get("portID"))
+		child := SpanFromContext(ctx).StartChild("capmodel.GetPort")
+		child.End()
+		ctx.WriteString(span.TraceParent())
+	})
+	e := httptest.New(t, mockApp)
+
+	// tracing disabled by default
+	e.GET("/ports/eth1-1").Expect().Status(http.StatusOK).Body().Equal("")
+	if len(exporter.spans) != 0 {
+		t.Fatalf("%d spans exported with tracing disabled", len(exporter.spans))
+	}
+
+	config.Data.OTelConf = &config.OTelConf{Enabled: true, SamplingRatio: 1, Exporter: "log"}
+	defer func() { config.Data.OTelConf = nil }()
+
+	// trace context of the request is continued
+	e.GET("/ports/eth1-1").WithHeader(TraceParentHeader, "00-"+testTraceID+"-"+testSpanID+"-01").
+		Expect().Status(http.StatusOK).Body().Match("^00-" + testTraceID + "-[0-9a-f]{16}-01$")
+	if len(exporter.spans) != 2 {
+		t.Fatalf("%d spans exported, want 2", len(exporter.spans))
+	}
+	child, parent := exporter.spans[0], exporter.spans[1]
+	if parent.TraceID != testTraceID || parent.ParentSpanID != testSpanID || parent.Attributes["portID"] != "eth1-1" {
+		t.Errorf("handler span = %+v, not continuing the request trace", parent)
+	}
+	if child.TraceID != testTraceID || child.ParentSpanID != parent.SpanID {
+		t.Errorf("child span = %+v, not part of the handler span", child)
+	}
+
+	// unsampled trace context of the request is not exported
+	e.GET("/ports/eth1-1").WithHeader(TraceParentHeader, "00-"+testTraceID+"-"+testSpanID+"-00").
+		Expect().Status(http.StatusOK)
+	if len(exporter.spans) != 2 {
+		t.Errorf("%d spans exported, unsampled spans must not be exported", len(exporter.spans))
+	}
+}
@@ -16,14 +16,18 @@
 package caputilities
 
 import (
+	"bytes"
 	"encoding/base64"
 	"encoding/json"
 	"fmt"
+	"io"
 	"io/ioutil"
 	"net/http"
+	"net/url"
+	"strings"
 
-	lutilconf "github.com/ODIM-Project/ODIM/lib-utilities/config"
 	"github.com/ODIM-Project/PluginCiscoACI/capmodel"
+	"github.com/ODIM-Project/PluginCiscoACI/captrace"
 	"github.com/ODIM-Project/PluginCiscoACI/config"
 	"github.com/ciscoecosystem/aci-go-client/client"
 	"github.com/ciscoecosystem/aci-go-client/models"
@@ -37,117 +41,208 @@ type ACIHTTPClient struct {
 var aciClient *client.Client
 var aciServiceManager *client.ServiceManager
 
+// newAPICClient returns a new client of APIC logging in with the configured user
+func newAPICClient() *client.Client {
+	return newAPICHostClient(config.Data.APICConf.APICHost)
+}
+
+// newAPICHostClient returns a new client of the given controller of the APIC cluster logging in with the configured user
+func newAPICHostClient(host string) *client.Client {
+	return client.NewClient("https://"+host, apicLoginName(), client.Password(config.Data.APICConf.Password), client.Insecure(true))
+}
+
+// apicLoginName returns the user name sent by the login to APIC, the user is
+// prefixed with the login domain like apic:DOMAIN\user when it is configured
+func apicLoginName() string {
+	if config.Data.APICConf.LoginDomain == "" {
+		return config.Data.APICConf.UserName
+	}
+	return "apic:" + config.Data.APICConf.LoginDomain + "\\" + config.Data.APICConf.UserName
+}
+
 // GetClient returns a new connection client to APIC
 func GetClient() *client.Client {
-	aciClient = client.NewClient("https://"+config.Data.APICConf.APICHost, config.Data.APICConf.UserName, client.Password(config.Data.APICConf.Password), client.Insecure(true))
+	aciClient = newAPICClient()
 	return aciClient
 }
 
 // GetConnection returns a new connection to APIC
 func GetConnection() *client.ServiceManager {
-	aciClient = client.NewClient("https://"+config.Data.APICConf.APICHost, config.Data.APICConf.UserName, client.Password(config.Data.APICConf.Password), client.Insecure(true))
-	aciServiceManager = client.NewServiceManager(client.DefaultMOURL, aciClient)
+	aciClient = newAPICClient()
+	aciServiceManager = client.NewServiceManager(apicPath("/node/mo"), aciClient)
 	return aciServiceManager
 }
 
-// GetFabricNodeData collects the all switch and fabric  details from the aci
+// GetFabricNodeData collects the all switch and fabric  details from the aci,
+// from two controllers in quorum when QuorumReads is configured
 func GetFabricNodeData() ([]*models.FabricNodeMember, error) {
-	aciClient = client.NewClient("https://"+config.Data.APICConf.APICHost, config.Data.APICConf.UserName, client.Password(config.Data.APICConf.Password), client.Insecure(true))
-	aciServiceManager = client.NewServiceManager(client.DefaultMOURL, aciClient)
+	if config.Data.APICConf.QuorumReads {
+		return getQuorumFabricNodeData()
+	}
+	aciClient = newAPICClient()
+	aciServiceManager = client.NewServiceManager(apicPath("/node/mo"), aciClient)
 	return aciServiceManager.ListFabricNodeMember()
 
 }
 
-//GetPortData collects the all port data for the given switch
-func GetPortData(podID, ACISwitchID string) (*capmodel.PortCollectionResponse, error) {
-	endpoint := fmt.Sprintf("https://%s/api/node/class/topology/pod-%s/node-%s/l1PhysIf.json", config.Data.APICConf.APICHost, podID, ACISwitchID)
-
-	req, err := http.NewRequest("GET", endpoint, nil)
-	if err != nil {
-		return nil, err
-	}
-	newClient := ACIHTTPClient{}
-	httpConf := &lutilconf.HTTPConfig{
-		CACertificate: &config.Data.KeyCertConf.RootCACertificate,
+// apicPath returns the path of the APIC REST API resource under the configured base path,
+// the format and its arguments give the path of the resource like /node/mo/<dn>.json
+func apicPath(format string, args ...interface{}) string {
+	basePath := config.Data.APICConf.APIBasePath
+	if basePath == "" {
+		basePath = config.DefaultAPICAPIBasePath
 	}
-	if newClient.httpClient, err = httpConf.GetHTTPClientObj(); err != nil {
-		return nil, err
-	}
-	req.Close = true
-	req.Header.Set("Accept", "application/json")
-	req.AddCookie(&http.Cookie{
-		Name:  "APIC-Cookie",
-		Value: aciClient.AuthToken.Token,
-	})
-	req.Close = true
+	return basePath + fmt.Sprintf(format, args...)
+}
 
-	resp, err := newClient.httpClient.Do(req)
-	if err != nil {
-		return nil, err
+// apicURL returns the URL of the APIC REST API resource under the configured base path
+func apicURL(format string, args ...interface{}) string {
+	return "https://" + config.Data.APICConf.APICHost + apicPath(format, args...)
+}
+
+// getAPICData collects the response body of GET on the given endpoint with the APIC token of the plugin,
+// from the controllers of the cluster in turn when BalanceReads is configured. The request carries the
+// trace context of traceParent, no trace context is sent when it is empty.
+func getAPICData(traceParent, endpoint string) ([]byte, error) {
+	if config.Data.APICConf.BalanceReads {
+		return readBalancedAPIC(traceParent, strings.TrimPrefix(endpoint, "https://"+config.Data.APICConf.APICHost))
 	}
-	defer resp.Body.Close()
-	body, err := ioutil.ReadAll(resp.Body)
+	token, err := apicAuthToken()
 	if err != nil {
 		return nil, err
 	}
-	if resp.StatusCode >= 300 {
-		errMsg := fmt.Sprintf("Get on the URL %s is giving response with status code %d with response body %s", endpoint, resp.StatusCode, string(body))
-		return nil, fmt.Errorf(errMsg)
-	}
+	body, err := doAPICRequest(traceParent, http.MethodGet, endpoint, token, nil)
+	apicHostTokens(config.Data.APICConf.APICHost).invalidate(token, err)
+	return body, err
+}
 
-	var portResponseData capmodel.PortCollectionResponse
-	json.Unmarshal(body, &portResponseData)
-	return &portResponseData, nil
+// tracedAPICData returns the reader of the APIC endpoints carrying the trace context of traceParent,
+// for the queries read in pages
+func tracedAPICData(traceParent string) func(endpoint string) ([]byte, error) {
+	return func(endpoint string) ([]byte, error) {
+		return getAPICData(traceParent, endpoint)
+	}
+}
 
+// getAPICDataWithToken collects the response body of GET on the given endpoint using the given APIC token
+func getAPICDataWithToken(endpoint, token string) ([]byte, error) {
+	return doAPICRequest("", http.MethodGet, endpoint, token, nil)
 }
 
-//GetFabricHealth queries the fabric for it's Health from ACI
-func GetFabricHealth(podID string) (*capmodel.FabricHealth, error) {
-	aciClient := client.NewClient("https://"+config.Data.APICConf.APICHost, config.Data.APICConf.UserName, client.Password(config.Data.APICConf.Password), client.Insecure(true))
-	// Get the port data for given switch using the uri /api/node/mo/topology/{pod_id}/health.json
-	err := aciClient.Authenticate()
+// postAPICData posts the managed objects of body on the given endpoint with the APIC token of the plugin,
+// the request carries the trace context of traceParent
+func postAPICData(traceParent, endpoint string, body []byte) error {
+	token, err := apicAuthToken()
 	if err != nil {
-		return nil, err
+		return err
 	}
-	endpoint := fmt.Sprintf("https://%s/api/node/mo/topology/pod-%s/health.json", config.Data.APICConf.APICHost, podID)
+	_, err = doAPICRequest(traceParent, http.MethodPost, endpoint, token, body)
+	apicHostTokens(config.Data.APICConf.APICHost).invalidate(token, err)
+	return err
+}
 
-	req, err := http.NewRequest("GET", endpoint, nil)
+// doAPICRequest makes the request on the given endpoint using the given APIC token and returns the response body,
+// the W3C trace context of traceParent is sent when it is not empty so that APIC calls are part of the trace
+func doAPICRequest(traceParent, method, endpoint, token string, body []byte) ([]byte, error) {
+	if err := waitAPICRateLimit(endpoint); err != nil {
+		return nil, err
+	}
+	var reqBody io.Reader
+	if body != nil {
+		reqBody = bytes.NewReader(body)
+	}
+	req, err := http.NewRequest(method, endpoint, reqBody)
 	if err != nil {
 		return nil, err
 	}
-	newClient := ACIHTTPClient{}
-	httpConf := &lutilconf.HTTPConfig{
-		CACertificate: &config.Data.KeyCertConf.RootCACertificate,
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
 	}
-	if newClient.httpClient, err = httpConf.GetHTTPClientObj(); err != nil {
+	newClient := ACIHTTPClient{}
+	if newClient.httpClient, err = apicHTTPClient(); err != nil {
 		return nil, err
 	}
-	req.Close = true
 	req.Header.Set("Accept", "application/json")
+	if traceParent != "" {
+		req.Header.Set(captrace.TraceParentHeader, traceParent)
+	}
 	req.AddCookie(&http.Cookie{
 		Name:  "APIC-Cookie",
-		Value: aciClient.AuthToken.Token,
+		Value: token,
 	})
-	req.Close = true
 
 	resp, err := newClient.httpClient.Do(req)
 	if err != nil {
 		return nil, err
 	}
 	defer resp.Body.Close()
-	body, err := ioutil.ReadAll(resp.Body)
+	if err := checkAPICThrottle(endpoint, resp); err != nil {
+		return nil, err
+	}
+	respBody, err := ioutil.ReadAll(resp.Body)
 	if err != nil {
 		return nil, err
 	}
-	if resp.StatusCode >= 300 {
-		errMsg := fmt.Sprintf("Get on the URL %s is giving response with status code %d with response body %s", endpoint, resp.StatusCode, string(body))
-		return nil, fmt.Errorf(errMsg)
+	if err := checkAPICResponse(method, endpoint, resp.StatusCode, respBody); err != nil {
+		return nil, err
 	}
+	return respBody, nil
+}
 
-	var fabricHealthData capmodel.FabricHealth
-	json.Unmarshal(body, &fabricHealthData)
-	return &fabricHealthData, nil
+// apicHTTPClient returns the HTTP client verifying the certificate of APIC with the root CA, against the
+// configured TLSServerName when APIC is reached through an address which is not in its certificate.
+// The client is shared by the APIC requests, which reuse its idle connections.
+func apicHTTPClient() (*http.Client, error) {
+	return sharedHTTPClient(config.Data.APICConf.TLSServerName)
+}
+
+//GetPortData collects the all port data for the given switch, read in pages of QueryPageSize
+func GetPortData(podID, ACISwitchID string) (*capmodel.PortCollectionResponse, error) {
+	path := fmt.Sprintf("/node/class/topology/pod-%s/node-%s/l1PhysIf.json", podID, ACISwitchID)
+	body, err := getAPICPages(path, func(pagePath string) ([]byte, error) {
+		return getTopologyData("%s", pagePath)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return ParsePortCollection(body)
+}
+
+// Tenant scoping of the APIC queries
+//
+// When APICConf.Tenant is configured, the tenant-scopable queries are made on the tenant
+// DN uni/tn-<Tenant> instead of the fabric, so that only the objects of the tenant are
+// considered. The fabric health (GetFabricHealth) then reports the health of the tenant.
+// The health and the faults of the ports (GetPortHealth, GetSwitchPortsHealth and
+// GetPortFaults) are restricted to the ports the EPGs of the tenant are statically bound
+// to, GetPortHealth failing with ErrOutOfTenant for the other ports. The queries of the
+// physical topology - switches, chassis, ports and fabric path endpoints - are owned by
+// the fabric infrastructure and are always fabric-wide.
+
+// tenantDN returns the DN of the tenant the queries are scoped to, empty when no tenant is configured
+func tenantDN() string {
+	if config.Data.APICConf.Tenant == "" {
+		return ""
+	}
+	return "uni/tn-" + config.Data.APICConf.Tenant
+}
 
+// fabricHealthEndpoint returns the endpoint of the health of the pod, or of the tenant when configured
+func fabricHealthEndpoint(podID string) string {
+	if dn := tenantDN(); dn != "" {
+		return apicURL("/node/mo/%s/health.json", dn)
+	}
+	return apicURL("/node/mo/topology/pod-%s/health.json", podID)
+}
+
+//GetFabricHealth queries the fabric for it's Health from ACI, scoped to the tenant when configured
+func GetFabricHealth(podID string) (*capmodel.FabricHealth, error) {
+	endpoint := fabricHealthEndpoint(podID)
+	body, err := getAPICData("", endpoint)
+	if err != nil {
+		return nil, err
+	}
+	return ParseFabricHealth(body)
 }
 
 // GetSwitchInfo collects the given switch data from the aci
@@ -158,64 +253,15 @@ func GetSwitchInfo(podID, ACISwitchID int) (*models.System, error) {
 
 // GetSwitchChassisInfo collects the given switch chassis data from the aci
 func GetSwitchChassisInfo(podID, ACISwitchID string) (*capmodel.SwitchChassis, *capmodel.Health, error) {
-	endpoint := fmt.Sprintf("https://%s/api/node/mo/topology/pod-%s/node-%s/sys/ch.json", config.Data.APICConf.APICHost, podID, ACISwitchID)
-
-	req, err := http.NewRequest("GET", endpoint, nil)
+	body, err := getTopologyData("/node/mo/topology/pod-%s/node-%s/sys/ch.json", podID, ACISwitchID)
 	if err != nil {
 		return nil, nil, err
 	}
-	newClient := ACIHTTPClient{}
-	httpConf := &lutilconf.HTTPConfig{
-		CACertificate: &config.Data.KeyCertConf.RootCACertificate,
-	}
-	if newClient.httpClient, err = httpConf.GetHTTPClientObj(); err != nil {
-		return nil, nil, err
-	}
-	req.Close = true
-	req.Header.Set("Accept", "application/json")
-	req.AddCookie(&http.Cookie{
-		Name:  "APIC-Cookie",
-		Value: aciClient.AuthToken.Token,
-	})
-	req.Close = true
-
-	resp, err := newClient.httpClient.Do(req)
-	if err != nil {
-		return nil, nil, err
-	}
-	defer resp.Body.Close()
-	body, err := ioutil.ReadAll(resp.Body)
-	if err != nil {
-		return nil, nil, err
-	}
-	if resp.StatusCode >= 300 {
-		errMsg := fmt.Sprintf("Get on the URL %s is giving response with status code %d with response body %s", endpoint, resp.StatusCode, string(body))
-		return nil, nil, fmt.Errorf(errMsg)
-	}
 
 	var switchChassisData capmodel.SwitchChassis
 	var chassisHealth capmodel.Health
 	json.Unmarshal(body, &switchChassisData)
-	healthEndpoint := fmt.Sprintf("https://%s/api/node/mo/topology/pod-%s/node-%s/sys/ch/health.json", config.Data.APICConf.APICHost, podID, ACISwitchID)
-
-	healthReq, err := http.NewRequest("GET", healthEndpoint, nil)
-	if err != nil {
-		return nil, nil, err
-	}
-	healthReq.Close = true
-	healthReq.Header.Set("Accept", "application/json")
-	healthReq.AddCookie(&http.Cookie{
-		Name:  "APIC-Cookie",
-		Value: aciClient.AuthToken.Token,
-	})
-	healthReq.Close = true
-
-	healthResp, err := newClient.httpClient.Do(healthReq)
-	if err != nil {
-		return nil, nil, err
-	}
-	defer healthResp.Body.Close()
-	healthBody, err := ioutil.ReadAll(healthResp.Body)
+	healthBody, err := getTopologyData("/node/mo/topology/pod-%s/node-%s/sys/ch/health.json", podID, ACISwitchID)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -225,155 +271,321 @@ func GetSwitchChassisInfo(podID, ACISwitchID string) (*capmodel.SwitchChassis, *
 
 //GetSwitchHealth queries the switch for it's Health from ACI
 func GetSwitchHealth(podID, ACISwitchID string) (*capmodel.Health, error) {
-	aciClient := client.NewClient("https://"+config.Data.APICConf.APICHost, config.Data.APICConf.UserName, client.Password(config.Data.APICConf.Password), client.Insecure(true))
-	// Get the port data for given switch using the uri /api/node/mo/topology/{pod_id}/health.json
-	err := aciClient.Authenticate()
+	endpoint := apicURL("/node/mo/topology/pod-%s/node-%s/sys/health.json", podID, ACISwitchID)
+	body, err := getAPICData("", endpoint)
 	if err != nil {
 		return nil, err
 	}
-	endpoint := fmt.Sprintf("https://%s/api/node/mo/topology/pod-%s/node-%s/sys/health.json", config.Data.APICConf.APICHost, podID, ACISwitchID)
+	return ParseHealth(body)
+}
 
-	req, err := http.NewRequest("GET", endpoint, nil)
+//GetPortInfo collects the dat for  given port, the request carries the trace context of traceParent
+func GetPortInfo(traceParent, podID, ACISwitchID, portID string) (*capmodel.PortInfoResponse, error) {
+	body, err := getAPICData(traceParent, portInfoEndpoint(podID, ACISwitchID, portID))
 	if err != nil {
 		return nil, err
 	}
-	newClient := ACIHTTPClient{}
-	httpConf := &lutilconf.HTTPConfig{
-		CACertificate: &config.Data.KeyCertConf.RootCACertificate,
-	}
-	if newClient.httpClient, err = httpConf.GetHTTPClientObj(); err != nil {
-		return nil, err
+	return ParsePortInfo(body)
+}
+
+// PortDN returns the distinguished name of the physical interface of the port in APIC,
+// portID is the APIC id of the port like eth1/1
+func PortDN(podID, ACISwitchID, portID string) string {
+	return fmt.Sprintf("topology/pod-%s/node-%s/sys/phys-[%s]", podID, ACISwitchID, portID)
+}
+
+// PortPathDN returns the distinguished name of the fabric path endpoint of the port in APIC,
+// which the policies applied on the port refer to
+func PortPathDN(podID, ACISwitchID, portID string) string {
+	return fmt.Sprintf("topology/pod-%s/paths-%s/pathep-[%s]", podID, ACISwitchID, portID)
+}
+
+// SetPortOutOfService takes the port out of service in APIC, which disables the port, when
+// outOfService is true and puts it back in service otherwise. The request carries the trace context of traceParent.
+func SetPortOutOfService(traceParent, podID, ACISwitchID, portID string, outOfService bool) error {
+	return postAPICData(traceParent, apicURL("/node/mo/uni/fabric/outofsvc.json"), portOutOfServicePayload(podID, ACISwitchID, portID, outOfService))
+}
+
+// portOutOfServicePayload builds the out of service relation of the port path, deleted to put the port back in service
+func portOutOfServicePayload(podID, ACISwitchID, portID string, outOfService bool) []byte {
+	attributes := map[string]string{"tDn": PortPathDN(podID, ACISwitchID, portID)}
+	if outOfService {
+		attributes["lc"] = "blacklist"
+	} else {
+		attributes["status"] = "deleted"
 	}
-	req.Close = true
-	req.Header.Set("Accept", "application/json")
-	req.AddCookie(&http.Cookie{
-		Name:  "APIC-Cookie",
-		Value: aciClient.AuthToken.Token,
+	payload, _ := json.Marshal(map[string]interface{}{
+		"fabricRsOosPath": map[string]interface{}{"attributes": attributes},
 	})
-	req.Close = true
+	return payload
+}
 
-	resp, err := newClient.httpClient.Do(req)
-	if err != nil {
-		return nil, err
-	}
-	defer resp.Body.Close()
-	body, err := ioutil.ReadAll(resp.Body)
+// portInfoEndpoint returns the endpoint of the ethpmPhysIf of the port
+func portInfoEndpoint(podID, ACISwitchID, portID string) string {
+	return apicURL("/node/mo/%s/phys.json", PortDN(podID, ACISwitchID, portID))
+}
+
+// GetPortTransceiver collects the inventory of the transceiver plugged in the port, nil is
+// returned when the transceiver slot of the port is empty. The request carries the trace context of traceParent.
+func GetPortTransceiver(traceParent, podID, ACISwitchID, portID string) (*capmodel.PortTransceiver, error) {
+	body, err := getAPICData(traceParent, apicURL("/node/mo/%s/phys/fcot.json", PortDN(podID, ACISwitchID, portID)))
 	if err != nil {
 		return nil, err
 	}
-	if resp.StatusCode >= 300 {
-		errMsg := fmt.Sprintf("Get on the URL %s is giving response with status code %d with response body %s", endpoint, resp.StatusCode, string(body))
-		return nil, fmt.Errorf(errMsg)
-	}
-
-	var switchHealthData capmodel.Health
-	json.Unmarshal(body, &switchHealthData)
-	return &switchHealthData, nil
+	return ParsePortTransceiver(body)
+}
 
+// GetPortNeighbors collects the devices connected to the port discovered by LLDP and CDP, no
+// neighbor is returned when none is discovered. The requests carry the trace context of traceParent.
+func GetPortNeighbors(traceParent, podID, ACISwitchID, portID string) ([]capmodel.PortNeighbor, error) {
+	var neighbors []capmodel.PortNeighbor
+	for _, protocol := range []struct{ name, class string }{{"lldp", "lldpAdjEp"}, {"cdp", "cdpAdjEp"}} {
+		endpoint := apicURL("/node/mo/topology/pod-%s/node-%s/sys/%s/inst/if-[%s].json?query-target=children&target-subtree-class=%s",
+			podID, ACISwitchID, protocol.name, portID, protocol.class)
+		body, err := getAPICData(traceParent, endpoint)
+		if err != nil {
+			return nil, err
+		}
+		protocolNeighbors, err := ParsePortNeighbors(body)
+		if err != nil {
+			return nil, err
+		}
+		neighbors = append(neighbors, protocolNeighbors...)
+	}
+	return neighbors, nil
 }
 
-//GetPortInfo collects the dat for  given port
-func GetPortInfo(podID, ACISwitchID, portID string) (*capmodel.PortInfoResponse, error) {
-	aciClient := client.NewClient("https://"+config.Data.APICConf.APICHost, config.Data.APICConf.UserName, client.Password(config.Data.APICConf.Password), client.Insecure(true))
-	// Get the port data for given switch using the uri /api/node/mo/topology/{pod_id}/health.json
-	err := aciClient.Authenticate()
+//GetPortHealth collects the Health  for  given port, ErrOutOfTenant is returned for the port
+//no EPG of the tenant the queries are scoped to is bound to. The requests carry the trace context of traceParent.
+func GetPortHealth(traceParent, podID, ACISwitchID, portID string) (*capmodel.Health, error) {
+	if err := checkTenantPort(traceParent, podID, ACISwitchID, portID); err != nil {
+		return nil, err
+	}
+	endpoint := apicURL("/node/mo/%s/phys/health.json", PortDN(podID, ACISwitchID, portID))
+	body, err := getAPICData(traceParent, endpoint)
 	if err != nil {
 		return nil, err
 	}
-	endpoint := fmt.Sprintf("https://%s/api/node/mo/topology/pod-%s/node-%s/sys/phys-[%s]/phys.json", config.Data.APICConf.APICHost, podID, ACISwitchID, portID)
+	return ParseHealth(body)
+}
 
-	req, err := http.NewRequest("GET", endpoint, nil)
+// GetSwitchPortsHealth collects the health of all the ports of the switch in a single class query read in
+// pages, keyed by the port id like eth1/1. The ports without health score in APIC are absent, as are the
+// ports no EPG of the tenant the queries are scoped to is bound to. The requests carry the trace context of traceParent.
+func GetSwitchPortsHealth(traceParent, podID, ACISwitchID string) (map[string]capmodel.HealthData, error) {
+	body, err := getAPICPages(switchPortsHealthEndpoint(podID, ACISwitchID), tracedAPICData(traceParent))
 	if err != nil {
 		return nil, err
 	}
-	newClient := ACIHTTPClient{}
-	httpConf := &lutilconf.HTTPConfig{
-		CACertificate: &config.Data.KeyCertConf.RootCACertificate,
+	health, err := ParseSwitchPortsHealth(body)
+	if err != nil {
+		return nil, err
 	}
-	if newClient.httpClient, err = httpConf.GetHTTPClientObj(); err != nil {
+	if err := scopeSwitchPortsHealth(traceParent, podID, ACISwitchID, health); err != nil {
 		return nil, err
 	}
-	req.Close = true
-	req.Header.Set("Accept", "application/json")
-	req.AddCookie(&http.Cookie{
-		Name:  "APIC-Cookie",
-		Value: aciClient.AuthToken.Token,
-	})
-	req.Close = true
+	return health, nil
+}
 
-	resp, err := newClient.httpClient.Do(req)
+// switchPortsHealthEndpoint returns the endpoint of the health of the physical interfaces of the switch
+func switchPortsHealthEndpoint(podID, ACISwitchID string) string {
+	filter := url.QueryEscape(`wcard(healthInst.dn,"/phys/health")`)
+	return apicURL("/node/class/topology/pod-%s/node-%s/healthInst.json?query-target-filter=%s", podID, ACISwitchID, filter)
+}
+
+// FaultSeverities are the severities of the APIC faults ordered from the least to the most severe
+var FaultSeverities = []string{"cleared", "info", "warning", "minor", "major", "critical"}
+
+// FaultSeverityRank returns the position of the severity in FaultSeverities, -1 for an unknown severity
+func FaultSeverityRank(severity string) int {
+	for rank, known := range FaultSeverities {
+		if severity == known {
+			return rank
+		}
+	}
+	return -1
+}
+
+// GetPortFaults collects the faults of the physical interfaces of the switch, or of all the switches
+// of the pod when ACISwitchID is empty, in a single class query read in pages. The worst fault of each port is
+// returned keyed by the APIC node id and then by the port id, like eth1/1. The faults of the ports no EPG of
+// the tenant the queries are scoped to is bound to are dropped. The requests carry the trace context of traceParent.
+func GetPortFaults(traceParent, podID, ACISwitchID string) (map[string]map[string]capmodel.PortFault, error) {
+	body, err := getAPICPages(portFaultsEndpoint(podID, ACISwitchID), tracedAPICData(traceParent))
 	if err != nil {
 		return nil, err
 	}
-	defer resp.Body.Close()
-	body, err := ioutil.ReadAll(resp.Body)
+	faults, err := ParsePortFaults(body)
 	if err != nil {
 		return nil, err
 	}
-	if resp.StatusCode >= 300 {
-		errMsg := fmt.Sprintf("Get on the URL %s is giving response with status code %d with response body %s", endpoint, resp.StatusCode, string(body))
-		return nil, fmt.Errorf(errMsg)
+	if err := scopePortFaults(traceParent, podID, faults); err != nil {
+		return nil, err
 	}
+	return faults, nil
+}
+
+// portFaultsEndpoint returns the endpoint of the faults of the physical interfaces of the switch or of the pod
+func portFaultsEndpoint(podID, ACISwitchID string) string {
+	filter := url.QueryEscape(`wcard(faultInst.dn,"/sys/phys-")`)
+	if ACISwitchID == "" {
+		return apicURL("/node/class/topology/pod-%s/faultInst.json?query-target-filter=%s", podID, filter)
+	}
+	return apicURL("/node/class/topology/pod-%s/node-%s/faultInst.json?query-target-filter=%s", podID, ACISwitchID, filter)
+}
 
-	var portResponseData capmodel.PortInfoResponse
-	json.Unmarshal(body, &portResponseData)
-	return &portResponseData, nil
+// PortStatsGranularities are the intervals APIC rolls up the counter history of the ports in
+var PortStatsGranularities = []string{"5min", "15min", "1h", "1d", "1w", "1mo", "1qtr", "1year"}
 
+// IsPortStatsGranularity reports whether APIC keeps the counter history of the ports at the given granularity
+func IsPortStatsGranularity(granularity string) bool {
+	for _, supported := range PortStatsGranularities {
+		if granularity == supported {
+			return true
+		}
+	}
+	return false
 }
 
-//GetPortHealth collects the Health  for  given port
-func GetPortHealth(podID, ACISwitchID, portID string) (*capmodel.Health, error) {
-	aciClient := client.NewClient("https://"+config.Data.APICConf.APICHost, config.Data.APICConf.UserName, client.Password(config.Data.APICConf.Password), client.Insecure(true))
-	// Get the port data for given switch using the uri /api/node/mo/topology/{pod_id}/health.json
-	err := aciClient.Authenticate()
+// GetPortStatsHistory collects the traffic history of the port at the given granularity, ordered
+// from the oldest to the most recent sample. The granularity must be one of PortStatsGranularities.
+// The request carries the trace context of traceParent.
+func GetPortStatsHistory(traceParent, podID, ACISwitchID, portID, granularity string) ([]capmodel.PortStatsSample, error) {
+	body, err := getAPICData(traceParent, portStatsHistoryEndpoint(podID, ACISwitchID, portID, granularity))
 	if err != nil {
 		return nil, err
 	}
-	endpoint := fmt.Sprintf("https://%s/api/node/mo/topology/pod-%s/node-%s/sys/phys-[%s]/phys/health.json", config.Data.APICConf.APICHost, podID, ACISwitchID, portID)
+	return ParsePortStatsHistory(body, granularity)
+}
+
+// portStatsHistoryEndpoint returns the endpoint of the ingress and egress byte counter history of the port
+func portStatsHistoryEndpoint(podID, ACISwitchID, portID, granularity string) string {
+	ingressClass, egressClass := portStatsHistoryClasses(granularity)
+	return apicURL("/node/mo/%s.json?query-target=subtree&target-subtree-class=%s,%s",
+		PortDN(podID, ACISwitchID, portID), ingressClass, egressClass)
+}
+
+// portStatsHistoryClasses returns the classes of the ingress and egress byte counter history at the granularity
+func portStatsHistoryClasses(granularity string) (string, string) {
+	return "eqptIngrBytesHist" + granularity, "eqptEgrBytesHist" + granularity
+}
 
-	req, err := http.NewRequest("GET", endpoint, nil)
+// PortCountersClasses are the classes of the ingress and egress bytes and of the ingress and egress
+// packets dropped for errors of the ports, over the last 5 minutes
+var PortCountersClasses = []string{"eqptIngrBytes5min", "eqptEgrBytes5min", "eqptIngrDropPkts5min", "eqptEgrDropPkts5min"}
+
+// GetPortCounters collects the counters of the physical interfaces of all the switches of the pod in
+// a single subtree query read in pages. The counters of each port are returned keyed by the APIC node id and then
+// by the port id, like eth1/1. The requests carry the trace context of traceParent.
+func GetPortCounters(traceParent, podID string) (map[string]map[string]capmodel.PortCounters, error) {
+	body, err := getAPICPages(portCountersEndpoint(podID), tracedAPICData(traceParent))
 	if err != nil {
 		return nil, err
 	}
-	newClient := ACIHTTPClient{}
-	httpConf := &lutilconf.HTTPConfig{
-		CACertificate: &config.Data.KeyCertConf.RootCACertificate,
+	return ParsePortCounters(body)
+}
+
+// portCountersEndpoint returns the endpoint of the counters of the managed objects of the pod
+func portCountersEndpoint(podID string) string {
+	return apicURL("/node/mo/topology/pod-%s.json?query-target=subtree&target-subtree-class=%s",
+		podID, strings.Join(PortCountersClasses, ","))
+}
+
+// PortExists checks whether the given port is still present in APIC
+func PortExists(podID, ACISwitchID, portID string) (bool, error) {
+	endpoint := apicURL("/node/mo/%s.json", PortDN(podID, ACISwitchID, portID))
+	body, err := getAPICData("", endpoint)
+	if err != nil {
+		return false, err
 	}
-	if newClient.httpClient, err = httpConf.GetHTTPClientObj(); err != nil {
-		return nil, err
+	portResponseData, err := ParsePortCollection(body)
+	if err != nil {
+		return false, err
 	}
-	req.Close = true
-	req.Header.Set("Accept", "application/json")
-	req.AddCookie(&http.Cookie{
-		Name:  "APIC-Cookie",
-		Value: aciClient.AuthToken.Token,
-	})
-	req.Close = true
+	return len(portResponseData.IMData) > 0, nil
+}
 
-	resp, err := newClient.httpClient.Do(req)
+// GetPhysicalInterface collects the attributes of the l1PhysIf of the port, which holds the
+// configuration applied on the port like its description (descr) and admin speed (speed)
+func GetPhysicalInterface(podID, ACISwitchID, portID string) (map[string]interface{}, error) {
+	body, err := getAPICData("", apicURL("/node/mo/%s.json", PortDN(podID, ACISwitchID, portID)))
 	if err != nil {
 		return nil, err
 	}
-	defer resp.Body.Close()
-	body, err := ioutil.ReadAll(resp.Body)
+	portResponseData, err := ParsePortCollection(body)
 	if err != nil {
 		return nil, err
 	}
-	if resp.StatusCode >= 300 {
-		errMsg := fmt.Sprintf("Get on the URL %s is giving response with status code %d with response body %s", endpoint, resp.StatusCode, string(body))
-		return nil, fmt.Errorf(errMsg)
+	if len(portResponseData.IMData) == 0 {
+		return nil, fmt.Errorf("%w: no l1PhysIf in response", ErrAPICResponseMalformed)
+	}
+	return portResponseData.IMData[0].PhysicalInterface.Attributes, nil
+}
+
+// ApplyPortSettings requests APIC to apply the l1PhysIf attributes of the settings on the port through
+// an interface override policy, descr and speed are supported. APIC applies them asynchronously,
+// the applied values are reported in the l1PhysIf of the port. The request carries the trace context of traceParent.
+func ApplyPortSettings(traceParent, podID, ACISwitchID, portID string, settings map[string]string) error {
+	payload, err := portSettingsPayload(podID, ACISwitchID, portID, settings)
+	if err != nil {
+		return err
 	}
+	return postAPICData(traceParent, apicURL("/node/mo/uni/infra.json"), payload)
+}
 
-	var portResponseData capmodel.Health
-	json.Unmarshal(body, &portResponseData)
-	return &portResponseData, nil
+// portSettingsPayload builds the interface override policy of the port applying the settings, the
+// admin speed is applied through a link level policy of the speed shared by the ports of that speed
+func portSettingsPayload(podID, ACISwitchID, portID string, settings map[string]string) ([]byte, error) {
+	type managedObject map[string]map[string]interface{}
+	overrideAttributes := map[string]interface{}{
+		"name": fmt.Sprintf("odim-%s-%s", ACISwitchID, strings.Replace(portID, "/", "-", -1)),
+	}
+	overrideChildren := []managedObject{
+		{"infraRsHPathAtt": {"attributes": map[string]string{
+			"tDn": PortPathDN(podID, ACISwitchID, portID),
+		}}},
+	}
+	for attribute := range settings {
+		if attribute != "descr" && attribute != "speed" {
+			return nil, fmt.Errorf("l1PhysIf attribute %s can't be set on the port", attribute)
+		}
+	}
+	var infraChildren []managedObject
+	if descr, ok := settings["descr"]; ok {
+		overrideAttributes["descr"] = descr
+	}
+	if speed, ok := settings["speed"]; ok {
+		policyName := "odim-speed-" + speed
+		infraChildren = append(infraChildren, managedObject{"fabricHIfPol": {"attributes": map[string]string{
+			"name":  policyName,
+			"speed": speed,
+		}}})
+		overrideChildren = append(overrideChildren, managedObject{"infraRsHIfPol": {"attributes": map[string]string{
+			"tnFabricHIfPolName": policyName,
+		}}})
+	}
+	infraChildren = append(infraChildren, managedObject{"infraHPathS": {
+		"attributes": overrideAttributes,
+		"children":   overrideChildren,
+	}})
+	return json.Marshal(managedObject{"infraInfra": {
+		"attributes": map[string]string{},
+		"children":   infraChildren,
+	}})
+}
 
+// PortExistenceCheck returns the callback used by capmodel to verify the
+// presence of a port of the given switch in APIC before storing it
+func PortExistenceCheck(podID, ACISwitchID string) capmodel.PortExistenceCheck {
+	return func(portID string) (bool, error) {
+		return PortExists(podID, ACISwitchID, portID)
+	}
 }
 
 // GetPortPolicyGroup collects all policy group for given fabric and  switch
 func GetPortPolicyGroup(podID, switchPath string) ([]*models.FabricPathEndpoint, error) {
 	serviceManager := GetConnection()
-	endPointUrL := fmt.Sprintf("/api/node/class/topology/pod-%s/protpaths%s/fabricPathEp.json", podID, switchPath)
+	endPointUrL := apicPath("/node/class/topology/pod-%s/protpaths%s/fabricPathEp.json", podID, switchPath)
 
 	cont, err := serviceManager.GetViaURL(endPointUrL)
 	list := models.FabricPathEndpointListFromContainer(cont)
@@ -381,8 +593,8 @@ func GetPortPolicyGroup(podID, switchPath string) ([]*models.FabricPathEndpoint,
 	return list, err
 }
 
-// CheckValidityOfEthernet check if provided Ethernet is available in ODIM
-func CheckValidityOfEthernet(reqURL string, odimUsername string, odimPassword string) (bool, error) {
+// CheckValidityOfEthernet check if provided Ethernet is available in ODIM, the request carries the trace context of traceParent
+func CheckValidityOfEthernet(traceParent, reqURL string, odimUsername string, odimPassword string) (bool, error) {
 	req, err := http.NewRequest("GET", reqURL, nil)
 	if err != nil {
 		return false, err
@@ -394,6 +606,9 @@ func CheckValidityOfEthernet(reqURL string, odimUsername string, odimPassword st
 
 	auth := odimUsername + ":" + odimPassword
 	req.Header.Set("Authorization", "Basic "+base64.StdEncoding.EncodeToString([]byte(auth)))
+	if traceParent != "" {
+		req.Header.Set(captrace.TraceParentHeader, traceParent)
+	}
 	resp, err := newClient.httpClient.Do(req)
 	if err != nil {
 		return false, err
@@ -0,0 +1,211 @@
+//(C) Copyright [2020] Hewlett Packard Enterprise Development LP
+//
+//Licensed under the Apache License, Version 2.0 (the "License"); you may
+//not use this file except in compliance with the License. You may obtain
+//a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+//Unless required by applicable law or agreed to in writing, software
+//distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+//WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+//License for the specific language governing permissions and limitations
+// under the License.
+
+package caputilities
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/ODIM-Project/PluginCiscoACI/captrace"
+	"github.com/ODIM-Project/PluginCiscoACI/config"
+)
+
+func TestFabricHealthEndpoint(t *testing.T) {
+	config.SetUpMockConfig(t)
+	host := config.Data.APICConf.APICHost
+	defer func() { config.Data.APICConf.Tenant = "" }()
+
+	tests := []struct {
+		name   string
+		tenant string
+		want   string
+	}{
+		{"fabric-wide when no tenant is configured", "", "https://" + host + "/api/node/mo/topology/pod-1/health.json"},
+		{"scoped to the configured tenant", "Sales", "https://" + host + "/api/node/mo/uni/tn-Sales/health.json"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config.Data.APICConf.Tenant = tt.tenant
+			if got := fabricHealthEndpoint("1"); got != tt.want {
+				t.Errorf("fabricHealthEndpoint() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPortInfoEndpoint(t *testing.T) {
+	config.SetUpMockConfig(t)
+	host := config.Data.APICConf.APICHost
+	defer func() { config.Data.APICConf.APIBasePath = "" }()
+
+	tests := []struct {
+		name     string
+		basePath string
+		want     string
+	}{
+		{"default base path", "", "https://" + host + "/api/node/mo/topology/pod-1/node-101/sys/phys-[eth1/1]/phys.json"},
+		{"proxied base path", "/apic/v2/api", "https://" + host + "/apic/v2/api/node/mo/topology/pod-1/node-101/sys/phys-[eth1/1]/phys.json"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config.Data.APICConf.APIBasePath = tt.basePath
+			if got := portInfoEndpoint("1", "101", "eth1/1"); got != tt.want {
+				t.Errorf("portInfoEndpoint() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAPICLoginName(t *testing.T) {
+	config.SetUpMockConfig(t)
+	defer func() { config.Data.APICConf.LoginDomain = "" }()
+	var loginName string
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var login struct {
+			AAAUser struct {
+				Attributes struct {
+					Name string `json:"name"`
+				} `json:"attributes"`
+			} `json:"aaaUser"`
+		}
+		json.NewDecoder(r.Body).Decode(&login)
+		loginName = login.AAAUser.Attributes.Name
+		w.Write([]byte(`{"imdata":[{"aaaLogin":{"attributes":{"token":"token","refreshTimeoutSeconds":"600"}}}]}`))
+	}))
+	defer server.Close()
+	config.Data.APICConf.APICHost = strings.TrimPrefix(server.URL, "https://")
+	user := config.Data.APICConf.UserName
+
+	tests := []struct {
+		name   string
+		domain string
+		want   string
+	}{
+		{"default domain", "", user},
+		{"TACACS domain", "TACACS", `apic:TACACS\` + user},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config.Data.APICConf.LoginDomain = tt.domain
+			newAPICClient().Authenticate()
+			if loginName != tt.want {
+				t.Errorf("login payload name = %s, want %s", loginName, tt.want)
+			}
+		})
+	}
+}
+
+func TestAPICHTTPClientServerName(t *testing.T) {
+	config.SetUpMockConfig(t)
+	defer func() { config.Data.APICConf.TLSServerName = "" }()
+	for _, serverName := range []string{"", "apic.example.com"} {
+		config.Data.APICConf.TLSServerName = serverName
+		httpClient, err := apicHTTPClient()
+		if err != nil {
+			t.Fatalf("apicHTTPClient() error = %v", err)
+		}
+		transport, ok := httpClient.Transport.(*http.Transport)
+		if !ok || transport.TLSClientConfig == nil {
+			t.Fatalf("apicHTTPClient() transport = %T, want *http.Transport with TLS config", httpClient.Transport)
+		}
+		if transport.TLSClientConfig.ServerName != serverName {
+			t.Errorf("apicHTTPClient() ServerName = %q, want %q", transport.TLSClientConfig.ServerName, serverName)
+		}
+	}
+}
+
+func TestPortDN(t *testing.T) {
+	if got, want := PortDN("1", "101", "eth1/33"), "topology/pod-1/node-101/sys/phys-[eth1/33]"; got != want {
+		t.Errorf("PortDN() = %s, want %s", got, want)
+	}
+}
+
+func TestSwitchPortsHealthEndpoint(t *testing.T) {
+	config.SetUpMockConfig(t)
+	want := "https://" + config.Data.APICConf.APICHost + "/api/node/class/topology/pod-1/node-101/healthInst.json?query-target-filter=" +
+		"wcard%28healthInst.dn%2C%22%2Fphys%2Fhealth%22%29"
+	if got := switchPortsHealthEndpoint("1", "101"); got != want {
+		t.Errorf("switchPortsHealthEndpoint() = %s, want %s", got, want)
+	}
+}
+
+func TestPortStatsHistoryEndpoint(t *testing.T) {
+	config.SetUpMockConfig(t)
+	want := "https://" + config.Data.APICConf.APICHost + "/api/node/mo/topology/pod-1/node-101/sys/phys-[eth1/1].json" +
+		"?query-target=subtree&target-subtree-class=eqptIngrBytesHist1h,eqptEgrBytesHist1h"
+	if got := portStatsHistoryEndpoint("1", "101", "eth1/1", "1h"); got != want {
+		t.Errorf("portStatsHistoryEndpoint() = %s, want %s", got, want)
+	}
+	if IsPortStatsGranularity("1m") || !IsPortStatsGranularity("1qtr") {
+		t.Error("IsPortStatsGranularity() doesn't match the APIC granularities")
+	}
+}
+
+func TestPortSettingsPayload(t *testing.T) {
+	payload, err := portSettingsPayload("1", "101", "eth1/1", map[string]string{"descr": "uplink", "speed": "10G"})
+	if err != nil {
+		t.Fatalf("portSettingsPayload() error = %v", err)
+	}
+	want := `{"infraInfra":{"attributes":{},"children":[` +
+		`{"fabricHIfPol":{"attributes":{"name":"odim-speed-10G","speed":"10G"}}},` +
+		`{"infraHPathS":{"attributes":{"descr":"uplink","name":"odim-101-eth1-1"},"children":[` +
+		`{"infraRsHPathAtt":{"attributes":{"tDn":"topology/pod-1/paths-101/pathep-[eth1/1]"}}},` +
+		`{"infraRsHIfPol":{"attributes":{"tnFabricHIfPolName":"odim-speed-10G"}}}]}}]}}`
+	if string(payload) != want {
+		t.Errorf("portSettingsPayload() = %s, want %s", payload, want)
+	}
+	if _, err := portSettingsPayload("1", "101", "eth1/1", map[string]string{"mtu": "9000"}); err == nil {
+		t.Error("portSettingsPayload() with mtu, want error")
+	}
+}
+
+func TestPortOutOfServicePayload(t *testing.T) {
+	tests := []struct {
+		outOfService bool
+		want         string
+	}{
+		{true, `{"fabricRsOosPath":{"attributes":{"lc":"blacklist","tDn":"topology/pod-1/paths-101/pathep-[eth1/1]"}}}`},
+		{false, `{"fabricRsOosPath":{"attributes":{"status":"deleted","tDn":"topology/pod-1/paths-101/pathep-[eth1/1]"}}}`},
+	}
+	for _, tt := range tests {
+		if payload := portOutOfServicePayload("1", "101", "eth1/1", tt.outOfService); string(payload) != tt.want {
+			t.Errorf("portOutOfServicePayload(%t) = %s, want %s", tt.outOfService, payload, tt.want)
+		}
+	}
+}
+
+func TestDoAPICRequestTraceParent(t *testing.T) {
+	config.SetUpMockConfig(t)
+	var got []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = append(got, r.Header.Get(captrace.TraceParentHeader))
+		w.Write([]byte(`{"totalCount":"0","imdata":[]}`))
+	}))
+	defer server.Close()
+
+	traceParent := "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+	if _, err := doAPICRequest(traceParent, http.MethodGet, server.URL, "token", nil); err != nil {
+		t.Fatalf("doAPICRequest() error = %v", err)
+	}
+	if _, err := doAPICRequest("", http.MethodGet, server.URL, "token", nil); err != nil {
+		t.Fatalf("doAPICRequest() without trace context error = %v", err)
+	}
+	if len(got) != 2 || got[0] != traceParent || got[1] != "" {
+		t.Errorf("APIC received traceparent %q, want %q and none", got, traceParent)
+	}
+}
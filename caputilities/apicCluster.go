@@ -0,0 +1,301 @@
+//(C) Copyright [2020] Hewlett Packard Enterprise Development LP
+//
+//Licensed under the Apache License, Version 2.0 (the "License"); you may
+//not use this file except in compliance with the License. You may obtain
+//a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+//Unless required by applicable law or agreed to in writing, software
+//distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+//WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+//License for the specific language governing permissions and limitations
+// under the License.
+
+//Package caputilities ...
+package caputilities
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ODIM-Project/PluginCiscoACI/config"
+	"github.com/ciscoecosystem/aci-go-client/client"
+	"github.com/ciscoecosystem/aci-go-client/models"
+	log "github.com/sirupsen/logrus"
+)
+
+// Quorum reads of the APIC cluster
+//
+// A controller of a multi-controller APIC cluster which is not a member of the cluster quorum,
+// like during a cluster transition, can serve stale data. With APICConf.QuorumReads the fabric
+// topology read during the discovery is read from the controllers of the cluster, APICHost and
+// ClusterHosts, in turn: the controllers reporting they are not a member of the quorum are
+// skipped, and the data is returned once two controllers agree on it. The data of a single
+// controller is returned when no other one could be read.
+
+// Balanced reads of the APIC cluster
+//
+// With APICConf.BalanceReads the reads made to APIC are spread over the controllers of the cluster,
+// APICHost and ClusterHosts, in proportion to their HostWeights with a smooth weighted round-robin.
+// A read failing on a controller, as it can't be reached or answers with a server error, is retried
+// on the other controllers. The writes and the websocket subscriptions are always made to APICHost.
+
+// ErrAPICClusterInconsistent is returned when the controllers of the APIC cluster don't agree on the data read
+var ErrAPICClusterInconsistent = errors.New("APIC controllers returned different data")
+
+// apicQuorumErrText is present in the error text of a controller which is not a member of the cluster quorum
+const apicQuorumErrText = "quorum"
+
+// getAPICHostData collects the response body of GET on the given path of the APIC REST API
+// with the token of the controller and the trace context of traceParent, replaced in unit tests
+var getAPICHostData = func(traceParent, host, path string) ([]byte, error) {
+	tokens := apicHostTokens(host)
+	token, err := tokens.get(time.Now(), apicTokenClockSkew())
+	if err != nil {
+		return nil, err
+	}
+	body, err := doAPICRequest(traceParent, http.MethodGet, "https://"+host+path, token, nil)
+	tokens.invalidate(token, err)
+	return body, err
+}
+
+// apicHostBalancer picks the controllers the reads are made to with a smooth weighted round-robin,
+// each controller is picked in proportion to its weight and the picks of a controller are spread out
+type apicHostBalancer struct {
+	lock    sync.Mutex
+	hosts   []string
+	weights []int
+	current []int
+}
+
+var (
+	apicReadBalancer     *apicHostBalancer
+	apicReadBalancerLock sync.Mutex
+)
+
+func newAPICHostBalancer(hosts []string, weights map[string]int) *apicHostBalancer {
+	balancer := &apicHostBalancer{
+		hosts:   hosts,
+		weights: make([]int, len(hosts)),
+		current: make([]int, len(hosts)),
+	}
+	for i, host := range hosts {
+		if balancer.weights[i] = weights[host]; balancer.weights[i] <= 0 {
+			balancer.weights[i] = 1
+		}
+	}
+	return balancer
+}
+
+// next returns the controllers to read from in order, the picked controller first and then the
+// others by decreasing weight, to which the read fails over
+func (b *apicHostBalancer) next() []string {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	picked, total := 0, 0
+	for i, weight := range b.weights {
+		b.current[i] += weight
+		total += weight
+		if b.current[i] > b.current[picked] {
+			picked = i
+		}
+	}
+	b.current[picked] -= total
+	hosts := []string{b.hosts[picked]}
+	others := make([]int, 0, len(b.hosts)-1)
+	for i := range b.hosts {
+		if i != picked {
+			others = append(others, i)
+		}
+	}
+	sort.SliceStable(others, func(i, j int) bool { return b.weights[others[i]] > b.weights[others[j]] })
+	for _, i := range others {
+		hosts = append(hosts, b.hosts[i])
+	}
+	return hosts
+}
+
+// getAPICReadBalancer returns the balancer of the configured controllers, it is built again when the
+// controllers or their weights are reconfigured
+func getAPICReadBalancer() *apicHostBalancer {
+	apicReadBalancerLock.Lock()
+	defer apicReadBalancerLock.Unlock()
+	hosts := apicClusterHosts()
+	balancer := newAPICHostBalancer(hosts, config.Data.APICConf.HostWeights)
+	if apicReadBalancer == nil || !reflect.DeepEqual(apicReadBalancer.hosts, balancer.hosts) ||
+		!reflect.DeepEqual(apicReadBalancer.weights, balancer.weights) {
+		apicReadBalancer = balancer
+	}
+	return apicReadBalancer
+}
+
+// readBalancedAPIC collects the response body of GET on the given path of the APIC REST API from
+// the controller picked by the balancer, failing over to the other controllers
+func readBalancedAPIC(traceParent, path string) ([]byte, error) {
+	var lastErr error
+	for _, host := range getAPICReadBalancer().next() {
+		body, err := getAPICHostData(traceParent, host, path)
+		if err == nil {
+			return body, nil
+		}
+		if !isAPICFailover(err) {
+			return nil, err
+		}
+		log.Warn(fmt.Sprintf("while reading from APIC controller %s, got: %v, reading from another controller", host, err))
+		lastErr = err
+	}
+	return nil, fmt.Errorf("no APIC controller of the cluster could be read: %w", lastErr)
+}
+
+// isAPICFailover reports whether the read failing with the error is retried on another controller,
+// the controller couldn't be reached or answered with a server error. The errors about the request
+// itself and the throttling of the plugin would be the same on the other controllers.
+func isAPICFailover(err error) bool {
+	var apicErr *APICError
+	if errors.As(err, &apicErr) {
+		return apicErr.StatusCode >= http.StatusInternalServerError
+	}
+	var throttleErr *APICThrottleError
+	return !errors.As(err, &throttleErr) && !errors.Is(err, ErrAPICResponseMalformed)
+}
+
+// apicQuorumRead reads the data from the controller and returns it with its digest,
+// the data of two controllers agree when their digests are the same
+type apicQuorumRead func(host string) (data interface{}, digest string, err error)
+
+// apicClusterHosts returns the controllers of the APIC cluster, APICHost first
+func apicClusterHosts() []string {
+	return append([]string{config.Data.APICConf.APICHost}, config.Data.APICConf.ClusterHosts...)
+}
+
+// isAPICQuorumError reports whether the controller rejected the request as it is not a member of the cluster quorum
+func isAPICQuorumError(err error) bool {
+	return strings.Contains(strings.ToLower(err.Error()), apicQuorumErrText)
+}
+
+// readAPICQuorum reads the data from the controllers of the cluster until two of them agree on it,
+// the data is returned with the controller it was read from
+func readAPICQuorum(read apicQuorumRead) (interface{}, string, error) {
+	type reply struct {
+		host   string
+		data   interface{}
+		digest string
+	}
+	var replies []reply
+	var lastErr error
+	for _, host := range apicClusterHosts() {
+		data, digest, err := read(host)
+		if err != nil {
+			if isAPICQuorumError(err) {
+				log.Warn("APIC controller " + host + " is not a member of the cluster quorum, reading from another controller")
+			} else {
+				log.Warn(fmt.Sprintf("while reading from APIC controller %s, got: %v", host, err))
+			}
+			lastErr = err
+			continue
+		}
+		for _, previous := range replies {
+			if previous.digest == digest {
+				return previous.data, previous.host, nil
+			}
+		}
+		replies = append(replies, reply{host: host, data: data, digest: digest})
+	}
+	switch len(replies) {
+	case 0:
+		return nil, "", fmt.Errorf("no APIC controller of the cluster could be read: %w", lastErr)
+	case 1:
+		log.Warn("only APIC controller " + replies[0].host + " could be read, its data is not checked against another controller")
+		return replies[0].data, replies[0].host, nil
+	}
+	hosts := make([]string, 0, len(replies))
+	for _, r := range replies {
+		hosts = append(hosts, r.host)
+	}
+	return nil, "", fmt.Errorf("%w: %s", ErrAPICClusterInconsistent, strings.Join(hosts, ", "))
+}
+
+// getTopologyData collects the response body of GET on the APIC REST API path given by the format and
+// its arguments, from two controllers in quorum when QuorumReads is configured
+func getTopologyData(format string, args ...interface{}) ([]byte, error) {
+	if !config.Data.APICConf.QuorumReads {
+		return getAPICDataWithToken(apicURL(format, args...), aciClient.AuthToken.Token)
+	}
+	path := apicPath(format, args...)
+	data, _, err := readAPICQuorum(func(host string) (interface{}, string, error) {
+		body, err := getAPICHostData("", host, path)
+		if err != nil {
+			return nil, "", err
+		}
+		digest, err := apicDataDigest(body)
+		if err != nil {
+			return nil, "", err
+		}
+		return body, digest, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return data.([]byte), nil
+}
+
+// apicDataDigest returns the managed objects of the response body in a canonical form, so that the same
+// managed objects listed in another order by another controller have the same digest
+func apicDataDigest(body []byte) (string, error) {
+	var resp struct {
+		IMData []interface{} `json:"imdata"`
+	}
+	if err := parseAPICResponse(body, &resp); err != nil {
+		return "", err
+	}
+	managedObjects := make([]string, 0, len(resp.IMData))
+	for _, mo := range resp.IMData {
+		data, err := json.Marshal(mo)
+		if err != nil {
+			return "", err
+		}
+		managedObjects = append(managedObjects, string(data))
+	}
+	sort.Strings(managedObjects)
+	return strings.Join(managedObjects, "\n"), nil
+}
+
+// fabricNodeRead is the fabric node members read from a controller with the client used
+type fabricNodeRead struct {
+	client  *client.Client
+	members []*models.FabricNodeMember
+}
+
+// getQuorumFabricNodeData collects the fabric node members from two controllers in quorum, the
+// client of the controller they are read from is kept for the reads following the discovery
+func getQuorumFabricNodeData() ([]*models.FabricNodeMember, error) {
+	data, host, err := readAPICQuorum(func(host string) (interface{}, string, error) {
+		hostClient := newAPICHostClient(host)
+		members, err := client.NewServiceManager(apicPath("/node/mo"), hostClient).ListFabricNodeMember()
+		if err != nil {
+			return nil, "", err
+		}
+		digests := make([]string, 0, len(members))
+		for _, member := range members {
+			digests = append(digests, fmt.Sprintf("%+v", *member))
+		}
+		sort.Strings(digests)
+		return fabricNodeRead{client: hostClient, members: members}, strings.Join(digests, "\n"), nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	read := data.(fabricNodeRead)
+	log.Info("fabric node members read from APIC controller " + host)
+	aciClient = read.client
+	aciServiceManager = client.NewServiceManager(apicPath("/node/mo"), aciClient)
+	return read.members, nil
+}
@@ -0,0 +1,174 @@
+//(C) Copyright [2020] Hewlett Packard Enterprise Development LP
+//
+//Licensed under the Apache License, Version 2.0 (the "License"); you may
+//not use this file except in compliance with the License. You may obtain
+//a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+//Unless required by applicable law or agreed to in writing, software
+//distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+//WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+//License for the specific language governing permissions and limitations
+// under the License.
+
+package caputilities
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"testing"
+
+	"github.com/ODIM-Project/PluginCiscoACI/config"
+)
+
+const apicNotInQuorumBody = `{"totalCount":"1","imdata":[{"error":{"attributes":{"code":"503","text":"Unable to process the query, this APIC is not a member of the quorum"}}}]}`
+
+// mockAPICCluster replaces the reads of the controllers with the responses of the given controllers,
+// the controllers read are returned in order
+func mockAPICCluster(t *testing.T, responses map[string]string) *[]string {
+	config.SetUpMockConfig(t)
+	config.Data.APICConf.APICHost = "apic1"
+	config.Data.APICConf.ClusterHosts = []string{"apic2", "apic3"}
+	config.Data.APICConf.QuorumReads = true
+	var read []string
+	defaultGetAPICHostData := getAPICHostData
+	getAPICHostData = func(traceParent, host, path string) ([]byte, error) {
+		read = append(read, host)
+		body := responses[host]
+		statusCode := http.StatusOK
+		if body == apicNotInQuorumBody {
+			statusCode = http.StatusServiceUnavailable
+		}
+		if err := checkAPICResponse(http.MethodGet, "https://"+host+path, statusCode, []byte(body)); err != nil {
+			return nil, err
+		}
+		return []byte(body), nil
+	}
+	t.Cleanup(func() {
+		config.Data.APICConf.ClusterHosts = nil
+		config.Data.APICConf.QuorumReads = false
+		getAPICHostData = defaultGetAPICHostData
+	})
+	return &read
+}
+
+func TestGetPortDataQuorumReads(t *testing.T) {
+	ports := `{"totalCount":"2","imdata":[{"l1PhysIf":{"attributes":{"id":"eth1/1"}}},{"l1PhysIf":{"attributes":{"id":"eth1/2"}}}]}`
+	// the same ports listed in another order
+	reordered := `{"totalCount":"2","imdata":[{"l1PhysIf":{"attributes":{"id":"eth1/2"}}},{"l1PhysIf":{"attributes":{"id":"eth1/1"}}}]}`
+	stale := `{"totalCount":"1","imdata":[{"l1PhysIf":{"attributes":{"id":"eth1/1"}}}]}`
+	tests := []struct {
+		name      string
+		responses map[string]string
+		wantPorts int
+		wantRead  []string
+		wantErr   error
+	}{
+		{"controllers agree", map[string]string{"apic1": ports, "apic2": reordered, "apic3": stale}, 2, []string{"apic1", "apic2"}, nil},
+		{"controller not in quorum", map[string]string{"apic1": apicNotInQuorumBody, "apic2": ports, "apic3": ports}, 2, []string{"apic1", "apic2", "apic3"}, nil},
+		{"stale controller outvoted", map[string]string{"apic1": stale, "apic2": ports, "apic3": ports}, 2, []string{"apic1", "apic2", "apic3"}, nil},
+		{"single controller in quorum", map[string]string{"apic1": apicNotInQuorumBody, "apic2": apicNotInQuorumBody, "apic3": stale}, 1, []string{"apic1", "apic2", "apic3"}, nil},
+		{"controllers disagree", map[string]string{"apic1": stale, "apic2": ports, "apic3": apicNotInQuorumBody}, 0, []string{"apic1", "apic2", "apic3"}, ErrAPICClusterInconsistent},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			read := mockAPICCluster(t, tt.responses)
+			portData, err := GetPortData("1", "101")
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Errorf("GetPortData() error = %v, want %v", err, tt.wantErr)
+				}
+			} else if err != nil || len(portData.IMData) != tt.wantPorts {
+				t.Errorf("GetPortData() = %v, %v, want %d ports", portData, err, tt.wantPorts)
+			}
+			if !reflect.DeepEqual(*read, tt.wantRead) {
+				t.Errorf("controllers read = %v, want %v", *read, tt.wantRead)
+			}
+		})
+	}
+}
+
+func TestGetPortDataNoControllerInQuorum(t *testing.T) {
+	mockAPICCluster(t, map[string]string{"apic1": apicNotInQuorumBody, "apic2": apicNotInQuorumBody, "apic3": apicNotInQuorumBody})
+	_, err := GetPortData("1", "101")
+	var apicErr *APICError
+	if err == nil || !errors.As(err, &apicErr) || !isAPICQuorumError(err) {
+		t.Errorf("GetPortData() error = %v, want the APIC error of the controller not in quorum", err)
+	}
+}
+
+func TestAPICDataDigest(t *testing.T) {
+	digest, err := apicDataDigest([]byte(`{"imdata":[{"b":{"attributes":{"id":"2"}}},{"a":{"attributes":{"id":"1"}}}]}`))
+	if err != nil {
+		t.Fatalf("apicDataDigest() error = %v", err)
+	}
+	if want := fmt.Sprintf("%s\n%s", `{"a":{"attributes":{"id":"1"}}}`, `{"b":{"attributes":{"id":"2"}}}`); digest != want {
+		t.Errorf("apicDataDigest() = %s, want %s", digest, want)
+	}
+	if _, err := apicDataDigest([]byte(apicNotInQuorumBody)); !isAPICQuorumError(err) {
+		t.Errorf("apicDataDigest() of controller not in quorum error = %v, want quorum error", err)
+	}
+}
+
+func TestGetPortHealthBalancedReads(t *testing.T) {
+	health := `{"totalCount":"1","imdata":[{"healthInst":{"attributes":{"cur":"100"}}}]}`
+	read := mockAPICCluster(t, map[string]string{"apic1": health, "apic2": health, "apic3": health})
+	config.Data.APICConf.QuorumReads = false
+	config.Data.APICConf.BalanceReads = true
+	config.Data.APICConf.HostWeights = map[string]int{"apic2": 3, "apic3": 6}
+	apicReadBalancer = nil
+	defer func() {
+		config.Data.APICConf.BalanceReads = false
+		config.Data.APICConf.HostWeights = nil
+	}()
+
+	const calls = 1000
+	for i := 0; i < calls; i++ {
+		if _, err := GetPortHealth("", "1", "101", "eth1/1"); err != nil {
+			t.Fatalf("GetPortHealth() error = %v", err)
+		}
+	}
+	reads := make(map[string]int)
+	for _, host := range *read {
+		reads[host]++
+	}
+	// apic1 has the default weight 1, out of the total weight 10
+	for host, share := range map[string]float64{"apic1": 0.1, "apic2": 0.3, "apic3": 0.6} {
+		if got := float64(reads[host]) / calls; got < share-0.02 || got > share+0.02 {
+			t.Errorf("share of the reads made to %s = %.3f, want %.1f", host, got, share)
+		}
+	}
+}
+
+func TestGetPortHealthBalancedReadsFailover(t *testing.T) {
+	health := `{"totalCount":"1","imdata":[{"healthInst":{"attributes":{"cur":"100"}}}]}`
+	notFound := `{"totalCount":"1","imdata":[{"error":{"attributes":{"code":"103","text":"object not found"}}}]}`
+	read := mockAPICCluster(t, map[string]string{"apic1": health, "apic2": apicNotInQuorumBody, "apic3": health})
+	config.Data.APICConf.QuorumReads = false
+	config.Data.APICConf.BalanceReads = true
+	config.Data.APICConf.HostWeights = map[string]int{"apic2": 10}
+	apicReadBalancer = nil
+	defer func() {
+		config.Data.APICConf.BalanceReads = false
+		config.Data.APICConf.HostWeights = nil
+	}()
+
+	// apic2 is picked first and the read fails over to another controller
+	if _, err := GetPortHealth("", "1", "101", "eth1/1"); err != nil {
+		t.Fatalf("GetPortHealth() error = %v", err)
+	}
+	if len(*read) != 2 || (*read)[0] != "apic2" {
+		t.Errorf("controllers read = %v, want apic2 then another controller", *read)
+	}
+
+	// the errors about the request itself are not failed over
+	read = mockAPICCluster(t, map[string]string{"apic1": notFound, "apic2": notFound, "apic3": notFound})
+	config.Data.APICConf.QuorumReads = false
+	config.Data.APICConf.BalanceReads = true
+	if _, err := GetPortHealth("", "1", "101", "eth1/1"); err == nil || len(*read) != 1 {
+		t.Errorf("GetPortHealth() of missing object = %v after reading %v, want error from a single controller", err, *read)
+	}
+}
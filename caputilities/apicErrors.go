@@ -0,0 +1,126 @@
+//(C) Copyright [2020] Hewlett Packard Enterprise Development LP
+//
+//Licensed under the Apache License, Version 2.0 (the "License"); you may
+//not use this file except in compliance with the License. You may obtain
+//a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+//Unless required by applicable law or agreed to in writing, software
+//distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+//WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+//License for the specific language governing permissions and limitations
+// under the License.
+
+//Package caputilities ...
+package caputilities
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+// ErrAPICWritePrivilege is returned when APIC rejects a request because the
+// configured APIC account is not allowed to modify the interface policy
+var ErrAPICWritePrivilege = errors.New("APIC account lacks write privilege for interface policy")
+
+const (
+	// apicAccessDeniedCode is the error code APIC sets when the token of the request is invalid or expired
+	apicAccessDeniedCode = "403"
+	// apicWriteDeniedCode is the error code APIC sets when RBAC denies the account the write of the managed object
+	apicWriteDeniedCode = "122"
+	// apicUnauthorizedCode is the error code APIC sets when the login fails
+	apicUnauthorizedCode = "401"
+	// apicObjectNotFoundCode is the error code APIC sets when the configured object of the request is not found
+	apicObjectNotFoundCode = "102"
+)
+
+// APICError is the error managed object APIC returns in the imdata of the response of a failed request
+type APICError struct {
+	// Method is the method of the failed request, empty when the error was decoded from a response body only
+	Method   string
+	Endpoint string
+	// StatusCode is the status code of the APIC response, APIC also reports errors in successful responses
+	StatusCode int
+	Code       string
+	Text       string
+}
+
+func (e *APICError) Error() string {
+	if e.Endpoint == "" {
+		return fmt.Sprintf("APIC returned error %s: %s", e.Code, e.Text)
+	}
+	return fmt.Sprintf("request on the URL %s failed with APIC error %s: %s", e.Endpoint, e.Code, e.Text)
+}
+
+// Is reports the APIC errors denying a write request for missing privilege as ErrAPICWritePrivilege,
+// APIC answers them with the status code 403 and the error code 122
+func (e *APICError) Is(target error) bool {
+	return target == ErrAPICWritePrivilege && isWriteMethod(e.Method) &&
+		e.StatusCode == http.StatusForbidden && e.Code == apicWriteDeniedCode
+}
+
+// Unauthorized reports whether APIC rejected the credentials or the token of the plugin
+func (e *APICError) Unauthorized() bool {
+	return e.Code == apicUnauthorizedCode || e.Code == apicAccessDeniedCode || e.StatusCode == http.StatusUnauthorized
+}
+
+func isWriteMethod(method string) bool {
+	return method == http.MethodPost || method == http.MethodPut || method == http.MethodPatch || method == http.MethodDelete
+}
+
+// NotFound reports whether APIC didn't find the object the request is made on
+func (e *APICError) NotFound() bool {
+	return e.Code == apicObjectNotFoundCode || e.StatusCode == http.StatusNotFound
+}
+
+// apicErrorResponse is the body APIC returns when a request fails
+type apicErrorResponse struct {
+	IMData []apicErrorIMData `json:"imdata"`
+}
+
+type apicErrorIMData struct {
+	Error struct {
+		Attributes struct {
+			Code string `json:"code"`
+			Text string `json:"text"`
+		} `json:"attributes"`
+	} `json:"error"`
+}
+
+// apicError returns the first error managed object of the response, nil when there are none
+func (r apicErrorResponse) apicError() *APICError {
+	for _, imdata := range r.IMData {
+		if attributes := imdata.Error.Attributes; attributes.Code != "" || attributes.Text != "" {
+			return &APICError{Code: attributes.Code, Text: attributes.Text}
+		}
+	}
+	return nil
+}
+
+// DecodeAPICError decodes the error managed object of the APIC response body, nil is
+// returned when the body is not JSON or doesn't have an error managed object
+func DecodeAPICError(body []byte) *APICError {
+	var errResp apicErrorResponse
+	if err := json.Unmarshal(body, &errResp); err != nil {
+		return nil
+	}
+	return errResp.apicError()
+}
+
+// checkAPICResponse validates the status code of a response received from APIC, the error
+// managed object of the response is returned as APICError when the request failed
+func checkAPICResponse(method, endpoint string, statusCode int, body []byte) error {
+	if statusCode < 300 {
+		return nil
+	}
+	if apicErr := DecodeAPICError(body); apicErr != nil {
+		apicErr.Method = method
+		apicErr.Endpoint = endpoint
+		apicErr.StatusCode = statusCode
+		return apicErr
+	}
+	return fmt.Errorf("Get on the URL %s is giving response with status code %d with response body %s", endpoint, statusCode, string(body))
+}
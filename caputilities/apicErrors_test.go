@@ -0,0 +1,189 @@
+//(C) Copyright [2020] Hewlett Packard Enterprise Development LP
+//
+//Licensed under the Apache License, Version 2.0 (the "License"); you may
+//not use this file except in compliance with the License. You may obtain
+//a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+//Unless required by applicable law or agreed to in writing, software
+//distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+//WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+//License for the specific language governing permissions and limitations
+// under the License.
+
+package caputilities
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func mockAPICServer(statusCode int, body string) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(statusCode)
+		w.Write([]byte(body))
+	}))
+}
+
+func TestCheckAPICResponse(t *testing.T) {
+	writeDenied := `{"totalCount":"1","imdata":[{"error":{"attributes":{"code":"122","text":"user odim does not have domain access to config Mo, please check your role and domain"}}}]}`
+	tests := []struct {
+		name          string
+		method        string
+		statusCode    int
+		body          string
+		wantErr       bool
+		wantPrivilege bool
+	}{
+		{
+			name:       "successful response",
+			method:     http.MethodGet,
+			statusCode: http.StatusOK,
+			body:       `{"totalCount":"0","imdata":[]}`,
+		},
+		{
+			name:          "write privilege denied",
+			method:        http.MethodPost,
+			statusCode:    http.StatusForbidden,
+			body:          writeDenied,
+			wantErr:       true,
+			wantPrivilege: true,
+		},
+		{
+			name:       "write privilege code without forbidden status",
+			method:     http.MethodPost,
+			statusCode: http.StatusBadRequest,
+			body:       writeDenied,
+			wantErr:    true,
+		},
+		{
+			name:       "read denied",
+			method:     http.MethodGet,
+			statusCode: http.StatusForbidden,
+			body:       writeDenied,
+			wantErr:    true,
+		},
+		{
+			name:       "token expired on write",
+			method:     http.MethodPost,
+			statusCode: http.StatusForbidden,
+			body:       `{"totalCount":"1","imdata":[{"error":{"attributes":{"code":"403","text":"Token was invalid (Error: Token timeout), no privilege to write"}}}]}`,
+			wantErr:    true,
+		},
+		{
+			name:       "server error",
+			method:     http.MethodPost,
+			statusCode: http.StatusInternalServerError,
+			body:       `internal error`,
+			wantErr:    true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := mockAPICServer(tt.statusCode, tt.body)
+			defer server.Close()
+			resp, err := http.Get(server.URL)
+			if err != nil {
+				t.Fatalf("failed to contact mock APIC: %s", err.Error())
+			}
+			defer resp.Body.Close()
+			body, _ := ioutil.ReadAll(resp.Body)
+			err = checkAPICResponse(tt.method, server.URL, resp.StatusCode, body)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("checkAPICResponse() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if errors.Is(err, ErrAPICWritePrivilege) != tt.wantPrivilege {
+				t.Errorf("checkAPICResponse() error = %v, wantPrivilege %v", err, tt.wantPrivilege)
+			}
+		})
+	}
+}
+
+func TestDecodeAPICError(t *testing.T) {
+	tests := []struct {
+		name             string
+		body             string
+		wantCode         string
+		wantUnauthorized bool
+		wantNotFound     bool
+	}{
+		{
+			name:             "login failed",
+			body:             `{"totalCount":"1","imdata":[{"error":{"attributes":{"code":"401","text":"Username or password is incorrect - FAILED local authentication"}}}]}`,
+			wantCode:         "401",
+			wantUnauthorized: true,
+		},
+		{
+			name:             "token expired",
+			body:             `{"totalCount":"1","imdata":[{"error":{"attributes":{"code":"403","text":"Token was invalid (Error: Token timeout)"}}}]}`,
+			wantCode:         "403",
+			wantUnauthorized: true,
+		},
+		{
+			name:     "write privilege denied",
+			body:     `{"totalCount":"1","imdata":[{"error":{"attributes":{"code":"122","text":"user odim does not have domain access to config Mo, please check your role and domain"}}}]}`,
+			wantCode: "122",
+		},
+		{
+			name:         "object not found",
+			body:         `{"totalCount":"1","imdata":[{"error":{"attributes":{"code":"102","text":"configured object ((Dn0)) not found Dn0=uni/tn-ACI/ap-ODIM, "}}}]}`,
+			wantCode:     "102",
+			wantNotFound: true,
+		},
+		{
+			name:     "unresolved class",
+			body:     `{"totalCount":"1","imdata":[{"error":{"attributes":{"code":"400","text":"Request failed, unresolved class for ethpmPhys"}}}]}`,
+			wantCode: "400",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			apicErr := DecodeAPICError([]byte(tt.body))
+			if apicErr == nil || apicErr.Code != tt.wantCode {
+				t.Fatalf("DecodeAPICError() = %v, want code %s", apicErr, tt.wantCode)
+			}
+			if apicErr.Unauthorized() != tt.wantUnauthorized || apicErr.NotFound() != tt.wantNotFound {
+				t.Errorf("DecodeAPICError() = %v, want unauthorized %v, not found %v", apicErr, tt.wantUnauthorized, tt.wantNotFound)
+			}
+			// the method of the request is needed to tell a denied write
+			if errors.Is(apicErr, ErrAPICWritePrivilege) {
+				t.Errorf("DecodeAPICError() = %v, want no write privilege error without the method", apicErr)
+			}
+		})
+	}
+	for _, body := range []string{`{"totalCount":"0","imdata":[]}`, `<html>Service Unavailable</html>`} {
+		if apicErr := DecodeAPICError([]byte(body)); apicErr != nil {
+			t.Errorf("DecodeAPICError(%s) = %v, want nil", body, apicErr)
+		}
+	}
+}
+
+func TestAPICErrorReturned(t *testing.T) {
+	body := []byte(`{"totalCount":"1","imdata":[{"error":{"attributes":{"code":"102","text":"configured object ((Dn0)) not found"}}}]}`)
+	var apicErr *APICError
+	if err := checkAPICResponse(http.MethodGet, "https://apic/api/mo.json", http.StatusBadRequest, body); !errors.As(err, &apicErr) ||
+		apicErr.Endpoint != "https://apic/api/mo.json" || apicErr.StatusCode != http.StatusBadRequest || apicErr.Method != http.MethodGet {
+		t.Errorf("checkAPICResponse() error = %v, want APICError of the endpoint", err)
+	}
+	if _, err := ParseHealth(body); !errors.As(err, &apicErr) || !apicErr.NotFound() || apicErr.StatusCode != http.StatusOK {
+		t.Errorf("ParseHealth() error = %v, want APICError", err)
+	}
+}
+
+func TestAPICWritePrivilegeError(t *testing.T) {
+	err := fmt.Errorf("failed to apply port settings: %w", &APICError{Method: http.MethodPost, StatusCode: http.StatusForbidden, Code: "122"})
+	if !errors.Is(err, ErrAPICWritePrivilege) {
+		t.Errorf("error = %v, want ErrAPICWritePrivilege", err)
+	}
+	// only the error managed object of the APIC response tells a denied write
+	err = errors.New("Unauthorized: user does not have write privilege")
+	if errors.Is(err, ErrAPICWritePrivilege) {
+		t.Errorf("error = %v, want generic error", err)
+	}
+}
@@ -0,0 +1,66 @@
+//(C) Copyright [2020] Hewlett Packard Enterprise Development LP
+//
+//Licensed under the Apache License, Version 2.0 (the "License"); you may
+//not use this file except in compliance with the License. You may obtain
+//a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+//Unless required by applicable law or agreed to in writing, software
+//distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+//WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+//License for the specific language governing permissions and limitations
+// under the License.
+
+package caputilities
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/ODIM-Project/PluginCiscoACI/config"
+)
+
+// apicPage is a page of the managed objects of a class or subtree query, totalCount is
+// the number of managed objects of the query over all the pages
+type apicPage struct {
+	TotalCount string            `json:"totalCount"`
+	IMData     []json.RawMessage `json:"imdata"`
+}
+
+// getAPICPages reads the managed objects of the class or subtree query on the endpoint in pages of
+// QueryPageSize with get, and returns them in a single response body in the order of the pages.
+// The reading stops once the totalCount reported by APIC is collected, or on a short page when
+// managed objects were removed in the meantime.
+func getAPICPages(endpoint string, get func(endpoint string) ([]byte, error)) ([]byte, error) {
+	pageSize := config.Data.APICConf.QueryPageSize
+	if pageSize <= 0 {
+		pageSize = config.DefaultAPICQueryPageSize
+	}
+	separator := "?"
+	if strings.Contains(endpoint, "?") {
+		separator = "&"
+	}
+	objects := []json.RawMessage{}
+	for page := 0; ; page++ {
+		body, err := get(fmt.Sprintf("%s%spage=%d&page-size=%d", endpoint, separator, page, pageSize))
+		if err != nil {
+			return nil, err
+		}
+		var resp apicPage
+		if err := parseAPICResponse(body, &resp); err != nil {
+			return nil, err
+		}
+		totalCount, err := strconv.Atoi(resp.TotalCount)
+		if err != nil {
+			return nil, fmt.Errorf("%w: invalid totalCount %q in page %d of %s", ErrAPICResponseMalformed, resp.TotalCount, page, endpoint)
+		}
+		objects = append(objects, resp.IMData...)
+		if len(objects) >= totalCount || len(resp.IMData) < pageSize {
+			break
+		}
+	}
+	return json.Marshal(apicPage{TotalCount: strconv.Itoa(len(objects)), IMData: objects})
+}
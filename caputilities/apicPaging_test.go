@@ -0,0 +1,106 @@
+//(C) Copyright [2020] Hewlett Packard Enterprise Development LP
+//
+//Licensed under the Apache License, Version 2.0 (the "License"); you may
+//not use this file except in compliance with the License. You may obtain
+//a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+//Unless required by applicable law or agreed to in writing, software
+//distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+//WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+//License for the specific language governing permissions and limitations
+// under the License.
+
+package caputilities
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/ODIM-Project/PluginCiscoACI/config"
+)
+
+// mockAPICPages returns the read of a mock APIC serving the ports eth1/1 to eth1/<total> in
+// pages, the endpoints read are returned in order
+func mockAPICPages(t *testing.T, total int) (func(string) ([]byte, error), *[]string) {
+	var read []string
+	return func(endpoint string) ([]byte, error) {
+		read = append(read, endpoint)
+		query, err := url.ParseQuery(endpoint[strings.Index(endpoint, "?")+1:])
+		if err != nil {
+			t.Fatalf("invalid query of %s: %v", endpoint, err)
+		}
+		page, _ := strconv.Atoi(query.Get("page"))
+		pageSize, _ := strconv.Atoi(query.Get("page-size"))
+		var objects []string
+		for i := page*pageSize + 1; i <= total && i <= (page+1)*pageSize; i++ {
+			objects = append(objects, fmt.Sprintf(`{"l1PhysIf":{"attributes":{"id":"eth1/%d"}}}`, i))
+		}
+		return []byte(fmt.Sprintf(`{"totalCount":"%d","imdata":[%s]}`, total, strings.Join(objects, ","))), nil
+	}, &read
+}
+
+func TestGetAPICPages(t *testing.T) {
+	config.SetUpMockConfig(t)
+	config.Data.APICConf.QueryPageSize = 2
+	defer func() { config.Data.APICConf.QueryPageSize = config.DefaultAPICQueryPageSize }()
+	tests := []struct {
+		name     string
+		endpoint string
+		total    int
+		wantRead []string
+	}{
+		{"multiple pages", "/node/class/l1PhysIf.json", 5, []string{
+			"/node/class/l1PhysIf.json?page=0&page-size=2",
+			"/node/class/l1PhysIf.json?page=1&page-size=2",
+			"/node/class/l1PhysIf.json?page=2&page-size=2",
+		}},
+		{"full last page", "/node/class/l1PhysIf.json?query-target-filter=x", 4, []string{
+			"/node/class/l1PhysIf.json?query-target-filter=x&page=0&page-size=2",
+			"/node/class/l1PhysIf.json?query-target-filter=x&page=1&page-size=2",
+		}},
+		{"no managed objects", "/node/class/l1PhysIf.json", 0, []string{
+			"/node/class/l1PhysIf.json?page=0&page-size=2",
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			get, read := mockAPICPages(t, tt.total)
+			body, err := getAPICPages(tt.endpoint, get)
+			if err != nil {
+				t.Fatalf("getAPICPages() error = %v", err)
+			}
+			ports, err := ParsePortCollection(body)
+			if err != nil {
+				t.Fatalf("ParsePortCollection() of the assembled pages error = %v", err)
+			}
+			if len(ports.IMData) != tt.total {
+				t.Fatalf("getAPICPages() assembled %d ports, want %d", len(ports.IMData), tt.total)
+			}
+			for i, port := range ports.IMData {
+				if want := fmt.Sprintf("eth1/%d", i+1); port.PhysicalInterface.Attributes["id"] != want {
+					t.Errorf("port %d = %v, want %s", i, port.PhysicalInterface.Attributes["id"], want)
+				}
+			}
+			if !reflect.DeepEqual(*read, tt.wantRead) {
+				t.Errorf("pages read = %v, want %v", *read, tt.wantRead)
+			}
+		})
+	}
+}
+
+func TestGetAPICPagesMalformed(t *testing.T) {
+	config.SetUpMockConfig(t)
+	_, err := getAPICPages("/node/class/l1PhysIf.json", func(string) ([]byte, error) {
+		return []byte(`{"imdata":[]}`), nil
+	})
+	if !errors.Is(err, ErrAPICResponseMalformed) {
+		t.Errorf("getAPICPages() without totalCount error = %v, want ErrAPICResponseMalformed", err)
+	}
+}
@@ -0,0 +1,438 @@
+//(C) Copyright [2020] Hewlett Packard Enterprise Development LP
+//
+//Licensed under the Apache License, Version 2.0 (the "License"); you may
+//not use this file except in compliance with the License. You may obtain
+//a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+//Unless required by applicable law or agreed to in writing, software
+//distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+//WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+//License for the specific language governing permissions and limitations
+// under the License.
+
+package caputilities
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/ODIM-Project/PluginCiscoACI/capmodel"
+)
+
+// ErrAPICResponseMalformed is returned when the response of APIC doesn't have the expected managed objects
+var ErrAPICResponseMalformed = errors.New("malformed APIC response")
+
+// portObjectDNPattern matches the dn of a managed object of a physical interface, like the fault
+// topology/pod-1/node-101/sys/phys-[eth1/1]/phys/fault-F1678
+var portObjectDNPattern = regexp.MustCompile(`^topology/pod-[^/]+/node-([^/]+)/sys/phys-\[([^\]]+)\]/`)
+
+// portHealthDNPattern matches the dn of the health of a physical interface, like topology/pod-1/node-101/sys/phys-[eth1/1]/phys/health
+var portHealthDNPattern = regexp.MustCompile(`/sys/phys-\[([^\]]+)\]/phys/health$`)
+
+// parseAPICResponse decodes the APIC response body into v. APIC can report errors in the imdata
+// of a successful response, those are returned as APICError instead of decoding them as managed objects.
+func parseAPICResponse(body []byte, v interface{}) error {
+	var errResp apicErrorResponse
+	if err := json.Unmarshal(body, &errResp); err != nil {
+		return fmt.Errorf("%w: %v", ErrAPICResponseMalformed, err)
+	}
+	if apicErr := errResp.apicError(); apicErr != nil {
+		apicErr.StatusCode = http.StatusOK
+		return apicErr
+	}
+	if err := json.Unmarshal(body, v); err != nil {
+		return fmt.Errorf("%w: %v", ErrAPICResponseMalformed, err)
+	}
+	return nil
+}
+
+// ParsePortCollection decodes the l1PhysIf managed objects of the switch, each of them has attributes
+func ParsePortCollection(body []byte) (*capmodel.PortCollectionResponse, error) {
+	var portCollection capmodel.PortCollectionResponse
+	if err := parseAPICResponse(body, &portCollection); err != nil {
+		return nil, err
+	}
+	for _, imdata := range portCollection.IMData {
+		if imdata.PhysicalInterface.Attributes == nil {
+			return nil, fmt.Errorf("%w: l1PhysIf without attributes", ErrAPICResponseMalformed)
+		}
+	}
+	return &portCollection, nil
+}
+
+// ParsePortInfo decodes the ethpmPhysIf managed object of the port, the response has at least one with attributes
+func ParsePortInfo(body []byte) (*capmodel.PortInfoResponse, error) {
+	var portInfo capmodel.PortInfoResponse
+	if err := parseAPICResponse(body, &portInfo); err != nil {
+		return nil, err
+	}
+	if len(portInfo.IMData) == 0 || portInfo.IMData[0].PhysicalInterface.Attributes == nil {
+		return nil, fmt.Errorf("%w: no ethpmPhysIf in response", ErrAPICResponseMalformed)
+	}
+	return &portInfo, nil
+}
+
+// ParseHealth decodes the healthInst managed object, the response has at least one with attributes
+func ParseHealth(body []byte) (*capmodel.Health, error) {
+	var health capmodel.Health
+	if err := parseAPICResponse(body, &health); err != nil {
+		return nil, err
+	}
+	if len(health.IMData) == 0 || health.IMData[0].HealthData.Attributes == nil {
+		return nil, fmt.Errorf("%w: no healthInst in response", ErrAPICResponseMalformed)
+	}
+	return &health, nil
+}
+
+// ParseSwitchPortsHealth decodes the healthInst managed objects of the physical interfaces of a switch
+// into their health keyed by the port id, like eth1/1. The managed objects of other health are skipped.
+func ParseSwitchPortsHealth(body []byte) (map[string]capmodel.HealthData, error) {
+	var health capmodel.Health
+	if err := parseAPICResponse(body, &health); err != nil {
+		return nil, err
+	}
+	portsHealth := make(map[string]capmodel.HealthData)
+	for _, imdata := range health.IMData {
+		dn, _ := imdata.HealthData.Attributes["dn"].(string)
+		if match := portHealthDNPattern.FindStringSubmatch(dn); match != nil {
+			portsHealth[match[1]] = imdata.HealthData
+		}
+	}
+	return portsHealth, nil
+}
+
+// ParseTenantPaths decodes the fvRsPathAtt managed objects of the static path bindings of the EPGs of a tenant
+// into the set of the DNs of the fabric path endpoints they are bound to. The managed objects of other classes
+// are skipped.
+func ParseTenantPaths(body []byte) (map[string]bool, error) {
+	var response struct {
+		IMData []struct {
+			PathAttachment *struct {
+				Attributes map[string]interface{} `json:"attributes"`
+			} `json:"fvRsPathAtt"`
+		} `json:"imdata"`
+	}
+	if err := parseAPICResponse(body, &response); err != nil {
+		return nil, err
+	}
+	paths := make(map[string]bool)
+	for _, imdata := range response.IMData {
+		if imdata.PathAttachment == nil {
+			continue
+		}
+		path, err := AttributeString(imdata.PathAttachment.Attributes, "tDn")
+		if err != nil {
+			return nil, err
+		}
+		paths[path] = true
+	}
+	return paths, nil
+}
+
+// ParsePortFaults decodes the faultInst managed objects of the physical interfaces into the worst fault
+// of each port, keyed by the APIC node id and then by the port id. The cleared faults and the faults of
+// other objects are skipped.
+func ParsePortFaults(body []byte) (map[string]map[string]capmodel.PortFault, error) {
+	var response capmodel.PortFaultsResponse
+	if err := parseAPICResponse(body, &response); err != nil {
+		return nil, err
+	}
+	faults := make(map[string]map[string]capmodel.PortFault)
+	for _, imdata := range response.IMData {
+		attributes := imdata.Fault.Attributes
+		dn, _ := attributes["dn"].(string)
+		match := portObjectDNPattern.FindStringSubmatch(dn)
+		if match == nil {
+			continue
+		}
+		var fault capmodel.PortFault
+		var err error
+		if fault.Severity, err = AttributeString(attributes, "severity"); err != nil {
+			return nil, err
+		}
+		rank := FaultSeverityRank(fault.Severity)
+		if rank < 0 {
+			return nil, fmt.Errorf("%w: unknown fault severity %s", ErrAPICResponseMalformed, fault.Severity)
+		}
+		if fault.Severity == "cleared" {
+			continue
+		}
+		if fault.Code, err = AttributeString(attributes, "code"); err != nil {
+			return nil, err
+		}
+		fault.Description, _ = attributes["descr"].(string)
+		fault.Created, _ = attributes["created"].(string)
+		nodeID, portID := match[1], match[2]
+		if faults[nodeID] == nil {
+			faults[nodeID] = make(map[string]capmodel.PortFault)
+		}
+		if worst, ok := faults[nodeID][portID]; !ok || rank > FaultSeverityRank(worst.Severity) {
+			faults[nodeID][portID] = fault
+		}
+	}
+	return faults, nil
+}
+
+// ParsePortCounters decodes the 5 minute counters of the physical interfaces into the counters of
+// each port, keyed by the APIC node id and then by the port id. The bytes are the sum of the unicast,
+// multicast and flood bytes and the errors are the packets dropped for errors. The counters of other
+// objects are skipped.
+func ParsePortCounters(body []byte) (map[string]map[string]capmodel.PortCounters, error) {
+	var response capmodel.PortStatsHistoryResponse
+	if err := parseAPICResponse(body, &response); err != nil {
+		return nil, err
+	}
+	counters := make(map[string]map[string]capmodel.PortCounters)
+	for _, imdata := range response.IMData {
+		for class, mo := range imdata {
+			dn, _ := mo.Attributes["dn"].(string)
+			match := portObjectDNPattern.FindStringSubmatch(dn)
+			if match == nil {
+				continue
+			}
+			nodeID, portID := match[1], match[2]
+			portCounters := counters[nodeID][portID]
+			var err error
+			switch class {
+			case "eqptIngrBytes5min":
+				portCounters.RXBytes, err = portBytes(mo.Attributes)
+			case "eqptEgrBytes5min":
+				portCounters.TXBytes, err = portBytes(mo.Attributes)
+			case "eqptIngrDropPkts5min":
+				portCounters.RXErrors, err = attributeUint(mo.Attributes, "errorPer")
+			case "eqptEgrDropPkts5min":
+				portCounters.TXErrors, err = attributeUint(mo.Attributes, "errorPer")
+			default:
+				continue
+			}
+			if err != nil {
+				return nil, fmt.Errorf("while parsing %s: %w", class, err)
+			}
+			if intervalEnd, _ := mo.Attributes["repIntvEnd"].(string); intervalEnd > portCounters.IntervalEnd {
+				portCounters.IntervalEnd = intervalEnd
+			}
+			if counters[nodeID] == nil {
+				counters[nodeID] = make(map[string]capmodel.PortCounters)
+			}
+			counters[nodeID][portID] = portCounters
+		}
+	}
+	return counters, nil
+}
+
+// ParsePortStatsHistory decodes the ingress and egress byte counter history of the port at the given
+// granularity into samples ordered from the oldest to the most recent. The ingress and egress counters
+// of an interval share its index, the intervals with only one of them are reported with the other as 0.
+func ParsePortStatsHistory(body []byte, granularity string) ([]capmodel.PortStatsSample, error) {
+	var history capmodel.PortStatsHistoryResponse
+	if err := parseAPICResponse(body, &history); err != nil {
+		return nil, err
+	}
+	ingressClass, egressClass := portStatsHistoryClasses(granularity)
+	samples := make(map[int]*capmodel.PortStatsSample)
+	for _, imdata := range history.IMData {
+		for class, mo := range imdata {
+			if class != ingressClass && class != egressClass {
+				continue
+			}
+			index, interval, bytes, err := parsePortStatsInterval(mo.Attributes)
+			if err != nil {
+				return nil, fmt.Errorf("while parsing %s: %w", class, err)
+			}
+			sample, ok := samples[index]
+			if !ok {
+				sample = &interval
+				samples[index] = sample
+			}
+			if class == ingressClass {
+				sample.RXBytes = bytes
+			} else {
+				sample.TXBytes = bytes
+			}
+		}
+	}
+	indexes := make([]int, 0, len(samples))
+	for index := range samples {
+		indexes = append(indexes, index)
+	}
+	// APIC indexes the history from the most recent interval, 0, to the oldest
+	sort.Sort(sort.Reverse(sort.IntSlice(indexes)))
+	ordered := make([]capmodel.PortStatsSample, 0, len(indexes))
+	for _, index := range indexes {
+		ordered = append(ordered, *samples[index])
+	}
+	return ordered, nil
+}
+
+// parsePortStatsInterval decodes the index, the interval and the bytes of a counter history managed
+// object, the bytes are the sum of the unicast, multicast and flood bytes of the interval
+func parsePortStatsInterval(attributes map[string]interface{}) (int, capmodel.PortStatsSample, uint64, error) {
+	var interval capmodel.PortStatsSample
+	indexValue, err := AttributeString(attributes, "index")
+	if err != nil {
+		return 0, interval, 0, err
+	}
+	index, err := strconv.Atoi(indexValue)
+	if err != nil || index < 0 {
+		return 0, interval, 0, fmt.Errorf("%w: index %s is not a number", ErrAPICResponseMalformed, indexValue)
+	}
+	if interval.IntervalStart, err = AttributeString(attributes, "repIntvStart"); err != nil {
+		return 0, interval, 0, err
+	}
+	if interval.IntervalEnd, err = AttributeString(attributes, "repIntvEnd"); err != nil {
+		return 0, interval, 0, err
+	}
+	bytes, err := portBytes(attributes)
+	if err != nil {
+		return 0, interval, 0, err
+	}
+	return index, interval, bytes, nil
+}
+
+// portBytes returns the bytes of a byte counter managed object, the sum of its unicast, multicast and flood bytes
+func portBytes(attributes map[string]interface{}) (uint64, error) {
+	var bytes uint64
+	for _, name := range []string{"unicastPer", "multicastPer", "floodPer"} {
+		count, err := attributeUint(attributes, name)
+		if err != nil {
+			return 0, err
+		}
+		bytes += count
+	}
+	return bytes, nil
+}
+
+// attributeUint returns the counter attribute of a managed object, an error is returned when
+// the attribute is absent or is not a number
+func attributeUint(attributes map[string]interface{}, name string) (uint64, error) {
+	value, err := AttributeString(attributes, name)
+	if err != nil {
+		return 0, err
+	}
+	count, err := strconv.ParseUint(value, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("%w: %s value %s is not a number", ErrAPICResponseMalformed, name, value)
+	}
+	return count, nil
+}
+
+// ParsePortNeighbors decodes the lldpAdjEp and cdpAdjEp managed objects of the neighbors of the port,
+// no neighbor is returned when none is discovered
+func ParsePortNeighbors(body []byte) ([]capmodel.PortNeighbor, error) {
+	var response capmodel.PortNeighborsResponse
+	if err := parseAPICResponse(body, &response); err != nil {
+		return nil, err
+	}
+	var neighbors []capmodel.PortNeighbor
+	for _, imdata := range response.IMData {
+		var neighbor capmodel.PortNeighbor
+		var attributes map[string]interface{}
+		var chassisID, portID string
+		switch {
+		case imdata.LLDPAdjacency != nil:
+			neighbor.Protocol = "LLDP"
+			attributes, chassisID, portID = imdata.LLDPAdjacency.Attributes, "chassisIdV", "portIdV"
+		case imdata.CDPAdjacency != nil:
+			neighbor.Protocol = "CDP"
+			attributes, chassisID, portID = imdata.CDPAdjacency.Attributes, "devId", "portId"
+		default:
+			return nil, fmt.Errorf("%w: neighbor of unknown class", ErrAPICResponseMalformed)
+		}
+		var err error
+		if neighbor.ChassisID, err = AttributeString(attributes, chassisID); err != nil {
+			return nil, err
+		}
+		if neighbor.PortID, err = AttributeString(attributes, portID); err != nil {
+			return nil, err
+		}
+		// the neighbors not advertising their system name are reported with their chassis id only
+		neighbor.SystemName, _ = attributes["sysName"].(string)
+		neighbors = append(neighbors, neighbor)
+	}
+	return neighbors, nil
+}
+
+// ParsePortTransceiver decodes the ethpmFcot managed object of the transceiver slot of the port,
+// nil is returned when no transceiver is plugged in the slot or the port has no transceiver slot
+func ParsePortTransceiver(body []byte) (*capmodel.PortTransceiver, error) {
+	var response capmodel.PortTransceiverResponse
+	if err := parseAPICResponse(body, &response); err != nil {
+		return nil, err
+	}
+	if len(response.IMData) == 0 {
+		return nil, nil
+	}
+	attributes := response.IMData[0].Transceiver.Attributes
+	if present, _ := attributes["isFcotPresent"].(string); present != "true" {
+		return nil, nil
+	}
+	var transceiver capmodel.PortTransceiver
+	// the attributes are read in a fixed order, so the same attribute is reported when several are missing
+	for _, attribute := range []struct {
+		name  string
+		value *string
+	}{
+		{"guiName", &transceiver.Vendor},
+		{"guiPN", &transceiver.PartNumber},
+		{"guiSN", &transceiver.SerialNumber},
+		{"typeName", &transceiver.Type},
+	} {
+		value, err := AttributeString(attributes, attribute.name)
+		if err != nil {
+			return nil, err
+		}
+		// APIC pads the values read from the transceiver EEPROM with spaces
+		*attribute.value = strings.TrimSpace(value)
+	}
+	if wavelength, ok := attributes["wavelength"].(string); ok && wavelength != "" {
+		value, err := strconv.ParseFloat(wavelength, 64)
+		if err != nil || value < 0 {
+			return nil, fmt.Errorf("%w: wavelength %s is not a number", ErrAPICResponseMalformed, wavelength)
+		}
+		transceiver.WavelengthNanometers = value
+	}
+	return &transceiver, nil
+}
+
+// ParseFabricHealth decodes the fabricHealthTotal managed object, the response has at least one with attributes
+func ParseFabricHealth(body []byte) (*capmodel.FabricHealth, error) {
+	var health capmodel.FabricHealth
+	if err := parseAPICResponse(body, &health); err != nil {
+		return nil, err
+	}
+	if len(health.IMData) == 0 || health.IMData[0].FabricHealthData.Attributes == nil {
+		return nil, fmt.Errorf("%w: no fabricHealthTotal in response", ErrAPICResponseMalformed)
+	}
+	return &health, nil
+}
+
+// AttributeString returns the string attribute of a managed object, an error is returned
+// when the attribute is absent or is not a string
+func AttributeString(attributes map[string]interface{}, name string) (string, error) {
+	value, ok := attributes[name].(string)
+	if !ok {
+		return "", fmt.Errorf("%w: attribute %s is missing or not a string", ErrAPICResponseMalformed, name)
+	}
+	return value, nil
+}
+
+// HealthScore returns the current health score of the health attributes
+func HealthScore(attributes map[string]interface{}) (int, error) {
+	currentHealthValue, err := AttributeString(attributes, "cur")
+	if err != nil {
+		return 0, err
+	}
+	healthValue, err := strconv.Atoi(currentHealthValue)
+	if err != nil {
+		return 0, fmt.Errorf("%w: current health value %s is not a number", ErrAPICResponseMalformed, currentHealthValue)
+	}
+	return healthValue, nil
+}
@@ -0,0 +1,388 @@
+//(C) Copyright [2020] Hewlett Packard Enterprise Development LP
+//
+//Licensed under the Apache License, Version 2.0 (the "License"); you may
+//not use this file except in compliance with the License. You may obtain
+//a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+//Unless required by applicable law or agreed to in writing, software
+//distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+//WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+//License for the specific language governing permissions and limitations
+// under the License.
+
+package caputilities
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/ODIM-Project/PluginCiscoACI/capmodel"
+)
+
+// apicResponseSeeds are the response bodies seeding the fuzz tests, including the
+// error object APIC returns in the imdata of a successful response
+var apicResponseSeeds = []string{
+	`{"totalCount":"1","imdata":[{"ethpmPhysIf":{"attributes":{"operSt":"up","operSpeed":"10G"}}}]}`,
+	`{"totalCount":"1","imdata":[{"healthInst":{"attributes":{"cur":"100"}}}]}`,
+	`{"totalCount":"1","imdata":[{"fabricHealthTotal":{"attributes":{"cur":"95"}}}]}`,
+	`{"totalCount":"1","imdata":[{"l1PhysIf":{"attributes":{"id":"eth1/1","mtu":"9000"}}}]}`,
+	`{"totalCount":"1","imdata":[{"error":{"attributes":{"code":"400","text":"Request failed, unresolved class"}}}]}`,
+	`{"totalCount":"0","imdata":[]}`,
+	`{"imdata":{}}`,
+	`{"imdata":[{"healthInst":{"attributes":{"cur":100}}}]}`,
+	`[]`,
+	`null`,
+	``,
+}
+
+func TestParseAPICResponses(t *testing.T) {
+	tests := []struct {
+		name    string
+		body    string
+		parse   func([]byte) error
+		wantErr bool
+	}{
+		{"port info", apicResponseSeeds[0], parsePortInfoErr, false},
+		{"port info with APIC error", apicResponseSeeds[4], parsePortInfoErr, true},
+		{"port info without managed objects", apicResponseSeeds[5], parsePortInfoErr, true},
+		{"health", apicResponseSeeds[1], parseHealthErr, false},
+		{"health with APIC error", apicResponseSeeds[4], parseHealthErr, true},
+		{"health with imdata object", apicResponseSeeds[6], parseHealthErr, true},
+		{"fabric health", apicResponseSeeds[2], parseFabricHealthErr, false},
+		{"fabric health of other class", apicResponseSeeds[1], parseFabricHealthErr, true},
+		{"port collection", apicResponseSeeds[3], parsePortCollectionErr, false},
+		{"empty port collection", apicResponseSeeds[5], parsePortCollectionErr, false},
+		{"port collection with APIC error", apicResponseSeeds[4], parsePortCollectionErr, true},
+		{"not JSON", "<html></html>", parsePortCollectionErr, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.parse([]byte(tt.body)); (err != nil) != tt.wantErr {
+				t.Errorf("parse() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestHealthScore(t *testing.T) {
+	if score, err := HealthScore(map[string]interface{}{"cur": "87"}); err != nil || score != 87 {
+		t.Errorf("HealthScore() = %d, %v, want 87", score, err)
+	}
+	for _, attributes := range []map[string]interface{}{nil, {"cur": 87.0}, {"cur": "high"}} {
+		if _, err := HealthScore(attributes); !errors.Is(err, ErrAPICResponseMalformed) {
+			t.Errorf("HealthScore(%v) error = %v, want ErrAPICResponseMalformed", attributes, err)
+		}
+	}
+}
+
+func TestParseSwitchPortsHealth(t *testing.T) {
+	body := []byte(`{"totalCount":"4","imdata":[
+		{"healthInst":{"attributes":{"dn":"topology/pod-1/node-101/sys/phys-[eth1/1]/phys/health","cur":"100"}}},
+		{"healthInst":{"attributes":{"dn":"topology/pod-1/node-101/sys/phys-[eth1/2]/phys/health","cur":"85"}}},
+		{"healthInst":{"attributes":{"dn":"topology/pod-1/node-101/sys/phys-[eth1/49/1]/phys/health","cur":"20"}}},
+		{"healthInst":{"attributes":{"dn":"topology/pod-1/node-101/sys/health","cur":"95"}}}]}`)
+	portsHealth, err := ParseSwitchPortsHealth(body)
+	if err != nil {
+		t.Fatalf("ParseSwitchPortsHealth() error = %v", err)
+	}
+	want := map[string]int{"eth1/1": 100, "eth1/2": 85, "eth1/49/1": 20}
+	if len(portsHealth) != len(want) {
+		t.Errorf("ParseSwitchPortsHealth() = %v, want the health of %d ports", portsHealth, len(want))
+	}
+	for portID, wantScore := range want {
+		if score, err := HealthScore(portsHealth[portID].Attributes); err != nil || score != wantScore {
+			t.Errorf("health of %s = %d, %v, want %d", portID, score, err, wantScore)
+		}
+	}
+	if _, err := ParseSwitchPortsHealth([]byte(apicResponseSeeds[4])); err == nil {
+		t.Error("ParseSwitchPortsHealth() of APIC error, want error")
+	}
+}
+
+func TestParsePortFaults(t *testing.T) {
+	body := []byte(`{"totalCount":"6","imdata":[
+		{"faultInst":{"attributes":{"dn":"topology/pod-1/node-101/sys/phys-[eth1/1]/phys/fault-F1678","code":"F1678","severity":"minor","descr":"Port is down","created":"2026-10-16T10:00:00.000+00:00"}}},
+		{"faultInst":{"attributes":{"dn":"topology/pod-1/node-101/sys/phys-[eth1/1]/fault-F0532","code":"F0532","severity":"critical","descr":"Port is down, reason: sfpAbsent"}}},
+		{"faultInst":{"attributes":{"dn":"topology/pod-1/node-101/sys/phys-[eth1/2]/fault-F0532","code":"F0532","severity":"cleared"}}},
+		{"faultInst":{"attributes":{"dn":"topology/pod-1/node-102/sys/phys-[eth1/49/1]/phys/fault-F1678","code":"F1678","severity":"major"}}},
+		{"faultInst":{"attributes":{"dn":"topology/pod-1/node-101/sys/ch/psuslot-1/psu/fault-F1451","code":"F1451","severity":"critical"}}}]}`)
+	faults, err := ParsePortFaults(body)
+	if err != nil {
+		t.Fatalf("ParsePortFaults() error = %v", err)
+	}
+	want := map[string]map[string]string{
+		"101": {"eth1/1": "F0532/critical"},
+		"102": {"eth1/49/1": "F1678/major"},
+	}
+	if len(faults) != len(want) {
+		t.Errorf("ParsePortFaults() = %v, want the faults of %d switches", faults, len(want))
+	}
+	for nodeID, ports := range want {
+		if len(faults[nodeID]) != len(ports) {
+			t.Errorf("faults of node %s = %v, want %d ports", nodeID, faults[nodeID], len(ports))
+		}
+		for portID, wantFault := range ports {
+			if fault := faults[nodeID][portID]; fault.Code+"/"+fault.Severity != wantFault {
+				t.Errorf("worst fault of %s/%s = %s/%s, want %s", nodeID, portID, fault.Code, fault.Severity, wantFault)
+			}
+		}
+	}
+	if faults["101"]["eth1/1"].Description != "Port is down, reason: sfpAbsent" {
+		t.Errorf("description of the worst fault = %q", faults["101"]["eth1/1"].Description)
+	}
+
+	unknownSeverity := []byte(`{"imdata":[{"faultInst":{"attributes":{"dn":"topology/pod-1/node-101/sys/phys-[eth1/1]/fault-F0532","code":"F0532","severity":"fatal"}}}]}`)
+	if _, err := ParsePortFaults(unknownSeverity); !errors.Is(err, ErrAPICResponseMalformed) {
+		t.Errorf("ParsePortFaults() with unknown severity error = %v, want ErrAPICResponseMalformed", err)
+	}
+	if _, err := ParsePortFaults([]byte(apicResponseSeeds[4])); err == nil {
+		t.Error("ParsePortFaults() of APIC error, want error")
+	}
+}
+
+func TestFaultSeverityRank(t *testing.T) {
+	for i := 1; i < len(FaultSeverities); i++ {
+		if FaultSeverityRank(FaultSeverities[i]) <= FaultSeverityRank(FaultSeverities[i-1]) {
+			t.Errorf("severity %s is not ranked above %s", FaultSeverities[i], FaultSeverities[i-1])
+		}
+	}
+	if FaultSeverityRank("critical") <= FaultSeverityRank("major") || FaultSeverityRank("Major") != -1 {
+		t.Error("FaultSeverityRank() doesn't follow the APIC severity ordering")
+	}
+}
+
+func TestParsePortStatsHistory(t *testing.T) {
+	body := []byte(`{"totalCount":"4","imdata":[
+		{"eqptIngrBytesHist5min":{"attributes":{"index":"0","repIntvStart":"2026-10-16T10:05:00.000+00:00","repIntvEnd":"2026-10-16T10:10:00.000+00:00","unicastPer":"1000","multicastPer":"20","floodPer":"3"}}},
+		{"eqptEgrBytesHist5min":{"attributes":{"index":"0","repIntvStart":"2026-10-16T10:05:00.000+00:00","repIntvEnd":"2026-10-16T10:10:00.000+00:00","unicastPer":"500","multicastPer":"0","floodPer":"0"}}},
+		{"eqptIngrBytesHist5min":{"attributes":{"index":"1","repIntvStart":"2026-10-16T10:00:00.000+00:00","repIntvEnd":"2026-10-16T10:05:00.000+00:00","unicastPer":"10","multicastPer":"0","floodPer":"0"}}},
+		{"eqptIngrBytesHist1h":{"attributes":{"index":"0","repIntvStart":"2026-10-16T09:00:00.000+00:00","repIntvEnd":"2026-10-16T10:00:00.000+00:00","unicastPer":"1","multicastPer":"1","floodPer":"1"}}}]}`)
+	samples, err := ParsePortStatsHistory(body, "5min")
+	if err != nil {
+		t.Fatalf("ParsePortStatsHistory() error = %v", err)
+	}
+	want := []capmodel.PortStatsSample{
+		{IntervalStart: "2026-10-16T10:00:00.000+00:00", IntervalEnd: "2026-10-16T10:05:00.000+00:00", RXBytes: 10},
+		{IntervalStart: "2026-10-16T10:05:00.000+00:00", IntervalEnd: "2026-10-16T10:10:00.000+00:00", RXBytes: 1023, TXBytes: 500},
+	}
+	if !reflect.DeepEqual(samples, want) {
+		t.Errorf("ParsePortStatsHistory() = %+v, want %+v", samples, want)
+	}
+	malformed := []byte(`{"imdata":[{"eqptIngrBytesHist5min":{"attributes":{"index":"0","unicastPer":"many"}}}]}`)
+	if _, err := ParsePortStatsHistory(malformed, "5min"); !errors.Is(err, ErrAPICResponseMalformed) {
+		t.Errorf("ParsePortStatsHistory() of malformed history error = %v, want ErrAPICResponseMalformed", err)
+	}
+	if _, err := ParsePortStatsHistory([]byte(apicResponseSeeds[4]), "5min"); err == nil {
+		t.Error("ParsePortStatsHistory() of APIC error, want error")
+	}
+}
+
+func TestParsePortCounters(t *testing.T) {
+	body := []byte(`{"totalCount":"6","imdata":[
+		{"eqptIngrBytes5min":{"attributes":{"dn":"topology/pod-1/node-101/sys/phys-[eth1/1]/CDeqptIngrBytes5min","unicastPer":"1000","multicastPer":"20","floodPer":"3","repIntvEnd":"2026-10-16T10:05:00.000+00:00"}}},
+		{"eqptEgrBytes5min":{"attributes":{"dn":"topology/pod-1/node-101/sys/phys-[eth1/1]/CDeqptEgrBytes5min","unicastPer":"500","multicastPer":"0","floodPer":"0","repIntvEnd":"2026-10-16T10:05:00.000+00:00"}}},
+		{"eqptIngrDropPkts5min":{"attributes":{"dn":"topology/pod-1/node-101/sys/phys-[eth1/1]/CDeqptIngrDropPkts5min","errorPer":"7","repIntvEnd":"2026-10-16T10:05:00.000+00:00"}}},
+		{"eqptEgrDropPkts5min":{"attributes":{"dn":"topology/pod-1/node-101/sys/phys-[eth1/1]/CDeqptEgrDropPkts5min","errorPer":"2","repIntvEnd":"2026-10-16T10:05:00.000+00:00"}}},
+		{"eqptIngrBytes5min":{"attributes":{"dn":"topology/pod-1/node-102/sys/phys-[eth1/49/1]/CDeqptIngrBytes5min","unicastPer":"42","multicastPer":"0","floodPer":"0"}}},
+		{"eqptIngrBytes5min":{"attributes":{"dn":"topology/pod-1/node-101/sys/cpu/CDeqptIngrBytes5min","unicastPer":"1","multicastPer":"0","floodPer":"0"}}}]}`)
+	counters, err := ParsePortCounters(body)
+	if err != nil {
+		t.Fatalf("ParsePortCounters() error = %v", err)
+	}
+	want := map[string]map[string]capmodel.PortCounters{
+		"101": {"eth1/1": {IntervalEnd: "2026-10-16T10:05:00.000+00:00", RXBytes: 1023, TXBytes: 500, RXErrors: 7, TXErrors: 2}},
+		"102": {"eth1/49/1": {RXBytes: 42}},
+	}
+	if !reflect.DeepEqual(counters, want) {
+		t.Errorf("ParsePortCounters() = %+v, want %+v", counters, want)
+	}
+
+	malformed := []string{
+		`{"imdata":[{"eqptIngrDropPkts5min":{"attributes":{"dn":"topology/pod-1/node-101/sys/phys-[eth1/1]/CDeqptIngrDropPkts5min"}}}]}`,
+		`{"imdata":[{"eqptEgrBytes5min":{"attributes":{"dn":"topology/pod-1/node-101/sys/phys-[eth1/1]/CDeqptEgrBytes5min","unicastPer":"-1","multicastPer":"0","floodPer":"0"}}}]}`,
+	}
+	for _, body := range malformed {
+		if _, err := ParsePortCounters([]byte(body)); !errors.Is(err, ErrAPICResponseMalformed) {
+			t.Errorf("ParsePortCounters(%s) error = %v, want ErrAPICResponseMalformed", body, err)
+		}
+	}
+	if _, err := ParsePortCounters([]byte(apicResponseSeeds[4])); err == nil {
+		t.Error("ParsePortCounters() of APIC error, want error")
+	}
+}
+
+func TestParsePortTransceiver(t *testing.T) {
+	body := []byte(`{"totalCount":"1","imdata":[{"ethpmFcot":{"attributes":{
+		"dn":"topology/pod-1/node-101/sys/phys-[eth1/1]/phys/fcot","isFcotPresent":"true","state":"inserted",
+		"guiName":"CISCO-FINISAR   ","guiPN":"FTLX8574D3BCL-C2","guiSN":"FNS17251ABC","typeName":"10Gbase-SR","wavelength":"850"}}}]}`)
+	transceiver, err := ParsePortTransceiver(body)
+	if err != nil {
+		t.Fatalf("ParsePortTransceiver() error = %v", err)
+	}
+	want := &capmodel.PortTransceiver{Vendor: "CISCO-FINISAR", PartNumber: "FTLX8574D3BCL-C2", SerialNumber: "FNS17251ABC",
+		Type: "10Gbase-SR", WavelengthNanometers: 850}
+	if !reflect.DeepEqual(transceiver, want) {
+		t.Errorf("ParsePortTransceiver() = %+v, want %+v", transceiver, want)
+	}
+
+	// empty slots and ports without transceiver slot
+	for _, body := range []string{
+		`{"totalCount":"1","imdata":[{"ethpmFcot":{"attributes":{"isFcotPresent":"false","state":"unknown","guiName":"","guiPN":"","guiSN":""}}}]}`,
+		apicResponseSeeds[5],
+	} {
+		if transceiver, err := ParsePortTransceiver([]byte(body)); err != nil || transceiver != nil {
+			t.Errorf("ParsePortTransceiver(%s) = %+v, %v, want nil", body, transceiver, err)
+		}
+	}
+
+	malformed := []string{
+		`{"imdata":[{"ethpmFcot":{"attributes":{"isFcotPresent":"true","guiName":"CISCO"}}}]}`,
+		`{"imdata":[{"ethpmFcot":{"attributes":{"isFcotPresent":"true","guiName":"CISCO","guiPN":"PN","guiSN":"SN","typeName":"10Gbase-SR","wavelength":"short"}}}]}`,
+	}
+	for _, body := range malformed {
+		if _, err := ParsePortTransceiver([]byte(body)); !errors.Is(err, ErrAPICResponseMalformed) {
+			t.Errorf("ParsePortTransceiver(%s) error = %v, want ErrAPICResponseMalformed", body, err)
+		}
+	}
+	if _, err := ParsePortTransceiver([]byte(apicResponseSeeds[4])); err == nil {
+		t.Error("ParsePortTransceiver() of APIC error, want error")
+	}
+
+	// the first missing attribute is reported whatever else is missing
+	for i := 0; i < 10; i++ {
+		_, err := ParsePortTransceiver([]byte(`{"imdata":[{"ethpmFcot":{"attributes":{"isFcotPresent":"true","guiSN":"SN"}}}]}`))
+		if err == nil || !strings.Contains(err.Error(), "guiName") {
+			t.Fatalf("ParsePortTransceiver() error = %v, want the missing guiName", err)
+		}
+	}
+}
+
+func FuzzParsePortInfo(f *testing.F) {
+	for _, seed := range apicResponseSeeds {
+		f.Add([]byte(seed))
+	}
+	f.Fuzz(func(t *testing.T, body []byte) {
+		portInfo, err := ParsePortInfo(body)
+		if err != nil {
+			return
+		}
+		// the attributes are read by the port handler, they must be present once parsed
+		attributes := portInfo.IMData[0].PhysicalInterface.Attributes
+		if attributes == nil {
+			t.Fatalf("ParsePortInfo(%q) returned port info without attributes", body)
+		}
+		AttributeString(attributes, "operSt")
+	})
+}
+
+func FuzzParseHealth(f *testing.F) {
+	for _, seed := range apicResponseSeeds {
+		f.Add([]byte(seed))
+	}
+	f.Fuzz(func(t *testing.T, body []byte) {
+		health, err := ParseHealth(body)
+		if err != nil {
+			return
+		}
+		if health.IMData[0].HealthData.Attributes == nil {
+			t.Fatalf("ParseHealth(%q) returned health without attributes", body)
+		}
+		HealthScore(health.IMData[0].HealthData.Attributes)
+	})
+}
+
+func FuzzParseFabricHealth(f *testing.F) {
+	for _, seed := range apicResponseSeeds {
+		f.Add([]byte(seed))
+	}
+	f.Fuzz(func(t *testing.T, body []byte) {
+		health, err := ParseFabricHealth(body)
+		if err != nil {
+			return
+		}
+		HealthScore(health.IMData[0].FabricHealthData.Attributes)
+	})
+}
+
+func FuzzParsePortCollection(f *testing.F) {
+	for _, seed := range apicResponseSeeds {
+		f.Add([]byte(seed))
+	}
+	f.Fuzz(func(t *testing.T, body []byte) {
+		portCollection, err := ParsePortCollection(body)
+		if err != nil {
+			return
+		}
+		for _, imdata := range portCollection.IMData {
+			if imdata.PhysicalInterface.Attributes == nil {
+				t.Fatalf("ParsePortCollection(%q) returned port without attributes", body)
+			}
+			AttributeString(imdata.PhysicalInterface.Attributes, "id")
+		}
+	})
+}
+
+func parsePortInfoErr(body []byte) error {
+	_, err := ParsePortInfo(body)
+	return err
+}
+
+func parseHealthErr(body []byte) error {
+	_, err := ParseHealth(body)
+	return err
+}
+
+func parseFabricHealthErr(body []byte) error {
+	_, err := ParseFabricHealth(body)
+	return err
+}
+
+func parsePortCollectionErr(body []byte) error {
+	_, err := ParsePortCollection(body)
+	return err
+}
+
+func TestParsePortNeighbors(t *testing.T) {
+	body := []byte(`{"totalCount":"1","imdata":[{"lldpAdjEp":{"attributes":{
+		"dn":"topology/pod-1/node-101/sys/lldp/inst/if-[eth1/1]/adj-1","chassisIdT":"mac","chassisIdV":"00:50:56:aa:bb:cc",
+		"portIdT":"if-name","portIdV":"ens192","sysName":"server-1","mgmtIp":"192.0.2.10"}}}]}`)
+	neighbors, err := ParsePortNeighbors(body)
+	if err != nil {
+		t.Fatalf("ParsePortNeighbors() error = %v", err)
+	}
+	want := []capmodel.PortNeighbor{{Protocol: "LLDP", ChassisID: "00:50:56:aa:bb:cc", PortID: "ens192", SystemName: "server-1"}}
+	if !reflect.DeepEqual(neighbors, want) {
+		t.Errorf("ParsePortNeighbors() = %+v, want %+v", neighbors, want)
+	}
+
+	body = []byte(`{"totalCount":"1","imdata":[{"cdpAdjEp":{"attributes":{
+		"dn":"topology/pod-1/node-101/sys/cdp/inst/if-[eth1/2]/adj-1","devId":"switch-2(FDO12345ABC)","portId":"Ethernet1/49","platId":"N9K-C93180YC-EX"}}}]}`)
+	neighbors, err = ParsePortNeighbors(body)
+	want = []capmodel.PortNeighbor{{Protocol: "CDP", ChassisID: "switch-2(FDO12345ABC)", PortID: "Ethernet1/49"}}
+	if err != nil || !reflect.DeepEqual(neighbors, want) {
+		t.Errorf("ParsePortNeighbors() of CDP adjacency = %+v, %v, want %+v", neighbors, err, want)
+	}
+
+	// no neighbor discovered
+	if neighbors, err := ParsePortNeighbors([]byte(apicResponseSeeds[5])); err != nil || neighbors != nil {
+		t.Errorf("ParsePortNeighbors() without adjacency = %+v, %v, want no neighbor", neighbors, err)
+	}
+
+	malformed := []string{
+		`{"imdata":[{"lldpAdjEp":{"attributes":{"portIdV":"ens192"}}}]}`,
+		`{"imdata":[{"cdpAdjEp":{"attributes":{"devId":"switch-2"}}}]}`,
+		`{"imdata":[{"lldpIf":{"attributes":{"id":"eth1/1"}}}]}`,
+	}
+	for _, body := range malformed {
+		if _, err := ParsePortNeighbors([]byte(body)); !errors.Is(err, ErrAPICResponseMalformed) {
+			t.Errorf("ParsePortNeighbors(%s) error = %v, want ErrAPICResponseMalformed", body, err)
+		}
+	}
+	if _, err := ParsePortNeighbors([]byte(apicResponseSeeds[4])); err == nil {
+		t.Error("ParsePortNeighbors() of APIC error, want error")
+	}
+}
@@ -0,0 +1,163 @@
+//(C) Copyright [2020] Hewlett Packard Enterprise Development LP
+//
+//Licensed under the Apache License, Version 2.0 (the "License"); you may
+//not use this file except in compliance with the License. You may obtain
+//a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+//Unless required by applicable law or agreed to in writing, software
+//distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+//WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+//License for the specific language governing permissions and limitations
+// under the License.
+
+package caputilities
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/ODIM-Project/PluginCiscoACI/config"
+)
+
+// ErrAPICRateLimited is returned when the rate limit of the plugin doesn't allow
+// a request to APIC within the configured wait
+var ErrAPICRateLimited = errors.New("rate limit of the plugin on the APIC requests reached")
+
+// ErrAPICThrottled is returned when APIC rejects a request with 429 Too Many Requests
+var ErrAPICThrottled = errors.New("APIC is throttling the requests of the plugin")
+
+// defaultAPICRetryAfter is the retry delay assumed when APIC throttles a request without Retry-After
+const defaultAPICRetryAfter = time.Second
+
+// APICThrottleError is returned when a request to APIC is held back by the rate limit of the
+// plugin or rejected by APIC for its own. RetryAfter is the estimated time after which the
+// request can be made. It wraps ErrAPICRateLimited or ErrAPICThrottled to tell them apart.
+type APICThrottleError struct {
+	Err        error
+	Endpoint   string
+	RetryAfter time.Duration
+}
+
+func (e *APICThrottleError) Error() string {
+	return fmt.Sprintf("request on the URL %s is not served: %v, retry after %s", e.Endpoint, e.Err, e.RetryAfter)
+}
+
+func (e *APICThrottleError) Unwrap() error {
+	return e.Err
+}
+
+// RateLimiter is a token bucket limiting the rate of the requests, the tokens are
+// refilled at the rate up to the burst and each request takes one of them
+type RateLimiter struct {
+	rate   float64
+	burst  float64
+	lock   sync.Mutex
+	tokens float64
+	last   time.Time
+	now    func() time.Time
+	sleep  func(time.Duration)
+}
+
+// NewRateLimiter returns the limiter allowing the given requests per second with the given burst
+func NewRateLimiter(requestsPerSecond float64, burst int) *RateLimiter {
+	return &RateLimiter{
+		rate:   requestsPerSecond,
+		burst:  float64(burst),
+		tokens: float64(burst),
+		last:   time.Now(),
+		now:    time.Now,
+		sleep:  time.Sleep,
+	}
+}
+
+// reserve takes a token for a request and returns the time to wait before making it. When the
+// wait is longer than maxWait no token is taken and false is returned with the wait estimated.
+func (l *RateLimiter) reserve(maxWait time.Duration) (time.Duration, bool) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	now := l.now()
+	l.tokens = math.Min(l.burst, l.tokens+now.Sub(l.last).Seconds()*l.rate)
+	l.last = now
+	var wait time.Duration
+	if l.tokens < 1 {
+		wait = time.Duration((1 - l.tokens) / l.rate * float64(time.Second))
+	}
+	if wait > maxWait {
+		return wait, false
+	}
+	// the token can be taken before it is refilled, the requests made after wait longer
+	l.tokens--
+	return wait, true
+}
+
+// Wait blocks until the request on the endpoint can be made. APICThrottleError wrapping
+// ErrAPICRateLimited is returned without waiting when it can't be made within maxWait.
+func (l *RateLimiter) Wait(endpoint string, maxWait time.Duration) error {
+	wait, ok := l.reserve(maxWait)
+	if !ok {
+		return &APICThrottleError{Err: ErrAPICRateLimited, Endpoint: endpoint, RetryAfter: wait}
+	}
+	if wait > 0 {
+		l.sleep(wait)
+	}
+	return nil
+}
+
+var (
+	apicRateLimiter     *RateLimiter
+	apicRateLimiterLock sync.Mutex
+)
+
+// waitAPICRateLimit waits for the rate limit of the plugin before a request on the endpoint,
+// the requests are not limited when APIC RequestsPerSecond is not configured
+func waitAPICRateLimit(endpoint string) error {
+	if config.Data.APICConf.RequestsPerSecond <= 0 {
+		return nil
+	}
+	apicRateLimiterLock.Lock()
+	if apicRateLimiter == nil {
+		apicRateLimiter = NewRateLimiter(config.Data.APICConf.RequestsPerSecond, config.Data.APICConf.RequestBurst)
+	}
+	limiter := apicRateLimiter
+	apicRateLimiterLock.Unlock()
+	return limiter.Wait(endpoint, time.Duration(config.Data.APICConf.RateLimitWaitInMilliseconds)*time.Millisecond)
+}
+
+// checkAPICThrottle returns APICThrottleError wrapping ErrAPICThrottled when APIC rejected
+// the request with 429 Too Many Requests, the Retry-After of APIC is used when present
+func checkAPICThrottle(endpoint string, resp *http.Response) error {
+	if resp.StatusCode != http.StatusTooManyRequests {
+		return nil
+	}
+	return &APICThrottleError{Err: ErrAPICThrottled, Endpoint: endpoint, RetryAfter: parseRetryAfter(resp.Header.Get("Retry-After"))}
+}
+
+// parseRetryAfter reads the Retry-After header given either in seconds or as HTTP date
+func parseRetryAfter(value string) time.Duration {
+	if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second
+	}
+	if date, err := http.ParseTime(value); err == nil {
+		if wait := time.Until(date); wait > 0 {
+			return wait
+		}
+	}
+	return defaultAPICRetryAfter
+}
+
+// RetryAfterSeconds formats the wait as the value of the Retry-After header,
+// rounded up to whole seconds so that the retry is not made early
+func RetryAfterSeconds(wait time.Duration) string {
+	seconds := int(math.Ceil(wait.Seconds()))
+	if seconds < 1 {
+		seconds = 1
+	}
+	return strconv.Itoa(seconds)
+}
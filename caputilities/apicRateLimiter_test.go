@@ -0,0 +1,90 @@
+//(C) Copyright [2020] Hewlett Packard Enterprise Development LP
+//
+//Licensed under the Apache License, Version 2.0 (the "License"); you may
+//not use this file except in compliance with the License. You may obtain
+//a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+//Unless required by applicable law or agreed to in writing, software
+//distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+//WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+//License for the specific language governing permissions and limitations
+// under the License.
+
+package caputilities
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/ODIM-Project/PluginCiscoACI/config"
+)
+
+func TestRateLimiterWait(t *testing.T) {
+	clock := time.Now()
+	var slept time.Duration
+	limiter := NewRateLimiter(1, 1)
+	limiter.last = clock
+	limiter.now = func() time.Time { return clock }
+	limiter.sleep = func(wait time.Duration) { slept += wait }
+
+	if err := limiter.Wait("first", time.Second); err != nil || slept != 0 {
+		t.Fatalf("Wait() within burst = %v after sleeping %s, want no error without sleeping", err, slept)
+	}
+	err := limiter.Wait("second", 500*time.Millisecond)
+	var throttleErr *APICThrottleError
+	if !errors.Is(err, ErrAPICRateLimited) || !errors.As(err, &throttleErr) || throttleErr.RetryAfter != time.Second {
+		t.Fatalf("Wait() above rate = %v, want ErrAPICRateLimited with RetryAfter 1s", err)
+	}
+	if err := limiter.Wait("third", 2*time.Second); err != nil || slept != time.Second {
+		t.Errorf("Wait() within max wait = %v after sleeping %s, want no error after sleeping 1s", err, slept)
+	}
+}
+
+func TestGetAPICDataThrottled(t *testing.T) {
+	config.SetUpMockConfig(t)
+	defer func() {
+		config.Data.APICConf.RequestsPerSecond = 0
+		apicRateLimiter = nil
+	}()
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.Header().Set("Retry-After", "7")
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer server.Close()
+
+	// throttled by APIC
+	_, err := getAPICDataWithToken(server.URL, "token")
+	var throttleErr *APICThrottleError
+	if !errors.Is(err, ErrAPICThrottled) || !errors.As(err, &throttleErr) || throttleErr.RetryAfter != 7*time.Second {
+		t.Fatalf("getAPICDataWithToken() = %v, want ErrAPICThrottled with RetryAfter 7s", err)
+	}
+
+	// throttled by the rate limit of the plugin, the request is not sent to APIC
+	config.Data.APICConf.RequestsPerSecond = 0.1
+	config.Data.APICConf.RequestBurst = 1
+	config.Data.APICConf.RateLimitWaitInMilliseconds = 100
+	apicRateLimiter = nil
+	getAPICDataWithToken(server.URL, "token")
+	_, err = getAPICDataWithToken(server.URL, "token")
+	if !errors.Is(err, ErrAPICRateLimited) || !errors.As(err, &throttleErr) || throttleErr.RetryAfter < 9*time.Second {
+		t.Errorf("getAPICDataWithToken() = %v, want ErrAPICRateLimited with RetryAfter about 10s", err)
+	}
+	if requests != 2 {
+		t.Errorf("APIC received %d requests, want 2", requests)
+	}
+}
+
+func TestRetryAfterSeconds(t *testing.T) {
+	for wait, want := range map[time.Duration]string{0: "1", 1500 * time.Millisecond: "2", 7 * time.Second: "7"} {
+		if got := RetryAfterSeconds(wait); got != want {
+			t.Errorf("RetryAfterSeconds(%s) = %s, want %s", wait, got, want)
+		}
+	}
+}
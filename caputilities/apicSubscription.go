@@ -0,0 +1,265 @@
+//(C) Copyright [2020] Hewlett Packard Enterprise Development LP
+//
+//Licensed under the Apache License, Version 2.0 (the "License"); you may
+//not use this file except in compliance with the License. You may obtain
+//a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+//Unless required by applicable law or agreed to in writing, software
+//distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+//WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+//License for the specific language governing permissions and limitations
+// under the License.
+
+package caputilities
+
+import (
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/ODIM-Project/PluginCiscoACI/config"
+	"github.com/gorilla/websocket"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	minAPICReconnectDelay = time.Second
+	maxAPICReconnectDelay = time.Minute
+)
+
+// APICNotification is the change of a managed object pushed by APIC for a subscription
+type APICNotification struct {
+	Class      string
+	Attributes map[string]interface{}
+}
+
+// apicManagedObject holds the attributes of a managed object of any class
+type apicManagedObject struct {
+	Attributes map[string]interface{} `json:"attributes"`
+}
+
+// apicSubscriptionMessage is the message pushed by APIC on the websocket
+type apicSubscriptionMessage struct {
+	SubscriptionID []string                       `json:"subscriptionId"`
+	IMData         []map[string]apicManagedObject `json:"imdata"`
+}
+
+// apicSubscriptionResponse is the response of the subscription query
+type apicSubscriptionResponse struct {
+	SubscriptionID string `json:"subscriptionId"`
+}
+
+// apicLoginResponse is the response of the token refresh
+type apicLoginResponse struct {
+	IMData []struct {
+		AAALogin struct {
+			Attributes struct {
+				Token string `json:"token"`
+			} `json:"attributes"`
+		} `json:"aaaLogin"`
+	} `json:"imdata"`
+}
+
+// APICSubscriptionManager subscribes to the changes of the managed object classes over the
+// APIC websocket and notifies them. The subscriptions and the APIC token are refreshed before
+// they time out, and the websocket is reopened with the subscriptions when it is dropped.
+type APICSubscriptionManager struct {
+	classes []string
+	refresh time.Duration
+	notify  func(APICNotification)
+	lock    sync.Mutex
+	stop    chan struct{}
+}
+
+// NewAPICSubscriptionManager returns the manager for subscribing to the given classes
+func NewAPICSubscriptionManager(classes []string, refresh time.Duration, notify func(APICNotification)) *APICSubscriptionManager {
+	return &APICSubscriptionManager{
+		classes: classes,
+		refresh: refresh,
+		notify:  notify,
+	}
+}
+
+// Start opens the APIC websocket and subscribes to the classes, false is returned when already started
+func (m *APICSubscriptionManager) Start() bool {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	if m.stop != nil {
+		return false
+	}
+	m.stop = make(chan struct{})
+	go m.run(m.stop)
+	return true
+}
+
+// Stop closes the APIC websocket, the subscriptions time out in APIC as they are no longer refreshed
+func (m *APICSubscriptionManager) Stop() {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	if m.stop != nil {
+		close(m.stop)
+		m.stop = nil
+	}
+}
+
+// Running reports whether the manager is started
+func (m *APICSubscriptionManager) Running() bool {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	return m.stop != nil
+}
+
+// Classes returns the managed object classes subscribed
+func (m *APICSubscriptionManager) Classes() []string {
+	return m.classes
+}
+
+// run keeps the subscription session open, reconnecting with backoff when it is dropped
+func (m *APICSubscriptionManager) run(stop chan struct{}) {
+	delay := minAPICReconnectDelay
+	for {
+		started := time.Now()
+		err := m.session(stop)
+		select {
+		case <-stop:
+			log.Info("APIC subscriptions stopped")
+			return
+		default:
+		}
+		if time.Since(started) > m.refresh {
+			delay = minAPICReconnectDelay
+		}
+		log.Error(fmt.Sprintf("APIC subscription session ended, reconnecting in %s: %v", delay, err))
+		select {
+		case <-stop:
+			return
+		case <-time.After(JitteredInterval(delay)):
+		}
+		if delay *= 2; delay > maxAPICReconnectDelay {
+			delay = maxAPICReconnectDelay
+		}
+	}
+}
+
+// session opens the websocket, subscribes to the classes and dispatches the notifications
+// until the websocket is dropped, refreshing of the subscriptions fails or stop is closed
+func (m *APICSubscriptionManager) session(stop chan struct{}) error {
+	aciClient := newAPICClient()
+	if err := aciClient.Authenticate(); err != nil {
+		return err
+	}
+	token := aciClient.AuthToken.Token
+	conn, err := dialAPICWebsocket(token)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	var subscriptionIDs []string
+	for _, class := range m.classes {
+		id, err := subscribeAPICClass(class, token, m.refresh)
+		if err != nil {
+			return err
+		}
+		subscriptionIDs = append(subscriptionIDs, id)
+	}
+	log.Info(fmt.Sprintf("subscribed to APIC classes %v", m.classes))
+
+	readErr := make(chan error, 1)
+	go func() {
+		for {
+			_, message, err := conn.ReadMessage()
+			if err != nil {
+				readErr <- err
+				return
+			}
+			m.dispatch(message)
+		}
+	}()
+	timer := time.NewTimer(JitteredInterval(m.refresh))
+	defer timer.Stop()
+	for {
+		select {
+		case <-stop:
+			return nil
+		case err := <-readErr:
+			return fmt.Errorf("APIC websocket closed: %v", err)
+		case <-timer.C:
+			timer.Reset(JitteredInterval(m.refresh))
+			if token, err = refreshAPICToken(token); err != nil {
+				return err
+			}
+			for _, id := range subscriptionIDs {
+				endpoint := apicURL("/subscriptionRefresh.json?id=%s", id)
+				if _, err := getAPICDataWithToken(endpoint, token); err != nil {
+					return fmt.Errorf("while refreshing APIC subscription %s, got: %v", id, err)
+				}
+			}
+		}
+	}
+}
+
+// dispatch notifies the managed object changes of the websocket message
+func (m *APICSubscriptionManager) dispatch(message []byte) {
+	var subscriptionMessage apicSubscriptionMessage
+	if err := json.Unmarshal(message, &subscriptionMessage); err != nil {
+		log.Error("while unmarshalling APIC subscription message, got: " + err.Error())
+		return
+	}
+	for _, imdata := range subscriptionMessage.IMData {
+		for class, mo := range imdata {
+			m.notify(APICNotification{Class: class, Attributes: mo.Attributes})
+		}
+	}
+}
+
+func dialAPICWebsocket(token string) (*websocket.Conn, error) {
+	httpClient, err := apicHTTPClient()
+	if err != nil {
+		return nil, err
+	}
+	dialer := *websocket.DefaultDialer
+	if transport, ok := httpClient.Transport.(*http.Transport); ok {
+		dialer.TLSClientConfig = transport.TLSClientConfig
+	} else {
+		dialer.TLSClientConfig = &tls.Config{}
+	}
+	conn, _, err := dialer.Dial(fmt.Sprintf("wss://%s/socket%s", config.Data.APICConf.APICHost, token), nil)
+	if err != nil {
+		return nil, fmt.Errorf("while opening APIC websocket, got: %v", err)
+	}
+	return conn, nil
+}
+
+// subscribeAPICClass subscribes to the changes of the class and returns the subscription id
+func subscribeAPICClass(class, token string, refresh time.Duration) (string, error) {
+	// subscription is timed out by APIC when it is not refreshed twice in a row
+	endpoint := apicURL("/class/%s.json?subscription=yes&refresh-timeout=%d", class, int(2*refresh.Seconds()))
+	body, err := getAPICDataWithToken(endpoint, token)
+	if err != nil {
+		return "", fmt.Errorf("while subscribing to APIC class %s, got: %v", class, err)
+	}
+	var subscription apicSubscriptionResponse
+	if err := json.Unmarshal(body, &subscription); err != nil || subscription.SubscriptionID == "" {
+		return "", fmt.Errorf("while subscribing to APIC class %s, got response without subscription id: %s", class, body)
+	}
+	return subscription.SubscriptionID, nil
+}
+
+// refreshAPICToken extends the validity of the APIC token, the refreshed token is returned
+func refreshAPICToken(token string) (string, error) {
+	body, err := getAPICDataWithToken(apicURL("/aaaRefresh.json"), token)
+	if err != nil {
+		return "", fmt.Errorf("while refreshing APIC token, got: %v", err)
+	}
+	var login apicLoginResponse
+	if err := json.Unmarshal(body, &login); err != nil || len(login.IMData) == 0 || login.IMData[0].AAALogin.Attributes.Token == "" {
+		return "", fmt.Errorf("while refreshing APIC token, got response without token: %s", body)
+	}
+	return login.IMData[0].AAALogin.Attributes.Token, nil
+}
@@ -0,0 +1,117 @@
+//(C) Copyright [2020] Hewlett Packard Enterprise Development LP
+//
+//Licensed under the Apache License, Version 2.0 (the "License"); you may
+//not use this file except in compliance with the License. You may obtain
+//a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+//Unless required by applicable law or agreed to in writing, software
+//distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+//WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+//License for the specific language governing permissions and limitations
+// under the License.
+
+//Package caputilities ...
+package caputilities
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/ODIM-Project/PluginCiscoACI/capmodel"
+	"github.com/ODIM-Project/PluginCiscoACI/config"
+)
+
+// ErrOutOfTenant is returned for the port which no EPG of the tenant the queries are scoped to is bound to
+var ErrOutOfTenant = errors.New("the port is not bound to an EPG of the APIC tenant")
+
+// tenantPathsTTL is how long the paths of the EPGs of the tenant are used before they are read again, so
+// that the health and fault queries of the ports don't each read them from APIC
+const tenantPathsTTL = 30 * time.Second
+
+// tenantPaths is the cache of the fabric path endpoints the EPGs of the tenant are bound to
+var tenantPaths struct {
+	lock    sync.Mutex
+	tenant  string
+	fetched time.Time
+	paths   map[string]bool
+}
+
+// getTenantPaths returns the DNs of the fabric path endpoints the EPGs of the configured tenant are statically
+// bound to, like topology/pod-1/paths-101/pathep-[eth1/1]. nil is returned when no tenant is configured.
+// The query carries the trace context of traceParent.
+func getTenantPaths(traceParent string) (map[string]bool, error) {
+	dn := tenantDN()
+	if dn == "" {
+		return nil, nil
+	}
+	tenantPaths.lock.Lock()
+	defer tenantPaths.lock.Unlock()
+	if tenantPaths.paths != nil && tenantPaths.tenant == dn && time.Since(tenantPaths.fetched) < tenantPathsTTL {
+		return tenantPaths.paths, nil
+	}
+	body, err := getAPICPages(tenantPathsEndpoint(dn), tracedAPICData(traceParent))
+	if err != nil {
+		return nil, fmt.Errorf("while reading the paths of the EPGs of tenant %s: %w", config.Data.APICConf.Tenant, err)
+	}
+	paths, err := ParseTenantPaths(body)
+	if err != nil {
+		return nil, err
+	}
+	tenantPaths.tenant, tenantPaths.fetched, tenantPaths.paths = dn, time.Now(), paths
+	return paths, nil
+}
+
+// tenantPathsEndpoint returns the endpoint of the static path bindings of the EPGs of the tenant
+func tenantPathsEndpoint(dn string) string {
+	return apicURL("/node/mo/%s.json?query-target=subtree&target-subtree-class=fvRsPathAtt", dn)
+}
+
+// checkTenantPort returns ErrOutOfTenant when a tenant is configured and none of its EPGs is bound to the port
+func checkTenantPort(traceParent, podID, ACISwitchID, portID string) error {
+	paths, err := getTenantPaths(traceParent)
+	if err != nil || paths == nil {
+		return err
+	}
+	if !paths[PortPathDN(podID, ACISwitchID, portID)] {
+		return fmt.Errorf("%w: port %s of switch %s", ErrOutOfTenant, portID, ACISwitchID)
+	}
+	return nil
+}
+
+// scopeSwitchPortsHealth drops the health of the ports of the switch no EPG of the configured tenant is bound to
+func scopeSwitchPortsHealth(traceParent, podID, ACISwitchID string, health map[string]capmodel.HealthData) error {
+	paths, err := getTenantPaths(traceParent)
+	if err != nil || paths == nil {
+		return err
+	}
+	for portID := range health {
+		if !paths[PortPathDN(podID, ACISwitchID, portID)] {
+			delete(health, portID)
+		}
+	}
+	return nil
+}
+
+// scopePortFaults drops the faults of the ports no EPG of the configured tenant is bound to,
+// the faults are keyed by the APIC node id and then by the port id
+func scopePortFaults(traceParent, podID string, faults map[string]map[string]capmodel.PortFault) error {
+	paths, err := getTenantPaths(traceParent)
+	if err != nil || paths == nil {
+		return err
+	}
+	for nodeID, portFaults := range faults {
+		for portID := range portFaults {
+			if !paths[PortPathDN(podID, nodeID, portID)] {
+				delete(portFaults, portID)
+			}
+		}
+		if len(portFaults) == 0 {
+			delete(faults, nodeID)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,94 @@
+//(C) Copyright [2020] Hewlett Packard Enterprise Development LP
+//
+//Licensed under the Apache License, Version 2.0 (the "License"); you may
+//not use this file except in compliance with the License. You may obtain
+//a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+//Unless required by applicable law or agreed to in writing, software
+//distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+//WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+//License for the specific language governing permissions and limitations
+// under the License.
+
+package caputilities
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/ODIM-Project/PluginCiscoACI/config"
+)
+
+// mockAPICTenants answers the reads of APIC with the static paths of the EPGs of the tenants Sales and Eng,
+// bound to eth1/1 and eth1/2 of switch 101, and with a fault and the health of both ports
+func mockAPICTenants(t *testing.T) {
+	config.SetUpMockConfig(t)
+	config.Data.APICConf.APICHost = "apic1"
+	config.Data.APICConf.BalanceReads = true
+	apicReadBalancer = nil
+	defaultGetAPICHostData := getAPICHostData
+	getAPICHostData = func(traceParent, host, path string) ([]byte, error) {
+		switch {
+		case strings.Contains(path, "/uni/tn-Sales.json"):
+			return []byte(`{"totalCount":"1","imdata":[{"fvRsPathAtt":{"attributes":{"tDn":"topology/pod-1/paths-101/pathep-[eth1/1]"}}}]}`), nil
+		case strings.Contains(path, "/uni/tn-Eng.json"):
+			return []byte(`{"totalCount":"1","imdata":[{"fvRsPathAtt":{"attributes":{"tDn":"topology/pod-1/paths-101/pathep-[eth1/2]"}}}]}`), nil
+		case strings.Contains(path, "/faultInst.json"):
+			return []byte(`{"totalCount":"2","imdata":[` +
+				`{"faultInst":{"attributes":{"dn":"topology/pod-1/node-101/sys/phys-[eth1/1]/phys/fault-F1678","severity":"major","code":"F1678"}}},` +
+				`{"faultInst":{"attributes":{"dn":"topology/pod-1/node-101/sys/phys-[eth1/2]/phys/fault-F0532","severity":"critical","code":"F0532"}}}]}`), nil
+		case strings.Contains(path, "/healthInst.json"):
+			return []byte(`{"totalCount":"2","imdata":[` +
+				`{"healthInst":{"attributes":{"dn":"topology/pod-1/node-101/sys/phys-[eth1/1]/phys/health","cur":"100"}}},` +
+				`{"healthInst":{"attributes":{"dn":"topology/pod-1/node-101/sys/phys-[eth1/2]/phys/health","cur":"20"}}}]}`), nil
+		}
+		return []byte(`{"totalCount":"1","imdata":[{"healthInst":{"attributes":{"cur":"100"}}}]}`), nil
+	}
+	t.Cleanup(func() {
+		config.Data.APICConf.BalanceReads = false
+		config.Data.APICConf.Tenant = ""
+		getAPICHostData = defaultGetAPICHostData
+		tenantPaths.paths = nil
+	})
+}
+
+func TestGetPortFaultsTenant(t *testing.T) {
+	mockAPICTenants(t)
+
+	// fabric-wide when no tenant is configured
+	faults, err := GetPortFaults("", "1", "101")
+	if err != nil || len(faults["101"]) != 2 {
+		t.Fatalf("GetPortFaults() = %v, %v, want the faults of both ports", faults, err)
+	}
+
+	// the faults of the ports of another tenant are excluded
+	config.Data.APICConf.Tenant = "Sales"
+	faults, err = GetPortFaults("", "1", "101")
+	if err != nil || len(faults["101"]) != 1 || faults["101"]["eth1/1"].Code != "F1678" {
+		t.Errorf("GetPortFaults() of tenant Sales = %v, %v, want only the fault of eth1/1", faults, err)
+	}
+	config.Data.APICConf.Tenant = "Eng"
+	faults, err = GetPortFaults("", "1", "")
+	if err != nil || len(faults["101"]) != 1 || faults["101"]["eth1/2"].Code != "F0532" {
+		t.Errorf("GetPortFaults() of tenant Eng = %v, %v, want only the fault of eth1/2", faults, err)
+	}
+}
+
+func TestGetPortHealthTenant(t *testing.T) {
+	mockAPICTenants(t)
+	config.Data.APICConf.Tenant = "Sales"
+
+	health, err := GetSwitchPortsHealth("", "1", "101")
+	if _, ok := health["eth1/2"]; err != nil || len(health) != 1 || ok {
+		t.Errorf("GetSwitchPortsHealth() of tenant Sales = %v, %v, want only the health of eth1/1", health, err)
+	}
+	if _, err := GetPortHealth("", "1", "101", "eth1/1"); err != nil {
+		t.Errorf("GetPortHealth() of the port of the tenant error = %v", err)
+	}
+	if _, err := GetPortHealth("", "1", "101", "eth1/2"); !errors.Is(err, ErrOutOfTenant) {
+		t.Errorf("GetPortHealth() of the port of another tenant error = %v, want ErrOutOfTenant", err)
+	}
+}
@@ -0,0 +1,104 @@
+//(C) Copyright [2020] Hewlett Packard Enterprise Development LP
+//
+//Licensed under the Apache License, Version 2.0 (the "License"); you may
+//not use this file except in compliance with the License. You may obtain
+//a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+//Unless required by applicable law or agreed to in writing, software
+//distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+//WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+//License for the specific language governing permissions and limitations
+// under the License.
+
+package caputilities
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/ODIM-Project/PluginCiscoACI/config"
+)
+
+// apicTokenCache holds the token of a controller the requests of the plugin are made with, the token
+// is reused until it is about to expire so that the controller isn't logged in to for every request
+type apicTokenCache struct {
+	host   string
+	lock   sync.Mutex
+	token  string
+	expiry time.Time
+}
+
+var (
+	apicTokens     = make(map[string]*apicTokenCache)
+	apicTokensLock sync.Mutex
+)
+
+// apicLogin logs in to the controller and returns the token with its expiry, replaced in unit tests
+var apicLogin = func(host string) (string, time.Time, error) {
+	hostClient := newAPICHostClient(host)
+	if err := hostClient.Authenticate(); err != nil {
+		return "", time.Time{}, err
+	}
+	return hostClient.AuthToken.Token, hostClient.AuthToken.Expiry, nil
+}
+
+// apicAuthToken returns the APIC token of the requests made to APICHost
+func apicAuthToken() (string, error) {
+	return apicHostTokens(config.Data.APICConf.APICHost).get(time.Now(), apicTokenClockSkew())
+}
+
+// apicHostTokens returns the token cache of the controller
+func apicHostTokens(host string) *apicTokenCache {
+	apicTokensLock.Lock()
+	defer apicTokensLock.Unlock()
+	tokens, ok := apicTokens[host]
+	if !ok {
+		tokens = &apicTokenCache{host: host}
+		apicTokens[host] = tokens
+	}
+	return tokens
+}
+
+// apicTokenClockSkew returns the configured clock skew tolerance, the controllers are logged in
+// to again when their token expires within it
+func apicTokenClockSkew() time.Duration {
+	return time.Duration(config.Data.APICConf.TokenClockSkewInSeconds) * time.Second
+}
+
+func (c *apicTokenCache) get(now time.Time, skew time.Duration) (string, error) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if c.token != "" && !tokenNeedsRefresh(c.expiry, now, skew) {
+		return c.token, nil
+	}
+	token, expiry, err := apicLogin(c.host)
+	if err != nil {
+		c.token = ""
+		return "", err
+	}
+	c.token, c.expiry = token, expiry
+	return token, nil
+}
+
+// invalidate drops the token when APIC rejected it, so that the next request logs in again
+func (c *apicTokenCache) invalidate(token string, err error) {
+	var apicErr *APICError
+	if !errors.As(err, &apicErr) || !apicErr.Unauthorized() {
+		return
+	}
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if c.token == token {
+		c.token = ""
+	}
+}
+
+// tokenNeedsRefresh reports whether the token expiring at expiry is refreshed at now. The expiry is
+// told by APIC, the skew is subtracted from it so that the token is refreshed before it expires
+// when the clock of the plugin host is behind the one of APIC.
+func tokenNeedsRefresh(expiry, now time.Time, skew time.Duration) bool {
+	return !now.Before(expiry.Add(-skew))
+}
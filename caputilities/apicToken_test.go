@@ -0,0 +1,53 @@
+//(C) Copyright [2020] Hewlett Packard Enterprise Development LP
+//
+//Licensed under the Apache License, Version 2.0 (the "License"); you may
+//not use this file except in compliance with the License. You may obtain
+//a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+//Unless required by applicable law or agreed to in writing, software
+//distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+//WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+//License for the specific language governing permissions and limitations
+// under the License.
+
+package caputilities
+
+import (
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestAPICTokenRefreshedBeforeExpiry(t *testing.T) {
+	defer func(login func(host string) (string, time.Time, error)) { apicLogin = login }(apicLogin)
+	now := time.Now()
+	expiry := now.Add(10 * time.Minute)
+	skew := 30 * time.Second
+	logins := 0
+	apicLogin = func(host string) (string, time.Time, error) {
+		logins++
+		return "token", expiry, nil
+	}
+	cache := apicTokenCache{host: "apic1"}
+
+	if _, err := cache.get(now, skew); err != nil || logins != 1 {
+		t.Fatalf("get() without token = %v after %d logins, want 1 login", err, logins)
+	}
+	if _, err := cache.get(expiry.Add(-skew-time.Second), skew); err != nil || logins != 1 {
+		t.Errorf("get() %s before expiry = %v after %d logins, want the cached token", skew+time.Second, err, logins)
+	}
+	if _, err := cache.get(expiry.Add(-skew), skew); err != nil || logins != 2 {
+		t.Errorf("get() %s before expiry = %v after %d logins, want the token refreshed", skew, err, logins)
+	}
+
+	cache.invalidate("token", &APICError{StatusCode: http.StatusNotFound})
+	if _, err := cache.get(now, skew); err != nil || logins != 2 {
+		t.Errorf("get() after not found error = %v after %d logins, want the cached token", err, logins)
+	}
+	cache.invalidate("token", &APICError{StatusCode: http.StatusUnauthorized})
+	if _, err := cache.get(now, skew); err != nil || logins != 3 {
+		t.Errorf("get() after unauthorized error = %v after %d logins, want the token refreshed", err, logins)
+	}
+}
@@ -0,0 +1,146 @@
+//(C) Copyright [2020] Hewlett Packard Enterprise Development LP
+//
+//Licensed under the Apache License, Version 2.0 (the "License"); you may
+//not use this file except in compliance with the License. You may obtain
+//a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+//Unless required by applicable law or agreed to in writing, software
+//distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+//WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+//License for the specific language governing permissions and limitations
+// under the License.
+
+package caputilities
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	log "github.com/sirupsen/logrus"
+)
+
+// certReloadDebounce is the time waited after a change of the certificate files before reloading
+// them, so that the certificate and the key written one after the other are loaded together
+const certReloadDebounce = 2 * time.Second
+
+// CertReloader serves the plugin certificate to the TLS handshakes and reloads it from the disk
+// when the certificate or the key file is changed, so that the rotated certificates are used
+// without restarting the plugin
+type CertReloader struct {
+	certPath string
+	keyPath  string
+	debounce time.Duration
+	lock     sync.RWMutex
+	cert     *tls.Certificate
+}
+
+// NewCertReloader loads the certificate and key from the given files
+func NewCertReloader(certPath, keyPath string) (*CertReloader, error) {
+	reloader := &CertReloader{
+		certPath: certPath,
+		keyPath:  keyPath,
+		debounce: certReloadDebounce,
+	}
+	if err := reloader.Reload(); err != nil {
+		return nil, err
+	}
+	return reloader, nil
+}
+
+// GetCertificate returns the certificate currently loaded, to be used as the tls.Config GetCertificate callback
+func (r *CertReloader) GetCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+	return r.cert, nil
+}
+
+// Reload reads and validates the certificate and key files. When they are not valid
+// an error is returned and the certificate loaded previously continues to be served.
+func (r *CertReloader) Reload() error {
+	certPEM, err := ioutil.ReadFile(r.certPath)
+	if err != nil {
+		return fmt.Errorf("while reading certificate %s, got: %v", r.certPath, err)
+	}
+	keyPEM, err := ioutil.ReadFile(r.keyPath)
+	if err != nil {
+		return fmt.Errorf("while reading private key %s, got: %v", r.keyPath, err)
+	}
+	cert, err := tls.X509KeyPair(certPEM, keyPEM)
+	if err != nil {
+		return fmt.Errorf("while loading certificate %s and private key %s, got: %v", r.certPath, r.keyPath, err)
+	}
+	leaf, err := x509.ParseCertificate(cert.Certificate[0])
+	if err != nil {
+		return fmt.Errorf("while parsing certificate %s, got: %v", r.certPath, err)
+	}
+	if time.Now().After(leaf.NotAfter) {
+		return fmt.Errorf("certificate %s expired on %s", r.certPath, leaf.NotAfter.Format(time.RFC3339))
+	}
+	cert.Leaf = leaf
+	r.lock.Lock()
+	r.cert = &cert
+	r.lock.Unlock()
+	return nil
+}
+
+// Watch monitors the directories of the certificate and key files using fsnotify and
+// reloads the certificate on changes. Directories are watched instead of the files,
+// as the mounted secrets are updated by replacing the symbolic links to the files.
+func (r *CertReloader) Watch() error {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return err
+	}
+	for _, dir := range []string{filepath.Dir(r.certPath), filepath.Dir(r.keyPath)} {
+		if err := watcher.Add(dir); err != nil {
+			watcher.Close()
+			return err
+		}
+	}
+	go func() {
+		defer watcher.Close()
+		var reloadTimer *time.Timer
+		for {
+			select {
+			case fileEvent, ok := <-watcher.Events:
+				if !ok {
+					return
+				}
+				log.Debug("Modified file: " + fileEvent.Name)
+				if reloadTimer != nil {
+					reloadTimer.Stop()
+				}
+				reloadTimer = time.AfterFunc(r.debounce, r.reloadOnChange)
+			case err, ok := <-watcher.Errors:
+				if !ok {
+					return
+				}
+				log.Error("while watching certificate files, got: " + err.Error())
+			}
+		}
+	}()
+	return nil
+}
+
+func (r *CertReloader) reloadOnChange() {
+	if err := r.Reload(); err != nil {
+		log.Error("rejected the changed certificate, continuing with the previous one: " + err.Error())
+		return
+	}
+	log.Info("reloaded the plugin certificate " + r.certPath)
+}
+
+// SetCertReloader makes the TLS handshakes of the server to use the certificate of the reloader
+func SetCertReloader(tlsConfig *tls.Config, reloader *CertReloader) {
+	// GetCertificate is not used by crypto/tls when Certificates are present
+	tlsConfig.Certificates = nil
+	tlsConfig.GetCertificate = reloader.GetCertificate
+}
@@ -0,0 +1,120 @@
+//(C) Copyright [2020] Hewlett Packard Enterprise Development LP
+//
+//Licensed under the Apache License, Version 2.0 (the "License"); you may
+//not use this file except in compliance with the License. You may obtain
+//a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+//Unless required by applicable law or agreed to in writing, software
+//distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+//WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+//License for the specific language governing permissions and limitations
+// under the License.
+
+package caputilities
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io/ioutil"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// writeTestCert writes a self signed certificate with the given serial number and its key to the files
+func writeTestCert(t *testing.T, certPath, keyPath string, serial int64) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(serial),
+		Subject:      pkix.Name{CommonName: "localhost"},
+		DNSNames:     []string{"localhost"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	certDER, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("failed to create certificate: %v", err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatalf("failed to marshal key: %v", err)
+	}
+	if err := ioutil.WriteFile(certPath, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: certDER}), 0600); err != nil {
+		t.Fatalf("failed to write certificate: %v", err)
+	}
+	if err := ioutil.WriteFile(keyPath, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0600); err != nil {
+		t.Fatalf("failed to write key: %v", err)
+	}
+}
+
+// servedSerial returns the serial number of the certificate served by the server
+func servedSerial(t *testing.T, server *httptest.Server) int64 {
+	client := http.Client{Transport: &http.Transport{
+		TLSClientConfig:   &tls.Config{InsecureSkipVerify: true},
+		DisableKeepAlives: true,
+	}}
+	resp, err := client.Get(server.URL)
+	if err != nil {
+		t.Fatalf("request to the server failed: %v", err)
+	}
+	resp.Body.Close()
+	return resp.TLS.PeerCertificates[0].SerialNumber.Int64()
+}
+
+func TestCertReloader(t *testing.T) {
+	dir := t.TempDir()
+	certPath, keyPath := filepath.Join(dir, "server.crt"), filepath.Join(dir, "server.key")
+	writeTestCert(t, certPath, keyPath, 1)
+
+	reloader, err := NewCertReloader(certPath, keyPath)
+	if err != nil {
+		t.Fatalf("NewCertReloader() error = %v", err)
+	}
+	reloader.debounce = 10 * time.Millisecond
+	if err := reloader.Watch(); err != nil {
+		t.Fatalf("Watch() error = %v", err)
+	}
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	server.TLS = &tls.Config{}
+	SetCertReloader(server.TLS, reloader)
+	server.StartTLS()
+	defer server.Close()
+
+	if serial := servedSerial(t, server); serial != 1 {
+		t.Fatalf("served certificate serial = %d, want 1", serial)
+	}
+
+	// rotated certificate is served without restarting the server
+	writeTestCert(t, certPath, keyPath, 2)
+	deadline := time.Now().Add(5 * time.Second)
+	for servedSerial(t, server) != 2 {
+		if time.Now().After(deadline) {
+			t.Fatal("rotated certificate is not served")
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+
+	// malformed certificate is rejected and the previous one continues to be served
+	if err := ioutil.WriteFile(certPath, []byte("not a certificate"), 0600); err != nil {
+		t.Fatalf("failed to write certificate: %v", err)
+	}
+	if err := reloader.Reload(); err == nil {
+		t.Error("Reload() of malformed certificate error = nil, want error")
+	}
+	if serial := servedSerial(t, server); serial != 2 {
+		t.Errorf("served certificate serial = %d, want 2", serial)
+	}
+}
@@ -0,0 +1,76 @@
+//(C) Copyright [2020] Hewlett Packard Enterprise Development LP
+//
+//Licensed under the Apache License, Version 2.0 (the "License"); you may
+//not use this file except in compliance with the License. You may obtain
+//a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+//Unless required by applicable law or agreed to in writing, software
+//distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+//WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+//License for the specific language governing permissions and limitations
+// under the License.
+
+package caputilities
+
+import (
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	lutilconf "github.com/ODIM-Project/ODIM/lib-utilities/config"
+	"github.com/ODIM-Project/PluginCiscoACI/config"
+)
+
+var (
+	httpClientsLock sync.Mutex
+	// httpClients are the HTTP clients shared by the outbound requests, by TLS server name
+	httpClients = map[string]*http.Client{}
+)
+
+// sharedHTTPClient returns the HTTP client shared by the requests to the servers whose certificate is
+// verified against the given TLS server name, or the host of the request when empty. The requests reuse
+// the idle connections of the client as per ClientConf, instead of doing a TLS handshake each.
+func sharedHTTPClient(serverName string) (*http.Client, error) {
+	httpClientsLock.Lock()
+	defer httpClientsLock.Unlock()
+	if httpClient, ok := httpClients[serverName]; ok {
+		return httpClient, nil
+	}
+	httpClient, err := newHTTPClient(serverName)
+	if err != nil {
+		return nil, err
+	}
+	httpClients[serverName] = httpClient
+	return httpClient, nil
+}
+
+// newHTTPClient returns the HTTP client verifying the certificates with the root CA, against the TLS server
+// name when given, and keeping the idle connections as per ClientConf
+func newHTTPClient(serverName string) (*http.Client, error) {
+	httpConf := &lutilconf.HTTPConfig{
+		CACertificate: &config.Data.KeyCertConf.RootCACertificate,
+	}
+	httpClient, err := httpConf.GetHTTPClientObj()
+	if err != nil {
+		return nil, err
+	}
+	transport, ok := httpClient.Transport.(*http.Transport)
+	if !ok {
+		return nil, fmt.Errorf("the connections of the HTTP client can't be configured, its transport is %T", httpClient.Transport)
+	}
+	if serverName != "" {
+		if transport.TLSClientConfig == nil {
+			return nil, fmt.Errorf("TLS server name %s can't be set on the HTTP client", serverName)
+		}
+		transport.TLSClientConfig.ServerName = serverName
+	}
+	if conf := config.Data.ClientConf; conf != nil {
+		transport.MaxIdleConns = conf.MaxIdleConns
+		transport.MaxIdleConnsPerHost = conf.MaxIdleConnsPerHost
+		transport.IdleConnTimeout = time.Duration(conf.IdleConnTimeoutInSeconds) * time.Second
+	}
+	return httpClient, nil
+}
@@ -0,0 +1,57 @@
+//(C) Copyright [2020] Hewlett Packard Enterprise Development LP
+//
+//Licensed under the Apache License, Version 2.0 (the "License"); you may
+//not use this file except in compliance with the License. You may obtain
+//a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+//Unless required by applicable law or agreed to in writing, software
+//distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+//WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+//License for the specific language governing permissions and limitations
+// under the License.
+
+package caputilities
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/ODIM-Project/PluginCiscoACI/config"
+)
+
+func TestNewHTTPClientKeepAlive(t *testing.T) {
+	config.SetUpMockConfig(t)
+	config.Data.ClientConf = &config.ClientConf{MaxIdleConns: 200, MaxIdleConnsPerHost: 50, IdleConnTimeoutInSeconds: 120}
+	httpClient, err := newHTTPClient("apic.example.com")
+	if err != nil {
+		t.Fatalf("newHTTPClient() error = %v", err)
+	}
+	transport, ok := httpClient.Transport.(*http.Transport)
+	if !ok {
+		t.Fatalf("newHTTPClient() transport = %T, want *http.Transport", httpClient.Transport)
+	}
+	if transport.MaxIdleConns != 200 || transport.MaxIdleConnsPerHost != 50 || transport.IdleConnTimeout != 120*time.Second {
+		t.Errorf("newHTTPClient() transport MaxIdleConns = %d, MaxIdleConnsPerHost = %d, IdleConnTimeout = %s, want 200, 50, 2m0s",
+			transport.MaxIdleConns, transport.MaxIdleConnsPerHost, transport.IdleConnTimeout)
+	}
+	if transport.TLSClientConfig.ServerName != "apic.example.com" {
+		t.Errorf("newHTTPClient() ServerName = %q, want apic.example.com", transport.TLSClientConfig.ServerName)
+	}
+}
+
+func TestSharedHTTPClient(t *testing.T) {
+	config.SetUpMockConfig(t)
+	first, err := sharedHTTPClient("")
+	if err != nil {
+		t.Fatalf("sharedHTTPClient() error = %v", err)
+	}
+	if second, _ := sharedHTTPClient(""); second != first {
+		t.Error("sharedHTTPClient() returned a new client, want the client shared for the idle connections")
+	}
+	if other, _ := sharedHTTPClient("apic.example.com"); other == first {
+		t.Error("sharedHTTPClient() of another TLS server name returned the same client, want its own")
+	}
+}
@@ -0,0 +1,85 @@
+//(C) Copyright [2020] Hewlett Packard Enterprise Development LP
+//
+//Licensed under the Apache License, Version 2.0 (the "License"); you may
+//not use this file except in compliance with the License. You may obtain
+//a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+//Unless required by applicable law or agreed to in writing, software
+//distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+//WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+//License for the specific language governing permissions and limitations
+// under the License.
+
+package caputilities
+
+import (
+	"crypto/tls"
+	"fmt"
+	"net"
+	"net/http"
+
+	"github.com/ODIM-Project/PluginCiscoACI/config"
+)
+
+// Listen opens a listener on the port for each of the addresses, so that the
+// server can be reached over IPv4 and IPv6 at the same time
+func Listen(addresses []string, port string) ([]net.Listener, error) {
+	var listeners []net.Listener
+	for _, address := range addresses {
+		listener, err := net.Listen("tcp", net.JoinHostPort(address, port))
+		if err != nil {
+			for _, l := range listeners {
+				l.Close()
+			}
+			return nil, fmt.Errorf("while listening on %s, got: %v", address, err)
+		}
+		listeners = append(listeners, listener)
+	}
+	return listeners, nil
+}
+
+// ServeTLS serves the server over TLS on all the listeners, the server is closed
+// when serving on one of them fails and the error of that listener is returned
+func ServeTLS(server *http.Server, listeners []net.Listener) error {
+	errs := make(chan error, len(listeners))
+	for _, listener := range listeners {
+		go func(l net.Listener) {
+			errs <- server.ServeTLS(l, "", "")
+		}(listener)
+	}
+	err := <-errs
+	server.Close()
+	return err
+}
+
+// SetHTTP2 enables HTTP/2 on the TLS server when it is configured, the clients negotiate it over
+// ALPN and the others are served HTTP/1.1. Only HTTP/1.1 is served when HTTP/2 is not configured.
+// HTTP/2 over cleartext (h2c) is never served.
+func SetHTTP2(server *http.Server) error {
+	if config.Data.ServerConf == nil || !config.Data.ServerConf.EnableHTTP2 {
+		// a non nil TLSNextProto turns off the HTTP/2 support of net/http
+		server.TLSNextProto = map[string]func(*http.Server, *tls.Conn, http.Handler){}
+		return nil
+	}
+	if server.TLSConfig == nil {
+		return fmt.Errorf("HTTP/2 is served only over TLS, the server has no TLS configuration")
+	}
+	server.TLSNextProto = nil
+	for _, proto := range []string{"h2", "http/1.1"} {
+		if !containsProto(server.TLSConfig.NextProtos, proto) {
+			server.TLSConfig.NextProtos = append(server.TLSConfig.NextProtos, proto)
+		}
+	}
+	return nil
+}
+
+func containsProto(protos []string, proto string) bool {
+	for _, p := range protos {
+		if p == proto {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,116 @@
+//(C) Copyright [2020] Hewlett Packard Enterprise Development LP
+//
+//Licensed under the Apache License, Version 2.0 (the "License"); you may
+//not use this file except in compliance with the License. You may obtain
+//a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+//Unless required by applicable law or agreed to in writing, software
+//distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+//WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+//License for the specific language governing permissions and limitations
+// under the License.
+
+package caputilities
+
+import (
+	"crypto/tls"
+	"net"
+	"net/http"
+	"path/filepath"
+	"testing"
+
+	"github.com/ODIM-Project/PluginCiscoACI/config"
+)
+
+func TestListenDualStack(t *testing.T) {
+	addresses := []string{"127.0.0.1", "::1"}
+	if ln, err := net.Listen("tcp6", "[::1]:0"); err != nil {
+		t.Log("IPv6 is not available, listening only on IPv4")
+		addresses = addresses[:1]
+	} else {
+		ln.Close()
+	}
+	listeners, err := Listen(addresses, "0")
+	if err != nil {
+		t.Fatalf("Listen() error = %v", err)
+	}
+	defer func() {
+		for _, l := range listeners {
+			l.Close()
+		}
+	}()
+	if len(listeners) != len(addresses) {
+		t.Fatalf("Listen() opened %d listeners, want %d", len(listeners), len(addresses))
+	}
+	for i, l := range listeners {
+		addr := l.Addr().(*net.TCPAddr)
+		if !addr.IP.Equal(net.ParseIP(addresses[i])) {
+			t.Errorf("listener %d is on %s, want %s", i, addr.IP, addresses[i])
+		}
+		conn, err := net.Dial("tcp", l.Addr().String())
+		if err != nil {
+			t.Errorf("connecting to %s, got: %v", l.Addr(), err)
+			continue
+		}
+		conn.Close()
+	}
+
+	// listeners already opened are closed when one of the addresses fails
+	if _, err := Listen([]string{"127.0.0.1", "192.0.2.1"}, "0"); err == nil {
+		t.Error("Listen() on an address not assigned to the host succeeded")
+	}
+}
+
+// servedProto returns the protocol the server answers a client attempting HTTP/2 with
+func servedProto(t *testing.T, enableHTTP2 bool) string {
+	config.SetUpMockConfig(t)
+	config.Data.ServerConf.EnableHTTP2 = enableHTTP2
+	dir := t.TempDir()
+	certPath, keyPath := filepath.Join(dir, "server.crt"), filepath.Join(dir, "server.key")
+	writeTestCert(t, certPath, keyPath, 1)
+	cert, err := tls.LoadX509KeyPair(certPath, keyPath)
+	if err != nil {
+		t.Fatalf("failed to load certificate: %v", err)
+	}
+	server := &http.Server{
+		Handler:   http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}),
+		TLSConfig: &tls.Config{Certificates: []tls.Certificate{cert}},
+	}
+	if err := SetHTTP2(server); err != nil {
+		t.Fatalf("SetHTTP2() error = %v", err)
+	}
+	listeners, err := Listen([]string{"127.0.0.1"}, "0")
+	if err != nil {
+		t.Fatalf("Listen() error = %v", err)
+	}
+	go ServeTLS(server, listeners)
+	defer server.Close()
+
+	client := http.Client{Transport: &http.Transport{
+		TLSClientConfig:   &tls.Config{InsecureSkipVerify: true, NextProtos: []string{"h2", "http/1.1"}},
+		ForceAttemptHTTP2: true,
+	}}
+	resp, err := client.Get("https://" + listeners[0].Addr().String())
+	if err != nil {
+		t.Fatalf("request to the server failed: %v", err)
+	}
+	resp.Body.Close()
+	return resp.Proto
+}
+
+func TestSetHTTP2(t *testing.T) {
+	if proto := servedProto(t, true); proto != "HTTP/2.0" {
+		t.Errorf("served protocol with HTTP/2 enabled = %s, want HTTP/2.0", proto)
+	}
+	if proto := servedProto(t, false); proto != "HTTP/1.1" {
+		t.Errorf("served protocol with HTTP/2 disabled = %s, want HTTP/1.1", proto)
+	}
+
+	// HTTP/2 is not enabled on a server without TLS
+	config.Data.ServerConf.EnableHTTP2 = true
+	if err := SetHTTP2(&http.Server{}); err == nil {
+		t.Error("SetHTTP2() on a server without TLS configuration, want error")
+	}
+}
@@ -21,8 +21,6 @@ import (
 	"encoding/json"
 	"fmt"
 	dmtfmodel "github.com/ODIM-Project/ODIM/lib-dmtf/model"
-	lutilconf "github.com/ODIM-Project/ODIM/lib-utilities/config"
-	"github.com/ODIM-Project/PluginCiscoACI/config"
 	"github.com/gofrs/uuid"
 	log "github.com/sirupsen/logrus"
 	"io/ioutil"
@@ -55,14 +53,12 @@ type RedfishClient struct {
 
 var redfishServiceRootURI = "/redfish/v1"
 
-// GetRedfishClient : Returns a new RedfishClient with insecure flag set.
+// GetRedfishClient : Returns a new RedfishClient with insecure flag set, the HTTP client is shared
+// by the Redfish requests, which reuse its idle connections.
 func GetRedfishClient() (*RedfishClient, error) {
 	var err error
 	newClient := RedfishClient{}
-	httpConf := &lutilconf.HTTPConfig{
-		CACertificate: &config.Data.KeyCertConf.RootCACertificate,
-	}
-	if newClient.httpClient, err = httpConf.GetHTTPClientObj(); err != nil {
+	if newClient.httpClient, err = sharedHTTPClient(""); err != nil {
 		return nil, err
 	}
 	return &newClient, nil
@@ -76,12 +72,10 @@ func (client *RedfishClient) Get(device *RedfishDevice, requestURI string) (*htt
 		return nil, err
 	}
 
-	req.Close = true
 	req.Header.Set("Accept", "application/json")
 	if device.Token != "" {
 		req.Header.Set("X-Auth-Token", device.Token)
 	}
-	req.Close = true
 
 	resp, err := client.httpClient.Do(req)
 	if err != nil {
@@ -127,13 +121,11 @@ func (client *RedfishClient) BasicAuthWithDevice(device *RedfishDevice, requestU
 		return nil, err
 	}
 
-	req.Close = true
 	req.Header.Set("Accept", "application/json")
 	auth := device.Username + ":" + string(device.Password)
 	Basicauth := "Basic " + base64.StdEncoding.EncodeToString([]byte(auth))
 	req.Header.Add("Authorization", Basicauth)
 	req.Header.Add("Content-Type", "application/json")
-	req.Close = true
 
 	resp, err := client.httpClient.Do(req)
 	if err != nil {
@@ -152,13 +144,11 @@ func (client *RedfishClient) GetWithBasicAuth(device *RedfishDevice, requestURI
 		return nil, err
 	}
 
-	req.Close = true
 	req.Header.Set("Accept", "application/json")
 	auth := device.Username + ":" + string(device.Password)
 	Basicauth := "Basic " + base64.StdEncoding.EncodeToString([]byte(auth))
 	req.Header.Add("Authorization", Basicauth)
 	req.Header.Add("Content-Type", "application/json")
-	req.Close = true
 
 	resp, err := client.httpClient.Do(req)
 	if err != nil {
@@ -175,13 +165,11 @@ func (client *RedfishClient) SubscribeForEvents(device *RedfishDevice) (*http.Re
 	if err != nil {
 		return nil, err
 	}
-	req.Close = true
 	req.Header.Set("Accept", "application/json")
 	auth := device.Username + ":" + string(device.Password)
 	Basicauth := "Basic " + base64.StdEncoding.EncodeToString([]byte(auth))
 	req.Header.Add("Authorization", Basicauth)
 	req.Header.Set("Content-Type", "application/json")
-	req.Close = true
 	var resp *http.Response
 	resp, err = client.httpClient.Do(req)
 	if err != nil {
@@ -199,13 +187,11 @@ func (client *RedfishClient) DeleteSubscriptionDetail(device *RedfishDevice) (*h
 		return nil, err
 	}
 
-	req.Close = true
 	auth := device.Username + ":" + string(device.Password)
 	Basicauth := "Basic " + base64.StdEncoding.EncodeToString([]byte(auth))
 	req.Header.Add("Authorization", Basicauth)
 	req.Header.Add("Content-Type", "application/json")
 	req.Header.Set("Accept", "application/json")
-	req.Close = true
 
 	resp, err := client.httpClient.Do(req)
 	if err != nil {
@@ -223,13 +209,11 @@ func (client *RedfishClient) GetSubscriptionDetail(device *RedfishDevice) (*http
 		return nil, err
 	}
 
-	req.Close = true
 	req.Header.Set("Accept", "application/json")
 	auth := device.Username + ":" + string(device.Password)
 	Basicauth := "Basic " + base64.StdEncoding.EncodeToString([]byte(auth))
 	req.Header.Add("Authorization", Basicauth)
 	req.Header.Add("Content-Type", "application/json")
-	req.Close = true
 
 	resp, err := client.httpClient.Do(req)
 	if err != nil {
@@ -0,0 +1,98 @@
+//(C) Copyright [2020] Hewlett Packard Enterprise Development LP
+//
+//Licensed under the Apache License, Version 2.0 (the "License"); you may
+//not use this file except in compliance with the License. You may obtain
+//a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+//Unless required by applicable law or agreed to in writing, software
+//distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+//WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+//License for the specific language governing permissions and limitations
+// under the License.
+
+package caputilities
+
+import (
+	"fmt"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// maxStartupRetryDelay bounds the doubling of the delay between the retries of a dependency
+const maxStartupRetryDelay = time.Minute
+
+// startupSleep waits between the retries, replaced in the tests
+var startupSleep = time.Sleep
+
+// StartupStep is a dependency of the plugin initialized at startup
+type StartupStep struct {
+	Name string
+	// Required steps must succeed for the plugin to start, the failure of an optional
+	// step, like the metric reports, is logged and doesn't block the readiness
+	Required bool
+	Init     func() error
+}
+
+// Startup initializes the dependencies of the plugin in order, retrying each of them with a
+// delay doubled on every retry until it succeeds or the attempts are exhausted
+type Startup struct {
+	steps      []StartupStep
+	attempts   int
+	retryDelay time.Duration
+}
+
+// NewStartup returns the startup trying each dependency the given number of times
+func NewStartup(attempts int, retryDelay time.Duration) *Startup {
+	if attempts < 1 {
+		attempts = 1
+	}
+	return &Startup{attempts: attempts, retryDelay: retryDelay}
+}
+
+// Require adds the dependency the plugin can't start without
+func (s *Startup) Require(name string, init func() error) {
+	s.steps = append(s.steps, StartupStep{Name: name, Required: true, Init: init})
+}
+
+// Optional adds the dependency the plugin can start without
+func (s *Startup) Optional(name string, init func() error) {
+	s.steps = append(s.steps, StartupStep{Name: name, Init: init})
+}
+
+// Run initializes the dependencies in the order they were added. The error of the first
+// required dependency failing all its attempts is returned, the following dependencies
+// are then not initialized.
+func (s *Startup) Run() error {
+	for i, step := range s.steps {
+		log.Info(fmt.Sprintf("startup %d/%d: initializing %s", i+1, len(s.steps), step.Name))
+		err := s.initialize(step)
+		switch {
+		case err == nil:
+			log.Info(fmt.Sprintf("startup %d/%d: %s is up", i+1, len(s.steps), step.Name))
+		case step.Required:
+			return fmt.Errorf("required dependency %s is not available after %d attempts: %v", step.Name, s.attempts, err)
+		default:
+			log.Warn(fmt.Sprintf("startup %d/%d: optional dependency %s is not available, starting without it: %v", i+1, len(s.steps), step.Name, err))
+		}
+	}
+	return nil
+}
+
+// initialize tries the step until it succeeds or the attempts are exhausted, the last error is returned
+func (s *Startup) initialize(step StartupStep) error {
+	delay := s.retryDelay
+	var err error
+	for attempt := 1; ; attempt++ {
+		if err = step.Init(); err == nil || attempt == s.attempts {
+			return err
+		}
+		log.Warn(fmt.Sprintf("startup: attempt %d/%d of %s failed, retrying in %s: %v", attempt, s.attempts, step.Name, delay, err))
+		startupSleep(delay)
+		if delay *= 2; delay > maxStartupRetryDelay {
+			delay = maxStartupRetryDelay
+		}
+	}
+}
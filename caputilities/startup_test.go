@@ -0,0 +1,98 @@
+//(C) Copyright [2020] Hewlett Packard Enterprise Development LP
+//
+//Licensed under the Apache License, Version 2.0 (the "License"); you may
+//not use this file except in compliance with the License. You may obtain
+//a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+//Unless required by applicable law or agreed to in writing, software
+//distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+//WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+//License for the specific language governing permissions and limitations
+// under the License.
+
+package caputilities
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// mockStartupSleep records the delays waited between the retries instead of waiting
+func mockStartupSleep(t *testing.T) *[]time.Duration {
+	var delays []time.Duration
+	startupSleep = func(delay time.Duration) { delays = append(delays, delay) }
+	t.Cleanup(func() { startupSleep = time.Sleep })
+	return &delays
+}
+
+// flakyDependency fails the given number of times before it comes up
+func flakyDependency(failures int, calls *int) func() error {
+	return func() error {
+		*calls++
+		if *calls <= failures {
+			return errors.New("connection refused")
+		}
+		return nil
+	}
+}
+
+func TestStartupDependencyUpAfterRetry(t *testing.T) {
+	delays := mockStartupSleep(t)
+	var order []string
+	var redisCalls int
+	startup := NewStartup(3, time.Second)
+	startup.Require("Redis", func() error {
+		order = append(order, "Redis")
+		return flakyDependency(2, &redisCalls)()
+	})
+	startup.Require("APIC", func() error {
+		order = append(order, "APIC")
+		return nil
+	})
+	if err := startup.Run(); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if want := []string{"Redis", "Redis", "Redis", "APIC"}; !reflect.DeepEqual(order, want) {
+		t.Errorf("dependencies initialized = %v, want %v", order, want)
+	}
+	if want := []time.Duration{time.Second, 2 * time.Second}; !reflect.DeepEqual(*delays, want) {
+		t.Errorf("retry delays = %v, want %v", *delays, want)
+	}
+}
+
+func TestStartupRequiredDependencyDown(t *testing.T) {
+	mockStartupSleep(t)
+	var redisCalls int
+	apicInitialized := false
+	startup := NewStartup(3, time.Second)
+	startup.Require("Redis", flakyDependency(3, &redisCalls))
+	startup.Require("APIC", func() error {
+		apicInitialized = true
+		return nil
+	})
+	if err := startup.Run(); err == nil {
+		t.Error("Run() with Redis down succeeded, want error")
+	}
+	if redisCalls != 3 || apicInitialized {
+		t.Errorf("Redis tried %d times and APIC initialized %v, want 3 attempts and APIC not initialized", redisCalls, apicInitialized)
+	}
+}
+
+func TestStartupOptionalDependencyDown(t *testing.T) {
+	mockStartupSleep(t)
+	var metricCalls int
+	apicInitialized := false
+	startup := NewStartup(2, time.Second)
+	startup.Optional("port metric reports", flakyDependency(2, &metricCalls))
+	startup.Require("APIC", func() error {
+		apicInitialized = true
+		return nil
+	})
+	if err := startup.Run(); err != nil || !apicInitialized {
+		t.Errorf("Run() with metric reports down = %v, APIC initialized %v, want the startup not blocked", err, apicInitialized)
+	}
+}
@@ -30,6 +30,10 @@
 package caputilities
 
 import (
+	"math/rand"
+	"net/http"
+	"strings"
+	"sync"
 	"time"
 
 	log "github.com/sirupsen/logrus"
@@ -45,6 +49,53 @@ var Status capresponse.Status
 // PluginStartTime hold the time from which plugin started
 var PluginStartTime time.Time
 
+var (
+	jitterRand = rand.New(rand.NewSource(time.Now().UnixNano()))
+	jitterLock sync.Mutex
+	// jitterFraction returns a random fraction between 0 and 1, it is replaced in the tests
+	jitterFraction = func() float64 {
+		jitterLock.Lock()
+		defer jitterLock.Unlock()
+		return jitterRand.Float64()
+	}
+)
+
+// JitteredInterval shortens the interval by a random part of up to the APIC RefreshJitter fraction
+// of it. The refreshes and the polls waiting for jittered intervals spread out over the interval
+// instead of hitting APIC at once, as they would when started together like after a restart. The
+// interval is only shortened, so that the refreshes are never late for the APIC timeouts.
+func JitteredInterval(interval time.Duration) time.Duration {
+	if config.Data.APICConf == nil {
+		return interval
+	}
+	return interval - time.Duration(config.Data.APICConf.RefreshJitter*jitterFraction()*float64(interval))
+}
+
+// DisplayPortID renders the APIC port id, like eth1/5, in the configured APIC PortIDFormat. The port
+// ids without the eth prefix, like the ones of the port channels, are reported as read from APIC.
+func DisplayPortID(apicPortID string) string {
+	if config.Data.APICConf == nil || config.Data.APICConf.PortIDFormat == "" || !strings.HasPrefix(apicPortID, "eth") {
+		return apicPortID
+	}
+	return strings.Replace(config.Data.APICConf.PortIDFormat, config.PortIDPlaceholder, strings.TrimPrefix(apicPortID, "eth"), 1)
+}
+
+// SetServerTimeouts applies the configured read, write and idle timeouts on the server
+func SetServerTimeouts(server *http.Server) {
+	if config.Data.ServerConf == nil {
+		return
+	}
+	server.ReadTimeout = time.Duration(config.Data.ServerConf.ReadTimeoutInSeconds) * time.Second
+	server.WriteTimeout = time.Duration(config.Data.ServerConf.WriteTimeoutInSeconds) * time.Second
+	server.IdleTimeout = time.Duration(config.Data.ServerConf.IdleTimeoutInSeconds) * time.Second
+}
+
+// TranslateSouthBoundPath translates the path of a southbound URL using the SouthBoundURL
+// translation followed by the SouthBoundRules in the configured order, as compiled on load
+func TranslateSouthBoundPath(path string) string {
+	return config.Data.URLTranslation.SouthBound().Translate(path)
+}
+
 // TrackConfigFileChanges monitors the config changes using fsnotfiy
 func TrackConfigFileChanges(configFilePath string) {
 	watcher, err := fsnotify.NewWatcher()
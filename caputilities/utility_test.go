@@ -0,0 +1,232 @@
+//(C) Copyright [2020] Hewlett Packard Enterprise Development LP
+//
+//Licensed under the Apache License, Version 2.0 (the "License"); you may
+//not use this file except in compliance with the License. You may obtain
+//a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+//Unless required by applicable law or agreed to in writing, software
+//distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+//WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+//License for the specific language governing permissions and limitations
+// under the License.
+
+package caputilities
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ODIM-Project/PluginCiscoACI/config"
+)
+
+func TestSetServerTimeouts(t *testing.T) {
+	config.SetUpMockConfig(t)
+	config.Data.ServerConf = &config.ServerConf{
+		ReadTimeoutInSeconds:  1,
+		WriteTimeoutInSeconds: 1,
+		IdleTimeoutInSeconds:  1,
+	}
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/slow" {
+			time.Sleep(2 * time.Second)
+		}
+		w.Write([]byte("done"))
+	}))
+	SetServerTimeouts(server.Config)
+	if server.Config.WriteTimeout != time.Second {
+		t.Fatalf("WriteTimeout = %v, want %v", server.Config.WriteTimeout, time.Second)
+	}
+	server.Start()
+	defer server.Close()
+
+	resp, err := http.Get(server.URL + "/fast")
+	if err != nil {
+		t.Fatalf("request within the write timeout failed: %v", err)
+	}
+	body, _ := ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	if string(body) != "done" {
+		t.Errorf("body = %q, want %q", body, "done")
+	}
+
+	// the response of a handler exceeding the write timeout must be terminated
+	resp, err = http.Get(server.URL + "/slow")
+	if err == nil {
+		body, err = ioutil.ReadAll(resp.Body)
+		resp.Body.Close()
+		if err == nil && string(body) == "done" {
+			t.Error("response written after the write timeout was expected to be terminated")
+		}
+	}
+}
+
+func TestJitteredInterval(t *testing.T) {
+	config.SetUpMockConfig(t)
+	config.Data.APICConf.RefreshJitter = 0.2
+	interval := 45 * time.Second
+	// the refreshes of the subscriptions started at once are spread out over the jitter
+	refreshes := make(map[time.Duration]bool)
+	shortest, longest := interval, time.Duration(0)
+	for i := 0; i < 100; i++ {
+		got := JitteredInterval(interval)
+		if got > interval || got < interval-9*time.Second {
+			t.Fatalf("JitteredInterval() = %v, want between %v and %v", got, interval-9*time.Second, interval)
+		}
+		refreshes[got] = true
+		if got < shortest {
+			shortest = got
+		}
+		if got > longest {
+			longest = got
+		}
+	}
+	if len(refreshes) < 90 {
+		t.Errorf("JitteredInterval() returned %d distinct intervals of 100, want the refreshes spread out", len(refreshes))
+	}
+	if longest-shortest < 4*time.Second {
+		t.Errorf("JitteredInterval() ranged over %v, want the refreshes spread over the jitter of 9s", longest-shortest)
+	}
+
+	// the bounds of the jitter
+	defer func(fraction func() float64) { jitterFraction = fraction }(jitterFraction)
+	jitterFraction = func() float64 { return 0 }
+	if got := JitteredInterval(interval); got != interval {
+		t.Errorf("JitteredInterval() = %v, want %v", got, interval)
+	}
+	jitterFraction = func() float64 { return 0.5 }
+	if got := JitteredInterval(interval); got != interval-4500*time.Millisecond {
+		t.Errorf("JitteredInterval() = %v, want %v", got, interval-4500*time.Millisecond)
+	}
+}
+
+func TestDisplayPortID(t *testing.T) {
+	config.SetUpMockConfig(t)
+	defer func() { config.Data.APICConf.PortIDFormat = config.DefaultPortIDFormat }()
+	tests := []struct {
+		format, portID, want string
+	}{
+		{config.DefaultPortIDFormat, "eth1/5", "eth1/5"},
+		{"{id}", "eth1/5", "1/5"},
+		{"Ethernet{id}", "eth1/5", "Ethernet1/5"},
+		{"Ethernet{id}", "eth1/1/3", "Ethernet1/1/3"},
+		{"Ethernet{id}", "po1", "po1"},
+	}
+	for _, tt := range tests {
+		config.Data.APICConf.PortIDFormat = tt.format
+		if got := DisplayPortID(tt.portID); got != tt.want {
+			t.Errorf("DisplayPortID(%s) with format %s = %s, want %s", tt.portID, tt.format, got, tt.want)
+		}
+	}
+}
+
+func TestTranslateSouthBoundPath(t *testing.T) {
+	config.SetUpMockConfig(t)
+	defer func() {
+		config.Data.URLTranslation.SouthBoundRules = nil
+		config.Data.URLTranslation.CompileSouthBound()
+	}()
+
+	// default single swap
+	if got := TranslateSouthBoundPath("/redfish/v1/Systems/sysUUID.1/EthernetInterfaces/1"); got != "/ODIM/v1/Systems/sysUUID.1/EthernetInterfaces/1" {
+		t.Errorf("TranslateSouthBoundPath() = %s, want the ODIM path", got)
+	}
+
+	// ODIM proxied behind a path prefix
+	config.Data.URLTranslation.SouthBoundRules = []config.URLRewriteRule{
+		{Action: config.URLRewriteStripPrefix, Match: "/ODIM"},
+		{Action: config.URLRewriteAddPrefix, Value: "/redfish"},
+		{Action: config.URLRewriteAddPrefix, Value: "/proxy/odim"},
+		{Action: config.URLRewriteReplace, Match: "sysUUID.1", Value: "sysUUID.2"},
+	}
+	config.Data.URLTranslation.CompileSouthBound()
+	if got := TranslateSouthBoundPath("/redfish/v1/Systems/sysUUID.1/EthernetInterfaces/1"); got != "/proxy/odim/redfish/v1/Systems/sysUUID.2/EthernetInterfaces/1" {
+		t.Errorf("TranslateSouthBoundPath() = %s, want the rules applied in order", got)
+	}
+}
+
+func TestTranslateSouthBoundPathMultipleRules(t *testing.T) {
+	config.SetUpMockConfig(t)
+	defer func() {
+		config.Data.URLTranslation.SouthBoundURL = map[string]string{"redfish": "ODIM"}
+		config.Data.URLTranslation.SouthBoundRules = nil
+		config.Data.URLTranslation.CompileSouthBound()
+	}()
+	config.Data.URLTranslation.SouthBoundURL = map[string]string{
+		"redfish":      "ODIM",
+		"redfish/v1":   "ODIM/v2",
+		"EthernetPort": "EthernetInterface",
+	}
+	config.Data.URLTranslation.SouthBoundRules = []config.URLRewriteRule{
+		{Action: config.URLRewriteReplace, Match: "ODIM", Value: "odim"},
+		{Action: config.URLRewriteReplace, Match: "odim/v2", Value: "odim/v1"},
+		{Action: config.URLRewriteAddPrefix, Value: "/proxy"},
+		{Action: config.URLRewriteStripPrefix, Match: "/proxy/odim"},
+		{Action: config.URLRewriteAddPrefix, Value: "/gateway"},
+	}
+	config.Data.URLTranslation.CompileSouthBound()
+	// the longest translation is applied first whatever the map order, the rules follow in order
+	want := "/gateway/v1/Systems/sys.1/EthernetInterfaces/1"
+	for i := 0; i < 10; i++ {
+		if got := TranslateSouthBoundPath("/redfish/v1/Systems/sys.1/EthernetPorts/1"); got != want {
+			t.Fatalf("TranslateSouthBoundPath() = %s, want %s", got, want)
+		}
+	}
+	// the translation changed by a configuration reload applies once compiled again
+	config.Data.URLTranslation.SouthBoundRules = nil
+	config.Data.URLTranslation.CompileSouthBound()
+	if got := TranslateSouthBoundPath("/redfish/v1/Systems"); got != "/ODIM/v2/Systems" {
+		t.Errorf("TranslateSouthBoundPath() after reload = %s, want /ODIM/v2/Systems", got)
+	}
+}
+
+// benchmarkSouthBoundRules is an ODIM proxied behind a gateway with a few rewrites
+var benchmarkSouthBoundRules = []config.URLRewriteRule{
+	{Action: config.URLRewriteStripPrefix, Match: "/ODIM"},
+	{Action: config.URLRewriteAddPrefix, Value: "/redfish"},
+	{Action: config.URLRewriteReplace, Match: "EthernetInterfaces", Value: "NetworkInterfaces"},
+	{Action: config.URLRewriteReplace, Match: "/v1/", Value: "/v1.1/"},
+	{Action: config.URLRewriteAddPrefix, Value: "/proxy/odim"},
+}
+
+const benchmarkSouthBoundPath = "/redfish/v1/Systems/6d7e1a5e-2d22-4b5e-9bd3-5f4c5b3e1f2a.1/EthernetInterfaces/1"
+
+// BenchmarkTranslateSouthBoundPathPerRequest interprets the translation and the rules for every path
+func BenchmarkTranslateSouthBoundPathPerRequest(b *testing.B) {
+	translation := map[string]string{"redfish": "ODIM"}
+	for i := 0; i < b.N; i++ {
+		path := benchmarkSouthBoundPath
+		for key, value := range translation {
+			path = strings.Replace(path, key, value, -1)
+		}
+		for _, rule := range benchmarkSouthBoundRules {
+			switch rule.Action {
+			case config.URLRewriteReplace:
+				path = strings.Replace(path, rule.Match, rule.Value, -1)
+			case config.URLRewriteAddPrefix:
+				path = rule.Value + path
+			case config.URLRewriteStripPrefix:
+				path = strings.TrimPrefix(path, rule.Match)
+			}
+		}
+	}
+}
+
+// BenchmarkTranslateSouthBoundPath translates the paths with the translation and the rules compiled once
+func BenchmarkTranslateSouthBoundPath(b *testing.B) {
+	defer func(translation *config.URLTranslation) { config.Data.URLTranslation = translation }(config.Data.URLTranslation)
+	config.Data.URLTranslation = &config.URLTranslation{
+		SouthBoundURL:   map[string]string{"redfish": "ODIM"},
+		SouthBoundRules: benchmarkSouthBoundRules,
+	}
+	config.Data.URLTranslation.CompileSouthBound()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		TranslateSouthBoundPath(benchmarkSouthBoundPath)
+	}
+}
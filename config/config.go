@@ -19,21 +19,42 @@ import (
 	"crypto/rand"
 	"crypto/rsa"
 	"crypto/sha512"
+	"crypto/tls"
 	"crypto/x509"
 	"encoding/base64"
 	"encoding/json"
 	"encoding/pem"
 	"fmt"
 	"io/ioutil"
+	"net"
 	"os"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+	"sync/atomic"
+	"unicode"
+	"unicode/utf8"
 
+	"github.com/ODIM-Project/ODIM/lib-dmtf/model"
 	lutilconf "github.com/ODIM-Project/ODIM/lib-utilities/config"
 	log "github.com/sirupsen/logrus"
+	"golang.org/x/crypto/sha3"
 )
 
 // Data will have the configuration data from config file
 var Data configModel
 
+// current is the configuration last loaded. A reload publishes it as a whole once it is validated, so
+// the readers of every request, like the maintenance mode, never see a configuration partially applied.
+var current atomic.Pointer[configModel]
+
+// hostNamePattern is the format of the host names as defined by RFC 1123
+var hostNamePattern = regexp.MustCompile(`^([a-zA-Z0-9]([a-zA-Z0-9-]{0,61}[a-zA-Z0-9])?)(\.[a-zA-Z0-9]([a-zA-Z0-9-]{0,61}[a-zA-Z0-9])?)*$`)
+
+// apicNamePattern is the format of the APIC object names, like the tenant name
+var apicNamePattern = regexp.MustCompile(`^[a-zA-Z0-9_.:-]{1,64}$`)
+
 // configModel is for holding all the run time configurations for the svc-redfish-plugin
 type configModel struct {
 	FirmwareVersion         string            `json:"FirmwareVersion"` //FirmwareVersion of plugin of the plugin
@@ -49,6 +70,15 @@ type configModel struct {
 	TLSConf                 *TLSConf          `json:"TLSConf"`
 	APICConf                *APICConf         `json:"APICConf"`
 	ODIMConf                *ODIMConf         `json:"ODIMConf"`
+	CORSConf                *CORSConf         `json:"CORSConf"`
+	ServerConf              *ServerConf       `json:"ServerConf"`
+	ClientConf              *ClientConf       `json:"ClientConf"`
+	OTelConf                *OTelConf         `json:"OTelConf"`
+	AuditConf               *AuditConf        `json:"AuditConf"`
+	WritablePortProperties  []string          `json:"WritablePortProperties"` //Port properties which can be modified with PATCH
+	// RefreshSessionOnActivity restarts the session time out on every use of the session token, which
+	// is the default. When false the session expires SessionTimeoutInMinutes after its creation.
+	RefreshSessionOnActivity *bool `json:"RefreshSessionOnActivity"`
 }
 
 // DBConf holds all DB related configurations
@@ -63,15 +93,74 @@ type DBConf struct {
 	MasterSet                    string `json:"MasterSet"`
 	RedisOnDiskEncryptedPassword string `json:"RedisOnDiskEncryptedPassword"`
 	RedisOnDiskPassword          []byte
+	// KeyPrefix is prepended as is to all the keys of the plugin, like prod:aci:, for sharing
+	// the Redis instance with other plugins and environments
+	KeyPrefix string `json:"KeyPrefix"`
 }
 
 //PluginConf is for holding all the plugin related configurations
 type PluginConf struct {
-	ID       string `json:"ID"` // PluginID hold the id of the plugin
-	Host     string `json:"Host"`
-	Port     string `json:"Port"`
-	UserName string `json:"UserName"`
-	Password string `json:"Password"`
+	ID       string        `json:"ID"`   // PluginID hold the id of the plugin
+	Host     BindAddresses `json:"Host"` // addresses the plugin listens on, the first one is used by ODIMRA
+	Port     string        `json:"Port"`
+	UserName string        `json:"UserName"`
+	Password string        `json:"Password"`
+	// PasswordPolicy holds the complexity rules of the plugin password, not enforced when not provided
+	PasswordPolicy *PasswordPolicy `json:"PasswordPolicy"`
+	// EncryptedPassword is the plugin password encrypted with the RSA public key of KeyCertConf, like the
+	// DB password, required when the PasswordPolicy is enabled as the policy can't be checked on the hash
+	EncryptedPassword string `json:"EncryptedPassword"`
+	// Vendor, Model and Name are reported on the manager of the plugin for the inventory tools
+	Vendor string `json:"Vendor"`
+	Model  string `json:"Model"`
+	Name   string `json:"Name"`
+	// PortEventsPort is the port of the dedicated listener of the port event stream, which has no write
+	// timeout. The stream is served on Port and closed after the write timeout when not provided.
+	PortEventsPort string `json:"PortEventsPort"`
+}
+
+// BindAddresses is the list of addresses to listen on, which can be configured
+// as a single address or as a list of addresses, like the IPv4 and IPv6 ones
+type BindAddresses []string
+
+// UnmarshalJSON reads the addresses from a string or a list of strings
+func (a *BindAddresses) UnmarshalJSON(data []byte) error {
+	var address string
+	if err := json.Unmarshal(data, &address); err == nil {
+		*a = nil
+		if address != "" {
+			*a = BindAddresses{address}
+		}
+		return nil
+	}
+	var addresses []string
+	if err := json.Unmarshal(data, &addresses); err != nil {
+		return fmt.Errorf("addresses should be a string or a list of strings: %v", err)
+	}
+	*a = addresses
+	return nil
+}
+
+// First returns the first address, empty when there are none
+func (a BindAddresses) First() string {
+	if len(a) == 0 {
+		return ""
+	}
+	return a[0]
+}
+
+// PasswordPolicy holds the complexity rules of the plugin username and password.
+// The password is configured as a SHA3-512 hash, hence the rules are applied on the
+// EncryptedPassword when the configuration is loaded, the plugin fails to start when
+// the password is too weak.
+type PasswordPolicy struct {
+	Enabled           bool `json:"Enabled"`
+	MinUserNameLength int  `json:"MinUserNameLength"`
+	MinLength         int  `json:"MinLength"`
+	RequireUppercase  bool `json:"RequireUppercase"`
+	RequireLowercase  bool `json:"RequireLowercase"`
+	RequireDigit      bool `json:"RequireDigit"`
+	RequireSpecial    bool `json:"RequireSpecial"`
 }
 
 //LoadBalancerConf is for holding all load balancer related configurations
@@ -92,6 +181,18 @@ type MessageBusConf struct {
 	MessageQueueConfigFilePath string   `json:"MessageQueueConfigFilePath"` // Message Queue Config File Path
 	EmbType                    string   `json:"MessageBusType"`
 	EmbQueue                   []string `json:"MessageBusQueue"`
+	// EventBufferCapacity is the number of events buffered for the publisher, events produced
+	// while the buffer is full are handled according to EventOverflowPolicy
+	EventBufferCapacity int `json:"EventBufferCapacity"`
+	// EventOverflowPolicy is DropOldest or Block, Block waits for EventBlockTimeoutInSeconds
+	// for room in the buffer before dropping the produced event
+	EventOverflowPolicy        string `json:"EventOverflowPolicy"`
+	EventBlockTimeoutInSeconds int    `json:"EventBlockTimeoutInSeconds"`
+	// EventBacklogCapacity is the number of events kept in the DB while the message bus is unavailable,
+	// the oldest event is dropped for a new one when the backlog is full
+	EventBacklogCapacity int `json:"EventBacklogCapacity"`
+	// EventBacklogRetryIntervalInSeconds is the interval of the retries of publishing the backlog
+	EventBacklogRetryIntervalInSeconds int `json:"EventBacklogRetryIntervalInSeconds"`
 }
 
 //KeyCertConf is for holding all security oriented configuration
@@ -110,6 +211,20 @@ type KeyCertConf struct {
 type URLTranslation struct {
 	NorthBoundURL map[string]string `json:"NorthBoundURL"` // holds value of NorthBound Translation
 	SouthBoundURL map[string]string `json:"SouthBoundURL"` // holds value of SouthBound Translation
+	// SouthBoundRules are applied in order on the southbound paths after the SouthBoundURL translation
+	SouthBoundRules []URLRewriteRule `json:"SouthBoundRules"`
+	// southBound is the southbound translation compiled when the configuration is loaded
+	southBound *SouthBoundTranslator
+}
+
+// URLRewriteRule is a rewrite of the URL path, the Action is one of
+// Replace - replaces all occurrences of Match with Value
+// AddPrefix - prepends Value to the path
+// StripPrefix - removes Match from the start of the path
+type URLRewriteRule struct {
+	Action string `json:"Action"`
+	Match  string `json:"Match"`
+	Value  string `json:"Value"`
 }
 
 // TLSConf holds TLS confifurations used in https queries
@@ -120,12 +235,91 @@ type TLSConf struct {
 	PreferredCipherSuites []string `json:"PreferredCipherSuites"`
 }
 
+// PortOperState is the Redfish link state, link status and state a port is reported with for an APIC operSt value
+type PortOperState struct {
+	LinkState  string `json:"LinkState"`
+	LinkStatus string `json:"LinkStatus"`
+	State      string `json:"State"`
+}
+
 //APICConf is for holding all the cisco APIC related configurations
 type APICConf struct {
-	APICHost   string            `json:"APICHost"`
-	UserName   string            `json:"UserName"`
-	Password   string            `json:"Password"`
-	DomainData map[string]string `json:"DomainData"`
+	APICHost              string            `json:"APICHost"`
+	UserName              string            `json:"UserName"`
+	Password              string            `json:"Password"`
+	DomainData            map[string]string `json:"DomainData"`
+	VerifyPortExistence   bool              `json:"VerifyPortExistence"`   // verify port is still present in APIC before storing it during discovery
+	DisableLiveEnrichment bool              `json:"DisableLiveEnrichment"` // serve port data only from the DB without querying APIC
+	// DefaultDomain is the key of DomainData used when no domain is mapped for the key requested
+	DefaultDomain string `json:"DefaultDomain"`
+	// SubscriptionClasses are the managed object classes subscribed over the APIC websocket
+	SubscriptionClasses          []string `json:"SubscriptionClasses"`
+	SubscriptionRefreshInSeconds int      `json:"SubscriptionRefreshInSeconds"`
+	// RefreshJitter is the largest fraction of the interval the subscription refreshes and the APIC polls
+	// are randomly shortened by, so that they spread out over the interval instead of hitting APIC at once
+	RefreshJitter float64 `json:"RefreshJitter"`
+	// Tenant scopes the tenant-scopable APIC queries, the fabric health and the health and faults of the ports,
+	// to the objects of the tenant, queries are fabric-wide when not set
+	Tenant string `json:"Tenant"`
+	// UnknownHealthPolicy is the health reported for the ports without health score in APIC, OK, Warning or Ignore
+	UnknownHealthPolicy string `json:"UnknownHealthPolicy"`
+	// UnavailableHealthPolicy is the health reported for the ports whose health can't be read from APIC,
+	// OK, Warning or Ignore
+	UnavailableHealthPolicy string `json:"UnavailableHealthPolicy"`
+	// PortOperStates overrides the Redfish state reported for the APIC operSt values of the ports
+	PortOperStates map[string]PortOperState `json:"PortOperStates"`
+	// PortFlapGraceInSeconds is the time a port has to stay down or Critical before it is reported so,
+	// the previous state is reported meanwhile. The state is reported immediately when not set.
+	PortFlapGraceInSeconds int `json:"PortFlapGraceInSeconds"`
+	// PortStatsHistoryMaxSamples is the largest number of samples returned for the statistics history of a port
+	PortStatsHistoryMaxSamples int `json:"PortStatsHistoryMaxSamples"`
+	// PortResetEnabled allows the ports to be reset with the Port.Reset action, which takes the port out of service in APIC
+	PortResetEnabled bool `json:"PortResetEnabled"`
+	// PortSettingsReconcileIntervalInSeconds is the interval at which the pending settings of the ports are checked against APIC
+	PortSettingsReconcileIntervalInSeconds int `json:"PortSettingsReconcileIntervalInSeconds"`
+	// PortMetricReportIntervalInSeconds is the interval at which the metric report of the port counters is published
+	// on the message bus, the report is only generated when requested when not set
+	PortMetricReportIntervalInSeconds int `json:"PortMetricReportIntervalInSeconds"`
+	// LoginDomain is the authentication domain the APIC user logs in to, the default domain of APIC is used when not set
+	LoginDomain string `json:"LoginDomain"`
+	// APIBasePath is the path the APIC REST API is served under, like /api
+	APIBasePath string `json:"APIBasePath"`
+	// TLSServerName is the name the certificate of APIC is verified against, for APIC reached through
+	// an address which is not in its certificate, like a VIP. APICHost is verified when not set.
+	TLSServerName string `json:"TLSServerName"`
+	// RequestsPerSecond limits the rate of the requests made to APIC by the plugin, requests are not limited when not set
+	RequestsPerSecond float64 `json:"RequestsPerSecond"`
+	// RequestBurst is the number of requests which can be made to APIC at once above the rate
+	RequestBurst int `json:"RequestBurst"`
+	// RateLimitWaitInMilliseconds is the longest time a request waits for the rate limit before it is rejected
+	RateLimitWaitInMilliseconds int `json:"RateLimitWaitInMilliseconds"`
+	// ClusterHosts are the other controllers of the APIC cluster, the fabric topology is read from them too with QuorumReads
+	ClusterHosts []string `json:"ClusterHosts"`
+	// QuorumReads reads the fabric topology during the discovery from two controllers of the cluster in quorum which
+	// agree on it, so that stale data isn't read from a controller during a cluster transition. It doubles the reads.
+	QuorumReads bool `json:"QuorumReads"`
+	// BalanceReads spreads the reads made to APIC over APICHost and ClusterHosts in proportion to HostWeights,
+	// a read failing on a controller is retried on the others. The writes are always made to APICHost.
+	BalanceReads bool `json:"BalanceReads"`
+	// HostWeights are the weights of the controllers, APICHost or ClusterHosts, the reads are spread by
+	// with BalanceReads. The controllers without weight have the weight 1.
+	HostWeights map[string]int `json:"HostWeights"`
+	// TokenClockSkewInSeconds is subtracted from the expiry of the APIC token when deciding to refresh it,
+	// so that the token is refreshed early rather than used expired when the clocks of the plugin host and APIC differ
+	TokenClockSkewInSeconds int `json:"TokenClockSkewInSeconds"`
+	// QueryPageSize is the number of managed objects read per page from the class and subtree queries
+	// of APIC returning large sets, like the ports of a switch, the pages are assembled in the full result
+	QueryPageSize int `json:"QueryPageSize"`
+	// PortIDFormat is the template of the PortId of the ports reported, where {id} stands for the APIC port id
+	// without its eth prefix, like 1/5 of eth1/5. The APIC port id is kept for the calls made to APIC.
+	PortIDFormat string `json:"PortIDFormat"`
+	// HealthPollConcurrency is the number of switches whose port health is read from APIC at once when
+	// polling the health of the ports of a fabric
+	HealthPollConcurrency int `json:"HealthPollConcurrency"`
+	// DefaultPortAdminState is the admin state, Enabled or Disabled, recorded as the desired admin state of the
+	// ports when they are discovered, the baseline of the drift detection. APIC is left as it is, and no admin
+	// state is recorded when it is empty.
+	DefaultPortAdminState string `json:"DefaultPortAdminState"`
 }
 
 // ODIMConf hold the value of the ODIMConfiguration to plugin
@@ -135,192 +329,524 @@ type ODIMConf struct {
 	Password string `json:"Password"`
 }
 
+// CORSConf holds the CORS policy applied on the plugin http server
+type CORSConf struct {
+	AllowedOrigins   []string `json:"AllowedOrigins"`
+	AllowedMethods   []string `json:"AllowedMethods"`
+	AllowedHeaders   []string `json:"AllowedHeaders"`
+	AllowCredentials bool     `json:"AllowCredentials"`
+}
+
+// ServerConf holds the timeouts applied on the plugin http servers
+type ServerConf struct {
+	ReadTimeoutInSeconds  int `json:"ReadTimeoutInSeconds"`
+	WriteTimeoutInSeconds int `json:"WriteTimeoutInSeconds"`
+	IdleTimeoutInSeconds  int `json:"IdleTimeoutInSeconds"`
+	// IdempotencyKeyTTLInSeconds is how long the result of a request made with an Idempotency-Key header is replayed
+	IdempotencyKeyTTLInSeconds int `json:"IdempotencyKeyTTLInSeconds"`
+	// MaxConcurrentRequests bounds the requests handled at once, requests are not limited when not set
+	MaxConcurrentRequests int `json:"MaxConcurrentRequests"`
+	// RequestQueueTimeoutInMilliseconds is the longest time a request beyond MaxConcurrentRequests waits
+	// to be handled before it is rejected, such requests are rejected immediately when not set
+	RequestQueueTimeoutInMilliseconds int `json:"RequestQueueTimeoutInMilliseconds"`
+	// MaxPortEventStreams bounds the clients connected at once to the stream of the port events
+	MaxPortEventStreams int `json:"MaxPortEventStreams"`
+	// MaintenanceMode rejects the write requests while the fabric is under maintenance, the reads are served
+	MaintenanceMode bool `json:"MaintenanceMode"`
+	// MaintenanceReason is reported with the maintenance mode on the status and the rejected writes
+	MaintenanceReason string `json:"MaintenanceReason"`
+	// CacheMaxAgeInSeconds is the max-age of the Cache-Control of the read responses, the responses
+	// enriched with the attributes read from APIC are reused at most for as long as the plugin reuses them
+	CacheMaxAgeInSeconds int `json:"CacheMaxAgeInSeconds"`
+	// MaxPageSize is the largest $top of the collection requests, larger ones are clamped to it
+	MaxPageSize int `json:"MaxPageSize"`
+	// LogSampleRate writes the info logs of one request in LogSampleRate, the warnings and errors
+	// of all the requests are written
+	LogSampleRate int `json:"LogSampleRate"`
+	// TrailingSlashPolicy is how the request URIs with trailing slashes are matched to the routes,
+	// Ignore routes them as the URIs without the slashes and Redirect redirects to those URIs
+	TrailingSlashPolicy string `json:"TrailingSlashPolicy"`
+	// StartupAttempts is the number of times each dependency is tried at startup before the startup fails
+	StartupAttempts int `json:"StartupAttempts"`
+	// StartupRetryDelayInSeconds is the delay before the first retry of a dependency at startup,
+	// it is doubled for each following retry
+	StartupRetryDelayInSeconds int `json:"StartupRetryDelayInSeconds"`
+	// EnableHTTP2 serves HTTP/2 to the clients negotiating it over TLS, HTTP/1.1 is served when not set
+	EnableHTTP2 bool `json:"EnableHTTP2"`
+	// CompressionAlgorithms are the encodings the responses are compressed with, in the order of
+	// preference of the plugin among the ones the client accepts equally
+	CompressionAlgorithms []string `json:"CompressionAlgorithms"`
+	// CompressionMinSizeInBytes is the size of the smallest response compressed
+	CompressionMinSizeInBytes int `json:"CompressionMinSizeInBytes"`
+}
+
+// ClientConf holds the reuse of the connections of the outbound http clients, to APIC and ODIM
+type ClientConf struct {
+	// MaxIdleConns bounds the idle connections kept for reuse across all the hosts
+	MaxIdleConns int `json:"MaxIdleConns"`
+	// MaxIdleConnsPerHost bounds the idle connections kept for reuse to each host
+	MaxIdleConnsPerHost int `json:"MaxIdleConnsPerHost"`
+	// IdleConnTimeoutInSeconds is how long an idle connection is kept for reuse before it is closed
+	IdleConnTimeoutInSeconds int `json:"IdleConnTimeoutInSeconds"`
+}
+
+// OTelConf holds the distributed tracing configurations, tracing is disabled when not provided
+type OTelConf struct {
+	Enabled       bool    `json:"Enabled"`
+	ServiceName   string  `json:"ServiceName"`
+	SamplingRatio float64 `json:"SamplingRatio"` // fraction of the root spans sampled, between 0 and 1
+	Exporter      string  `json:"Exporter"`      // log or otlphttp
+	Endpoint      string  `json:"Endpoint"`      // OTLP/HTTP traces endpoint, required for otlphttp exporter
+}
+
+// AuditConf holds the audit log configurations, the write operations are not audited when not provided
+type AuditConf struct {
+	Sink     string `json:"Sink"`     // File or MessageBus
+	FilePath string `json:"FilePath"` // file the audit records are appended to, required for File sink
+	Topic    string `json:"Topic"`    // message bus topic the audit records are published to, required for MessageBus sink
+}
+
+// ValidationErrors holds all the errors found while validating the configuration, the configuration
+// is validated as a whole so that all of its errors are reported at once
+type ValidationErrors []error
+
+// Error joins the errors found
+func (e ValidationErrors) Error() string {
+	messages := make([]string, 0, len(e))
+	for _, err := range e {
+		messages = append(messages, err.Error())
+	}
+	return strings.Join(messages, "; ")
+}
+
 // SetConfiguration will extract the config data from file
 func SetConfiguration() error {
 	configFilePath := os.Getenv("PLUGIN_CONFIG_FILE_PATH")
 	if configFilePath == "" {
 		return fmt.Errorf("no value set to environment variable PLUGIN_CONFIG_FILE_PATH")
 	}
+	return loadConfiguration(configFilePath)
+}
+
+// ValidateFile loads the config file in place of the configuration read and validates it, without
+// connecting to any of the configured services. The errors found are returned as ValidationErrors.
+func ValidateFile(configFilePath string) error {
+	return loadConfiguration(configFilePath)
+}
+
+// loadConfiguration reads the config data from the file into a new configuration and validates it.
+// The configuration replaces Data only when it is valid, the keys removed from the file are reset.
+func loadConfiguration(configFilePath string) error {
 	configData, err := ioutil.ReadFile(configFilePath)
 	if err != nil {
 		return fmt.Errorf("failed to read the config file: %v", err)
 	}
-	err = json.Unmarshal(configData, &Data)
+	conf := &configModel{}
+	err = json.Unmarshal(configData, conf)
 	if err != nil {
 		return fmt.Errorf("failed to unmarshal config data: %v", err)
 	}
+	if err := conf.validate(); err != nil {
+		return err
+	}
+	Data = *conf
+	current.Store(conf)
+	return nil
+}
 
-	return ValidateConfiguration()
+// CurrentServerConf returns the ServerConf of the configuration last loaded, it is read on every
+// request by the middlewares which follow the reloads of the config file
+func CurrentServerConf() *ServerConf {
+	if conf := current.Load(); conf != nil {
+		return conf.ServerConf
+	}
+	return Data.ServerConf
 }
 
-// ValidateConfiguration will validate configurations read and assign default values, where required
+// ValidateConfiguration will validate configurations read and assign default values, where required.
+// All the errors found are returned as ValidationErrors.
 func ValidateConfiguration() error {
-	if err := lutilconf.CheckRootServiceuuid(Data.RootServiceUUID); err != nil {
-		return err
+	return Data.validate()
+}
+
+// validate validates the configuration and assigns the default values, see ValidateConfiguration
+func (c *configModel) validate() error {
+	var errs ValidationErrors
+	check := func(err error) bool {
+		if err != nil {
+			errs = append(errs, err)
+		}
+		return err == nil
 	}
-	if Data.FirmwareVersion == "" {
+	check(lutilconf.CheckRootServiceuuid(c.RootServiceUUID))
+	if c.FirmwareVersion == "" {
 		log.Info("no value set for FirmwareVersion, setting default value")
-		Data.FirmwareVersion = "1.0"
+		c.FirmwareVersion = "1.0"
 	}
-	if Data.RootServiceUUID == "" {
-		return fmt.Errorf("no value set for rootServiceUUID")
+	if c.RootServiceUUID == "" {
+		check(fmt.Errorf("no value set for rootServiceUUID"))
 	}
-	if Data.SessionTimeoutInMinutes == 0 {
+	if c.SessionTimeoutInMinutes == 0 {
 		log.Info("no value set for SessionTimeoutInMinutes, setting default value")
-		Data.SessionTimeoutInMinutes = 30
+		c.SessionTimeoutInMinutes = 30
+	}
+	if c.RefreshSessionOnActivity == nil {
+		log.Info("no value set for RefreshSessionOnActivity, setting default value")
+		refresh := true
+		c.RefreshSessionOnActivity = &refresh
+	}
+	check(c.checkPluginConf())
+	check(c.checkODIMConf())
+	// the load balancer defaults to the event listener
+	eventConfValid := check(c.checkEventConf())
+	check(c.checkMessageBusConf())
+	// the DB password is decrypted with the RSA key of KeyCertConf
+	keyCertConfValid := check(c.checkCertsAndKeysConf())
+	check(c.checkTLSConf())
+	if eventConfValid {
+		c.checkLBConf()
+	}
+	check(c.checkURLTranslationConf())
+	check(c.checkAPICConf())
+	if keyCertConfValid {
+		check(c.checkDBConf())
+		check(c.checkPluginPasswordComplexity())
+	}
+	check(c.checkCORSConf())
+	check(c.checkServerConf())
+	check(c.checkClientConf())
+	check(c.checkOTelConf())
+	check(c.checkAuditConf())
+	check(c.checkWritablePortProperties())
+	if len(errs) != 0 {
+		return errs
 	}
-	if err := checkPluginConf(); err != nil {
-		return err
+	return nil
+}
+
+func (c *configModel) checkPluginConf() error {
+	if c.PluginConf == nil {
+		return fmt.Errorf("no value found for PluginConf")
 	}
-	if err := checkODIMConf(); err != nil {
-		return err
+	if c.PluginConf.ID == "" {
+		log.Info("no value set for Plugin ID, setting default value")
+		c.PluginConf.ID = "GRF"
 	}
-	if err := checkEventConf(); err != nil {
-		return err
+	if len(c.PluginConf.Host) == 0 {
+		return fmt.Errorf("no value set for Plugin Host")
 	}
-	if err := checkMessageBusConf(); err != nil {
-		return err
+	for _, host := range c.PluginConf.Host {
+		if net.ParseIP(host) == nil && !hostNamePattern.MatchString(host) {
+			return fmt.Errorf("error: invalid address %s configured for Plugin Host", host)
+		}
 	}
-	if err := checkCertsAndKeysConf(); err != nil {
-		return err
+	if c.PluginConf.Port == "" {
+		return fmt.Errorf("no value set for Plugin Port")
 	}
-	if err := checkTLSConf(); err != nil {
-		return err
+	if c.PluginConf.PortEventsPort == c.PluginConf.Port {
+		return fmt.Errorf("error: Plugin PortEventsPort %s is already used by Plugin Port", c.PluginConf.PortEventsPort)
 	}
-	checkLBConf()
-	checkURLTranslationConf()
-	if err := checkAPICConf(); err != nil {
-		return err
+	if c.PluginConf.UserName == "" {
+		return fmt.Errorf("no value set for Plugin Username")
 	}
-	if err := checkDBConf(); err != nil {
-		return err
+	if c.PluginConf.Password == "" {
+		return fmt.Errorf("no value set for Plugin Password")
+	}
+	identity := []struct {
+		name         string
+		value        *string
+		defaultValue string
+	}{
+		{"Vendor", &c.PluginConf.Vendor, DefaultPluginVendor},
+		{"Model", &c.PluginConf.Model, DefaultPluginModel},
+		{"Name", &c.PluginConf.Name, c.PluginConf.ID},
+	}
+	for _, field := range identity {
+		if *field.value == "" {
+			log.Info("no value set for Plugin " + field.name + ", setting default value")
+			*field.value = field.defaultValue
+		} else if strings.TrimSpace(*field.value) == "" {
+			return fmt.Errorf("error: invalid value %q configured for Plugin %s, it should not be blank", *field.value, field.name)
+		}
+	}
+	return c.checkPasswordPolicy()
+}
+
+// checkPasswordPolicy validates the password policy and the plugin username against it
+func (c *configModel) checkPasswordPolicy() error {
+	policy := c.PluginConf.PasswordPolicy
+	if policy == nil || !policy.Enabled {
+		log.Warn("plugin password complexity check is disabled, enabling PluginConf.PasswordPolicy is recommended")
+		return nil
+	}
+	if policy.MinLength < 0 || policy.MinUserNameLength < 0 {
+		return fmt.Errorf("error: invalid value configured for PasswordPolicy MinLength or MinUserNameLength, it should be positive")
+	}
+	if policy.MinLength == 0 {
+		log.Info("no value set for PasswordPolicy MinLength, setting default value")
+		policy.MinLength = DefaultPasswordMinLength
+	}
+	if policy.MinUserNameLength == 0 {
+		log.Info("no value set for PasswordPolicy MinUserNameLength, setting default value")
+		policy.MinUserNameLength = DefaultUserNameMinLength
+	}
+	if len(c.PluginConf.UserName) < policy.MinUserNameLength {
+		return fmt.Errorf("error: Plugin Username violates the password policy: it should have at least %d characters", policy.MinUserNameLength)
 	}
 	return nil
 }
 
-func checkPluginConf() error {
-	if Data.PluginConf == nil {
-		return fmt.Errorf("no value found for PluginConf")
+// checkPluginPasswordComplexity validates the plugin password against the password policy, the
+// password is decrypted from EncryptedPassword and has to match the hash configured as Password
+func (c *configModel) checkPluginPasswordComplexity() error {
+	if c.PluginConf == nil || c.PluginConf.PasswordPolicy == nil || !c.PluginConf.PasswordPolicy.Enabled {
+		return nil
 	}
-	if Data.PluginConf.ID == "" {
-		log.Info("no value set for Plugin ID, setting default value")
-		Data.PluginConf.ID = "GRF"
+	if c.PluginConf.EncryptedPassword == "" {
+		return fmt.Errorf("error: no value configured for Plugin EncryptedPassword, it is required for checking the password policy")
 	}
-	if Data.PluginConf.Host == "" {
-		return fmt.Errorf("no value set for Plugin Host")
+	password, err := c.decryptRSAOAEPEncryptedPasswords(c.PluginConf.EncryptedPassword)
+	if err != nil {
+		return fmt.Errorf("error: while decrypting Plugin EncryptedPassword, got: %v", err)
 	}
-	if Data.PluginConf.Port == "" {
-		return fmt.Errorf("no value set for Plugin Port")
+	hash := sha3.New512()
+	hash.Write(password)
+	if base64.URLEncoding.EncodeToString(hash.Sum(nil)) != c.PluginConf.Password {
+		return fmt.Errorf("error: Plugin EncryptedPassword doesn't match the Plugin Password")
 	}
-	if Data.PluginConf.UserName == "" {
-		return fmt.Errorf("no value set for Plugin Username")
+	if err := c.checkPasswordComplexity(string(password)); err != nil {
+		return fmt.Errorf("error: Plugin %v", err)
 	}
-	if Data.PluginConf.Password == "" {
-		return fmt.Errorf("no value set for Plugin Password")
+	return nil
+}
+
+// CheckPasswordComplexity validates the password against the configured password policy,
+// the error returned names the rule which the password violates
+func CheckPasswordComplexity(password string) error {
+	return Data.checkPasswordComplexity(password)
+}
+
+func (c *configModel) checkPasswordComplexity(password string) error {
+	if c.PluginConf == nil || c.PluginConf.PasswordPolicy == nil || !c.PluginConf.PasswordPolicy.Enabled {
+		return nil
+	}
+	policy := c.PluginConf.PasswordPolicy
+	if utf8.RuneCountInString(password) < policy.MinLength {
+		return fmt.Errorf("password violates the password policy: it should have at least %d characters", policy.MinLength)
+	}
+	var hasUpper, hasLower, hasDigit, hasSpecial bool
+	for _, char := range password {
+		switch {
+		case unicode.IsUpper(char):
+			hasUpper = true
+		case unicode.IsLower(char):
+			hasLower = true
+		case unicode.IsDigit(char):
+			hasDigit = true
+		case unicode.IsPunct(char) || unicode.IsSymbol(char):
+			hasSpecial = true
+		}
+	}
+	rules := []struct {
+		required  bool
+		satisfied bool
+		rule      string
+	}{
+		{policy.RequireUppercase, hasUpper, "an uppercase letter"},
+		{policy.RequireLowercase, hasLower, "a lowercase letter"},
+		{policy.RequireDigit, hasDigit, "a digit"},
+		{policy.RequireSpecial, hasSpecial, "a special character"},
+	}
+	for _, r := range rules {
+		if r.required && !r.satisfied {
+			return fmt.Errorf("password violates the password policy: it should contain at least %s", r.rule)
+		}
 	}
 	return nil
 }
 
-func checkODIMConf() error {
-	if Data.ODIMConf == nil {
+func (c *configModel) checkODIMConf() error {
+	if c.ODIMConf == nil {
 		return fmt.Errorf("no value found for ODIMConf")
 	}
-	if Data.ODIMConf.URL == "" {
+	if c.ODIMConf.URL == "" {
 		return fmt.Errorf("no value set for ODIM URL")
 	}
-	if Data.ODIMConf.Password == "" {
+	if c.ODIMConf.Password == "" {
 		return fmt.Errorf("no value set for ODIM Password")
 	}
-	if Data.ODIMConf.UserName == "" {
+	if c.ODIMConf.UserName == "" {
 		return fmt.Errorf("no value set for ODIM Username")
 	}
 	return nil
 }
 
 //check load balancer configuration
-func checkLBConf() {
-	if Data.LoadBalancerConf == nil {
+func (c *configModel) checkLBConf() {
+	if c.LoadBalancerConf == nil {
 		log.Info("no value set for LoadBalancerConf, setting default value")
-		Data.LoadBalancerConf = &LoadBalancerConf{
-			Host: Data.EventConf.ListenerHost,
-			Port: Data.EventConf.ListenerPort,
+		c.LoadBalancerConf = &LoadBalancerConf{
+			Host: c.EventConf.ListenerHost,
+			Port: c.EventConf.ListenerPort,
 		}
 		return
 	}
-	if Data.LoadBalancerConf.Host == "" || Data.LoadBalancerConf.Port == "" {
+	if c.LoadBalancerConf.Host == "" || c.LoadBalancerConf.Port == "" {
 		log.Info("no value set for LBHost/LBPort, setting ListenerHost/ListenerPort value")
-		Data.LoadBalancerConf.Host = Data.EventConf.ListenerHost
-		Data.LoadBalancerConf.Port = Data.EventConf.ListenerPort
+		c.LoadBalancerConf.Host = c.EventConf.ListenerHost
+		c.LoadBalancerConf.Port = c.EventConf.ListenerPort
 	}
 }
 
-func checkEventConf() error {
-	if Data.EventConf == nil {
+func (c *configModel) checkEventConf() error {
+	if c.EventConf == nil {
 		return fmt.Errorf("no value found for EventConf")
 	}
-	if Data.EventConf.DestURI == "" {
+	if c.EventConf.DestURI == "" {
 		return fmt.Errorf("no value set for EventURI")
 	}
-	if Data.EventConf.ListenerHost == "" {
+	if c.EventConf.ListenerHost == "" {
 		return fmt.Errorf("no value set for ListenerHost")
 	}
-	if Data.EventConf.ListenerPort == "" {
+	if c.EventConf.ListenerPort == "" {
 		return fmt.Errorf("no value set for ListenerPort")
 	}
 	return nil
 }
 
 //Check or apply default values for message bus to be used by this plugin
-func checkMessageBusConf() error {
-	if Data.MessageBusConf == nil {
+func (c *configModel) checkMessageBusConf() error {
+	if c.MessageBusConf == nil {
 		return fmt.Errorf("no value found for MessageBusConf")
 	}
-	if Data.MessageBusConf.EmbType == "" {
+	if c.MessageBusConf.EmbType == "" {
 		log.Warn("No value set for MessageBusType, setting default value")
-		Data.MessageBusConf.EmbType = "Kafka"
+		c.MessageBusConf.EmbType = "Kafka"
 	}
-	if _, err := os.Stat(Data.MessageBusConf.MessageQueueConfigFilePath); err != nil {
-		return fmt.Errorf("Value check failed for MessageQueueConfigFilePath:%s with %v", Data.MessageBusConf.MessageQueueConfigFilePath, err)
+	if _, err := os.Stat(c.MessageBusConf.MessageQueueConfigFilePath); err != nil {
+		return fmt.Errorf("Value check failed for MessageQueueConfigFilePath:%s with %v", c.MessageBusConf.MessageQueueConfigFilePath, err)
 	}
-	if len(Data.MessageBusConf.EmbQueue) <= 0 {
+	if len(c.MessageBusConf.EmbQueue) <= 0 {
 		log.Warn("No value set for MessageBusQueue, setting default value")
-		Data.MessageBusConf.EmbQueue = []string{"REDFISH-EVENTS-TOPIC"}
+		c.MessageBusConf.EmbQueue = []string{"REDFISH-EVENTS-TOPIC"}
 	}
-	if !AllowedMessageBusTypes[Data.MessageBusConf.EmbType] {
+	if !AllowedMessageBusTypes[c.MessageBusConf.EmbType] {
 		return fmt.Errorf("error: invalid value configured for MessageBusType")
 	}
+	return checkEventBufferConf(c.MessageBusConf)
+}
+
+// checkEventBufferConf applies the default values of the buffer of the events to be published
+func checkEventBufferConf(conf *MessageBusConf) error {
+	if conf.EventBufferCapacity < 0 {
+		return fmt.Errorf("error: invalid value %d configured for EventBufferCapacity", conf.EventBufferCapacity)
+	}
+	if conf.EventBufferCapacity == 0 {
+		log.Warn("No value set for EventBufferCapacity, setting default value")
+		conf.EventBufferCapacity = DefaultEventBufferCapacity
+	}
+	if conf.EventOverflowPolicy == "" {
+		log.Warn("No value set for EventOverflowPolicy, setting default value")
+		conf.EventOverflowPolicy = EventOverflowDropOldest
+	}
+	if conf.EventOverflowPolicy != EventOverflowDropOldest && conf.EventOverflowPolicy != EventOverflowBlock {
+		return fmt.Errorf("error: invalid value %s configured for EventOverflowPolicy, it should be %s or %s",
+			conf.EventOverflowPolicy, EventOverflowDropOldest, EventOverflowBlock)
+	}
+	if conf.EventBlockTimeoutInSeconds < 0 {
+		return fmt.Errorf("error: invalid value %d configured for EventBlockTimeoutInSeconds", conf.EventBlockTimeoutInSeconds)
+	}
+	if conf.EventBlockTimeoutInSeconds == 0 {
+		conf.EventBlockTimeoutInSeconds = DefaultEventBlockTimeout
+	}
+	if conf.EventBacklogCapacity < 0 {
+		return fmt.Errorf("error: invalid value %d configured for EventBacklogCapacity", conf.EventBacklogCapacity)
+	}
+	if conf.EventBacklogCapacity == 0 {
+		log.Warn("No value set for EventBacklogCapacity, setting default value")
+		conf.EventBacklogCapacity = DefaultEventBacklogCapacity
+	}
+	if conf.EventBacklogRetryIntervalInSeconds < 0 {
+		return fmt.Errorf("error: invalid value %d configured for EventBacklogRetryIntervalInSeconds", conf.EventBacklogRetryIntervalInSeconds)
+	}
+	if conf.EventBacklogRetryIntervalInSeconds == 0 {
+		conf.EventBacklogRetryIntervalInSeconds = DefaultEventBacklogRetryInterval
+	}
 
 	return nil
 }
 
 //Check or apply default values for certs/keys used by this plugin
-func checkCertsAndKeysConf() error {
+func (c *configModel) checkCertsAndKeysConf() error {
 	var err error
-	if Data.KeyCertConf == nil {
+	if c.KeyCertConf == nil {
 		return fmt.Errorf("no value found for KeyCertConf")
 	}
-	if Data.KeyCertConf.Certificate, err = ioutil.ReadFile(Data.KeyCertConf.CertificatePath); err != nil {
-		return fmt.Errorf("value check failed for CertificatePath:%s with %v", Data.KeyCertConf.CertificatePath, err)
+	if c.KeyCertConf.Certificate, err = ioutil.ReadFile(c.KeyCertConf.CertificatePath); err != nil {
+		return fmt.Errorf("value check failed for CertificatePath:%s with %v", c.KeyCertConf.CertificatePath, err)
+	}
+	if c.KeyCertConf.PrivateKey, err = ioutil.ReadFile(c.KeyCertConf.PrivateKeyPath); err != nil {
+		return fmt.Errorf("value check failed for PrivateKeyPath:%s with %v", c.KeyCertConf.PrivateKeyPath, err)
+	}
+	if err = checkKeyPairMatch(c.KeyCertConf.Certificate, c.KeyCertConf.PrivateKey); err != nil {
+		return fmt.Errorf("value check failed for CertificatePath:%s and PrivateKeyPath:%s with %v",
+			c.KeyCertConf.CertificatePath, c.KeyCertConf.PrivateKeyPath, err)
+	}
+
+	if c.KeyCertConf.RootCACertificate, err = ioutil.ReadFile(c.KeyCertConf.RootCACertificatePath); err != nil {
+		return fmt.Errorf("value check failed for RootCACertificatePath:%s with %v", c.KeyCertConf.RootCACertificatePath, err)
 	}
-	if Data.KeyCertConf.PrivateKey, err = ioutil.ReadFile(Data.KeyCertConf.PrivateKeyPath); err != nil {
-		return fmt.Errorf("value check failed for PrivateKeyPath:%s with %v", Data.KeyCertConf.PrivateKeyPath, err)
+	if c.KeyCertConf.RSAPrivateKey, err = ioutil.ReadFile(c.KeyCertConf.RSAPrivateKeyPath); err != nil {
+		return fmt.Errorf("value check failed for RSAPrivateKeyPath:%s with %v", c.KeyCertConf.RSAPrivateKeyPath, err)
 	}
+	if err = checkRSAPrivateKey(c.KeyCertConf.RSAPrivateKey); err != nil {
+		if c.KeyCertConf.RSAPrivateKeyPath == c.KeyCertConf.PrivateKeyPath {
+			return fmt.Errorf("value check failed for RSAPrivateKeyPath:%s with %v, it is the same file as PrivateKeyPath: "+
+				"RSAPrivateKeyPath must be an RSA key used for decrypting the passwords, distinct from the TLS key of the plugin",
+				c.KeyCertConf.RSAPrivateKeyPath, err)
+		}
+		return fmt.Errorf("value check failed for RSAPrivateKeyPath:%s with %v, it must be an RSA key used for decrypting the passwords",
+			c.KeyCertConf.RSAPrivateKeyPath, err)
+	}
+
+	return nil
+}
 
-	if Data.KeyCertConf.RootCACertificate, err = ioutil.ReadFile(Data.KeyCertConf.RootCACertificatePath); err != nil {
-		return fmt.Errorf("value check failed for RootCACertificatePath:%s with %v", Data.KeyCertConf.RootCACertificatePath, err)
+// checkRSAPrivateKey verifies that the key is a PKCS1 RSA private key, as the encrypted passwords
+// are decrypted with it. Keys of other types are otherwise reported only when a password is decrypted.
+func checkRSAPrivateKey(keyPEM []byte) error {
+	block, _ := pem.Decode(keyPEM)
+	if block == nil {
+		return fmt.Errorf("key is not PEM encoded")
 	}
-	if Data.KeyCertConf.RSAPrivateKey, err = ioutil.ReadFile(Data.KeyCertConf.RSAPrivateKeyPath); err != nil {
-		return fmt.Errorf("value check failed for RSAPrivateKeyPath:%s with %v", Data.KeyCertConf.RSAPrivateKeyPath, err)
+	if _, err := bytesToPrivateKey(keyPEM); err != nil {
+		return fmt.Errorf("%s is not a PKCS1 RSA private key: %v", block.Type, err)
 	}
+	return nil
+}
 
+// checkKeyPairMatch verifies that the private key corresponds to the public key of the certificate,
+// so that a mismatched pair is reported during validation rather than at the TLS handshake
+func checkKeyPairMatch(certPEM, keyPEM []byte) error {
+	certBlock, _ := pem.Decode(certPEM)
+	if certBlock == nil {
+		return fmt.Errorf("certificate is not PEM encoded")
+	}
+	cert, err := x509.ParseCertificate(certBlock.Bytes)
+	if err != nil {
+		return fmt.Errorf("unable to parse certificate: %v", err)
+	}
+	if _, err = tls.X509KeyPair(certPEM, keyPEM); err != nil {
+		return fmt.Errorf("private key is not the pair of certificate with subject %s: %v", cert.Subject.String(), err)
+	}
 	return nil
 }
 
 //Check or apply default values for URL translation from ODIM <=> redfish
-func checkURLTranslationConf() {
-	if Data.URLTranslation == nil {
+func (c *configModel) checkURLTranslationConf() error {
+	if c.URLTranslation == nil {
 		log.Info("URL translation not provided, setting default value")
-		Data.URLTranslation = &URLTranslation{
+		c.URLTranslation = &URLTranslation{
 			NorthBoundURL: map[string]string{
 				"ODIM": "redfish",
 			},
@@ -328,92 +854,344 @@ func checkURLTranslationConf() {
 				"redfish": "ODIM",
 			},
 		}
-		return
+		c.URLTranslation.CompileSouthBound()
+		return nil
 	}
-	if len(Data.URLTranslation.NorthBoundURL) <= 0 {
+	if len(c.URLTranslation.NorthBoundURL) <= 0 {
 		log.Info("NorthBoundURL is empty, setting default value")
-		Data.URLTranslation.NorthBoundURL = map[string]string{
+		c.URLTranslation.NorthBoundURL = map[string]string{
 			"ODIM": "redfish",
 		}
 	}
-	if len(Data.URLTranslation.SouthBoundURL) <= 0 {
+	if len(c.URLTranslation.SouthBoundURL) <= 0 {
 		log.Info("SouthBoundURL is empty, setting default value")
-		Data.URLTranslation.SouthBoundURL = map[string]string{
+		c.URLTranslation.SouthBoundURL = map[string]string{
 			"redfish": "ODIM",
 		}
 	}
+	if err := checkURLRewriteRules(c.URLTranslation.SouthBoundRules); err != nil {
+		return err
+	}
+	c.URLTranslation.CompileSouthBound()
+	return nil
 }
 
-func checkTLSConf() error {
-	if Data.TLSConf == nil {
+// checkURLRewriteRules validates the rules and rejects the conflicting ones,
+// which are the rules repeating the match of an earlier rule with the same action
+// and the prefix strips undoing the prefix added by the previous rule
+func checkURLRewriteRules(rules []URLRewriteRule) error {
+	seen := map[URLRewriteRule]bool{}
+	for i, rule := range rules {
+		switch rule.Action {
+		case URLRewriteReplace, URLRewriteStripPrefix:
+			if rule.Match == "" {
+				return fmt.Errorf("error: no Match configured for %s rule %d of URLTranslation", rule.Action, i)
+			}
+		case URLRewriteAddPrefix:
+			if rule.Value == "" {
+				return fmt.Errorf("error: no Value configured for %s rule %d of URLTranslation", rule.Action, i)
+			}
+		default:
+			return fmt.Errorf("error: invalid Action %s configured for rule %d of URLTranslation", rule.Action, i)
+		}
+		key := URLRewriteRule{Action: rule.Action, Match: rule.Match}
+		if rule.Action == URLRewriteAddPrefix {
+			key.Match = rule.Value
+		}
+		if rule.Action != URLRewriteReplace && !strings.HasPrefix(key.Match, "/") {
+			return fmt.Errorf("error: prefix of %s rule %d of URLTranslation should start with /", rule.Action, i)
+		}
+		if seen[key] {
+			return fmt.Errorf("error: %s rule %d of URLTranslation conflicts with an earlier rule for %s", rule.Action, i, key.Match)
+		}
+		seen[key] = true
+		if i > 0 && rule.Action == URLRewriteStripPrefix && rules[i-1].Action == URLRewriteAddPrefix && rules[i-1].Value == rule.Match {
+			return fmt.Errorf("error: %s rule %d of URLTranslation undoes the prefix added by rule %d", rule.Action, i, i-1)
+		}
+	}
+	return nil
+}
+
+func (c *configModel) checkTLSConf() error {
+	if c.TLSConf == nil {
 		log.Info("TLSConf not provided, setting default value")
-		Data.TLSConf = &TLSConf{}
+		c.TLSConf = &TLSConf{}
 		lutilconf.SetDefaultTLSConf()
 		return nil
 	}
 
 	var err error
-	lutilconf.SetVerifyPeer(Data.TLSConf.VerifyPeer)
-	if err = lutilconf.SetTLSMinVersion(Data.TLSConf.MinVersion); err != nil {
+	lutilconf.SetVerifyPeer(c.TLSConf.VerifyPeer)
+	if err = lutilconf.SetTLSMinVersion(c.TLSConf.MinVersion); err != nil {
 		return err
 	}
-	if err = lutilconf.SetTLSMaxVersion(Data.TLSConf.MaxVersion); err != nil {
+	if err = lutilconf.SetTLSMaxVersion(c.TLSConf.MaxVersion); err != nil {
 		return err
 	}
 	if err = lutilconf.ValidateConfiguredTLSVersions(); err != nil {
 		return err
 	}
-	if err = lutilconf.SetPreferredCipherSuites(Data.TLSConf.PreferredCipherSuites); err != nil {
+	if err = lutilconf.SetPreferredCipherSuites(c.TLSConf.PreferredCipherSuites); err != nil {
 		return err
 	}
 	return nil
 }
 
-func checkAPICConf() error {
-	if Data.APICConf.APICHost == "" {
+func (c *configModel) checkAPICConf() error {
+	if c.APICConf == nil {
+		return fmt.Errorf("no value found for APICConf")
+	}
+	if c.APICConf.APICHost == "" {
 		return fmt.Errorf("no value set for APIC Host ")
 	}
-	if Data.APICConf.UserName == "" {
+	if c.APICConf.UserName == "" {
 		return fmt.Errorf("no value set for APIC Username")
 	}
-	if Data.APICConf.Password == "" {
+	if c.APICConf.Password == "" {
 		return fmt.Errorf("no value set for APIC Password")
 	}
+	if _, ok := c.APICConf.DomainData[c.APICConf.DefaultDomain]; c.APICConf.DefaultDomain != "" && !ok {
+		return fmt.Errorf("error: invalid value %s configured for APIC DefaultDomain, it should be one of the DomainData keys %v", c.APICConf.DefaultDomain, c.APICConf.DomainKeys())
+	}
+	if len(c.APICConf.SubscriptionClasses) == 0 {
+		log.Info("no value set for APIC SubscriptionClasses, setting default value")
+		c.APICConf.SubscriptionClasses = append([]string(nil), DefaultAPICSubscriptionClasses...)
+	}
+	if c.APICConf.SubscriptionRefreshInSeconds < 0 {
+		return fmt.Errorf("error: invalid value %d configured for APIC SubscriptionRefreshInSeconds, it should be positive", c.APICConf.SubscriptionRefreshInSeconds)
+	}
+	if c.APICConf.SubscriptionRefreshInSeconds == 0 {
+		log.Info("no value set for APIC SubscriptionRefreshInSeconds, setting default value")
+		c.APICConf.SubscriptionRefreshInSeconds = DefaultAPICSubscriptionRefresh
+	}
+	if c.APICConf.RefreshJitter < 0 || c.APICConf.RefreshJitter >= 1 {
+		return fmt.Errorf("error: invalid value %v configured for APIC RefreshJitter, it should be between 0 and 1", c.APICConf.RefreshJitter)
+	}
+	if c.APICConf.RefreshJitter == 0 {
+		log.Info("no value set for APIC RefreshJitter, setting default value")
+		c.APICConf.RefreshJitter = DefaultAPICRefreshJitter
+	}
+	if c.APICConf.Tenant == "" {
+		log.Info("no value set for APIC Tenant, APIC queries are not scoped to a tenant")
+	} else if !apicNamePattern.MatchString(c.APICConf.Tenant) {
+		return fmt.Errorf("error: invalid value %s configured for APIC Tenant", c.APICConf.Tenant)
+	}
+	switch c.APICConf.UnknownHealthPolicy {
+	case "":
+		log.Info("no value set for APIC UnknownHealthPolicy, setting default value")
+		c.APICConf.UnknownHealthPolicy = UnknownHealthIgnore
+	case UnknownHealthOK, UnknownHealthWarning, UnknownHealthIgnore:
+	default:
+		return fmt.Errorf("error: invalid value %s configured for APIC UnknownHealthPolicy, it should be one of %s, %s or %s",
+			c.APICConf.UnknownHealthPolicy, UnknownHealthOK, UnknownHealthWarning, UnknownHealthIgnore)
+	}
+	switch c.APICConf.UnavailableHealthPolicy {
+	case "":
+		log.Info("no value set for APIC UnavailableHealthPolicy, setting default value")
+		c.APICConf.UnavailableHealthPolicy = UnknownHealthWarning
+	case UnknownHealthOK, UnknownHealthWarning, UnknownHealthIgnore:
+	default:
+		return fmt.Errorf("error: invalid value %s configured for APIC UnavailableHealthPolicy, it should be one of %s, %s or %s",
+			c.APICConf.UnavailableHealthPolicy, UnknownHealthOK, UnknownHealthWarning, UnknownHealthIgnore)
+	}
+	if err := c.checkPortOperStates(); err != nil {
+		return err
+	}
+	if c.APICConf.PortFlapGraceInSeconds < 0 {
+		return fmt.Errorf("error: invalid value %d configured for APIC PortFlapGraceInSeconds, it should be positive", c.APICConf.PortFlapGraceInSeconds)
+	}
+	if c.APICConf.PortStatsHistoryMaxSamples < 0 {
+		return fmt.Errorf("error: invalid value %d configured for APIC PortStatsHistoryMaxSamples, it should be positive", c.APICConf.PortStatsHistoryMaxSamples)
+	}
+	if c.APICConf.PortStatsHistoryMaxSamples == 0 {
+		log.Info("no value set for APIC PortStatsHistoryMaxSamples, setting default value")
+		c.APICConf.PortStatsHistoryMaxSamples = DefaultPortStatsHistoryMaxSamples
+	}
+	if c.APICConf.PortSettingsReconcileIntervalInSeconds < 0 {
+		return fmt.Errorf("error: invalid value %d configured for APIC PortSettingsReconcileIntervalInSeconds, it should be positive", c.APICConf.PortSettingsReconcileIntervalInSeconds)
+	}
+	if c.APICConf.PortSettingsReconcileIntervalInSeconds == 0 {
+		log.Info("no value set for APIC PortSettingsReconcileIntervalInSeconds, setting default value")
+		c.APICConf.PortSettingsReconcileIntervalInSeconds = DefaultPortSettingsReconcileInterval
+	}
+	if c.APICConf.PortMetricReportIntervalInSeconds < 0 {
+		return fmt.Errorf("error: invalid value %d configured for APIC PortMetricReportIntervalInSeconds, it should be positive", c.APICConf.PortMetricReportIntervalInSeconds)
+	}
+	if c.APICConf.LoginDomain != "" && !apicNamePattern.MatchString(c.APICConf.LoginDomain) {
+		return fmt.Errorf("error: invalid value %s configured for APIC LoginDomain", c.APICConf.LoginDomain)
+	}
+	if c.APICConf.TLSServerName != "" && !hostNamePattern.MatchString(c.APICConf.TLSServerName) {
+		return fmt.Errorf("error: invalid value %s configured for APIC TLSServerName, it should be a host name", c.APICConf.TLSServerName)
+	}
+	if c.APICConf.APIBasePath == "" {
+		log.Info("no value set for APIC APIBasePath, setting default value")
+		c.APICConf.APIBasePath = DefaultAPICAPIBasePath
+	} else if !strings.HasPrefix(c.APICConf.APIBasePath, "/") || strings.HasSuffix(c.APICConf.APIBasePath, "/") {
+		return fmt.Errorf("error: invalid value %s configured for APIC APIBasePath, it should start with / and should not end with /", c.APICConf.APIBasePath)
+	}
+	if c.APICConf.TokenClockSkewInSeconds < 0 || c.APICConf.TokenClockSkewInSeconds >= APICTokenLifetime {
+		return fmt.Errorf("error: invalid value %d configured for APIC TokenClockSkewInSeconds, it should be positive and less than the APIC token lifetime of %d seconds", c.APICConf.TokenClockSkewInSeconds, APICTokenLifetime)
+	}
+	if c.APICConf.TokenClockSkewInSeconds == 0 {
+		log.Info("no value set for APIC TokenClockSkewInSeconds, setting default value")
+		c.APICConf.TokenClockSkewInSeconds = DefaultAPICTokenClockSkew
+	}
+	if c.APICConf.QueryPageSize < 0 {
+		return fmt.Errorf("error: invalid value %d configured for APIC QueryPageSize, it should be positive", c.APICConf.QueryPageSize)
+	}
+	if c.APICConf.QueryPageSize == 0 {
+		log.Info("no value set for APIC QueryPageSize, setting default value")
+		c.APICConf.QueryPageSize = DefaultAPICQueryPageSize
+	}
+	if c.APICConf.HealthPollConcurrency < 0 {
+		return fmt.Errorf("error: invalid value %d configured for APIC HealthPollConcurrency, it should be positive", c.APICConf.HealthPollConcurrency)
+	}
+	if c.APICConf.HealthPollConcurrency == 0 {
+		log.Info("no value set for APIC HealthPollConcurrency, setting default value")
+		c.APICConf.HealthPollConcurrency = DefaultHealthPollConcurrency
+	}
+	if c.APICConf.PortIDFormat == "" {
+		log.Info("no value set for APIC PortIDFormat, setting default value")
+		c.APICConf.PortIDFormat = DefaultPortIDFormat
+	}
+	if strings.Count(c.APICConf.PortIDFormat, PortIDPlaceholder) != 1 ||
+		strings.ContainsAny(strings.Replace(c.APICConf.PortIDFormat, PortIDPlaceholder, "", 1), "{}") {
+		return fmt.Errorf("error: invalid value %s configured for APIC PortIDFormat, it should contain %s once and no other placeholder",
+			c.APICConf.PortIDFormat, PortIDPlaceholder)
+	}
+	switch c.APICConf.DefaultPortAdminState {
+	case "", PortAdminStateEnabled, PortAdminStateDisabled:
+	default:
+		return fmt.Errorf("error: invalid value %s configured for APIC DefaultPortAdminState, it should be %s or %s",
+			c.APICConf.DefaultPortAdminState, PortAdminStateEnabled, PortAdminStateDisabled)
+	}
+	if err := c.checkAPICCluster(); err != nil {
+		return err
+	}
+	return c.checkAPICRateLimit()
+}
+
+// DomainKeys returns the keys of DomainData, sorted
+func (c *APICConf) DomainKeys() []string {
+	keys := make([]string, 0, len(c.DomainData))
+	for key := range c.DomainData {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// DomainDN returns the DN of the domain mapped for the key in DomainData, the domain of DefaultDomain
+// when the key isn't mapped. The error names the missing key and the available keys when there is no
+// default domain.
+func (c *APICConf) DomainDN(key string) (string, error) {
+	if dn, ok := c.DomainData[key]; ok {
+		return dn, nil
+	}
+	if c.DefaultDomain != "" {
+		if dn, ok := c.DomainData[c.DefaultDomain]; ok {
+			log.Info(fmt.Sprintf("no APIC domain mapped for %s, using the default domain %s", key, c.DefaultDomain))
+			return dn, nil
+		}
+	}
+	return "", fmt.Errorf("no APIC domain mapped for %s in DomainData, available domains are %v and no DefaultDomain is configured", key, c.DomainKeys())
+}
+
+func (c *configModel) checkAPICCluster() error {
+	for _, host := range c.APICConf.ClusterHosts {
+		if host == "" || host == c.APICConf.APICHost {
+			return fmt.Errorf("error: invalid value %q configured in APIC ClusterHosts, it should be a controller other than APICHost", host)
+		}
+	}
+	if c.APICConf.QuorumReads && len(c.APICConf.ClusterHosts) == 0 {
+		return fmt.Errorf("error: no value configured for APIC ClusterHosts, required by QuorumReads")
+	}
+	if c.APICConf.BalanceReads && len(c.APICConf.ClusterHosts) == 0 {
+		return fmt.Errorf("error: no value configured for APIC ClusterHosts, required by BalanceReads")
+	}
+	for host, weight := range c.APICConf.HostWeights {
+		if !c.isAPICClusterHost(host) {
+			return fmt.Errorf("error: invalid host %q configured in APIC HostWeights, it should be APICHost or one of ClusterHosts", host)
+		}
+		if weight <= 0 {
+			return fmt.Errorf("error: invalid weight %d configured in APIC HostWeights for %s, it should be positive", weight, host)
+		}
+	}
 	return nil
 }
 
-func checkDBConf() error {
-	if Data.DBConf == nil {
+func (c *configModel) isAPICClusterHost(host string) bool {
+	if host == c.APICConf.APICHost {
+		return true
+	}
+	for _, clusterHost := range c.APICConf.ClusterHosts {
+		if host == clusterHost {
+			return true
+		}
+	}
+	return false
+}
+
+func (c *configModel) checkAPICRateLimit() error {
+	if c.APICConf.RequestsPerSecond < 0 {
+		return fmt.Errorf("error: invalid value %v configured for APIC RequestsPerSecond, it should be positive", c.APICConf.RequestsPerSecond)
+	}
+	if c.APICConf.RequestBurst < 0 {
+		return fmt.Errorf("error: invalid value %d configured for APIC RequestBurst, it should be positive", c.APICConf.RequestBurst)
+	}
+	if c.APICConf.RateLimitWaitInMilliseconds < 0 {
+		return fmt.Errorf("error: invalid value %d configured for APIC RateLimitWaitInMilliseconds, it should be positive", c.APICConf.RateLimitWaitInMilliseconds)
+	}
+	if c.APICConf.RequestsPerSecond == 0 {
+		log.Info("no value set for APIC RequestsPerSecond, requests to APIC are not rate limited")
+		return nil
+	}
+	if c.APICConf.RequestBurst == 0 {
+		log.Info("no value set for APIC RequestBurst, setting default value")
+		c.APICConf.RequestBurst = DefaultAPICRequestBurst
+	}
+	if c.APICConf.RateLimitWaitInMilliseconds == 0 {
+		log.Info("no value set for APIC RateLimitWaitInMilliseconds, setting default value")
+		c.APICConf.RateLimitWaitInMilliseconds = DefaultAPICRateLimitWait
+	}
+	return nil
+}
+
+func (c *configModel) checkDBConf() error {
+	if c.DBConf == nil {
 		return fmt.Errorf("error: DBConf is not provided")
 	}
-	if Data.DBConf.Protocol != DefaultDBProtocol {
+	if c.DBConf.Protocol != DefaultDBProtocol {
 		log.Warn("Incorrect value configured for DB Protocol, setting default value")
-		Data.DBConf.Protocol = DefaultDBProtocol
+		c.DBConf.Protocol = DefaultDBProtocol
 	}
-	if Data.DBConf.Host == "" {
+	if c.DBConf.Host == "" {
 		return fmt.Errorf("error: no value configured for DB Host")
 	}
-	if Data.DBConf.Port == "" {
+	if c.DBConf.Port == "" {
 		return fmt.Errorf("error: no value configured for DB Port")
 	}
-	if Data.DBConf.PoolSize == 0 {
+	if c.DBConf.PoolSize == 0 {
 		log.Warn("No value configured for PoolSize, setting default value")
-		Data.DBConf.PoolSize = DefaultDBPoolSize
+		c.DBConf.PoolSize = DefaultDBPoolSize
 	}
-	if Data.DBConf.MinIdleConns == 0 {
+	if c.DBConf.MinIdleConns == 0 {
 		log.Warn("No value configured for MinIdleConns, setting default value")
-		Data.DBConf.MinIdleConns = DefaultDBMinIdleConns
+		c.DBConf.MinIdleConns = DefaultDBMinIdleConns
 	}
-	if Data.DBConf.RedisOnDiskEncryptedPassword == "" {
+	if c.DBConf.RedisOnDiskEncryptedPassword == "" {
 		return fmt.Errorf("error: no value configured for Redis OnDisk Encrypted Password")
 	}
+	if err := checkDBKeyPrefix(c.DBConf.KeyPrefix); err != nil {
+		return err
+	}
 	var err error
-	Data.DBConf.RedisOnDiskPassword, err = decryptRSAOAEPEncryptedPasswords(Data.DBConf.RedisOnDiskEncryptedPassword)
+	c.DBConf.RedisOnDiskPassword, err = c.decryptRSAOAEPEncryptedPasswords(c.DBConf.RedisOnDiskEncryptedPassword)
 	if err != nil {
 		return err
 	}
-	if Data.DBConf.RedisHAEnabled {
-		if err = checkDBHAConf(); err != nil {
+	if c.DBConf.RedisHAEnabled {
+		if err = c.checkDBHAConf(); err != nil {
 			return err
 		}
 	}
@@ -421,23 +1199,276 @@ func checkDBConf() error {
 	return nil
 }
 
-func checkDBHAConf() error {
-	if Data.DBConf.SentinelPort == "" {
+// checkDBKeyPrefix checks the key prefix can be used in the keys and in the key patterns scanned
+func checkDBKeyPrefix(prefix string) error {
+	if strings.IndexFunc(prefix, unicode.IsSpace) >= 0 {
+		return fmt.Errorf("error: DB KeyPrefix %q contains whitespace", prefix)
+	}
+	if strings.ContainsAny(prefix, `*?[]\`) {
+		return fmt.Errorf("error: DB KeyPrefix %q contains Redis pattern characters", prefix)
+	}
+	return nil
+}
+
+func (c *configModel) checkDBHAConf() error {
+	if c.DBConf.SentinelPort == "" {
 		return fmt.Errorf("error: no value configured for DB SentinelPort")
 	}
-	if Data.DBConf.MasterSet == "" {
+	if c.DBConf.MasterSet == "" {
 		return fmt.Errorf("error: no value configured for DB MasterSet")
 	}
 	return nil
 }
 
-func decryptRSAOAEPEncryptedPasswords(encryptedPassword string) ([]byte, error) {
+// checkCORSConf validates the CORS policy, when CORSConf is not provided no CORS headers are added
+func (c *configModel) checkCORSConf() error {
+	if c.CORSConf == nil {
+		return nil
+	}
+	if len(c.CORSConf.AllowedOrigins) == 0 {
+		return fmt.Errorf("error: no value configured for CORS AllowedOrigins")
+	}
+	for _, origin := range c.CORSConf.AllowedOrigins {
+		if origin == "*" && c.CORSConf.AllowCredentials {
+			return fmt.Errorf("error: wildcard CORS origin can't be combined with AllowCredentials")
+		}
+	}
+	if len(c.CORSConf.AllowedMethods) == 0 {
+		log.Info("no value set for CORS AllowedMethods, setting default value")
+		c.CORSConf.AllowedMethods = append([]string(nil), DefaultCORSAllowedMethods...)
+	}
+	if len(c.CORSConf.AllowedHeaders) == 0 {
+		log.Info("no value set for CORS AllowedHeaders, setting default value")
+		c.CORSConf.AllowedHeaders = append([]string(nil), DefaultCORSAllowedHeaders...)
+	}
+	return nil
+}
+
+// checkPortOperStates validates the Redfish states configured for the APIC operSt values
+func (c *configModel) checkPortOperStates() error {
+	for operState, state := range c.APICConf.PortOperStates {
+		if !AllowedPortLinkStates[state.LinkState] || !AllowedPortLinkStatuses[state.LinkStatus] || !AllowedPortStates[state.State] {
+			return fmt.Errorf("error: invalid value %+v configured for APIC PortOperStates of %s", state, operState)
+		}
+	}
+	return nil
+}
+
+// checkServerConf validates the server timeouts and the idempotency key TTL and sets the default value for the ones not configured
+func (c *configModel) checkServerConf() error {
+	if c.ServerConf == nil {
+		log.Info("ServerConf not provided, setting default value")
+		c.ServerConf = &ServerConf{}
+	}
+	timeouts := []struct {
+		name         string
+		value        *int
+		defaultValue int
+	}{
+		{"ReadTimeoutInSeconds", &c.ServerConf.ReadTimeoutInSeconds, DefaultServerReadTimeout},
+		{"WriteTimeoutInSeconds", &c.ServerConf.WriteTimeoutInSeconds, DefaultServerWriteTimeout},
+		{"IdleTimeoutInSeconds", &c.ServerConf.IdleTimeoutInSeconds, DefaultServerIdleTimeout},
+		{"IdempotencyKeyTTLInSeconds", &c.ServerConf.IdempotencyKeyTTLInSeconds, DefaultIdempotencyKeyTTL},
+	}
+	for _, timeout := range timeouts {
+		if *timeout.value < 0 {
+			return fmt.Errorf("error: invalid value %d configured for server %s, it should be positive", *timeout.value, timeout.name)
+		}
+		if *timeout.value == 0 {
+			log.Info("no value set for server " + timeout.name + ", setting default value")
+			*timeout.value = timeout.defaultValue
+		}
+	}
+	if c.ServerConf.MaxConcurrentRequests < 0 {
+		return fmt.Errorf("error: invalid value %d configured for server MaxConcurrentRequests, it should be positive", c.ServerConf.MaxConcurrentRequests)
+	}
+	if c.ServerConf.RequestQueueTimeoutInMilliseconds < 0 {
+		return fmt.Errorf("error: invalid value %d configured for server RequestQueueTimeoutInMilliseconds, it should be positive", c.ServerConf.RequestQueueTimeoutInMilliseconds)
+	}
+	if c.ServerConf.MaxConcurrentRequests == 0 {
+		log.Info("no value set for server MaxConcurrentRequests, concurrent requests are not limited")
+	}
+	if c.ServerConf.MaxPortEventStreams < 0 {
+		return fmt.Errorf("error: invalid value %d configured for server MaxPortEventStreams, it should be positive", c.ServerConf.MaxPortEventStreams)
+	}
+	if c.ServerConf.MaxPortEventStreams == 0 {
+		log.Info("no value set for server MaxPortEventStreams, setting default value")
+		c.ServerConf.MaxPortEventStreams = DefaultMaxPortEventStreams
+	}
+	if c.ServerConf.CacheMaxAgeInSeconds < 0 {
+		return fmt.Errorf("error: invalid value %d configured for server CacheMaxAgeInSeconds, it should be positive", c.ServerConf.CacheMaxAgeInSeconds)
+	}
+	if c.ServerConf.CacheMaxAgeInSeconds == 0 {
+		log.Info("no value set for server CacheMaxAgeInSeconds, setting default value")
+		c.ServerConf.CacheMaxAgeInSeconds = DefaultCacheMaxAge
+	}
+	if c.ServerConf.MaxPageSize < 0 {
+		return fmt.Errorf("error: invalid value %d configured for server MaxPageSize, it should be positive", c.ServerConf.MaxPageSize)
+	}
+	if c.ServerConf.MaxPageSize == 0 {
+		log.Info("no value set for server MaxPageSize, setting default value")
+		c.ServerConf.MaxPageSize = DefaultMaxPageSize
+	}
+	if c.ServerConf.LogSampleRate < 0 {
+		return fmt.Errorf("error: invalid value %d configured for server LogSampleRate, it should be positive", c.ServerConf.LogSampleRate)
+	}
+	if c.ServerConf.LogSampleRate == 0 {
+		log.Info("no value set for server LogSampleRate, setting default value")
+		c.ServerConf.LogSampleRate = DefaultLogSampleRate
+	}
+	if c.ServerConf.StartupAttempts < 0 {
+		return fmt.Errorf("error: invalid value %d configured for server StartupAttempts, it should be positive", c.ServerConf.StartupAttempts)
+	}
+	if c.ServerConf.StartupAttempts == 0 {
+		log.Info("no value set for server StartupAttempts, setting default value")
+		c.ServerConf.StartupAttempts = DefaultStartupAttempts
+	}
+	if c.ServerConf.StartupRetryDelayInSeconds < 0 {
+		return fmt.Errorf("error: invalid value %d configured for server StartupRetryDelayInSeconds, it should be positive", c.ServerConf.StartupRetryDelayInSeconds)
+	}
+	if c.ServerConf.StartupRetryDelayInSeconds == 0 {
+		log.Info("no value set for server StartupRetryDelayInSeconds, setting default value")
+		c.ServerConf.StartupRetryDelayInSeconds = DefaultStartupRetryDelay
+	}
+	switch c.ServerConf.TrailingSlashPolicy {
+	case "":
+		log.Info("no value set for server TrailingSlashPolicy, setting default value")
+		c.ServerConf.TrailingSlashPolicy = TrailingSlashIgnore
+	case TrailingSlashIgnore, TrailingSlashRedirect:
+	default:
+		return fmt.Errorf("error: invalid value %s configured for server TrailingSlashPolicy, it should be %s or %s",
+			c.ServerConf.TrailingSlashPolicy, TrailingSlashIgnore, TrailingSlashRedirect)
+	}
+	if len(c.ServerConf.CompressionAlgorithms) == 0 {
+		log.Info("no value set for server CompressionAlgorithms, setting default value")
+		c.ServerConf.CompressionAlgorithms = append([]string(nil), DefaultCompressionAlgorithms...)
+	}
+	for _, algorithm := range c.ServerConf.CompressionAlgorithms {
+		if !AllowedCompressionAlgorithms[algorithm] {
+			return fmt.Errorf("error: invalid value %s configured for server CompressionAlgorithms, it should be %s, %s or %s",
+				algorithm, CompressionGzip, CompressionDeflate, CompressionBrotli)
+		}
+	}
+	if c.ServerConf.CompressionMinSizeInBytes < 0 {
+		return fmt.Errorf("error: invalid value %d configured for server CompressionMinSizeInBytes, it should be positive", c.ServerConf.CompressionMinSizeInBytes)
+	}
+	if c.ServerConf.CompressionMinSizeInBytes == 0 {
+		log.Info("no value set for server CompressionMinSizeInBytes, setting default value")
+		c.ServerConf.CompressionMinSizeInBytes = DefaultCompressionMinSize
+	}
+	if c.ServerConf.EnableHTTP2 {
+		return c.checkHTTP2Conf()
+	}
+	return nil
+}
+
+// checkClientConf validates the connection reuse of the outbound http clients and sets the default value for the ones not configured
+func (c *configModel) checkClientConf() error {
+	if c.ClientConf == nil {
+		log.Info("ClientConf not provided, setting default value")
+		c.ClientConf = &ClientConf{}
+	}
+	settings := []struct {
+		name         string
+		value        *int
+		defaultValue int
+	}{
+		{"MaxIdleConns", &c.ClientConf.MaxIdleConns, DefaultClientMaxIdleConns},
+		{"MaxIdleConnsPerHost", &c.ClientConf.MaxIdleConnsPerHost, DefaultClientMaxIdleConnsPerHost},
+		{"IdleConnTimeoutInSeconds", &c.ClientConf.IdleConnTimeoutInSeconds, DefaultClientIdleConnTimeout},
+	}
+	for _, setting := range settings {
+		if *setting.value < 0 {
+			return fmt.Errorf("error: invalid value %d configured for client %s, it should be positive", *setting.value, setting.name)
+		}
+		if *setting.value == 0 {
+			log.Info("no value set for client " + setting.name + ", setting default value")
+			*setting.value = setting.defaultValue
+		}
+	}
+	return nil
+}
+
+// checkHTTP2Conf validates that HTTP/2 is served only over TLS 1.2 or later, with the cipher suite HTTP/2 requires
+func (c *configModel) checkHTTP2Conf() error {
+	if c.KeyCertConf == nil || len(c.KeyCertConf.Certificate) == 0 {
+		return fmt.Errorf("error: server EnableHTTP2 is configured without the TLS certificate of KeyCertConf, HTTP/2 is served only over TLS")
+	}
+	if c.TLSConf == nil {
+		return nil
+	}
+	if c.TLSConf.MaxVersion == "TLS_1.0" || c.TLSConf.MaxVersion == "TLS_1.1" {
+		return fmt.Errorf("error: server EnableHTTP2 is configured with TLS MaxVersion %s, HTTP/2 requires TLS_1.2 or later", c.TLSConf.MaxVersion)
+	}
+	if len(c.TLSConf.PreferredCipherSuites) == 0 {
+		return nil
+	}
+	for _, cipherSuite := range c.TLSConf.PreferredCipherSuites {
+		if HTTP2RequiredCipherSuites[cipherSuite] {
+			return nil
+		}
+	}
+	return fmt.Errorf("error: server EnableHTTP2 is configured without an AES_128_GCM_SHA256 cipher suite in TLS PreferredCipherSuites, HTTP/2 requires one of them")
+}
+
+// checkOTelConf validates the tracing configuration and sets the default value for the ones not configured
+func (c *configModel) checkOTelConf() error {
+	if c.OTelConf == nil || !c.OTelConf.Enabled {
+		log.Info("tracing is disabled")
+		return nil
+	}
+	if c.OTelConf.ServiceName == "" {
+		log.Info("no value set for OTel ServiceName, setting default value")
+		c.OTelConf.ServiceName = DefaultOTelServiceName
+	}
+	if c.OTelConf.SamplingRatio < 0 || c.OTelConf.SamplingRatio > 1 {
+		return fmt.Errorf("error: invalid value %v configured for OTel SamplingRatio, it should be between 0 and 1", c.OTelConf.SamplingRatio)
+	}
+	if c.OTelConf.SamplingRatio == 0 {
+		log.Info("no value set for OTel SamplingRatio, setting default value")
+		c.OTelConf.SamplingRatio = DefaultOTelSamplingRatio
+	}
+	if c.OTelConf.Exporter == "" {
+		log.Info("no value set for OTel Exporter, setting default value")
+		c.OTelConf.Exporter = DefaultOTelExporter
+	}
+	if !AllowedOTelExporters[c.OTelConf.Exporter] {
+		return fmt.Errorf("error: invalid value %s configured for OTel Exporter", c.OTelConf.Exporter)
+	}
+	if c.OTelConf.Exporter == "otlphttp" && c.OTelConf.Endpoint == "" {
+		return fmt.Errorf("error: no value configured for OTel Endpoint, required by otlphttp exporter")
+	}
+	return nil
+}
+
+// checkAuditConf validates the audit log configuration
+func (c *configModel) checkAuditConf() error {
+	if c.AuditConf == nil {
+		log.Info("audit log is disabled")
+		return nil
+	}
+	switch c.AuditConf.Sink {
+	case AuditSinkFile:
+		if c.AuditConf.FilePath == "" {
+			return fmt.Errorf("error: no value configured for Audit FilePath, required by File sink")
+		}
+	case AuditSinkMessageBus:
+		if c.AuditConf.Topic == "" {
+			return fmt.Errorf("error: no value configured for Audit Topic, required by MessageBus sink")
+		}
+	default:
+		return fmt.Errorf("error: invalid value %s configured for Audit Sink, it should be %s or %s", c.AuditConf.Sink, AuditSinkFile, AuditSinkMessageBus)
+	}
+	return nil
+}
+
+func (c *configModel) decryptRSAOAEPEncryptedPasswords(encryptedPassword string) ([]byte, error) {
 	decoded, err := base64.StdEncoding.DecodeString(encryptedPassword)
 	if err != nil {
 		return nil, err
 	}
 	hash := sha512.New()
-	priv, err := bytesToPrivateKey(Data.KeyCertConf.RSAPrivateKey)
+	priv, err := bytesToPrivateKey(c.KeyCertConf.RSAPrivateKey)
 	if err != nil {
 
 		return nil, err
@@ -452,6 +1483,9 @@ func decryptRSAOAEPEncryptedPasswords(encryptedPassword string) ([]byte, error)
 
 func bytesToPrivateKey(privateKey []byte) (*rsa.PrivateKey, error) {
 	block, _ := pem.Decode(privateKey)
+	if block == nil {
+		return nil, fmt.Errorf("private key is not PEM encoded")
+	}
 	enc := x509.IsEncryptedPEMBlock(block)
 	b := block.Bytes
 	var err error
@@ -469,3 +1503,27 @@ func bytesToPrivateKey(privateKey []byte) (*rsa.PrivateKey, error) {
 	}
 	return key, nil
 }
+
+// checkWritablePortProperties validates the configured writable port properties are properties of the Port model
+func (c *configModel) checkWritablePortProperties() error {
+	if len(c.WritablePortProperties) == 0 {
+		log.Info("no value set for WritablePortProperties, setting default value")
+		c.WritablePortProperties = append([]string(nil), DefaultWritablePortProperties...)
+		return nil
+	}
+	portProperties := map[string]bool{}
+	portType := reflect.TypeOf(model.Port{})
+	for i := 0; i < portType.NumField(); i++ {
+		name := strings.Split(portType.Field(i).Tag.Get("json"), ",")[0]
+		if name == "" {
+			name = portType.Field(i).Name
+		}
+		portProperties[name] = true
+	}
+	for _, property := range c.WritablePortProperties {
+		if !portProperties[property] {
+			return fmt.Errorf("error: invalid value %s configured for WritablePortProperties, it is not a property of Port", property)
+		}
+	}
+	return nil
+}
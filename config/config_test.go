@@ -0,0 +1,251 @@
+//(C) Copyright [2020] Hewlett Packard Enterprise Development LP
+//
+//Licensed under the Apache License, Version 2.0 (the "License"); you may
+//not use this file except in compliance with the License. You may obtain
+//a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+//Unless required by applicable law or agreed to in writing, software
+//distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+//WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+//License for the specific language governing permissions and limitations
+// under the License.
+
+package config
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// generateKeyPair returns a PEM encoded self signed certificate and its private key
+func generateKeyPair(t *testing.T) ([]byte, []byte) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "plugin"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	certDER, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("failed to create certificate: %v", err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatalf("failed to marshal key: %v", err)
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: certDER}),
+		pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
+}
+
+func TestCheckKeyPairMatch(t *testing.T) {
+	cert, key := generateKeyPair(t)
+	_, otherKey := generateKeyPair(t)
+	tests := []struct {
+		name    string
+		cert    []byte
+		key     []byte
+		wantErr string
+	}{
+		{name: "matching pair", cert: cert, key: key},
+		{name: "mismatched pair", cert: cert, key: otherKey, wantErr: "CN=plugin"},
+		{name: "malformed certificate", cert: []byte("not a certificate"), key: key, wantErr: "not PEM encoded"},
+		{name: "malformed key", cert: cert, key: []byte("not a key"), wantErr: "CN=plugin"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := checkKeyPairMatch(tt.cert, tt.key)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("checkKeyPairMatch() error = %v, want nil", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("checkKeyPairMatch() error = %v, want error containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestCheckRSAPrivateKey(t *testing.T) {
+	_, ecKey := generateKeyPair(t)
+	if err := checkRSAPrivateKey([]byte(rsaPrivateKey)); err != nil {
+		t.Errorf("checkRSAPrivateKey() of RSA key error = %v, want nil", err)
+	}
+	if err := checkRSAPrivateKey(ecKey); err == nil || !strings.Contains(err.Error(), "EC PRIVATE KEY") {
+		t.Errorf("checkRSAPrivateKey() of EC key error = %v, want error naming the key type", err)
+	}
+	if err := checkRSAPrivateKey([]byte("not a key")); err == nil {
+		t.Error("checkRSAPrivateKey() of malformed key, want error")
+	}
+}
+
+func TestCheckCertsAndKeysConfRSAPrivateKey(t *testing.T) {
+	cert, key := generateKeyPair(t)
+	dir := t.TempDir()
+	writeFile := func(name string, data []byte) string {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, data, 0600); err != nil {
+			t.Fatalf("failed to write %s: %v", name, err)
+		}
+		return path
+	}
+	keyCertConf := KeyCertConf{
+		CertificatePath:       writeFile("plugin.crt", cert),
+		PrivateKeyPath:        writeFile("plugin.key", key),
+		RootCACertificatePath: writeFile("rootCA.crt", cert),
+		RSAPrivateKeyPath:     writeFile("odimra_rsa.private", []byte(rsaPrivateKey)),
+	}
+	defer func(conf *KeyCertConf) { Data.KeyCertConf = conf }(Data.KeyCertConf)
+
+	Data.KeyCertConf = &keyCertConf
+	if err := Data.checkCertsAndKeysConf(); err != nil {
+		t.Errorf("checkCertsAndKeysConf() with RSA key error = %v, want nil", err)
+	}
+
+	// the EC key of the plugin can't decrypt the passwords
+	ecConf := keyCertConf
+	ecConf.RSAPrivateKeyPath = ecConf.PrivateKeyPath
+	Data.KeyCertConf = &ecConf
+	err := Data.checkCertsAndKeysConf()
+	if err == nil || !strings.Contains(err.Error(), "same file as PrivateKeyPath") {
+		t.Errorf("checkCertsAndKeysConf() with the EC key of the plugin error = %v, want error naming PrivateKeyPath", err)
+	}
+}
+
+func TestValidateFile(t *testing.T) {
+	defer func(data configModel) { Data = data }(Data)
+	dir := t.TempDir()
+	writeFile := func(name string, data []byte) string {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, data, 0600); err != nil {
+			t.Fatalf("failed to write %s: %v", name, err)
+		}
+		return path
+	}
+
+	SetUpMockConfig(t)
+	cert, key := generateKeyPair(t)
+	Data.KeyCertConf = &KeyCertConf{
+		CertificatePath:       writeFile("plugin.crt", cert),
+		PrivateKeyPath:        writeFile("plugin.key", key),
+		RootCACertificatePath: writeFile("rootCA.crt", cert),
+		RSAPrivateKeyPath:     writeFile("odimra_rsa.private", []byte(rsaPrivateKey)),
+	}
+	Data.MessageBusConf.MessageQueueConfigFilePath = writeFile("platformconfig.toml", nil)
+	configData, err := json.Marshal(Data)
+	if err != nil {
+		t.Fatalf("failed to marshal the config: %v", err)
+	}
+	if err := ValidateFile(writeFile("config.json", configData)); err != nil {
+		t.Errorf("ValidateFile() of valid config error = %v, want nil", err)
+	}
+
+	invalidConfig := writeFile("invalid.json", []byte(`{
+		"RootServiceUUID": "3bd1f589-117a-4cf9-89f2-da44ee8e2325",
+		"PluginConf": {"Host": "127.0.0.1", "UserName": "admin", "Password": "password"},
+		"EventConf": {"ListenerHost": "127.0.0.1", "ListenerPort": "45002"},
+		"APICConf": {"APICHost": "127.0.0.1", "UserName": "admin", "Password": "password", "TokenClockSkewInSeconds": -1},
+		"AuditConf": {"Sink": "Syslog"}
+	}`))
+	err = ValidateFile(invalidConfig)
+	var errs ValidationErrors
+	if !errors.As(err, &errs) {
+		t.Fatalf("ValidateFile() of invalid config error = %v, want ValidationErrors", err)
+	}
+	wantErrs := []string{"Plugin Port", "ODIMConf", "EventURI", "MessageBusConf", "KeyCertConf", "TokenClockSkewInSeconds", "Audit Sink"}
+	if len(errs) != len(wantErrs) {
+		t.Errorf("ValidateFile() of invalid config returned %d errors %v, want %d", len(errs), errs, len(wantErrs))
+	}
+	for _, want := range wantErrs {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("ValidateFile() of invalid config error = %v, want error naming %s", err, want)
+		}
+	}
+
+	if err := ValidateFile(filepath.Join(dir, "missing.json")); err == nil {
+		t.Error("ValidateFile() of missing file, want error")
+	}
+}
+
+func TestLoadConfigurationMaintenanceMode(t *testing.T) {
+	defer func(data configModel) {
+		Data = data
+		current.Store(&Data)
+	}(Data)
+	dir := t.TempDir()
+	writeFile := func(name string, data []byte) string {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, data, 0600); err != nil {
+			t.Fatalf("failed to write %s: %v", name, err)
+		}
+		return path
+	}
+
+	SetUpMockConfig(t)
+	cert, key := generateKeyPair(t)
+	Data.KeyCertConf = &KeyCertConf{
+		CertificatePath:       writeFile("plugin.crt", cert),
+		PrivateKeyPath:        writeFile("plugin.key", key),
+		RootCACertificatePath: writeFile("rootCA.crt", cert),
+		RSAPrivateKeyPath:     writeFile("odimra_rsa.private", []byte(rsaPrivateKey)),
+	}
+	Data.MessageBusConf.MessageQueueConfigFilePath = writeFile("platformconfig.toml", nil)
+	Data.ServerConf.MaintenanceMode = true
+	configData, err := json.Marshal(Data)
+	if err != nil {
+		t.Fatalf("failed to marshal the config: %v", err)
+	}
+	configFilePath := writeFile("config.json", configData)
+	if err := loadConfiguration(configFilePath); err != nil {
+		t.Fatalf("loadConfiguration() error = %v", err)
+	}
+	if !CurrentServerConf().MaintenanceMode {
+		t.Fatal("loadConfiguration() with MaintenanceMode, want maintenance mode")
+	}
+
+	// the key removed from the file leaves the maintenance mode
+	var conf map[string]interface{}
+	if err := json.Unmarshal(configData, &conf); err != nil {
+		t.Fatalf("failed to unmarshal the config: %v", err)
+	}
+	delete(conf["ServerConf"].(map[string]interface{}), "MaintenanceMode")
+	if configData, err = json.Marshal(conf); err != nil {
+		t.Fatalf("failed to marshal the config: %v", err)
+	}
+	writeFile("config.json", configData)
+	if err := loadConfiguration(configFilePath); err != nil {
+		t.Fatalf("loadConfiguration() without MaintenanceMode error = %v", err)
+	}
+	if CurrentServerConf().MaintenanceMode || Data.ServerConf.MaintenanceMode {
+		t.Error("loadConfiguration() without MaintenanceMode, want maintenance mode left")
+	}
+
+	// an invalid file leaves the configuration loaded untouched
+	loaded := current.Load()
+	writeFile("config.json", []byte(`{"PluginConf": {"Host": "127.0.0.1"}, "ServerConf": {"MaintenanceMode": true}}`))
+	if err := loadConfiguration(configFilePath); err == nil {
+		t.Fatal("loadConfiguration() of invalid config, want error")
+	}
+	if current.Load() != loaded || Data.RootServiceUUID != loaded.RootServiceUUID || Data.ServerConf.MaintenanceMode {
+		t.Error("loadConfiguration() of invalid config changed the configuration loaded")
+	}
+}
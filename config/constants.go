@@ -21,9 +21,207 @@ const (
 	DefaultDBPoolSize = 120
 	// DefaultDBMinIdleConns - default MinIdleConns value
 	DefaultDBMinIdleConns = 10
+	// DefaultServerReadTimeout - default server ReadTimeoutInSeconds value
+	DefaultServerReadTimeout = 30
+	// DefaultServerWriteTimeout - default server WriteTimeoutInSeconds value
+	DefaultServerWriteTimeout = 60
+	// DefaultServerIdleTimeout - default server IdleTimeoutInSeconds value
+	DefaultServerIdleTimeout = 120
+	// DefaultIdempotencyKeyTTL - default server IdempotencyKeyTTLInSeconds value
+	DefaultIdempotencyKeyTTL = 300
+	// DefaultMaxPortEventStreams - default server MaxPortEventStreams value
+	DefaultMaxPortEventStreams = 16
+	// DefaultCacheMaxAge - default server CacheMaxAgeInSeconds value, the time the ports read from the DB are cached by the plugin
+	DefaultCacheMaxAge = 30
+	// DefaultPluginVendor - default Plugin Vendor value
+	DefaultPluginVendor = "ODIM"
+	// DefaultPluginModel - default Plugin Model value
+	DefaultPluginModel = "PluginCiscoACI"
+	// DefaultMaxPageSize - default server MaxPageSize value
+	DefaultMaxPageSize = 1000
+	// DefaultStartupAttempts - default server StartupAttempts value
+	DefaultStartupAttempts = 5
+	// DefaultStartupRetryDelay - default server StartupRetryDelayInSeconds value
+	DefaultStartupRetryDelay = 2
+	// DefaultLogSampleRate - default server LogSampleRate value, all the requests are logged
+	DefaultLogSampleRate = 1
+	// DefaultCompressionMinSize - default server CompressionMinSizeInBytes value
+	DefaultCompressionMinSize = 1024
+	// DefaultClientMaxIdleConns - default client MaxIdleConns value
+	DefaultClientMaxIdleConns = 100
+	// DefaultClientMaxIdleConnsPerHost - default client MaxIdleConnsPerHost value
+	DefaultClientMaxIdleConnsPerHost = 16
+	// DefaultClientIdleConnTimeout - default client IdleConnTimeoutInSeconds value
+	DefaultClientIdleConnTimeout = 90
+	// DefaultPasswordMinLength - default PasswordPolicy MinLength value
+	DefaultPasswordMinLength = 12
+	// DefaultUserNameMinLength - default PasswordPolicy MinUserNameLength value
+	DefaultUserNameMinLength = 4
+	// DefaultAPICSubscriptionRefresh - default APIC SubscriptionRefreshInSeconds value,
+	// APIC times out the subscriptions not refreshed in 90 seconds
+	DefaultAPICSubscriptionRefresh = 45
+	// DefaultAPICRefreshJitter - default APIC RefreshJitter value
+	DefaultAPICRefreshJitter = 0.2
+	// DefaultAPICTokenClockSkew - default APIC TokenClockSkewInSeconds value
+	DefaultAPICTokenClockSkew = 30
+	// DefaultAPICQueryPageSize - default APIC QueryPageSize value
+	DefaultAPICQueryPageSize = 1000
+	// DefaultHealthPollConcurrency - default APIC HealthPollConcurrency value
+	DefaultHealthPollConcurrency = 4
+	// PortIDPlaceholder is the placeholder of the APIC port id without its eth prefix in APIC PortIDFormat
+	PortIDPlaceholder = "{id}"
+	// DefaultPortIDFormat - default APIC PortIDFormat value, the PortId is reported as read from APIC
+	DefaultPortIDFormat = "eth{id}"
+	// PortAdminStateEnabled is the admin state of the ports enabled, one of the values of APIC DefaultPortAdminState
+	PortAdminStateEnabled = "Enabled"
+	// PortAdminStateDisabled is the admin state of the ports disabled, one of the values of APIC DefaultPortAdminState
+	PortAdminStateDisabled = "Disabled"
+	// APICTokenLifetime - lifetime in seconds of the tokens issued by APIC with its default web session idle timeout
+	APICTokenLifetime = 600
+	// DefaultAPICAPIBasePath - default APIC APIBasePath value
+	DefaultAPICAPIBasePath = "/api"
+	// DefaultAPICRequestBurst - default APIC RequestBurst value
+	DefaultAPICRequestBurst = 1
+	// DefaultAPICRateLimitWait - default APIC RateLimitWaitInMilliseconds value
+	DefaultAPICRateLimitWait = 2000
+	// DefaultPortStatsHistoryMaxSamples - default APIC PortStatsHistoryMaxSamples value, a day of 5min samples
+	DefaultPortStatsHistoryMaxSamples = 288
+	// DefaultPortSettingsReconcileInterval - default APIC PortSettingsReconcileIntervalInSeconds value
+	DefaultPortSettingsReconcileInterval = 30
+	// DefaultOTelServiceName - default OTel ServiceName value
+	DefaultOTelServiceName = "PluginCiscoACI"
+	// DefaultOTelSamplingRatio - default OTel SamplingRatio value
+	DefaultOTelSamplingRatio = 1.0
+	// DefaultOTelExporter - default OTel Exporter value
+	DefaultOTelExporter = "log"
+	// DefaultEventBufferCapacity - default MessageBus EventBufferCapacity value
+	DefaultEventBufferCapacity = 1000
+	// DefaultEventBlockTimeout - default MessageBus EventBlockTimeoutInSeconds value
+	DefaultEventBlockTimeout = 5
+	// DefaultEventBacklogCapacity - default MessageBus EventBacklogCapacity value
+	DefaultEventBacklogCapacity = 10000
+	// DefaultEventBacklogRetryInterval - default MessageBus EventBacklogRetryIntervalInSeconds value
+	DefaultEventBacklogRetryInterval = 10
+)
+
+// overflow policies of the buffer of the events to be published
+const (
+	EventOverflowDropOldest = "DropOldest"
+	EventOverflowBlock      = "Block"
+)
+
+// handling of the request URIs with a trailing slash
+const (
+	TrailingSlashIgnore   = "Ignore"
+	TrailingSlashRedirect = "Redirect"
+)
+
+// compression algorithms of the responses, as named in Accept-Encoding
+const (
+	CompressionGzip    = "gzip"
+	CompressionDeflate = "deflate"
+	CompressionBrotli  = "br"
+)
+
+// AllowedCompressionAlgorithms is for checking the compression algorithms supported
+var AllowedCompressionAlgorithms = map[string]bool{
+	CompressionGzip:    true,
+	CompressionDeflate: true,
+	CompressionBrotli:  true,
+}
+
+// DefaultCompressionAlgorithms is the list of compression algorithms enabled when not configured,
+// br is left out as it costs more CPU
+var DefaultCompressionAlgorithms = []string{CompressionGzip, CompressionDeflate}
+
+// audit log sinks supported
+const (
+	AuditSinkFile       = "File"
+	AuditSinkMessageBus = "MessageBus"
+)
+
+// AllowedOTelExporters is for checking the span exporters supported
+var AllowedOTelExporters = map[string]bool{
+	"log":      true,
+	"otlphttp": true,
+}
+
+// HTTP2RequiredCipherSuites is for checking that the TLS cipher suites include one which HTTP/2 requires
+var HTTP2RequiredCipherSuites = map[string]bool{
+	"TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256":   true,
+	"TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256": true,
+}
+
+// actions of the URL rewrite rules
+const (
+	URLRewriteReplace     = "Replace"
+	URLRewriteAddPrefix   = "AddPrefix"
+	URLRewriteStripPrefix = "StripPrefix"
+)
+
+// policies for the health of the ports without health score in APIC or whose health can't be read
+const (
+	UnknownHealthOK      = "OK"
+	UnknownHealthWarning = "Warning"
+	UnknownHealthIgnore  = "Ignore"
 )
 
 // AllowedMessageBusTypes is for checking for message types are allowed
 var AllowedMessageBusTypes = map[string]bool{
 	"Kafka": true,
 }
+
+// DefaultAPICSubscriptionClasses is the list of classes subscribed over APIC websocket when not configured,
+// ethpmPhysIf carries the operational state of the physical interfaces
+var DefaultAPICSubscriptionClasses = []string{"ethpmPhysIf"}
+
+// DefaultPortOperStates is the Redfish state reported for the APIC operSt values of the ports,
+// along with the operStQual values giving the reason of a port being down
+var DefaultPortOperStates = map[string]PortOperState{
+	"up":           {LinkState: "Enabled", LinkStatus: "LinkUp", State: "Enabled"},
+	"link-up":      {LinkState: "Enabled", LinkStatus: "Starting", State: "Starting"},
+	"down":         {LinkState: "Disabled", LinkStatus: "LinkDown", State: "Disabled"},
+	"admin-down":   {LinkState: "Disabled", LinkStatus: "LinkDown", State: "Disabled"},
+	"err-disabled": {LinkState: "Enabled", LinkStatus: "LinkDown", State: "UnavailableOffline"},
+	"unknown":      {LinkState: "Disabled", LinkStatus: "NoLink", State: "Disabled"},
+}
+
+// AllowedPortLinkStates is for checking the LinkState values of the port
+var AllowedPortLinkStates = map[string]bool{
+	"Enabled":  true,
+	"Disabled": true,
+}
+
+// AllowedPortLinkStatuses is for checking the LinkStatus values of the port
+var AllowedPortLinkStatuses = map[string]bool{
+	"LinkUp":   true,
+	"Starting": true,
+	"Training": true,
+	"LinkDown": true,
+	"NoLink":   true,
+}
+
+// AllowedPortStates is for checking the Status.State values of the port
+var AllowedPortStates = map[string]bool{
+	"Enabled":            true,
+	"Disabled":           true,
+	"StandbyOffline":     true,
+	"StandbySpare":       true,
+	"InTest":             true,
+	"Starting":           true,
+	"Absent":             true,
+	"UnavailableOffline": true,
+	"Deferring":          true,
+	"Quiesced":           true,
+	"Updating":           true,
+	"Qualified":          true,
+}
+
+// DefaultWritablePortProperties is the list of port properties which can be modified with PATCH when not configured
+var DefaultWritablePortProperties = []string{"Links"}
+
+// DefaultCORSAllowedMethods is the list of methods allowed for cross origin requests when not configured
+var DefaultCORSAllowedMethods = []string{"GET", "POST", "PATCH", "DELETE"}
+
+// DefaultCORSAllowedHeaders is the list of headers allowed for cross origin requests when not configured
+var DefaultCORSAllowedHeaders = []string{"Authorization", "Content-Type", "X-Auth-Token"}
@@ -224,16 +224,21 @@ ZlgyKGJ8O0RB8M1vofMdLfMCAwEAAQ==
 )
 
 // SetUpMockConfig set ups a mock ration for unit testing
-func SetUpMockConfig(t *testing.T) error {
+func SetUpMockConfig(t testing.TB) error {
 	Data.RootServiceUUID = "3bd1f589-117a-4cf9-89f2-da44ee8e2325"
 	Data.FirmwareVersion = "1.0"
 	Data.SessionTimeoutInMinutes = 30
+	refreshSession := true
+	Data.RefreshSessionOnActivity = &refreshSession
 	Data.PluginConf = &PluginConf{
 		ID:       "GRF",
-		Host:     localhost,
+		Host:     BindAddresses{localhost},
 		Port:     "45001",
 		UserName: "admin",
 		Password: "O01bKrP7Tzs7YoO3YvQt4pRa2J_R6HI34ZfP4MxbqNIYAVQVt2ewGXmhjvBfzMifM7bHFccXKGmdHvj3hY44Hw==",
+		Vendor:   DefaultPluginVendor,
+		Model:    DefaultPluginModel,
+		Name:     "GRF",
 	}
 	Data.LoadBalancerConf = &LoadBalancerConf{
 		Host: localhost,
@@ -245,8 +250,13 @@ func SetUpMockConfig(t *testing.T) error {
 		ListenerPort: "45002",
 	}
 	Data.MessageBusConf = &MessageBusConf{
-		EmbType:  "Kafka",
-		EmbQueue: []string{"REDFISH-EVENTS-TOPIC"},
+		EmbType:                            "Kafka",
+		EmbQueue:                           []string{"REDFISH-EVENTS-TOPIC"},
+		EventBufferCapacity:                DefaultEventBufferCapacity,
+		EventOverflowPolicy:                EventOverflowDropOldest,
+		EventBlockTimeoutInSeconds:         DefaultEventBlockTimeout,
+		EventBacklogCapacity:               DefaultEventBacklogCapacity,
+		EventBacklogRetryIntervalInSeconds: DefaultEventBacklogRetryInterval,
 	}
 	Data.KeyCertConf = &KeyCertConf{
 		RootCACertificate: hostCA,
@@ -262,6 +272,7 @@ func SetUpMockConfig(t *testing.T) error {
 			"redfish": "ODIM",
 		},
 	}
+	Data.URLTranslation.CompileSouthBound()
 	Data.TLSConf = &TLSConf{
 		VerifyPeer: true,
 		MinVersion: "TLS_1.2",
@@ -279,6 +290,48 @@ func SetUpMockConfig(t *testing.T) error {
 	lutilconf.SetTLSMaxVersion(Data.TLSConf.MaxVersion)
 	lutilconf.SetPreferredCipherSuites(Data.TLSConf.PreferredCipherSuites)
 
+	Data.APICConf = &APICConf{
+		APICHost: localhost,
+		UserName: "admin",
+		Password: "password",
+		DomainData: map[string]string{
+			"ValidDomain": "uni/phys-ValidDomain",
+		},
+		PortStatsHistoryMaxSamples:             DefaultPortStatsHistoryMaxSamples,
+		PortSettingsReconcileIntervalInSeconds: DefaultPortSettingsReconcileInterval,
+		UnavailableHealthPolicy:                UnknownHealthWarning,
+		TokenClockSkewInSeconds:                DefaultAPICTokenClockSkew,
+		RefreshJitter:                          DefaultAPICRefreshJitter,
+		QueryPageSize:                          DefaultAPICQueryPageSize,
+		PortIDFormat:                           DefaultPortIDFormat,
+		HealthPollConcurrency:                  DefaultHealthPollConcurrency,
+	}
+	Data.ServerConf = &ServerConf{
+		ReadTimeoutInSeconds:       DefaultServerReadTimeout,
+		WriteTimeoutInSeconds:      DefaultServerWriteTimeout,
+		IdleTimeoutInSeconds:       DefaultServerIdleTimeout,
+		IdempotencyKeyTTLInSeconds: DefaultIdempotencyKeyTTL,
+		MaxPortEventStreams:        DefaultMaxPortEventStreams,
+		CacheMaxAgeInSeconds:       DefaultCacheMaxAge,
+		MaxPageSize:                DefaultMaxPageSize,
+		LogSampleRate:              DefaultLogSampleRate,
+		TrailingSlashPolicy:        TrailingSlashIgnore,
+		StartupAttempts:            DefaultStartupAttempts,
+		StartupRetryDelayInSeconds: DefaultStartupRetryDelay,
+		CompressionAlgorithms:      DefaultCompressionAlgorithms,
+		CompressionMinSizeInBytes:  DefaultCompressionMinSize,
+	}
+	Data.ClientConf = &ClientConf{
+		MaxIdleConns:             DefaultClientMaxIdleConns,
+		MaxIdleConnsPerHost:      DefaultClientMaxIdleConnsPerHost,
+		IdleConnTimeoutInSeconds: DefaultClientIdleConnTimeout,
+	}
+	Data.ODIMConf = &ODIMConf{
+		URL:      "https://" + localhost + ":45000",
+		UserName: "admin",
+		Password: "password",
+	}
+
 	Data.DBConf = &DBConf{
 		Protocol:                     "tcp",
 		Host:                         "ValidHost",
@@ -290,6 +343,8 @@ func SetUpMockConfig(t *testing.T) error {
 		MasterSet:                    "ValidMasterSet",
 		RedisOnDiskEncryptedPassword: redisPassword,
 	}
+	// the changes of the tests to Data are seen by the readers of the current configuration
+	current.Store(&Data)
 
 	return nil
 }
@@ -15,7 +15,16 @@
 package config
 
 import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/json"
+	"reflect"
+	"strings"
 	"testing"
+
+	"golang.org/x/crypto/sha3"
 )
 
 func TestSetUpMockConfig(t *testing.T) {
@@ -52,7 +61,831 @@ func TestSetUpMockConfig(t *testing.T) {
 	if Data.TLSConf == nil {
 		t.Error("error: Data.TLSConf is not initialized")
 	}
-	if err := checkDBConf(); err != nil {
+	if err := Data.checkDBConf(); err != nil {
 		t.Error("error: Data.DBConf validation failed: " + err.Error())
 	}
 }
+
+func TestCheckPasswordComplexity(t *testing.T) {
+	SetUpMockConfig(t)
+	policy := &PasswordPolicy{
+		Enabled:          true,
+		MinLength:        12,
+		RequireUppercase: true,
+		RequireLowercase: true,
+		RequireDigit:     true,
+		RequireSpecial:   true,
+	}
+	tests := []struct {
+		name     string
+		policy   *PasswordPolicy
+		password string
+		wantErr  string
+	}{
+		{"policy not configured", nil, "weak", ""},
+		{"policy disabled", &PasswordPolicy{MinLength: 12}, "weak", ""},
+		{"complex password", policy, "Plugin@ACI2020", ""},
+		{"too short", policy, "Pl@ACI20", "at least 12 characters"},
+		{"no uppercase", policy, "plugin@aci2020", "an uppercase letter"},
+		{"no lowercase", policy, "PLUGIN@ACI2020", "a lowercase letter"},
+		{"no digit", policy, "Plugin@ACIOdim", "a digit"},
+		{"no special character", policy, "PluginACI2020x", "a special character"},
+		{"only length required", &PasswordPolicy{Enabled: true, MinLength: 8}, "pluginaci", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			Data.PluginConf.PasswordPolicy = tt.policy
+			err := CheckPasswordComplexity(tt.password)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("CheckPasswordComplexity() error = %v, want nil", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("CheckPasswordComplexity() error = %v, want error containing %q", err, tt.wantErr)
+			}
+		})
+	}
+	Data.PluginConf.PasswordPolicy = nil
+}
+
+// encryptTestPassword encrypts the password with the public key of the mock RSA private key
+func encryptTestPassword(t *testing.T, password string) string {
+	priv, err := bytesToPrivateKey(Data.KeyCertConf.RSAPrivateKey)
+	if err != nil {
+		t.Fatalf("bytesToPrivateKey() error = %v", err)
+	}
+	encrypted, err := rsa.EncryptOAEP(sha512.New(), rand.Reader, &priv.PublicKey, []byte(password), nil)
+	if err != nil {
+		t.Fatalf("EncryptOAEP() error = %v", err)
+	}
+	return base64.StdEncoding.EncodeToString(encrypted)
+}
+
+func TestCheckPluginPasswordComplexity(t *testing.T) {
+	SetUpMockConfig(t)
+	defer func() { Data.PluginConf.PasswordPolicy = nil }()
+	hashPassword := func(password string) string {
+		hash := sha3.New512()
+		hash.Write([]byte(password))
+		return base64.URLEncoding.EncodeToString(hash.Sum(nil))
+	}
+	policy := &PasswordPolicy{Enabled: true, MinLength: 12, RequireDigit: true}
+	tests := []struct {
+		name              string
+		policy            *PasswordPolicy
+		password          string
+		encryptedPassword string
+		wantErr           bool
+	}{
+		{"policy not configured", nil, hashPassword("weak"), "", false},
+		{"complex password", policy, hashPassword("Plugin@ACI2020"), encryptTestPassword(t, "Plugin@ACI2020"), false},
+		{"weak password", policy, hashPassword("weak"), encryptTestPassword(t, "weak"), true},
+		{"no encrypted password", policy, hashPassword("Plugin@ACI2020"), "", true},
+		{"encrypted password not matching", policy, hashPassword("Plugin@ACI2020"), encryptTestPassword(t, "Plugin@ACI2021"), true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			Data.PluginConf.PasswordPolicy = tt.policy
+			Data.PluginConf.Password = tt.password
+			Data.PluginConf.EncryptedPassword = tt.encryptedPassword
+			if err := Data.checkPluginPasswordComplexity(); (err != nil) != tt.wantErr {
+				t.Errorf("checkPluginPasswordComplexity() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestCheckPasswordPolicy(t *testing.T) {
+	SetUpMockConfig(t)
+	tests := []struct {
+		name     string
+		policy   *PasswordPolicy
+		userName string
+		wantErr  bool
+	}{
+		{"policy not configured", nil, "admin", false},
+		{"defaults applied", &PasswordPolicy{Enabled: true}, "admin", false},
+		{"short username", &PasswordPolicy{Enabled: true, MinUserNameLength: 6}, "admin", true},
+		{"negative length", &PasswordPolicy{Enabled: true, MinLength: -1}, "admin", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			Data.PluginConf.PasswordPolicy = tt.policy
+			Data.PluginConf.UserName = tt.userName
+			if err := Data.checkPasswordPolicy(); (err != nil) != tt.wantErr {
+				t.Errorf("checkPasswordPolicy() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+	Data.PluginConf.PasswordPolicy = nil
+}
+
+func TestCheckAPICConfTenant(t *testing.T) {
+	SetUpMockConfig(t)
+	tests := []struct {
+		name    string
+		tenant  string
+		wantErr bool
+	}{
+		{"tenant not configured", "", false},
+		{"valid tenant", "Sales_01", false},
+		{"tenant with dn separator", "Sales/ap-web", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			Data.APICConf.Tenant = tt.tenant
+			if err := Data.checkAPICConf(); (err != nil) != tt.wantErr {
+				t.Errorf("checkAPICConf() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+	Data.APICConf.Tenant = ""
+}
+
+func TestCheckAPICConfAPIBasePath(t *testing.T) {
+	SetUpMockConfig(t)
+	tests := []struct {
+		name     string
+		basePath string
+		want     string
+		wantErr  bool
+	}{
+		{"default applied", "", DefaultAPICAPIBasePath, false},
+		{"proxied path", "/apic/api", "/apic/api", false},
+		{"without leading slash", "api", "", true},
+		{"with trailing slash", "/api/", "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			Data.APICConf.APIBasePath = tt.basePath
+			err := Data.checkAPICConf()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("checkAPICConf() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && Data.APICConf.APIBasePath != tt.want {
+				t.Errorf("APIBasePath = %s, want %s", Data.APICConf.APIBasePath, tt.want)
+			}
+		})
+	}
+	Data.APICConf.APIBasePath = ""
+}
+
+func TestCheckAPICRateLimit(t *testing.T) {
+	SetUpMockConfig(t)
+	Data.APICConf.RequestsPerSecond = 5
+	if err := Data.checkAPICRateLimit(); err != nil {
+		t.Fatalf("checkAPICRateLimit() error = %v", err)
+	}
+	if Data.APICConf.RequestBurst != DefaultAPICRequestBurst || Data.APICConf.RateLimitWaitInMilliseconds != DefaultAPICRateLimitWait {
+		t.Errorf("checkAPICRateLimit() set burst %d and wait %d, want the defaults", Data.APICConf.RequestBurst, Data.APICConf.RateLimitWaitInMilliseconds)
+	}
+	Data.APICConf.RequestsPerSecond = -1
+	if err := Data.checkAPICRateLimit(); err == nil {
+		t.Error("checkAPICRateLimit() accepted negative RequestsPerSecond")
+	}
+	Data.APICConf.RequestsPerSecond = 0
+}
+
+func TestCheckAPICCluster(t *testing.T) {
+	SetUpMockConfig(t)
+	defer func() {
+		Data.APICConf.ClusterHosts = nil
+		Data.APICConf.QuorumReads = false
+	}()
+	Data.APICConf.QuorumReads = true
+	if err := Data.checkAPICConf(); err == nil {
+		t.Error("checkAPICConf() with QuorumReads without ClusterHosts, want error")
+	}
+	Data.APICConf.ClusterHosts = []string{Data.APICConf.APICHost}
+	if err := Data.checkAPICConf(); err == nil {
+		t.Error("checkAPICConf() with APICHost in ClusterHosts, want error")
+	}
+	Data.APICConf.ClusterHosts = []string{"apic2.example.com", "apic3.example.com"}
+	if err := Data.checkAPICConf(); err != nil {
+		t.Errorf("checkAPICConf() with ClusterHosts error = %v, want nil", err)
+	}
+}
+
+func TestCheckAPICConfHostWeights(t *testing.T) {
+	SetUpMockConfig(t)
+	defer func() {
+		Data.APICConf.ClusterHosts = nil
+		Data.APICConf.BalanceReads = false
+		Data.APICConf.HostWeights = nil
+	}()
+	Data.APICConf.BalanceReads = true
+	if err := Data.checkAPICConf(); err == nil {
+		t.Error("checkAPICConf() with BalanceReads without ClusterHosts, want error")
+	}
+	Data.APICConf.ClusterHosts = []string{"apic2.example.com"}
+	tests := []struct {
+		name    string
+		weights map[string]int
+		wantErr bool
+	}{
+		{"equal weights", nil, false},
+		{"weights of the controllers", map[string]int{Data.APICConf.APICHost: 1, "apic2.example.com": 3}, false},
+		{"zero weight", map[string]int{"apic2.example.com": 0}, true},
+		{"negative weight", map[string]int{Data.APICConf.APICHost: -1}, true},
+		{"unknown controller", map[string]int{"apic3.example.com": 1}, true},
+	}
+	for _, tt := range tests {
+		Data.APICConf.HostWeights = tt.weights
+		if err := Data.checkAPICConf(); (err != nil) != tt.wantErr {
+			t.Errorf("checkAPICConf() with %s error = %v, wantErr %v", tt.name, err, tt.wantErr)
+		}
+	}
+}
+
+func TestCheckAPICConfLoginDomain(t *testing.T) {
+	SetUpMockConfig(t)
+	for domain, wantErr := range map[string]bool{"": false, "TACACS": false, `TACACS\admin`: true} {
+		Data.APICConf.LoginDomain = domain
+		if err := Data.checkAPICConf(); (err != nil) != wantErr {
+			t.Errorf("checkAPICConf() with LoginDomain %q error = %v, wantErr %v", domain, err, wantErr)
+		}
+	}
+	Data.APICConf.LoginDomain = ""
+}
+
+func TestCheckAPICConfTLSServerName(t *testing.T) {
+	SetUpMockConfig(t)
+	defer func() { Data.APICConf.TLSServerName = "" }()
+	for name, wantErr := range map[string]bool{"": false, "apic.example.com": false, "apic1": false, "https://apic.example.com": true, "apic example": true, "-apic": true} {
+		Data.APICConf.TLSServerName = name
+		if err := Data.checkAPICConf(); (err != nil) != wantErr {
+			t.Errorf("checkAPICConf() with TLSServerName %q error = %v, wantErr %v", name, err, wantErr)
+		}
+	}
+}
+
+func TestCheckAPICConfTokenClockSkew(t *testing.T) {
+	SetUpMockConfig(t)
+	defer func() { Data.APICConf.TokenClockSkewInSeconds = DefaultAPICTokenClockSkew }()
+	for skew, wantErr := range map[int]bool{-1: true, 10: false, APICTokenLifetime - 1: false, APICTokenLifetime: true} {
+		Data.APICConf.TokenClockSkewInSeconds = skew
+		if err := Data.checkAPICConf(); (err != nil) != wantErr {
+			t.Errorf("checkAPICConf() with TokenClockSkewInSeconds %d error = %v, wantErr %v", skew, err, wantErr)
+		}
+	}
+	Data.APICConf.TokenClockSkewInSeconds = 0
+	if err := Data.checkAPICConf(); err != nil || Data.APICConf.TokenClockSkewInSeconds != DefaultAPICTokenClockSkew {
+		t.Errorf("checkAPICConf() without TokenClockSkewInSeconds = %d, %v, want %d", Data.APICConf.TokenClockSkewInSeconds, err, DefaultAPICTokenClockSkew)
+	}
+}
+
+func TestCheckAPICConfDefaultDomain(t *testing.T) {
+	SetUpMockConfig(t)
+	defer func() { Data.APICConf.DefaultDomain = "" }()
+	Data.APICConf.DefaultDomain = "MissingDomain"
+	if err := Data.checkAPICConf(); err == nil || !strings.Contains(err.Error(), "ValidDomain") {
+		t.Errorf("checkAPICConf() with DefaultDomain missing in DomainData error = %v, want error listing the DomainData keys", err)
+	}
+	Data.APICConf.DefaultDomain = "ValidDomain"
+	if err := Data.checkAPICConf(); err != nil {
+		t.Errorf("checkAPICConf() with DefaultDomain in DomainData error = %v", err)
+	}
+}
+
+func TestAPICConfDomainDN(t *testing.T) {
+	SetUpMockConfig(t)
+	defer func() { Data.APICConf.DefaultDomain = "" }()
+	if dn, err := Data.APICConf.DomainDN("ValidDomain"); err != nil || dn != "uni/phys-ValidDomain" {
+		t.Errorf("DomainDN() of mapped domain = %s, %v, want uni/phys-ValidDomain", dn, err)
+	}
+
+	// miss without default domain
+	_, err := Data.APICConf.DomainDN("StorageDomain")
+	if err == nil || !strings.Contains(err.Error(), "StorageDomain") || !strings.Contains(err.Error(), "[ValidDomain]") {
+		t.Errorf("DomainDN() of missing domain error = %v, want error naming the key and the available keys", err)
+	}
+
+	// miss with default domain
+	Data.APICConf.DefaultDomain = "ValidDomain"
+	if dn, err := Data.APICConf.DomainDN("StorageDomain"); err != nil || dn != "uni/phys-ValidDomain" {
+		t.Errorf("DomainDN() of missing domain with default = %s, %v, want uni/phys-ValidDomain", dn, err)
+	}
+}
+
+func TestCheckAPICConfHealthPollConcurrency(t *testing.T) {
+	SetUpMockConfig(t)
+	defer func() { Data.APICConf.HealthPollConcurrency = DefaultHealthPollConcurrency }()
+	Data.APICConf.HealthPollConcurrency = -1
+	if err := Data.checkAPICConf(); err == nil {
+		t.Error("checkAPICConf() with negative HealthPollConcurrency succeeded, want error")
+	}
+	Data.APICConf.HealthPollConcurrency = 0
+	if err := Data.checkAPICConf(); err != nil || Data.APICConf.HealthPollConcurrency != DefaultHealthPollConcurrency {
+		t.Errorf("checkAPICConf() without HealthPollConcurrency = %d, %v, want %d", Data.APICConf.HealthPollConcurrency, err, DefaultHealthPollConcurrency)
+	}
+}
+
+func TestCheckAPICConfPortIDFormat(t *testing.T) {
+	SetUpMockConfig(t)
+	defer func() { Data.APICConf.PortIDFormat = DefaultPortIDFormat }()
+	for _, format := range []string{"Ethernet", "{id}/{id}", "{slot}/{id}", "eth{id"} {
+		Data.APICConf.PortIDFormat = format
+		if err := Data.checkAPICConf(); err == nil {
+			t.Errorf("checkAPICConf() with PortIDFormat %s succeeded, want error", format)
+		}
+	}
+	for _, format := range []string{"{id}", "Ethernet{id}"} {
+		Data.APICConf.PortIDFormat = format
+		if err := Data.checkAPICConf(); err != nil {
+			t.Errorf("checkAPICConf() with PortIDFormat %s error = %v", format, err)
+		}
+	}
+	Data.APICConf.PortIDFormat = ""
+	if err := Data.checkAPICConf(); err != nil || Data.APICConf.PortIDFormat != DefaultPortIDFormat {
+		t.Errorf("checkAPICConf() without PortIDFormat = %s, %v, want %s", Data.APICConf.PortIDFormat, err, DefaultPortIDFormat)
+	}
+}
+
+func TestCheckAPICConfQueryPageSize(t *testing.T) {
+	SetUpMockConfig(t)
+	defer func() { Data.APICConf.QueryPageSize = DefaultAPICQueryPageSize }()
+	Data.APICConf.QueryPageSize = -1
+	if err := Data.checkAPICConf(); err == nil {
+		t.Error("checkAPICConf() with negative QueryPageSize succeeded, want error")
+	}
+	Data.APICConf.QueryPageSize = 0
+	if err := Data.checkAPICConf(); err != nil || Data.APICConf.QueryPageSize != DefaultAPICQueryPageSize {
+		t.Errorf("checkAPICConf() without QueryPageSize = %d, %v, want %d", Data.APICConf.QueryPageSize, err, DefaultAPICQueryPageSize)
+	}
+}
+
+func TestCheckAPICConfUnknownHealthPolicy(t *testing.T) {
+	SetUpMockConfig(t)
+	tests := []struct {
+		policy  string
+		want    string
+		wantErr bool
+	}{
+		{"", UnknownHealthIgnore, false},
+		{UnknownHealthWarning, UnknownHealthWarning, false},
+		{"Critical", "", true},
+	}
+	for _, tt := range tests {
+		Data.APICConf.UnknownHealthPolicy = tt.policy
+		err := Data.checkAPICConf()
+		if (err != nil) != tt.wantErr {
+			t.Errorf("checkAPICConf() with UnknownHealthPolicy %q error = %v, wantErr %v", tt.policy, err, tt.wantErr)
+		}
+		if !tt.wantErr && Data.APICConf.UnknownHealthPolicy != tt.want {
+			t.Errorf("UnknownHealthPolicy = %s, want %s", Data.APICConf.UnknownHealthPolicy, tt.want)
+		}
+	}
+	Data.APICConf.UnknownHealthPolicy = ""
+}
+
+func TestCheckAPICConfUnavailableHealthPolicy(t *testing.T) {
+	SetUpMockConfig(t)
+	tests := []struct {
+		policy  string
+		want    string
+		wantErr bool
+	}{
+		{"", UnknownHealthWarning, false},
+		{UnknownHealthIgnore, UnknownHealthIgnore, false},
+		{"Unknown", "", true},
+	}
+	for _, tt := range tests {
+		Data.APICConf.UnavailableHealthPolicy = tt.policy
+		err := Data.checkAPICConf()
+		if (err != nil) != tt.wantErr {
+			t.Errorf("checkAPICConf() with UnavailableHealthPolicy %q error = %v, wantErr %v", tt.policy, err, tt.wantErr)
+		}
+		if !tt.wantErr && Data.APICConf.UnavailableHealthPolicy != tt.want {
+			t.Errorf("UnavailableHealthPolicy = %s, want %s", Data.APICConf.UnavailableHealthPolicy, tt.want)
+		}
+	}
+	Data.APICConf.UnavailableHealthPolicy = UnknownHealthWarning
+}
+
+func TestCheckAPICConfPortFlapGrace(t *testing.T) {
+	SetUpMockConfig(t)
+	Data.APICConf.PortFlapGraceInSeconds = -1
+	if err := Data.checkAPICConf(); err == nil {
+		t.Error("checkAPICConf() with negative PortFlapGraceInSeconds, want error")
+	}
+	Data.APICConf.PortFlapGraceInSeconds = 30
+	if err := Data.checkAPICConf(); err != nil {
+		t.Errorf("checkAPICConf() with PortFlapGraceInSeconds 30 error = %v", err)
+	}
+	Data.APICConf.PortFlapGraceInSeconds = 0
+}
+
+func TestCheckAPICConfPortStatsHistoryMaxSamples(t *testing.T) {
+	SetUpMockConfig(t)
+	Data.APICConf.PortStatsHistoryMaxSamples = -1
+	if err := Data.checkAPICConf(); err == nil {
+		t.Error("checkAPICConf() with negative PortStatsHistoryMaxSamples, want error")
+	}
+	Data.APICConf.PortStatsHistoryMaxSamples = 0
+	if err := Data.checkAPICConf(); err != nil || Data.APICConf.PortStatsHistoryMaxSamples != DefaultPortStatsHistoryMaxSamples {
+		t.Errorf("PortStatsHistoryMaxSamples = %d, %v, want default %d", Data.APICConf.PortStatsHistoryMaxSamples, err, DefaultPortStatsHistoryMaxSamples)
+	}
+}
+
+func TestCheckAPICConfPortSettingsReconcileInterval(t *testing.T) {
+	SetUpMockConfig(t)
+	Data.APICConf.PortSettingsReconcileIntervalInSeconds = -1
+	if err := Data.checkAPICConf(); err == nil {
+		t.Error("checkAPICConf() with negative PortSettingsReconcileIntervalInSeconds, want error")
+	}
+	Data.APICConf.PortSettingsReconcileIntervalInSeconds = 0
+	if err := Data.checkAPICConf(); err != nil || Data.APICConf.PortSettingsReconcileIntervalInSeconds != DefaultPortSettingsReconcileInterval {
+		t.Errorf("PortSettingsReconcileIntervalInSeconds = %d, %v, want default %d", Data.APICConf.PortSettingsReconcileIntervalInSeconds, err, DefaultPortSettingsReconcileInterval)
+	}
+}
+
+func TestCheckAPICConfPortMetricReportInterval(t *testing.T) {
+	SetUpMockConfig(t)
+	Data.APICConf.PortMetricReportIntervalInSeconds = -1
+	if err := Data.checkAPICConf(); err == nil {
+		t.Error("checkAPICConf() with negative PortMetricReportIntervalInSeconds, want error")
+	}
+	Data.APICConf.PortMetricReportIntervalInSeconds = 0
+	if err := Data.checkAPICConf(); err != nil || Data.APICConf.PortMetricReportIntervalInSeconds != 0 {
+		t.Errorf("PortMetricReportIntervalInSeconds = %d, %v, want 0 for the reports generated on request", Data.APICConf.PortMetricReportIntervalInSeconds, err)
+	}
+}
+
+func TestCheckAPICConfRefreshJitter(t *testing.T) {
+	SetUpMockConfig(t)
+	for _, jitter := range []float64{-0.1, 1, 1.5} {
+		Data.APICConf.RefreshJitter = jitter
+		if err := Data.checkAPICConf(); err == nil {
+			t.Errorf("checkAPICConf() with RefreshJitter %v, want error", jitter)
+		}
+	}
+	Data.APICConf.RefreshJitter = 0
+	if err := Data.checkAPICConf(); err != nil || Data.APICConf.RefreshJitter != DefaultAPICRefreshJitter {
+		t.Errorf("RefreshJitter = %v, %v, want default %v", Data.APICConf.RefreshJitter, err, DefaultAPICRefreshJitter)
+	}
+}
+
+func TestCheckAuditConf(t *testing.T) {
+	SetUpMockConfig(t)
+	defer func() { Data.AuditConf = nil }()
+	for _, conf := range []AuditConf{{Sink: "Syslog"}, {Sink: AuditSinkFile}, {Sink: AuditSinkMessageBus}} {
+		Data.AuditConf = &conf
+		if err := Data.checkAuditConf(); err == nil {
+			t.Errorf("checkAuditConf() with %+v, want error", conf)
+		}
+	}
+	for _, conf := range []AuditConf{{Sink: AuditSinkFile, FilePath: "/var/log/plugin-audit.log"}, {Sink: AuditSinkMessageBus, Topic: "AUDIT"}} {
+		Data.AuditConf = &conf
+		if err := Data.checkAuditConf(); err != nil {
+			t.Errorf("checkAuditConf() with %+v error = %v, want nil", conf, err)
+		}
+	}
+}
+
+func TestCheckDBKeyPrefix(t *testing.T) {
+	for _, prefix := range []string{"", "prod:aci:", "tenant-1/"} {
+		if err := checkDBKeyPrefix(prefix); err != nil {
+			t.Errorf("checkDBKeyPrefix(%q) error = %v", prefix, err)
+		}
+	}
+	for _, prefix := range []string{"prod aci:", "prod:aci:\t", "prod:*:", "prod[1]:"} {
+		if err := checkDBKeyPrefix(prefix); err == nil {
+			t.Errorf("checkDBKeyPrefix(%q), want error", prefix)
+		}
+	}
+}
+
+func TestCheckServerConfLogSampleRate(t *testing.T) {
+	SetUpMockConfig(t)
+	Data.ServerConf.LogSampleRate = -1
+	if err := Data.checkServerConf(); err == nil {
+		t.Error("checkServerConf() with negative LogSampleRate, want error")
+	}
+	Data.ServerConf.LogSampleRate = 0
+	if err := Data.checkServerConf(); err != nil || Data.ServerConf.LogSampleRate != DefaultLogSampleRate {
+		t.Errorf("checkServerConf() LogSampleRate = %d, %v, want default %d", Data.ServerConf.LogSampleRate, err, DefaultLogSampleRate)
+	}
+}
+
+func TestCheckServerConfCacheMaxAge(t *testing.T) {
+	SetUpMockConfig(t)
+	Data.ServerConf.CacheMaxAgeInSeconds = -1
+	if err := Data.checkServerConf(); err == nil {
+		t.Error("checkServerConf() with negative CacheMaxAgeInSeconds, want error")
+	}
+	Data.ServerConf.CacheMaxAgeInSeconds = 0
+	if err := Data.checkServerConf(); err != nil || Data.ServerConf.CacheMaxAgeInSeconds != DefaultCacheMaxAge {
+		t.Errorf("checkServerConf() CacheMaxAgeInSeconds = %d, %v, want default %d", Data.ServerConf.CacheMaxAgeInSeconds, err, DefaultCacheMaxAge)
+	}
+}
+
+func TestCheckServerConfMaxPageSize(t *testing.T) {
+	SetUpMockConfig(t)
+	Data.ServerConf.MaxPageSize = -1
+	if err := Data.checkServerConf(); err == nil {
+		t.Error("checkServerConf() with negative MaxPageSize, want error")
+	}
+	Data.ServerConf.MaxPageSize = 0
+	if err := Data.checkServerConf(); err != nil || Data.ServerConf.MaxPageSize != DefaultMaxPageSize {
+		t.Errorf("checkServerConf() MaxPageSize = %d, %v, want default %d", Data.ServerConf.MaxPageSize, err, DefaultMaxPageSize)
+	}
+}
+
+func TestCheckServerConfStartup(t *testing.T) {
+	SetUpMockConfig(t)
+	Data.ServerConf.StartupAttempts = -1
+	if err := Data.checkServerConf(); err == nil {
+		t.Error("checkServerConf() with negative StartupAttempts, want error")
+	}
+	Data.ServerConf.StartupAttempts = 0
+	Data.ServerConf.StartupRetryDelayInSeconds = -1
+	if err := Data.checkServerConf(); err == nil {
+		t.Error("checkServerConf() with negative StartupRetryDelayInSeconds, want error")
+	}
+	Data.ServerConf.StartupRetryDelayInSeconds = 0
+	if err := Data.checkServerConf(); err != nil || Data.ServerConf.StartupAttempts != DefaultStartupAttempts ||
+		Data.ServerConf.StartupRetryDelayInSeconds != DefaultStartupRetryDelay {
+		t.Errorf("checkServerConf() StartupAttempts = %d, StartupRetryDelayInSeconds = %d, %v, want defaults %d, %d",
+			Data.ServerConf.StartupAttempts, Data.ServerConf.StartupRetryDelayInSeconds, err, DefaultStartupAttempts, DefaultStartupRetryDelay)
+	}
+}
+
+func TestCheckServerConfHTTP2(t *testing.T) {
+	SetUpMockConfig(t)
+	Data.ServerConf.EnableHTTP2 = true
+	if err := Data.checkServerConf(); err != nil {
+		t.Errorf("checkServerConf() with EnableHTTP2 over TLS error = %v", err)
+	}
+	Data.TLSConf.PreferredCipherSuites = []string{"TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384"}
+	if err := Data.checkServerConf(); err == nil {
+		t.Error("checkServerConf() with EnableHTTP2 without an AES_128_GCM_SHA256 cipher suite, want error")
+	}
+	Data.TLSConf.PreferredCipherSuites = nil
+	Data.TLSConf.MaxVersion = "TLS_1.1"
+	if err := Data.checkServerConf(); err == nil {
+		t.Error("checkServerConf() with EnableHTTP2 and TLS MaxVersion TLS_1.1, want error")
+	}
+	Data.TLSConf.MaxVersion = "TLS_1.2"
+	Data.KeyCertConf.Certificate = nil
+	if err := Data.checkServerConf(); err == nil {
+		t.Error("checkServerConf() with EnableHTTP2 without TLS certificate, want error")
+	}
+	Data.ServerConf.EnableHTTP2 = false
+	if err := Data.checkServerConf(); err != nil {
+		t.Errorf("checkServerConf() with HTTP/2 disabled error = %v", err)
+	}
+}
+
+func TestCheckServerConfCompression(t *testing.T) {
+	SetUpMockConfig(t)
+	Data.ServerConf.CompressionAlgorithms = []string{CompressionBrotli, "zstd"}
+	if err := Data.checkServerConf(); err == nil {
+		t.Error("checkServerConf() with unknown compression algorithm, want error")
+	}
+	Data.ServerConf.CompressionAlgorithms = nil
+	Data.ServerConf.CompressionMinSizeInBytes = -1
+	if err := Data.checkServerConf(); err == nil {
+		t.Error("checkServerConf() with negative CompressionMinSizeInBytes, want error")
+	}
+	Data.ServerConf.CompressionMinSizeInBytes = 0
+	if err := Data.checkServerConf(); err != nil || !reflect.DeepEqual(Data.ServerConf.CompressionAlgorithms, DefaultCompressionAlgorithms) ||
+		Data.ServerConf.CompressionMinSizeInBytes != DefaultCompressionMinSize {
+		t.Errorf("checkServerConf() CompressionAlgorithms = %v, CompressionMinSizeInBytes = %d, %v, want defaults %v, %d",
+			Data.ServerConf.CompressionAlgorithms, Data.ServerConf.CompressionMinSizeInBytes, err, DefaultCompressionAlgorithms, DefaultCompressionMinSize)
+	}
+}
+
+func TestCheckClientConf(t *testing.T) {
+	SetUpMockConfig(t)
+	for _, conf := range []ClientConf{{MaxIdleConns: -1}, {MaxIdleConnsPerHost: -1}, {IdleConnTimeoutInSeconds: -1}} {
+		Data.ClientConf = &conf
+		if err := Data.checkClientConf(); err == nil {
+			t.Errorf("checkClientConf() with %+v succeeded, want error", conf)
+		}
+	}
+	Data.ClientConf = nil
+	want := ClientConf{MaxIdleConns: DefaultClientMaxIdleConns, MaxIdleConnsPerHost: DefaultClientMaxIdleConnsPerHost,
+		IdleConnTimeoutInSeconds: DefaultClientIdleConnTimeout}
+	if err := Data.checkClientConf(); err != nil || *Data.ClientConf != want {
+		t.Errorf("checkClientConf() without ClientConf = %+v, %v, want %+v", Data.ClientConf, err, want)
+	}
+}
+
+func TestCheckServerConfTrailingSlashPolicy(t *testing.T) {
+	SetUpMockConfig(t)
+	Data.ServerConf.TrailingSlashPolicy = "Strip"
+	if err := Data.checkServerConf(); err == nil {
+		t.Error("checkServerConf() with unknown TrailingSlashPolicy, want error")
+	}
+	Data.ServerConf.TrailingSlashPolicy = TrailingSlashRedirect
+	if err := Data.checkServerConf(); err != nil {
+		t.Errorf("checkServerConf() with Redirect TrailingSlashPolicy error = %v", err)
+	}
+	Data.ServerConf.TrailingSlashPolicy = ""
+	if err := Data.checkServerConf(); err != nil || Data.ServerConf.TrailingSlashPolicy != TrailingSlashIgnore {
+		t.Errorf("checkServerConf() TrailingSlashPolicy = %s, %v, want default %s", Data.ServerConf.TrailingSlashPolicy, err, TrailingSlashIgnore)
+	}
+}
+
+func TestCheckEventBufferConf(t *testing.T) {
+	for _, conf := range []MessageBusConf{{EventBufferCapacity: -1}, {EventOverflowPolicy: "DropNewest"}, {EventBlockTimeoutInSeconds: -1},
+		{EventBacklogCapacity: -1}, {EventBacklogRetryIntervalInSeconds: -1}} {
+		if err := checkEventBufferConf(&conf); err == nil {
+			t.Errorf("checkEventBufferConf() with %+v, want error", conf)
+		}
+	}
+	conf := MessageBusConf{}
+	if err := checkEventBufferConf(&conf); err != nil {
+		t.Fatalf("checkEventBufferConf() error = %v", err)
+	}
+	want := MessageBusConf{EventBufferCapacity: DefaultEventBufferCapacity, EventOverflowPolicy: EventOverflowDropOldest, EventBlockTimeoutInSeconds: DefaultEventBlockTimeout,
+		EventBacklogCapacity: DefaultEventBacklogCapacity, EventBacklogRetryIntervalInSeconds: DefaultEventBacklogRetryInterval}
+	if conf.EventBufferCapacity != want.EventBufferCapacity || conf.EventOverflowPolicy != want.EventOverflowPolicy || conf.EventBlockTimeoutInSeconds != want.EventBlockTimeoutInSeconds ||
+		conf.EventBacklogCapacity != want.EventBacklogCapacity || conf.EventBacklogRetryIntervalInSeconds != want.EventBacklogRetryIntervalInSeconds {
+		t.Errorf("checkEventBufferConf() defaults = %+v, want %+v", conf, want)
+	}
+}
+
+func TestCheckServerConfConcurrencyLimit(t *testing.T) {
+	SetUpMockConfig(t)
+	for _, conf := range []ServerConf{{MaxConcurrentRequests: -1}, {MaxConcurrentRequests: 10, RequestQueueTimeoutInMilliseconds: -1}} {
+		Data.ServerConf = &conf
+		if err := Data.checkServerConf(); err == nil {
+			t.Errorf("checkServerConf() with %+v, want error", conf)
+		}
+	}
+	Data.ServerConf = &ServerConf{MaxConcurrentRequests: 10, RequestQueueTimeoutInMilliseconds: 500}
+	if err := Data.checkServerConf(); err != nil {
+		t.Errorf("checkServerConf() error = %v", err)
+	}
+}
+
+func TestCheckAPICConfDefaultPortAdminState(t *testing.T) {
+	SetUpMockConfig(t)
+	defer func() { Data.APICConf.DefaultPortAdminState = "" }()
+	for _, adminState := range []string{"", PortAdminStateEnabled, PortAdminStateDisabled} {
+		Data.APICConf.DefaultPortAdminState = adminState
+		if err := Data.checkAPICConf(); err != nil {
+			t.Errorf("checkAPICConf() with DefaultPortAdminState %q error = %v", adminState, err)
+		}
+	}
+	for _, adminState := range []string{"enabled", "Up", "Enabled "} {
+		Data.APICConf.DefaultPortAdminState = adminState
+		if err := Data.checkAPICConf(); err == nil {
+			t.Errorf("checkAPICConf() with DefaultPortAdminState %q succeeded, want error", adminState)
+		}
+	}
+}
+
+func TestCheckAPICConfPortOperStates(t *testing.T) {
+	SetUpMockConfig(t)
+	Data.APICConf.PortOperStates = map[string]PortOperState{
+		"link-up": {LinkState: "Enabled", LinkStatus: "Training", State: "Starting"},
+	}
+	if err := Data.checkAPICConf(); err != nil {
+		t.Errorf("checkAPICConf() with valid PortOperStates error = %v", err)
+	}
+	Data.APICConf.PortOperStates["err-disabled"] = PortOperState{LinkState: "Enabled", LinkStatus: "ErrDisabled", State: "Disabled"}
+	if err := Data.checkAPICConf(); err == nil {
+		t.Error("checkAPICConf() with invalid LinkStatus in PortOperStates, want error")
+	}
+	Data.APICConf.PortOperStates = nil
+}
+
+func TestCheckWritablePortProperties(t *testing.T) {
+	SetUpMockConfig(t)
+	tests := []struct {
+		name       string
+		properties []string
+		wantErr    bool
+	}{
+		{"defaults applied", nil, false},
+		{"port properties", []string{"Links", "Description", "CurrentSpeedGbps"}, false},
+		{"unknown property", []string{"Links", "Speed"}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			Data.WritablePortProperties = tt.properties
+			if err := Data.checkWritablePortProperties(); (err != nil) != tt.wantErr {
+				t.Errorf("checkWritablePortProperties() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+	Data.WritablePortProperties = nil
+}
+
+func TestCheckConfDefaultsCopied(t *testing.T) {
+	SetUpMockConfig(t)
+	Data.APICConf.SubscriptionClasses = nil
+	Data.CORSConf = &CORSConf{AllowedOrigins: []string{"https://odim.example.com"}}
+	Data.ServerConf.CompressionAlgorithms = nil
+	Data.WritablePortProperties = nil
+	defer func() {
+		Data.CORSConf = nil
+		Data.WritablePortProperties = nil
+	}()
+	for name, check := range map[string]func() error{"checkAPICConf": Data.checkAPICConf, "checkCORSConf": Data.checkCORSConf,
+		"checkServerConf": Data.checkServerConf, "checkWritablePortProperties": Data.checkWritablePortProperties} {
+		if err := check(); err != nil {
+			t.Fatalf("%s() error = %v", name, err)
+		}
+	}
+	// a reload changing the values in place leaves the defaults of the next one untouched
+	for _, values := range [][]string{Data.APICConf.SubscriptionClasses, Data.CORSConf.AllowedMethods, Data.CORSConf.AllowedHeaders,
+		Data.ServerConf.CompressionAlgorithms, Data.WritablePortProperties} {
+		values[0] = "changed"
+	}
+	for _, defaults := range [][]string{DefaultAPICSubscriptionClasses, DefaultCORSAllowedMethods, DefaultCORSAllowedHeaders,
+		DefaultCompressionAlgorithms, DefaultWritablePortProperties} {
+		if defaults[0] == "changed" {
+			t.Errorf("the configuration shares the default values %v", defaults)
+		}
+	}
+}
+
+func TestCheckURLRewriteRules(t *testing.T) {
+	tests := []struct {
+		name    string
+		rules   []URLRewriteRule
+		wantErr bool
+	}{
+		{"no rules", nil, false},
+		{"prefix rewrite", []URLRewriteRule{{Action: URLRewriteStripPrefix, Match: "/ODIM"}, {Action: URLRewriteAddPrefix, Value: "/proxy/redfish"}}, false},
+		{"unknown action", []URLRewriteRule{{Action: "Rename", Match: "ODIM"}}, true},
+		{"replace without match", []URLRewriteRule{{Action: URLRewriteReplace, Value: "ODIM"}}, true},
+		{"prefix not a path", []URLRewriteRule{{Action: URLRewriteAddPrefix, Value: "proxy"}}, true},
+		{"repeated match", []URLRewriteRule{{Action: URLRewriteReplace, Match: "redfish", Value: "ODIM"}, {Action: URLRewriteReplace, Match: "redfish", Value: "odim"}}, true},
+		{"strip undoing add", []URLRewriteRule{{Action: URLRewriteAddPrefix, Value: "/proxy"}, {Action: URLRewriteStripPrefix, Match: "/proxy"}}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := checkURLRewriteRules(tt.rules); (err != nil) != tt.wantErr {
+				t.Errorf("checkURLRewriteRules() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestBindAddresses(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    string
+		want    BindAddresses
+		wantErr bool
+	}{
+		{"single address", `"10.0.0.1"`, BindAddresses{"10.0.0.1"}, false},
+		{"dual stack", `["10.0.0.1", "fd00::1"]`, BindAddresses{"10.0.0.1", "fd00::1"}, false},
+		{"invalid", `10`, nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got BindAddresses
+			if err := json.Unmarshal([]byte(tt.data), &got); (err != nil) != tt.wantErr {
+				t.Fatalf("UnmarshalJSON() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("UnmarshalJSON() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	SetUpMockConfig(t)
+	for _, host := range []string{"10.0.0.1", "fd00::1", "plugin.odim.local"} {
+		Data.PluginConf.Host = BindAddresses{host}
+		if err := Data.checkPluginConf(); err != nil {
+			t.Errorf("checkPluginConf() with host %s error = %v", host, err)
+		}
+	}
+	Data.PluginConf.Host = BindAddresses{"10.0.0.1", "10.0.0.256:45001"}
+	if err := Data.checkPluginConf(); err == nil {
+		t.Error("checkPluginConf() with invalid host succeeded")
+	}
+}
+
+func TestCheckPluginConfIdentity(t *testing.T) {
+	SetUpMockConfig(t)
+	Data.PluginConf.Vendor = " "
+	if err := Data.checkPluginConf(); err == nil {
+		t.Error("checkPluginConf() with blank Vendor succeeded")
+	}
+	Data.PluginConf.Vendor, Data.PluginConf.Model, Data.PluginConf.Name = "", "", ""
+	if err := Data.checkPluginConf(); err != nil {
+		t.Fatalf("checkPluginConf() without Vendor, Model and Name error = %v", err)
+	}
+	if Data.PluginConf.Vendor != DefaultPluginVendor || Data.PluginConf.Model != DefaultPluginModel || Data.PluginConf.Name != Data.PluginConf.ID {
+		t.Errorf("checkPluginConf() set Vendor %s, Model %s and Name %s, want the defaults", Data.PluginConf.Vendor, Data.PluginConf.Model, Data.PluginConf.Name)
+	}
+}
+
+func TestCheckPluginConfPortEventsPort(t *testing.T) {
+	SetUpMockConfig(t)
+	defer func() { Data.PluginConf.PortEventsPort = "" }()
+	for port, wantErr := range map[string]bool{"": false, "45010": false, Data.PluginConf.Port: true} {
+		Data.PluginConf.PortEventsPort = port
+		if err := Data.checkPluginConf(); (err != nil) != wantErr {
+			t.Errorf("checkPluginConf() with PortEventsPort %q error = %v, wantErr %v", port, err, wantErr)
+		}
+	}
+}
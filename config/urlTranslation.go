@@ -0,0 +1,84 @@
+//(C) Copyright [2020] Hewlett Packard Enterprise Development LP
+//
+//Licensed under the Apache License, Version 2.0 (the "License"); you may
+//not use this file except in compliance with the License. You may obtain
+//a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+//Unless required by applicable law or agreed to in writing, software
+//distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+//WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+//License for the specific language governing permissions and limitations
+// under the License.
+
+package config
+
+import (
+	"sort"
+	"strings"
+)
+
+// SouthBoundTranslator translates the southbound paths with the SouthBoundURL translation and the
+// SouthBoundRules compiled once, instead of the translation and the rules being interpreted for every path
+type SouthBoundTranslator struct {
+	steps []func(string) string
+}
+
+// Translate translates the path with the SouthBoundURL translation followed by the SouthBoundRules
+// in the configured order
+func (t *SouthBoundTranslator) Translate(path string) string {
+	for _, step := range t.steps {
+		path = step(path)
+	}
+	return path
+}
+
+// CompileSouthBound compiles the SouthBoundURL translation and the SouthBoundRules, it is done
+// when the configuration is loaded and has to be done again when the translation is changed
+func (t *URLTranslation) CompileSouthBound() {
+	t.southBound = compileSouthBound(t.SouthBoundURL, t.SouthBoundRules)
+}
+
+// SouthBound returns the compiled southbound translation, it is compiled on first use when it
+// was not compiled on load
+func (t *URLTranslation) SouthBound() *SouthBoundTranslator {
+	if t.southBound == nil {
+		t.CompileSouthBound()
+	}
+	return t.southBound
+}
+
+func compileSouthBound(translation map[string]string, rules []URLRewriteRule) *SouthBoundTranslator {
+	var translator SouthBoundTranslator
+	if len(translation) > 0 {
+		// the longest matches are tried first so that the result doesn't depend on the map order
+		keys := make([]string, 0, len(translation))
+		for key := range translation {
+			keys = append(keys, key)
+		}
+		sort.Slice(keys, func(i, j int) bool {
+			if len(keys[i]) != len(keys[j]) {
+				return len(keys[i]) > len(keys[j])
+			}
+			return keys[i] < keys[j]
+		})
+		pairs := make([]string, 0, 2*len(keys))
+		for _, key := range keys {
+			pairs = append(pairs, key, translation[key])
+		}
+		translator.steps = append(translator.steps, strings.NewReplacer(pairs...).Replace)
+	}
+	for _, rule := range rules {
+		match, value := rule.Match, rule.Value
+		switch rule.Action {
+		case URLRewriteReplace:
+			translator.steps = append(translator.steps, strings.NewReplacer(match, value).Replace)
+		case URLRewriteAddPrefix:
+			translator.steps = append(translator.steps, func(path string) string { return value + path })
+		case URLRewriteStripPrefix:
+			translator.steps = append(translator.steps, func(path string) string { return strings.TrimPrefix(path, match) })
+		}
+	}
+	return &translator
+}
@@ -18,6 +18,12 @@ package constants
 const (
 	// ResourceCreatedMessageID holds the value Resource created MessageID
 	ResourceCreatedMessageID = "ResourceEvent.1.0.3.ResourceCreated"
+	// ResourceStatusChangedOKMessageID holds the MessageID of the event of resource status changed to OK
+	ResourceStatusChangedOKMessageID = "ResourceEvent.1.0.3.ResourceStatusChangedOK"
+	// ResourceStatusChangedWarningMessageID holds the MessageID of the event of resource status changed to Warning
+	ResourceStatusChangedWarningMessageID = "ResourceEvent.1.0.3.ResourceStatusChangedWarning"
+	// ResourceStatusChangedCriticalMessageID holds the MessageID of the event of resource status changed to Critical
+	ResourceStatusChangedCriticalMessageID = "ResourceEvent.1.0.3.ResourceStatusChangedCritical"
 	// EventODataType holds the supported version of Event type
 	EventODataType = "#Event.v1_5_0.Event"
 )
@@ -15,6 +15,7 @@
 package db
 
 import (
+	"context"
 	"crypto/tls"
 	"crypto/x509"
 	"fmt"
@@ -75,6 +76,57 @@ func getClient() (*Client, error) {
 	return client, nil
 }
 
+// PoolStats holds the statistics of the DB connection pool
+type PoolStats struct {
+	TotalConns uint32
+	IdleConns  uint32
+	StaleConns uint32
+	Timeouts   uint32
+}
+
+// Ping checks that the DB can be reached, the connection pool is created on the first call
+func Ping() error {
+	return connector{}.Ping(context.Background())
+}
+
+// Ping makes a round-trip to the DB, through the sentinel when Redis HA is enabled. The connection
+// pool is created on the first call, the DB is reported unavailable when it doesn't answer before ctx is done.
+func (d connector) Ping(ctx context.Context) error {
+	result := make(chan error, 1)
+	go func() {
+		c, err := getClient()
+		if err != nil {
+			result <- err
+			return
+		}
+		result <- c.pool.Ping().Err()
+	}()
+	var err error
+	select {
+	case err = <-result:
+	case <-ctx.Done():
+		err = ctx.Err()
+	}
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrorServiceUnavailable, err)
+	}
+	return nil
+}
+
+// Stats returns the statistics of the DB connection pool, all zero until the pool is created
+func (d connector) Stats() PoolStats {
+	if client == nil || client.pool == nil {
+		return PoolStats{}
+	}
+	stats := client.pool.PoolStats()
+	return PoolStats{
+		TotalConns: stats.TotalConns,
+		IdleConns:  stats.IdleConns,
+		StaleConns: stats.StaleConns,
+		Timeouts:   stats.Timeouts,
+	}
+}
+
 // resetDBConection is used to reset the WriteConnection Pool
 func resetDBConection() (err error) {
 	client.mux.Lock()
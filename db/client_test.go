@@ -15,7 +15,13 @@
 package db
 
 import (
+	"bufio"
+	"context"
+	"errors"
+	"net"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/ODIM-Project/PluginCiscoACI/config"
 	"github.com/go-redis/redis"
@@ -54,3 +60,85 @@ func TestGetClient(t *testing.T) {
 		})
 	}
 }
+
+// redisExtCallsAddrMock connects to the Redis server listening on addr without TLS
+type redisExtCallsAddrMock struct {
+	addr string
+}
+
+func (r redisExtCallsAddrMock) getNewClient() *redis.Client {
+	return redis.NewClient(&redis.Options{Addr: r.addr, MaxRetries: -1})
+}
+
+// listenRedis accepts the connections on a local port, PING is answered when pong is set
+func listenRedis(t *testing.T, pong bool) net.Listener {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				reader := bufio.NewReader(conn)
+				for {
+					line, err := reader.ReadString('\n')
+					if err != nil {
+						return
+					}
+					if pong && strings.EqualFold(strings.TrimSpace(line), "PING") {
+						conn.Write([]byte("+PONG\r\n"))
+					}
+				}
+			}()
+		}
+	}()
+	return listener
+}
+
+func TestConnectorPing(t *testing.T) {
+	config.SetUpMockConfig(t)
+	defer func() {
+		redisExtCalls = redisExtCallsImp{}
+		client = nil
+	}()
+	healthy := listenRedis(t, true)
+	defer healthy.Close()
+	unresponsive := listenRedis(t, false)
+	defer unresponsive.Close()
+	unreachable := listenRedis(t, false)
+	unreachable.Close()
+
+	tests := []struct {
+		name    string
+		addr    string
+		wantErr bool
+	}{
+		{"healthy", healthy.Addr().String(), false},
+		{"unreachable", unreachable.Addr().String(), true},
+		{"unresponsive", unresponsive.Addr().String(), true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			redisExtCalls = redisExtCallsAddrMock{addr: tt.addr}
+			client = nil
+			ctx, cancel := context.WithTimeout(context.Background(), 500*time.Millisecond)
+			defer cancel()
+			started := time.Now()
+			err := connector{}.Ping(ctx)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Ping() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil && !errors.Is(err, ErrorServiceUnavailable) {
+				t.Errorf("Ping() error = %v, want %v", err, ErrorServiceUnavailable)
+			}
+			if elapsed := time.Since(started); elapsed > 2*time.Second {
+				t.Errorf("Ping() took %s, want it bounded by the context", elapsed)
+			}
+		})
+	}
+}
@@ -19,14 +19,37 @@ const (
 	scanPaginationSize = 1000
 	// TableFabric is the table for storing switch and pod ids
 	TableFabric = "ACI-Fabric"
+	// TableFabricSet is the key set of the ids of the fabrics stored, used for listing the fabrics
+	TableFabricSet = "ACI-FabricSet"
 	// TableSwitch is the table for storing switch information
 	TableSwitch = "ACI-Switch"
 	// TableSwitchChassis is the table for storing switch chassis information
 	TableSwitchChassis = "ACI-SwitchChassis"
 	// TableSwitchPorts is the table for storing ports of each switch
 	TableSwitchPorts = "ACI-SwitchPorts"
+	// TableSwitchPortSet is the table for storing the set of ports of each switch, used for counting the ports
+	TableSwitchPortSet = "ACI-SwitchPortSet"
 	// TablePort is the table for storing port information
 	TablePort = "ACI-Port"
+	// TablePortState is the table for storing the last known operational state of each port read from APIC
+	TablePortState = "ACI-PortState"
+	// TablePortHealthSet is the table for storing the set of ports of each health, used for querying the ports by health
+	TablePortHealthSet = "ACI-PortHealthSet"
+	// TablePortLinkSet is the table for storing the set of ports connected to each ethernet interface, used for
+	// finding the ports linked to an ethernet interface
+	TablePortLinkSet = "ACI-PortLinkSet"
+	// TablePortSettings is the table for storing the settings of each port requested from APIC and not yet applied
+	TablePortSettings = "ACI-PortSettings"
+	// TableEventBacklog is the table for storing the events not yet published while the message bus is unavailable
+	TableEventBacklog = "ACI-EventBacklog"
+	// TableEventBacklogSet is the key set of the ids of the events in the backlog, used for publishing them in order
+	TableEventBacklogSet = "ACI-EventBacklogSet"
+	// TableIdempotencyKey is the table for storing the result of the requests made with an idempotency key
+	TableIdempotencyKey = "ACI-IdempotencyKey"
+	// TableSession is the table for storing the sessions created by the plugin
+	TableSession = "ACI-Session"
+	// TableSessionToken is the table for storing the session id of each session token hash
+	TableSessionToken = "ACI-SessionToken"
 	// TableZone is the table for storing zone information
 	TableZone = "ACI-Zone"
 	// TableAddressPool is the table for storing addresspool information
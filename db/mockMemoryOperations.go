@@ -0,0 +1,274 @@
+//(C) Copyright [2020] Hewlett Packard Enterprise Development LP
+//
+//Licensed under the Apache License, Version 2.0 (the "License"); you may
+//not use this file except in compliance with the License. You may obtain
+//a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+//Unless required by applicable law or agreed to in writing, software
+//distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+//WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+//License for the specific language governing permissions and limitations
+// under the License.
+
+package db
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// MockMemoryConnector is an in-memory DB connector, used in unit tests
+// which need the written data to be read back
+type MockMemoryConnector struct {
+	lock   *sync.Mutex
+	data   map[string]string
+	sets   map[string]map[string]bool
+	expiry map[string]time.Time
+}
+
+// NewMockMemoryConnector returns an empty in-memory DB connector
+func NewMockMemoryConnector() MockMemoryConnector {
+	return MockMemoryConnector{
+		lock:   &sync.Mutex{},
+		data:   make(map[string]string),
+		sets:   make(map[string]map[string]bool),
+		expiry: make(map[string]time.Time),
+	}
+}
+
+// Create will create a new entry for the value with the given table and resourceID
+func (d MockMemoryConnector) Create(table, resourceID, data string) error {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	key := generateKey(table, resourceID)
+	d.removeExpired(key)
+	if _, exist := d.data[key]; exist {
+		return fmt.Errorf("%w: %s", ErrorKeyAlreadyExist,
+			fmt.Sprintf("An entry with resource id %s is already present in table %s", resourceID, table))
+	}
+	d.data[key] = data
+	return nil
+}
+
+// CreateWithExpiry will create a new entry for the value with the given table and resourceID,
+// the entry is removed once expiry has elapsed
+func (d MockMemoryConnector) CreateWithExpiry(table, resourceID, data string, expiry time.Duration) error {
+	if err := d.Create(table, resourceID, data); err != nil {
+		return err
+	}
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	d.expiry[generateKey(table, resourceID)] = time.Now().Add(expiry)
+	return nil
+}
+
+// removeExpired removes the entry of the key when its expiry has elapsed, the lock is held by the caller
+func (d MockMemoryConnector) removeExpired(key string) {
+	if expiry, exist := d.expiry[key]; exist && !time.Now().Before(expiry) {
+		delete(d.data, key)
+		delete(d.expiry, key)
+	}
+}
+
+// Update will update an entry with the value for the given table and resourceID
+func (d MockMemoryConnector) Update(table, resourceID, data string) error {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	key := generateKey(table, resourceID)
+	d.data[key] = data
+	delete(d.expiry, key)
+	return nil
+}
+
+// GetAllMatchingKeys will collect all the keys of provided table and pattern
+func (d MockMemoryConnector) GetAllMatchingKeys(table, pattern string) ([]string, error) {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	var keys []string
+	for key := range d.data {
+		d.removeExpired(key)
+		if _, exist := d.data[key]; exist && strings.HasPrefix(key, generateKey(table, pattern)) {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	return trimTableFromKeys(table, keys), nil
+}
+
+// Get will collect the data associated with the given key from the given table
+func (d MockMemoryConnector) Get(table, resourceID string) (string, error) {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	key := generateKey(table, resourceID)
+	d.removeExpired(key)
+	data, exist := d.data[key]
+	if !exist {
+		return "", fmt.Errorf("%w: %s", ErrorKeyNotFound,
+			fmt.Sprintf("Data with resource ID %s not found in table %s", resourceID, table))
+	}
+	return data, nil
+}
+
+// Exists will check if an entry is present for the given key in the given table
+func (d MockMemoryConnector) Exists(table, resourceID string) (bool, error) {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	key := generateKey(table, resourceID)
+	d.removeExpired(key)
+	_, exist := d.data[key]
+	return exist, nil
+}
+
+// UpdateKeySet will add passed member to the particular key set
+func (d MockMemoryConnector) UpdateKeySet(key string, member string) error {
+	key = prefixKey(key)
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	if d.sets[key] == nil {
+		d.sets[key] = make(map[string]bool)
+	}
+	d.sets[key][member] = true
+	return nil
+}
+
+// GetKeySetMembers will get the list of member in the particular key set
+func (d MockMemoryConnector) GetKeySetMembers(key string) ([]string, error) {
+	key = prefixKey(key)
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	list := []string{}
+	for member := range d.sets[key] {
+		list = append(list, member)
+	}
+	sort.Strings(list)
+	return list, nil
+}
+
+// GetKeySetCount will get the number of members in the particular key set
+func (d MockMemoryConnector) GetKeySetCount(key string) (int, error) {
+	key = prefixKey(key)
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	return len(d.sets[key]), nil
+}
+
+// Delete will delete the data associated with the given key from the given table
+func (d MockMemoryConnector) Delete(table, resourceID string) error {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	key := generateKey(table, resourceID)
+	delete(d.data, key)
+	delete(d.expiry, key)
+	return nil
+}
+
+// DeleteKeySetMembers will delete the member from the particular key set
+func (d MockMemoryConnector) DeleteKeySetMembers(key string, member string) error {
+	key = prefixKey(key)
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	delete(d.sets[key], member)
+	return nil
+}
+
+// CompareAndSwap will update the entry with newData only when the stored value is still oldData
+func (d MockMemoryConnector) CompareAndSwap(table, resourceID, oldData, newData string) (bool, error) {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	key := generateKey(table, resourceID)
+	data, exist := d.data[key]
+	if !exist {
+		return false, fmt.Errorf("%w: %s", ErrorKeyNotFound,
+			fmt.Sprintf("Data with resource ID %s not found in table %s", resourceID, table))
+	}
+	if data != oldData {
+		return false, nil
+	}
+	d.data[key] = newData
+	return true, nil
+}
+
+// Transaction will apply all the writes at once, none of them is applied when an entry to be created is present
+// or when an entry compared was modified
+func (d MockMemoryConnector) Transaction(writes []Write) error {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	for _, write := range writes {
+		if write.KeySet != "" {
+			continue
+		}
+		key := generateKey(write.Table, write.ResourceID)
+		d.removeExpired(key)
+		data, exist := d.data[key]
+		if write.Create && exist {
+			return fmt.Errorf("%w: %s", ErrorKeyAlreadyExist,
+				fmt.Sprintf("An entry with resource id %s is already present in table %s", write.ResourceID, write.Table))
+		}
+		if write.Compare && !write.Create && !exist {
+			return fmt.Errorf("%w: %s", ErrorKeyNotFound,
+				fmt.Sprintf("Data with resource ID %s not found in table %s", write.ResourceID, write.Table))
+		}
+		if write.Compare && !write.Create && data != write.Previous {
+			return fmt.Errorf("%w: %s", ErrorEntryModified,
+				fmt.Sprintf("Data with resource ID %s was modified in table %s", write.ResourceID, write.Table))
+		}
+	}
+	for _, write := range writes {
+		if write.KeySet != "" {
+			key := prefixKey(write.KeySet)
+			if write.Delete {
+				delete(d.sets[key], write.Member)
+			} else {
+				if d.sets[key] == nil {
+					d.sets[key] = make(map[string]bool)
+				}
+				d.sets[key][write.Member] = true
+			}
+			continue
+		}
+		key := generateKey(write.Table, write.ResourceID)
+		delete(d.expiry, key)
+		if write.Delete {
+			delete(d.data, key)
+		} else {
+			d.data[key] = write.Data
+			if write.Expiry > 0 {
+				d.expiry[key] = time.Now().Add(write.Expiry)
+			}
+		}
+	}
+	return nil
+}
+
+// Ping reports the in-memory DB reachable
+func (d MockMemoryConnector) Ping(ctx context.Context) error {
+	return nil
+}
+
+// Stats returns no connection pool statistics, the in-memory DB has no connections
+func (d MockMemoryConnector) Stats() PoolStats {
+	return PoolStats{}
+}
+
+// Keys returns all the keys currently stored, sorted
+func (d MockMemoryConnector) Keys() []string {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	var keys []string
+	for key := range d.data {
+		keys = append(keys, key)
+	}
+	for key, members := range d.sets {
+		if len(members) > 0 {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
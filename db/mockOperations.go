@@ -15,7 +15,9 @@
 package db
 
 import (
+	"context"
 	"fmt"
+	"time"
 )
 
 // MockConnector is for mocking DB connector interface
@@ -26,6 +28,11 @@ func (d MockConnector) Create(table, resourceID, data string) error {
 	return nil
 }
 
+// CreateWithExpiry is for mocking DB CreateWithExpiry operation
+func (d MockConnector) CreateWithExpiry(table, resourceID, data string, expiry time.Duration) error {
+	return nil
+}
+
 // Update is for mocking DB Update operation
 func (d MockConnector) Update(table, resourceID, data string) error {
 	return nil
@@ -56,6 +63,11 @@ func (d MockConnector) Get(table, resourceID string) (string, error) {
 	return "", fmt.Errorf("not found")
 }
 
+// Exists is for mocking DB EXISTS operation
+func (d MockConnector) Exists(table, resourceID string) (bool, error) {
+	return resourceID == "validID", nil
+}
+
 // UpdateKeySet is for mocking DB SADD operation
 func (d MockConnector) UpdateKeySet(key string, member string) (err error) {
 	return nil
@@ -66,6 +78,11 @@ func (d MockConnector) GetKeySetMembers(key string) (list []string, err error) {
 	return []string{"zoneID"}, nil
 }
 
+// GetKeySetCount is for mocking DB SCARD operation
+func (d MockConnector) GetKeySetCount(key string) (int, error) {
+	return 1, nil
+}
+
 // Delete is for mocking DB Delete operation
 func (d MockConnector) Delete(table, resourceID string) (err error) {
 	return nil
@@ -75,3 +92,23 @@ func (d MockConnector) Delete(table, resourceID string) (err error) {
 func (d MockConnector) DeleteKeySetMembers(key string, member string) (err error) {
 	return nil
 }
+
+// CompareAndSwap is for mocking DB WATCH/MULTI based update operation
+func (d MockConnector) CompareAndSwap(table, resourceID, oldData, newData string) (bool, error) {
+	return true, nil
+}
+
+// Transaction is for mocking DB WATCH/MULTI based transaction
+func (d MockConnector) Transaction(writes []Write) error {
+	return nil
+}
+
+// Ping is for mocking DB PING operation
+func (d MockConnector) Ping(ctx context.Context) error {
+	return nil
+}
+
+// Stats is for mocking the statistics of the DB connection pool
+func (d MockConnector) Stats() PoolStats {
+	return PoolStats{}
+}
@@ -15,10 +15,13 @@
 package db
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"strings"
+	"time"
 
+	"github.com/ODIM-Project/PluginCiscoACI/config"
 	"github.com/go-redis/redis"
 )
 
@@ -30,23 +33,50 @@ var (
 	ErrorKeyAlreadyExist = errors.New("Key already exist in DB")
 	// ErrorKeyNotFound is for identifing not found error
 	ErrorKeyNotFound = errors.New("Key not Found in DB")
+	// ErrorEntryModified is for identifing the entries modified since they were read
+	ErrorEntryModified = errors.New("Entry modified in DB")
 )
 
 type dbCalls interface {
 	Create(table, resourceID, data string) (err error)
+	CreateWithExpiry(table, resourceID, data string, expiry time.Duration) (err error)
 	Update(table, resourceID, data string) (err error)
 	GetAllMatchingKeys(table, pattern string) ([]string, error)
 	Get(table, resourceID string) (string, error)
+	Exists(table, resourceID string) (bool, error)
 	UpdateKeySet(key string, member string) (err error)
 	GetKeySetMembers(key string) (list []string, err error)
+	GetKeySetCount(key string) (int, error)
 	Delete(table, resourceID string) (err error)
 	DeleteKeySetMembers(key string, member string) (err error)
+	CompareAndSwap(table, resourceID, oldData, newData string) (bool, error)
+	Transaction(writes []Write) error
+	Ping(ctx context.Context) error
+	Stats() PoolStats
 }
 
 // Connector is the interface which connects the DB functions
 var Connector dbCalls
 
 // connector is used as a receiver for DB communication functions
+// Write is an entry written by Transaction, the entry is deleted when Delete is set and
+// the transaction fails when Create is set and the entry is already present. When KeySet
+// is set Member is added to the key set instead, or removed from it when Delete is set.
+// The entry is removed by the DB once Expiry has elapsed when Expiry is set. When Compare
+// is set the transaction fails with ErrorEntryModified unless the entry still holds Previous.
+type Write struct {
+	Table      string
+	ResourceID string
+	Data       string
+	Create     bool
+	Delete     bool
+	KeySet     string
+	Member     string
+	Expiry     time.Duration
+	Compare    bool
+	Previous   string
+}
+
 type connector struct{}
 
 // Create will create a new entry in DB for the value with the given table and resourceID
@@ -73,6 +103,31 @@ func (d connector) Create(table, resourceID, data string) (err error) {
 	}
 }
 
+// CreateWithExpiry will create a new entry in DB for the value with the given table and resourceID,
+// the entry is removed by the DB once expiry has elapsed
+func (d connector) CreateWithExpiry(table, resourceID, data string, expiry time.Duration) (err error) {
+	c, err := getClient()
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrorServiceUnavailable, err)
+	}
+	ok, err := c.pool.SetNX(generateKey(table, resourceID), data, expiry).Result()
+	switch {
+	case err != nil:
+		return fmt.Errorf(
+			"Creating new entry for value %v in table %s with resource id %s failed: %v",
+			data, table, resourceID, err,
+		)
+	case !ok:
+		return fmt.Errorf(
+			"%w: %s",
+			ErrorKeyAlreadyExist,
+			fmt.Sprintf("An entry with resource id %s is already present in table %s", resourceID, table),
+		)
+	default:
+		return nil
+	}
+}
+
 // Update will update an entry in DB with the value for the given table and resourceID
 func (d connector) Update(table, resourceID, data string) (err error) {
 	c, err := getClient()
@@ -131,9 +186,30 @@ func (d connector) Get(table, resourceID string) (string, error) {
 	}
 }
 
+// Exists will check if an entry is present for the given key in the given table, without reading it
+func (d connector) Exists(table, resourceID string) (bool, error) {
+	c, err := getClient()
+	if err != nil {
+		return false, fmt.Errorf("%w: %v", ErrorServiceUnavailable, err)
+	}
+	count, err := c.pool.Exists(generateKey(table, resourceID)).Result()
+	if err != nil {
+		return false, fmt.Errorf("unable to complete the operation: %s", err.Error())
+	}
+	return count > 0, nil
+}
+
 // generateKey is for concatinating table and resourceID to for a key
 func generateKey(table, resourceID string) string {
-	return fmt.Sprintf("%s:%s", table, resourceID)
+	return prefixKey(fmt.Sprintf("%s:%s", table, resourceID))
+}
+
+// prefixKey prepends the configured KeyPrefix to the key
+func prefixKey(key string) string {
+	if config.Data.DBConf == nil {
+		return key
+	}
+	return config.Data.DBConf.KeyPrefix + key
 }
 
 // trimTableFromKeys trims <table>: from the slice of keys in the form of <table>:<resourceID>
@@ -147,6 +223,7 @@ func trimTableFromKeys(table string, fullKeys []string) []string {
 
 // UpdateKeySet will add passed member to the particular key set.
 func (d connector) UpdateKeySet(key string, member string) (err error) {
+	key = prefixKey(key)
 	c, err := getClient()
 	if err != nil {
 		return fmt.Errorf("%w: %v", ErrorServiceUnavailable, err)
@@ -162,6 +239,7 @@ func (d connector) UpdateKeySet(key string, member string) (err error) {
 
 // GetKeySetMembers will get the list of member in the particular key set.
 func (d connector) GetKeySetMembers(key string) (list []string, err error) {
+	key = prefixKey(key)
 	c, err := getClient()
 	if err != nil {
 		return nil, fmt.Errorf("%w: %v", ErrorServiceUnavailable, err)
@@ -198,6 +276,7 @@ func (d connector) Delete(table, resourceID string) (err error) {
 
 // DeleteKeySetMembers will delete the list of member in the particular key set.
 func (d connector) DeleteKeySetMembers(key string, member string) (err error) {
+	key = prefixKey(key)
 	c, err := getClient()
 	if err != nil {
 		return fmt.Errorf("%w: %v", ErrorServiceUnavailable, err)
@@ -207,3 +286,146 @@ func (d connector) DeleteKeySetMembers(key string, member string) (err error) {
 	}
 	return nil
 }
+
+// GetKeySetCount will get the number of members in the particular key set.
+func (d connector) GetKeySetCount(key string) (int, error) {
+	key = prefixKey(key)
+	c, err := getClient()
+	if err != nil {
+		return 0, fmt.Errorf("%w: %v", ErrorServiceUnavailable, err)
+	}
+	count, err := c.pool.SCard(key).Result()
+	if err != nil {
+		return 0, fmt.Errorf("Getting count of members in the key set %s failed: %v", key, err)
+	}
+	return int(count), nil
+}
+
+// CompareAndSwap will update the entry for the given table and resourceID with newData only when
+// the stored value is still oldData, false is returned when the entry was modified in between
+func (d connector) CompareAndSwap(table, resourceID, oldData, newData string) (bool, error) {
+	c, err := getClient()
+	if err != nil {
+		return false, fmt.Errorf("%w: %v", ErrorServiceUnavailable, err)
+	}
+	key := generateKey(table, resourceID)
+	swapped := false
+	err = c.pool.Watch(func(tx *redis.Tx) error {
+		val, err := tx.Get(key).Result()
+		if err != nil {
+			return err
+		}
+		if val != oldData {
+			return nil
+		}
+		_, err = tx.Pipelined(func(pipe redis.Pipeliner) error {
+			pipe.Set(key, newData, 0)
+			return nil
+		})
+		if err == nil {
+			swapped = true
+		}
+		return err
+	}, key)
+	switch err {
+	case redis.Nil:
+		return false, fmt.Errorf(
+			"%w: %s",
+			ErrorKeyNotFound,
+			fmt.Sprintf("Data with resource ID %s not found in table %s", resourceID, table),
+		)
+	case redis.TxFailedErr:
+		return false, nil
+	case nil:
+		return swapped, nil
+	default:
+		return false, fmt.Errorf("unable to complete the operation: %s", err.Error())
+	}
+}
+
+// Transaction will apply all the writes at once, none of them is applied when an entry to be
+// created is already present, when an entry compared was modified or when one of those entries
+// is created or modified concurrently
+func (d connector) Transaction(writes []Write) error {
+	c, err := getClient()
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrorServiceUnavailable, err)
+	}
+	var created, compared []string
+	for _, write := range writes {
+		switch {
+		case write.KeySet != "":
+		case write.Create:
+			created = append(created, generateKey(write.Table, write.ResourceID))
+		case write.Compare:
+			compared = append(compared, generateKey(write.Table, write.ResourceID))
+		}
+	}
+	err = c.pool.Watch(func(tx *redis.Tx) error {
+		for _, write := range writes {
+			if !write.Compare || write.Create || write.KeySet != "" {
+				continue
+			}
+			val, err := tx.Get(generateKey(write.Table, write.ResourceID)).Result()
+			if err == redis.Nil {
+				return fmt.Errorf("%w: %s", ErrorKeyNotFound,
+					fmt.Sprintf("Data with resource ID %s not found in table %s", write.ResourceID, write.Table))
+			}
+			if err != nil {
+				return err
+			}
+			if val != write.Previous {
+				return fmt.Errorf("%w: %s", ErrorEntryModified,
+					fmt.Sprintf("Data with resource ID %s was modified in table %s", write.ResourceID, write.Table))
+			}
+		}
+		for _, write := range writes {
+			if !write.Create || write.KeySet != "" {
+				continue
+			}
+			count, err := tx.Exists(generateKey(write.Table, write.ResourceID)).Result()
+			if err != nil {
+				return err
+			}
+			if count > 0 {
+				return fmt.Errorf(
+					"%w: %s",
+					ErrorKeyAlreadyExist,
+					fmt.Sprintf("An entry with resource id %s is already present in table %s", write.ResourceID, write.Table),
+				)
+			}
+		}
+		_, err := tx.Pipelined(func(pipe redis.Pipeliner) error {
+			for _, write := range writes {
+				if write.KeySet != "" {
+					if write.Delete {
+						pipe.SRem(prefixKey(write.KeySet), write.Member)
+					} else {
+						pipe.SAdd(prefixKey(write.KeySet), write.Member)
+					}
+					continue
+				}
+				key := generateKey(write.Table, write.ResourceID)
+				if write.Delete {
+					pipe.Del(key)
+				} else {
+					pipe.Set(key, write.Data, write.Expiry)
+				}
+			}
+			return nil
+		})
+		return err
+	}, append(created, compared...)...)
+	switch {
+	case err == nil:
+		return nil
+	case errors.Is(err, ErrorKeyAlreadyExist), errors.Is(err, ErrorKeyNotFound), errors.Is(err, ErrorEntryModified):
+		return err
+	case err == redis.TxFailedErr && len(compared) != 0:
+		return fmt.Errorf("%w: an entry compared was modified concurrently", ErrorEntryModified)
+	case err == redis.TxFailedErr:
+		return fmt.Errorf("%w: an entry to be created was created concurrently", ErrorKeyAlreadyExist)
+	default:
+		return fmt.Errorf("unable to complete the operation: %s", err.Error())
+	}
+}
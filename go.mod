@@ -6,10 +6,12 @@ require (
 	github.com/ODIM-Project/ODIM/lib-dmtf v0.0.0-20220824054103-cca8c7a2fb39
 	github.com/ODIM-Project/ODIM/lib-messagebus v0.0.0-20210128033657-6247ee21f91f
 	github.com/ODIM-Project/ODIM/lib-utilities v0.0.0-20220222103721-601850b1770b
+	github.com/andybalholm/brotli v1.0.4
 	github.com/ciscoecosystem/aci-go-client v1.6.6
 	github.com/fsnotify/fsnotify v1.5.1
 	github.com/go-redis/redis v6.15.9+incompatible
 	github.com/gofrs/uuid v3.2.0+incompatible
+	github.com/gorilla/websocket v1.5.0
 	github.com/kataras/iris/v12 v12.2.0-alpha9
 	github.com/satori/go.uuid v1.2.0
 	github.com/sirupsen/logrus v1.8.1
@@ -27,7 +29,6 @@ require (
 	github.com/Shopify/goreferrer v0.0.0-20210630161223-536fa16abd6f // indirect
 	github.com/agext/levenshtein v1.2.2 // indirect
 	github.com/ajg/form v1.5.1 // indirect
-	github.com/andybalholm/brotli v1.0.4 // indirect
 	github.com/apparentlymart/go-cidr v1.0.1 // indirect
 	github.com/apparentlymart/go-textseg v1.0.0 // indirect
 	github.com/armon/go-radix v1.0.0 // indirect
@@ -55,7 +56,6 @@ require (
 	github.com/googleapis/gax-go/v2 v2.0.5 // indirect
 	github.com/googleapis/gnostic v0.4.1 // indirect
 	github.com/gorilla/css v1.0.0 // indirect
-	github.com/gorilla/websocket v1.5.0 // indirect
 	github.com/hashicorp/errwrap v1.0.0 // indirect
 	github.com/hashicorp/go-cleanhttp v0.5.1 // indirect
 	github.com/hashicorp/go-getter v1.4.0 // indirect
@@ -18,17 +18,13 @@ import (
 	"errors"
 	"fmt"
 	"net"
-	"net/http"
 	"os"
-	"strconv"
 	"strings"
 	"time"
 
-	dmtfmodel "github.com/ODIM-Project/ODIM/lib-dmtf/model"
 	dc "github.com/ODIM-Project/ODIM/lib-messagebus/datacommunicator"
 	"github.com/ODIM-Project/ODIM/lib-utilities/common"
 	lutilconf "github.com/ODIM-Project/ODIM/lib-utilities/config"
-	"github.com/ODIM-Project/PluginCiscoACI/capdata"
 	"github.com/ODIM-Project/PluginCiscoACI/caphandler"
 	"github.com/ODIM-Project/PluginCiscoACI/capmessagebus"
 	"github.com/ODIM-Project/PluginCiscoACI/capmiddleware"
@@ -37,15 +33,16 @@ import (
 	"github.com/ODIM-Project/PluginCiscoACI/config"
 	"github.com/ODIM-Project/PluginCiscoACI/db"
 
-	"github.com/ciscoecosystem/aci-go-client/models"
 	iris "github.com/kataras/iris/v12"
-	uuid "github.com/satori/go.uuid"
 	"github.com/sirupsen/logrus"
 )
 
 var subscriptionInfo []capmodel.Device
 var log = logrus.New()
 
+// certReloader serves the plugin certificate to the plugin and event servers
+var certReloader *caputilities.CertReloader
+
 // TokenObject will contains the generated token and public key of odimra
 type TokenObject struct {
 	AuthToken string `json:"authToken"`
@@ -53,6 +50,10 @@ type TokenObject struct {
 }
 
 func main() {
+	if configFilePath, ok := validateConfigFlag(os.Args[1:]); ok {
+		os.Exit(validateConfigFile(configFilePath))
+	}
+
 	// intializing the plugin start time
 	caputilities.PluginStartTime = time.Now()
 	log.Info("Plugin Start time:", caputilities.PluginStartTime.Format(time.RFC3339))
@@ -66,19 +67,17 @@ func main() {
 		log.Fatal("while reading from config, PluginCiscoACI got" + err.Error())
 	}
 
-	if err := dc.SetConfiguration(config.Data.MessageBusConf.MessageQueueConfigFilePath); err != nil {
-		log.Fatal("while trying to set messagebus configuration, PluginCiscoACI got: " + err.Error())
-	}
-
-	// CreateJobQueue defines the queue which will act as an infinite buffer
-	// In channel is an entry or input channel and the Out channel is an exit or output channel
-	caphandler.In, caphandler.Out = common.CreateJobQueue(1)
+	// EventQueue is the bounded buffer of the events, which are published
+	// by the Publish method after reading them from the buffer
+	caphandler.EventQueue = capmessagebus.NewEventBuffer(
+		config.Data.MessageBusConf.EventBufferCapacity,
+		config.Data.MessageBusConf.EventOverflowPolicy,
+		time.Duration(config.Data.MessageBusConf.EventBlockTimeoutInSeconds)*time.Second,
+	)
 
-	// RunReadWorkers will create a worker pool for doing a specific task
-	// which is passed to it as Publish method after reading the data from the channel.
-	go common.RunReadWorkers(caphandler.Out, capmessagebus.Publish, 1)
-
-	intializeACIData()
+	if err := startup().Run(); err != nil {
+		log.Fatal("while starting, PluginCiscoACI got: " + err.Error())
+	}
 
 	configFilePath := os.Getenv("PLUGIN_CONFIG_FILE_PATH")
 	if configFilePath == "" {
@@ -89,9 +88,54 @@ func main() {
 
 	intializePluginStatus()
 
+	var err error
+	if certReloader, err = caputilities.NewCertReloader(config.Data.KeyCertConf.CertificatePath, config.Data.KeyCertConf.PrivateKeyPath); err != nil {
+		log.Fatal("while loading plugin certificate, PluginCiscoACI got: " + err.Error())
+	}
+	if err = certReloader.Watch(); err != nil {
+		log.Error("while trying to watch plugin certificate files, certificate rotation requires restart: " + err.Error())
+	}
+
 	app()
 }
 
+// validateConfigFlag returns the config file given with the -validate-config flag. The arguments are
+// scanned rather than parsed as flags, as the plugin is started with the flags of the ODIM libraries.
+func validateConfigFlag(args []string) (string, bool) {
+	for i, arg := range args {
+		name := strings.TrimLeft(arg, "-")
+		if name == arg {
+			continue
+		}
+		if name == "validate-config" && i+1 < len(args) {
+			return args[i+1], true
+		}
+		if strings.HasPrefix(name, "validate-config=") {
+			return strings.TrimPrefix(name, "validate-config="), true
+		}
+	}
+	return "", false
+}
+
+// validateConfigFile prints all the errors of the config file and returns the exit code of the validation,
+// the plugin doesn't bind any port nor connect to the DB or APIC
+func validateConfigFile(configFilePath string) int {
+	err := config.ValidateFile(configFilePath)
+	if err == nil {
+		fmt.Println("config file " + configFilePath + " is valid")
+		return 0
+	}
+	var errs config.ValidationErrors
+	if !errors.As(err, &errs) {
+		errs = config.ValidationErrors{err}
+	}
+	fmt.Fprintf(os.Stderr, "config file %s has %d error(s):\n", configFilePath, len(errs))
+	for _, err := range errs {
+		fmt.Fprintln(os.Stderr, "  "+err.Error())
+	}
+	return 1
+}
+
 func app() {
 	app := routers()
 	go func() {
@@ -101,47 +145,91 @@ func app() {
 		Certificate:   &config.Data.KeyCertConf.Certificate,
 		PrivateKey:    &config.Data.KeyCertConf.PrivateKey,
 		CACertificate: &config.Data.KeyCertConf.RootCACertificate,
-		ServerAddress: config.Data.PluginConf.Host,
+		ServerAddress: config.Data.PluginConf.Host.First(),
 		ServerPort:    config.Data.PluginConf.Port,
 	}
 	pluginServer, err := conf.GetHTTPServerObj()
 	if err != nil {
 		log.Fatal("while initializing plugin server, PluginCiscoACI got: " + err.Error())
 	}
-	app.Run(iris.Server(pluginServer))
+	caputilities.SetServerTimeouts(pluginServer)
+	caputilities.SetCertReloader(pluginServer.TLSConfig, certReloader)
+	if err := caputilities.SetHTTP2(pluginServer); err != nil {
+		log.Fatal("while initializing plugin server, PluginCiscoACI got: " + err.Error())
+	}
+	listeners, err := caputilities.Listen(config.Data.PluginConf.Host, config.Data.PluginConf.Port)
+	if err != nil {
+		log.Fatal("while initializing plugin server, PluginCiscoACI got: " + err.Error())
+	}
+	pluginServer.Handler = app
+	if config.Data.PluginConf.PortEventsPort != "" {
+		go portEventsRouter()
+	}
+	app.Run(iris.Raw(func() error {
+		return caputilities.ServeTLS(pluginServer, listeners)
+	}))
 }
 
 func routers() *iris.Application {
+	capmiddleware.TokenValidator = caphandler.TokenValidation
 	app := iris.New()
-	app.WrapRouter(func(w http.ResponseWriter, r *http.Request, next http.HandlerFunc) {
-		path := r.URL.Path
-		if len(path) > 1 && path[len(path)-1] == '/' && path[len(path)-2] != '/' {
-			path = path[:len(path)-1]
-			r.RequestURI = path
-			r.URL.Path = path
-		}
-		next(w, r)
-	})
+	app.WrapRouter(capmiddleware.TrailingSlash)
+	app.WrapRouter(capmiddleware.Compress)
+	app.UseRouter(capmiddleware.CORS)
+	app.UseRouter(capmiddleware.LimitConcurrency)
+	app.UseRouter(capmiddleware.SampleLogs)
 
 	pluginRoutes := app.Party("/ODIM/v1")
 	pluginRoutes.Post("/validate", capmiddleware.BasicAuth, caphandler.Validate)
 	pluginRoutes.Post("/Sessions", caphandler.CreateSession)
-	pluginRoutes.Post("/Subscriptions", capmiddleware.BasicAuth, caphandler.CreateEventSubscription)
-	pluginRoutes.Delete("/Subscriptions", capmiddleware.BasicAuth, caphandler.DeleteEventSubscription)
+	pluginRoutes.Post("/SessionService/Sessions", caphandler.CreateSession)
+	pluginRoutes.Get("/SessionService/Sessions", capmiddleware.BasicAuth, caphandler.GetSessionCollection)
+	pluginRoutes.Get("/SessionService/Sessions/{id}", capmiddleware.BasicAuth, caphandler.GetSession)
+	pluginRoutes.Delete("/SessionService/Sessions/{id}", capmiddleware.BasicAuth, caphandler.DeleteSession)
+	pluginRoutes.Post("/Subscriptions", capmiddleware.BasicAuth, capmiddleware.ReadOnlyInMaintenance, caphandler.CreateEventSubscription)
+	pluginRoutes.Delete("/Subscriptions", capmiddleware.BasicAuth, capmiddleware.ReadOnlyInMaintenance, caphandler.DeleteEventSubscription)
 	pluginRoutes.Get("/Status", capmiddleware.BasicAuth, caphandler.GetPluginStatus)
+	pluginRoutes.Get("/Readiness", caphandler.GetPluginReadiness)
 	pluginRoutes.Post("/Startup", capmiddleware.BasicAuth, caphandler.GetPluginStartup)
+	pluginRoutes.Post("/APICSubscriptions", capmiddleware.BasicAuth, capmiddleware.ReadOnlyInMaintenance, caphandler.SubscribeAPICEvents)
+	pluginRoutes.Delete("/APICSubscriptions", capmiddleware.BasicAuth, capmiddleware.ReadOnlyInMaintenance, caphandler.UnsubscribeAPICEvents)
+	if config.Data.PluginConf.PortEventsPort == "" {
+		// the streams are closed after the write timeout of the server, the clients reconnect to resume them
+		pluginRoutes.Get("/PortEvents", capmiddleware.BasicAuth, caphandler.StreamPortEvents)
+	}
+	pluginRoutes.Get("/openapi.json", capmiddleware.BasicAuth, caphandler.GetOpenAPI)
+	pluginRoutes.Get("/StateArchive", capmiddleware.BasicAuth, caphandler.ExportStateArchive)
+	pluginRoutes.Get("/TelemetryService/MetricReportDefinitions/{id}", capmiddleware.BasicAuth, caphandler.GetMetricReportDefinition)
+	pluginRoutes.Get("/TelemetryService/MetricDefinitions/{id}", capmiddleware.BasicAuth, caphandler.GetMetricDefinition)
+	pluginRoutes.Get("/TelemetryService/MetricReports/{id}", capmiddleware.BasicAuth, caphandler.GetMetricReport)
+	pluginRoutes.Post("/StateArchive", capmiddleware.Audit, capmiddleware.BasicAuth, capmiddleware.ReadOnlyInMaintenance, caphandler.ImportStateArchive)
 	pluginRoutes.Get("/Chassis", capmiddleware.BasicAuth, caphandler.GetChassisCollection)
 	pluginRoutes.Get("/Chassis/{id}", capmiddleware.BasicAuth, caphandler.GetChassis)
-	pluginRoutes.Patch("/Chassis/{id}", capmiddleware.BasicAuth, caphandler.ChassisMethodNotAllowed)
-	pluginRoutes.Delete("/Chassis/{id}", capmiddleware.BasicAuth, caphandler.ChassisMethodNotAllowed)
-	fabricRoutes := pluginRoutes.Party("/Fabrics", capmiddleware.BasicAuth)
-	fabricRoutes.Get("/", caphandler.GetFabricResource)
+	pluginRoutes.Patch("/Chassis/{id}", capmiddleware.BasicAuth, capmiddleware.ReadOnlyInMaintenance, caphandler.ChassisMethodNotAllowed)
+	pluginRoutes.Delete("/Chassis/{id}", capmiddleware.BasicAuth, capmiddleware.ReadOnlyInMaintenance, caphandler.ChassisMethodNotAllowed)
+	fabricRoutes := pluginRoutes.Party("/Fabrics", capmiddleware.Audit, capmiddleware.BasicAuth, capmiddleware.ReadOnlyInMaintenance)
+	fabricRoutes.Get("/", caphandler.GetFabricCollection)
 	fabricRoutes.Get("/{id}", caphandler.GetFabricData)
+	fabricRoutes.Post("/{id}/Actions/Oem/CiscoACIFabric.ExportTopology", caphandler.ExportFabricTopology)
+	fabricRoutes.Post("/{id}/Actions/Oem/CiscoACIFabric.Rebuild", caphandler.RebuildFabric)
+	fabricRoutes.Post("/{id}/Actions/Oem/CiscoACIFabric.RepairPorts", caphandler.RepairFabricPorts)
 	fabricRoutes.Get("/{id}/Switches", caphandler.GetSwitchCollection)
 	fabricRoutes.Get("/{id}/Switches/{rid}", caphandler.GetSwitchInfo)
+	fabricRoutes.Post("/{id}/Switches/{switchID}/Actions/Oem/CiscoACISwitch.Discover", caphandler.DiscoverSwitch)
+	fabricRoutes.Get("/{id}/Oem/CiscoACI/PortFaults", caphandler.GetPortFaults)
+	fabricRoutes.Get("/{id}/Switches/{switchID}/Oem/CiscoACI/PortFaults", caphandler.GetPortFaults)
+	fabricRoutes.Get("/{id}/Oem/CiscoACI/PortsByHealth", caphandler.GetPortsByHealth)
+	fabricRoutes.Get("/{id}/Switches/{switchID}/Oem/CiscoACI/PortsByHealth", caphandler.GetPortsByHealth)
 	fabricRoutes.Get("/{id}/Switches/{switchID}/Ports", caphandler.GetPortCollection)
+	fabricRoutes.Head("/{id}/Switches/{switchID}/Ports", caphandler.GetPortCollection)
 	fabricRoutes.Get("/{id}/Switches/{switchID}/Ports/{portID}", caphandler.GetPortInfo)
+	fabricRoutes.Head("/{id}/Switches/{switchID}/Ports/{portID}", caphandler.GetPortInfo)
 	fabricRoutes.Patch("/{id}/Switches/{switchID}/Ports/{portID}", caphandler.PatchPort)
+	fabricRoutes.Get("/{id}/Switches/{switchID}/Ports/{portID}/Oem/CiscoACI/StatisticsHistory", caphandler.GetPortStatisticsHistory)
+	fabricRoutes.Get("/{id}/Switches/{switchID}/Ports/{portID}/Settings", caphandler.GetPortSettings)
+	fabricRoutes.Patch("/{id}/Switches/{switchID}/Ports/{portID}/Settings", caphandler.PatchPortSettings)
+	fabricRoutes.Post("/{id}/Switches/{switchID}/Ports/{portID}/Actions/Port.Reset", caphandler.ResetPort)
+	fabricRoutes.Delete("/{id}/Switches/{switchID}/Ports/{portID}/Links/ConnectedPorts", caphandler.DeletePortConnectedPorts)
 	fabricRoutes.Get("/{id}/Zones", caphandler.GetZones)
 	fabricRoutes.Post("/{id}/Zones", caphandler.CreateZone)
 	fabricRoutes.Get("/{id}/Zones/{rid}", caphandler.GetZone)
@@ -156,13 +244,13 @@ func routers() *iris.Application {
 	fabricRoutes.Get("/{id}/Endpoints/{rid}", caphandler.GetEndpointInfo)
 	fabricRoutes.Delete("/{id}/Endpoints/{rid}", caphandler.DeleteEndpointInfo)
 
-	managers := pluginRoutes.Party("/Managers")
+	managers := pluginRoutes.Party("/Managers", capmiddleware.BasicAuth)
 	managers.Get("/", caphandler.GetManagersCollection)
 	managers.Get("/{id}", caphandler.GetManagersInfo)
-	taskmon := pluginRoutes.Party("/taskmon")
+	taskmon := pluginRoutes.Party("/taskmon", capmiddleware.BasicAuth)
 	taskmon.Get("/{TaskID}", caphandler.GetTaskMonitor)
 
-	task := pluginRoutes.Party("/TaskService")
+	task := pluginRoutes.Party("/TaskService", capmiddleware.BasicAuth)
 	task.Get("/", caphandler.GetTaskService)
 	task.Get("/Tasks", caphandler.GetTaskService)
 	task.Get("/Tasks/{TaskID}", caphandler.GetTaskService)
@@ -187,9 +275,35 @@ func eventsrouters() {
 	if err != nil {
 		log.Fatal("while initializing event server, PluginCiscoACI got: " + err.Error())
 	}
+	caputilities.SetServerTimeouts(evtServer)
+	caputilities.SetCertReloader(evtServer.TLSConfig, certReloader)
 	app.Run(iris.Server(evtServer))
 }
 
+// portEventsRouter serves the port event stream on its own listener, PortEventsPort. Its server has
+// no write timeout, which can't be lifted for a single request, so that the streams stay open for
+// as long as the clients are connected.
+func portEventsRouter() {
+	app := iris.New()
+	app.WrapRouter(capmiddleware.TrailingSlash)
+	app.Get("/ODIM/v1/PortEvents", capmiddleware.BasicAuth, caphandler.StreamPortEvents)
+	conf := &lutilconf.HTTPConfig{
+		Certificate:   &config.Data.KeyCertConf.Certificate,
+		PrivateKey:    &config.Data.KeyCertConf.PrivateKey,
+		CACertificate: &config.Data.KeyCertConf.RootCACertificate,
+		ServerAddress: config.Data.PluginConf.Host.First(),
+		ServerPort:    config.Data.PluginConf.PortEventsPort,
+	}
+	streamServer, err := conf.GetHTTPServerObj()
+	if err != nil {
+		log.Fatal("while initializing port event stream server, PluginCiscoACI got: " + err.Error())
+	}
+	caputilities.SetServerTimeouts(streamServer)
+	streamServer.WriteTimeout = 0
+	caputilities.SetCertReloader(streamServer.TLSConfig, certReloader)
+	app.Run(iris.Server(streamServer))
+}
+
 // intializePluginStatus sets plugin status
 func intializePluginStatus() {
 	caputilities.Status.Available = "yes"
@@ -197,188 +311,47 @@ func intializePluginStatus() {
 	go sendStartupEvent()
 }
 
-// intializeACIData reads required fabric,switch and port data from aci and stored it in the data store
-func intializeACIData() {
-	aciNodesData, err := caputilities.GetFabricNodeData()
-	if err != nil {
-		log.Fatal("while intializing ACI Data  PluginCiscoACI got: " + err.Error())
-	}
-	for _, aciNodeData := range aciNodesData {
-		switchID := uuid.NewV4().String() + ":" + aciNodeData.NodeId
-		fabricID := config.Data.RootServiceUUID + ":" + aciNodeData.FabricId
-		fabricExists := true
-		fabricData, err := capmodel.GetFabric(fabricID)
-		if err != nil {
-			if errors.Is(err, db.ErrorKeyNotFound) {
-				fabricExists = false
-				data := &capdata.Fabric{
-					SwitchData: []string{
-						switchID,
-					},
-					PodID: aciNodeData.PodId,
-				}
-				if err := capmodel.SaveFabric(fabricID, data); err != nil {
-					log.Fatal("storing " + fabricID + " fabric failed with " + err.Error())
-				}
-			} else {
-				log.Fatal("fetching " + fabricID + " fabric failed with " + err.Error())
-			}
+// startup returns the initialization of the dependencies of the plugin in order: the message bus
+// the events are published on, the DB and the fabric data discovered from APIC into the DB. The
+// plugin is marked ready by intializePluginStatus only once all of them are up.
+func startup() *caputilities.Startup {
+	startup := caputilities.NewStartup(config.Data.ServerConf.StartupAttempts,
+		time.Duration(config.Data.ServerConf.StartupRetryDelayInSeconds)*time.Second)
+	startup.Require("message bus", func() error {
+		if err := dc.SetConfiguration(config.Data.MessageBusConf.MessageQueueConfigFilePath); err != nil {
+			return fmt.Errorf("while trying to set messagebus configuration, got: %v", err)
 		}
-		if !checkSwitchIDExists(fabricData.SwitchData, aciNodeData.NodeId) {
-			if fabricExists {
-				fabricData.SwitchData = append(fabricData.SwitchData, switchID)
-				fabricData.PodID = aciNodeData.PodId
-				if err := capmodel.UpdateFabric(fabricID, &fabricData); err != nil {
-					log.Fatal("updating " + fabricID + " fabric failed with " + err.Error())
-				}
-			}
-			switchData, chassisData := getSwitchData(fabricID, aciNodeData, switchID)
-			if err := capmodel.SaveSwitchChassis(chassisData.ID, chassisData); err != nil {
-				log.Fatal("storing " + chassisData.ID + " chassis failed with " + err.Error())
-			}
-			if err := capmodel.SaveSwitch(switchID, switchData); err != nil {
-				log.Fatal("storing " + switchID + " switch failed with " + err.Error())
-			}
-			// adding logic to collect the ports data
-			portData, err := caputilities.GetPortData(aciNodeData.PodId, aciNodeData.NodeId)
-			if err != nil {
-				log.Fatal("while intializing ACI Port  Data  PluginCiscoACI got: " + err.Error())
-			}
-			parsePortData(portData, switchID, fabricID)
-		}
-	}
-
-	// TODO:
-	// registering the for the aci events
-
-	return
-}
-
-// parsePortData parses the portData and stores it  in the inmemory
-func parsePortData(portResponseData *capmodel.PortCollectionResponse, switchID, fabricID string) {
-	var portData []string
-	for _, imdata := range portResponseData.IMData {
-		portAttributes := imdata.PhysicalInterface.Attributes
-		id := portAttributes["id"].(string)
-		id = strings.Replace(id, "/", "-", -1)
-		portID := uuid.NewV4().String() + ":" + id
-		portData = append(portData, portID)
-		portInfo := dmtfmodel.Port{
-			ODataContext:          "/ODIM/v1/$metadata#Port.Port",
-			ODataType:             "#Port.v1_3_0.Port",
-			ODataID:               fmt.Sprintf("/ODIM/v1/Fabrics/%s/Switches/%s/Ports/%s", fabricID, switchID, portID),
-			ID:                    portID,
-			Name:                  "Port-" + portAttributes["id"].(string),
-			PortID:                portAttributes["id"].(string),
-			PortProtocol:          "Ethernet",
-			PortType:              "BidirectionalPort",
-			LinkNetworkTechnology: "Ethernet",
-		}
-		mtu, err := strconv.Atoi(portAttributes["mtu"].(string))
-		if err != nil {
-			log.Error("Unable to get mtu for the port" + portID)
-		}
-		portInfo.MaxFrameSize = mtu
-		if err = capmodel.SavePort(portInfo.ODataID, &portInfo); err != nil {
-			log.Fatal("storing " + portInfo.ODataID + " port failed with " + err.Error())
+		// the events are kept in the DB while the message bus is unavailable
+		backlog := capmessagebus.NewEventBacklog(config.Data.MessageBusConf.EventBacklogCapacity,
+			time.Duration(config.Data.MessageBusConf.EventBacklogRetryIntervalInSeconds)*time.Second, capmessagebus.PublishEvent)
+		go backlog.Run()
+		go caphandler.EventQueue.Run(backlog.Publish)
+		return nil
+	})
+	startup.Require("Redis", db.Ping)
+	startup.Require("APIC", intializeACIData)
+	startup.Require("port settings reconciler", func() error {
+		caphandler.StartPortSettingsReconciler(time.Duration(config.Data.APICConf.PortSettingsReconcileIntervalInSeconds) * time.Second)
+		return nil
+	})
+	startup.Optional("port metric reports", func() error {
+		if interval := config.Data.APICConf.PortMetricReportIntervalInSeconds; interval > 0 {
+			caphandler.StartPortMetricReports(time.Duration(interval) * time.Second)
 		}
-	}
-	if err := capmodel.SaveSwitchPort(switchID, portData); err != nil {
-		log.Fatal("storing port data of switch " + switchID + " failed with " + err.Error())
-	}
+		return nil
+	})
+	return startup
 }
 
-func getSwitchData(fabricID string, fabricNodeData *models.FabricNodeMember, switchID string) (*dmtfmodel.Switch, *dmtfmodel.Chassis) {
-	switchUUIDData := strings.Split(switchID, ":")
-	var switchData = dmtfmodel.Switch{
-		ODataContext: "/ODIM/v1/$metadata#Switch.Switch",
-		ODataType:    "#Switch.v1_4_0.Switch",
-		ODataID:      "/ODIM/v1/Fabrics/" + fabricID + "/Switches/" + switchID,
-		ID:           switchID,
-		Name:         fabricNodeData.Name,
-		SwitchType:   "Ethernet",
-		UUID:         switchUUIDData[0],
-		SerialNumber: fabricNodeData.Serial,
-	}
-	podID, err := strconv.Atoi(fabricNodeData.PodId)
-	if err != nil {
-		log.Fatal("Converstion of PODID" + fabricNodeData.PodId + " failed")
-	}
-	nodeID, err := strconv.Atoi(fabricNodeData.NodeId)
-	if err != nil {
-		log.Fatal("Converstion of NodeID" + fabricNodeData.NodeId + " failed")
-	}
-	log.Info("Getting the switchData for NodeID" + fabricNodeData.NodeId)
-	switchRespData, err := caputilities.GetSwitchInfo(podID, nodeID)
-	if err != nil {
-		log.Fatal("Unable to get the Switch info:" + err.Error())
-	}
-	switchData.FirmwareVersion = switchRespData.SystemAttributes.Version
-	switchChassisData, healthChassisData, err := caputilities.GetSwitchChassisInfo(fabricNodeData.PodId, fabricNodeData.NodeId)
-	if err != nil {
-		log.Fatal("Unable to get the Switch Chassis info for node " + fabricNodeData.NodeId + " :" + err.Error())
-	}
-	switchData.Manufacturer = switchChassisData.IMData[0].SwitchChassisData.Attributes["vendor"].(string)
-	switchData.Model = switchChassisData.IMData[0].SwitchChassisData.Attributes["model"].(string)
-	chassisID := switchChassisData.IMData[0].SwitchChassisData.Attributes["id"].(string)
-	chassisUUID := uuid.NewV4().String()
-	var chassisHealth string
-
-	//take health value
-	data := healthChassisData.IMData[0].HealthData.Attributes
-	currentHealthValue := data["cur"].(string)
-	healthValue, err := strconv.Atoi(currentHealthValue)
-	if err != nil {
-		log.Fatal("Unable to convert current Health value:" + currentHealthValue + " go the error" + err.Error())
-	}
-
-	if healthValue > 90 {
-		chassisHealth = "OK"
-	} else if healthValue <= 90 && healthValue < 30 {
-		chassisHealth = "Warning"
-	} else {
-		chassisHealth = "Critical"
-	}
-	var chassisData = dmtfmodel.Chassis{
-		Ocontext:     "/ODIM/v1/$metadata#Chassis.Chassis",
-		Otype:        "#Chassis.v1_4_0.Chassis",
-		Oid:          "/ODIM/v1/Chassis/" + chassisUUID + ":" + chassisID,
-		ID:           chassisUUID + ":" + chassisID,
-		Name:         fabricNodeData.Name + "_chassis",
-		ChassisType:  "RackMount",
-		UUID:         chassisUUID,
-		SerialNumber: switchChassisData.IMData[0].SwitchChassisData.Attributes["ser"].(string),
-		Manufacturer: switchChassisData.IMData[0].SwitchChassisData.Attributes["vendor"].(string),
-		Model:        switchChassisData.IMData[0].SwitchChassisData.Attributes["model"].(string),
-		PowerState:   switchChassisData.IMData[0].SwitchChassisData.Attributes["operSt"].(string),
-		Status: &dmtfmodel.Status{
-			State:  "Enabled",
-			Health: chassisHealth,
-		},
-		Links: &dmtfmodel.Links{
-			Switches: []*dmtfmodel.Link{
-				&dmtfmodel.Link{
-					Oid: switchData.ODataID,
-				},
-			},
-		},
+// intializeACIData reads required fabric,switch and port data from aci and stored it in the data store
+func intializeACIData() error {
+	if err := capmodel.IndexFabrics(); err != nil {
+		return fmt.Errorf("while intializing ACI Data got: %v", err)
 	}
-	switchData.Links = &dmtfmodel.SwitchLinks{
-		Chassis: &dmtfmodel.Link{
-			Oid: chassisData.Oid,
-		},
+	if err := caphandler.DiscoverACIData(); err != nil {
+		return fmt.Errorf("while intializing ACI Data got: %v", err)
 	}
-
-	return &switchData, &chassisData
-}
-
-func checkSwitchIDExists(switchIDs []string, nodeID string) (exists bool) {
-	for _, switchid := range switchIDs {
-		if strings.HasSuffix(switchid, ":"+nodeID) {
-			return true
-		}
-	}
-	return false
+	return nil
 }
 
 // sendStartupEvent is for sending startup event
@@ -388,7 +361,7 @@ func sendStartupEvent() {
 
 	var pluginIP string
 	if pluginIP = os.Getenv("ASSIGNED_POD_IP"); pluginIP == "" {
-		pluginIP = config.Data.PluginConf.Host
+		pluginIP = config.Data.PluginConf.Host.First()
 	}
 
 	startupEvt := common.PluginStatusEvent{
@@ -400,13 +373,14 @@ func sendStartupEvent() {
 
 	request, _ := json.Marshal(startupEvt)
 	event := common.Events{
-		IP:        net.JoinHostPort(config.Data.PluginConf.Host, config.Data.PluginConf.Port),
+		IP:        net.JoinHostPort(config.Data.PluginConf.Host.First(), config.Data.PluginConf.Port),
 		Request:   request,
 		EventType: "PluginStartUp",
 	}
 
-	done := make(chan bool)
-	events := []interface{}{event}
-	go common.RunWriteWorkers(caphandler.In, events, 1, done)
+	if !caphandler.EventQueue.Enqueue(event) {
+		log.Warn("startup event dropped")
+		return
+	}
 	log.Info("successfully sent startup event")
 }